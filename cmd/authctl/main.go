@@ -0,0 +1,99 @@
+// Command authctl performs operational tasks on an auth-portal deployment.
+//
+//	authctl sessions migrate -from old-redis:6379 -to new-redis:6379
+//
+// copies the sessions, the state of logins in progress, pending invites and
+// email changes, role versions and replay protection to another Redis,
+// e.g. to upgrade it without logging everyone out: run it once, switch the
+// servers to the new Redis, then run it again to copy the sessions created on
+// the old one in the meantime. Passwords are read from the environment
+// variables AUTHCTL_FROM_REDIS_PASSWORD and AUTHCTL_TO_REDIS_PASSWORD.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/redis/go-redis/v9"
+)
+
+const usage = `usage: authctl <command> [flags]
+
+commands:
+  sessions migrate  copy sessions and login states to another Redis
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) >= 2 && args[0] == "sessions" && args[1] == "migrate" {
+		return migrateSessions(ctx, args[2:], out)
+	}
+	return errors.New(usage)
+}
+
+func migrateSessions(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("sessions migrate", flag.ContinueOnError)
+	from := flags.String("from", "", "comma separated addresses of the Redis to copy from")
+	to := flags.String("to", "", "comma separated addresses of the Redis to copy to")
+	dryRun := flags.Bool("dry-run", false, "count the keys to copy without copying them")
+	verbose := flags.Bool("v", false, "report progress after every batch of keys")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" || *to == "" {
+		return errors.New("both -from and -to are required")
+	}
+	if *from == *to {
+		return errors.New("-from and -to must differ")
+	}
+
+	src := newRedis(*from, os.Getenv("AUTHCTL_FROM_REDIS_PASSWORD"))
+	defer func() { _ = src.Close() }()
+	dst := newRedis(*to, os.Getenv("AUTHCTL_TO_REDIS_PASSWORD"))
+	defer func() { _ = dst.Close() }()
+	for name, rdb := range map[string]redis.UniversalClient{*from: src, *to: dst} {
+		if err := rdb.Ping(ctx).Err(); err != nil {
+			return fmt.Errorf("failed to reach %s: %w", name, err)
+		}
+	}
+
+	opts := repository.MigrateSessionsOptions{DryRun: *dryRun}
+	if *verbose {
+		opts.Progress = func(stats repository.SessionMigration) {
+			_, _ = fmt.Fprintf(out, "%+v\n", stats)
+		}
+	}
+	stats, err := repository.MigrateSessions(ctx, src, dst, opts)
+	verb := "copied"
+	if *dryRun {
+		verb = "would copy"
+	}
+	_, _ = fmt.Fprintf(
+		out,
+		"%s %d keys, merged %d indexes, skipped %d existing and %d expired keys\n",
+		verb, stats.Copied, stats.Merged, stats.Skipped, stats.Expired,
+	)
+	return err
+}
+
+func newRedis(addrs, password string) redis.UniversalClient {
+	return redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:    strings.Split(addrs, ","),
+		Password: password,
+	})
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+)
+
+func TestMigrateSessions(t *testing.T) {
+	ctx := context.Background()
+	src, srcMR := testutil.NewRedis(t)
+	_, dstMR := testutil.NewRedis(t)
+	sessionID, err := repository.NewSessionRepository(src).Create(ctx, "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	args := []string{"sessions", "migrate", "-from", srcMR.Addr(), "-to", dstMR.Addr()}
+	if err := run(ctx, args, &out); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "copied 1 keys, merged 1 indexes") {
+		t.Errorf("unexpected report %q", out.String())
+	}
+	if !dstMR.Exists(repository.SessionKey(sessionID)) {
+		t.Error("expected the session to be copied")
+	}
+
+	for _, args := range [][]string{
+		{"sessions"},
+		{"sessions", "migrate", "-from", srcMR.Addr()},
+		{"sessions", "migrate", "-from", srcMR.Addr(), "-to", srcMR.Addr()},
+	} {
+		if err := run(ctx, args, &out); err == nil {
+			t.Errorf("expected %v to fail", args)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/gateway"
+	"github.com/poly-workshop/auth-portal/internal/selfcheck"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// checkTimeout bounds each check, so an unreachable dependency fails it rather
+// than hanging the deployment
+const checkTimeout = 10 * time.Second
+
+// runChecks validates the configuration of the gateway, reaches Redis if
+// responses are cached and asks the gRPC server whether it is serving, reports
+// the results in format and returns the exit code.
+func runChecks(cfg configs.Config, format string) int {
+	target := gateway.ClientTarget(cfg.Gateway, cfg.Server.Port)
+	dialOpts, dialErr := gateway.DialOptions(cfg.Gateway)
+	checks := []selfcheck.Check{
+		selfcheck.Config("grpc client", func() error { return dialErr }),
+		selfcheck.Config("routes", func() error {
+			return checkGateway(target, func(g *gateway.Gateway) error {
+				return g.AddProxyRoutes(cfg.Gateway.Routes, cfg.Auth)
+			})
+		}),
+		selfcheck.Config("breaker", func() error {
+			return checkGateway(target, func(g *gateway.Gateway) error {
+				return g.EnableBreaker(cfg.Gateway)
+			})
+		}),
+		selfcheck.Config("asset origin", func() error {
+			if cfg.Gateway.AssetOrigin == "" {
+				return nil
+			}
+			return checkGateway(target, func(g *gateway.Gateway) error {
+				return g.UseAssetOrigin(cfg.Gateway.AssetOrigin)
+			})
+		}),
+	}
+	if len(cfg.Gateway.CachePaths) > 0 {
+		checks = append(checks, selfcheck.Redis(cfg.Redis))
+	}
+	if dialErr == nil {
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		checks = append(checks, selfcheck.GRPCHealth(target, dialOpts...))
+	}
+
+	results := selfcheck.Run(context.Background(), checkTimeout, checks...)
+	if err := selfcheck.Report(os.Stdout, format, results); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to report checks: %v\n", err)
+		return 2
+	}
+	if !selfcheck.Passed(results) {
+		return 1
+	}
+	return 0
+}
+
+// checkGateway runs configure on a gateway that is never served, to validate
+// the configuration it applies.
+func checkGateway(target string, configure func(*gateway.Gateway) error) error {
+	g, err := gateway.NewGateway(target, "", "/api/")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = g.Close() }()
+	return configure(g)
+}
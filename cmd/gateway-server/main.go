@@ -1,23 +1,23 @@
 package main
 
 import (
-	"context"
+	"flag"
 	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"strings"
+	"syscall"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/poly-workshop/auth-portal/configs"
-	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
-	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/buildinfo"
+	"github.com/poly-workshop/auth-portal/internal/gateway"
+	"github.com/poly-workshop/auth-portal/internal/logctx"
 	"github.com/poly-workshop/go-webmods/app"
-	"github.com/rs/cors"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func init() {
@@ -26,170 +26,84 @@ func init() {
 	app.Init(cwd)
 }
 
-// Gateway wraps the grpc-gateway mux and provides HTTP endpoints for gRPC services
-type Gateway struct {
-	mux       *runtime.ServeMux
-	grpcConn  *grpc.ClientConn
-	staticDir string
-	apiPrefix string
-}
-
-// NewGateway creates a new gateway instance
-func NewGateway(grpcEndpoint, staticDir, apiPrefix string) (*Gateway, error) {
-	// Create gRPC connection
-	conn, err := grpc.NewClient(grpcEndpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to gRPC server: %w", err)
-	}
-
-	// Create gateway mux with custom options
-	mux := runtime.NewServeMux(
-		runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
-			switch key {
-			case "Authorization":
-				return key, true
-			case "X-Request-Id":
-				return key, true
-			default:
-				return "", false
-			}
-		}),
-		runtime.WithOutgoingHeaderMatcher(func(key string) (string, bool) {
-			switch key {
-			case "X-Request-Id":
-				return key, true
-			default:
-				return "", false
-			}
-		}),
-	)
-
-	// Register services
-	ctx := context.Background()
-	if err := user_v1_pb.RegisterUserServiceHandler(ctx, mux, conn); err != nil {
-		_ = conn.Close()
-		return nil, fmt.Errorf("failed to register user service handler: %w", err)
-	}
-
-	if err := auth_v1_pb.RegisterAuthServiceHandler(ctx, mux, conn); err != nil {
-		_ = conn.Close()
-		return nil, fmt.Errorf("failed to register auth service handler: %w", err)
-	}
-
-	return &Gateway{
-		mux:       mux,
-		grpcConn:  conn,
-		staticDir: staticDir,
-		apiPrefix: apiPrefix,
-	}, nil
-}
-
-// Handler returns an HTTP handler with CORS support and static file serving
-func (g *Gateway) Handler() http.Handler {
-	// Setup CORS
-	c := cors.New(cors.Options{
-		AllowedOrigins: []string{"*"}, // In production, specify your frontend domains
-		AllowedMethods: []string{
-			http.MethodGet,
-			http.MethodPost,
-			http.MethodPut,
-			http.MethodDelete,
-			http.MethodOptions,
-		},
-		AllowedHeaders: []string{
-			"Accept",
-			"Accept-Language",
-			"Content-Language",
-			"Content-Type",
-			"Authorization",
-			"X-Request-Id",
-		},
-		ExposedHeaders: []string{
-			"X-Request-Id",
-		},
-		AllowCredentials: true,
-	})
-
-	// Create a multiplexer that handles both API and static files
-	mux := http.NewServeMux()
-
-	// Handle API routes with the gRPC gateway
-	mux.Handle(g.apiPrefix, http.StripPrefix(strings.TrimSuffix(g.apiPrefix, "/"), g.mux))
+func main() {
+	check := flag.Bool("check", false, "check the configuration and dependencies, then exit")
+	checkFormat := flag.String("check-format", "text", "format of the --check report: text or json")
+	flag.Parse()
 
-	// Handle static files for the frontend
-	if g.staticDir != "" {
-		// Check if static directory exists
-		if _, err := os.Stat(g.staticDir); err == nil {
-			// Serve static files, with index.html as fallback for SPA
-			fileServer := http.FileServer(http.Dir(g.staticDir))
-			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-				// Check if the requested file exists
-				path := filepath.Join(g.staticDir, r.URL.Path)
-				if _, err := os.Stat(path); os.IsNotExist(err) {
-					// If file doesn't exist and it's not an API request, serve index.html for SPA routing
-					if !strings.HasPrefix(r.URL.Path, strings.TrimSuffix(g.apiPrefix, "/")) {
-						http.ServeFile(w, r, filepath.Join(g.staticDir, "index.html"))
-						return
-					}
-				}
-				fileServer.ServeHTTP(w, r)
-			})
-			slog.Info("Static file serving enabled", "directory", g.staticDir)
-		} else {
-			slog.Warn("Static directory not found, serving API only", "directory", g.staticDir)
-			// If static directory doesn't exist, just serve the API
-			mux.Handle("/", g.mux)
-		}
-	} else {
-		// If no static directory specified, just serve the API
-		mux.Handle("/", g.mux)
+	cfg := configs.Load()
+	if *check {
+		os.Exit(runChecks(cfg, *checkFormat))
 	}
-
-	return c.Handler(mux)
-}
-
-// Close closes the gRPC connection
-func (g *Gateway) Close() error {
-	if g.grpcConn != nil {
-		return g.grpcConn.Close()
+	if err := logctx.Setup("gateway_server", cfg.Log); err != nil {
+		log.Fatalf("invalid log configuration: %v", err)
 	}
-	return nil
-}
-
-func main() {
-	cfg := configs.Load()
 
 	// Get current working directory to locate frontend dist
 	cwd, err := os.Getwd()
 	if err != nil {
 		log.Fatalf("failed to get current working directory: %v", err)
 	}
+	go reloadLogLevelOnSIGHUP(filepath.Join(cwd, "configs"))
 
 	// Static directory path (relative to project root)
 	staticDir := filepath.Join(cwd, "frontend", "dist")
 	apiPrefix := "/api/"
 
+	// Calls without a Grpc-Timeout header get this deadline
+	runtime.DefaultContextTimeout = cfg.Gateway.DefaultTimeout
+
 	// Create gateway instance
-	grpcEndpoint := fmt.Sprintf("localhost:%d", cfg.Server.Port)
-	gateway, err := NewGateway(grpcEndpoint, staticDir, apiPrefix)
+	grpcEndpoint := gateway.ClientTarget(cfg.Gateway, cfg.Server.Port)
+	dialOpts, err := gateway.DialOptions(cfg.Gateway)
+	if err != nil {
+		log.Fatalf("invalid gRPC client configuration: %v", err)
+	}
+	g, err := gateway.NewGateway(grpcEndpoint, staticDir, apiPrefix, dialOpts...)
 	if err != nil {
 		log.Fatalf("failed to create gateway: %v", err)
 	}
-	defer func() { _ = gateway.Close() }()
+	defer func() { _ = g.Close() }()
+	if err := g.Configure(cfg); err != nil {
+		log.Fatal(err)
+	}
 
-	// Create HTTP server
-	httpServer := &http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.Server.HTTPPort),
-		Handler: gateway.Handler(),
+	// Expose Prometheus metrics
+	if cfg.Gateway.MetricsPort != 0 {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", promhttp.Handler())
+			slog.Info("metrics server started", "port", cfg.Gateway.MetricsPort)
+			err := http.ListenAndServe(fmt.Sprintf(":%d", cfg.Gateway.MetricsPort), mux)
+			if err != nil {
+				slog.Error("metrics server stopped", "error", err)
+			}
+		}()
 	}
 
+	// Create HTTP server
+	httpServer := g.HTTPServer(cfg)
+
 	slog.Info("HTTP gateway server started",
 		"port", cfg.Server.HTTPPort,
+		"version", buildinfo.Get().Version,
 		"grpc_endpoint", grpcEndpoint,
+		"grpc_endpoints", cfg.Gateway.GRPCEndpoints,
 		"static_dir", staticDir,
-		"api_prefix", apiPrefix)
+		"api_prefix", apiPrefix,
+		"h2c", cfg.Gateway.H2C)
 
 	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		log.Fatalf("failed to serve HTTP: %v", err)
 	}
 }
+
+func reloadLogLevelOnSIGHUP(configDir string) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		if err := logctx.ReloadLevel(configDir); err != nil {
+			slog.Error("failed to reload log level", "error", err)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/clientip"
+	"github.com/poly-workshop/auth-portal/internal/errreport"
+	"github.com/poly-workshop/auth-portal/internal/failpoint"
+	"github.com/poly-workshop/auth-portal/internal/fieldcrypt"
+	"github.com/poly-workshop/auth-portal/internal/gateway"
+	"github.com/poly-workshop/auth-portal/internal/mailer"
+	"github.com/poly-workshop/auth-portal/internal/objectstore"
+	"github.com/poly-workshop/auth-portal/internal/outbound"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/selfcheck"
+	"github.com/poly-workshop/auth-portal/internal/server"
+	"github.com/poly-workshop/auth-portal/internal/service"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// checkTimeout bounds each check, so an unreachable dependency fails it rather
+// than hanging the deployment
+const checkTimeout = 10 * time.Second
+
+// runChecks validates the configuration, reaches the database, Redis and the
+// OAuth providers and loads the RBAC policy, reports the results in format and
+// returns the exit code.
+func runChecks(cfg configs.Config, format string) int {
+	checks := []selfcheck.Check{
+		selfcheck.Config("error reporting", func() error {
+			_, err := errreport.NewReporter(cfg.ErrorReporting)
+			return err
+		}),
+		selfcheck.Config("mailer", func() error {
+			_, err := mailer.NewMailer(cfg.Mailer)
+			return err
+		}),
+		selfcheck.Config("object storage", func() error {
+			_, err := objectstore.New(cfg.ObjectStorage)
+			return err
+		}),
+		selfcheck.Config("trusted proxies", func() error {
+			_, err := clientip.NewResolver(cfg.Server.TrustedProxies)
+			return err
+		}),
+		selfcheck.Config("peer guard", func() error {
+			_, err := server.NewPeerGuard(cfg.Server.PeerGuard)
+			return err
+		}),
+		selfcheck.Config("method access", func() error {
+			_, err := auth.NewMethodAccess(cfg.Auth.PublicMethods, cfg.Auth.DisabledMethods)
+			return err
+		}),
+		selfcheck.Config("internal credentials", func() error {
+			_, err := auth.NewInternalCredentials(
+				cfg.Auth.InternalToken,
+				cfg.Auth.InternalCredentials,
+			)
+			return err
+		}),
+		selfcheck.Config("workload issuers", func() error {
+			_, err := auth.NewWorkloadVerifier(cfg.Auth.WorkloadIssuers)
+			return err
+		}),
+		selfcheck.Config("failpoints", func() error {
+			_, err := failpoint.NewRegistry(cfg.Failpoints, configs.LoadSources().Mode)
+			return err
+		}),
+		selfcheck.Config("encryption", func() error {
+			_, err := fieldcrypt.NewKeyring(cfg.Encryption)
+			return err
+		}),
+		selfcheck.Config("user store", func() error {
+			backend := cmp.Or(cfg.UserStore.Backend, configs.UserStoreDatabase)
+			if !slices.Contains(repository.UserBackends(), backend) {
+				return fmt.Errorf("unknown user store backend %q", backend)
+			}
+			return nil
+		}),
+		selfcheck.Config("outbound", func() error {
+			_, err := outbound.NewClient(cfg.Outbound)
+			return err
+		}),
+		selfcheck.Database(cfg.Database),
+		selfcheck.Redis(cfg.Redis),
+	}
+	if cfg.Gateway.InProcess {
+		checks = append(checks, selfcheck.Config("gateway", func() error {
+			return checkGateway(cfg)
+		}))
+	}
+	// Providers are reached as logins reach them; if the outbound
+	// configuration is invalid, which fails above and stops the server from
+	// starting, they are still checked directly
+	client, _ := outbound.NewClient(cfg.Outbound)
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Outbound.Timeout}
+	}
+	oauthConfigs := service.OAuthConfigs(cfg.Auth)
+	providers := make([]string, 0, len(oauthConfigs))
+	for name := range oauthConfigs {
+		providers = append(providers, name)
+	}
+	slices.Sort(providers)
+	for _, name := range providers {
+		checks = append(checks, selfcheck.OAuthClient(name, oauthConfigs[name], client))
+	}
+	checks = append(checks, selfcheck.Policy())
+
+	results := selfcheck.Run(context.Background(), checkTimeout, checks...)
+	if err := selfcheck.Report(os.Stdout, format, results); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to report checks: %v\n", err)
+		return 2
+	}
+	if !selfcheck.Passed(results) {
+		return 1
+	}
+	return 0
+}
+
+// checkGateway applies the gateway settings to an in-process gateway that is
+// never served, to validate them.
+func checkGateway(cfg configs.Config) error {
+	conn, err := grpc.NewClient(
+		"passthrough:///inprocess",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return err
+	}
+	g, err := gateway.NewGatewayWithConn(conn, "", "/api/")
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func() { _ = g.Close() }()
+	return g.Configure(cfg)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/gateway"
+	"github.com/poly-workshop/auth-portal/internal/server"
+	"google.golang.org/grpc"
+)
+
+// serveGateway serves the gateway on the HTTP port, calling the services
+// registered on grpcServer in-process, and returns its HTTP server.
+func serveGateway(cfg configs.Config, grpcServer *grpc.Server) *http.Server {
+	conn, err := server.ServeInProcess(grpcServer)
+	if err != nil {
+		log.Fatalf("failed to serve gRPC in-process: %v", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		log.Fatalf("failed to get current working directory: %v", err)
+	}
+	staticDir := filepath.Join(cwd, "frontend", "dist")
+
+	// Calls without a Grpc-Timeout header get this deadline
+	runtime.DefaultContextTimeout = cfg.Gateway.DefaultTimeout
+	g, err := gateway.NewGatewayWithConn(conn, staticDir, "/api/")
+	if err != nil {
+		log.Fatalf("failed to create gateway: %v", err)
+	}
+	if err := g.Configure(cfg); err != nil {
+		log.Fatal(err)
+	}
+	httpServer := g.HTTPServer(cfg)
+	go func() {
+		slog.Info("HTTP gateway started in-process", "port", cfg.Server.HTTPPort,
+			"static_dir", staticDir, "h2c", cfg.Gateway.H2C)
+		err := httpServer.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("failed to serve HTTP: %v", err)
+		}
+	}()
+	return httpServer
+}
@@ -2,95 +2,437 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"log/slog"
 	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
 
-	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 	"github.com/poly-workshop/auth-portal/configs"
 	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
 	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/activity"
+	"github.com/poly-workshop/auth-portal/internal/buildinfo"
+	"github.com/poly-workshop/auth-portal/internal/clientip"
+	"github.com/poly-workshop/auth-portal/internal/errreport"
+	"github.com/poly-workshop/auth-portal/internal/failpoint"
+	"github.com/poly-workshop/auth-portal/internal/featureflags"
+	"github.com/poly-workshop/auth-portal/internal/fieldcrypt"
+	"github.com/poly-workshop/auth-portal/internal/job"
+	"github.com/poly-workshop/auth-portal/internal/logctx"
+	"github.com/poly-workshop/auth-portal/internal/mailer"
 	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/objectstore"
+	"github.com/poly-workshop/auth-portal/internal/outbound"
+	"github.com/poly-workshop/auth-portal/internal/report"
 	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/server"
 	"github.com/poly-workshop/auth-portal/internal/service"
+	"github.com/poly-workshop/auth-portal/internal/siem"
+	"github.com/poly-workshop/auth-portal/internal/slo"
+	"github.com/poly-workshop/auth-portal/internal/throttle"
+	"github.com/poly-workshop/auth-portal/internal/usage"
 	"github.com/poly-workshop/auth-portal/pkg/auth"
 	"github.com/poly-workshop/go-webmods/app"
 	"github.com/poly-workshop/go-webmods/gorm_client"
-	"github.com/poly-workshop/go-webmods/grpc_utils"
 	"github.com/poly-workshop/go-webmods/redis_client"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+// shutdownTimeout bounds how long calls in flight may run once the server is
+// asked to stop.
+const shutdownTimeout = 30 * time.Second
+
+// healthInterval is how often the server pings its database and Redis to
+// report its health.
+const healthInterval = 10 * time.Second
+
 func init() {
 	cwd, _ := os.Getwd()
 	app.SetCMDName("grpc_server")
 	app.Init(cwd)
 }
 
-// InterceptorLogger adapts slog logger to interceptor logger.
-// This code is simple enough to be copied and not imported.
-func InterceptorLogger(l *slog.Logger) logging.Logger {
-	return logging.LoggerFunc(
-		func(ctx context.Context, lvl logging.Level, msg string, fields ...any) {
-			l.Log(ctx, slog.Level(lvl), msg, fields...)
-		},
-	)
-}
-
 func main() {
+	check := flag.Bool("check", false, "check the configuration and dependencies, then exit")
+	checkFormat := flag.String("check-format", "text", "format of the --check report: text or json")
+	flag.Parse()
+
 	cfg := configs.Load()
+	if *check {
+		os.Exit(runChecks(cfg, *checkFormat))
+	}
+	reporter, err := errreport.NewReporter(cfg.ErrorReporting)
+	if err != nil {
+		log.Fatalf("invalid error reporting configuration: %v", err)
+	}
+	reporter.Start(context.Background())
+	// Log the request ID, user and method of calls with every line, and report
+	// errors with them
+	if err := logctx.Setup("grpc_server", cfg.Log, errreport.Middleware(reporter)); err != nil {
+		log.Fatalf("invalid log configuration: %v", err)
+	}
+
+	// Encrypt sensitive columns with the configured keys, if any
+	keyring, err := fieldcrypt.NewKeyring(cfg.Encryption)
+	if err != nil {
+		log.Fatalf("invalid encryption configuration: %v", err)
+	}
+	fieldcrypt.Install(keyring)
+
+	// Inject failures into the calls to dependencies, in development only
+	failpoints, err := failpoint.NewRegistry(cfg.Failpoints, configs.LoadSources().Mode)
+	if err != nil {
+		log.Fatalf("invalid failpoints configuration: %v", err)
+	}
+	failpoint.Install(failpoints)
 
 	// Initialize database
 	db := gorm_client.NewDB(cfg.Database)
-	err := db.AutoMigrate(&model.UserModel{})
+	err = db.AutoMigrate(
+		&model.UserModel{},
+		&model.AuditEventModel{},
+		&model.AuditChainHeadModel{},
+		&model.TenantSettingsModel{},
+		&model.ProviderTokenModel{},
+		&model.NotificationPreferencesModel{},
+		&model.ReportModel{},
+	)
 	if err != nil {
 		slog.Error("failed to migrate database", "error", err)
 	}
+	if err := repository.CreateSearchIndexes(db); err != nil {
+		slog.Error("failed to create user search indexes", "error", err)
+	}
+	if failpoints != nil {
+		if err := failpoint.RegisterGorm(db); err != nil {
+			log.Fatalf("failed to register failpoints: %v", err)
+		}
+	}
 
 	// Initialize Redis client
 	redis_client.SetConfig(cfg.Redis.Urls, cfg.Redis.Password)
 	rdb := redis_client.GetRDB()
+	if failpoints != nil {
+		rdb.AddHook(failpoint.RedisHook{})
+		slog.Warn("failpoints are enabled", "failpoints", len(failpoints.List()))
+	}
 
 	// Initialize repositories and services
-	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
-	authService := service.NewAuthService(db, rdb)
-
-	// Define public methods that don't require authentication
-	publicMethods := map[string]bool{
-		auth_v1_pb.AuthService_GetOAuthCodeURL_FullMethodName: true,
-		auth_v1_pb.AuthService_LoginByOAuth_FullMethodName:    true,
-		auth_v1_pb.AuthService_LoginByPassword_FullMethodName: true,
-		auth_v1_pb.AuthService_GetUserToken_FullMethodName:    true,
-	}
-
-	// Setup gRPC server with auth interceptor
-	grpcServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			grpc_utils.BuildRequestIDInterceptor(),
-			logging.UnaryServerInterceptor(InterceptorLogger(slog.Default())),
-			auth.BuildAuthInterceptor(publicMethods, cfg.Auth.JWTSecret),
+	userRepo, err := repository.OpenUserRepository(db, cfg.UserStore)
+	if err != nil {
+		log.Fatalf("failed to open user store: %v", err)
+	}
+	sessionRepo := repository.NewSessionRepository(
+		rdb,
+		repository.WithTTLJitter(cfg.Session.TTLJitter),
+	)
+	auditRepo := repository.NewAuditRepository(db)
+	// Stopped once the server is, so the events of the last calls are flushed
+	siemCtx, stopSIEM := context.WithCancel(context.Background())
+	var exporter *siem.Exporter
+	if cfg.SIEM.Sink != "" {
+		exporter, err = siem.NewExporter(cfg.SIEM)
+		if err != nil {
+			log.Fatalf("failed to create siem exporter: %v", err)
+		}
+		exporter.Start(siemCtx)
+		auditRepo = siem.NewAuditRepository(auditRepo, exporter)
+	}
+	emailChangeRepo := repository.NewEmailChangeRepository(rdb)
+	roleVersionRepo := repository.NewRoleVersionRepository(rdb)
+	tenantSettings := repository.NewTenantSettingsRepository(db)
+	inviteRepo := repository.NewInviteRepository(
+		rdb,
+		repository.WithExpiredInviteRetention(cfg.Retention.ExpiredInvites),
+	)
+	oauthStateRepo := repository.NewOAuthStateRepository(rdb)
+	flags := featureflags.NewStore(rdb, cfg.Features)
+	activityTracker := activity.NewTracker(rdb, userRepo, cfg.Account.LastSeenInterval)
+	keyUsage := usage.NewMeter(rdb, cfg.Usage)
+	sloRecorder := slo.NewRecorder(rdb, cfg.SLO)
+	reportRepo := repository.NewReportRepository(
+		db,
+		repository.WithReportClaimLease(cfg.Reports.ClaimLease),
+	)
+	objects, err := objectstore.New(cfg.ObjectStorage)
+	if err != nil {
+		log.Fatalf("failed to create object store: %v", err)
+	}
+	mail, err := mailer.NewMailer(cfg.Mailer)
+	if err != nil {
+		log.Fatalf("failed to create mailer: %v", err)
+	}
+	reportSigner, err := report.NewSigner(cfg.Reports.SigningKey)
+	if err != nil {
+		log.Fatalf("failed to create report signer: %v", err)
+	}
+	prefsRepo := repository.NewNotificationPreferencesRepository(db)
+	userService := service.NewUserService(
+		userRepo,
+		sessionRepo,
+		auditRepo,
+		emailChangeRepo,
+		roleVersionRepo,
+		tenantSettings,
+		inviteRepo,
+		oauthStateRepo,
+		prefsRepo,
+		mail,
+		flags,
+		keyUsage,
+		reportRepo,
+		objects,
+		reportSigner,
+		throttle.NewRateLimiter(rdb, "user_search", cfg.Account.UserSearchPerMinute, time.Minute),
+		usage.NewEnumerationQuota(rdb, cfg.Enumeration),
+		sloRecorder,
+	)
+	httpClient, err := outbound.NewClient(cfg.Outbound)
+	if err != nil {
+		log.Fatalf("invalid outbound configuration: %v", err)
+	}
+	authService := service.NewAuthService(
+		db,
+		rdb,
+		auditRepo,
+		tenantSettings,
+		flags,
+		mail,
+		service.WithUserRepository(userRepo),
+		service.WithHTTPClient(httpClient),
+	)
+
+	// Keep the RBAC policy in sync across instances; SIGHUP reloads it everywhere,
+	// along with the log level of this instance
+	policyWatcher, err := auth.NewRedisWatcher(context.Background(), rdb)
+	if err != nil {
+		log.Fatalf("failed to create policy watcher: %v", err)
+	}
+	if err := auth.WatchPolicy(policyWatcher); err != nil {
+		slog.Error("failed to watch policy updates", "error", err)
+	}
+	go reloadOnSIGHUP()
+
+	// Start background jobs. Purges delete the provider tokens stored before,
+	// even once auth.store_provider_tokens is off
+	providerTokenRepo := repository.NewProviderTokenRepository(db, cfg.Auth.ProviderTokenKey)
+	jobRunner := job.NewRunner()
+	jobRunner.Register(
+		job.NewAccountPurgeJob(
+			userRepo,
+			auditRepo,
+			sessionRepo,
+			providerTokenRepo,
+			prefsRepo,
+			cfg.Audit.PseudonymizationKey,
+		),
+		cfg.Account.PurgeInterval,
+	)
+	jobRunner.Register(
+		job.NewRetentionJob(
+			auditRepo,
+			userRepo,
+			sessionRepo,
+			providerTokenRepo,
+			prefsRepo,
+			inviteRepo,
+			cfg.Audit,
+			cfg.Retention,
 		),
+		cfg.Audit.RetentionInterval,
 	)
+	var anchors objectstore.Store
+	if cfg.Audit.AnchorEnabled {
+		anchors = objects
+	}
+	jobRunner.Register(job.NewAuditChainJob(auditRepo, anchors, cfg.Audit), cfg.Audit.ChainInterval)
+	jobRunner.Register(job.NewSessionCleanupJob(sessionRepo), cfg.Session.CleanupInterval)
+	jobRunner.Register(
+		job.NewReportJob(reportRepo, report.NewGenerator(userRepo, auditRepo), objects),
+		cfg.Reports.PollInterval,
+	)
+	if cfg.Account.DormantAfter > 0 {
+		jobRunner.Register(
+			job.NewDormantAccountJob(
+				userRepo,
+				sessionRepo,
+				auditRepo,
+				mail,
+				cfg.Account,
+				cfg.Mailer.LinkBaseURL,
+			),
+			cfg.Account.DormantCheckInterval,
+		)
+	}
+	if keyring != nil && cfg.Encryption.RotationInterval > 0 {
+		jobRunner.Register(
+			job.NewReencryptionJob(
+				repository.NewReencryptionRepository(db, keyring, cfg.Auth.ProviderTokenKey),
+			),
+			cfg.Encryption.RotationInterval,
+		)
+	}
+	jobRunner.Start(context.Background())
+
+	// Expose Prometheus metrics
+	if cfg.Metrics.Port != 0 {
+		go func() {
+			mux := http.NewServeMux()
+			// OpenMetrics carries the exemplars of the SLI metrics to scrapers
+			// asking for it
+			mux.Handle("/metrics", promhttp.InstrumentMetricHandler(
+				prometheus.DefaultRegisterer,
+				promhttp.HandlerFor(
+					prometheus.DefaultGatherer,
+					promhttp.HandlerOpts{EnableOpenMetrics: true},
+				),
+			))
+			slog.Info("metrics server started", "port", cfg.Metrics.Port)
+			err := http.ListenAndServe(fmt.Sprintf(":%d", cfg.Metrics.Port), mux)
+			if err != nil {
+				slog.Error("metrics server stopped", "error", err)
+			}
+		}()
+	}
+
+	// Setup gRPC server with the interceptor chain derived from the config
+	peerGuard, err := server.NewPeerGuard(cfg.Server.PeerGuard)
+	if err != nil {
+		log.Fatalf("invalid peer guard configuration: %v", err)
+	}
+	clientIPResolver, err := clientip.NewResolver(cfg.Server.TrustedProxies)
+	if err != nil {
+		log.Fatalf("invalid trusted proxies: %v", err)
+	}
+	methodAccess, err := auth.NewMethodAccess(cfg.Auth.PublicMethods, cfg.Auth.DisabledMethods)
+	if err != nil {
+		log.Fatalf("invalid method access configuration: %v", err)
+	}
+	internalCredentials, err := auth.NewInternalCredentials(
+		cfg.Auth.InternalToken,
+		cfg.Auth.InternalCredentials,
+	)
+	if err != nil {
+		log.Fatalf("invalid internal credentials: %v", err)
+	}
+	workloadVerifier, err := auth.NewWorkloadVerifier(cfg.Auth.WorkloadIssuers)
+	if err != nil {
+		log.Fatalf("invalid workload issuers: %v", err)
+	}
+	grpcServer := server.NewBuilder(cfg).
+		WithClientIPResolver(clientIPResolver).
+		WithRoleVersions(roleVersionRepo).
+		WithSessionChecker(sessionRepo).
+		WithAuditRepository(auditRepo).
+		WithActivityRecorder(activityTracker).
+		WithUsageMeter(keyUsage).
+		WithPeerGuard(peerGuard).
+		WithMethodAccess(methodAccess).
+		WithInternalCredentials(internalCredentials).
+		WithNonceStore(repository.NewRequestNonceRepository(rdb)).
+		WithWorkloadVerifier(workloadVerifier).
+		WithSLORecorder(sloRecorder).
+		Build()
 	user_v1_pb.RegisterUserServiceServer(grpcServer, userService)
+	user_v1_pb.RegisterTenantSettingsServiceServer(
+		grpcServer,
+		service.NewTenantSettingsService(tenantSettings, auditRepo, cfg.Auth),
+	)
 	auth_v1_pb.RegisterAuthServiceServer(grpcServer, authService)
-	reflection.Register(grpcServer)
+	// Gateways checking the health of their servers skip the ones not serving:
+	// those whose database or Redis is unreachable, and those shutting down
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("failed to get database connection pool: %v", err)
+	}
+	go server.WatchHealth(context.Background(), healthServer, healthInterval, map[string]server.Probe{
+		"database": sqlDB.PingContext,
+		"redis": func(ctx context.Context) error {
+			return rdb.Ping(ctx).Err()
+		},
+	})
+
+	// Serve the gateway from this binary too, if configured
+	var httpServer *http.Server
+	if cfg.Gateway.InProcess {
+		httpServer = serveGateway(cfg, grpcServer)
+	}
 
 	// Start gRPC server
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.Port))
 	if err != nil {
 		log.Fatalf("failed to listen on gRPC port: %v", err)
 	}
+	if cfg.Server.PeerGuard.Enabled {
+		lis = peerGuard.Listener(lis)
+	}
 
-	slog.Info("gRPC server started", "port", cfg.Server.Port)
+	build := buildinfo.Get()
+	slog.Info("gRPC server started", "port", cfg.Server.Port, "version", build.Version,
+		"commit", build.Commit)
+	go stopOnSignal(grpcServer, healthServer, httpServer)
 	if err := grpcServer.Serve(lis); err != nil {
 		log.Fatalf("failed to serve gRPC: %v", err)
 	}
+	stopSIEM()
+	if exporter != nil {
+		exporter.Wait()
+	}
+	slog.Info("gRPC server stopped")
+}
+
+// stopOnSignal stops the server on SIGINT or SIGTERM, letting the calls in
+// flight finish for up to shutdownTimeout. The server reports itself as not
+// serving from then on, so gateways move new calls to other servers. The
+// in-process gateway's httpServer, if not nil, stops first, so that its
+// requests in flight still reach the services.
+func stopOnSignal(grpcServer *grpc.Server, healthServer *health.Server, httpServer *http.Server) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+	slog.Info("stopping gRPC server")
+	healthServer.Shutdown()
+	if httpServer != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			slog.Error("failed to stop HTTP gateway", "error", err)
+		}
+	}
+	timer := time.AfterFunc(shutdownTimeout, grpcServer.Stop)
+	defer timer.Stop()
+	grpcServer.GracefulStop()
+}
+
+func reloadOnSIGHUP() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		cwd, _ := os.Getwd()
+		if err := logctx.ReloadLevel(filepath.Join(cwd, "configs")); err != nil {
+			slog.Error("failed to reload log level", "error", err)
+		}
+		if err := auth.ReloadPolicy(); err != nil {
+			slog.Error("failed to reload policy", "error", err)
+			continue
+		}
+		slog.Info("policy reloaded")
+	}
 }
 
 func NewDB(cfg configs.Config) *gorm.DB {
@@ -0,0 +1,243 @@
+// Command loadtest drives the token hot path of a running grpc-server and
+// reports throughput and latency percentiles.
+//
+// It replays the sessions written by cmd/seed -sessions-file: every worker
+// picks sessions round robin and either exchanges them for access tokens
+// (GetUserToken) or calls GetCurrentUser with a token obtained up front, which
+// exercises the interceptor's token validation and RBAC check.
+//
+//	go run ./cmd/seed -sessions-file sessions.csv
+//	go run ./cmd/loadtest -sessions-file sessions.csv -rpc token -duration 30s
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	rpcToken = "token"
+	rpcMe    = "me"
+)
+
+type options struct {
+	addr         string
+	sessionsFile string
+	rpc          string
+	concurrency  int
+	duration     time.Duration
+}
+
+// result is what a worker measured.
+type result struct {
+	latencies []time.Duration
+	errors    map[string]int
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.addr, "addr", "localhost:50051", "address of the grpc-server")
+	flag.StringVar(
+		&opts.sessionsFile,
+		"sessions-file",
+		"sessions.csv",
+		"CSV of email,session_id lines as written by cmd/seed",
+	)
+	flag.StringVar(
+		&opts.rpc,
+		"rpc",
+		rpcToken,
+		`"token" calls GetUserToken, "me" calls GetCurrentUser with a user token`,
+	)
+	flag.IntVar(&opts.concurrency, "concurrency", 16, "number of concurrent workers")
+	flag.DurationVar(&opts.duration, "duration", 30*time.Second, "how long to send requests")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(opts options) error {
+	if opts.rpc != rpcToken && opts.rpc != rpcMe {
+		return fmt.Errorf("unknown rpc %q", opts.rpc)
+	}
+	if opts.concurrency < 1 {
+		return errors.New("concurrency must be at least 1")
+	}
+	sessions, err := readSessions(opts.sessionsFile)
+	if err != nil {
+		return fmt.Errorf("failed to read sessions: %w", err)
+	}
+
+	conn, err := grpc.NewClient(opts.addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", opts.addr, err)
+	}
+	defer func() { _ = conn.Close() }()
+	authClient := auth_v1_pb.NewAuthServiceClient(conn)
+	userClient := user_v1_pb.NewUserServiceClient(conn)
+
+	var call func(ctx context.Context, i int) error
+	switch opts.rpc {
+	case rpcToken:
+		call = func(ctx context.Context, i int) error {
+			_, err := authClient.GetUserToken(ctx, &auth_v1_pb.GetUserTokenRequest{
+				SessionId: sessions[i%len(sessions)],
+			})
+			return err
+		}
+	case rpcMe:
+		tokens, err := fetchTokens(authClient, sessions)
+		if err != nil {
+			return err
+		}
+		call = func(ctx context.Context, i int) error {
+			ctx = metadata.AppendToOutgoingContext(
+				ctx,
+				"authorization", "Bearer "+tokens[i%len(tokens)],
+			)
+			_, err := userClient.GetCurrentUser(ctx, &user_v1_pb.GetCurrentUserRequest{})
+			return err
+		}
+	}
+
+	fmt.Printf("%s: %d workers, %d sessions, %s\n",
+		opts.rpc, opts.concurrency, len(sessions), opts.duration)
+	ctx, cancel := context.WithTimeout(context.Background(), opts.duration)
+	defer cancel()
+	start := time.Now()
+	results := make([]result, opts.concurrency)
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[w] = work(ctx, call, &next)
+		}()
+	}
+	wg.Wait()
+	report(os.Stdout, results, time.Since(start))
+	return nil
+}
+
+// work calls the RPC until ctx is done. Calls cut short by the end of the run
+// are not counted.
+func work(ctx context.Context, call func(context.Context, int) error, next *atomic.Int64) result {
+	res := result{errors: make(map[string]int)}
+	for ctx.Err() == nil {
+		i := int(next.Add(1))
+		began := time.Now()
+		err := call(ctx, i)
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			res.errors[status.Code(err).String()]++
+			continue
+		}
+		res.latencies = append(res.latencies, time.Since(began))
+	}
+	return res
+}
+
+// fetchTokens exchanges every session for an access token. Tokens expire after
+// auth.access_token_lifetime_minutes, so keep runs shorter than that.
+func fetchTokens(client auth_v1_pb.AuthServiceClient, sessions []string) ([]string, error) {
+	tokens := make([]string, 0, len(sessions))
+	for _, sessionID := range sessions {
+		resp, err := client.GetUserToken(
+			context.Background(),
+			&auth_v1_pb.GetUserTokenRequest{SessionId: sessionID},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get a token for a seeded session: %w", err)
+		}
+		tokens = append(tokens, resp.Token.Token)
+	}
+	return tokens, nil
+}
+
+func readSessions(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var sessions []string
+	for i, row := range rows {
+		if i == 0 && row[0] == "email" {
+			continue
+		}
+		if len(row) < 2 {
+			return nil, fmt.Errorf("line %d: expected email,session_id", i+1)
+		}
+		sessions = append(sessions, row[1])
+	}
+	if len(sessions) == 0 {
+		return nil, errors.New("no sessions; run cmd/seed with -sessions-file first")
+	}
+	return sessions, nil
+}
+
+func report(w io.Writer, results []result, elapsed time.Duration) {
+	var latencies []time.Duration
+	errs := make(map[string]int)
+	for _, res := range results {
+		latencies = append(latencies, res.latencies...)
+		for code, n := range res.errors {
+			errs[code] += n
+		}
+	}
+	slices.Sort(latencies)
+
+	_, _ = fmt.Fprintf(w, "requests:   %d ok, %d failed in %s\n",
+		len(latencies), sum(errs), elapsed.Round(time.Millisecond))
+	_, _ = fmt.Fprintf(w, "throughput: %.0f req/s\n", float64(len(latencies))/elapsed.Seconds())
+	if len(latencies) > 0 {
+		_, _ = fmt.Fprintf(w, "latency:    p50 %s  p95 %s  p99 %s  max %s\n",
+			percentile(latencies, 50),
+			percentile(latencies, 95),
+			percentile(latencies, 99),
+			latencies[len(latencies)-1])
+	}
+	for code, n := range errs {
+		_, _ = fmt.Fprintf(w, "errors:     %s x%d\n", code, n)
+	}
+}
+
+// percentile returns the p-th percentile of sorted latencies (nearest rank).
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (len(sorted)*p + 99) / 100
+	return sorted[max(rank-1, 0)]
+}
+
+func sum(counts map[string]int) int {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	tests := []struct {
+		p    int
+		want time.Duration
+	}{
+		{50, 50 * time.Millisecond},
+		{95, 95 * time.Millisecond},
+		{99, 99 * time.Millisecond},
+		{100, 100 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := percentile(latencies, tt.p); got != tt.want {
+			t.Errorf("p%d: expected %s, got %s", tt.p, tt.want, got)
+		}
+	}
+	if got := percentile(latencies[:1], 99); got != time.Millisecond {
+		t.Errorf("expected the only latency for a single sample, got %s", got)
+	}
+}
+
+func TestReadSessions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.csv")
+	content := "email,session_id\na@example.test,session-a\nb@example.test,session-b\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := readSessions(path)
+	if err != nil {
+		t.Fatalf("readSessions failed: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0] != "session-a" || sessions[1] != "session-b" {
+		t.Errorf("expected both sessions, got %v", sessions)
+	}
+
+	if err := os.WriteFile(path, []byte("email,session_id\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readSessions(path); err == nil {
+		t.Error("expected an error for a file without sessions")
+	}
+}
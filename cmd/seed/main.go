@@ -0,0 +1,252 @@
+// Command seed fills a development database with fake users and login
+// sessions for UI development and load testing.
+//
+// Seeded users get deterministic email addresses, so running the command again
+// only adds missing users; the sessions of seeded users are replaced on every
+// run. It refuses to run in production mode or against a database that is not
+// on this machine, unless the host is explicitly allowed with -allow-host.
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/fieldcrypt"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"github.com/poly-workshop/go-webmods/app"
+	"github.com/poly-workshop/go-webmods/gorm_client"
+	"github.com/poly-workshop/go-webmods/redis_client"
+	"gorm.io/gorm"
+)
+
+// seedEmailDomain is reserved for testing (RFC 2606), so seeded addresses never reach anyone.
+const seedEmailDomain = "example.test"
+
+var (
+	firstNames = []string{"Ada", "Alan", "Barbara", "Dennis", "Edsger", "Frances", "Grace", "Ken"}
+	lastNames  = []string{"Allen", "Hopper", "Knuth", "Lamport", "Liskov", "Ritchie", "Thompson"}
+)
+
+type options struct {
+	users        int
+	admins       int
+	sessions     int
+	password     string
+	sessionsFile string
+	allowHosts   []string
+}
+
+func init() {
+	cwd, _ := os.Getwd()
+	app.SetCMDName("seed")
+	app.Init(cwd)
+}
+
+func main() {
+	var opts options
+	var allowHosts string
+	flag.IntVar(&opts.users, "users", 50, "number of users with the user role")
+	flag.IntVar(&opts.admins, "admins", 2, "number of users with the admin role")
+	flag.IntVar(&opts.sessions, "sessions", 1, "login sessions per seeded user")
+	flag.StringVar(&opts.password, "password", "password", "password of every seeded user")
+	flag.StringVar(
+		&opts.sessionsFile,
+		"sessions-file",
+		"",
+		"write email,session_id lines of the created sessions to this CSV file",
+	)
+	flag.StringVar(
+		&allowHosts,
+		"allow-host",
+		"",
+		"comma separated database hosts besides this machine that may be seeded",
+	)
+	flag.Parse()
+	if allowHosts != "" {
+		opts.allowHosts = strings.Split(allowHosts, ",")
+	}
+
+	cfg := configs.Load()
+	if err := checkSafeTarget(os.Getenv("MODE"), cfg.Database, opts.allowHosts); err != nil {
+		log.Fatalf("refusing to seed: %v", err)
+	}
+	// Seed users the way the server stores them
+	keyring, err := fieldcrypt.NewKeyring(cfg.Encryption)
+	if err != nil {
+		log.Fatalf("invalid encryption configuration: %v", err)
+	}
+	fieldcrypt.Install(keyring)
+
+	db := gorm_client.NewDB(cfg.Database)
+	err = db.AutoMigrate(
+		&model.UserModel{},
+		&model.AuditEventModel{},
+		&model.AuditChainHeadModel{},
+		&model.ProviderTokenModel{},
+		&model.NotificationPreferencesModel{},
+	)
+	if err != nil {
+		log.Fatalf("failed to migrate database: %v", err)
+	}
+	userRepo, err := repository.OpenUserRepository(db, cfg.UserStore)
+	if err != nil {
+		log.Fatalf("failed to open user store: %v", err)
+	}
+	redis_client.SetConfig(cfg.Redis.Urls, cfg.Redis.Password)
+
+	s := &seeder{
+		userRepo:    userRepo,
+		sessionRepo: repository.NewSessionRepository(redis_client.GetRDB()),
+		cfg:         cfg,
+		opts:        opts,
+	}
+	if err := s.run(context.Background()); err != nil {
+		log.Fatalf("failed to seed: %v", err)
+	}
+}
+
+// checkSafeTarget guards against seeding a production database: the mode must
+// not be production and the database must be SQLite or on this machine, unless
+// its host is explicitly allowed.
+func checkSafeTarget(mode string, db gorm_client.Config, allowHosts []string) error {
+	if strings.EqualFold(mode, "production") {
+		return errors.New("MODE is production")
+	}
+	if db.Driver == "sqlite" {
+		return nil
+	}
+	if db.Host == "localhost" || slices.Contains(allowHosts, db.Host) {
+		return nil
+	}
+	if ip := net.ParseIP(db.Host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("database host %q is not local, pass -allow-host to seed it anyway", db.Host)
+}
+
+type seeder struct {
+	userRepo    repository.UserRepository
+	sessionRepo repository.SessionRepository
+	cfg         configs.Config
+	opts        options
+}
+
+func (s *seeder) run(ctx context.Context) error {
+	// Hashing is deliberately slow, so every user shares one hash
+	hashedPassword, err := utils.HashPassword(s.opts.password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	var users []*model.UserModel
+	created := 0
+	for _, spec := range []struct {
+		prefix string
+		role   model.UserRole
+		count  int
+	}{
+		{prefix: "admin", role: model.UserRoleAdmin, count: s.opts.admins},
+		{prefix: "user", role: model.UserRoleUser, count: s.opts.users},
+	} {
+		for i := 1; i <= spec.count; i++ {
+			user, isNew, err := s.ensureUser(ctx, spec.prefix, spec.role, i, hashedPassword)
+			if err != nil {
+				return err
+			}
+			if isNew {
+				created++
+			}
+			users = append(users, user)
+		}
+	}
+	slog.Info("seeded users", "created", created, "existing", len(users)-created)
+
+	var rows [][]string
+	for _, user := range users {
+		if _, err := s.sessionRepo.DeleteByUserID(ctx, user.ID); err != nil {
+			return fmt.Errorf("failed to delete sessions of %s: %w", user.Email, err)
+		}
+		ttl := s.cfg.Session.ExpirationFor(string(user.Role))
+		for range s.opts.sessions {
+			sessionID, err := s.sessionRepo.Create(ctx, user.ID, ttl)
+			if err != nil {
+				return fmt.Errorf("failed to create session of %s: %w", user.Email, err)
+			}
+			rows = append(rows, []string{user.Email, sessionID})
+		}
+	}
+	slog.Info("seeded sessions", "count", len(rows))
+
+	if s.opts.sessionsFile != "" {
+		if err := writeCSV(s.opts.sessionsFile, rows); err != nil {
+			return fmt.Errorf("failed to write sessions file: %w", err)
+		}
+		slog.Info("sessions written", "file", s.opts.sessionsFile)
+	}
+	return nil
+}
+
+// ensureUser returns the seeded user with the given index, creating it if it
+// does not exist yet.
+func (s *seeder) ensureUser(
+	ctx context.Context,
+	prefix string,
+	role model.UserRole,
+	index int,
+	hashedPassword string,
+) (*model.UserModel, bool, error) {
+	email := fmt.Sprintf("seed-%s-%03d@%s", prefix, index, seedEmailDomain)
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err == nil {
+		return user, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, fmt.Errorf("failed to query %s: %w", email, err)
+	}
+
+	user = &model.UserModel{
+		Name:           fakeName(index),
+		Email:          email,
+		HashedPassword: &hashedPassword,
+		Role:           role,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, false, fmt.Errorf("failed to create %s: %w", email, err)
+	}
+	return user, true, nil
+}
+
+func fakeName(index int) string {
+	first := firstNames[index%len(firstNames)]
+	last := lastNames[(index/len(firstNames))%len(lastNames)]
+	return first + " " + last
+}
+
+func writeCSV(path string, rows [][]string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	if err := w.Write([]string{"email", "session_id"}); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := w.WriteAll(rows); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
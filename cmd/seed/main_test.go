@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/poly-workshop/go-webmods/gorm_client"
+)
+
+func TestCheckSafeTarget(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		db         gorm_client.Config
+		allowHosts []string
+		safe       bool
+	}{
+		{name: "sqlite", db: gorm_client.Config{Driver: "sqlite"}, safe: true},
+		{name: "localhost", db: gorm_client.Config{Driver: "postgres", Host: "localhost"}, safe: true},
+		{name: "loopback", db: gorm_client.Config{Driver: "postgres", Host: "127.0.0.1"}, safe: true},
+		{name: "remote", db: gorm_client.Config{Driver: "postgres", Host: "db.example.com"}},
+		{
+			name:       "allowed remote",
+			db:         gorm_client.Config{Driver: "postgres", Host: "postgres"},
+			allowHosts: []string{"postgres"},
+			safe:       true,
+		},
+		{name: "production", mode: "production", db: gorm_client.Config{Driver: "sqlite"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSafeTarget(tt.mode, tt.db, tt.allowHosts)
+			if (err == nil) != tt.safe {
+				t.Errorf("expected safe=%v, got %v", tt.safe, err)
+			}
+		})
+	}
+}
@@ -1,29 +1,292 @@
 package configs
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/poly-workshop/go-webmods/app"
 	"github.com/poly-workshop/go-webmods/gorm_client"
 	"github.com/poly-workshop/go-webmods/redis_client"
+	"github.com/spf13/viper"
 )
 
 // Configuration keys constants
 const (
 	// Server configuration keys
-	ServerPortKey     = "server.port"
-	ServerHTTPPortKey = "server.http_port"
+	ServerPortKey               = "server.port"
+	ServerHTTPPortKey           = "server.http_port"
+	ServerRateLimitPerSecondKey = "server.rate_limit_per_second"
+	ServerRateLimitBurstKey     = "server.rate_limit_burst"
+	ServerRPCTimeoutKey         = "server.rpc_timeout_seconds"
+	ServerRPCTimeoutByMethodKey = "server.rpc_timeout_seconds_by_method"
+	ServerTrustedProxiesKey     = "server.trusted_proxies"
+	// Peer guard configuration keys
+	ServerPeerGuardEnabledKey       = "server.peer_guard.enabled"
+	ServerPeerGuardLimitKey         = "server.peer_guard.limit"
+	ServerPeerGuardWindowSecondsKey = "server.peer_guard.window_seconds"
+	ServerPeerGuardBlockSecondsKey  = "server.peer_guard.block_seconds"
+	ServerPeerGuardExemptCIDRsKey   = "server.peer_guard.exempt_cidrs"
 
 	// Auth configuration keys
-	AuthInternalTokenKey               = "auth.internal_token"
-	AuthJWTSecretKey                   = "auth.jwt_secret"
-	AuthGithubClientIDKey              = "auth.github_client_id"
-	AuthGithubClientSecretKey          = "auth.github_client_secret"
-	AuthGithubRedirectURLKey           = "auth.github_redirect_url"
-	AuthOAuthStateExpirationMinutesKey = "auth.oauth_state_expiration_minutes"
+	AuthInternalTokenKey                = "auth.internal_token"
+	AuthInternalCredentialsKey          = "auth.internal_credentials"
+	AuthSignedRequestMaxSkewSecondsKey  = "auth.signed_request_max_skew_seconds"
+	AuthWorkloadIssuersKey              = "auth.workload_issuers"
+	AuthAuthzDecisionLogKey             = "auth.authz_decision_log"
+	AuthAuthzDryRunKey                  = "auth.authz_dry_run"
+	AuthJWTSecretKey                    = "auth.jwt_secret"
+	AuthJWTIssuerKey                    = "auth.jwt_issuer"
+	AuthJWTAudienceKey                  = "auth.jwt_audience"
+	AuthJWTRejectLegacyTokensKey        = "auth.jwt_reject_legacy_tokens"
+	AuthJWTValidMethodsKey              = "auth.jwt_valid_methods"
+	AuthJWTLeewaySecondsKey             = "auth.jwt_leeway_seconds"
+	AuthAccessTokenLifetimeMinutesKey   = "auth.access_token_lifetime_minutes"
+	AuthAccessTokenLifetimeByRoleKey    = "auth.access_token_lifetime_minutes_by_role"
+	AuthTokenScopesKey                  = "auth.token_scopes"
+	AuthLoginGenericErrorsKey           = "auth.login_generic_errors"
+	AuthLoginTarpitMinMillisKey         = "auth.login_tarpit_min_ms"
+	AuthLoginTarpitMaxMillisKey         = "auth.login_tarpit_max_ms"
+	AuthGithubClientIDKey               = "auth.github_client_id"
+	AuthGithubClientSecretKey           = "auth.github_client_secret"
+	AuthGithubRedirectURLKey            = "auth.github_redirect_url"
+	AuthGithubBaseURLKey                = "auth.github_base_url"
+	AuthGithubAllowedOrgsKey            = "auth.github_allowed_orgs"
+	AuthGithubAllowedTeamsKey           = "auth.github_allowed_teams"
+	AuthOAuthStateExpirationMinutesKey  = "auth.oauth_state_expiration_minutes"
+	AuthOAuthCodeReplayWindowMinutesKey = "auth.oauth_code_replay_window_minutes"
+	AuthIdempotencyKeyTTLSecondsKey     = "auth.idempotency_key_ttl_seconds"
+	AuthAllowedRedirectURLsKey          = "auth.allowed_redirect_urls"
+	AuthOAuthStateBindingKey            = "auth.oauth_state_binding"
+	AuthOAuthStateIPMatchKey            = "auth.oauth_state_ip_match"
+	AuthStoreProviderTokensKey          = "auth.store_provider_tokens"
+	AuthProviderTokenKeyKey             = "auth.provider_token_key"
+	AuthGithubExtraScopesKey            = "auth.github_extra_scopes"
+	AuthDeviceCodeExpirationMinutesKey  = "auth.device_code_expiration_minutes"
+	AuthDevicePollIntervalSecondsKey    = "auth.device_poll_interval_seconds"
+	AuthMagicLinkExpirationMinutesKey   = "auth.magic_link_expiration_minutes"
+	AuthMagicLinksPerHourKey            = "auth.magic_links_per_hour"
+	AuthHandoffExpirationSecondsKey     = "auth.handoff_expiration_seconds"
+	AuthPublicMethodsKey                = "auth.public_methods"
+	AuthDisabledMethodsKey              = "auth.disabled_methods"
 
 	// Session configuration keys
-	SessionExpirationHoursKey = "session.expiration_hours"
+	SessionExpirationHoursKey   = "session.expiration_hours"
+	SessionBoundTokensKey       = "session.bound_tokens"
+	SessionCheckCacheSecondsKey = "session.check_cache_seconds"
+	SessionExpirationByRoleKey  = "session.expiration_hours_by_role"
+	SessionCleanupIntervalKey   = "session.cleanup_interval_minutes"
+	SessionTTLJitterPercentKey  = "session.ttl_jitter_percent"
+
+	// Account configuration keys
+	AccountDeletionGracePeriodDaysKey    = "account.deletion_grace_period_days"
+	AccountPurgeIntervalMinutesKey       = "account.purge_interval_minutes"
+	AccountEmailChangeExpirationHoursKey = "account.email_change_expiration_hours"
+	AccountEmailChangeRollbackDaysKey    = "account.email_change_rollback_days"
+	AccountAllowedEmailDomainsKey        = "account.allowed_email_domains"
+	AccountDisallowedDomainSignupKey     = "account.disallowed_domain_signup"
+	AccountSignupModeKey                 = "account.signup_mode"
+	AccountInviteExpirationDaysKey       = "account.invite_expiration_days"
+	AccountEmailCheckPerMinuteKey        = "account.email_check_per_minute"
+	AccountEmailCheckCaptchaKey          = "account.email_check_captcha"
+	AccountUserSearchPerMinuteKey        = "account.user_search_per_minute"
+	AccountLastSeenIntervalMinutesKey    = "account.last_seen_interval_minutes"
+	AccountDormantDaysKey                = "account.dormant_days"
+	AccountDormantWarningDaysKey         = "account.dormant_warning_days"
+	AccountDormantCheckIntervalKey       = "account.dormant_check_interval_minutes"
+
+	// CAPTCHA configuration keys
+	CaptchaVerifyURLKey = "captcha.verify_url"
+	CaptchaSecretKey    = "captcha.secret"
+	CaptchaSiteKeyKey   = "captcha.site_key"
+
+	// Mailer configuration keys
+	MailerDriverKey       = "mailer.driver"
+	MailerFromKey         = "mailer.from"
+	MailerLinkBaseURLKey  = "mailer.link_base_url"
+	MailerSMTPHostKey     = "mailer.smtp_host"
+	MailerSMTPPortKey     = "mailer.smtp_port"
+	MailerSMTPUsernameKey = "mailer.smtp_username"
+	MailerSMTPPasswordKey = "mailer.smtp_password"
+
+	// Audit configuration keys
+	AuditPseudonymizationKeyKey      = "audit.pseudonymization_key"
+	AuditRetentionDaysKey            = "audit.retention_days"
+	AuditPIIRetentionDaysKey         = "audit.pii_retention_days"
+	AuditRetentionIntervalMinutesKey = "audit.retention_interval_minutes"
+	AuditChainIntervalMinutesKey     = "audit.chain_interval_minutes"
+	AuditChainFullVerifyHoursKey     = "audit.chain_full_verify_hours"
+	AuditAnchorEnabledKey            = "audit.anchor_enabled"
+
+	// Retention configuration keys
+	RetentionLoginHistoryDaysKey   = "retention.login_history_days"
+	RetentionDeletedUsersDaysKey   = "retention.deleted_users_days"
+	RetentionExpiredInvitesDaysKey = "retention.expired_invites_days"
+
+	// Enumeration quota configuration keys
+	EnumerationDailyUsersKey = "enumeration.daily_users"
+	EnumerationAlertUsersKey = "enumeration.alert_users"
+
+	// Column encryption configuration keys
+	EncryptionKeysKey                    = "encryption.keys"
+	EncryptionIndexKeyKey                = "encryption.index_key"
+	EncryptionEncryptEmailKey            = "encryption.encrypt_email"
+	EncryptionRotationIntervalMinutesKey = "encryption.rotation_interval_minutes"
+
+	// Throttle configuration keys
+	ThrottleEnabledKey          = "throttle.enabled"
+	ThrottleFreeAttemptsKey     = "throttle.free_attempts"
+	ThrottleIPFreeAttemptsKey   = "throttle.ip_free_attempts"
+	ThrottleBaseDelaySecondsKey = "throttle.base_delay_seconds"
+	ThrottleMaxDelaySecondsKey  = "throttle.max_delay_seconds"
+	ThrottleWindowMinutesKey    = "throttle.window_minutes"
+
+	// Risk scoring configuration keys
+	RiskEnabledKey               = "risk.enabled"
+	RiskChallengeThresholdKey    = "risk.challenge_threshold"
+	RiskBlockThresholdKey        = "risk.block_threshold"
+	RiskVelocityWindowMinutesKey = "risk.velocity_window_minutes"
+	RiskVelocityMaxLoginsKey     = "risk.velocity_max_logins"
+	RiskFailedAttemptsKey        = "risk.failed_attempts"
+	RiskHistoryDaysKey           = "risk.history_days"
+
+	// Metrics configuration keys
+	MetricsPortKey = "metrics.port"
+
+	// Log configuration keys
+	LogLevelKey                = "log.level"
+	LogFormatKey               = "log.format"
+	LogWarnSamplesPerMinuteKey = "log.warn_samples_per_minute"
+
+	// Gateway configuration keys
+	GatewayRoutesKey            = "gateway.routes"
+	GatewayProtectedPrefixesKey = "gateway.protected_prefixes"
+	GatewayLoginURLKey          = "gateway.login_url"
+	GatewayCachePathsKey        = "gateway.cache_paths"
+	GatewayCacheTTLSecondsKey   = "gateway.cache_ttl_seconds"
+	GatewayCacheStaleSecondsKey = "gateway.cache_stale_seconds"
+	GatewayH2CKey               = "gateway.h2c"
+	GatewayInProcessKey         = "gateway.in_process"
+	GatewayGRPCTargetKey        = "gateway.grpc_target"
+	GatewayGRPCEndpointsKey     = "gateway.grpc_endpoints"
+	GatewayGRPCHealthCheckKey   = "gateway.grpc_health_check"
+	GatewayGRPCKeepaliveKey     = "gateway.grpc_keepalive_seconds"
+	GatewayGRPCKeepaliveTimeKey = "gateway.grpc_keepalive_timeout_seconds"
+	GatewayGRPCIdleTimeoutKey   = "gateway.grpc_idle_timeout_seconds"
+	GatewayGRPCLoadBalancingKey = "gateway.grpc_load_balancing"
+	GatewayGRPCMaxRetriesKey    = "gateway.grpc_max_retries"
+	GatewayGRPCRetryBackoffKey  = "gateway.grpc_retry_initial_backoff_ms"
+	GatewayGRPCRetryMaxKey      = "gateway.grpc_retry_max_backoff_ms"
+	GatewayGRPCHedgingDelayKey  = "gateway.grpc_hedging_delay_ms"
+	GatewayDefaultTimeoutKey    = "gateway.default_timeout_seconds"
+	GatewayMetricsPortKey       = "gateway.metrics_port"
+	GatewayAssetOriginKey       = "gateway.asset_origin"
+	GatewayBreakerFailuresKey   = "gateway.breaker_failures"
+	GatewayBreakerCooldownKey   = "gateway.breaker_cooldown_seconds"
+	GatewayMaintenanceKey       = "gateway.maintenance"
+	GatewayMaintenancePageKey   = "gateway.maintenance_page"
+	// Gateway security header configuration keys
+	GatewaySecurityHeadersEnabledKey = "gateway.security_headers.enabled"
+	GatewayHSTSMaxAgeSecondsKey      = "gateway.security_headers.hsts_max_age_seconds"
+	GatewayHSTSIncludeSubdomainsKey  = "gateway.security_headers.hsts_include_subdomains"
+	GatewayHSTSPreloadKey            = "gateway.security_headers.hsts_preload"
+	GatewayReferrerPolicyKey         = "gateway.security_headers.referrer_policy"
+	GatewayContentSecurityPolicyKey  = "gateway.security_headers.content_security_policy"
+	GatewayFrameAncestorsKey         = "gateway.security_headers.frame_ancestors"
+
+	// Provider webhook configuration keys
+	WebhooksGithubSecretKey     = "webhooks.github_secret"
+	WebhooksGithubRevocationKey = "webhooks.github_revocation"
+
+	// Login code configuration keys
+	LoginCodeChannelsKey          = "login_code.channels"
+	LoginCodeExpirationMinutesKey = "login_code.expiration_minutes"
+	LoginCodeMaxAttemptsKey       = "login_code.max_attempts"
+	LoginCodeSendsPerHourKey      = "login_code.sends_per_hour"
+	LoginCodeSMSURLKey            = "login_code.sms_url"
+	LoginCodeSMSAuthorizationKey  = "login_code.sms_authorization"
+
+	// User provisioning configuration keys
+	ProvisioningHooksKey                 = "provisioning.hooks"
+	ProvisioningWebhookURLKey            = "provisioning.webhook_url"
+	ProvisioningWebhookSecretKey         = "provisioning.webhook_secret"
+	ProvisioningWebhookTimeoutSecondsKey = "provisioning.webhook_timeout_seconds"
+
+	// Custom claims configuration keys
+	ClaimsCalloutURLKey            = "claims.callout_url"
+	ClaimsCalloutAuthorizationKey  = "claims.callout_authorization"
+	ClaimsCalloutTimeoutSecondsKey = "claims.callout_timeout_seconds"
+	ClaimsMaxBytesKey              = "claims.max_bytes"
+	ClaimsCacheSecondsKey          = "claims.cache_seconds"
+
+	// User info configuration keys
+	UserInfoTimeoutSecondsKey           = "userinfo.timeout_seconds"
+	UserInfoTimeoutSecondsByProviderKey = "userinfo.timeout_seconds_by_provider"
+	UserInfoMaxRetriesKey               = "userinfo.max_retries"
+	UserInfoRetryBackoffMillisecondsKey = "userinfo.retry_backoff_milliseconds"
+	UserInfoBreakerFailuresKey          = "userinfo.breaker_failures"
+	UserInfoBreakerOpenSecondsKey       = "userinfo.breaker_open_seconds"
+
+	// Outbound HTTP configuration keys
+	OutboundProxyURLKey       = "outbound.proxy_url"
+	OutboundCAFileKey         = "outbound.ca_file"
+	OutboundTimeoutSecondsKey = "outbound.timeout_seconds"
+
+	// SLO configuration keys
+	SLOLoginSuccessPercentKey          = "slo.login_success_percent"
+	SLOTokenIssuanceP99MillisecondsKey = "slo.token_issuance_p99_milliseconds"
+	SLOOAuthExchangeSuccessPercentKey  = "slo.oauth_exchange_success_percent"
+
+	// Failpoint configuration keys
+	FailpointsEnabledKey = "failpoints.enabled"
+	FailpointsPointsKey  = "failpoints.points"
+
+	// User store configuration keys
+	UserStoreBackendKey = "user_store.backend"
+	UserStoreOptionsKey = "user_store.options"
+
+	// Object storage configuration keys
+	ObjectStorageProviderKey = "object_storage.provider"
+	ObjectStorageBasePathKey = "object_storage.base_path"
+
+	// Report configuration keys
+	ReportsDownloadBaseURLKey      = "reports.download_base_url"
+	ReportsSigningKeyKey           = "reports.signing_key"
+	ReportsURLExpirationMinutesKey = "reports.url_expiration_minutes"
+	ReportsPollIntervalSecondsKey  = "reports.poll_interval_seconds"
+	ReportsClaimLeaseMinutesKey    = "reports.claim_lease_minutes"
+
+	// Key usage configuration keys
+	UsageEnabledKey = "usage.enabled"
+	UsageQuotasKey  = "usage.quotas"
+
+	// Error reporting configuration keys
+	ErrorReportingDSNKey         = "error_reporting.dsn"
+	ErrorReportingEnvironmentKey = "error_reporting.environment"
+	ErrorReportingReleaseKey     = "error_reporting.release"
+
+	// SIEM export configuration keys
+	SIEMSinkKey                 = "siem.sink"
+	SIEMFormatKey               = "siem.format"
+	SIEMEventTypesKey           = "siem.event_types"
+	SIEMFilePathKey             = "siem.file_path"
+	SIEMSyslogNetworkKey        = "siem.syslog_network"
+	SIEMSyslogAddressKey        = "siem.syslog_address"
+	SIEMHTTPURLKey              = "siem.http_url"
+	SIEMHTTPAuthorizationKey    = "siem.http_authorization"
+	SIEMBufferSizeKey           = "siem.buffer_size"
+	SIEMBatchSizeKey            = "siem.batch_size"
+	SIEMFlushIntervalSecondsKey = "siem.flush_interval_seconds"
+
+	// Feature flag configuration keys
+	FeatureFlagsDefaultsKey     = "feature_flags.defaults"
+	FeatureFlagsCacheSecondsKey = "feature_flags.cache_seconds"
 
 	// Database configuration keys
 	DatabaseDriverKey   = "gorm_client.database.driver"
@@ -39,62 +302,1434 @@ const (
 	RedisPasswordKey = "redis.password"
 )
 
+// OAuth state binding modes
+const (
+	// StateBindingOff ignores client mismatches between the code URL request and the callback
+	StateBindingOff = "off"
+	// StateBindingWarn records mismatches as audit events but lets the login continue
+	StateBindingWarn = "warn"
+	// StateBindingEnforce records mismatches and rejects the login
+	StateBindingEnforce = "enforce"
+)
+
+// OAuth state IP match modes
+const (
+	// StateIPMatchExact requires the same IP address
+	StateIPMatchExact = "exact"
+	// StateIPMatchPrefix requires the same network (IPv4 /24, IPv6 /48)
+	StateIPMatchPrefix = "prefix"
+)
+
+// Handling of signups outside the allowed email domains
+const (
+	// DomainSignupReject refuses the signup
+	DomainSignupReject = "reject"
+	// DomainSignupApproval creates the account but blocks logins until an admin approves it
+	DomainSignupApproval = "approval"
+)
+
+// Signup modes
+const (
+	// SignupModeOpen lets anyone sign up, subject to the email domain restriction
+	SignupModeOpen = "open"
+	// SignupModeInviteOnly only lets invited email addresses sign up
+	SignupModeInviteOnly = "invite_only"
+	// SignupModeClosed refuses every new registration, including OAuth auto-provisioning
+	SignupModeClosed = "closed"
+)
+
+// Authentication of requests the gateway proxies to other backends
+const (
+	// GatewayAuthRequired rejects requests without a valid user token
+	GatewayAuthRequired = "required"
+	// GatewayAuthOptional also forwards anonymous requests, identifying users with valid tokens
+	GatewayAuthOptional = "optional"
+	// GatewayAuthNone forwards requests without looking at tokens
+	GatewayAuthNone = "none"
+)
+
+// UserStoreDatabase keeps users in the database, the only user store backend
+// built in; builds of the server can register others (see
+// repository.RegisterUserBackend)
+const UserStoreDatabase = "database"
+
+// Object storage providers
+const (
+	// ObjectStorageLocal stores objects as files under the base path
+	ObjectStorageLocal = "local"
+)
+
+// Load balancing policies of the gateway's gRPC client
+const (
+	// LoadBalancingPickFirst sends all calls over one connection
+	LoadBalancingPickFirst = "pick_first"
+	// LoadBalancingRoundRobin spreads calls over all addresses the target resolves to
+	LoadBalancingRoundRobin = "round_robin"
+)
+
+// Reactions to a provider revoking a user's authorization
+const (
+	// RevocationRevokeSessions revokes the sessions of the user
+	RevocationRevokeSessions = "revoke_sessions"
+	// RevocationUnlink also unlinks the identity, unless it is the only way the
+	// user can log in
+	RevocationUnlink = "unlink"
+)
+
+// Channels delivering login codes
+const (
+	LoginCodeChannelEmail = "email"
+	LoginCodeChannelSMS   = "sms"
+)
+
+// Formats of the log output
+const (
+	// LogFormatText writes key=value lines
+	LogFormatText = "text"
+	// LogFormatJSON writes a JSON object per line, e.g. for log collectors
+	LogFormatJSON = "json"
+)
+
+// DefaultTrustedProxies are the networks of the proxies whose X-Forwarded-For
+// hops are trusted unless configured otherwise: loopback and private networks,
+// where the gateway usually runs.
+var DefaultTrustedProxies = []string{
+	"127.0.0.0/8",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::1/128",
+	"fc00::/7",
+}
+
+// DefaultPeerGuardExemptCIDRs are the networks exempt from the peer guard
+// unless configured otherwise: loopback and private networks, where gateways
+// and other trusted infrastructure usually run.
+var DefaultPeerGuardExemptCIDRs = []string{
+	"127.0.0.0/8",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::1/128",
+	"fc00::/7",
+}
+
+// MinGRPCKeepalive is the shortest keepalive ping interval the gRPC server
+// accepts from clients; shorter intervals are raised to it.
+const MinGRPCKeepalive = 10 * time.Second
+
+// Forms of the scope claim in user tokens
+const (
+	// TokenScopesOff leaves the scope claim out to keep tokens small
+	TokenScopesOff = "off"
+	// TokenScopesNames lists the granted policy objects, e.g. "/UserService/GetUser"
+	TokenScopesNames = "names"
+	// TokenScopesHashed lists short hashes of the granted policy objects
+	TokenScopesHashed = "hashed"
+)
+
+// Authorization decisions of the RBAC policy that are logged
+const (
+	// AuthzDecisionLogOff logs no decisions
+	AuthzDecisionLogOff = "off"
+	// AuthzDecisionLogDenials logs the calls the policy denies
+	AuthzDecisionLogDenials = "denials"
+	// AuthzDecisionLogAll logs every decision
+	AuthzDecisionLogAll = "all"
+)
+
 // Default values constants
 const (
-	DefaultJWTSecret                   = "default_jwt_secret_change_in_production"
-	DefaultSessionExpirationHours      = 24
-	DefaultOAuthStateExpirationMinutes = 10
+	DefaultJWTSecret                      = "default_jwt_secret_change_in_production"
+	DefaultJWTIssuer                      = "auth-portal"
+	DefaultJWTAudience                    = "auth-portal"
+	DefaultJWTValidMethod                 = "HS256"
+	DefaultJWTLeewaySeconds               = 30
+	DefaultSignedRequestMaxSkewSeconds    = 300
+	DefaultAccessTokenLifetimeMinutes     = 15
+	DefaultSessionExpirationHours         = 24
+	DefaultRPCTimeoutSeconds              = 30
+	DefaultPeerGuardLimit                 = 50
+	DefaultPeerGuardWindowSeconds         = 60
+	DefaultPeerGuardBlockSeconds          = 300
+	DefaultLogWarnSamplesPerMinute        = 10
+	DefaultSessionCheckCacheSeconds       = 2
+	DefaultSessionCleanupIntervalMinutes  = 30
+	DefaultSessionTTLJitterPercent        = 5
+	DefaultOAuthStateExpirationMinutes    = 10
+	DefaultOAuthCodeReplayWindowMinutes   = 15
+	DefaultIdempotencyKeyTTLSeconds       = 60
+	DefaultDeviceCodeExpirationMinutes    = 10
+	DefaultDevicePollIntervalSeconds      = 5
+	DefaultMagicLinkExpirationMinutes     = 15
+	DefaultMagicLinksPerHour              = 5
+	DefaultHandoffExpirationSeconds       = 60
+	DefaultLoginCodeExpirationMinutes     = 10
+	DefaultLoginCodeMaxAttempts           = 5
+	DefaultLoginCodeSendsPerHour          = 5
+	DefaultProvisioningWebhookTimeout     = 5
+	DefaultClaimsCalloutTimeoutSeconds    = 2
+	DefaultOutboundTimeoutSeconds         = 10
+	DefaultUserInfoTimeoutSeconds         = 10
+	DefaultUserInfoMaxRetries             = 2
+	DefaultUserInfoRetryBackoffMS         = 200
+	DefaultUserInfoBreakerFailures        = 5
+	DefaultUserInfoBreakerOpenSeconds     = 30
+	DefaultClaimsMaxBytes                 = 1024
+	DefaultClaimsCacheSeconds             = 300
+	DefaultObjectStorageBasePath          = "data/objects"
+	DefaultSLOLoginSuccessPercent         = 99.5
+	DefaultSLOTokenIssuanceP99MS          = 500
+	DefaultSLOOAuthExchangeSuccessPercent = 99.0
+	DefaultReportURLExpirationMinutes     = 15
+	DefaultReportPollIntervalSeconds      = 10
+	DefaultReportClaimLeaseMinutes        = 30
+	DefaultDeletionGracePeriodDays        = 30
+	DefaultAccountPurgeIntervalMinutes    = 60
+	DefaultEmailChangeExpirationHours     = 24
+	DefaultEmailChangeRollbackDays        = 7
+	DefaultInviteExpirationDays           = 14
+	DefaultEmailCheckPerMinute            = 10
+	DefaultUserSearchPerMinute            = 60
+	DefaultLastSeenIntervalMinutes        = 60
+	DefaultDormantWarningDays             = 14
+	DefaultDormantCheckIntervalMinutes    = 60
+	DefaultMailerLinkBaseURL              = "http://localhost:8080"
+	DefaultMailerSMTPPort                 = 587
+	DefaultGatewayLoginURL                = "/login"
+	DefaultGatewayCacheTTLSeconds         = 60
+	DefaultGatewayCacheStaleSeconds       = 300
+	DefaultGatewayGRPCKeepaliveSeconds    = 30
+	DefaultGatewayGRPCKeepaliveTimeout    = 10
+	DefaultGatewayGRPCRetryBackoffMs      = 100
+	DefaultGatewayGRPCRetryMaxBackoffMs   = 1000
+	DefaultGatewayBreakerFailures         = 5
+	DefaultGatewayBreakerCooldownSeconds  = 10
+	DefaultHSTSMaxAgeSeconds              = 365 * 24 * 60 * 60
+	DefaultReferrerPolicy                 = "strict-origin-when-cross-origin"
+	// DefaultContentSecurityPolicy suits the frontend build: scripts, styles and
+	// fonts from the portal itself, images also inlined or from HTTPS (e.g.
+	// provider avatars), and API calls to the portal only
+	DefaultContentSecurityPolicy = "default-src 'self'; script-src 'self'; " +
+		"style-src 'self'; img-src 'self' data: https:; font-src 'self' data:; " +
+		"connect-src 'self'; object-src 'none'; base-uri 'self'; form-action 'self'"
+	DefaultSIEMBufferSize                = 1000
+	DefaultRiskChallengeThreshold        = 50
+	DefaultRiskVelocityWindowMinutes     = 60
+	DefaultRiskVelocityMaxLogins         = 10
+	DefaultRiskFailedAttempts            = 3
+	DefaultRiskHistoryDays               = 90
+	DefaultSIEMBatchSize                 = 100
+	DefaultSIEMFlushIntervalSeconds      = 5
+	DefaultAuditRetentionDays            = 365
+	DefaultAuditPIIRetentionDays         = 90
+	DefaultAuditRetentionIntervalMinutes = 60
+	DefaultAuditChainIntervalMinutes     = 60
+	DefaultAuditChainFullVerifyHours     = 24
+	DefaultLoginHistoryRetentionDays     = 365
+	DefaultDeletedUserRetentionDays      = 30
+	DefaultExpiredInviteRetentionDays    = 30
+	DefaultEnumerationDailyUsers         = 10000
+	DefaultEnumerationAlertUsers         = 2000
+	DefaultEncryptionRotationMinutes     = 60
+	DefaultThrottleFreeAttempts          = 3
+	DefaultThrottleIPFreeAttempts        = 20
+	DefaultThrottleBaseDelaySeconds      = 1
+	DefaultThrottleMaxDelaySeconds       = 300
+	DefaultThrottleWindowMinutes         = 15
+	DefaultFeatureFlagsCacheSeconds      = 5
 )
 
 type Config struct {
-	Server   ServerConfig
-	Auth     AuthConfig
-	Session  SessionConfig
-	Database gorm_client.Config
-	Redis    redis_client.Config
+	Server         ServerConfig
+	Auth           AuthConfig
+	Session        SessionConfig
+	Account        AccountConfig
+	Captcha        CaptchaConfig
+	Audit          AuditConfig
+	Retention      RetentionConfig
+	Enumeration    EnumerationConfig
+	Encryption     EncryptionConfig
+	Mailer         MailerConfig
+	Throttle       ThrottleConfig
+	Risk           RiskConfig
+	Metrics        MetricsConfig
+	Log            LogConfig
+	Gateway        GatewayConfig
+	SIEM           SIEMConfig
+	ErrorReporting ErrorReportingConfig
+	Webhooks       WebhooksConfig
+	LoginCode      LoginCodeConfig
+	Provisioning   ProvisioningConfig
+	Claims         ClaimsConfig
+	Outbound       OutboundConfig
+	UserInfo       UserInfoConfig
+	Usage          UsageConfig
+	SLO            SLOConfig
+	Failpoints     FailpointsConfig
+	UserStore      UserStoreConfig
+	ObjectStorage  ObjectStorageConfig
+	Reports        ReportsConfig
+	Features       FeatureFlagsConfig
+	Database       gorm_client.Config
+	Redis          redis_client.Config
 }
 
 type ServerConfig struct {
 	Port     uint
 	HTTPPort uint
+	// RateLimitPerSecond caps the calls the gRPC server accepts per second
+	// across all clients, bursting up to RateLimitBurst; 0 disables the limit
+	RateLimitPerSecond int
+	RateLimitBurst     int
+	// RPCTimeout bounds every unary call (0 leaves calls unbounded);
+	// RPCTimeoutByMethod overrides it by lower-cased method name, e.g. "listusers",
+	// and is the only bound of streams. Shorter deadlines set by clients are kept.
+	RPCTimeout         time.Duration
+	RPCTimeoutByMethod map[string]time.Duration
+	// TrustedProxies are the networks of the proxies, such as the gateway,
+	// whose X-Forwarded-For hops name the client; calls of other peers come
+	// from the peer itself
+	TrustedProxies []string
+	PeerGuard      PeerGuardConfig
+}
+
+// PeerGuardConfig protects the gRPC port from peers calling it directly: a
+// peer whose calls fail as unauthenticated more than Limit times within Window
+// is blocked for BlockFor.
+type PeerGuardConfig struct {
+	Enabled  bool
+	Limit    int
+	Window   time.Duration
+	BlockFor time.Duration
+	// ExemptCIDRs are the networks never blocked, such as the gateway's, whose
+	// calls carry the failures of all of its clients
+	ExemptCIDRs []string
 }
 
 type AuthConfig struct {
-	InternalToken                string
-	JWTSecret                    string
-	GithubClientID               string
-	GithubClientSecret           string
-	GithubRedirectURL            string
+	// InternalToken is the plaintext token of the "internal" credential, which
+	// may call every RPC; the gateway calls internal RPCs with it
+	InternalToken string
+	JWTSecret     string
+	JWTIssuer     string
+	JWTAudience   string
+	// JWTRejectLegacyTokens rejects tokens issued without iss and aud claims
+	JWTRejectLegacyTokens bool
+	// JWTValidMethods are the signing algorithms accepted when validating tokens
+	JWTValidMethods []string
+	// JWTLeeway is the clock skew tolerated when checking exp, nbf and iat
+	JWTLeeway time.Duration
+	// AccessTokenLifetime is how long tokens from GetUserToken are valid, capped
+	// by the expiration of the session they were issued for
+	AccessTokenLifetime time.Duration
+	// AccessTokenLifetimeByRole overrides AccessTokenLifetime for users of a role
+	AccessTokenLifetimeByRole map[string]time.Duration
+	// TokenScopes is the form of the scope claim derived from the RBAC policy
+	TokenScopes string
+	// LoginGenericErrors answers every failed password login with the same
+	// status, so responses do not reveal whether an account exists
+	LoginGenericErrors bool
+	// LoginTarpitMin and LoginTarpitMax bound a random delay added to failed
+	// password logins; a zero max disables it
+	LoginTarpitMin     time.Duration
+	LoginTarpitMax     time.Duration
+	GithubClientID     string
+	GithubClientSecret string
+	GithubRedirectURL  string
+	// GithubBaseURL is the URL of a GitHub Enterprise Server (or a stub in tests);
+	// empty uses github.com
+	GithubBaseURL string
+	// GithubAllowedOrgs and GithubAllowedTeams ("org/team-slug") restrict GitHub
+	// logins to their members; both empty allows every GitHub user
+	GithubAllowedOrgs            []string
+	GithubAllowedTeams           []string
 	OAuthStateExpirationDuration time.Duration
+	// OAuthCodeReplayWindow is how long used authorization codes are remembered
+	OAuthCodeReplayWindow time.Duration
+	// IdempotencyKeyTTL is how long the response of a login sent with an
+	// Idempotency-Key is answered again to the same request (0 = never)
+	IdempotencyKeyTTL time.Duration
+	// AllowedRedirectURLs are the redirect URLs clients may request besides the
+	// provider's own redirect URL; entries may be patterns (see utils.MatchRedirectURL)
+	AllowedRedirectURLs []string
+	// OAuthStateBinding is how user agent and IP mismatches of an OAuth state are handled
+	OAuthStateBinding string
+	// OAuthStateIPMatch is how strictly the IP address of an OAuth state is compared
+	OAuthStateIPMatch string
+	// StoreProviderTokens keeps the provider tokens of OAuth logins, encrypted
+	// with the encryption keys if set and with ProviderTokenKey otherwise, for
+	// GetProviderToken
+	StoreProviderTokens bool
+	ProviderTokenKey    string
+	// GithubExtraScopes are requested at login besides those needed to log in,
+	// for the services calling GitHub with the stored tokens
+	GithubExtraScopes []string
+	// DeviceCodeExpiration is how long a device login waits for the user's
+	// approval; devices poll at most every DevicePollInterval
+	DeviceCodeExpiration time.Duration
+	DevicePollInterval   time.Duration
+	// MagicLinkExpiration is how long the login links of RequestMagicLink are
+	// valid; at most MagicLinksPerHour are sent to an address
+	MagicLinkExpiration time.Duration
+	MagicLinksPerHour   int
+	// HandoffExpiration is how long the handoff tokens passing a web session on
+	// to a native app may be redeemed
+	HandoffExpiration time.Duration
+	// PublicMethods are RPCs anyone may call without a token, besides those
+	// declared public in proto/, e.g. "/user.v1.UserService/GetUser"
+	PublicMethods []string
+	// DisabledMethods are RPCs refused to every caller, e.g. CheckEmailAvailable
+	// for deployments without self-service signups
+	DisabledMethods []string
+	// InternalCredentials are the credentials of other services besides
+	// InternalToken
+	InternalCredentials []InternalCredential
+	// SignedRequestMaxSkew is how far the timestamp of a signed request may be
+	// off; nonces are remembered for twice as long to reject replays
+	SignedRequestMaxSkew time.Duration
+	// WorkloadIssuers are the issuers of the workload tokens accepted for
+	// internal credentials with Workloads
+	WorkloadIssuers []WorkloadIssuer
+	// AuthzDecisionLog is which decisions of the RBAC policy are logged
+	AuthzDecisionLog string
+	// AuthzDryRun only logs the calls the RBAC policy denies and lets them
+	// pass, to try out policy changes
+	AuthzDryRun bool
+}
+
+// InternalCredential is a named credential other services call internal RPCs
+// with. Only digests of its tokens are configured.
+type InternalCredential struct {
+	// Name identifies the credential, e.g. in usage quotas
+	Name string `mapstructure:"name"`
+	// TokenSHA256 are the hex SHA-256 digests of the tokens accepted for the
+	// credential; listing the next token too rotates it without downtime
+	TokenSHA256 []string `mapstructure:"token_sha256"`
+	// SigningKeys are the secrets the credential signs requests with instead of
+	// sending a token (see auth.SignRequest); listing the next key too rotates it
+	SigningKeys []string `mapstructure:"signing_keys"`
+	// Workloads are the identities of the workload tokens the credential is
+	// presented with
+	Workloads []WorkloadIdentity `mapstructure:"workloads"`
+	// Methods restricts the RPCs the credential may call to these full method
+	// names; empty allows every RPC
+	Methods []string `mapstructure:"methods"`
+	// ExpiresAt is when the credential stops being accepted; zero never
+	ExpiresAt time.Time `mapstructure:"expires_at"`
+}
+
+// WorkloadIdentity is a workload as named by the tokens of one issuer. Subjects
+// are only unique per issuer, so both are matched.
+type WorkloadIdentity struct {
+	// Issuer is the iss claim of its tokens, one of the WorkloadIssuers
+	Issuer string `mapstructure:"issuer"`
+	// Subject is the sub claim of its tokens, e.g. a Kubernetes service account
+	// ("system:serviceaccount:reporting:reporter") or a SPIFFE ID
+	Subject string `mapstructure:"subject"`
+}
+
+// WorkloadIssuer is an OIDC issuer of workload tokens, e.g. the service
+// account issuer of a Kubernetes cluster or a SPIFFE trust domain.
+type WorkloadIssuer struct {
+	// Issuer is the iss claim of its tokens
+	Issuer string `mapstructure:"issuer"`
+	// Audience is the aud claim its tokens must carry
+	Audience string `mapstructure:"audience"`
+	// JWKSURL serves its signing keys; empty discovers it from the OpenID
+	// configuration of Issuer
+	JWKSURL string `mapstructure:"jwks_url"`
+	// CAFile verifies the TLS certificates of Issuer and JWKSURL instead of
+	// the system roots, e.g. the CA of the cluster
+	CAFile string `mapstructure:"ca_file"`
 }
 
 type SessionConfig struct {
 	ExpirationDuration time.Duration
+	// ExpirationByRole overrides ExpirationDuration for users of a role
+	ExpirationByRole map[string]time.Duration
+	// BoundTokens makes every call check that the token's session still exists,
+	// so revoking a session revokes its tokens right away
+	BoundTokens bool
+	// CheckCacheDuration is how long session checks are cached per server
+	CheckCacheDuration time.Duration
+	// CleanupInterval is how often the per-user session indexes are reconciled
+	// with the existing sessions and the session gauges are updated
+	CleanupInterval time.Duration
+	// TTLJitter is the fraction by which session TTLs are randomly shortened, so
+	// sessions created at the same time don't expire at the same time
+	TTLJitter float64
+}
+
+type AccountConfig struct {
+	// DeletionGracePeriod is how long a requested account deletion can still be cancelled
+	DeletionGracePeriod time.Duration
+	// PurgeInterval is how often accounts past their grace period are purged
+	PurgeInterval time.Duration
+	// EmailChangeExpiration is how long both sides have to confirm an email change
+	EmailChangeExpiration time.Duration
+	// EmailChangeRollback is how long the previous address can roll an email change back
+	EmailChangeRollback time.Duration
+	// AllowedEmailDomains restricts self-service signups to these domains
+	// ("*.example.com" for subdomains); empty allows every domain
+	AllowedEmailDomains []string
+	// DisallowedDomainSignup is how signups outside AllowedEmailDomains are handled
+	DisallowedDomainSignup string
+	// SignupMode is who may register; admins can tighten it at runtime with the
+	// signup_blocked and signup_invite_only feature flags
+	SignupMode string
+	// InviteExpiration is how long a signup invite can be used
+	InviteExpiration time.Duration
+	// EmailCheckPerMinute is how many CheckEmailAvailable calls a client
+	// network may make per minute
+	EmailCheckPerMinute int
+	// EmailCheckCaptcha requires a solved CAPTCHA for CheckEmailAvailable
+	EmailCheckCaptcha bool
+	// UserSearchPerMinute is how many SearchUsers calls a user may make per
+	// minute
+	UserSearchPerMinute int
+	// LastSeenInterval is how often a user's last_seen_at is written at most
+	LastSeenInterval time.Duration
+	// DormantAfter is how long accounts may be inactive before they are
+	// deactivated; 0 keeps them active
+	DormantAfter time.Duration
+	// DormantWarning is how long before the deactivation the user is warned
+	DormantWarning time.Duration
+	// DormantCheckInterval is how often dormant accounts are looked for
+	DormantCheckInterval time.Duration
+}
+
+type CaptchaConfig struct {
+	// VerifyURL is the siteverify endpoint of the CAPTCHA provider, e.g.
+	// "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+	VerifyURL string
+	Secret    string
+	// SiteKey is the public key the frontend renders the widget with
+	SiteKey string
+}
+
+type MailerConfig struct {
+	// Driver selects the delivery mechanism: "log" (default) or "smtp"
+	Driver string
+	From   string
+	// LinkBaseURL is the public URL of the frontend that links in emails point to
+	LinkBaseURL  string
+	SMTPHost     string
+	SMTPPort     uint
+	SMTPUsername string
+	SMTPPassword string
+}
+
+type AuditConfig struct {
+	// PseudonymizationKey is the HMAC key used to derive pseudonyms for purged
+	// users; defaults to a key derived from the JWT secret
+	PseudonymizationKey string
+	// Retention is how long audit events are kept before they are deleted
+	Retention time.Duration
+	// PIIRetention is how long client information (IP, user agent, metadata) is kept
+	PIIRetention time.Duration
+	// RetentionInterval is how often the retention policy is enforced
+	RetentionInterval time.Duration
+	// ChainInterval is how often the audit hash chain is verified
+	ChainInterval time.Duration
+	// ChainFullVerifyInterval is how often the whole audit hash chain is
+	// verified again from the oldest retained event, rather than only the
+	// events appended since the last verification
+	ChainFullVerifyInterval time.Duration
+	// AnchorEnabled anchors the head of the verified chain in the object store
+	AnchorEnabled bool
+}
+
+// RetentionConfig sets how long data of categories beyond audit events is
+// kept; the retention job enforces it along with AuditConfig.Retention.
+type RetentionConfig struct {
+	// LoginHistory is how long login events stay linked to the user and keep
+	// their client information
+	LoginHistory time.Duration
+	// DeletedUsers is how long deleted users are kept before they are purged
+	DeletedUsers time.Duration
+	// ExpiredInvites is how long signup invites are kept once they expired
+	ExpiredInvites time.Duration
+}
+
+// EnumerationConfig bounds the users each admin may read per UTC day through
+// ListUsers, SearchUsers and ExportUsers, so a stolen admin token can't
+// quietly scrape all users.
+type EnumerationConfig struct {
+	// DailyUsers is how many users an admin may read per day; calls are
+	// rejected once it is reached. 0 leaves it unbounded.
+	DailyUsers int64
+	// AlertUsers is how many users read per day raise an alert (an audit event
+	// and a metric); 0 disables alerts
+	AlertUsers int64
+}
+
+// EncryptionConfig encrypts sensitive columns in the application, so they are
+// unreadable in database dumps and backups: provider tokens always, once keys
+// are configured, and email addresses optionally.
+type EncryptionConfig struct {
+	// Keys are the AES-256 keys as "<id>:<base64 of 32 bytes>"; values are
+	// encrypted with the first and decrypted with the one they name, so a key
+	// is rotated by prepending a new one and dropping the old one once the
+	// rotation job re-encrypted everything. Empty disables encryption.
+	Keys []string
+	// IndexKey is the HMAC key of the blind index of encrypted email
+	// addresses, which email lookups use; it can't be rotated without
+	// rebuilding the index
+	IndexKey string
+	// EncryptEmail encrypts the email addresses of users; searching users
+	// then matches names by prefix and email addresses only exactly
+	EncryptEmail bool
+	// RotationInterval is how often values not encrypted as configured are
+	// re-encrypted; 0 disables the job
+	RotationInterval time.Duration
+}
+
+type ThrottleConfig struct {
+	Enabled bool
+	// FreeAttempts is how many consecutive failures per account are not delayed
+	FreeAttempts int
+	// IPFreeAttempts is how many consecutive failures per client network are not delayed
+	IPFreeAttempts int
+	// BaseDelay is the first delay past the free attempts, doubled after each further failure
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Window is how long failures are remembered after the last one
+	Window time.Duration
+}
+
+type RiskConfig struct {
+	Enabled bool
+	// ChallengeThreshold is the score (0-100) from which a login should require
+	// additional verification
+	ChallengeThreshold int
+	// BlockThreshold is the score from which logins are rejected; 0 never blocks
+	BlockThreshold int
+	// VelocityWindow and VelocityMaxLogins flag accounts logging in unusually often
+	VelocityWindow    time.Duration
+	VelocityMaxLogins int
+	// FailedAttempts is how many recent failures on the account raise the score
+	FailedAttempts int
+	// History is how long known devices and networks of a user are remembered
+	History time.Duration
+}
+
+type ErrorReportingConfig struct {
+	// DSN of a Sentry (or compatible) project errors are reported to, e.g.
+	// "https://key@sentry.example.com/42"; empty disables error reporting
+	DSN string
+	// Environment and Release tag the reported errors; the release defaults to
+	// the VCS revision the binary was built from
+	Environment string
+	Release     string
+}
+
+type WebhooksConfig struct {
+	// GithubSecret verifies the signatures of the GitHub webhooks the gateway
+	// receives at /webhooks/github; empty disables the endpoint
+	GithubSecret string
+	// GithubRevocation is how a user revoking the GitHub authorization is handled:
+	// RevocationRevokeSessions or RevocationUnlink
+	GithubRevocation string
+}
+
+type LoginCodeConfig struct {
+	// Channels enable login codes (SendLoginCode) delivered by these channels,
+	// LoginCodeChannelEmail or LoginCodeChannelSMS; empty disables them
+	Channels   []string
+	Expiration time.Duration
+	// MaxAttempts is how many wrong codes void a code
+	MaxAttempts int
+	// SendsPerHour is how many codes are sent to an address per hour (-1 = all)
+	SendsPerHour int
+	// SMSURL is the endpoint of the SMS gateway: it receives a JSON object with
+	// the "to" phone number and the "message" to send, with SMSAuthorization as
+	// Authorization header
+	SMSURL           string
+	SMSAuthorization string
+}
+
+type ProvisioningConfig struct {
+	// Hooks run in order on the first OAuth login of a user, before the user is
+	// created, and may change or reject the user: the names of hooks registered
+	// with provisioning.Register, or "webhook"
+	Hooks []string
+	// WebhookURL receives the identity and the user to create as JSON POST and
+	// answers with the changes to the user, or a rejection
+	WebhookURL string
+	// WebhookSecret signs the webhook requests (X-Signature: sha256=<hex HMAC>)
+	WebhookSecret  string
+	WebhookTimeout time.Duration
+}
+
+type ClaimsConfig struct {
+	// CalloutURL receives the user a token is issued for as JSON POST and answers
+	// with {"claims": {...}} to add to the token; empty disables the callout
+	CalloutURL           string
+	CalloutAuthorization string
+	CalloutTimeout       time.Duration
+	// MaxBytes bounds the JSON size of the custom claims of a token; larger
+	// claims are left out of the token
+	MaxBytes int
+	// CacheTTL is how long the custom claims of a user are reused (-1 = not cached)
+	CacheTTL time.Duration
+}
+
+// OutboundConfig shapes the calls to OAuth providers: token exchanges and
+// refreshes and the user info APIs.
+type OutboundConfig struct {
+	// ProxyURL is the proxy the calls go through, e.g.
+	// "http://proxy.example.com:3128"; empty uses the HTTPS_PROXY, HTTP_PROXY
+	// and NO_PROXY environment variables
+	ProxyURL string
+	// CAFile is a PEM bundle of CAs trusted besides those of the system, e.g.
+	// that of a proxy inspecting TLS traffic
+	CAFile string
+	// Timeout bounds each call, reading the response included (0 = unbounded)
+	Timeout time.Duration
+}
+
+// UserInfoConfig bounds the user info calls of OAuth logins, so a slow or
+// failing provider fails logins quickly instead of stalling them.
+type UserInfoConfig struct {
+	// Timeout bounds the user info of a login, retries included (0 =
+	// unbounded); TimeoutByProvider overrides it by provider name
+	Timeout           time.Duration
+	TimeoutByProvider map[string]time.Duration
+	// MaxRetries is how often calls the provider answers with a server error
+	// are retried, after RetryBackoff doubling with every retry
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// BreakerFailures consecutive failures of a provider (server errors,
+	// timeouts, unreachable) fail its logins right away for BreakerOpen,
+	// after which one login tries again; 0 disables the breaker
+	BreakerFailures int
+	BreakerOpen     time.Duration
+}
+
+// SLOConfig sets the objectives of the login SLIs, against which GetLoginSLO
+// reports the error budget left.
+type SLOConfig struct {
+	// LoginSuccess is the share of logins that must not fail for a server
+	// error, e.g. 0.995; logins refused for the request or the user don't count
+	LoginSuccess float64
+	// TokenIssuanceP99 is the latency 99% of token requests must stay under
+	TokenIssuanceP99 time.Duration
+	// OAuthExchangeSuccess is the share of OAuth code exchanges that must not
+	// fail for an error of the provider or the network
+	OAuthExchangeSuccess float64
+}
+
+// FailpointsConfig injects failures of dependencies, for development only.
+type FailpointsConfig struct {
+	// Enabled allows failpoints, which servers refuse to outside of the
+	// development and test modes
+	Enabled bool
+	// Points are the failpoints set at startup, e.g. "redis:get fail,percent=50"
+	// (see failpoint.ParseSpec)
+	Points []string
+}
+
+type UserStoreConfig struct {
+	// Backend selects where users are stored, e.g. a user directory a
+	// deployment already has, while sessions, tokens and audit events stay
+	// with this service
+	Backend string
+	// Options are the settings of the backend, e.g. its address
+	Options map[string]string
+}
+
+type ObjectStorageConfig struct {
+	// Provider selects where objects such as reports are stored; only "local"
+	// (a directory shared by the servers) is supported
+	Provider string
+	BasePath string
+}
+
+type ReportsConfig struct {
+	// DownloadBaseURL is the public URL of the API that signed download URLs
+	// point to; defaults to <mailer.link_base_url>/api
+	DownloadBaseURL string
+	// SigningKey signs download URLs; defaults to a key derived from the JWT
+	// secret
+	SigningKey string
+	// URLExpiration is how long signed download URLs are valid
+	URLExpiration time.Duration
+	// PollInterval is how often the report job looks for requested reports
+	PollInterval time.Duration
+	// ClaimLease is how long a server may take to generate a report before
+	// another one takes it over, the first presumably having died; 0 never
+	ClaimLease time.Duration
+}
+
+type UsageConfig struct {
+	// Enabled counts the calls of internal credentials per day and month
+	Enabled bool
+	// Quotas bound the calls of credentials by name; calls beyond them are
+	// rejected with ResourceExhausted until the window ends
+	Quotas map[string]Quota
+}
+
+// Quota is the number of calls a credential may make per UTC day and month;
+// 0 leaves a window unbounded.
+type Quota struct {
+	Daily   int64
+	Monthly int64
+}
+
+type SIEMConfig struct {
+	// Sink selects where security events are exported to: "file", "syslog" or
+	// "http"; empty disables the export
+	Sink string
+	// Format is "json" (JSON Lines, default) or "cef"
+	Format string
+	// EventTypes limits the export to these audit event types ("login.*" for a
+	// whole category); empty exports every event
+	EventTypes    []string
+	FilePath      string
+	SyslogNetwork string
+	SyslogAddress string
+	// HTTPURL is the collector endpoint, e.g. a Splunk HEC or Elastic ingest URL
+	HTTPURL string
+	// HTTPAuthorization is sent as the Authorization header, e.g. "Splunk <token>"
+	HTTPAuthorization string
+	// BufferSize is how many events are queued before further events are dropped
+	BufferSize int
+	BatchSize  int
+	// FlushInterval is how long events are held back to fill a batch
+	FlushInterval time.Duration
+}
+
+type FeatureFlagsConfig struct {
+	// Defaults are the flag values used until an admin overrides them at runtime
+	Defaults map[string]bool
+	// CacheDuration is how long flag values are cached per server
+	CacheDuration time.Duration
+}
+
+type MetricsConfig struct {
+	// Port serves Prometheus metrics on /metrics; 0 disables the endpoint
+	Port uint
+}
+
+type LogConfig struct {
+	// Level is the minimum level logged: "debug", "info" (default), "warn" or "error"
+	Level string
+	// Format is LogFormatText (default) or LogFormatJSON
+	Format string
+	// WarnSamplesPerMinute limits noisy warnings, e.g. of failed logins, to this
+	// many lines per client network and minute (-1 = all)
+	WarnSamplesPerMinute int
+}
+
+type GatewayConfig struct {
+	// Routes are path prefixes the gateway proxies to other backends
+	Routes []GatewayRoute
+	// ProtectedPrefixes are API paths, e.g. "/v1/users", whose requests the gateway
+	// rejects without a valid user token before they reach the gRPC server
+	ProtectedPrefixes []string
+	// LoginURL is where clients of rejected requests are told to log in
+	LoginURL string
+	// CachePaths are public GET endpoints (API paths) whose responses the gateway
+	// caches in Redis for CacheTTL, serving them up to CacheStale longer while
+	// revalidating in the background. Each may list the query params it is
+	// cached by, e.g. "/config?locale"; other params are dropped. Segments in
+	// braces match any segment, e.g. "/v1/tenants/{org}/config".
+	CachePaths []string
+	CacheTTL   time.Duration
+	CacheStale time.Duration
+	// H2C serves HTTP/2 without TLS besides HTTP/1, e.g. behind a TLS-terminating proxy
+	H2C bool
+	// InProcess serves the gateway from the gRPC server's binary as well,
+	// calling the services in-process instead of over the network
+	InProcess bool
+	// GRPCTarget is the gRPC server the gateway calls, e.g. "dns:///auth-portal:50051"
+	// to resolve all addresses of a service; defaults to localhost:server.port
+	GRPCTarget string
+	// GRPCEndpoints are the gRPC servers the gateway calls, e.g. the addresses of
+	// replicas ("10.0.0.1:50051"), instead of GRPCTarget
+	GRPCEndpoints []string
+	// GRPCHealthCheck watches the health service of each server, so round robin
+	// skips the servers reporting themselves as not serving; pick first
+	// ignores it
+	GRPCHealthCheck bool
+	// GRPCKeepalive is how often idle connections are pinged (0 disables pings),
+	// dropping them if no answer arrives within GRPCKeepaliveTimeout
+	GRPCKeepalive        time.Duration
+	GRPCKeepaliveTimeout time.Duration
+	// GRPCIdleTimeout closes connections unused for this long; 0 keeps gRPC's default
+	GRPCIdleTimeout time.Duration
+	// GRPCLoadBalancing is LoadBalancingPickFirst (default) or LoadBalancingRoundRobin
+	GRPCLoadBalancing string
+	// GRPCMaxRetries retries idempotent calls failing with UNAVAILABLE or
+	// DEADLINE_EXCEEDED up to this many times. Retries wait a random time up to a
+	// backoff growing from GRPCRetryInitialBackoff to GRPCRetryMaxBackoff
+	GRPCMaxRetries          int
+	GRPCRetryInitialBackoff time.Duration
+	GRPCRetryMaxBackoff     time.Duration
+	// GRPCHedgingDelay, if set, hedges idempotent calls instead of retrying them:
+	// another attempt is sent whenever no answer arrived within the delay
+	GRPCHedgingDelay time.Duration
+	// DefaultTimeout is the deadline of API calls whose clients send no
+	// Grpc-Timeout header; 0 leaves it to the gRPC server
+	DefaultTimeout time.Duration
+	// MetricsPort serves the gateway's Prometheus metrics on /metrics; 0 disables
+	// the endpoint. It differs from metrics.port so both servers can share a host
+	MetricsPort uint
+	// AssetOrigin, e.g. "https://cdn.example.com/portal", serves the frontend
+	// assets instead of the gateway, which keeps serving index.html, pointed at
+	// it, and redirects the requests of assets there
+	AssetOrigin string
+	// BreakerFailures is how many API calls in a row failing as unavailable make
+	// the gateway stop calling the gRPC server for BreakerCooldown, answering
+	// with a 503 error and a maintenance page instead; 0 or less disables it
+	BreakerFailures int
+	BreakerCooldown time.Duration
+	// Maintenance answers all API calls that way until it is turned off, with
+	// MaintenancePage (an HTML file) if set
+	Maintenance     bool
+	MaintenancePage string
+	SecurityHeaders SecurityHeadersConfig
+}
+
+// SecurityHeadersConfig are the security headers the gateway adds to its
+// responses, guarding the portal against downgrade, clickjacking and content
+// injection.
+type SecurityHeadersConfig struct {
+	Enabled bool
+	// HSTSMaxAge is how long browsers only connect over HTTPS (-1 sends no
+	// Strict-Transport-Security header)
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+	ReferrerPolicy        string
+	// ContentSecurityPolicy applies to the frontend; API responses get one
+	// allowing nothing. Both get a frame-ancestors directive from FrameAncestors
+	ContentSecurityPolicy string
+	// FrameAncestors are the origins allowed to embed the portal; none by default
+	FrameAncestors []string
+}
+
+// GatewayRoute proxies requests under Prefix to another HTTP backend, e.g. the
+// grpc-gateway of another service, so the gateway is the single authenticated
+// entry point.
+type GatewayRoute struct {
+	// Prefix is the path prefix of the route, e.g. "/workshop/"
+	Prefix string `mapstructure:"prefix"`
+	// Backend is the base URL requests are forwarded to
+	Backend string `mapstructure:"backend"`
+	// StripPrefix removes Prefix from the forwarded path
+	StripPrefix bool `mapstructure:"strip_prefix"`
+	// Auth is GatewayAuthRequired (default), GatewayAuthOptional or GatewayAuthNone
+	Auth string `mapstructure:"auth"`
 }
 
 func Load() Config {
 	cfg := Config{
 		Server: ServerConfig{
-			Port:     app.Config().GetUint(ServerPortKey),
-			HTTPPort: app.Config().GetUint(ServerHTTPPortKey),
+			Port:               app.Config().GetUint(ServerPortKey),
+			HTTPPort:           app.Config().GetUint(ServerHTTPPortKey),
+			RateLimitPerSecond: app.Config().GetInt(ServerRateLimitPerSecondKey),
+			RateLimitBurst:     app.Config().GetInt(ServerRateLimitBurstKey),
+			RPCTimeout: time.Duration(
+				max(getIntWithDefault(ServerRPCTimeoutKey, DefaultRPCTimeoutSeconds), 0),
+			) * time.Second,
+			RPCTimeoutByMethod: getDurationMap(ServerRPCTimeoutByMethodKey, time.Second),
+			TrustedProxies:     app.Config().GetStringSlice(ServerTrustedProxiesKey),
+			PeerGuard: PeerGuardConfig{
+				Enabled: !app.Config().IsSet(ServerPeerGuardEnabledKey) ||
+					app.Config().GetBool(ServerPeerGuardEnabledKey),
+				Limit: getIntWithDefault(ServerPeerGuardLimitKey, DefaultPeerGuardLimit),
+				Window: time.Duration(getIntWithDefault(
+					ServerPeerGuardWindowSecondsKey,
+					DefaultPeerGuardWindowSeconds,
+				)) * time.Second,
+				BlockFor: time.Duration(getIntWithDefault(
+					ServerPeerGuardBlockSecondsKey,
+					DefaultPeerGuardBlockSeconds,
+				)) * time.Second,
+				ExemptCIDRs: app.Config().GetStringSlice(ServerPeerGuardExemptCIDRsKey),
+			},
 		},
 		Auth: AuthConfig{
-			InternalToken:      app.Config().GetString(AuthInternalTokenKey),
-			JWTSecret:          app.Config().GetString(AuthJWTSecretKey),
-			GithubClientID:     app.Config().GetString(AuthGithubClientIDKey),
-			GithubClientSecret: app.Config().GetString(AuthGithubClientSecretKey),
-			GithubRedirectURL:  app.Config().GetString(AuthGithubRedirectURLKey),
+			InternalToken:         app.Config().GetString(AuthInternalTokenKey),
+			InternalCredentials:   getInternalCredentials(),
+			WorkloadIssuers:       getWorkloadIssuers(),
+			AuthzDecisionLog:      app.Config().GetString(AuthAuthzDecisionLogKey),
+			AuthzDryRun:           app.Config().GetBool(AuthAuthzDryRunKey),
+			JWTSecret:             app.Config().GetString(AuthJWTSecretKey),
+			JWTIssuer:             app.Config().GetString(AuthJWTIssuerKey),
+			JWTAudience:           app.Config().GetString(AuthJWTAudienceKey),
+			JWTRejectLegacyTokens: app.Config().GetBool(AuthJWTRejectLegacyTokensKey),
+			JWTValidMethods:       app.Config().GetStringSlice(AuthJWTValidMethodsKey),
+			GithubClientID:        app.Config().GetString(AuthGithubClientIDKey),
+			GithubClientSecret:    app.Config().GetString(AuthGithubClientSecretKey),
+			GithubRedirectURL:     app.Config().GetString(AuthGithubRedirectURLKey),
+			GithubBaseURL:         app.Config().GetString(AuthGithubBaseURLKey),
+			AllowedRedirectURLs:   app.Config().GetStringSlice(AuthAllowedRedirectURLsKey),
+			OAuthStateBinding:     app.Config().GetString(AuthOAuthStateBindingKey),
+			OAuthStateIPMatch:     app.Config().GetString(AuthOAuthStateIPMatchKey),
+			StoreProviderTokens:   app.Config().GetBool(AuthStoreProviderTokensKey),
+			ProviderTokenKey:      app.Config().GetString(AuthProviderTokenKeyKey),
+			GithubExtraScopes:     app.Config().GetStringSlice(AuthGithubExtraScopesKey),
+			PublicMethods:         app.Config().GetStringSlice(AuthPublicMethodsKey),
+			DisabledMethods:       app.Config().GetStringSlice(AuthDisabledMethodsKey),
+			TokenScopes:           app.Config().GetString(AuthTokenScopesKey),
+			LoginGenericErrors:    app.Config().GetBool(AuthLoginGenericErrorsKey),
+			LoginTarpitMin: time.Duration(
+				app.Config().GetInt(AuthLoginTarpitMinMillisKey),
+			) * time.Millisecond,
+			LoginTarpitMax: time.Duration(
+				app.Config().GetInt(AuthLoginTarpitMaxMillisKey),
+			) * time.Millisecond,
+			JWTLeeway: time.Duration(
+				getIntWithDefault(AuthJWTLeewaySecondsKey, DefaultJWTLeewaySeconds),
+			) * time.Second,
+			SignedRequestMaxSkew: time.Duration(
+				getIntWithDefault(
+					AuthSignedRequestMaxSkewSecondsKey,
+					DefaultSignedRequestMaxSkewSeconds,
+				),
+			) * time.Second,
+			AccessTokenLifetime: time.Duration(
+				getIntWithDefault(
+					AuthAccessTokenLifetimeMinutesKey,
+					DefaultAccessTokenLifetimeMinutes,
+				),
+			) * time.Minute,
+			AccessTokenLifetimeByRole: getDurationMap(AuthAccessTokenLifetimeByRoleKey, time.Minute),
 			OAuthStateExpirationDuration: time.Duration(
 				getIntWithDefault(
 					AuthOAuthStateExpirationMinutesKey,
 					DefaultOAuthStateExpirationMinutes,
 				),
 			) * time.Minute,
+			OAuthCodeReplayWindow: time.Duration(
+				getIntWithDefault(
+					AuthOAuthCodeReplayWindowMinutesKey,
+					DefaultOAuthCodeReplayWindowMinutes,
+				),
+			) * time.Minute,
+			IdempotencyKeyTTL: time.Duration(max(getIntWithDefault(
+				AuthIdempotencyKeyTTLSecondsKey,
+				DefaultIdempotencyKeyTTLSeconds,
+			), 0)) * time.Second,
+			DeviceCodeExpiration: time.Duration(
+				getIntWithDefault(
+					AuthDeviceCodeExpirationMinutesKey,
+					DefaultDeviceCodeExpirationMinutes,
+				),
+			) * time.Minute,
+			DevicePollInterval: time.Duration(
+				getIntWithDefault(
+					AuthDevicePollIntervalSecondsKey,
+					DefaultDevicePollIntervalSeconds,
+				),
+			) * time.Second,
+			MagicLinkExpiration: time.Duration(
+				getIntWithDefault(
+					AuthMagicLinkExpirationMinutesKey,
+					DefaultMagicLinkExpirationMinutes,
+				),
+			) * time.Minute,
+			MagicLinksPerHour: getIntWithDefault(
+				AuthMagicLinksPerHourKey,
+				DefaultMagicLinksPerHour,
+			),
+			HandoffExpiration: time.Duration(
+				getIntWithDefault(AuthHandoffExpirationSecondsKey, DefaultHandoffExpirationSeconds),
+			) * time.Second,
 		},
 		Session: SessionConfig{
 			ExpirationDuration: time.Duration(
 				getIntWithDefault(SessionExpirationHoursKey, DefaultSessionExpirationHours),
 			) * time.Hour,
+			ExpirationByRole: getDurationMap(SessionExpirationByRoleKey, time.Hour),
+			BoundTokens:      app.Config().GetBool(SessionBoundTokensKey),
+			CheckCacheDuration: time.Duration(
+				getIntWithDefault(SessionCheckCacheSecondsKey, DefaultSessionCheckCacheSeconds),
+			) * time.Second,
+			CleanupInterval: time.Duration(
+				getIntWithDefault(SessionCleanupIntervalKey, DefaultSessionCleanupIntervalMinutes),
+			) * time.Minute,
+			TTLJitter: float64(
+				getIntWithDefault(SessionTTLJitterPercentKey, DefaultSessionTTLJitterPercent),
+			) / 100,
+		},
+		Account: AccountConfig{
+			DeletionGracePeriod: time.Duration(
+				getIntWithDefault(AccountDeletionGracePeriodDaysKey, DefaultDeletionGracePeriodDays),
+			) * 24 * time.Hour,
+			PurgeInterval: time.Duration(
+				getIntWithDefault(
+					AccountPurgeIntervalMinutesKey,
+					DefaultAccountPurgeIntervalMinutes,
+				),
+			) * time.Minute,
+			EmailChangeExpiration: time.Duration(
+				getIntWithDefault(
+					AccountEmailChangeExpirationHoursKey,
+					DefaultEmailChangeExpirationHours,
+				),
+			) * time.Hour,
+			EmailChangeRollback: time.Duration(
+				getIntWithDefault(AccountEmailChangeRollbackDaysKey, DefaultEmailChangeRollbackDays),
+			) * 24 * time.Hour,
+			AllowedEmailDomains:    app.Config().GetStringSlice(AccountAllowedEmailDomainsKey),
+			DisallowedDomainSignup: app.Config().GetString(AccountDisallowedDomainSignupKey),
+			SignupMode:             app.Config().GetString(AccountSignupModeKey),
+			InviteExpiration: time.Duration(
+				getIntWithDefault(AccountInviteExpirationDaysKey, DefaultInviteExpirationDays),
+			) * 24 * time.Hour,
+			EmailCheckPerMinute: getIntWithDefault(
+				AccountEmailCheckPerMinuteKey,
+				DefaultEmailCheckPerMinute,
+			),
+			EmailCheckCaptcha: app.Config().GetBool(AccountEmailCheckCaptchaKey),
+			UserSearchPerMinute: getIntWithDefault(
+				AccountUserSearchPerMinuteKey,
+				DefaultUserSearchPerMinute,
+			),
+			LastSeenInterval: time.Duration(
+				getIntWithDefault(
+					AccountLastSeenIntervalMinutesKey,
+					DefaultLastSeenIntervalMinutes,
+				),
+			) * time.Minute,
+			DormantAfter: time.Duration(
+				app.Config().GetInt(AccountDormantDaysKey),
+			) * 24 * time.Hour,
+			DormantWarning: time.Duration(
+				getIntWithDefault(AccountDormantWarningDaysKey, DefaultDormantWarningDays),
+			) * 24 * time.Hour,
+			DormantCheckInterval: time.Duration(
+				getIntWithDefault(
+					AccountDormantCheckIntervalKey,
+					DefaultDormantCheckIntervalMinutes,
+				),
+			) * time.Minute,
+		},
+		Captcha: CaptchaConfig{
+			VerifyURL: app.Config().GetString(CaptchaVerifyURLKey),
+			Secret:    app.Config().GetString(CaptchaSecretKey),
+			SiteKey:   app.Config().GetString(CaptchaSiteKeyKey),
+		},
+		Mailer: MailerConfig{
+			Driver:       app.Config().GetString(MailerDriverKey),
+			From:         app.Config().GetString(MailerFromKey),
+			LinkBaseURL:  app.Config().GetString(MailerLinkBaseURLKey),
+			SMTPHost:     app.Config().GetString(MailerSMTPHostKey),
+			SMTPPort:     app.Config().GetUint(MailerSMTPPortKey),
+			SMTPUsername: app.Config().GetString(MailerSMTPUsernameKey),
+			SMTPPassword: app.Config().GetString(MailerSMTPPasswordKey),
+		},
+		Audit: AuditConfig{
+			PseudonymizationKey: app.Config().GetString(AuditPseudonymizationKeyKey),
+			Retention: time.Duration(
+				getIntWithDefault(AuditRetentionDaysKey, DefaultAuditRetentionDays),
+			) * 24 * time.Hour,
+			PIIRetention: time.Duration(
+				getIntWithDefault(AuditPIIRetentionDaysKey, DefaultAuditPIIRetentionDays),
+			) * 24 * time.Hour,
+			RetentionInterval: time.Duration(
+				getIntWithDefault(
+					AuditRetentionIntervalMinutesKey,
+					DefaultAuditRetentionIntervalMinutes,
+				),
+			) * time.Minute,
+			ChainInterval: time.Duration(
+				getIntWithDefault(AuditChainIntervalMinutesKey, DefaultAuditChainIntervalMinutes),
+			) * time.Minute,
+			ChainFullVerifyInterval: time.Duration(
+				getIntWithDefault(AuditChainFullVerifyHoursKey, DefaultAuditChainFullVerifyHours),
+			) * time.Hour,
+			AnchorEnabled: app.Config().GetBool(AuditAnchorEnabledKey),
+		},
+		Retention: RetentionConfig{
+			LoginHistory: time.Duration(
+				getIntWithDefault(RetentionLoginHistoryDaysKey, DefaultLoginHistoryRetentionDays),
+			) * 24 * time.Hour,
+			DeletedUsers: time.Duration(
+				getIntWithDefault(RetentionDeletedUsersDaysKey, DefaultDeletedUserRetentionDays),
+			) * 24 * time.Hour,
+			ExpiredInvites: time.Duration(
+				getIntWithDefault(
+					RetentionExpiredInvitesDaysKey,
+					DefaultExpiredInviteRetentionDays,
+				),
+			) * 24 * time.Hour,
+		},
+		Enumeration: EnumerationConfig{
+			DailyUsers: int64(max(
+				getIntWithDefault(EnumerationDailyUsersKey, DefaultEnumerationDailyUsers),
+				0,
+			)),
+			AlertUsers: int64(max(
+				getIntWithDefault(EnumerationAlertUsersKey, DefaultEnumerationAlertUsers),
+				0,
+			)),
+		},
+		Encryption: EncryptionConfig{
+			Keys:         app.Config().GetStringSlice(EncryptionKeysKey),
+			IndexKey:     app.Config().GetString(EncryptionIndexKeyKey),
+			EncryptEmail: app.Config().GetBool(EncryptionEncryptEmailKey),
+			RotationInterval: time.Duration(max(getIntWithDefault(
+				EncryptionRotationIntervalMinutesKey,
+				DefaultEncryptionRotationMinutes,
+			), 0)) * time.Minute,
+		},
+		Risk: RiskConfig{
+			// Scoring stays on unless it is explicitly disabled
+			Enabled: !app.Config().IsSet(RiskEnabledKey) ||
+				app.Config().GetBool(RiskEnabledKey),
+			ChallengeThreshold: getIntWithDefault(
+				RiskChallengeThresholdKey,
+				DefaultRiskChallengeThreshold,
+			),
+			BlockThreshold: app.Config().GetInt(RiskBlockThresholdKey),
+			VelocityWindow: time.Duration(
+				getIntWithDefault(RiskVelocityWindowMinutesKey, DefaultRiskVelocityWindowMinutes),
+			) * time.Minute,
+			VelocityMaxLogins: getIntWithDefault(
+				RiskVelocityMaxLoginsKey,
+				DefaultRiskVelocityMaxLogins,
+			),
+			FailedAttempts: getIntWithDefault(RiskFailedAttemptsKey, DefaultRiskFailedAttempts),
+			History: time.Duration(
+				getIntWithDefault(RiskHistoryDaysKey, DefaultRiskHistoryDays),
+			) * 24 * time.Hour,
+		},
+		Webhooks: WebhooksConfig{
+			GithubSecret:     app.Config().GetString(WebhooksGithubSecretKey),
+			GithubRevocation: app.Config().GetString(WebhooksGithubRevocationKey),
+		},
+		LoginCode: LoginCodeConfig{
+			Channels: app.Config().GetStringSlice(LoginCodeChannelsKey),
+			Expiration: time.Duration(
+				getIntWithDefault(LoginCodeExpirationMinutesKey, DefaultLoginCodeExpirationMinutes),
+			) * time.Minute,
+			MaxAttempts: getIntWithDefault(LoginCodeMaxAttemptsKey, DefaultLoginCodeMaxAttempts),
+			SendsPerHour: getIntWithDefault(
+				LoginCodeSendsPerHourKey,
+				DefaultLoginCodeSendsPerHour,
+			),
+			SMSURL:           app.Config().GetString(LoginCodeSMSURLKey),
+			SMSAuthorization: app.Config().GetString(LoginCodeSMSAuthorizationKey),
+		},
+		Provisioning: ProvisioningConfig{
+			Hooks:         app.Config().GetStringSlice(ProvisioningHooksKey),
+			WebhookURL:    app.Config().GetString(ProvisioningWebhookURLKey),
+			WebhookSecret: app.Config().GetString(ProvisioningWebhookSecretKey),
+			WebhookTimeout: time.Duration(getIntWithDefault(
+				ProvisioningWebhookTimeoutSecondsKey,
+				DefaultProvisioningWebhookTimeout,
+			)) * time.Second,
+		},
+		Claims: ClaimsConfig{
+			CalloutURL:           app.Config().GetString(ClaimsCalloutURLKey),
+			CalloutAuthorization: app.Config().GetString(ClaimsCalloutAuthorizationKey),
+			CalloutTimeout: time.Duration(getIntWithDefault(
+				ClaimsCalloutTimeoutSecondsKey,
+				DefaultClaimsCalloutTimeoutSeconds,
+			)) * time.Second,
+			MaxBytes: getIntWithDefault(ClaimsMaxBytesKey, DefaultClaimsMaxBytes),
+			CacheTTL: time.Duration(
+				getIntWithDefault(ClaimsCacheSecondsKey, DefaultClaimsCacheSeconds),
+			) * time.Second,
+		},
+		Outbound: OutboundConfig{
+			ProxyURL: app.Config().GetString(OutboundProxyURLKey),
+			CAFile:   app.Config().GetString(OutboundCAFileKey),
+			Timeout: time.Duration(max(getIntWithDefault(
+				OutboundTimeoutSecondsKey,
+				DefaultOutboundTimeoutSeconds,
+			), 0)) * time.Second,
+		},
+		UserInfo: UserInfoConfig{
+			Timeout: time.Duration(max(getIntWithDefault(
+				UserInfoTimeoutSecondsKey,
+				DefaultUserInfoTimeoutSeconds,
+			), 0)) * time.Second,
+			TimeoutByProvider: getDurationMap(UserInfoTimeoutSecondsByProviderKey, time.Second),
+			MaxRetries: max(
+				getIntWithDefault(UserInfoMaxRetriesKey, DefaultUserInfoMaxRetries),
+				0,
+			),
+			RetryBackoff: time.Duration(max(getIntWithDefault(
+				UserInfoRetryBackoffMillisecondsKey,
+				DefaultUserInfoRetryBackoffMS,
+			), 0)) * time.Millisecond,
+			BreakerFailures: max(
+				getIntWithDefault(UserInfoBreakerFailuresKey, DefaultUserInfoBreakerFailures),
+				0,
+			),
+			BreakerOpen: time.Duration(getIntWithDefault(
+				UserInfoBreakerOpenSecondsKey,
+				DefaultUserInfoBreakerOpenSeconds,
+			)) * time.Second,
+		},
+		SLO: SLOConfig{
+			LoginSuccess: getPercentWithDefault(
+				SLOLoginSuccessPercentKey,
+				DefaultSLOLoginSuccessPercent,
+			),
+			TokenIssuanceP99: time.Duration(max(getIntWithDefault(
+				SLOTokenIssuanceP99MillisecondsKey,
+				DefaultSLOTokenIssuanceP99MS,
+			), 0)) * time.Millisecond,
+			OAuthExchangeSuccess: getPercentWithDefault(
+				SLOOAuthExchangeSuccessPercentKey,
+				DefaultSLOOAuthExchangeSuccessPercent,
+			),
+		},
+		Failpoints: FailpointsConfig{
+			Enabled: app.Config().GetBool(FailpointsEnabledKey),
+			Points:  app.Config().GetStringSlice(FailpointsPointsKey),
+		},
+		UserStore: UserStoreConfig{
+			Backend: app.Config().GetString(UserStoreBackendKey),
+			Options: app.Config().GetStringMapString(UserStoreOptionsKey),
+		},
+		ObjectStorage: ObjectStorageConfig{
+			Provider: app.Config().GetString(ObjectStorageProviderKey),
+			BasePath: app.Config().GetString(ObjectStorageBasePathKey),
+		},
+		Reports: ReportsConfig{
+			DownloadBaseURL: app.Config().GetString(ReportsDownloadBaseURLKey),
+			SigningKey:      app.Config().GetString(ReportsSigningKeyKey),
+			URLExpiration: time.Duration(getIntWithDefault(
+				ReportsURLExpirationMinutesKey,
+				DefaultReportURLExpirationMinutes,
+			)) * time.Minute,
+			PollInterval: time.Duration(getIntWithDefault(
+				ReportsPollIntervalSecondsKey,
+				DefaultReportPollIntervalSeconds,
+			)) * time.Second,
+			ClaimLease: time.Duration(max(getIntWithDefault(
+				ReportsClaimLeaseMinutesKey,
+				DefaultReportClaimLeaseMinutes,
+			), 0)) * time.Minute,
+		},
+		Usage: UsageConfig{
+			Enabled: app.Config().GetBool(UsageEnabledKey),
+			Quotas:  getQuotas(UsageQuotasKey),
+		},
+		ErrorReporting: ErrorReportingConfig{
+			DSN:         app.Config().GetString(ErrorReportingDSNKey),
+			Environment: app.Config().GetString(ErrorReportingEnvironmentKey),
+			Release:     app.Config().GetString(ErrorReportingReleaseKey),
+		},
+		SIEM: SIEMConfig{
+			Sink:              app.Config().GetString(SIEMSinkKey),
+			Format:            app.Config().GetString(SIEMFormatKey),
+			EventTypes:        app.Config().GetStringSlice(SIEMEventTypesKey),
+			FilePath:          app.Config().GetString(SIEMFilePathKey),
+			SyslogNetwork:     app.Config().GetString(SIEMSyslogNetworkKey),
+			SyslogAddress:     app.Config().GetString(SIEMSyslogAddressKey),
+			HTTPURL:           app.Config().GetString(SIEMHTTPURLKey),
+			HTTPAuthorization: app.Config().GetString(SIEMHTTPAuthorizationKey),
+			BufferSize:        getIntWithDefault(SIEMBufferSizeKey, DefaultSIEMBufferSize),
+			BatchSize:         getIntWithDefault(SIEMBatchSizeKey, DefaultSIEMBatchSize),
+			FlushInterval: time.Duration(
+				getIntWithDefault(SIEMFlushIntervalSecondsKey, DefaultSIEMFlushIntervalSeconds),
+			) * time.Second,
+		},
+		Throttle: ThrottleConfig{
+			// Throttling stays on unless it is explicitly disabled
+			Enabled: !app.Config().IsSet(ThrottleEnabledKey) ||
+				app.Config().GetBool(ThrottleEnabledKey),
+			FreeAttempts: getIntWithDefault(
+				ThrottleFreeAttemptsKey,
+				DefaultThrottleFreeAttempts,
+			),
+			IPFreeAttempts: getIntWithDefault(
+				ThrottleIPFreeAttemptsKey,
+				DefaultThrottleIPFreeAttempts,
+			),
+			BaseDelay: time.Duration(
+				getIntWithDefault(ThrottleBaseDelaySecondsKey, DefaultThrottleBaseDelaySeconds),
+			) * time.Second,
+			MaxDelay: time.Duration(
+				getIntWithDefault(ThrottleMaxDelaySecondsKey, DefaultThrottleMaxDelaySeconds),
+			) * time.Second,
+			Window: time.Duration(
+				getIntWithDefault(ThrottleWindowMinutesKey, DefaultThrottleWindowMinutes),
+			) * time.Minute,
+		},
+		Features: FeatureFlagsConfig{
+			Defaults: getBoolMap(FeatureFlagsDefaultsKey),
+			CacheDuration: time.Duration(
+				getIntWithDefault(FeatureFlagsCacheSecondsKey, DefaultFeatureFlagsCacheSeconds),
+			) * time.Second,
+		},
+		Metrics: MetricsConfig{
+			Port: app.Config().GetUint(MetricsPortKey),
+		},
+		Log: readLogConfig(app.Config()),
+		Gateway: GatewayConfig{
+			Routes:            getGatewayRoutes(),
+			ProtectedPrefixes: app.Config().GetStringSlice(GatewayProtectedPrefixesKey),
+			LoginURL:          app.Config().GetString(GatewayLoginURLKey),
+			CachePaths:        app.Config().GetStringSlice(GatewayCachePathsKey),
+			CacheTTL: time.Duration(
+				getIntWithDefault(GatewayCacheTTLSecondsKey, DefaultGatewayCacheTTLSeconds),
+			) * time.Second,
+			CacheStale: time.Duration(
+				getIntWithDefault(GatewayCacheStaleSecondsKey, DefaultGatewayCacheStaleSeconds),
+			) * time.Second,
+			H2C:           app.Config().GetBool(GatewayH2CKey),
+			InProcess:     app.Config().GetBool(GatewayInProcessKey),
+			GRPCTarget:    app.Config().GetString(GatewayGRPCTargetKey),
+			GRPCEndpoints: app.Config().GetStringSlice(GatewayGRPCEndpointsKey),
+			// Health checks stay on unless they are explicitly disabled
+			GRPCHealthCheck: !app.Config().IsSet(GatewayGRPCHealthCheckKey) ||
+				app.Config().GetBool(GatewayGRPCHealthCheckKey),
+			GRPCKeepalive: time.Duration(
+				getIntWithDefault(GatewayGRPCKeepaliveKey, DefaultGatewayGRPCKeepaliveSeconds),
+			) * time.Second,
+			GRPCKeepaliveTimeout: time.Duration(
+				getIntWithDefault(GatewayGRPCKeepaliveTimeKey, DefaultGatewayGRPCKeepaliveTimeout),
+			) * time.Second,
+			GRPCIdleTimeout: time.Duration(
+				app.Config().GetInt(GatewayGRPCIdleTimeoutKey),
+			) * time.Second,
+			GRPCLoadBalancing: app.Config().GetString(GatewayGRPCLoadBalancingKey),
+			GRPCMaxRetries:    app.Config().GetInt(GatewayGRPCMaxRetriesKey),
+			GRPCRetryInitialBackoff: time.Duration(
+				getIntWithDefault(GatewayGRPCRetryBackoffKey, DefaultGatewayGRPCRetryBackoffMs),
+			) * time.Millisecond,
+			GRPCRetryMaxBackoff: time.Duration(
+				getIntWithDefault(GatewayGRPCRetryMaxKey, DefaultGatewayGRPCRetryMaxBackoffMs),
+			) * time.Millisecond,
+			GRPCHedgingDelay: time.Duration(
+				app.Config().GetInt(GatewayGRPCHedgingDelayKey),
+			) * time.Millisecond,
+			DefaultTimeout: time.Duration(
+				app.Config().GetInt(GatewayDefaultTimeoutKey),
+			) * time.Second,
+			MetricsPort: app.Config().GetUint(GatewayMetricsPortKey),
+			AssetOrigin: app.Config().GetString(GatewayAssetOriginKey),
+			BreakerFailures: getIntWithDefault(
+				GatewayBreakerFailuresKey,
+				DefaultGatewayBreakerFailures,
+			),
+			BreakerCooldown: time.Duration(
+				getIntWithDefault(GatewayBreakerCooldownKey, DefaultGatewayBreakerCooldownSeconds),
+			) * time.Second,
+			Maintenance:     app.Config().GetBool(GatewayMaintenanceKey),
+			MaintenancePage: app.Config().GetString(GatewayMaintenancePageKey),
+			SecurityHeaders: SecurityHeadersConfig{
+				Enabled: !app.Config().IsSet(GatewaySecurityHeadersEnabledKey) ||
+					app.Config().GetBool(GatewaySecurityHeadersEnabledKey),
+				HSTSMaxAge: time.Duration(
+					getIntWithDefault(GatewayHSTSMaxAgeSecondsKey, DefaultHSTSMaxAgeSeconds),
+				) * time.Second,
+				HSTSIncludeSubdomains: app.Config().GetBool(GatewayHSTSIncludeSubdomainsKey),
+				HSTSPreload:           app.Config().GetBool(GatewayHSTSPreloadKey),
+				ReferrerPolicy:        app.Config().GetString(GatewayReferrerPolicyKey),
+				ContentSecurityPolicy: app.Config().GetString(GatewayContentSecurityPolicyKey),
+				FrameAncestors:        app.Config().GetStringSlice(GatewayFrameAncestorsKey),
+			},
 		},
 		Database: gorm_client.Config{
 			Driver:   app.Config().GetString(DatabaseDriverKey),
@@ -112,10 +1747,138 @@ func Load() Config {
 	}
 
 	// Set default JWT Secret if not provided
+	if !app.Config().IsSet(ServerTrustedProxiesKey) {
+		cfg.Server.TrustedProxies = DefaultTrustedProxies
+	}
+	if !app.Config().IsSet(ServerPeerGuardExemptCIDRsKey) {
+		cfg.Server.PeerGuard.ExemptCIDRs = DefaultPeerGuardExemptCIDRs
+	}
+
 	if cfg.Auth.JWTSecret == "" {
 		cfg.Auth.JWTSecret = DefaultJWTSecret
 	}
 
+	if cfg.Auth.JWTIssuer == "" {
+		cfg.Auth.JWTIssuer = DefaultJWTIssuer
+	}
+	if cfg.Auth.JWTAudience == "" {
+		cfg.Auth.JWTAudience = DefaultJWTAudience
+	}
+	if len(cfg.Auth.JWTValidMethods) == 0 {
+		cfg.Auth.JWTValidMethods = []string{DefaultJWTValidMethod}
+	}
+
+	if cfg.Auth.OAuthStateBinding == "" {
+		cfg.Auth.OAuthStateBinding = StateBindingEnforce
+	}
+	if cfg.Auth.OAuthStateIPMatch == "" {
+		cfg.Auth.OAuthStateIPMatch = StateIPMatchExact
+	}
+	if cfg.Auth.TokenScopes == "" {
+		cfg.Auth.TokenScopes = TokenScopesNames
+	}
+	if cfg.Auth.AuthzDecisionLog == "" {
+		cfg.Auth.AuthzDecisionLog = AuthzDecisionLogOff
+	}
+	if cfg.Auth.AuthzDryRun && cfg.Auth.AuthzDecisionLog == AuthzDecisionLogOff {
+		// Dry runs are pointless without logging the denials they let pass
+		cfg.Auth.AuthzDecisionLog = AuthzDecisionLogDenials
+	}
+
+	if cfg.Account.DisallowedDomainSignup == "" {
+		cfg.Account.DisallowedDomainSignup = DomainSignupReject
+	}
+	if cfg.Account.SignupMode == "" {
+		cfg.Account.SignupMode = SignupModeOpen
+	}
+
+	if cfg.Webhooks.GithubRevocation == "" {
+		cfg.Webhooks.GithubRevocation = RevocationRevokeSessions
+	}
+
+	if cfg.Mailer.LinkBaseURL == "" {
+		cfg.Mailer.LinkBaseURL = DefaultMailerLinkBaseURL
+	}
+	if cfg.ObjectStorage.Provider == "" {
+		cfg.ObjectStorage.Provider = ObjectStorageLocal
+	}
+	if cfg.ObjectStorage.BasePath == "" {
+		cfg.ObjectStorage.BasePath = DefaultObjectStorageBasePath
+	}
+	if cfg.Reports.DownloadBaseURL == "" {
+		cfg.Reports.DownloadBaseURL = strings.TrimSuffix(cfg.Mailer.LinkBaseURL, "/") + "/api"
+	}
+	if cfg.Gateway.LoginURL == "" {
+		cfg.Gateway.LoginURL = DefaultGatewayLoginURL
+	}
+	if cfg.Gateway.SecurityHeaders.ReferrerPolicy == "" {
+		cfg.Gateway.SecurityHeaders.ReferrerPolicy = DefaultReferrerPolicy
+	}
+	if cfg.Gateway.SecurityHeaders.ContentSecurityPolicy == "" {
+		cfg.Gateway.SecurityHeaders.ContentSecurityPolicy = DefaultContentSecurityPolicy
+	}
+	if cfg.Gateway.GRPCKeepalive < 0 {
+		cfg.Gateway.GRPCKeepalive = 0
+	} else if cfg.Gateway.GRPCKeepalive > 0 && cfg.Gateway.GRPCKeepalive < MinGRPCKeepalive {
+		cfg.Gateway.GRPCKeepalive = MinGRPCKeepalive
+	}
+	if cfg.Gateway.GRPCLoadBalancing == "" {
+		cfg.Gateway.GRPCLoadBalancing = LoadBalancingPickFirst
+	}
+	if cfg.Mailer.SMTPPort == 0 {
+		cfg.Mailer.SMTPPort = DefaultMailerSMTPPort
+	}
+
+	// Derive keys from the JWT secret so they are never empty or shared defaults
+	if cfg.Audit.PseudonymizationKey == "" {
+		cfg.Audit.PseudonymizationKey = deriveKey(cfg.Auth.JWTSecret, "audit-pseudonym")
+	}
+	if cfg.Reports.SigningKey == "" {
+		cfg.Reports.SigningKey = deriveKey(cfg.Auth.JWTSecret, "report-download")
+	}
+
+	return cfg
+}
+
+// ReadLogConfig reads the log configuration from the files in dir again, layered
+// like at startup: default.toml, then the file of the MODE, then environment
+// variables. It lets running servers pick up a changed log level.
+func ReadLogConfig(dir string) (LogConfig, error) {
+	v := viper.New()
+	v.AddConfigPath(dir)
+	v.SetConfigName("default")
+	if err := v.ReadInConfig(); err != nil {
+		return LogConfig{}, err
+	}
+	mode := os.Getenv("MODE")
+	if mode == "" {
+		mode = "development"
+	}
+	v.SetConfigName(mode)
+	if err := v.MergeInConfig(); err != nil &&
+		!errors.As(err, &viper.ConfigFileNotFoundError{}) {
+		return LogConfig{}, err
+	}
+	v.AutomaticEnv()
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "__"))
+	return readLogConfig(v), nil
+}
+
+func readLogConfig(v *viper.Viper) LogConfig {
+	cfg := LogConfig{
+		Level:                v.GetString(LogLevelKey),
+		Format:               v.GetString(LogFormatKey),
+		WarnSamplesPerMinute: v.GetInt(LogWarnSamplesPerMinuteKey),
+	}
+	if cfg.WarnSamplesPerMinute == 0 {
+		cfg.WarnSamplesPerMinute = DefaultLogWarnSamplesPerMinute
+	}
+	if cfg.Level == "" {
+		cfg.Level = "info"
+	}
+	if cfg.Format == "" {
+		cfg.Format = LogFormatText
+	}
 	return cfg
 }
 
@@ -125,3 +1888,114 @@ func getIntWithDefault(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getPercentWithDefault reads a percentage as a ratio, e.g. 99.5 as 0.995.
+// Values outside of (0, 100] fall back to the default.
+func getPercentWithDefault(key string, defaultValue float64) float64 {
+	value := app.Config().GetFloat64(key)
+	if value <= 0 || value > 100 {
+		value = defaultValue
+	}
+	return value / 100
+}
+
+// getDurationMap reads a table of positive integers, e.g. per role, as durations
+// of the given unit.
+func getDurationMap(key string, unit time.Duration) map[string]time.Duration {
+	durations := make(map[string]time.Duration)
+	for name := range app.Config().GetStringMap(key) {
+		if value := app.Config().GetInt(key + "." + name); value > 0 {
+			durations[name] = time.Duration(value) * unit
+		}
+	}
+	return durations
+}
+
+// getBoolMap reads a table of booleans, e.g. per feature flag.
+func getBoolMap(key string) map[string]bool {
+	values := make(map[string]bool)
+	for name := range app.Config().GetStringMap(key) {
+		values[name] = app.Config().GetBool(key + "." + name)
+	}
+	return values
+}
+
+// getQuotas reads a table of quotas by credential name.
+func getQuotas(key string) map[string]Quota {
+	quotas := make(map[string]Quota)
+	for name := range app.Config().GetStringMap(key) {
+		quotas[name] = Quota{
+			Daily:   app.Config().GetInt64(key + "." + name + ".daily"),
+			Monthly: app.Config().GetInt64(key + "." + name + ".monthly"),
+		}
+	}
+	return quotas
+}
+
+// getInternalCredentials reads the [[auth.internal_credentials]] tables, whose
+// expires_at may be a TOML date-time or an RFC 3339 string.
+func getInternalCredentials() []InternalCredential {
+	var credentials []InternalCredential
+	err := app.Config().UnmarshalKey(
+		AuthInternalCredentialsKey,
+		&credentials,
+		viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeHookFunc(time.RFC3339),
+			mapstructure.StringToSliceHookFunc(","),
+		)),
+	)
+	if err != nil {
+		slog.Error("failed to read internal credentials", "error", err)
+		return nil
+	}
+	return credentials
+}
+
+// getWorkloadIssuers reads the [[auth.workload_issuers]] tables.
+func getWorkloadIssuers() []WorkloadIssuer {
+	var issuers []WorkloadIssuer
+	if err := app.Config().UnmarshalKey(AuthWorkloadIssuersKey, &issuers); err != nil {
+		slog.Error("failed to read workload issuers", "error", err)
+		return nil
+	}
+	return issuers
+}
+
+// getGatewayRoutes reads the [[gateway.routes]] tables.
+func getGatewayRoutes() []GatewayRoute {
+	var routes []GatewayRoute
+	if err := app.Config().UnmarshalKey(GatewayRoutesKey, &routes); err != nil {
+		slog.Error("failed to read gateway routes", "error", err)
+		return nil
+	}
+	for i := range routes {
+		if routes[i].Auth == "" {
+			routes[i].Auth = GatewayAuthRequired
+		}
+	}
+	return routes
+}
+
+// deriveKey derives the key of purpose from secret, so one secret can back
+// several keys without a key for one purpose working for another.
+func deriveKey(secret, purpose string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// AccessTokenLifetimeFor returns the access token lifetime for users of role.
+func (c AuthConfig) AccessTokenLifetimeFor(role string) time.Duration {
+	if lifetime, ok := c.AccessTokenLifetimeByRole[role]; ok {
+		return lifetime
+	}
+	return c.AccessTokenLifetime
+}
+
+// ExpirationFor returns the session expiration for users of role.
+func (c SessionConfig) ExpirationFor(role string) time.Duration {
+	if expiration, ok := c.ExpirationByRole[role]; ok {
+		return expiration
+	}
+	return c.ExpirationDuration
+}
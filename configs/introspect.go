@@ -0,0 +1,112 @@
+package configs
+
+import (
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// RedactedValue replaces the secrets of a redacted configuration.
+const RedactedValue = "[REDACTED]"
+
+// Redacted returns a copy of c with the secrets that are set replaced by
+// RedactedValue, so the configuration can be shown, e.g. to admins debugging a
+// deployment. Unset secrets stay empty, so they can be told apart.
+func (c Config) Redacted() Config {
+	redact := func(secret *string) {
+		if *secret != "" {
+			*secret = RedactedValue
+		}
+	}
+	redact(&c.Auth.InternalToken)
+	redact(&c.Auth.JWTSecret)
+	redact(&c.Auth.GithubClientSecret)
+	redact(&c.Auth.ProviderTokenKey)
+	credentials := slices.Clone(c.Auth.InternalCredentials)
+	for i := range credentials {
+		keys := slices.Clone(credentials[i].SigningKeys)
+		for j := range keys {
+			redact(&keys[j])
+		}
+		credentials[i].SigningKeys = keys
+	}
+	c.Auth.InternalCredentials = credentials
+	redact(&c.Captcha.Secret)
+	redact(&c.Mailer.SMTPPassword)
+	redact(&c.Audit.PseudonymizationKey)
+	redact(&c.ErrorReporting.DSN)
+	redact(&c.Webhooks.GithubSecret)
+	redact(&c.LoginCode.SMSAuthorization)
+	redact(&c.Provisioning.WebhookSecret)
+	redact(&c.Claims.CalloutAuthorization)
+	redact(&c.Reports.SigningKey)
+	redact(&c.SIEM.HTTPAuthorization)
+	keys := slices.Clone(c.Encryption.Keys)
+	for i := range keys {
+		redact(&keys[i])
+	}
+	c.Encryption.Keys = keys
+	redact(&c.Encryption.IndexKey)
+	// Proxies may take credentials in their URL
+	if proxyURL, err := url.Parse(c.Outbound.ProxyURL); err == nil {
+		c.Outbound.ProxyURL = proxyURL.Redacted()
+	}
+	// Backends' options may hold credentials, e.g. in a connection string
+	options := maps.Clone(c.UserStore.Options)
+	for name, value := range options {
+		redact(&value)
+		options[name] = value
+	}
+	c.UserStore.Options = options
+	redact(&c.Database.Password)
+	redact(&c.Redis.Password)
+	return c
+}
+
+// Sources describes where the configuration of this process came from.
+type Sources struct {
+	// Mode selects the configuration file merged over the defaults
+	Mode string
+	// Files are the configuration files loaded, in the order they were merged
+	Files []string
+	// EnvOverrides are the keys set by environment variables, e.g.
+	// "auth.jwt_secret" for AUTH__JWT_SECRET, which take precedence over the
+	// files
+	EnvOverrides []string
+}
+
+// LoadSources finds the configuration files and environment variables the
+// configuration is loaded from, the way app.Init does: configs/default.* and
+// configs/<MODE>.* in the working directory, then environment variables named
+// after the keys with "." replaced by "__".
+func LoadSources() Sources {
+	sources := Sources{Mode: os.Getenv("MODE")}
+	if sources.Mode == "" {
+		sources.Mode = "development"
+	}
+	cwd, _ := os.Getwd()
+	dir := filepath.Join(cwd, "configs")
+	for _, name := range []string{"default", sources.Mode} {
+		for _, ext := range viper.SupportedExts {
+			path := filepath.Join(dir, name+"."+ext)
+			if _, err := os.Stat(path); err == nil {
+				sources.Files = append(sources.Files, path)
+				break
+			}
+		}
+	}
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		if strings.Contains(name, "__") {
+			key := strings.ToLower(strings.ReplaceAll(name, "__", "."))
+			sources.EnvOverrides = append(sources.EnvOverrides, key)
+		}
+	}
+	slices.Sort(sources.EnvOverrides)
+	return sources
+}
@@ -0,0 +1,220 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: audit/v1/options.proto
+
+package audit_v1_pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Sensitivity ranks how closely calls of an RPC are watched.
+type Sensitivity int32
+
+const (
+	Sensitivity_SENSITIVITY_UNSPECIFIED Sensitivity = 0
+	// Only successful calls are recorded
+	Sensitivity_SENSITIVITY_LOW Sensitivity = 1
+	// Failed and denied calls are recorded as well
+	Sensitivity_SENSITIVITY_MEDIUM Sensitivity = 2
+	// Like SENSITIVITY_MEDIUM; meant for changes to accounts, sessions and settings
+	Sensitivity_SENSITIVITY_HIGH Sensitivity = 3
+)
+
+// Enum value maps for Sensitivity.
+var (
+	Sensitivity_name = map[int32]string{
+		0: "SENSITIVITY_UNSPECIFIED",
+		1: "SENSITIVITY_LOW",
+		2: "SENSITIVITY_MEDIUM",
+		3: "SENSITIVITY_HIGH",
+	}
+	Sensitivity_value = map[string]int32{
+		"SENSITIVITY_UNSPECIFIED": 0,
+		"SENSITIVITY_LOW":         1,
+		"SENSITIVITY_MEDIUM":      2,
+		"SENSITIVITY_HIGH":        3,
+	}
+)
+
+func (x Sensitivity) Enum() *Sensitivity {
+	p := new(Sensitivity)
+	*p = x
+	return p
+}
+
+func (x Sensitivity) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Sensitivity) Descriptor() protoreflect.EnumDescriptor {
+	return file_audit_v1_options_proto_enumTypes[0].Descriptor()
+}
+
+func (Sensitivity) Type() protoreflect.EnumType {
+	return &file_audit_v1_options_proto_enumTypes[0]
+}
+
+func (x Sensitivity) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Sensitivity.Descriptor instead.
+func (Sensitivity) EnumDescriptor() ([]byte, []int) {
+	return file_audit_v1_options_proto_rawDescGZIP(), []int{0}
+}
+
+type MethodAudit struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Record an rpc.called audit event for every call
+	Log           bool        `protobuf:"varint,1,opt,name=log,proto3" json:"log,omitempty"`
+	Sensitivity   Sensitivity `protobuf:"varint,2,opt,name=sensitivity,proto3,enum=audit.v1.Sensitivity" json:"sensitivity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MethodAudit) Reset() {
+	*x = MethodAudit{}
+	mi := &file_audit_v1_options_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MethodAudit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MethodAudit) ProtoMessage() {}
+
+func (x *MethodAudit) ProtoReflect() protoreflect.Message {
+	mi := &file_audit_v1_options_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MethodAudit.ProtoReflect.Descriptor instead.
+func (*MethodAudit) Descriptor() ([]byte, []int) {
+	return file_audit_v1_options_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MethodAudit) GetLog() bool {
+	if x != nil {
+		return x.Log
+	}
+	return false
+}
+
+func (x *MethodAudit) GetSensitivity() Sensitivity {
+	if x != nil {
+		return x.Sensitivity
+	}
+	return Sensitivity_SENSITIVITY_UNSPECIFIED
+}
+
+var file_audit_v1_options_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*MethodAudit)(nil),
+		Field:         51001,
+		Name:          "audit.v1.audit",
+		Tag:           "bytes,51001,opt,name=audit",
+		Filename:      "audit/v1/options.proto",
+	},
+}
+
+// Extension fields to descriptorpb.MethodOptions.
+var (
+	// optional audit.v1.MethodAudit audit = 51001;
+	E_Audit = &file_audit_v1_options_proto_extTypes[0]
+)
+
+var File_audit_v1_options_proto protoreflect.FileDescriptor
+
+const file_audit_v1_options_proto_rawDesc = "" +
+	"\n" +
+	"\x16audit/v1/options.proto\x12\baudit.v1\x1a google/protobuf/descriptor.proto\"X\n" +
+	"\vMethodAudit\x12\x10\n" +
+	"\x03log\x18\x01 \x01(\bR\x03log\x127\n" +
+	"\vsensitivity\x18\x02 \x01(\x0e2\x15.audit.v1.SensitivityR\vsensitivity*m\n" +
+	"\vSensitivity\x12\x1b\n" +
+	"\x17SENSITIVITY_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fSENSITIVITY_LOW\x10\x01\x12\x16\n" +
+	"\x12SENSITIVITY_MEDIUM\x10\x02\x12\x14\n" +
+	"\x10SENSITIVITY_HIGH\x10\x03:M\n" +
+	"\x05audit\x12\x1e.google.protobuf.MethodOptions\x18\xb9\x8e\x03 \x01(\v2\x15.audit.v1.MethodAuditR\x05auditB?Z=github.com/poly-workshop/auth-portal/gen/audit/v1;audit_v1_pbb\x06proto3"
+
+var (
+	file_audit_v1_options_proto_rawDescOnce sync.Once
+	file_audit_v1_options_proto_rawDescData []byte
+)
+
+func file_audit_v1_options_proto_rawDescGZIP() []byte {
+	file_audit_v1_options_proto_rawDescOnce.Do(func() {
+		file_audit_v1_options_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_audit_v1_options_proto_rawDesc), len(file_audit_v1_options_proto_rawDesc)))
+	})
+	return file_audit_v1_options_proto_rawDescData
+}
+
+var file_audit_v1_options_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_audit_v1_options_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_audit_v1_options_proto_goTypes = []any{
+	(Sensitivity)(0),                   // 0: audit.v1.Sensitivity
+	(*MethodAudit)(nil),                // 1: audit.v1.MethodAudit
+	(*descriptorpb.MethodOptions)(nil), // 2: google.protobuf.MethodOptions
+}
+var file_audit_v1_options_proto_depIdxs = []int32{
+	0, // 0: audit.v1.MethodAudit.sensitivity:type_name -> audit.v1.Sensitivity
+	2, // 1: audit.v1.audit:extendee -> google.protobuf.MethodOptions
+	1, // 2: audit.v1.audit:type_name -> audit.v1.MethodAudit
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	2, // [2:3] is the sub-list for extension type_name
+	1, // [1:2] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_audit_v1_options_proto_init() }
+func file_audit_v1_options_proto_init() {
+	if File_audit_v1_options_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_audit_v1_options_proto_rawDesc), len(file_audit_v1_options_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 1,
+			NumServices:   0,
+		},
+		GoTypes:           file_audit_v1_options_proto_goTypes,
+		DependencyIndexes: file_audit_v1_options_proto_depIdxs,
+		EnumInfos:         file_audit_v1_options_proto_enumTypes,
+		MessageInfos:      file_audit_v1_options_proto_msgTypes,
+		ExtensionInfos:    file_audit_v1_options_proto_extTypes,
+	}.Build()
+	File_audit_v1_options_proto = out.File
+	file_audit_v1_options_proto_goTypes = nil
+	file_audit_v1_options_proto_depIdxs = nil
+}
@@ -7,6 +7,8 @@
 package auth_v1_pb
 
 import (
+	_ "github.com/poly-workshop/auth-portal/gen/audit/v1"
+	_ "github.com/poly-workshop/auth-portal/gen/authz/v1"
 	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
@@ -380,10 +382,12 @@ func (x *LoginByPasswordRequest) GetPassword() string {
 }
 
 type LoginByPasswordResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Session       *LoginSession          `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Session *LoginSession          `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+	// Tokens of this session only grant access to ChangePassword until the password was changed
+	MustChangePassword bool `protobuf:"varint,2,opt,name=must_change_password,json=mustChangePassword,proto3" json:"must_change_password,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *LoginByPasswordResponse) Reset() {
@@ -423,6 +427,13 @@ func (x *LoginByPasswordResponse) GetSession() *LoginSession {
 	return nil
 }
 
+func (x *LoginByPasswordResponse) GetMustChangePassword() bool {
+	if x != nil {
+		return x.MustChangePassword
+	}
+	return false
+}
+
 type GetUserTokenRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
@@ -511,46 +522,1772 @@ func (x *GetUserTokenResponse) GetToken() *UserToken {
 	return nil
 }
 
-var File_auth_v1_auth_proto protoreflect.FileDescriptor
+type GetPublicConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_auth_v1_auth_proto_rawDesc = "" +
-	"\n" +
-	"\x12auth/v1/auth.proto\x12\aauth.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\\\n" +
-	"\tUserToken\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\x129\n" +
-	"\n" +
-	"expires_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"Y\n" +
-	"\fLoginSession\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
-	"\n" +
-	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"m\n" +
-	"\x16GetOAuthCodeURLRequest\x12\x1a\n" +
-	"\bprovider\x18\x01 \x01(\tR\bprovider\x12&\n" +
-	"\fredirect_url\x18\x02 \x01(\tH\x00R\vredirectUrl\x88\x01\x01B\x0f\n" +
-	"\r_redirect_url\"A\n" +
-	"\x17GetOAuthCodeURLResponse\x12\x10\n" +
-	"\x03url\x18\x01 \x01(\tR\x03url\x12\x14\n" +
-	"\x05state\x18\x02 \x01(\tR\x05state\"?\n" +
-	"\x13LoginByOAuthRequest\x12\x12\n" +
-	"\x04code\x18\x01 \x01(\tR\x04code\x12\x14\n" +
-	"\x05state\x18\x02 \x01(\tR\x05state\"G\n" +
-	"\x14LoginByOAuthResponse\x12/\n" +
-	"\asession\x18\x01 \x01(\v2\x15.auth.v1.LoginSessionR\asession\"J\n" +
-	"\x16LoginByPasswordRequest\x12\x14\n" +
-	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1a\n" +
-	"\bpassword\x18\x02 \x01(\tR\bpassword\"J\n" +
-	"\x17LoginByPasswordResponse\x12/\n" +
-	"\asession\x18\x01 \x01(\v2\x15.auth.v1.LoginSessionR\asession\"4\n" +
-	"\x13GetUserTokenRequest\x12\x1d\n" +
-	"\n" +
-	"session_id\x18\x01 \x01(\tR\tsessionId\"@\n" +
-	"\x14GetUserTokenResponse\x12(\n" +
-	"\x05token\x18\x01 \x01(\v2\x12.auth.v1.UserTokenR\x05token2\xbb\x03\n" +
-	"\vAuthService\x12k\n" +
-	"\x0fGetOAuthCodeURL\x12\x1f.auth.v1.GetOAuthCodeURLRequest\x1a .auth.v1.GetOAuthCodeURLResponse\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/v1/oauth/url\x12g\n" +
-	"\fLoginByOAuth\x12\x1c.auth.v1.LoginByOAuthRequest\x1a\x1d.auth.v1.LoginByOAuthResponse\"\x1a\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/login/oauth\x12s\n" +
-	"\x0fLoginByPassword\x12\x1f.auth.v1.LoginByPasswordRequest\x1a .auth.v1.LoginByPasswordResponse\"\x1d\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/login/password\x12a\n" +
-	"\fGetUserToken\x12\x1c.auth.v1.GetUserTokenRequest\x1a\x1d.auth.v1.GetUserTokenResponse\"\x14\x82\xd3\xe4\x93\x02\x0e:\x01*\"\t/v1/tokenB=Z;github.com/poly-workshop/auth-portal/gen/auth/v1;auth_v1_pbb\x06proto3"
+func (x *GetPublicConfigRequest) Reset() {
+	*x = GetPublicConfigRequest{}
+	mi := &file_auth_v1_auth_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPublicConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPublicConfigRequest) ProtoMessage() {}
+
+func (x *GetPublicConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPublicConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetPublicConfigRequest) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{10}
+}
+
+type GetPublicConfigResponse struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	PasswordLoginEnabled bool                   `protobuf:"varint,1,opt,name=password_login_enabled,json=passwordLoginEnabled,proto3" json:"password_login_enabled,omitempty"`
+	OauthProviders       []*OAuthProviderInfo   `protobuf:"bytes,2,rep,name=oauth_providers,json=oauthProviders,proto3" json:"oauth_providers,omitempty"`
+	PasswordPolicy       *PasswordPolicy        `protobuf:"bytes,3,opt,name=password_policy,json=passwordPolicy,proto3" json:"password_policy,omitempty"`
+	// Feature flags by name, e.g. "signup_approval"
+	Features map[string]bool `protobuf:"bytes,4,rep,name=features,proto3" json:"features,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	// Public key of the CAPTCHA widget; empty if no CAPTCHA is configured
+	CaptchaSiteKey string `protobuf:"bytes,5,opt,name=captcha_site_key,json=captchaSiteKey,proto3" json:"captcha_site_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetPublicConfigResponse) Reset() {
+	*x = GetPublicConfigResponse{}
+	mi := &file_auth_v1_auth_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPublicConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPublicConfigResponse) ProtoMessage() {}
+
+func (x *GetPublicConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPublicConfigResponse.ProtoReflect.Descriptor instead.
+func (*GetPublicConfigResponse) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetPublicConfigResponse) GetPasswordLoginEnabled() bool {
+	if x != nil {
+		return x.PasswordLoginEnabled
+	}
+	return false
+}
+
+func (x *GetPublicConfigResponse) GetOauthProviders() []*OAuthProviderInfo {
+	if x != nil {
+		return x.OauthProviders
+	}
+	return nil
+}
+
+func (x *GetPublicConfigResponse) GetPasswordPolicy() *PasswordPolicy {
+	if x != nil {
+		return x.PasswordPolicy
+	}
+	return nil
+}
+
+func (x *GetPublicConfigResponse) GetFeatures() map[string]bool {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+func (x *GetPublicConfigResponse) GetCaptchaSiteKey() string {
+	if x != nil {
+		return x.CaptchaSiteKey
+	}
+	return ""
+}
+
+type GetServerInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetServerInfoRequest) Reset() {
+	*x = GetServerInfoRequest{}
+	mi := &file_auth_v1_auth_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetServerInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetServerInfoRequest) ProtoMessage() {}
+
+func (x *GetServerInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetServerInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetServerInfoRequest) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{12}
+}
+
+type GetServerInfoResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Version of the release, e.g. "v1.4.0"; "dev" if the build set none
+	Version string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	// Commit the server was built from; empty if unknown
+	Commit string `protobuf:"bytes,2,opt,name=commit,proto3" json:"commit,omitempty"`
+	// When the server was built, RFC 3339; empty if unknown
+	BuildDate string `protobuf:"bytes,3,opt,name=build_date,json=buildDate,proto3" json:"build_date,omitempty"`
+	// Go version the server was built with
+	GoVersion     string `protobuf:"bytes,4,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetServerInfoResponse) Reset() {
+	*x = GetServerInfoResponse{}
+	mi := &file_auth_v1_auth_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetServerInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetServerInfoResponse) ProtoMessage() {}
+
+func (x *GetServerInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetServerInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetServerInfoResponse) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetServerInfoResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *GetServerInfoResponse) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *GetServerInfoResponse) GetBuildDate() string {
+	if x != nil {
+		return x.BuildDate
+	}
+	return ""
+}
+
+func (x *GetServerInfoResponse) GetGoVersion() string {
+	if x != nil {
+		return x.GoVersion
+	}
+	return ""
+}
+
+type CheckEmailAvailableRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Email string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	// Response of the CAPTCHA widget, required if the email_check_captcha
+	// feature is enabled
+	CaptchaToken  string `protobuf:"bytes,2,opt,name=captcha_token,json=captchaToken,proto3" json:"captcha_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckEmailAvailableRequest) Reset() {
+	*x = CheckEmailAvailableRequest{}
+	mi := &file_auth_v1_auth_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckEmailAvailableRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckEmailAvailableRequest) ProtoMessage() {}
+
+func (x *CheckEmailAvailableRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckEmailAvailableRequest.ProtoReflect.Descriptor instead.
+func (*CheckEmailAvailableRequest) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CheckEmailAvailableRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *CheckEmailAvailableRequest) GetCaptchaToken() string {
+	if x != nil {
+		return x.CaptchaToken
+	}
+	return ""
+}
+
+type CheckEmailAvailableResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Available     bool                   `protobuf:"varint,1,opt,name=available,proto3" json:"available,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckEmailAvailableResponse) Reset() {
+	*x = CheckEmailAvailableResponse{}
+	mi := &file_auth_v1_auth_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckEmailAvailableResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckEmailAvailableResponse) ProtoMessage() {}
+
+func (x *CheckEmailAvailableResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckEmailAvailableResponse.ProtoReflect.Descriptor instead.
+func (*CheckEmailAvailableResponse) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CheckEmailAvailableResponse) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+type OAuthProviderInfo struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Endpoint the provider's login page is served from
+	AuthorizeUrl string `protobuf:"bytes,2,opt,name=authorize_url,json=authorizeUrl,proto3" json:"authorize_url,omitempty"`
+	// Redirect URL registered with the provider; GetOAuthCodeURL may override it
+	RedirectUrl   string   `protobuf:"bytes,3,opt,name=redirect_url,json=redirectUrl,proto3" json:"redirect_url,omitempty"`
+	Scopes        []string `protobuf:"bytes,4,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OAuthProviderInfo) Reset() {
+	*x = OAuthProviderInfo{}
+	mi := &file_auth_v1_auth_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OAuthProviderInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OAuthProviderInfo) ProtoMessage() {}
+
+func (x *OAuthProviderInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OAuthProviderInfo.ProtoReflect.Descriptor instead.
+func (*OAuthProviderInfo) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *OAuthProviderInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *OAuthProviderInfo) GetAuthorizeUrl() string {
+	if x != nil {
+		return x.AuthorizeUrl
+	}
+	return ""
+}
+
+func (x *OAuthProviderInfo) GetRedirectUrl() string {
+	if x != nil {
+		return x.RedirectUrl
+	}
+	return ""
+}
+
+func (x *OAuthProviderInfo) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+type PasswordPolicy struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinLength     uint32                 `protobuf:"varint,1,opt,name=min_length,json=minLength,proto3" json:"min_length,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PasswordPolicy) Reset() {
+	*x = PasswordPolicy{}
+	mi := &file_auth_v1_auth_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PasswordPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PasswordPolicy) ProtoMessage() {}
+
+func (x *PasswordPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PasswordPolicy.ProtoReflect.Descriptor instead.
+func (*PasswordPolicy) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *PasswordPolicy) GetMinLength() uint32 {
+	if x != nil {
+		return x.MinLength
+	}
+	return 0
+}
+
+type GetProviderTokenRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	UserId   string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Provider string                 `protobuf:"bytes,2,opt,name=provider,proto3" json:"provider,omitempty"`
+	// Scopes the token must have been granted; calls fail with FAILED_PRECONDITION
+	// until the user logs in again granting them
+	Scopes []string `protobuf:"bytes,3,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	// Name of the calling service, recorded in the audit log
+	Caller        string `protobuf:"bytes,4,opt,name=caller,proto3" json:"caller,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProviderTokenRequest) Reset() {
+	*x = GetProviderTokenRequest{}
+	mi := &file_auth_v1_auth_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProviderTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProviderTokenRequest) ProtoMessage() {}
+
+func (x *GetProviderTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProviderTokenRequest.ProtoReflect.Descriptor instead.
+func (*GetProviderTokenRequest) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetProviderTokenRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetProviderTokenRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *GetProviderTokenRequest) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+func (x *GetProviderTokenRequest) GetCaller() string {
+	if x != nil {
+		return x.Caller
+	}
+	return ""
+}
+
+type GetProviderTokenResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	AccessToken string                 `protobuf:"bytes,1,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`
+	TokenType   string                 `protobuf:"bytes,2,opt,name=token_type,json=tokenType,proto3" json:"token_type,omitempty"`
+	// Unset for tokens that don't expire
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3,oneof" json:"expires_at,omitempty"`
+	Scopes        []string               `protobuf:"bytes,4,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProviderTokenResponse) Reset() {
+	*x = GetProviderTokenResponse{}
+	mi := &file_auth_v1_auth_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProviderTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProviderTokenResponse) ProtoMessage() {}
+
+func (x *GetProviderTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProviderTokenResponse.ProtoReflect.Descriptor instead.
+func (*GetProviderTokenResponse) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetProviderTokenResponse) GetAccessToken() string {
+	if x != nil {
+		return x.AccessToken
+	}
+	return ""
+}
+
+func (x *GetProviderTokenResponse) GetTokenType() string {
+	if x != nil {
+		return x.TokenType
+	}
+	return ""
+}
+
+func (x *GetProviderTokenResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *GetProviderTokenResponse) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+type RevokeProviderIdentityRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Provider string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	// ID of the user's account at the provider
+	ProviderUserId string `protobuf:"bytes,2,opt,name=provider_user_id,json=providerUserId,proto3" json:"provider_user_id,omitempty"`
+	// Also unlink the identity, unless the user could not log in otherwise
+	Unlink bool `protobuf:"varint,3,opt,name=unlink,proto3" json:"unlink,omitempty"`
+	// Why the provider reported the user, recorded in the audit log
+	Reason        string `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeProviderIdentityRequest) Reset() {
+	*x = RevokeProviderIdentityRequest{}
+	mi := &file_auth_v1_auth_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeProviderIdentityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeProviderIdentityRequest) ProtoMessage() {}
+
+func (x *RevokeProviderIdentityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeProviderIdentityRequest.ProtoReflect.Descriptor instead.
+func (*RevokeProviderIdentityRequest) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RevokeProviderIdentityRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *RevokeProviderIdentityRequest) GetProviderUserId() string {
+	if x != nil {
+		return x.ProviderUserId
+	}
+	return ""
+}
+
+func (x *RevokeProviderIdentityRequest) GetUnlink() bool {
+	if x != nil {
+		return x.Unlink
+	}
+	return false
+}
+
+func (x *RevokeProviderIdentityRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type RevokeProviderIdentityResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of sessions that were revoked
+	RevokedSessions uint32 `protobuf:"varint,1,opt,name=revoked_sessions,json=revokedSessions,proto3" json:"revoked_sessions,omitempty"`
+	Unlinked        bool   `protobuf:"varint,2,opt,name=unlinked,proto3" json:"unlinked,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RevokeProviderIdentityResponse) Reset() {
+	*x = RevokeProviderIdentityResponse{}
+	mi := &file_auth_v1_auth_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeProviderIdentityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeProviderIdentityResponse) ProtoMessage() {}
+
+func (x *RevokeProviderIdentityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeProviderIdentityResponse.ProtoReflect.Descriptor instead.
+func (*RevokeProviderIdentityResponse) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *RevokeProviderIdentityResponse) GetRevokedSessions() uint32 {
+	if x != nil {
+		return x.RevokedSessions
+	}
+	return 0
+}
+
+func (x *RevokeProviderIdentityResponse) GetUnlinked() bool {
+	if x != nil {
+		return x.Unlinked
+	}
+	return false
+}
+
+type StartDeviceAuthorizationRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the device or tool shown to the user for approval, e.g. "authctl"
+	ClientName    string `protobuf:"bytes,1,opt,name=client_name,json=clientName,proto3" json:"client_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartDeviceAuthorizationRequest) Reset() {
+	*x = StartDeviceAuthorizationRequest{}
+	mi := &file_auth_v1_auth_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartDeviceAuthorizationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartDeviceAuthorizationRequest) ProtoMessage() {}
+
+func (x *StartDeviceAuthorizationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartDeviceAuthorizationRequest.ProtoReflect.Descriptor instead.
+func (*StartDeviceAuthorizationRequest) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *StartDeviceAuthorizationRequest) GetClientName() string {
+	if x != nil {
+		return x.ClientName
+	}
+	return ""
+}
+
+type StartDeviceAuthorizationResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Secret the device polls with
+	DeviceCode string `protobuf:"bytes,1,opt,name=device_code,json=deviceCode,proto3" json:"device_code,omitempty"`
+	// Code the user enters at the verification URL, e.g. "WDJB-MJHT"
+	UserCode        string `protobuf:"bytes,2,opt,name=user_code,json=userCode,proto3" json:"user_code,omitempty"`
+	VerificationUri string `protobuf:"bytes,3,opt,name=verification_uri,json=verificationUri,proto3" json:"verification_uri,omitempty"`
+	// Verification URL with the user code filled in, e.g. for a QR code
+	VerificationUriComplete string                 `protobuf:"bytes,4,opt,name=verification_uri_complete,json=verificationUriComplete,proto3" json:"verification_uri_complete,omitempty"`
+	ExpiresAt               *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// Seconds to wait between polls
+	Interval      uint32 `protobuf:"varint,6,opt,name=interval,proto3" json:"interval,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartDeviceAuthorizationResponse) Reset() {
+	*x = StartDeviceAuthorizationResponse{}
+	mi := &file_auth_v1_auth_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartDeviceAuthorizationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartDeviceAuthorizationResponse) ProtoMessage() {}
+
+func (x *StartDeviceAuthorizationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartDeviceAuthorizationResponse.ProtoReflect.Descriptor instead.
+func (*StartDeviceAuthorizationResponse) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *StartDeviceAuthorizationResponse) GetDeviceCode() string {
+	if x != nil {
+		return x.DeviceCode
+	}
+	return ""
+}
+
+func (x *StartDeviceAuthorizationResponse) GetUserCode() string {
+	if x != nil {
+		return x.UserCode
+	}
+	return ""
+}
+
+func (x *StartDeviceAuthorizationResponse) GetVerificationUri() string {
+	if x != nil {
+		return x.VerificationUri
+	}
+	return ""
+}
+
+func (x *StartDeviceAuthorizationResponse) GetVerificationUriComplete() string {
+	if x != nil {
+		return x.VerificationUriComplete
+	}
+	return ""
+}
+
+func (x *StartDeviceAuthorizationResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *StartDeviceAuthorizationResponse) GetInterval() uint32 {
+	if x != nil {
+		return x.Interval
+	}
+	return 0
+}
+
+type ApproveDeviceAuthorizationRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	UserCode string                 `protobuf:"bytes,1,opt,name=user_code,json=userCode,proto3" json:"user_code,omitempty"`
+	// Deny the login instead of approving it
+	Deny          bool `protobuf:"varint,2,opt,name=deny,proto3" json:"deny,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApproveDeviceAuthorizationRequest) Reset() {
+	*x = ApproveDeviceAuthorizationRequest{}
+	mi := &file_auth_v1_auth_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApproveDeviceAuthorizationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveDeviceAuthorizationRequest) ProtoMessage() {}
+
+func (x *ApproveDeviceAuthorizationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveDeviceAuthorizationRequest.ProtoReflect.Descriptor instead.
+func (*ApproveDeviceAuthorizationRequest) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ApproveDeviceAuthorizationRequest) GetUserCode() string {
+	if x != nil {
+		return x.UserCode
+	}
+	return ""
+}
+
+func (x *ApproveDeviceAuthorizationRequest) GetDeny() bool {
+	if x != nil {
+		return x.Deny
+	}
+	return false
+}
+
+type ApproveDeviceAuthorizationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientName    string                 `protobuf:"bytes,1,opt,name=client_name,json=clientName,proto3" json:"client_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApproveDeviceAuthorizationResponse) Reset() {
+	*x = ApproveDeviceAuthorizationResponse{}
+	mi := &file_auth_v1_auth_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApproveDeviceAuthorizationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveDeviceAuthorizationResponse) ProtoMessage() {}
+
+func (x *ApproveDeviceAuthorizationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveDeviceAuthorizationResponse.ProtoReflect.Descriptor instead.
+func (*ApproveDeviceAuthorizationResponse) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ApproveDeviceAuthorizationResponse) GetClientName() string {
+	if x != nil {
+		return x.ClientName
+	}
+	return ""
+}
+
+type PollDeviceAuthorizationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceCode    string                 `protobuf:"bytes,1,opt,name=device_code,json=deviceCode,proto3" json:"device_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PollDeviceAuthorizationRequest) Reset() {
+	*x = PollDeviceAuthorizationRequest{}
+	mi := &file_auth_v1_auth_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PollDeviceAuthorizationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollDeviceAuthorizationRequest) ProtoMessage() {}
+
+func (x *PollDeviceAuthorizationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollDeviceAuthorizationRequest.ProtoReflect.Descriptor instead.
+func (*PollDeviceAuthorizationRequest) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *PollDeviceAuthorizationRequest) GetDeviceCode() string {
+	if x != nil {
+		return x.DeviceCode
+	}
+	return ""
+}
+
+type PollDeviceAuthorizationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Session       *LoginSession          `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PollDeviceAuthorizationResponse) Reset() {
+	*x = PollDeviceAuthorizationResponse{}
+	mi := &file_auth_v1_auth_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PollDeviceAuthorizationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollDeviceAuthorizationResponse) ProtoMessage() {}
+
+func (x *PollDeviceAuthorizationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollDeviceAuthorizationResponse.ProtoReflect.Descriptor instead.
+func (*PollDeviceAuthorizationResponse) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *PollDeviceAuthorizationResponse) GetSession() *LoginSession {
+	if x != nil {
+		return x.Session
+	}
+	return nil
+}
+
+type RequestMagicLinkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestMagicLinkRequest) Reset() {
+	*x = RequestMagicLinkRequest{}
+	mi := &file_auth_v1_auth_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestMagicLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestMagicLinkRequest) ProtoMessage() {}
+
+func (x *RequestMagicLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestMagicLinkRequest.ProtoReflect.Descriptor instead.
+func (*RequestMagicLinkRequest) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *RequestMagicLinkRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type RequestMagicLinkResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// When the link expires, if one was sent
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestMagicLinkResponse) Reset() {
+	*x = RequestMagicLinkResponse{}
+	mi := &file_auth_v1_auth_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestMagicLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestMagicLinkResponse) ProtoMessage() {}
+
+func (x *RequestMagicLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestMagicLinkResponse.ProtoReflect.Descriptor instead.
+func (*RequestMagicLinkResponse) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *RequestMagicLinkResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type ConsumeMagicLinkRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Token of the link, from its token query parameter
+	Token         string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConsumeMagicLinkRequest) Reset() {
+	*x = ConsumeMagicLinkRequest{}
+	mi := &file_auth_v1_auth_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConsumeMagicLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsumeMagicLinkRequest) ProtoMessage() {}
+
+func (x *ConsumeMagicLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsumeMagicLinkRequest.ProtoReflect.Descriptor instead.
+func (*ConsumeMagicLinkRequest) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ConsumeMagicLinkRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type ConsumeMagicLinkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Session       *LoginSession          `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConsumeMagicLinkResponse) Reset() {
+	*x = ConsumeMagicLinkResponse{}
+	mi := &file_auth_v1_auth_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConsumeMagicLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsumeMagicLinkResponse) ProtoMessage() {}
+
+func (x *ConsumeMagicLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsumeMagicLinkResponse.ProtoReflect.Descriptor instead.
+func (*ConsumeMagicLinkResponse) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ConsumeMagicLinkResponse) GetSession() *LoginSession {
+	if x != nil {
+		return x.Session
+	}
+	return nil
+}
+
+type SendLoginCodeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Email string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	// "email" or "sms"; empty uses the first channel enabled in this deployment
+	Channel       string `protobuf:"bytes,2,opt,name=channel,proto3" json:"channel,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendLoginCodeRequest) Reset() {
+	*x = SendLoginCodeRequest{}
+	mi := &file_auth_v1_auth_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendLoginCodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendLoginCodeRequest) ProtoMessage() {}
+
+func (x *SendLoginCodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendLoginCodeRequest.ProtoReflect.Descriptor instead.
+func (*SendLoginCodeRequest) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *SendLoginCodeRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *SendLoginCodeRequest) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+type SendLoginCodeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// When the code expires, if one was sent
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Channel       string                 `protobuf:"bytes,2,opt,name=channel,proto3" json:"channel,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendLoginCodeResponse) Reset() {
+	*x = SendLoginCodeResponse{}
+	mi := &file_auth_v1_auth_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendLoginCodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendLoginCodeResponse) ProtoMessage() {}
+
+func (x *SendLoginCodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendLoginCodeResponse.ProtoReflect.Descriptor instead.
+func (*SendLoginCodeResponse) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *SendLoginCodeResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *SendLoginCodeResponse) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+type VerifyLoginCodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyLoginCodeRequest) Reset() {
+	*x = VerifyLoginCodeRequest{}
+	mi := &file_auth_v1_auth_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyLoginCodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyLoginCodeRequest) ProtoMessage() {}
+
+func (x *VerifyLoginCodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyLoginCodeRequest.ProtoReflect.Descriptor instead.
+func (*VerifyLoginCodeRequest) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *VerifyLoginCodeRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *VerifyLoginCodeRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type VerifyLoginCodeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Session       *LoginSession          `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyLoginCodeResponse) Reset() {
+	*x = VerifyLoginCodeResponse{}
+	mi := &file_auth_v1_auth_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyLoginCodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyLoginCodeResponse) ProtoMessage() {}
+
+func (x *VerifyLoginCodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyLoginCodeResponse.ProtoReflect.Descriptor instead.
+func (*VerifyLoginCodeResponse) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *VerifyLoginCodeResponse) GetSession() *LoginSession {
+	if x != nil {
+		return x.Session
+	}
+	return nil
+}
+
+type CreateHandoffTokenRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the app shown in the audit log, e.g. "Workshop for iOS"
+	ClientName string `protobuf:"bytes,1,opt,name=client_name,json=clientName,proto3" json:"client_name,omitempty"`
+	// Base64url-encoded SHA-256 hash of a secret the app generated (as in PKCE,
+	// RFC 7636); the app must redeem the token with the secret, so other apps
+	// intercepting the redirect can't
+	CodeChallenge string `protobuf:"bytes,2,opt,name=code_challenge,json=codeChallenge,proto3" json:"code_challenge,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateHandoffTokenRequest) Reset() {
+	*x = CreateHandoffTokenRequest{}
+	mi := &file_auth_v1_auth_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateHandoffTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateHandoffTokenRequest) ProtoMessage() {}
+
+func (x *CreateHandoffTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateHandoffTokenRequest.ProtoReflect.Descriptor instead.
+func (*CreateHandoffTokenRequest) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *CreateHandoffTokenRequest) GetClientName() string {
+	if x != nil {
+		return x.ClientName
+	}
+	return ""
+}
+
+func (x *CreateHandoffTokenRequest) GetCodeChallenge() string {
+	if x != nil {
+		return x.CodeChallenge
+	}
+	return ""
+}
+
+type CreateHandoffTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateHandoffTokenResponse) Reset() {
+	*x = CreateHandoffTokenResponse{}
+	mi := &file_auth_v1_auth_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateHandoffTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateHandoffTokenResponse) ProtoMessage() {}
+
+func (x *CreateHandoffTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateHandoffTokenResponse.ProtoReflect.Descriptor instead.
+func (*CreateHandoffTokenResponse) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *CreateHandoffTokenResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *CreateHandoffTokenResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type RedeemHandoffTokenRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Token string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	// The secret hashed into the code_challenge, if one was given
+	CodeVerifier  string `protobuf:"bytes,2,opt,name=code_verifier,json=codeVerifier,proto3" json:"code_verifier,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RedeemHandoffTokenRequest) Reset() {
+	*x = RedeemHandoffTokenRequest{}
+	mi := &file_auth_v1_auth_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RedeemHandoffTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedeemHandoffTokenRequest) ProtoMessage() {}
+
+func (x *RedeemHandoffTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RedeemHandoffTokenRequest.ProtoReflect.Descriptor instead.
+func (*RedeemHandoffTokenRequest) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *RedeemHandoffTokenRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *RedeemHandoffTokenRequest) GetCodeVerifier() string {
+	if x != nil {
+		return x.CodeVerifier
+	}
+	return ""
+}
+
+type RedeemHandoffTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Session       *LoginSession          `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RedeemHandoffTokenResponse) Reset() {
+	*x = RedeemHandoffTokenResponse{}
+	mi := &file_auth_v1_auth_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RedeemHandoffTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedeemHandoffTokenResponse) ProtoMessage() {}
+
+func (x *RedeemHandoffTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_auth_v1_auth_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RedeemHandoffTokenResponse.ProtoReflect.Descriptor instead.
+func (*RedeemHandoffTokenResponse) Descriptor() ([]byte, []int) {
+	return file_auth_v1_auth_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *RedeemHandoffTokenResponse) GetSession() *LoginSession {
+	if x != nil {
+		return x.Session
+	}
+	return nil
+}
+
+var File_auth_v1_auth_proto protoreflect.FileDescriptor
+
+const file_auth_v1_auth_proto_rawDesc = "" +
+	"\n" +
+	"\x12auth/v1/auth.proto\x12\aauth.v1\x1a\x16audit/v1/options.proto\x1a\x16authz/v1/options.proto\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\\\n" +
+	"\tUserToken\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x129\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"Y\n" +
+	"\fLoginSession\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"m\n" +
+	"\x16GetOAuthCodeURLRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12&\n" +
+	"\fredirect_url\x18\x02 \x01(\tH\x00R\vredirectUrl\x88\x01\x01B\x0f\n" +
+	"\r_redirect_url\"A\n" +
+	"\x17GetOAuthCodeURLResponse\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\"?\n" +
+	"\x13LoginByOAuthRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\"G\n" +
+	"\x14LoginByOAuthResponse\x12/\n" +
+	"\asession\x18\x01 \x01(\v2\x15.auth.v1.LoginSessionR\asession\"J\n" +
+	"\x16LoginByPasswordRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"|\n" +
+	"\x17LoginByPasswordResponse\x12/\n" +
+	"\asession\x18\x01 \x01(\v2\x15.auth.v1.LoginSessionR\asession\x120\n" +
+	"\x14must_change_password\x18\x02 \x01(\bR\x12mustChangePassword\"4\n" +
+	"\x13GetUserTokenRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"@\n" +
+	"\x14GetUserTokenResponse\x12(\n" +
+	"\x05token\x18\x01 \x01(\v2\x12.auth.v1.UserTokenR\x05token\"\x18\n" +
+	"\x16GetPublicConfigRequest\"\x89\x03\n" +
+	"\x17GetPublicConfigResponse\x124\n" +
+	"\x16password_login_enabled\x18\x01 \x01(\bR\x14passwordLoginEnabled\x12C\n" +
+	"\x0foauth_providers\x18\x02 \x03(\v2\x1a.auth.v1.OAuthProviderInfoR\x0eoauthProviders\x12@\n" +
+	"\x0fpassword_policy\x18\x03 \x01(\v2\x17.auth.v1.PasswordPolicyR\x0epasswordPolicy\x12J\n" +
+	"\bfeatures\x18\x04 \x03(\v2..auth.v1.GetPublicConfigResponse.FeaturesEntryR\bfeatures\x12(\n" +
+	"\x10captcha_site_key\x18\x05 \x01(\tR\x0ecaptchaSiteKey\x1a;\n" +
+	"\rFeaturesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\bR\x05value:\x028\x01\"\x16\n" +
+	"\x14GetServerInfoRequest\"\x87\x01\n" +
+	"\x15GetServerInfoResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x16\n" +
+	"\x06commit\x18\x02 \x01(\tR\x06commit\x12\x1d\n" +
+	"\n" +
+	"build_date\x18\x03 \x01(\tR\tbuildDate\x12\x1d\n" +
+	"\n" +
+	"go_version\x18\x04 \x01(\tR\tgoVersion\"W\n" +
+	"\x1aCheckEmailAvailableRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12#\n" +
+	"\rcaptcha_token\x18\x02 \x01(\tR\fcaptchaToken\";\n" +
+	"\x1bCheckEmailAvailableResponse\x12\x1c\n" +
+	"\tavailable\x18\x01 \x01(\bR\tavailable\"\x87\x01\n" +
+	"\x11OAuthProviderInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12#\n" +
+	"\rauthorize_url\x18\x02 \x01(\tR\fauthorizeUrl\x12!\n" +
+	"\fredirect_url\x18\x03 \x01(\tR\vredirectUrl\x12\x16\n" +
+	"\x06scopes\x18\x04 \x03(\tR\x06scopes\"/\n" +
+	"\x0ePasswordPolicy\x12\x1d\n" +
+	"\n" +
+	"min_length\x18\x01 \x01(\rR\tminLength\"~\n" +
+	"\x17GetProviderTokenRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1a\n" +
+	"\bprovider\x18\x02 \x01(\tR\bprovider\x12\x16\n" +
+	"\x06scopes\x18\x03 \x03(\tR\x06scopes\x12\x16\n" +
+	"\x06caller\x18\x04 \x01(\tR\x06caller\"\xc3\x01\n" +
+	"\x18GetProviderTokenResponse\x12!\n" +
+	"\faccess_token\x18\x01 \x01(\tR\vaccessToken\x12\x1d\n" +
+	"\n" +
+	"token_type\x18\x02 \x01(\tR\ttokenType\x12>\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampH\x00R\texpiresAt\x88\x01\x01\x12\x16\n" +
+	"\x06scopes\x18\x04 \x03(\tR\x06scopesB\r\n" +
+	"\v_expires_at\"\x95\x01\n" +
+	"\x1dRevokeProviderIdentityRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12(\n" +
+	"\x10provider_user_id\x18\x02 \x01(\tR\x0eproviderUserId\x12\x16\n" +
+	"\x06unlink\x18\x03 \x01(\bR\x06unlink\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\"g\n" +
+	"\x1eRevokeProviderIdentityResponse\x12)\n" +
+	"\x10revoked_sessions\x18\x01 \x01(\rR\x0frevokedSessions\x12\x1a\n" +
+	"\bunlinked\x18\x02 \x01(\bR\bunlinked\"B\n" +
+	"\x1fStartDeviceAuthorizationRequest\x12\x1f\n" +
+	"\vclient_name\x18\x01 \x01(\tR\n" +
+	"clientName\"\x9e\x02\n" +
+	" StartDeviceAuthorizationResponse\x12\x1f\n" +
+	"\vdevice_code\x18\x01 \x01(\tR\n" +
+	"deviceCode\x12\x1b\n" +
+	"\tuser_code\x18\x02 \x01(\tR\buserCode\x12)\n" +
+	"\x10verification_uri\x18\x03 \x01(\tR\x0fverificationUri\x12:\n" +
+	"\x19verification_uri_complete\x18\x04 \x01(\tR\x17verificationUriComplete\x129\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x1a\n" +
+	"\binterval\x18\x06 \x01(\rR\binterval\"T\n" +
+	"!ApproveDeviceAuthorizationRequest\x12\x1b\n" +
+	"\tuser_code\x18\x01 \x01(\tR\buserCode\x12\x12\n" +
+	"\x04deny\x18\x02 \x01(\bR\x04deny\"E\n" +
+	"\"ApproveDeviceAuthorizationResponse\x12\x1f\n" +
+	"\vclient_name\x18\x01 \x01(\tR\n" +
+	"clientName\"A\n" +
+	"\x1ePollDeviceAuthorizationRequest\x12\x1f\n" +
+	"\vdevice_code\x18\x01 \x01(\tR\n" +
+	"deviceCode\"R\n" +
+	"\x1fPollDeviceAuthorizationResponse\x12/\n" +
+	"\asession\x18\x01 \x01(\v2\x15.auth.v1.LoginSessionR\asession\"/\n" +
+	"\x17RequestMagicLinkRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"U\n" +
+	"\x18RequestMagicLinkResponse\x129\n" +
+	"\n" +
+	"expires_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"/\n" +
+	"\x17ConsumeMagicLinkRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"K\n" +
+	"\x18ConsumeMagicLinkResponse\x12/\n" +
+	"\asession\x18\x01 \x01(\v2\x15.auth.v1.LoginSessionR\asession\"F\n" +
+	"\x14SendLoginCodeRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x18\n" +
+	"\achannel\x18\x02 \x01(\tR\achannel\"l\n" +
+	"\x15SendLoginCodeResponse\x129\n" +
+	"\n" +
+	"expires_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x18\n" +
+	"\achannel\x18\x02 \x01(\tR\achannel\"B\n" +
+	"\x16VerifyLoginCodeRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\"J\n" +
+	"\x17VerifyLoginCodeResponse\x12/\n" +
+	"\asession\x18\x01 \x01(\v2\x15.auth.v1.LoginSessionR\asession\"c\n" +
+	"\x19CreateHandoffTokenRequest\x12\x1f\n" +
+	"\vclient_name\x18\x01 \x01(\tR\n" +
+	"clientName\x12%\n" +
+	"\x0ecode_challenge\x18\x02 \x01(\tR\rcodeChallenge\"m\n" +
+	"\x1aCreateHandoffTokenResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x129\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"V\n" +
+	"\x19RedeemHandoffTokenRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12#\n" +
+	"\rcode_verifier\x18\x02 \x01(\tR\fcodeVerifier\"M\n" +
+	"\x1aRedeemHandoffTokenResponse\x12/\n" +
+	"\asession\x18\x01 \x01(\v2\x15.auth.v1.LoginSessionR\asession2\xda\x11\n" +
+	"\vAuthService\x12q\n" +
+	"\x0fGetOAuthCodeURL\x12\x1f.auth.v1.GetOAuthCodeURLRequest\x1a .auth.v1.GetOAuthCodeURLResponse\"\x1b\xc2\xf3\x18\x02\b\x01\x82\xd3\xe4\x93\x02\x0f\x12\r/v1/oauth/url\x12m\n" +
+	"\fLoginByOAuth\x12\x1c.auth.v1.LoginByOAuthRequest\x1a\x1d.auth.v1.LoginByOAuthResponse\" \xc2\xf3\x18\x02\b\x01\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/login/oauth\x12y\n" +
+	"\x0fLoginByPassword\x12\x1f.auth.v1.LoginByPasswordRequest\x1a .auth.v1.LoginByPasswordResponse\"#\xc2\xf3\x18\x02\b\x01\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/login/password\x12g\n" +
+	"\fGetUserToken\x12\x1c.auth.v1.GetUserTokenRequest\x1a\x1d.auth.v1.GetUserTokenResponse\"\x1a\xc2\xf3\x18\x02\b\x01\x82\xd3\xe4\x93\x02\x0e:\x01*\"\t/v1/token\x12k\n" +
+	"\x0fGetPublicConfig\x12\x1f.auth.v1.GetPublicConfigRequest\x1a .auth.v1.GetPublicConfigResponse\"\x15\xc2\xf3\x18\x02\b\x01\x82\xd3\xe4\x93\x02\t\x12\a/config\x12f\n" +
+	"\rGetServerInfo\x12\x1d.auth.v1.GetServerInfoRequest\x1a\x1e.auth.v1.GetServerInfoResponse\"\x16\xc2\xf3\x18\x02\b\x01\x82\xd3\xe4\x93\x02\n" +
+	"\x12\b/version\x12\x89\x01\n" +
+	"\x13CheckEmailAvailable\x12#.auth.v1.CheckEmailAvailableRequest\x1a$.auth.v1.CheckEmailAvailableResponse\"'\xc2\xf3\x18\x02\b\x01\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/v1/signup/check-email\x12x\n" +
+	"\x10RequestMagicLink\x12 .auth.v1.RequestMagicLinkRequest\x1a!.auth.v1.RequestMagicLinkResponse\"\x1f\xc2\xf3\x18\x02\b\x01\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/magic-link\x12\x80\x01\n" +
+	"\x10ConsumeMagicLink\x12 .auth.v1.ConsumeMagicLinkRequest\x1a!.auth.v1.ConsumeMagicLinkResponse\"'\xc2\xf3\x18\x02\b\x01\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/v1/magic-link/consume\x12o\n" +
+	"\rSendLoginCode\x12\x1d.auth.v1.SendLoginCodeRequest\x1a\x1e.auth.v1.SendLoginCodeResponse\"\x1f\xc2\xf3\x18\x02\b\x01\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/login/code\x12|\n" +
+	"\x0fVerifyLoginCode\x12\x1f.auth.v1.VerifyLoginCodeRequest\x1a .auth.v1.VerifyLoginCodeResponse\"&\xc2\xf3\x18\x02\b\x01\x82\xd3\xe4\x93\x02\x1a:\x01*\"\x15/v1/login/code/verify\x12\x83\x01\n" +
+	"\x12CreateHandoffToken\x12\".auth.v1.CreateHandoffTokenRequest\x1a#.auth.v1.CreateHandoffTokenResponse\"$\xc2\xf3\x18\x02\b\x02\xca\xf3\x18\x04\b\x01\x10\x03\x82\xd3\xe4\x93\x02\x10:\x01*\"\v/v1/handoff\x12\x82\x01\n" +
+	"\x12RedeemHandoffToken\x12\".auth.v1.RedeemHandoffTokenRequest\x1a#.auth.v1.RedeemHandoffTokenResponse\"#\xc2\xf3\x18\x02\b\x01\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/handoff/redeem\x12\x91\x01\n" +
+	"\x18StartDeviceAuthorization\x12(.auth.v1.StartDeviceAuthorizationRequest\x1a).auth.v1.StartDeviceAuthorizationResponse\" \xc2\xf3\x18\x02\b\x01\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/device/code\x12\xa2\x01\n" +
+	"\x1aApproveDeviceAuthorization\x12*.auth.v1.ApproveDeviceAuthorizationRequest\x1a+.auth.v1.ApproveDeviceAuthorizationResponse\"+\xc2\xf3\x18\x02\b\x02\xca\xf3\x18\x04\b\x01\x10\x03\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/device/approve\x12\x8f\x01\n" +
+	"\x17PollDeviceAuthorization\x12'.auth.v1.PollDeviceAuthorizationRequest\x1a(.auth.v1.PollDeviceAuthorizationResponse\"!\xc2\xf3\x18\x02\b\x01\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/v1/device/token\x12g\n" +
+	"\x10GetProviderToken\x12 .auth.v1.GetProviderTokenRequest\x1a!.auth.v1.GetProviderTokenResponse\"\x0e\xc2\xf3\x18\x02\b\x04\xca\xf3\x18\x04\b\x01\x10\x03\x12y\n" +
+	"\x16RevokeProviderIdentity\x12&.auth.v1.RevokeProviderIdentityRequest\x1a'.auth.v1.RevokeProviderIdentityResponse\"\x0e\xc2\xf3\x18\x02\b\x04\xca\xf3\x18\x04\b\x01\x10\x03B=Z;github.com/poly-workshop/auth-portal/gen/auth/v1;auth_v1_pbb\x06proto3"
 
 var (
 	file_auth_v1_auth_proto_rawDescOnce sync.Once
@@ -564,39 +2301,110 @@ func file_auth_v1_auth_proto_rawDescGZIP() []byte {
 	return file_auth_v1_auth_proto_rawDescData
 }
 
-var file_auth_v1_auth_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_auth_v1_auth_proto_msgTypes = make([]protoimpl.MessageInfo, 41)
 var file_auth_v1_auth_proto_goTypes = []any{
-	(*UserToken)(nil),               // 0: auth.v1.UserToken
-	(*LoginSession)(nil),            // 1: auth.v1.LoginSession
-	(*GetOAuthCodeURLRequest)(nil),  // 2: auth.v1.GetOAuthCodeURLRequest
-	(*GetOAuthCodeURLResponse)(nil), // 3: auth.v1.GetOAuthCodeURLResponse
-	(*LoginByOAuthRequest)(nil),     // 4: auth.v1.LoginByOAuthRequest
-	(*LoginByOAuthResponse)(nil),    // 5: auth.v1.LoginByOAuthResponse
-	(*LoginByPasswordRequest)(nil),  // 6: auth.v1.LoginByPasswordRequest
-	(*LoginByPasswordResponse)(nil), // 7: auth.v1.LoginByPasswordResponse
-	(*GetUserTokenRequest)(nil),     // 8: auth.v1.GetUserTokenRequest
-	(*GetUserTokenResponse)(nil),    // 9: auth.v1.GetUserTokenResponse
-	(*timestamppb.Timestamp)(nil),   // 10: google.protobuf.Timestamp
+	(*UserToken)(nil),                          // 0: auth.v1.UserToken
+	(*LoginSession)(nil),                       // 1: auth.v1.LoginSession
+	(*GetOAuthCodeURLRequest)(nil),             // 2: auth.v1.GetOAuthCodeURLRequest
+	(*GetOAuthCodeURLResponse)(nil),            // 3: auth.v1.GetOAuthCodeURLResponse
+	(*LoginByOAuthRequest)(nil),                // 4: auth.v1.LoginByOAuthRequest
+	(*LoginByOAuthResponse)(nil),               // 5: auth.v1.LoginByOAuthResponse
+	(*LoginByPasswordRequest)(nil),             // 6: auth.v1.LoginByPasswordRequest
+	(*LoginByPasswordResponse)(nil),            // 7: auth.v1.LoginByPasswordResponse
+	(*GetUserTokenRequest)(nil),                // 8: auth.v1.GetUserTokenRequest
+	(*GetUserTokenResponse)(nil),               // 9: auth.v1.GetUserTokenResponse
+	(*GetPublicConfigRequest)(nil),             // 10: auth.v1.GetPublicConfigRequest
+	(*GetPublicConfigResponse)(nil),            // 11: auth.v1.GetPublicConfigResponse
+	(*GetServerInfoRequest)(nil),               // 12: auth.v1.GetServerInfoRequest
+	(*GetServerInfoResponse)(nil),              // 13: auth.v1.GetServerInfoResponse
+	(*CheckEmailAvailableRequest)(nil),         // 14: auth.v1.CheckEmailAvailableRequest
+	(*CheckEmailAvailableResponse)(nil),        // 15: auth.v1.CheckEmailAvailableResponse
+	(*OAuthProviderInfo)(nil),                  // 16: auth.v1.OAuthProviderInfo
+	(*PasswordPolicy)(nil),                     // 17: auth.v1.PasswordPolicy
+	(*GetProviderTokenRequest)(nil),            // 18: auth.v1.GetProviderTokenRequest
+	(*GetProviderTokenResponse)(nil),           // 19: auth.v1.GetProviderTokenResponse
+	(*RevokeProviderIdentityRequest)(nil),      // 20: auth.v1.RevokeProviderIdentityRequest
+	(*RevokeProviderIdentityResponse)(nil),     // 21: auth.v1.RevokeProviderIdentityResponse
+	(*StartDeviceAuthorizationRequest)(nil),    // 22: auth.v1.StartDeviceAuthorizationRequest
+	(*StartDeviceAuthorizationResponse)(nil),   // 23: auth.v1.StartDeviceAuthorizationResponse
+	(*ApproveDeviceAuthorizationRequest)(nil),  // 24: auth.v1.ApproveDeviceAuthorizationRequest
+	(*ApproveDeviceAuthorizationResponse)(nil), // 25: auth.v1.ApproveDeviceAuthorizationResponse
+	(*PollDeviceAuthorizationRequest)(nil),     // 26: auth.v1.PollDeviceAuthorizationRequest
+	(*PollDeviceAuthorizationResponse)(nil),    // 27: auth.v1.PollDeviceAuthorizationResponse
+	(*RequestMagicLinkRequest)(nil),            // 28: auth.v1.RequestMagicLinkRequest
+	(*RequestMagicLinkResponse)(nil),           // 29: auth.v1.RequestMagicLinkResponse
+	(*ConsumeMagicLinkRequest)(nil),            // 30: auth.v1.ConsumeMagicLinkRequest
+	(*ConsumeMagicLinkResponse)(nil),           // 31: auth.v1.ConsumeMagicLinkResponse
+	(*SendLoginCodeRequest)(nil),               // 32: auth.v1.SendLoginCodeRequest
+	(*SendLoginCodeResponse)(nil),              // 33: auth.v1.SendLoginCodeResponse
+	(*VerifyLoginCodeRequest)(nil),             // 34: auth.v1.VerifyLoginCodeRequest
+	(*VerifyLoginCodeResponse)(nil),            // 35: auth.v1.VerifyLoginCodeResponse
+	(*CreateHandoffTokenRequest)(nil),          // 36: auth.v1.CreateHandoffTokenRequest
+	(*CreateHandoffTokenResponse)(nil),         // 37: auth.v1.CreateHandoffTokenResponse
+	(*RedeemHandoffTokenRequest)(nil),          // 38: auth.v1.RedeemHandoffTokenRequest
+	(*RedeemHandoffTokenResponse)(nil),         // 39: auth.v1.RedeemHandoffTokenResponse
+	nil,                                        // 40: auth.v1.GetPublicConfigResponse.FeaturesEntry
+	(*timestamppb.Timestamp)(nil),              // 41: google.protobuf.Timestamp
 }
 var file_auth_v1_auth_proto_depIdxs = []int32{
-	10, // 0: auth.v1.UserToken.expires_at:type_name -> google.protobuf.Timestamp
-	10, // 1: auth.v1.LoginSession.expires_at:type_name -> google.protobuf.Timestamp
+	41, // 0: auth.v1.UserToken.expires_at:type_name -> google.protobuf.Timestamp
+	41, // 1: auth.v1.LoginSession.expires_at:type_name -> google.protobuf.Timestamp
 	1,  // 2: auth.v1.LoginByOAuthResponse.session:type_name -> auth.v1.LoginSession
 	1,  // 3: auth.v1.LoginByPasswordResponse.session:type_name -> auth.v1.LoginSession
 	0,  // 4: auth.v1.GetUserTokenResponse.token:type_name -> auth.v1.UserToken
-	2,  // 5: auth.v1.AuthService.GetOAuthCodeURL:input_type -> auth.v1.GetOAuthCodeURLRequest
-	4,  // 6: auth.v1.AuthService.LoginByOAuth:input_type -> auth.v1.LoginByOAuthRequest
-	6,  // 7: auth.v1.AuthService.LoginByPassword:input_type -> auth.v1.LoginByPasswordRequest
-	8,  // 8: auth.v1.AuthService.GetUserToken:input_type -> auth.v1.GetUserTokenRequest
-	3,  // 9: auth.v1.AuthService.GetOAuthCodeURL:output_type -> auth.v1.GetOAuthCodeURLResponse
-	5,  // 10: auth.v1.AuthService.LoginByOAuth:output_type -> auth.v1.LoginByOAuthResponse
-	7,  // 11: auth.v1.AuthService.LoginByPassword:output_type -> auth.v1.LoginByPasswordResponse
-	9,  // 12: auth.v1.AuthService.GetUserToken:output_type -> auth.v1.GetUserTokenResponse
-	9,  // [9:13] is the sub-list for method output_type
-	5,  // [5:9] is the sub-list for method input_type
-	5,  // [5:5] is the sub-list for extension type_name
-	5,  // [5:5] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+	16, // 5: auth.v1.GetPublicConfigResponse.oauth_providers:type_name -> auth.v1.OAuthProviderInfo
+	17, // 6: auth.v1.GetPublicConfigResponse.password_policy:type_name -> auth.v1.PasswordPolicy
+	40, // 7: auth.v1.GetPublicConfigResponse.features:type_name -> auth.v1.GetPublicConfigResponse.FeaturesEntry
+	41, // 8: auth.v1.GetProviderTokenResponse.expires_at:type_name -> google.protobuf.Timestamp
+	41, // 9: auth.v1.StartDeviceAuthorizationResponse.expires_at:type_name -> google.protobuf.Timestamp
+	1,  // 10: auth.v1.PollDeviceAuthorizationResponse.session:type_name -> auth.v1.LoginSession
+	41, // 11: auth.v1.RequestMagicLinkResponse.expires_at:type_name -> google.protobuf.Timestamp
+	1,  // 12: auth.v1.ConsumeMagicLinkResponse.session:type_name -> auth.v1.LoginSession
+	41, // 13: auth.v1.SendLoginCodeResponse.expires_at:type_name -> google.protobuf.Timestamp
+	1,  // 14: auth.v1.VerifyLoginCodeResponse.session:type_name -> auth.v1.LoginSession
+	41, // 15: auth.v1.CreateHandoffTokenResponse.expires_at:type_name -> google.protobuf.Timestamp
+	1,  // 16: auth.v1.RedeemHandoffTokenResponse.session:type_name -> auth.v1.LoginSession
+	2,  // 17: auth.v1.AuthService.GetOAuthCodeURL:input_type -> auth.v1.GetOAuthCodeURLRequest
+	4,  // 18: auth.v1.AuthService.LoginByOAuth:input_type -> auth.v1.LoginByOAuthRequest
+	6,  // 19: auth.v1.AuthService.LoginByPassword:input_type -> auth.v1.LoginByPasswordRequest
+	8,  // 20: auth.v1.AuthService.GetUserToken:input_type -> auth.v1.GetUserTokenRequest
+	10, // 21: auth.v1.AuthService.GetPublicConfig:input_type -> auth.v1.GetPublicConfigRequest
+	12, // 22: auth.v1.AuthService.GetServerInfo:input_type -> auth.v1.GetServerInfoRequest
+	14, // 23: auth.v1.AuthService.CheckEmailAvailable:input_type -> auth.v1.CheckEmailAvailableRequest
+	28, // 24: auth.v1.AuthService.RequestMagicLink:input_type -> auth.v1.RequestMagicLinkRequest
+	30, // 25: auth.v1.AuthService.ConsumeMagicLink:input_type -> auth.v1.ConsumeMagicLinkRequest
+	32, // 26: auth.v1.AuthService.SendLoginCode:input_type -> auth.v1.SendLoginCodeRequest
+	34, // 27: auth.v1.AuthService.VerifyLoginCode:input_type -> auth.v1.VerifyLoginCodeRequest
+	36, // 28: auth.v1.AuthService.CreateHandoffToken:input_type -> auth.v1.CreateHandoffTokenRequest
+	38, // 29: auth.v1.AuthService.RedeemHandoffToken:input_type -> auth.v1.RedeemHandoffTokenRequest
+	22, // 30: auth.v1.AuthService.StartDeviceAuthorization:input_type -> auth.v1.StartDeviceAuthorizationRequest
+	24, // 31: auth.v1.AuthService.ApproveDeviceAuthorization:input_type -> auth.v1.ApproveDeviceAuthorizationRequest
+	26, // 32: auth.v1.AuthService.PollDeviceAuthorization:input_type -> auth.v1.PollDeviceAuthorizationRequest
+	18, // 33: auth.v1.AuthService.GetProviderToken:input_type -> auth.v1.GetProviderTokenRequest
+	20, // 34: auth.v1.AuthService.RevokeProviderIdentity:input_type -> auth.v1.RevokeProviderIdentityRequest
+	3,  // 35: auth.v1.AuthService.GetOAuthCodeURL:output_type -> auth.v1.GetOAuthCodeURLResponse
+	5,  // 36: auth.v1.AuthService.LoginByOAuth:output_type -> auth.v1.LoginByOAuthResponse
+	7,  // 37: auth.v1.AuthService.LoginByPassword:output_type -> auth.v1.LoginByPasswordResponse
+	9,  // 38: auth.v1.AuthService.GetUserToken:output_type -> auth.v1.GetUserTokenResponse
+	11, // 39: auth.v1.AuthService.GetPublicConfig:output_type -> auth.v1.GetPublicConfigResponse
+	13, // 40: auth.v1.AuthService.GetServerInfo:output_type -> auth.v1.GetServerInfoResponse
+	15, // 41: auth.v1.AuthService.CheckEmailAvailable:output_type -> auth.v1.CheckEmailAvailableResponse
+	29, // 42: auth.v1.AuthService.RequestMagicLink:output_type -> auth.v1.RequestMagicLinkResponse
+	31, // 43: auth.v1.AuthService.ConsumeMagicLink:output_type -> auth.v1.ConsumeMagicLinkResponse
+	33, // 44: auth.v1.AuthService.SendLoginCode:output_type -> auth.v1.SendLoginCodeResponse
+	35, // 45: auth.v1.AuthService.VerifyLoginCode:output_type -> auth.v1.VerifyLoginCodeResponse
+	37, // 46: auth.v1.AuthService.CreateHandoffToken:output_type -> auth.v1.CreateHandoffTokenResponse
+	39, // 47: auth.v1.AuthService.RedeemHandoffToken:output_type -> auth.v1.RedeemHandoffTokenResponse
+	23, // 48: auth.v1.AuthService.StartDeviceAuthorization:output_type -> auth.v1.StartDeviceAuthorizationResponse
+	25, // 49: auth.v1.AuthService.ApproveDeviceAuthorization:output_type -> auth.v1.ApproveDeviceAuthorizationResponse
+	27, // 50: auth.v1.AuthService.PollDeviceAuthorization:output_type -> auth.v1.PollDeviceAuthorizationResponse
+	19, // 51: auth.v1.AuthService.GetProviderToken:output_type -> auth.v1.GetProviderTokenResponse
+	21, // 52: auth.v1.AuthService.RevokeProviderIdentity:output_type -> auth.v1.RevokeProviderIdentityResponse
+	35, // [35:53] is the sub-list for method output_type
+	17, // [17:35] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
 }
 
 func init() { file_auth_v1_auth_proto_init() }
@@ -605,13 +2413,14 @@ func file_auth_v1_auth_proto_init() {
 		return
 	}
 	file_auth_v1_auth_proto_msgTypes[2].OneofWrappers = []any{}
+	file_auth_v1_auth_proto_msgTypes[19].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_auth_v1_auth_proto_rawDesc), len(file_auth_v1_auth_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   10,
+			NumMessages:   41,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,10 +19,24 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	AuthService_GetOAuthCodeURL_FullMethodName = "/auth.v1.AuthService/GetOAuthCodeURL"
-	AuthService_LoginByOAuth_FullMethodName    = "/auth.v1.AuthService/LoginByOAuth"
-	AuthService_LoginByPassword_FullMethodName = "/auth.v1.AuthService/LoginByPassword"
-	AuthService_GetUserToken_FullMethodName    = "/auth.v1.AuthService/GetUserToken"
+	AuthService_GetOAuthCodeURL_FullMethodName            = "/auth.v1.AuthService/GetOAuthCodeURL"
+	AuthService_LoginByOAuth_FullMethodName               = "/auth.v1.AuthService/LoginByOAuth"
+	AuthService_LoginByPassword_FullMethodName            = "/auth.v1.AuthService/LoginByPassword"
+	AuthService_GetUserToken_FullMethodName               = "/auth.v1.AuthService/GetUserToken"
+	AuthService_GetPublicConfig_FullMethodName            = "/auth.v1.AuthService/GetPublicConfig"
+	AuthService_GetServerInfo_FullMethodName              = "/auth.v1.AuthService/GetServerInfo"
+	AuthService_CheckEmailAvailable_FullMethodName        = "/auth.v1.AuthService/CheckEmailAvailable"
+	AuthService_RequestMagicLink_FullMethodName           = "/auth.v1.AuthService/RequestMagicLink"
+	AuthService_ConsumeMagicLink_FullMethodName           = "/auth.v1.AuthService/ConsumeMagicLink"
+	AuthService_SendLoginCode_FullMethodName              = "/auth.v1.AuthService/SendLoginCode"
+	AuthService_VerifyLoginCode_FullMethodName            = "/auth.v1.AuthService/VerifyLoginCode"
+	AuthService_CreateHandoffToken_FullMethodName         = "/auth.v1.AuthService/CreateHandoffToken"
+	AuthService_RedeemHandoffToken_FullMethodName         = "/auth.v1.AuthService/RedeemHandoffToken"
+	AuthService_StartDeviceAuthorization_FullMethodName   = "/auth.v1.AuthService/StartDeviceAuthorization"
+	AuthService_ApproveDeviceAuthorization_FullMethodName = "/auth.v1.AuthService/ApproveDeviceAuthorization"
+	AuthService_PollDeviceAuthorization_FullMethodName    = "/auth.v1.AuthService/PollDeviceAuthorization"
+	AuthService_GetProviderToken_FullMethodName           = "/auth.v1.AuthService/GetProviderToken"
+	AuthService_RevokeProviderIdentity_FullMethodName     = "/auth.v1.AuthService/RevokeProviderIdentity"
 )
 
 // AuthServiceClient is the client API for AuthService service.
@@ -30,9 +44,58 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type AuthServiceClient interface {
 	GetOAuthCodeURL(ctx context.Context, in *GetOAuthCodeURLRequest, opts ...grpc.CallOption) (*GetOAuthCodeURLResponse, error)
+	// Requests sent again with the same Idempotency-Key header (metadata
+	// "idempotency-key") get the response of the first one for a short while,
+	// instead of an error about the state and code it used.
 	LoginByOAuth(ctx context.Context, in *LoginByOAuthRequest, opts ...grpc.CallOption) (*LoginByOAuthResponse, error)
 	LoginByPassword(ctx context.Context, in *LoginByPasswordRequest, opts ...grpc.CallOption) (*LoginByPasswordResponse, error)
 	GetUserToken(ctx context.Context, in *GetUserTokenRequest, opts ...grpc.CallOption) (*GetUserTokenResponse, error)
+	// GetPublicConfig describes the login options of this deployment to unauthenticated clients
+	GetPublicConfig(ctx context.Context, in *GetPublicConfigRequest, opts ...grpc.CallOption) (*GetPublicConfigResponse, error)
+	// GetServerInfo reports the build of the server, so operators and the SPA
+	// can detect deployments running mismatched versions
+	GetServerInfo(ctx context.Context, in *GetServerInfoRequest, opts ...grpc.CallOption) (*GetServerInfoResponse, error)
+	// CheckEmailAvailable tells the signup form whether an email address is
+	// still free; rate limited per client network
+	CheckEmailAvailable(ctx context.Context, in *CheckEmailAvailableRequest, opts ...grpc.CallOption) (*CheckEmailAvailableResponse, error)
+	// RequestMagicLink emails a single-use link logging the user in, as a
+	// passwordless alternative to OAuth. It answers the same whether or not an
+	// account uses the address; rate limited per address
+	RequestMagicLink(ctx context.Context, in *RequestMagicLinkRequest, opts ...grpc.CallOption) (*RequestMagicLinkResponse, error)
+	// ConsumeMagicLink exchanges the token of a magic link for a session
+	ConsumeMagicLink(ctx context.Context, in *ConsumeMagicLinkRequest, opts ...grpc.CallOption) (*ConsumeMagicLinkResponse, error)
+	// SendLoginCode sends a 6-digit login code to the account using the email
+	// address, by email or SMS, for users who can't use OAuth. It answers the
+	// same whether or not an account uses the address; rate limited per address
+	SendLoginCode(ctx context.Context, in *SendLoginCodeRequest, opts ...grpc.CallOption) (*SendLoginCodeResponse, error)
+	// VerifyLoginCode exchanges the login code for a session. Too many wrong
+	// codes void the code (RESOURCE_EXHAUSTED) and a new one must be sent
+	VerifyLoginCode(ctx context.Context, in *VerifyLoginCodeRequest, opts ...grpc.CallOption) (*VerifyLoginCodeResponse, error)
+	// CreateHandoffToken issues a short-lived, single-use token the web app
+	// passes to a native app (e.g. in a custom-scheme redirect), which redeems it
+	// for a session of its own instead of reading cookies from a WebView
+	CreateHandoffToken(ctx context.Context, in *CreateHandoffTokenRequest, opts ...grpc.CallOption) (*CreateHandoffTokenResponse, error)
+	// RedeemHandoffToken exchanges a handoff token for a new session of the user,
+	// as long as the web session it was created with is still active
+	RedeemHandoffToken(ctx context.Context, in *RedeemHandoffTokenRequest, opts ...grpc.CallOption) (*RedeemHandoffTokenResponse, error)
+	// StartDeviceAuthorization begins the login of a device without a browser,
+	// such as a CLI (RFC 8628): the user approves the user code at the
+	// verification URL while the device polls PollDeviceAuthorization
+	StartDeviceAuthorization(ctx context.Context, in *StartDeviceAuthorizationRequest, opts ...grpc.CallOption) (*StartDeviceAuthorizationResponse, error)
+	// ApproveDeviceAuthorization lets the logged-in user approve or deny a device
+	// login by its user code, from the /activate page
+	ApproveDeviceAuthorization(ctx context.Context, in *ApproveDeviceAuthorizationRequest, opts ...grpc.CallOption) (*ApproveDeviceAuthorizationResponse, error)
+	// PollDeviceAuthorization returns a session of the approving user once the
+	// device login was approved. Until then it fails with an ErrorInfo reason:
+	// AUTHORIZATION_PENDING, SLOW_DOWN (poll less often), ACCESS_DENIED or
+	// DEVICE_CODE_EXPIRED
+	PollDeviceAuthorization(ctx context.Context, in *PollDeviceAuthorizationRequest, opts ...grpc.CallOption) (*PollDeviceAuthorizationResponse, error)
+	// GetProviderToken gives other services the provider token of a user's last
+	// OAuth login, refreshed if it expired, to call the provider on their behalf
+	GetProviderToken(ctx context.Context, in *GetProviderTokenRequest, opts ...grpc.CallOption) (*GetProviderTokenResponse, error)
+	// RevokeProviderIdentity reacts to a provider reporting that a user revoked
+	// the authorization or their account was compromised, e.g. from a webhook
+	RevokeProviderIdentity(ctx context.Context, in *RevokeProviderIdentityRequest, opts ...grpc.CallOption) (*RevokeProviderIdentityResponse, error)
 }
 
 type authServiceClient struct {
@@ -83,14 +146,203 @@ func (c *authServiceClient) GetUserToken(ctx context.Context, in *GetUserTokenRe
 	return out, nil
 }
 
+func (c *authServiceClient) GetPublicConfig(ctx context.Context, in *GetPublicConfigRequest, opts ...grpc.CallOption) (*GetPublicConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPublicConfigResponse)
+	err := c.cc.Invoke(ctx, AuthService_GetPublicConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) GetServerInfo(ctx context.Context, in *GetServerInfoRequest, opts ...grpc.CallOption) (*GetServerInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetServerInfoResponse)
+	err := c.cc.Invoke(ctx, AuthService_GetServerInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) CheckEmailAvailable(ctx context.Context, in *CheckEmailAvailableRequest, opts ...grpc.CallOption) (*CheckEmailAvailableResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckEmailAvailableResponse)
+	err := c.cc.Invoke(ctx, AuthService_CheckEmailAvailable_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) RequestMagicLink(ctx context.Context, in *RequestMagicLinkRequest, opts ...grpc.CallOption) (*RequestMagicLinkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RequestMagicLinkResponse)
+	err := c.cc.Invoke(ctx, AuthService_RequestMagicLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) ConsumeMagicLink(ctx context.Context, in *ConsumeMagicLinkRequest, opts ...grpc.CallOption) (*ConsumeMagicLinkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConsumeMagicLinkResponse)
+	err := c.cc.Invoke(ctx, AuthService_ConsumeMagicLink_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) SendLoginCode(ctx context.Context, in *SendLoginCodeRequest, opts ...grpc.CallOption) (*SendLoginCodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendLoginCodeResponse)
+	err := c.cc.Invoke(ctx, AuthService_SendLoginCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) VerifyLoginCode(ctx context.Context, in *VerifyLoginCodeRequest, opts ...grpc.CallOption) (*VerifyLoginCodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyLoginCodeResponse)
+	err := c.cc.Invoke(ctx, AuthService_VerifyLoginCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) CreateHandoffToken(ctx context.Context, in *CreateHandoffTokenRequest, opts ...grpc.CallOption) (*CreateHandoffTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateHandoffTokenResponse)
+	err := c.cc.Invoke(ctx, AuthService_CreateHandoffToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) RedeemHandoffToken(ctx context.Context, in *RedeemHandoffTokenRequest, opts ...grpc.CallOption) (*RedeemHandoffTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RedeemHandoffTokenResponse)
+	err := c.cc.Invoke(ctx, AuthService_RedeemHandoffToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) StartDeviceAuthorization(ctx context.Context, in *StartDeviceAuthorizationRequest, opts ...grpc.CallOption) (*StartDeviceAuthorizationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartDeviceAuthorizationResponse)
+	err := c.cc.Invoke(ctx, AuthService_StartDeviceAuthorization_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) ApproveDeviceAuthorization(ctx context.Context, in *ApproveDeviceAuthorizationRequest, opts ...grpc.CallOption) (*ApproveDeviceAuthorizationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApproveDeviceAuthorizationResponse)
+	err := c.cc.Invoke(ctx, AuthService_ApproveDeviceAuthorization_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) PollDeviceAuthorization(ctx context.Context, in *PollDeviceAuthorizationRequest, opts ...grpc.CallOption) (*PollDeviceAuthorizationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PollDeviceAuthorizationResponse)
+	err := c.cc.Invoke(ctx, AuthService_PollDeviceAuthorization_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) GetProviderToken(ctx context.Context, in *GetProviderTokenRequest, opts ...grpc.CallOption) (*GetProviderTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetProviderTokenResponse)
+	err := c.cc.Invoke(ctx, AuthService_GetProviderToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) RevokeProviderIdentity(ctx context.Context, in *RevokeProviderIdentityRequest, opts ...grpc.CallOption) (*RevokeProviderIdentityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeProviderIdentityResponse)
+	err := c.cc.Invoke(ctx, AuthService_RevokeProviderIdentity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AuthServiceServer is the server API for AuthService service.
 // All implementations must embed UnimplementedAuthServiceServer
 // for forward compatibility.
 type AuthServiceServer interface {
 	GetOAuthCodeURL(context.Context, *GetOAuthCodeURLRequest) (*GetOAuthCodeURLResponse, error)
+	// Requests sent again with the same Idempotency-Key header (metadata
+	// "idempotency-key") get the response of the first one for a short while,
+	// instead of an error about the state and code it used.
 	LoginByOAuth(context.Context, *LoginByOAuthRequest) (*LoginByOAuthResponse, error)
 	LoginByPassword(context.Context, *LoginByPasswordRequest) (*LoginByPasswordResponse, error)
 	GetUserToken(context.Context, *GetUserTokenRequest) (*GetUserTokenResponse, error)
+	// GetPublicConfig describes the login options of this deployment to unauthenticated clients
+	GetPublicConfig(context.Context, *GetPublicConfigRequest) (*GetPublicConfigResponse, error)
+	// GetServerInfo reports the build of the server, so operators and the SPA
+	// can detect deployments running mismatched versions
+	GetServerInfo(context.Context, *GetServerInfoRequest) (*GetServerInfoResponse, error)
+	// CheckEmailAvailable tells the signup form whether an email address is
+	// still free; rate limited per client network
+	CheckEmailAvailable(context.Context, *CheckEmailAvailableRequest) (*CheckEmailAvailableResponse, error)
+	// RequestMagicLink emails a single-use link logging the user in, as a
+	// passwordless alternative to OAuth. It answers the same whether or not an
+	// account uses the address; rate limited per address
+	RequestMagicLink(context.Context, *RequestMagicLinkRequest) (*RequestMagicLinkResponse, error)
+	// ConsumeMagicLink exchanges the token of a magic link for a session
+	ConsumeMagicLink(context.Context, *ConsumeMagicLinkRequest) (*ConsumeMagicLinkResponse, error)
+	// SendLoginCode sends a 6-digit login code to the account using the email
+	// address, by email or SMS, for users who can't use OAuth. It answers the
+	// same whether or not an account uses the address; rate limited per address
+	SendLoginCode(context.Context, *SendLoginCodeRequest) (*SendLoginCodeResponse, error)
+	// VerifyLoginCode exchanges the login code for a session. Too many wrong
+	// codes void the code (RESOURCE_EXHAUSTED) and a new one must be sent
+	VerifyLoginCode(context.Context, *VerifyLoginCodeRequest) (*VerifyLoginCodeResponse, error)
+	// CreateHandoffToken issues a short-lived, single-use token the web app
+	// passes to a native app (e.g. in a custom-scheme redirect), which redeems it
+	// for a session of its own instead of reading cookies from a WebView
+	CreateHandoffToken(context.Context, *CreateHandoffTokenRequest) (*CreateHandoffTokenResponse, error)
+	// RedeemHandoffToken exchanges a handoff token for a new session of the user,
+	// as long as the web session it was created with is still active
+	RedeemHandoffToken(context.Context, *RedeemHandoffTokenRequest) (*RedeemHandoffTokenResponse, error)
+	// StartDeviceAuthorization begins the login of a device without a browser,
+	// such as a CLI (RFC 8628): the user approves the user code at the
+	// verification URL while the device polls PollDeviceAuthorization
+	StartDeviceAuthorization(context.Context, *StartDeviceAuthorizationRequest) (*StartDeviceAuthorizationResponse, error)
+	// ApproveDeviceAuthorization lets the logged-in user approve or deny a device
+	// login by its user code, from the /activate page
+	ApproveDeviceAuthorization(context.Context, *ApproveDeviceAuthorizationRequest) (*ApproveDeviceAuthorizationResponse, error)
+	// PollDeviceAuthorization returns a session of the approving user once the
+	// device login was approved. Until then it fails with an ErrorInfo reason:
+	// AUTHORIZATION_PENDING, SLOW_DOWN (poll less often), ACCESS_DENIED or
+	// DEVICE_CODE_EXPIRED
+	PollDeviceAuthorization(context.Context, *PollDeviceAuthorizationRequest) (*PollDeviceAuthorizationResponse, error)
+	// GetProviderToken gives other services the provider token of a user's last
+	// OAuth login, refreshed if it expired, to call the provider on their behalf
+	GetProviderToken(context.Context, *GetProviderTokenRequest) (*GetProviderTokenResponse, error)
+	// RevokeProviderIdentity reacts to a provider reporting that a user revoked
+	// the authorization or their account was compromised, e.g. from a webhook
+	RevokeProviderIdentity(context.Context, *RevokeProviderIdentityRequest) (*RevokeProviderIdentityResponse, error)
 	mustEmbedUnimplementedAuthServiceServer()
 }
 
@@ -113,6 +365,48 @@ func (UnimplementedAuthServiceServer) LoginByPassword(context.Context, *LoginByP
 func (UnimplementedAuthServiceServer) GetUserToken(context.Context, *GetUserTokenRequest) (*GetUserTokenResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserToken not implemented")
 }
+func (UnimplementedAuthServiceServer) GetPublicConfig(context.Context, *GetPublicConfigRequest) (*GetPublicConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPublicConfig not implemented")
+}
+func (UnimplementedAuthServiceServer) GetServerInfo(context.Context, *GetServerInfoRequest) (*GetServerInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServerInfo not implemented")
+}
+func (UnimplementedAuthServiceServer) CheckEmailAvailable(context.Context, *CheckEmailAvailableRequest) (*CheckEmailAvailableResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckEmailAvailable not implemented")
+}
+func (UnimplementedAuthServiceServer) RequestMagicLink(context.Context, *RequestMagicLinkRequest) (*RequestMagicLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestMagicLink not implemented")
+}
+func (UnimplementedAuthServiceServer) ConsumeMagicLink(context.Context, *ConsumeMagicLinkRequest) (*ConsumeMagicLinkResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConsumeMagicLink not implemented")
+}
+func (UnimplementedAuthServiceServer) SendLoginCode(context.Context, *SendLoginCodeRequest) (*SendLoginCodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendLoginCode not implemented")
+}
+func (UnimplementedAuthServiceServer) VerifyLoginCode(context.Context, *VerifyLoginCodeRequest) (*VerifyLoginCodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyLoginCode not implemented")
+}
+func (UnimplementedAuthServiceServer) CreateHandoffToken(context.Context, *CreateHandoffTokenRequest) (*CreateHandoffTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateHandoffToken not implemented")
+}
+func (UnimplementedAuthServiceServer) RedeemHandoffToken(context.Context, *RedeemHandoffTokenRequest) (*RedeemHandoffTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RedeemHandoffToken not implemented")
+}
+func (UnimplementedAuthServiceServer) StartDeviceAuthorization(context.Context, *StartDeviceAuthorizationRequest) (*StartDeviceAuthorizationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartDeviceAuthorization not implemented")
+}
+func (UnimplementedAuthServiceServer) ApproveDeviceAuthorization(context.Context, *ApproveDeviceAuthorizationRequest) (*ApproveDeviceAuthorizationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApproveDeviceAuthorization not implemented")
+}
+func (UnimplementedAuthServiceServer) PollDeviceAuthorization(context.Context, *PollDeviceAuthorizationRequest) (*PollDeviceAuthorizationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PollDeviceAuthorization not implemented")
+}
+func (UnimplementedAuthServiceServer) GetProviderToken(context.Context, *GetProviderTokenRequest) (*GetProviderTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProviderToken not implemented")
+}
+func (UnimplementedAuthServiceServer) RevokeProviderIdentity(context.Context, *RevokeProviderIdentityRequest) (*RevokeProviderIdentityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeProviderIdentity not implemented")
+}
 func (UnimplementedAuthServiceServer) mustEmbedUnimplementedAuthServiceServer() {}
 func (UnimplementedAuthServiceServer) testEmbeddedByValue()                     {}
 
@@ -206,6 +500,258 @@ func _AuthService_GetUserToken_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AuthService_GetPublicConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPublicConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).GetPublicConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_GetPublicConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).GetPublicConfig(ctx, req.(*GetPublicConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_GetServerInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServerInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).GetServerInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_GetServerInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).GetServerInfo(ctx, req.(*GetServerInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_CheckEmailAvailable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckEmailAvailableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).CheckEmailAvailable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_CheckEmailAvailable_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).CheckEmailAvailable(ctx, req.(*CheckEmailAvailableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_RequestMagicLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestMagicLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).RequestMagicLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_RequestMagicLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).RequestMagicLink(ctx, req.(*RequestMagicLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_ConsumeMagicLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConsumeMagicLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).ConsumeMagicLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_ConsumeMagicLink_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).ConsumeMagicLink(ctx, req.(*ConsumeMagicLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_SendLoginCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendLoginCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).SendLoginCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_SendLoginCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).SendLoginCode(ctx, req.(*SendLoginCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_VerifyLoginCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyLoginCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).VerifyLoginCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_VerifyLoginCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).VerifyLoginCode(ctx, req.(*VerifyLoginCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_CreateHandoffToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateHandoffTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).CreateHandoffToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_CreateHandoffToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).CreateHandoffToken(ctx, req.(*CreateHandoffTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_RedeemHandoffToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RedeemHandoffTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).RedeemHandoffToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_RedeemHandoffToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).RedeemHandoffToken(ctx, req.(*RedeemHandoffTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_StartDeviceAuthorization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartDeviceAuthorizationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).StartDeviceAuthorization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_StartDeviceAuthorization_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).StartDeviceAuthorization(ctx, req.(*StartDeviceAuthorizationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_ApproveDeviceAuthorization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApproveDeviceAuthorizationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).ApproveDeviceAuthorization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_ApproveDeviceAuthorization_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).ApproveDeviceAuthorization(ctx, req.(*ApproveDeviceAuthorizationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_PollDeviceAuthorization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PollDeviceAuthorizationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).PollDeviceAuthorization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_PollDeviceAuthorization_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).PollDeviceAuthorization(ctx, req.(*PollDeviceAuthorizationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_GetProviderToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProviderTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).GetProviderToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_GetProviderToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).GetProviderToken(ctx, req.(*GetProviderTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_RevokeProviderIdentity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeProviderIdentityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).RevokeProviderIdentity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_RevokeProviderIdentity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).RevokeProviderIdentity(ctx, req.(*RevokeProviderIdentityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AuthService_ServiceDesc is the grpc.ServiceDesc for AuthService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -229,6 +775,62 @@ var AuthService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUserToken",
 			Handler:    _AuthService_GetUserToken_Handler,
 		},
+		{
+			MethodName: "GetPublicConfig",
+			Handler:    _AuthService_GetPublicConfig_Handler,
+		},
+		{
+			MethodName: "GetServerInfo",
+			Handler:    _AuthService_GetServerInfo_Handler,
+		},
+		{
+			MethodName: "CheckEmailAvailable",
+			Handler:    _AuthService_CheckEmailAvailable_Handler,
+		},
+		{
+			MethodName: "RequestMagicLink",
+			Handler:    _AuthService_RequestMagicLink_Handler,
+		},
+		{
+			MethodName: "ConsumeMagicLink",
+			Handler:    _AuthService_ConsumeMagicLink_Handler,
+		},
+		{
+			MethodName: "SendLoginCode",
+			Handler:    _AuthService_SendLoginCode_Handler,
+		},
+		{
+			MethodName: "VerifyLoginCode",
+			Handler:    _AuthService_VerifyLoginCode_Handler,
+		},
+		{
+			MethodName: "CreateHandoffToken",
+			Handler:    _AuthService_CreateHandoffToken_Handler,
+		},
+		{
+			MethodName: "RedeemHandoffToken",
+			Handler:    _AuthService_RedeemHandoffToken_Handler,
+		},
+		{
+			MethodName: "StartDeviceAuthorization",
+			Handler:    _AuthService_StartDeviceAuthorization_Handler,
+		},
+		{
+			MethodName: "ApproveDeviceAuthorization",
+			Handler:    _AuthService_ApproveDeviceAuthorization_Handler,
+		},
+		{
+			MethodName: "PollDeviceAuthorization",
+			Handler:    _AuthService_PollDeviceAuthorization_Handler,
+		},
+		{
+			MethodName: "GetProviderToken",
+			Handler:    _AuthService_GetProviderToken_Handler,
+		},
+		{
+			MethodName: "RevokeProviderIdentity",
+			Handler:    _AuthService_RevokeProviderIdentity_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "auth/v1/auth.proto",
@@ -0,0 +1,227 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: authz/v1/options.proto
+
+package authz_v1_pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// AuthLevel is who may call an RPC.
+type AuthLevel int32
+
+const (
+	// Unannotated RPCs are treated like AUTH_LEVEL_USER
+	AuthLevel_AUTH_LEVEL_UNSPECIFIED AuthLevel = 0
+	// Callable without a token
+	AuthLevel_AUTH_LEVEL_PUBLIC AuthLevel = 1
+	// Requires a user token whose role is granted the permission by the RBAC policy
+	AuthLevel_AUTH_LEVEL_USER AuthLevel = 2
+	// Like AUTH_LEVEL_USER, and the token's role must be admin regardless of the policy
+	AuthLevel_AUTH_LEVEL_ADMIN AuthLevel = 3
+	// Requires the internal token of other services; user tokens are rejected
+	AuthLevel_AUTH_LEVEL_INTERNAL AuthLevel = 4
+)
+
+// Enum value maps for AuthLevel.
+var (
+	AuthLevel_name = map[int32]string{
+		0: "AUTH_LEVEL_UNSPECIFIED",
+		1: "AUTH_LEVEL_PUBLIC",
+		2: "AUTH_LEVEL_USER",
+		3: "AUTH_LEVEL_ADMIN",
+		4: "AUTH_LEVEL_INTERNAL",
+	}
+	AuthLevel_value = map[string]int32{
+		"AUTH_LEVEL_UNSPECIFIED": 0,
+		"AUTH_LEVEL_PUBLIC":      1,
+		"AUTH_LEVEL_USER":        2,
+		"AUTH_LEVEL_ADMIN":       3,
+		"AUTH_LEVEL_INTERNAL":    4,
+	}
+)
+
+func (x AuthLevel) Enum() *AuthLevel {
+	p := new(AuthLevel)
+	*p = x
+	return p
+}
+
+func (x AuthLevel) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AuthLevel) Descriptor() protoreflect.EnumDescriptor {
+	return file_authz_v1_options_proto_enumTypes[0].Descriptor()
+}
+
+func (AuthLevel) Type() protoreflect.EnumType {
+	return &file_authz_v1_options_proto_enumTypes[0]
+}
+
+func (x AuthLevel) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AuthLevel.Descriptor instead.
+func (AuthLevel) EnumDescriptor() ([]byte, []int) {
+	return file_authz_v1_options_proto_rawDescGZIP(), []int{0}
+}
+
+type MethodAuthz struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	AuthLevel AuthLevel              `protobuf:"varint,1,opt,name=auth_level,json=authLevel,proto3,enum=authz.v1.AuthLevel" json:"auth_level,omitempty"`
+	// Policy object checked against the RBAC policy; defaults to "/<Service>/<Method>"
+	RequiredPermission string `protobuf:"bytes,2,opt,name=required_permission,json=requiredPermission,proto3" json:"required_permission,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *MethodAuthz) Reset() {
+	*x = MethodAuthz{}
+	mi := &file_authz_v1_options_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MethodAuthz) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MethodAuthz) ProtoMessage() {}
+
+func (x *MethodAuthz) ProtoReflect() protoreflect.Message {
+	mi := &file_authz_v1_options_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MethodAuthz.ProtoReflect.Descriptor instead.
+func (*MethodAuthz) Descriptor() ([]byte, []int) {
+	return file_authz_v1_options_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MethodAuthz) GetAuthLevel() AuthLevel {
+	if x != nil {
+		return x.AuthLevel
+	}
+	return AuthLevel_AUTH_LEVEL_UNSPECIFIED
+}
+
+func (x *MethodAuthz) GetRequiredPermission() string {
+	if x != nil {
+		return x.RequiredPermission
+	}
+	return ""
+}
+
+var file_authz_v1_options_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*MethodAuthz)(nil),
+		Field:         51000,
+		Name:          "authz.v1.authz",
+		Tag:           "bytes,51000,opt,name=authz",
+		Filename:      "authz/v1/options.proto",
+	},
+}
+
+// Extension fields to descriptorpb.MethodOptions.
+var (
+	// optional authz.v1.MethodAuthz authz = 51000;
+	E_Authz = &file_authz_v1_options_proto_extTypes[0]
+)
+
+var File_authz_v1_options_proto protoreflect.FileDescriptor
+
+const file_authz_v1_options_proto_rawDesc = "" +
+	"\n" +
+	"\x16authz/v1/options.proto\x12\bauthz.v1\x1a google/protobuf/descriptor.proto\"r\n" +
+	"\vMethodAuthz\x122\n" +
+	"\n" +
+	"auth_level\x18\x01 \x01(\x0e2\x13.authz.v1.AuthLevelR\tauthLevel\x12/\n" +
+	"\x13required_permission\x18\x02 \x01(\tR\x12requiredPermission*\x82\x01\n" +
+	"\tAuthLevel\x12\x1a\n" +
+	"\x16AUTH_LEVEL_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11AUTH_LEVEL_PUBLIC\x10\x01\x12\x13\n" +
+	"\x0fAUTH_LEVEL_USER\x10\x02\x12\x14\n" +
+	"\x10AUTH_LEVEL_ADMIN\x10\x03\x12\x17\n" +
+	"\x13AUTH_LEVEL_INTERNAL\x10\x04:M\n" +
+	"\x05authz\x12\x1e.google.protobuf.MethodOptions\x18\xb8\x8e\x03 \x01(\v2\x15.authz.v1.MethodAuthzR\x05authzB?Z=github.com/poly-workshop/auth-portal/gen/authz/v1;authz_v1_pbb\x06proto3"
+
+var (
+	file_authz_v1_options_proto_rawDescOnce sync.Once
+	file_authz_v1_options_proto_rawDescData []byte
+)
+
+func file_authz_v1_options_proto_rawDescGZIP() []byte {
+	file_authz_v1_options_proto_rawDescOnce.Do(func() {
+		file_authz_v1_options_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_authz_v1_options_proto_rawDesc), len(file_authz_v1_options_proto_rawDesc)))
+	})
+	return file_authz_v1_options_proto_rawDescData
+}
+
+var file_authz_v1_options_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_authz_v1_options_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_authz_v1_options_proto_goTypes = []any{
+	(AuthLevel)(0),                     // 0: authz.v1.AuthLevel
+	(*MethodAuthz)(nil),                // 1: authz.v1.MethodAuthz
+	(*descriptorpb.MethodOptions)(nil), // 2: google.protobuf.MethodOptions
+}
+var file_authz_v1_options_proto_depIdxs = []int32{
+	0, // 0: authz.v1.MethodAuthz.auth_level:type_name -> authz.v1.AuthLevel
+	2, // 1: authz.v1.authz:extendee -> google.protobuf.MethodOptions
+	1, // 2: authz.v1.authz:type_name -> authz.v1.MethodAuthz
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	2, // [2:3] is the sub-list for extension type_name
+	1, // [1:2] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_authz_v1_options_proto_init() }
+func file_authz_v1_options_proto_init() {
+	if File_authz_v1_options_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_authz_v1_options_proto_rawDesc), len(file_authz_v1_options_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 1,
+			NumServices:   0,
+		},
+		GoTypes:           file_authz_v1_options_proto_goTypes,
+		DependencyIndexes: file_authz_v1_options_proto_depIdxs,
+		EnumInfos:         file_authz_v1_options_proto_enumTypes,
+		MessageInfos:      file_authz_v1_options_proto_msgTypes,
+		ExtensionInfos:    file_authz_v1_options_proto_extTypes,
+	}.Build()
+	File_authz_v1_options_proto = out.File
+	file_authz_v1_options_proto_goTypes = nil
+	file_authz_v1_options_proto_depIdxs = nil
+}
@@ -7,9 +7,14 @@
 package user_v1_pb
 
 import (
+	_ "github.com/poly-workshop/auth-portal/gen/audit/v1"
+	_ "github.com/poly-workshop/auth-portal/gen/authz/v1"
 	_ "google.golang.org/genproto/googleapis/api/annotations"
+	httpbody "google.golang.org/genproto/googleapis/api/httpbody"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -72,15 +77,246 @@ func (UserRole) EnumDescriptor() ([]byte, []int) {
 	return file_user_v1_user_proto_rawDescGZIP(), []int{0}
 }
 
+// Fields of the users a list returns
+type UserView int32
+
+const (
+	// Same as USER_VIEW_FULL
+	UserView_USER_VIEW_UNSPECIFIED UserView = 0
+	// Every field
+	UserView_USER_VIEW_FULL UserView = 1
+	// Only id, name and email, e.g. for autocomplete
+	UserView_USER_VIEW_LITE UserView = 2
+)
+
+// Enum value maps for UserView.
+var (
+	UserView_name = map[int32]string{
+		0: "USER_VIEW_UNSPECIFIED",
+		1: "USER_VIEW_FULL",
+		2: "USER_VIEW_LITE",
+	}
+	UserView_value = map[string]int32{
+		"USER_VIEW_UNSPECIFIED": 0,
+		"USER_VIEW_FULL":        1,
+		"USER_VIEW_LITE":        2,
+	}
+)
+
+func (x UserView) Enum() *UserView {
+	p := new(UserView)
+	*p = x
+	return p
+}
+
+func (x UserView) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UserView) Descriptor() protoreflect.EnumDescriptor {
+	return file_user_v1_user_proto_enumTypes[1].Descriptor()
+}
+
+func (UserView) Type() protoreflect.EnumType {
+	return &file_user_v1_user_proto_enumTypes[1]
+}
+
+func (x UserView) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UserView.Descriptor instead.
+func (UserView) EnumDescriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{1}
+}
+
+type ReportKind int32
+
+const (
+	ReportKind_REPORT_KIND_UNSPECIFIED ReportKind = 0
+	// All users with their role and activity
+	ReportKind_REPORT_KIND_USER_ROSTER ReportKind = 1
+	// Successful and failed logins of the period
+	ReportKind_REPORT_KIND_LOGIN_ACTIVITY ReportKind = 2
+	// Audit events of the period counted by type
+	ReportKind_REPORT_KIND_AUDIT_SUMMARY ReportKind = 3
+)
+
+// Enum value maps for ReportKind.
+var (
+	ReportKind_name = map[int32]string{
+		0: "REPORT_KIND_UNSPECIFIED",
+		1: "REPORT_KIND_USER_ROSTER",
+		2: "REPORT_KIND_LOGIN_ACTIVITY",
+		3: "REPORT_KIND_AUDIT_SUMMARY",
+	}
+	ReportKind_value = map[string]int32{
+		"REPORT_KIND_UNSPECIFIED":    0,
+		"REPORT_KIND_USER_ROSTER":    1,
+		"REPORT_KIND_LOGIN_ACTIVITY": 2,
+		"REPORT_KIND_AUDIT_SUMMARY":  3,
+	}
+)
+
+func (x ReportKind) Enum() *ReportKind {
+	p := new(ReportKind)
+	*p = x
+	return p
+}
+
+func (x ReportKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReportKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_user_v1_user_proto_enumTypes[2].Descriptor()
+}
+
+func (ReportKind) Type() protoreflect.EnumType {
+	return &file_user_v1_user_proto_enumTypes[2]
+}
+
+func (x ReportKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReportKind.Descriptor instead.
+func (ReportKind) EnumDescriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{2}
+}
+
+type ReportFormat int32
+
+const (
+	ReportFormat_REPORT_FORMAT_UNSPECIFIED ReportFormat = 0
+	ReportFormat_REPORT_FORMAT_CSV         ReportFormat = 1
+	ReportFormat_REPORT_FORMAT_PDF         ReportFormat = 2
+)
+
+// Enum value maps for ReportFormat.
+var (
+	ReportFormat_name = map[int32]string{
+		0: "REPORT_FORMAT_UNSPECIFIED",
+		1: "REPORT_FORMAT_CSV",
+		2: "REPORT_FORMAT_PDF",
+	}
+	ReportFormat_value = map[string]int32{
+		"REPORT_FORMAT_UNSPECIFIED": 0,
+		"REPORT_FORMAT_CSV":         1,
+		"REPORT_FORMAT_PDF":         2,
+	}
+)
+
+func (x ReportFormat) Enum() *ReportFormat {
+	p := new(ReportFormat)
+	*p = x
+	return p
+}
+
+func (x ReportFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReportFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_user_v1_user_proto_enumTypes[3].Descriptor()
+}
+
+func (ReportFormat) Type() protoreflect.EnumType {
+	return &file_user_v1_user_proto_enumTypes[3]
+}
+
+func (x ReportFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReportFormat.Descriptor instead.
+func (ReportFormat) EnumDescriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{3}
+}
+
+type ReportStatus int32
+
+const (
+	ReportStatus_REPORT_STATUS_UNSPECIFIED ReportStatus = 0
+	ReportStatus_REPORT_STATUS_PENDING     ReportStatus = 1
+	ReportStatus_REPORT_STATUS_RUNNING     ReportStatus = 2
+	ReportStatus_REPORT_STATUS_READY       ReportStatus = 3
+	ReportStatus_REPORT_STATUS_FAILED      ReportStatus = 4
+)
+
+// Enum value maps for ReportStatus.
+var (
+	ReportStatus_name = map[int32]string{
+		0: "REPORT_STATUS_UNSPECIFIED",
+		1: "REPORT_STATUS_PENDING",
+		2: "REPORT_STATUS_RUNNING",
+		3: "REPORT_STATUS_READY",
+		4: "REPORT_STATUS_FAILED",
+	}
+	ReportStatus_value = map[string]int32{
+		"REPORT_STATUS_UNSPECIFIED": 0,
+		"REPORT_STATUS_PENDING":     1,
+		"REPORT_STATUS_RUNNING":     2,
+		"REPORT_STATUS_READY":       3,
+		"REPORT_STATUS_FAILED":      4,
+	}
+)
+
+func (x ReportStatus) Enum() *ReportStatus {
+	p := new(ReportStatus)
+	*p = x
+	return p
+}
+
+func (x ReportStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReportStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_user_v1_user_proto_enumTypes[4].Descriptor()
+}
+
+func (ReportStatus) Type() protoreflect.EnumType {
+	return &file_user_v1_user_proto_enumTypes[4]
+}
+
+func (x ReportStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReportStatus.Descriptor instead.
+func (ReportStatus) EnumDescriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{4}
+}
+
 type User struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	Name          string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
-	Email         string                 `protobuf:"bytes,5,opt,name=email,proto3" json:"email,omitempty"`
-	Role          UserRole               `protobuf:"varint,6,opt,name=role,proto3,enum=user.v1.UserRole" json:"role,omitempty"`
-	GithubId      *string                `protobuf:"bytes,7,opt,name=github_id,json=githubId,proto3,oneof" json:"github_id,omitempty"`
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Id                  string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt           *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt           *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Name                string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Email               string                 `protobuf:"bytes,5,opt,name=email,proto3" json:"email,omitempty"`
+	Role                UserRole               `protobuf:"varint,6,opt,name=role,proto3,enum=user.v1.UserRole" json:"role,omitempty"`
+	GithubId            *string                `protobuf:"bytes,7,opt,name=github_id,json=githubId,proto3,oneof" json:"github_id,omitempty"`
+	DeletionScheduledAt *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=deletion_scheduled_at,json=deletionScheduledAt,proto3,oneof" json:"deletion_scheduled_at,omitempty"`
+	MustChangePassword  bool                   `protobuf:"varint,9,opt,name=must_change_password,json=mustChangePassword,proto3" json:"must_change_password,omitempty"`
+	// Set for signups outside the allowed email domains until an admin approves them
+	PendingApproval bool `protobuf:"varint,10,opt,name=pending_approval,json=pendingApproval,proto3" json:"pending_approval,omitempty"`
+	// Organization the user belongs to, administered by its org admins; the
+	// settings of its tenant apply to the user
+	Org string `protobuf:"bytes,11,opt,name=org,proto3" json:"org,omitempty"`
+	// Incremented by every update; served as the ETag of GET /v1/users/{id}
+	Version int64 `protobuf:"varint,12,opt,name=version,proto3" json:"version,omitempty"`
+	// Last time the user fetched a token or made a call, at hourly precision
+	LastSeenAt *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=last_seen_at,json=lastSeenAt,proto3,oneof" json:"last_seen_at,omitempty"`
+	// Set while the account is deactivated for inactivity; logging in reactivates it
+	DeactivatedAt *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=deactivated_at,json=deactivatedAt,proto3,oneof" json:"deactivated_at,omitempty"`
+	// E.164 number login codes can be sent to by SMS, e.g. "+15551234567"
+	PhoneNumber *string `protobuf:"bytes,15,opt,name=phone_number,json=phoneNumber,proto3,oneof" json:"phone_number,omitempty"`
+	// Set by the provisioning hooks of the deployment, e.g. the organization
+	Metadata map[string]string `protobuf:"bytes,16,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Features of the user's plan, e.g. "plan:pro"; carried in the user's tokens
+	Entitlements  []string `protobuf:"bytes,17,rep,name=entitlements,proto3" json:"entitlements,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -164,6 +400,76 @@ func (x *User) GetGithubId() string {
 	return ""
 }
 
+func (x *User) GetDeletionScheduledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletionScheduledAt
+	}
+	return nil
+}
+
+func (x *User) GetMustChangePassword() bool {
+	if x != nil {
+		return x.MustChangePassword
+	}
+	return false
+}
+
+func (x *User) GetPendingApproval() bool {
+	if x != nil {
+		return x.PendingApproval
+	}
+	return false
+}
+
+func (x *User) GetOrg() string {
+	if x != nil {
+		return x.Org
+	}
+	return ""
+}
+
+func (x *User) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *User) GetLastSeenAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeenAt
+	}
+	return nil
+}
+
+func (x *User) GetDeactivatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeactivatedAt
+	}
+	return nil
+}
+
+func (x *User) GetPhoneNumber() string {
+	if x != nil && x.PhoneNumber != nil {
+		return *x.PhoneNumber
+	}
+	return ""
+}
+
+func (x *User) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *User) GetEntitlements() []string {
+	if x != nil {
+		return x.Entitlements
+	}
+	return nil
+}
+
 type CreateUserRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -444,28 +750,30 @@ func (x *GetUserResponse) GetUser() *User {
 	return nil
 }
 
-type ListUsersRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Page          uint64                 `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
-	PageSize      uint64                 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+type ListInactiveUsersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Accounts without activity (or, never seen, created) in this many days; at least 1
+	InactiveDays  uint32 `protobuf:"varint,1,opt,name=inactive_days,json=inactiveDays,proto3" json:"inactive_days,omitempty"`
+	Page          uint64 `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      uint64 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListUsersRequest) Reset() {
-	*x = ListUsersRequest{}
+func (x *ListInactiveUsersRequest) Reset() {
+	*x = ListInactiveUsersRequest{}
 	mi := &file_user_v1_user_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListUsersRequest) String() string {
+func (x *ListInactiveUsersRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListUsersRequest) ProtoMessage() {}
+func (*ListInactiveUsersRequest) ProtoMessage() {}
 
-func (x *ListUsersRequest) ProtoReflect() protoreflect.Message {
+func (x *ListInactiveUsersRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_user_v1_user_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -477,26 +785,33 @@ func (x *ListUsersRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListUsersRequest.ProtoReflect.Descriptor instead.
-func (*ListUsersRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ListInactiveUsersRequest.ProtoReflect.Descriptor instead.
+func (*ListInactiveUsersRequest) Descriptor() ([]byte, []int) {
 	return file_user_v1_user_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *ListUsersRequest) GetPage() uint64 {
+func (x *ListInactiveUsersRequest) GetInactiveDays() uint32 {
+	if x != nil {
+		return x.InactiveDays
+	}
+	return 0
+}
+
+func (x *ListInactiveUsersRequest) GetPage() uint64 {
 	if x != nil {
 		return x.Page
 	}
 	return 0
 }
 
-func (x *ListUsersRequest) GetPageSize() uint64 {
+func (x *ListInactiveUsersRequest) GetPageSize() uint64 {
 	if x != nil {
 		return x.PageSize
 	}
 	return 0
 }
 
-type ListUsersResponse struct {
+type ListInactiveUsersResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Users         []*User                `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
 	Total         uint64                 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
@@ -504,20 +819,20 @@ type ListUsersResponse struct {
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListUsersResponse) Reset() {
-	*x = ListUsersResponse{}
+func (x *ListInactiveUsersResponse) Reset() {
+	*x = ListInactiveUsersResponse{}
 	mi := &file_user_v1_user_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListUsersResponse) String() string {
+func (x *ListInactiveUsersResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListUsersResponse) ProtoMessage() {}
+func (*ListInactiveUsersResponse) ProtoMessage() {}
 
-func (x *ListUsersResponse) ProtoReflect() protoreflect.Message {
+func (x *ListInactiveUsersResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_user_v1_user_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -529,51 +844,46 @@ func (x *ListUsersResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListUsersResponse.ProtoReflect.Descriptor instead.
-func (*ListUsersResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ListInactiveUsersResponse.ProtoReflect.Descriptor instead.
+func (*ListInactiveUsersResponse) Descriptor() ([]byte, []int) {
 	return file_user_v1_user_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *ListUsersResponse) GetUsers() []*User {
+func (x *ListInactiveUsersResponse) GetUsers() []*User {
 	if x != nil {
 		return x.Users
 	}
 	return nil
 }
 
-func (x *ListUsersResponse) GetTotal() uint64 {
+func (x *ListInactiveUsersResponse) GetTotal() uint64 {
 	if x != nil {
 		return x.Total
 	}
 	return 0
 }
 
-type UpdateUserRequest struct {
+type GetUserByEmailRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
-	Email         *string                `protobuf:"bytes,3,opt,name=email,proto3,oneof" json:"email,omitempty"`
-	Role          *UserRole              `protobuf:"varint,4,opt,name=role,proto3,enum=user.v1.UserRole,oneof" json:"role,omitempty"`
-	Password      *string                `protobuf:"bytes,5,opt,name=password,proto3,oneof" json:"password,omitempty"`
-	GithubId      *string                `protobuf:"bytes,6,opt,name=github_id,json=githubId,proto3,oneof" json:"github_id,omitempty"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateUserRequest) Reset() {
-	*x = UpdateUserRequest{}
+func (x *GetUserByEmailRequest) Reset() {
+	*x = GetUserByEmailRequest{}
 	mi := &file_user_v1_user_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateUserRequest) String() string {
+func (x *GetUserByEmailRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateUserRequest) ProtoMessage() {}
+func (*GetUserByEmailRequest) ProtoMessage() {}
 
-func (x *UpdateUserRequest) ProtoReflect() protoreflect.Message {
+func (x *GetUserByEmailRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_user_v1_user_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -585,73 +895,39 @@ func (x *UpdateUserRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateUserRequest.ProtoReflect.Descriptor instead.
-func (*UpdateUserRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetUserByEmailRequest.ProtoReflect.Descriptor instead.
+func (*GetUserByEmailRequest) Descriptor() ([]byte, []int) {
 	return file_user_v1_user_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *UpdateUserRequest) GetId() string {
+func (x *GetUserByEmailRequest) GetEmail() string {
 	if x != nil {
-		return x.Id
-	}
-	return ""
-}
-
-func (x *UpdateUserRequest) GetName() string {
-	if x != nil && x.Name != nil {
-		return *x.Name
-	}
-	return ""
-}
-
-func (x *UpdateUserRequest) GetEmail() string {
-	if x != nil && x.Email != nil {
-		return *x.Email
-	}
-	return ""
-}
-
-func (x *UpdateUserRequest) GetRole() UserRole {
-	if x != nil && x.Role != nil {
-		return *x.Role
-	}
-	return UserRole_USER_ROLE_UNSPECIFIED
-}
-
-func (x *UpdateUserRequest) GetPassword() string {
-	if x != nil && x.Password != nil {
-		return *x.Password
-	}
-	return ""
-}
-
-func (x *UpdateUserRequest) GetGithubId() string {
-	if x != nil && x.GithubId != nil {
-		return *x.GithubId
+		return x.Email
 	}
 	return ""
 }
 
-type UpdateUserResponse struct {
+type GetUserByEmailResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateUserResponse) Reset() {
-	*x = UpdateUserResponse{}
+func (x *GetUserByEmailResponse) Reset() {
+	*x = GetUserByEmailResponse{}
 	mi := &file_user_v1_user_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateUserResponse) String() string {
+func (x *GetUserByEmailResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateUserResponse) ProtoMessage() {}
+func (*GetUserByEmailResponse) ProtoMessage() {}
 
-func (x *UpdateUserResponse) ProtoReflect() protoreflect.Message {
+func (x *GetUserByEmailResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_user_v1_user_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -663,32 +939,39 @@ func (x *UpdateUserResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateUserResponse.ProtoReflect.Descriptor instead.
-func (*UpdateUserResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetUserByEmailResponse.ProtoReflect.Descriptor instead.
+func (*GetUserByEmailResponse) Descriptor() ([]byte, []int) {
 	return file_user_v1_user_proto_rawDescGZIP(), []int{10}
 }
 
-type DeleteUserRequest struct {
+func (x *GetUserByEmailResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+type BatchGetUsersRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteUserRequest) Reset() {
-	*x = DeleteUserRequest{}
+func (x *BatchGetUsersRequest) Reset() {
+	*x = BatchGetUsersRequest{}
 	mi := &file_user_v1_user_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteUserRequest) String() string {
+func (x *BatchGetUsersRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteUserRequest) ProtoMessage() {}
+func (*BatchGetUsersRequest) ProtoMessage() {}
 
-func (x *DeleteUserRequest) ProtoReflect() protoreflect.Message {
+func (x *BatchGetUsersRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_user_v1_user_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -700,39 +983,4065 @@ func (x *DeleteUserRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteUserRequest.ProtoReflect.Descriptor instead.
-func (*DeleteUserRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use BatchGetUsersRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetUsersRequest) Descriptor() ([]byte, []int) {
 	return file_user_v1_user_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *DeleteUserRequest) GetId() string {
+func (x *BatchGetUsersRequest) GetIds() []string {
 	if x != nil {
-		return x.Id
+		return x.Ids
 	}
-	return ""
+	return nil
 }
 
-type DeleteUserResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+type BatchGetUsersResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// In the order of the requested IDs; unknown and deleted users are left out
+	Users         []*User `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteUserResponse) Reset() {
-	*x = DeleteUserResponse{}
+func (x *BatchGetUsersResponse) Reset() {
+	*x = BatchGetUsersResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetUsersResponse) ProtoMessage() {}
+
+func (x *BatchGetUsersResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_user_v1_user_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetUsersResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetUsersResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *BatchGetUsersResponse) GetUsers() []*User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+type ListUsersRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Page     uint64                 `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize uint64                 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Only list users awaiting (true) or not awaiting (false) approval
+	PendingApproval *bool    `protobuf:"varint,3,opt,name=pending_approval,json=pendingApproval,proto3,oneof" json:"pending_approval,omitempty"`
+	View            UserView `protobuf:"varint,4,opt,name=view,proto3,enum=user.v1.UserView" json:"view,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ListUsersRequest) Reset() {
+	*x = ListUsersRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsersRequest) ProtoMessage() {}
+
+func (x *ListUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUsersRequest.ProtoReflect.Descriptor instead.
+func (*ListUsersRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListUsersRequest) GetPage() uint64 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListUsersRequest) GetPageSize() uint64 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListUsersRequest) GetPendingApproval() bool {
+	if x != nil && x.PendingApproval != nil {
+		return *x.PendingApproval
+	}
+	return false
+}
+
+func (x *ListUsersRequest) GetView() UserView {
+	if x != nil {
+		return x.View
+	}
+	return UserView_USER_VIEW_UNSPECIFIED
+}
+
+type ListUsersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         []*User                `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	Total         uint64                 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUsersResponse) Reset() {
+	*x = ListUsersResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsersResponse) ProtoMessage() {}
+
+func (x *ListUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUsersResponse.ProtoReflect.Descriptor instead.
+func (*ListUsersResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListUsersResponse) GetUsers() []*User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *ListUsersResponse) GetTotal() uint64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type ExportUsersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only export users awaiting (true) or not awaiting (false) approval
+	PendingApproval *bool `protobuf:"varint,1,opt,name=pending_approval,json=pendingApproval,proto3,oneof" json:"pending_approval,omitempty"`
+	// Users per response message; defaults to 500, at most 1000
+	ChunkSize     uint32   `protobuf:"varint,2,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
+	View          UserView `protobuf:"varint,3,opt,name=view,proto3,enum=user.v1.UserView" json:"view,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportUsersRequest) Reset() {
+	*x = ExportUsersRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportUsersRequest) ProtoMessage() {}
+
+func (x *ExportUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportUsersRequest.ProtoReflect.Descriptor instead.
+func (*ExportUsersRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ExportUsersRequest) GetPendingApproval() bool {
+	if x != nil && x.PendingApproval != nil {
+		return *x.PendingApproval
+	}
+	return false
+}
+
+func (x *ExportUsersRequest) GetChunkSize() uint32 {
+	if x != nil {
+		return x.ChunkSize
+	}
+	return 0
+}
+
+func (x *ExportUsersRequest) GetView() UserView {
+	if x != nil {
+		return x.View
+	}
+	return UserView_USER_VIEW_UNSPECIFIED
+}
+
+type ExportUsersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         []*User                `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportUsersResponse) Reset() {
+	*x = ExportUsersResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportUsersResponse) ProtoMessage() {}
+
+func (x *ExportUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportUsersResponse.ProtoReflect.Descriptor instead.
+func (*ExportUsersResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ExportUsersResponse) GetUsers() []*User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+type SearchUsersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Case-insensitive prefix of the name or email, at least 2 characters
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Defaults to and is at most 10
+	Limit         uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchUsersRequest) Reset() {
+	*x = SearchUsersRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchUsersRequest) ProtoMessage() {}
+
+func (x *SearchUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchUsersRequest.ProtoReflect.Descriptor instead.
+func (*SearchUsersRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SearchUsersRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchUsersRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SearchUsersResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Matching users ordered by name, in the lite view
+	Users         []*User `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchUsersResponse) Reset() {
+	*x = SearchUsersResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchUsersResponse) ProtoMessage() {}
+
+func (x *SearchUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchUsersResponse.ProtoReflect.Descriptor instead.
+func (*SearchUsersResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SearchUsersResponse) GetUsers() []*User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+type UpdateUserRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name               *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Email              *string                `protobuf:"bytes,3,opt,name=email,proto3,oneof" json:"email,omitempty"`
+	Role               *UserRole              `protobuf:"varint,4,opt,name=role,proto3,enum=user.v1.UserRole,oneof" json:"role,omitempty"`
+	Password           *string                `protobuf:"bytes,5,opt,name=password,proto3,oneof" json:"password,omitempty"`
+	GithubId           *string                `protobuf:"bytes,6,opt,name=github_id,json=githubId,proto3,oneof" json:"github_id,omitempty"`
+	MustChangePassword *bool                  `protobuf:"varint,7,opt,name=must_change_password,json=mustChangePassword,proto3,oneof" json:"must_change_password,omitempty"`
+	// Set to false to approve a pending signup
+	PendingApproval *bool `protobuf:"varint,8,opt,name=pending_approval,json=pendingApproval,proto3,oneof" json:"pending_approval,omitempty"`
+	// Organization of the user; empty removes it. Org admins can't change it.
+	Org *string `protobuf:"bytes,9,opt,name=org,proto3,oneof" json:"org,omitempty"`
+	// Only update if the user's version still matches (from the If-Match header
+	// through the gateway); fails with FAILED_PRECONDITION otherwise
+	Version *int64 `protobuf:"varint,10,opt,name=version,proto3,oneof" json:"version,omitempty"`
+	// Fields to update, e.g. "name,github_id" in JSON. Named fields that are
+	// unset in the request are cleared; without a mask only set fields change.
+	UpdateMask *fieldmaskpb.FieldMask `protobuf:"bytes,11,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	// E.164 phone number, e.g. "+15551234567"; empty removes it
+	PhoneNumber   *string `protobuf:"bytes,12,opt,name=phone_number,json=phoneNumber,proto3,oneof" json:"phone_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateUserRequest) Reset() {
+	*x = UpdateUserRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateUserRequest) ProtoMessage() {}
+
+func (x *UpdateUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateUserRequest.ProtoReflect.Descriptor instead.
+func (*UpdateUserRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *UpdateUserRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateUserRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *UpdateUserRequest) GetEmail() string {
+	if x != nil && x.Email != nil {
+		return *x.Email
+	}
+	return ""
+}
+
+func (x *UpdateUserRequest) GetRole() UserRole {
+	if x != nil && x.Role != nil {
+		return *x.Role
+	}
+	return UserRole_USER_ROLE_UNSPECIFIED
+}
+
+func (x *UpdateUserRequest) GetPassword() string {
+	if x != nil && x.Password != nil {
+		return *x.Password
+	}
+	return ""
+}
+
+func (x *UpdateUserRequest) GetGithubId() string {
+	if x != nil && x.GithubId != nil {
+		return *x.GithubId
+	}
+	return ""
+}
+
+func (x *UpdateUserRequest) GetMustChangePassword() bool {
+	if x != nil && x.MustChangePassword != nil {
+		return *x.MustChangePassword
+	}
+	return false
+}
+
+func (x *UpdateUserRequest) GetPendingApproval() bool {
+	if x != nil && x.PendingApproval != nil {
+		return *x.PendingApproval
+	}
+	return false
+}
+
+func (x *UpdateUserRequest) GetOrg() string {
+	if x != nil && x.Org != nil {
+		return *x.Org
+	}
+	return ""
+}
+
+func (x *UpdateUserRequest) GetVersion() int64 {
+	if x != nil && x.Version != nil {
+		return *x.Version
+	}
+	return 0
+}
+
+func (x *UpdateUserRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+func (x *UpdateUserRequest) GetPhoneNumber() string {
+	if x != nil && x.PhoneNumber != nil {
+		return *x.PhoneNumber
+	}
+	return ""
+}
+
+type UpdateUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateUserResponse) Reset() {
+	*x = UpdateUserResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateUserResponse) ProtoMessage() {}
+
+func (x *UpdateUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateUserResponse.ProtoReflect.Descriptor instead.
+func (*UpdateUserResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{20}
+}
+
+type DeleteUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteUserRequest) Reset() {
+	*x = DeleteUserRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUserRequest) ProtoMessage() {}
+
+func (x *DeleteUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteUserRequest.ProtoReflect.Descriptor instead.
+func (*DeleteUserRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DeleteUserRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteUserResponse) Reset() {
+	*x = DeleteUserResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUserResponse) ProtoMessage() {}
+
+func (x *DeleteUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteUserResponse.ProtoReflect.Descriptor instead.
+func (*DeleteUserResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{22}
+}
+
+type ListMyIdentitiesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMyIdentitiesRequest) Reset() {
+	*x = ListMyIdentitiesRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMyIdentitiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMyIdentitiesRequest) ProtoMessage() {}
+
+func (x *ListMyIdentitiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMyIdentitiesRequest.ProtoReflect.Descriptor instead.
+func (*ListMyIdentitiesRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{23}
+}
+
+type ListMyIdentitiesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Identities    []*Identity            `protobuf:"bytes,1,rep,name=identities,proto3" json:"identities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMyIdentitiesResponse) Reset() {
+	*x = ListMyIdentitiesResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMyIdentitiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMyIdentitiesResponse) ProtoMessage() {}
+
+func (x *ListMyIdentitiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMyIdentitiesResponse.ProtoReflect.Descriptor instead.
+func (*ListMyIdentitiesResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ListMyIdentitiesResponse) GetIdentities() []*Identity {
+	if x != nil {
+		return x.Identities
+	}
+	return nil
+}
+
+type Identity struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the login provider, e.g. "github"
+	Provider string `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	// Account ID at the provider with all but the last characters masked
+	MaskedProviderId string `protobuf:"bytes,2,opt,name=masked_provider_id,json=maskedProviderId,proto3" json:"masked_provider_id,omitempty"`
+	// Unset if the account was linked before link dates were recorded
+	LinkedAt      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=linked_at,json=linkedAt,proto3" json:"linked_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Identity) Reset() {
+	*x = Identity{}
+	mi := &file_user_v1_user_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Identity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Identity) ProtoMessage() {}
+
+func (x *Identity) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Identity.ProtoReflect.Descriptor instead.
+func (*Identity) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *Identity) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *Identity) GetMaskedProviderId() string {
+	if x != nil {
+		return x.MaskedProviderId
+	}
+	return ""
+}
+
+func (x *Identity) GetLinkedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LinkedAt
+	}
+	return nil
+}
+
+type RequestAccountDeletionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestAccountDeletionRequest) Reset() {
+	*x = RequestAccountDeletionRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestAccountDeletionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestAccountDeletionRequest) ProtoMessage() {}
+
+func (x *RequestAccountDeletionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestAccountDeletionRequest.ProtoReflect.Descriptor instead.
+func (*RequestAccountDeletionRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{26}
+}
+
+type RequestAccountDeletionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScheduledAt   *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=scheduled_at,json=scheduledAt,proto3" json:"scheduled_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestAccountDeletionResponse) Reset() {
+	*x = RequestAccountDeletionResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestAccountDeletionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestAccountDeletionResponse) ProtoMessage() {}
+
+func (x *RequestAccountDeletionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestAccountDeletionResponse.ProtoReflect.Descriptor instead.
+func (*RequestAccountDeletionResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *RequestAccountDeletionResponse) GetScheduledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ScheduledAt
+	}
+	return nil
+}
+
+type CancelAccountDeletionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelAccountDeletionRequest) Reset() {
+	*x = CancelAccountDeletionRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelAccountDeletionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelAccountDeletionRequest) ProtoMessage() {}
+
+func (x *CancelAccountDeletionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelAccountDeletionRequest.ProtoReflect.Descriptor instead.
+func (*CancelAccountDeletionRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{28}
+}
+
+type CancelAccountDeletionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelAccountDeletionResponse) Reset() {
+	*x = CancelAccountDeletionResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelAccountDeletionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelAccountDeletionResponse) ProtoMessage() {}
+
+func (x *CancelAccountDeletionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelAccountDeletionResponse.ProtoReflect.Descriptor instead.
+func (*CancelAccountDeletionResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{29}
+}
+
+type RequestEmailChangeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NewEmail      string                 `protobuf:"bytes,1,opt,name=new_email,json=newEmail,proto3" json:"new_email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestEmailChangeRequest) Reset() {
+	*x = RequestEmailChangeRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestEmailChangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestEmailChangeRequest) ProtoMessage() {}
+
+func (x *RequestEmailChangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestEmailChangeRequest.ProtoReflect.Descriptor instead.
+func (*RequestEmailChangeRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *RequestEmailChangeRequest) GetNewEmail() string {
+	if x != nil {
+		return x.NewEmail
+	}
+	return ""
+}
+
+type RequestEmailChangeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestEmailChangeResponse) Reset() {
+	*x = RequestEmailChangeResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestEmailChangeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestEmailChangeResponse) ProtoMessage() {}
+
+func (x *RequestEmailChangeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestEmailChangeResponse.ProtoReflect.Descriptor instead.
+func (*RequestEmailChangeResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *RequestEmailChangeResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type ConfirmEmailChangeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfirmEmailChangeRequest) Reset() {
+	*x = ConfirmEmailChangeRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmEmailChangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmEmailChangeRequest) ProtoMessage() {}
+
+func (x *ConfirmEmailChangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmEmailChangeRequest.ProtoReflect.Descriptor instead.
+func (*ConfirmEmailChangeRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ConfirmEmailChangeRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type ConfirmEmailChangeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Whether both addresses have confirmed and the email was changed
+	Completed     bool `protobuf:"varint,1,opt,name=completed,proto3" json:"completed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfirmEmailChangeResponse) Reset() {
+	*x = ConfirmEmailChangeResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmEmailChangeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmEmailChangeResponse) ProtoMessage() {}
+
+func (x *ConfirmEmailChangeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmEmailChangeResponse.ProtoReflect.Descriptor instead.
+func (*ConfirmEmailChangeResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ConfirmEmailChangeResponse) GetCompleted() bool {
+	if x != nil {
+		return x.Completed
+	}
+	return false
+}
+
+type RollbackEmailChangeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RollbackEmailChangeRequest) Reset() {
+	*x = RollbackEmailChangeRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackEmailChangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackEmailChangeRequest) ProtoMessage() {}
+
+func (x *RollbackEmailChangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackEmailChangeRequest.ProtoReflect.Descriptor instead.
+func (*RollbackEmailChangeRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *RollbackEmailChangeRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type RollbackEmailChangeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RollbackEmailChangeResponse) Reset() {
+	*x = RollbackEmailChangeResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackEmailChangeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackEmailChangeResponse) ProtoMessage() {}
+
+func (x *RollbackEmailChangeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackEmailChangeResponse.ProtoReflect.Descriptor instead.
+func (*RollbackEmailChangeResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{35}
+}
+
+type ChangePasswordRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Required unless the account has no password yet
+	CurrentPassword string `protobuf:"bytes,1,opt,name=current_password,json=currentPassword,proto3" json:"current_password,omitempty"`
+	NewPassword     string `protobuf:"bytes,2,opt,name=new_password,json=newPassword,proto3" json:"new_password,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ChangePasswordRequest) Reset() {
+	*x = ChangePasswordRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangePasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangePasswordRequest) ProtoMessage() {}
+
+func (x *ChangePasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangePasswordRequest.ProtoReflect.Descriptor instead.
+func (*ChangePasswordRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ChangePasswordRequest) GetCurrentPassword() string {
+	if x != nil {
+		return x.CurrentPassword
+	}
+	return ""
+}
+
+func (x *ChangePasswordRequest) GetNewPassword() string {
+	if x != nil {
+		return x.NewPassword
+	}
+	return ""
+}
+
+type ChangePasswordResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangePasswordResponse) Reset() {
+	*x = ChangePasswordResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangePasswordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangePasswordResponse) ProtoMessage() {}
+
+func (x *ChangePasswordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangePasswordResponse.ProtoReflect.Descriptor instead.
+func (*ChangePasswordResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{37}
+}
+
+// What a user wants to be emailed about. Emails the user asked for, like login
+// codes or email change confirmations, are always sent.
+type NotificationPreferences struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Notices about the security of the account, e.g. upcoming deactivation
+	SecurityEmails bool `protobuf:"varint,1,opt,name=security_emails,json=securityEmails,proto3" json:"security_emails,omitempty"`
+	// News about the product
+	ProductEmails bool `protobuf:"varint,2,opt,name=product_emails,json=productEmails,proto3" json:"product_emails,omitempty"`
+	// Alerts of logins from a new device or network
+	LoginAlerts   bool `protobuf:"varint,3,opt,name=login_alerts,json=loginAlerts,proto3" json:"login_alerts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotificationPreferences) Reset() {
+	*x = NotificationPreferences{}
+	mi := &file_user_v1_user_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotificationPreferences) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotificationPreferences) ProtoMessage() {}
+
+func (x *NotificationPreferences) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotificationPreferences.ProtoReflect.Descriptor instead.
+func (*NotificationPreferences) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *NotificationPreferences) GetSecurityEmails() bool {
+	if x != nil {
+		return x.SecurityEmails
+	}
+	return false
+}
+
+func (x *NotificationPreferences) GetProductEmails() bool {
+	if x != nil {
+		return x.ProductEmails
+	}
+	return false
+}
+
+func (x *NotificationPreferences) GetLoginAlerts() bool {
+	if x != nil {
+		return x.LoginAlerts
+	}
+	return false
+}
+
+type GetNotificationPreferencesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNotificationPreferencesRequest) Reset() {
+	*x = GetNotificationPreferencesRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNotificationPreferencesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNotificationPreferencesRequest) ProtoMessage() {}
+
+func (x *GetNotificationPreferencesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNotificationPreferencesRequest.ProtoReflect.Descriptor instead.
+func (*GetNotificationPreferencesRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{39}
+}
+
+type GetNotificationPreferencesResponse struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Preferences   *NotificationPreferences `protobuf:"bytes,1,opt,name=preferences,proto3" json:"preferences,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNotificationPreferencesResponse) Reset() {
+	*x = GetNotificationPreferencesResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNotificationPreferencesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNotificationPreferencesResponse) ProtoMessage() {}
+
+func (x *GetNotificationPreferencesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNotificationPreferencesResponse.ProtoReflect.Descriptor instead.
+func (*GetNotificationPreferencesResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *GetNotificationPreferencesResponse) GetPreferences() *NotificationPreferences {
+	if x != nil {
+		return x.Preferences
+	}
+	return nil
+}
+
+// Unset fields keep their current value
+type UpdateNotificationPreferencesRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SecurityEmails *bool                  `protobuf:"varint,1,opt,name=security_emails,json=securityEmails,proto3,oneof" json:"security_emails,omitempty"`
+	ProductEmails  *bool                  `protobuf:"varint,2,opt,name=product_emails,json=productEmails,proto3,oneof" json:"product_emails,omitempty"`
+	LoginAlerts    *bool                  `protobuf:"varint,3,opt,name=login_alerts,json=loginAlerts,proto3,oneof" json:"login_alerts,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UpdateNotificationPreferencesRequest) Reset() {
+	*x = UpdateNotificationPreferencesRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateNotificationPreferencesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateNotificationPreferencesRequest) ProtoMessage() {}
+
+func (x *UpdateNotificationPreferencesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateNotificationPreferencesRequest.ProtoReflect.Descriptor instead.
+func (*UpdateNotificationPreferencesRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *UpdateNotificationPreferencesRequest) GetSecurityEmails() bool {
+	if x != nil && x.SecurityEmails != nil {
+		return *x.SecurityEmails
+	}
+	return false
+}
+
+func (x *UpdateNotificationPreferencesRequest) GetProductEmails() bool {
+	if x != nil && x.ProductEmails != nil {
+		return *x.ProductEmails
+	}
+	return false
+}
+
+func (x *UpdateNotificationPreferencesRequest) GetLoginAlerts() bool {
+	if x != nil && x.LoginAlerts != nil {
+		return *x.LoginAlerts
+	}
+	return false
+}
+
+type UpdateNotificationPreferencesResponse struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Preferences   *NotificationPreferences `protobuf:"bytes,1,opt,name=preferences,proto3" json:"preferences,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateNotificationPreferencesResponse) Reset() {
+	*x = UpdateNotificationPreferencesResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateNotificationPreferencesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateNotificationPreferencesResponse) ProtoMessage() {}
+
+func (x *UpdateNotificationPreferencesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateNotificationPreferencesResponse.ProtoReflect.Descriptor instead.
+func (*UpdateNotificationPreferencesResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *UpdateNotificationPreferencesResponse) GetPreferences() *NotificationPreferences {
+	if x != nil {
+		return x.Preferences
+	}
+	return nil
+}
+
+type AdminRevokeUserSessionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminRevokeUserSessionsRequest) Reset() {
+	*x = AdminRevokeUserSessionsRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminRevokeUserSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminRevokeUserSessionsRequest) ProtoMessage() {}
+
+func (x *AdminRevokeUserSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminRevokeUserSessionsRequest.ProtoReflect.Descriptor instead.
+func (*AdminRevokeUserSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *AdminRevokeUserSessionsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type AdminRevokeUserSessionsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of sessions that were revoked
+	Revoked       uint32 `protobuf:"varint,1,opt,name=revoked,proto3" json:"revoked,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminRevokeUserSessionsResponse) Reset() {
+	*x = AdminRevokeUserSessionsResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminRevokeUserSessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminRevokeUserSessionsResponse) ProtoMessage() {}
+
+func (x *AdminRevokeUserSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminRevokeUserSessionsResponse.ProtoReflect.Descriptor instead.
+func (*AdminRevokeUserSessionsResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *AdminRevokeUserSessionsResponse) GetRevoked() uint32 {
+	if x != nil {
+		return x.Revoked
+	}
+	return 0
+}
+
+type AdminRevokeSessionRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID of the session, or its reference as found in the sid claim of its tokens
+	SessionId     string `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminRevokeSessionRequest) Reset() {
+	*x = AdminRevokeSessionRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminRevokeSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminRevokeSessionRequest) ProtoMessage() {}
+
+func (x *AdminRevokeSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminRevokeSessionRequest.ProtoReflect.Descriptor instead.
+func (*AdminRevokeSessionRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *AdminRevokeSessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type AdminRevokeSessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminRevokeSessionResponse) Reset() {
+	*x = AdminRevokeSessionResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminRevokeSessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminRevokeSessionResponse) ProtoMessage() {}
+
+func (x *AdminRevokeSessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminRevokeSessionResponse.ProtoReflect.Descriptor instead.
+func (*AdminRevokeSessionResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{46}
+}
+
+// Settings of a tenant; zero values keep the configuration of the deployment
+type TenantSettings struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Org   string                 `protobuf:"bytes,1,opt,name=org,proto3" json:"org,omitempty"`
+	// Shown on the login page of the tenant; https only
+	LogoUrl string `protobuf:"bytes,2,opt,name=logo_url,json=logoUrl,proto3" json:"logo_url,omitempty"`
+	// OAuth providers the users of the tenant may log in with, all if empty
+	AllowedProviders []string `protobuf:"bytes,3,rep,name=allowed_providers,json=allowedProviders,proto3" json:"allowed_providers,omitempty"`
+	// Caps the lifetime of access tokens
+	AccessTokenLifetimeSeconds int64 `protobuf:"varint,4,opt,name=access_token_lifetime_seconds,json=accessTokenLifetimeSeconds,proto3" json:"access_token_lifetime_seconds,omitempty"`
+	// Caps the lifetime of sessions
+	SessionLifetimeSeconds int64 `protobuf:"varint,5,opt,name=session_lifetime_seconds,json=sessionLifetimeSeconds,proto3" json:"session_lifetime_seconds,omitempty"`
+	// Raises the minimum length of passwords, at most 128
+	PasswordMinLength int32                  `protobuf:"varint,6,opt,name=password_min_length,json=passwordMinLength,proto3" json:"password_min_length,omitempty"`
+	UpdatedAt         *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3,oneof" json:"updated_at,omitempty"`
+	// ID of the admin who last updated the settings
+	UpdatedBy     string `protobuf:"bytes,8,opt,name=updated_by,json=updatedBy,proto3" json:"updated_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TenantSettings) Reset() {
+	*x = TenantSettings{}
+	mi := &file_user_v1_user_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TenantSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TenantSettings) ProtoMessage() {}
+
+func (x *TenantSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TenantSettings.ProtoReflect.Descriptor instead.
+func (*TenantSettings) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *TenantSettings) GetOrg() string {
+	if x != nil {
+		return x.Org
+	}
+	return ""
+}
+
+func (x *TenantSettings) GetLogoUrl() string {
+	if x != nil {
+		return x.LogoUrl
+	}
+	return ""
+}
+
+func (x *TenantSettings) GetAllowedProviders() []string {
+	if x != nil {
+		return x.AllowedProviders
+	}
+	return nil
+}
+
+func (x *TenantSettings) GetAccessTokenLifetimeSeconds() int64 {
+	if x != nil {
+		return x.AccessTokenLifetimeSeconds
+	}
+	return 0
+}
+
+func (x *TenantSettings) GetSessionLifetimeSeconds() int64 {
+	if x != nil {
+		return x.SessionLifetimeSeconds
+	}
+	return 0
+}
+
+func (x *TenantSettings) GetPasswordMinLength() int32 {
+	if x != nil {
+		return x.PasswordMinLength
+	}
+	return 0
+}
+
+func (x *TenantSettings) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *TenantSettings) GetUpdatedBy() string {
+	if x != nil {
+		return x.UpdatedBy
+	}
+	return ""
+}
+
+type ListTenantSettingsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTenantSettingsRequest) Reset() {
+	*x = ListTenantSettingsRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTenantSettingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTenantSettingsRequest) ProtoMessage() {}
+
+func (x *ListTenantSettingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTenantSettingsRequest.ProtoReflect.Descriptor instead.
+func (*ListTenantSettingsRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{48}
+}
+
+type ListTenantSettingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tenants       []*TenantSettings      `protobuf:"bytes,1,rep,name=tenants,proto3" json:"tenants,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTenantSettingsResponse) Reset() {
+	*x = ListTenantSettingsResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTenantSettingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTenantSettingsResponse) ProtoMessage() {}
+
+func (x *ListTenantSettingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTenantSettingsResponse.ProtoReflect.Descriptor instead.
+func (*ListTenantSettingsResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *ListTenantSettingsResponse) GetTenants() []*TenantSettings {
+	if x != nil {
+		return x.Tenants
+	}
+	return nil
+}
+
+type GetTenantSettingsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Org           string                 `protobuf:"bytes,1,opt,name=org,proto3" json:"org,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTenantSettingsRequest) Reset() {
+	*x = GetTenantSettingsRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTenantSettingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTenantSettingsRequest) ProtoMessage() {}
+
+func (x *GetTenantSettingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTenantSettingsRequest.ProtoReflect.Descriptor instead.
+func (*GetTenantSettingsRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *GetTenantSettingsRequest) GetOrg() string {
+	if x != nil {
+		return x.Org
+	}
+	return ""
+}
+
+type GetTenantSettingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Settings      *TenantSettings        `protobuf:"bytes,1,opt,name=settings,proto3" json:"settings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTenantSettingsResponse) Reset() {
+	*x = GetTenantSettingsResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTenantSettingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTenantSettingsResponse) ProtoMessage() {}
+
+func (x *GetTenantSettingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTenantSettingsResponse.ProtoReflect.Descriptor instead.
+func (*GetTenantSettingsResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *GetTenantSettingsResponse) GetSettings() *TenantSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+// Names of OAuth providers, so an empty list can be told from an unset one
+type TenantProviders struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Names         []string               `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TenantProviders) Reset() {
+	*x = TenantProviders{}
+	mi := &file_user_v1_user_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TenantProviders) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TenantProviders) ProtoMessage() {}
+
+func (x *TenantProviders) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TenantProviders.ProtoReflect.Descriptor instead.
+func (*TenantProviders) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *TenantProviders) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+// Unset fields keep their current value; zero resets a field to the default
+type UpdateTenantSettingsRequest struct {
+	state                      protoimpl.MessageState `protogen:"open.v1"`
+	Org                        string                 `protobuf:"bytes,1,opt,name=org,proto3" json:"org,omitempty"`
+	LogoUrl                    *string                `protobuf:"bytes,2,opt,name=logo_url,json=logoUrl,proto3,oneof" json:"logo_url,omitempty"`
+	AllowedProviders           *TenantProviders       `protobuf:"bytes,3,opt,name=allowed_providers,json=allowedProviders,proto3" json:"allowed_providers,omitempty"`
+	AccessTokenLifetimeSeconds *int64                 `protobuf:"varint,4,opt,name=access_token_lifetime_seconds,json=accessTokenLifetimeSeconds,proto3,oneof" json:"access_token_lifetime_seconds,omitempty"`
+	SessionLifetimeSeconds     *int64                 `protobuf:"varint,5,opt,name=session_lifetime_seconds,json=sessionLifetimeSeconds,proto3,oneof" json:"session_lifetime_seconds,omitempty"`
+	PasswordMinLength          *int32                 `protobuf:"varint,6,opt,name=password_min_length,json=passwordMinLength,proto3,oneof" json:"password_min_length,omitempty"`
+	unknownFields              protoimpl.UnknownFields
+	sizeCache                  protoimpl.SizeCache
+}
+
+func (x *UpdateTenantSettingsRequest) Reset() {
+	*x = UpdateTenantSettingsRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTenantSettingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTenantSettingsRequest) ProtoMessage() {}
+
+func (x *UpdateTenantSettingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTenantSettingsRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTenantSettingsRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *UpdateTenantSettingsRequest) GetOrg() string {
+	if x != nil {
+		return x.Org
+	}
+	return ""
+}
+
+func (x *UpdateTenantSettingsRequest) GetLogoUrl() string {
+	if x != nil && x.LogoUrl != nil {
+		return *x.LogoUrl
+	}
+	return ""
+}
+
+func (x *UpdateTenantSettingsRequest) GetAllowedProviders() *TenantProviders {
+	if x != nil {
+		return x.AllowedProviders
+	}
+	return nil
+}
+
+func (x *UpdateTenantSettingsRequest) GetAccessTokenLifetimeSeconds() int64 {
+	if x != nil && x.AccessTokenLifetimeSeconds != nil {
+		return *x.AccessTokenLifetimeSeconds
+	}
+	return 0
+}
+
+func (x *UpdateTenantSettingsRequest) GetSessionLifetimeSeconds() int64 {
+	if x != nil && x.SessionLifetimeSeconds != nil {
+		return *x.SessionLifetimeSeconds
+	}
+	return 0
+}
+
+func (x *UpdateTenantSettingsRequest) GetPasswordMinLength() int32 {
+	if x != nil && x.PasswordMinLength != nil {
+		return *x.PasswordMinLength
+	}
+	return 0
+}
+
+type UpdateTenantSettingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Settings      *TenantSettings        `protobuf:"bytes,1,opt,name=settings,proto3" json:"settings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTenantSettingsResponse) Reset() {
+	*x = UpdateTenantSettingsResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTenantSettingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTenantSettingsResponse) ProtoMessage() {}
+
+func (x *UpdateTenantSettingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTenantSettingsResponse.ProtoReflect.Descriptor instead.
+func (*UpdateTenantSettingsResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *UpdateTenantSettingsResponse) GetSettings() *TenantSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+type DeleteTenantSettingsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Org           string                 `protobuf:"bytes,1,opt,name=org,proto3" json:"org,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTenantSettingsRequest) Reset() {
+	*x = DeleteTenantSettingsRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTenantSettingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTenantSettingsRequest) ProtoMessage() {}
+
+func (x *DeleteTenantSettingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTenantSettingsRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTenantSettingsRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *DeleteTenantSettingsRequest) GetOrg() string {
+	if x != nil {
+		return x.Org
+	}
+	return ""
+}
+
+type DeleteTenantSettingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTenantSettingsResponse) Reset() {
+	*x = DeleteTenantSettingsResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTenantSettingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTenantSettingsResponse) ProtoMessage() {}
+
+func (x *DeleteTenantSettingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTenantSettingsResponse.ProtoReflect.Descriptor instead.
+func (*DeleteTenantSettingsResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{56}
+}
+
+type GetTenantPublicConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Org           string                 `protobuf:"bytes,1,opt,name=org,proto3" json:"org,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTenantPublicConfigRequest) Reset() {
+	*x = GetTenantPublicConfigRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTenantPublicConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTenantPublicConfigRequest) ProtoMessage() {}
+
+func (x *GetTenantPublicConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTenantPublicConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetTenantPublicConfigRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *GetTenantPublicConfigRequest) GetOrg() string {
+	if x != nil {
+		return x.Org
+	}
+	return ""
+}
+
+type GetTenantPublicConfigResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Org     string                 `protobuf:"bytes,1,opt,name=org,proto3" json:"org,omitempty"`
+	LogoUrl string                 `protobuf:"bytes,2,opt,name=logo_url,json=logoUrl,proto3" json:"logo_url,omitempty"`
+	// OAuth providers the users of the tenant may log in with
+	OauthProviders    []string `protobuf:"bytes,3,rep,name=oauth_providers,json=oauthProviders,proto3" json:"oauth_providers,omitempty"`
+	PasswordMinLength int32    `protobuf:"varint,4,opt,name=password_min_length,json=passwordMinLength,proto3" json:"password_min_length,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *GetTenantPublicConfigResponse) Reset() {
+	*x = GetTenantPublicConfigResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTenantPublicConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTenantPublicConfigResponse) ProtoMessage() {}
+
+func (x *GetTenantPublicConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTenantPublicConfigResponse.ProtoReflect.Descriptor instead.
+func (*GetTenantPublicConfigResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *GetTenantPublicConfigResponse) GetOrg() string {
+	if x != nil {
+		return x.Org
+	}
+	return ""
+}
+
+func (x *GetTenantPublicConfigResponse) GetLogoUrl() string {
+	if x != nil {
+		return x.LogoUrl
+	}
+	return ""
+}
+
+func (x *GetTenantPublicConfigResponse) GetOauthProviders() []string {
+	if x != nil {
+		return x.OauthProviders
+	}
+	return nil
+}
+
+func (x *GetTenantPublicConfigResponse) GetPasswordMinLength() int32 {
+	if x != nil {
+		return x.PasswordMinLength
+	}
+	return 0
+}
+
+type AdminInviteUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminInviteUserRequest) Reset() {
+	*x = AdminInviteUserRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminInviteUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminInviteUserRequest) ProtoMessage() {}
+
+func (x *AdminInviteUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminInviteUserRequest.ProtoReflect.Descriptor instead.
+func (*AdminInviteUserRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *AdminInviteUserRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type AdminInviteUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminInviteUserResponse) Reset() {
+	*x = AdminInviteUserResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminInviteUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminInviteUserResponse) ProtoMessage() {}
+
+func (x *AdminInviteUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminInviteUserResponse.ProtoReflect.Descriptor instead.
+func (*AdminInviteUserResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *AdminInviteUserResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type FeatureFlag struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Enabled       bool                   `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FeatureFlag) Reset() {
+	*x = FeatureFlag{}
+	mi := &file_user_v1_user_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FeatureFlag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeatureFlag) ProtoMessage() {}
+
+func (x *FeatureFlag) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeatureFlag.ProtoReflect.Descriptor instead.
+func (*FeatureFlag) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *FeatureFlag) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FeatureFlag) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+type AdminListFeatureFlagsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminListFeatureFlagsRequest) Reset() {
+	*x = AdminListFeatureFlagsRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminListFeatureFlagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminListFeatureFlagsRequest) ProtoMessage() {}
+
+func (x *AdminListFeatureFlagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminListFeatureFlagsRequest.ProtoReflect.Descriptor instead.
+func (*AdminListFeatureFlagsRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{62}
+}
+
+type AdminListFeatureFlagsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Flags         []*FeatureFlag         `protobuf:"bytes,1,rep,name=flags,proto3" json:"flags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminListFeatureFlagsResponse) Reset() {
+	*x = AdminListFeatureFlagsResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminListFeatureFlagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminListFeatureFlagsResponse) ProtoMessage() {}
+
+func (x *AdminListFeatureFlagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminListFeatureFlagsResponse.ProtoReflect.Descriptor instead.
+func (*AdminListFeatureFlagsResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *AdminListFeatureFlagsResponse) GetFlags() []*FeatureFlag {
+	if x != nil {
+		return x.Flags
+	}
+	return nil
+}
+
+type AdminSetFeatureFlagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Enabled       bool                   `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminSetFeatureFlagRequest) Reset() {
+	*x = AdminSetFeatureFlagRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminSetFeatureFlagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminSetFeatureFlagRequest) ProtoMessage() {}
+
+func (x *AdminSetFeatureFlagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminSetFeatureFlagRequest.ProtoReflect.Descriptor instead.
+func (*AdminSetFeatureFlagRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *AdminSetFeatureFlagRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AdminSetFeatureFlagRequest) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+type AdminSetFeatureFlagResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Flag          *FeatureFlag           `protobuf:"bytes,1,opt,name=flag,proto3" json:"flag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminSetFeatureFlagResponse) Reset() {
+	*x = AdminSetFeatureFlagResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminSetFeatureFlagResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminSetFeatureFlagResponse) ProtoMessage() {}
+
+func (x *AdminSetFeatureFlagResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminSetFeatureFlagResponse.ProtoReflect.Descriptor instead.
+func (*AdminSetFeatureFlagResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *AdminSetFeatureFlagResponse) GetFlag() *FeatureFlag {
+	if x != nil {
+		return x.Flag
+	}
+	return nil
+}
+
+type Failpoint struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Point, optionally qualified, e.g. "redis", "redis:get", "db:query" or
+	// "provider:api.github.com"
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Fail the calls, after the latency
+	Fail                bool   `protobuf:"varint,2,opt,name=fail,proto3" json:"fail,omitempty"`
+	LatencyMilliseconds uint32 `protobuf:"varint,3,opt,name=latency_milliseconds,json=latencyMilliseconds,proto3" json:"latency_milliseconds,omitempty"`
+	// Share of the calls affected, all of them if 0
+	Percent uint32 `protobuf:"varint,4,opt,name=percent,proto3" json:"percent,omitempty"`
+	// When the failpoint clears itself, unset if never
+	ExpireTime    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expire_time,json=expireTime,proto3" json:"expire_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Failpoint) Reset() {
+	*x = Failpoint{}
+	mi := &file_user_v1_user_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Failpoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Failpoint) ProtoMessage() {}
+
+func (x *Failpoint) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Failpoint.ProtoReflect.Descriptor instead.
+func (*Failpoint) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *Failpoint) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Failpoint) GetFail() bool {
+	if x != nil {
+		return x.Fail
+	}
+	return false
+}
+
+func (x *Failpoint) GetLatencyMilliseconds() uint32 {
+	if x != nil {
+		return x.LatencyMilliseconds
+	}
+	return 0
+}
+
+func (x *Failpoint) GetPercent() uint32 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *Failpoint) GetExpireTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpireTime
+	}
+	return nil
+}
+
+type AdminListFailpointsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminListFailpointsRequest) Reset() {
+	*x = AdminListFailpointsRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminListFailpointsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminListFailpointsRequest) ProtoMessage() {}
+
+func (x *AdminListFailpointsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminListFailpointsRequest.ProtoReflect.Descriptor instead.
+func (*AdminListFailpointsRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{67}
+}
+
+type AdminListFailpointsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Failpoints    []*Failpoint           `protobuf:"bytes,1,rep,name=failpoints,proto3" json:"failpoints,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminListFailpointsResponse) Reset() {
+	*x = AdminListFailpointsResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminListFailpointsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminListFailpointsResponse) ProtoMessage() {}
+
+func (x *AdminListFailpointsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminListFailpointsResponse.ProtoReflect.Descriptor instead.
+func (*AdminListFailpointsResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *AdminListFailpointsResponse) GetFailpoints() []*Failpoint {
+	if x != nil {
+		return x.Failpoints
+	}
+	return nil
+}
+
+type AdminSetFailpointRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Name                string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Fail                bool                   `protobuf:"varint,2,opt,name=fail,proto3" json:"fail,omitempty"`
+	LatencyMilliseconds uint32                 `protobuf:"varint,3,opt,name=latency_milliseconds,json=latencyMilliseconds,proto3" json:"latency_milliseconds,omitempty"`
+	Percent             uint32                 `protobuf:"varint,4,opt,name=percent,proto3" json:"percent,omitempty"`
+	// Clear the failpoint after this long, 0 to keep it
+	DurationSeconds uint32 `protobuf:"varint,5,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AdminSetFailpointRequest) Reset() {
+	*x = AdminSetFailpointRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminSetFailpointRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminSetFailpointRequest) ProtoMessage() {}
+
+func (x *AdminSetFailpointRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminSetFailpointRequest.ProtoReflect.Descriptor instead.
+func (*AdminSetFailpointRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *AdminSetFailpointRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AdminSetFailpointRequest) GetFail() bool {
+	if x != nil {
+		return x.Fail
+	}
+	return false
+}
+
+func (x *AdminSetFailpointRequest) GetLatencyMilliseconds() uint32 {
+	if x != nil {
+		return x.LatencyMilliseconds
+	}
+	return 0
+}
+
+func (x *AdminSetFailpointRequest) GetPercent() uint32 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *AdminSetFailpointRequest) GetDurationSeconds() uint32 {
+	if x != nil {
+		return x.DurationSeconds
+	}
+	return 0
+}
+
+type AdminSetFailpointResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unset if the failpoint was cleared
+	Failpoint     *Failpoint `protobuf:"bytes,1,opt,name=failpoint,proto3" json:"failpoint,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminSetFailpointResponse) Reset() {
+	*x = AdminSetFailpointResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminSetFailpointResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminSetFailpointResponse) ProtoMessage() {}
+
+func (x *AdminSetFailpointResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminSetFailpointResponse.ProtoReflect.Descriptor instead.
+func (*AdminSetFailpointResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *AdminSetFailpointResponse) GetFailpoint() *Failpoint {
+	if x != nil {
+		return x.Failpoint
+	}
+	return nil
+}
+
+type AdminGetRuntimeConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminGetRuntimeConfigRequest) Reset() {
+	*x = AdminGetRuntimeConfigRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminGetRuntimeConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminGetRuntimeConfigRequest) ProtoMessage() {}
+
+func (x *AdminGetRuntimeConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminGetRuntimeConfigRequest.ProtoReflect.Descriptor instead.
+func (*AdminGetRuntimeConfigRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{71}
+}
+
+type AdminGetRuntimeConfigResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Effective configuration by section and field, e.g. config.Auth.JWTIssuer;
+	// set secrets read "[REDACTED]" and durations are strings like "1h0m0s"
+	Config *structpb.Struct `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	// MODE selecting the configuration file merged over the defaults
+	Mode string `protobuf:"bytes,2,opt,name=mode,proto3" json:"mode,omitempty"`
+	// Configuration files loaded, in the order they were merged
+	ConfigFiles []string `protobuf:"bytes,3,rep,name=config_files,json=configFiles,proto3" json:"config_files,omitempty"`
+	// Keys set by environment variables, which take precedence over the files
+	EnvOverrides []string `protobuf:"bytes,4,rep,name=env_overrides,json=envOverrides,proto3" json:"env_overrides,omitempty"`
+	// Host name of the server that answered, e.g. its pod
+	Hostname string `protobuf:"bytes,5,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	// Version of the server that answered
+	Version       string `protobuf:"bytes,6,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminGetRuntimeConfigResponse) Reset() {
+	*x = AdminGetRuntimeConfigResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminGetRuntimeConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminGetRuntimeConfigResponse) ProtoMessage() {}
+
+func (x *AdminGetRuntimeConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminGetRuntimeConfigResponse.ProtoReflect.Descriptor instead.
+func (*AdminGetRuntimeConfigResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *AdminGetRuntimeConfigResponse) GetConfig() *structpb.Struct {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *AdminGetRuntimeConfigResponse) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *AdminGetRuntimeConfigResponse) GetConfigFiles() []string {
+	if x != nil {
+		return x.ConfigFiles
+	}
+	return nil
+}
+
+func (x *AdminGetRuntimeConfigResponse) GetEnvOverrides() []string {
+	if x != nil {
+		return x.EnvOverrides
+	}
+	return nil
+}
+
+func (x *AdminGetRuntimeConfigResponse) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *AdminGetRuntimeConfigResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+// A pending OAuth login, between GetOAuthCodeURL and LoginByOAuth
+type OAuthState struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// First characters of the state, enough to tell states apart
+	StatePrefix   string                 `protobuf:"bytes,1,opt,name=state_prefix,json=statePrefix,proto3" json:"state_prefix,omitempty"`
+	Provider      string                 `protobuf:"bytes,2,opt,name=provider,proto3" json:"provider,omitempty"`
+	RedirectUrl   string                 `protobuf:"bytes,3,opt,name=redirect_url,json=redirectUrl,proto3" json:"redirect_url,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OAuthState) Reset() {
+	*x = OAuthState{}
+	mi := &file_user_v1_user_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OAuthState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OAuthState) ProtoMessage() {}
+
+func (x *OAuthState) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OAuthState.ProtoReflect.Descriptor instead.
+func (*OAuthState) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *OAuthState) GetStatePrefix() string {
+	if x != nil {
+		return x.StatePrefix
+	}
+	return ""
+}
+
+func (x *OAuthState) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *OAuthState) GetRedirectUrl() string {
+	if x != nil {
+		return x.RedirectUrl
+	}
+	return ""
+}
+
+func (x *OAuthState) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *OAuthState) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type AdminListOAuthStatesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only list the states of this provider, e.g. "github"
+	Provider      string `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminListOAuthStatesRequest) Reset() {
+	*x = AdminListOAuthStatesRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminListOAuthStatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminListOAuthStatesRequest) ProtoMessage() {}
+
+func (x *AdminListOAuthStatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminListOAuthStatesRequest.ProtoReflect.Descriptor instead.
+func (*AdminListOAuthStatesRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *AdminListOAuthStatesRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+type AdminListOAuthStatesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of pending states
+	Total uint32 `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	// Number of pending states by provider
+	CountByProvider map[string]uint32 `protobuf:"bytes,2,rep,name=count_by_provider,json=countByProvider,proto3" json:"count_by_provider,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	// Age in seconds of the oldest pending state
+	OldestAgeSeconds uint32 `protobuf:"varint,3,opt,name=oldest_age_seconds,json=oldestAgeSeconds,proto3" json:"oldest_age_seconds,omitempty"`
+	// The most recent states, at most 100
+	States        []*OAuthState `protobuf:"bytes,4,rep,name=states,proto3" json:"states,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminListOAuthStatesResponse) Reset() {
+	*x = AdminListOAuthStatesResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminListOAuthStatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminListOAuthStatesResponse) ProtoMessage() {}
+
+func (x *AdminListOAuthStatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[75]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminListOAuthStatesResponse.ProtoReflect.Descriptor instead.
+func (*AdminListOAuthStatesResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{75}
+}
+
+func (x *AdminListOAuthStatesResponse) GetTotal() uint32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *AdminListOAuthStatesResponse) GetCountByProvider() map[string]uint32 {
+	if x != nil {
+		return x.CountByProvider
+	}
+	return nil
+}
+
+func (x *AdminListOAuthStatesResponse) GetOldestAgeSeconds() uint32 {
+	if x != nil {
+		return x.OldestAgeSeconds
+	}
+	return 0
+}
+
+func (x *AdminListOAuthStatesResponse) GetStates() []*OAuthState {
+	if x != nil {
+		return x.States
+	}
+	return nil
+}
+
+type AdminPurgeOAuthStatesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only purge the states of this provider; empty purges all providers
+	Provider string `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	// Only purge states created at least this many seconds ago
+	OlderThanSeconds uint32 `protobuf:"varint,2,opt,name=older_than_seconds,json=olderThanSeconds,proto3" json:"older_than_seconds,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *AdminPurgeOAuthStatesRequest) Reset() {
+	*x = AdminPurgeOAuthStatesRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[76]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminPurgeOAuthStatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminPurgeOAuthStatesRequest) ProtoMessage() {}
+
+func (x *AdminPurgeOAuthStatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[76]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminPurgeOAuthStatesRequest.ProtoReflect.Descriptor instead.
+func (*AdminPurgeOAuthStatesRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{76}
+}
+
+func (x *AdminPurgeOAuthStatesRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *AdminPurgeOAuthStatesRequest) GetOlderThanSeconds() uint32 {
+	if x != nil {
+		return x.OlderThanSeconds
+	}
+	return 0
+}
+
+type AdminPurgeOAuthStatesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of states that were purged
+	Purged        uint32 `protobuf:"varint,1,opt,name=purged,proto3" json:"purged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminPurgeOAuthStatesResponse) Reset() {
+	*x = AdminPurgeOAuthStatesResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[77]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminPurgeOAuthStatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminPurgeOAuthStatesResponse) ProtoMessage() {}
+
+func (x *AdminPurgeOAuthStatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[77]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminPurgeOAuthStatesResponse.ProtoReflect.Descriptor instead.
+func (*AdminPurgeOAuthStatesResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{77}
+}
+
+func (x *AdminPurgeOAuthStatesResponse) GetPurged() uint32 {
+	if x != nil {
+		return x.Purged
+	}
+	return 0
+}
+
+type AdminSendSecurityNoticeRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	UserId  string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Subject string                 `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
+	Body    string                 `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	// Send the notice even if the user turned security emails off
+	Mandatory     bool `protobuf:"varint,4,opt,name=mandatory,proto3" json:"mandatory,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminSendSecurityNoticeRequest) Reset() {
+	*x = AdminSendSecurityNoticeRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[78]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminSendSecurityNoticeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminSendSecurityNoticeRequest) ProtoMessage() {}
+
+func (x *AdminSendSecurityNoticeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[78]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminSendSecurityNoticeRequest.ProtoReflect.Descriptor instead.
+func (*AdminSendSecurityNoticeRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{78}
+}
+
+func (x *AdminSendSecurityNoticeRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AdminSendSecurityNoticeRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *AdminSendSecurityNoticeRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *AdminSendSecurityNoticeRequest) GetMandatory() bool {
+	if x != nil {
+		return x.Mandatory
+	}
+	return false
+}
+
+type AdminSendSecurityNoticeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// False if the notice was not sent because of the user's preferences
+	Sent          bool `protobuf:"varint,1,opt,name=sent,proto3" json:"sent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminSendSecurityNoticeResponse) Reset() {
+	*x = AdminSendSecurityNoticeResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[79]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminSendSecurityNoticeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminSendSecurityNoticeResponse) ProtoMessage() {}
+
+func (x *AdminSendSecurityNoticeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[79]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminSendSecurityNoticeResponse.ProtoReflect.Descriptor instead.
+func (*AdminSendSecurityNoticeResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{79}
+}
+
+func (x *AdminSendSecurityNoticeResponse) GetSent() bool {
+	if x != nil {
+		return x.Sent
+	}
+	return false
+}
+
+type AdminUpdateUserEntitlementsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Entitlements to add; removals are applied after additions
+	Add           []string `protobuf:"bytes,2,rep,name=add,proto3" json:"add,omitempty"`
+	Remove        []string `protobuf:"bytes,3,rep,name=remove,proto3" json:"remove,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminUpdateUserEntitlementsRequest) Reset() {
+	*x = AdminUpdateUserEntitlementsRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[80]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminUpdateUserEntitlementsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminUpdateUserEntitlementsRequest) ProtoMessage() {}
+
+func (x *AdminUpdateUserEntitlementsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[80]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminUpdateUserEntitlementsRequest.ProtoReflect.Descriptor instead.
+func (*AdminUpdateUserEntitlementsRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{80}
+}
+
+func (x *AdminUpdateUserEntitlementsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AdminUpdateUserEntitlementsRequest) GetAdd() []string {
+	if x != nil {
+		return x.Add
+	}
+	return nil
+}
+
+func (x *AdminUpdateUserEntitlementsRequest) GetRemove() []string {
+	if x != nil {
+		return x.Remove
+	}
+	return nil
+}
+
+type AdminUpdateUserEntitlementsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entitlements  []string               `protobuf:"bytes,1,rep,name=entitlements,proto3" json:"entitlements,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminUpdateUserEntitlementsResponse) Reset() {
+	*x = AdminUpdateUserEntitlementsResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[81]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminUpdateUserEntitlementsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminUpdateUserEntitlementsResponse) ProtoMessage() {}
+
+func (x *AdminUpdateUserEntitlementsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[81]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminUpdateUserEntitlementsResponse.ProtoReflect.Descriptor instead.
+func (*AdminUpdateUserEntitlementsResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{81}
+}
+
+func (x *AdminUpdateUserEntitlementsResponse) GetEntitlements() []string {
+	if x != nil {
+		return x.Entitlements
+	}
+	return nil
+}
+
+type GetKeyUsageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetKeyUsageRequest) Reset() {
+	*x = GetKeyUsageRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[82]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetKeyUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetKeyUsageRequest) ProtoMessage() {}
+
+func (x *GetKeyUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[82]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetKeyUsageRequest.ProtoReflect.Descriptor instead.
+func (*GetKeyUsageRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{82}
+}
+
+func (x *GetKeyUsageRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type GetKeyUsageResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// The current UTC day and month, e.g. "2026-10-16" and "2026-10"
+	Day          string `protobuf:"bytes,2,opt,name=day,proto3" json:"day,omitempty"`
+	DailyCalls   int64  `protobuf:"varint,3,opt,name=daily_calls,json=dailyCalls,proto3" json:"daily_calls,omitempty"`
+	Month        string `protobuf:"bytes,4,opt,name=month,proto3" json:"month,omitempty"`
+	MonthlyCalls int64  `protobuf:"varint,5,opt,name=monthly_calls,json=monthlyCalls,proto3" json:"monthly_calls,omitempty"`
+	// 0 if the window is unbounded
+	DailyQuota    int64 `protobuf:"varint,6,opt,name=daily_quota,json=dailyQuota,proto3" json:"daily_quota,omitempty"`
+	MonthlyQuota  int64 `protobuf:"varint,7,opt,name=monthly_quota,json=monthlyQuota,proto3" json:"monthly_quota,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetKeyUsageResponse) Reset() {
+	*x = GetKeyUsageResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[83]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetKeyUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetKeyUsageResponse) ProtoMessage() {}
+
+func (x *GetKeyUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[83]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetKeyUsageResponse.ProtoReflect.Descriptor instead.
+func (*GetKeyUsageResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{83}
+}
+
+func (x *GetKeyUsageResponse) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *GetKeyUsageResponse) GetDay() string {
+	if x != nil {
+		return x.Day
+	}
+	return ""
+}
+
+func (x *GetKeyUsageResponse) GetDailyCalls() int64 {
+	if x != nil {
+		return x.DailyCalls
+	}
+	return 0
+}
+
+func (x *GetKeyUsageResponse) GetMonth() string {
+	if x != nil {
+		return x.Month
+	}
+	return ""
+}
+
+func (x *GetKeyUsageResponse) GetMonthlyCalls() int64 {
+	if x != nil {
+		return x.MonthlyCalls
+	}
+	return 0
+}
+
+func (x *GetKeyUsageResponse) GetDailyQuota() int64 {
+	if x != nil {
+		return x.DailyQuota
+	}
+	return 0
+}
+
+func (x *GetKeyUsageResponse) GetMonthlyQuota() int64 {
+	if x != nil {
+		return x.MonthlyQuota
+	}
+	return 0
+}
+
+type GetLoginSLORequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLoginSLORequest) Reset() {
+	*x = GetLoginSLORequest{}
+	mi := &file_user_v1_user_proto_msgTypes[84]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLoginSLORequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLoginSLORequest) ProtoMessage() {}
+
+func (x *GetLoginSLORequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[84]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLoginSLORequest.ProtoReflect.Descriptor instead.
+func (*GetLoginSLORequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{84}
+}
+
+type GetLoginSLOResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	WindowStart *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=window_start,json=windowStart,proto3" json:"window_start,omitempty"`
+	WindowEnd   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=window_end,json=windowEnd,proto3" json:"window_end,omitempty"`
+	// Share of logins not failing for a server error; logins refused for the
+	// request or the user (e.g. a wrong password) are not counted
+	LoginSuccess *ServiceLevelIndicator `protobuf:"bytes,3,opt,name=login_success,json=loginSuccess,proto3" json:"login_success,omitempty"`
+	// 99th percentile of the latency of GetUserToken in seconds, by the upper
+	// bound of its histogram bucket
+	TokenIssuanceP99 *ServiceLevelIndicator `protobuf:"bytes,4,opt,name=token_issuance_p99,json=tokenIssuanceP99,proto3" json:"token_issuance_p99,omitempty"`
+	// Share of OAuth code exchanges not failing for an error of the provider or
+	// the network; codes refused by the provider are not counted
+	OauthExchangeSuccess *ServiceLevelIndicator `protobuf:"bytes,5,opt,name=oauth_exchange_success,json=oauthExchangeSuccess,proto3" json:"oauth_exchange_success,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *GetLoginSLOResponse) Reset() {
+	*x = GetLoginSLOResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[85]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLoginSLOResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLoginSLOResponse) ProtoMessage() {}
+
+func (x *GetLoginSLOResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[85]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLoginSLOResponse.ProtoReflect.Descriptor instead.
+func (*GetLoginSLOResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{85}
+}
+
+func (x *GetLoginSLOResponse) GetWindowStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.WindowStart
+	}
+	return nil
+}
+
+func (x *GetLoginSLOResponse) GetWindowEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.WindowEnd
+	}
+	return nil
+}
+
+func (x *GetLoginSLOResponse) GetLoginSuccess() *ServiceLevelIndicator {
+	if x != nil {
+		return x.LoginSuccess
+	}
+	return nil
+}
+
+func (x *GetLoginSLOResponse) GetTokenIssuanceP99() *ServiceLevelIndicator {
+	if x != nil {
+		return x.TokenIssuanceP99
+	}
+	return nil
+}
+
+func (x *GetLoginSLOResponse) GetOauthExchangeSuccess() *ServiceLevelIndicator {
+	if x != nil {
+		return x.OauthExchangeSuccess
+	}
+	return nil
+}
+
+type ServiceLevelIndicator struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Value float64                `protobuf:"fixed64,1,opt,name=value,proto3" json:"value,omitempty"`
+	// The objective of value
+	Target float64 `protobuf:"fixed64,2,opt,name=target,proto3" json:"target,omitempty"`
+	// Events counted, and those that failed the objective
+	Total int64 `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	Bad   int64 `protobuf:"varint,4,opt,name=bad,proto3" json:"bad,omitempty"`
+	// Share of the error budget left in the window, negative once exhausted
+	ErrorBudgetRemaining float64 `protobuf:"fixed64,5,opt,name=error_budget_remaining,json=errorBudgetRemaining,proto3" json:"error_budget_remaining,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *ServiceLevelIndicator) Reset() {
+	*x = ServiceLevelIndicator{}
+	mi := &file_user_v1_user_proto_msgTypes[86]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceLevelIndicator) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceLevelIndicator) ProtoMessage() {}
+
+func (x *ServiceLevelIndicator) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[86]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceLevelIndicator.ProtoReflect.Descriptor instead.
+func (*ServiceLevelIndicator) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{86}
+}
+
+func (x *ServiceLevelIndicator) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *ServiceLevelIndicator) GetTarget() float64 {
+	if x != nil {
+		return x.Target
+	}
+	return 0
+}
+
+func (x *ServiceLevelIndicator) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ServiceLevelIndicator) GetBad() int64 {
+	if x != nil {
+		return x.Bad
+	}
+	return 0
+}
+
+func (x *ServiceLevelIndicator) GetErrorBudgetRemaining() float64 {
+	if x != nil {
+		return x.ErrorBudgetRemaining
+	}
+	return 0
+}
+
+type Report struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Kind   ReportKind             `protobuf:"varint,2,opt,name=kind,proto3,enum=user.v1.ReportKind" json:"kind,omitempty"`
+	Format ReportFormat           `protobuf:"varint,3,opt,name=format,proto3,enum=user.v1.ReportFormat" json:"format,omitempty"`
+	Status ReportStatus           `protobuf:"varint,4,opt,name=status,proto3,enum=user.v1.ReportStatus" json:"status,omitempty"`
+	// Days before the request covered by login activity and audit summaries
+	PeriodDays  int32                  `protobuf:"varint,5,opt,name=period_days,json=periodDays,proto3" json:"period_days,omitempty"`
+	RequestedBy string                 `protobuf:"bytes,6,opt,name=requested_by,json=requestedBy,proto3" json:"requested_by,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	CompletedAt *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=completed_at,json=completedAt,proto3,oneof" json:"completed_at,omitempty"`
+	SizeBytes   int64                  `protobuf:"varint,9,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	// Why the report failed
+	Error         string `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Report) Reset() {
+	*x = Report{}
+	mi := &file_user_v1_user_proto_msgTypes[87]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Report) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Report) ProtoMessage() {}
+
+func (x *Report) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[87]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Report.ProtoReflect.Descriptor instead.
+func (*Report) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{87}
+}
+
+func (x *Report) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Report) GetKind() ReportKind {
+	if x != nil {
+		return x.Kind
+	}
+	return ReportKind_REPORT_KIND_UNSPECIFIED
+}
+
+func (x *Report) GetFormat() ReportFormat {
+	if x != nil {
+		return x.Format
+	}
+	return ReportFormat_REPORT_FORMAT_UNSPECIFIED
+}
+
+func (x *Report) GetStatus() ReportStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ReportStatus_REPORT_STATUS_UNSPECIFIED
+}
+
+func (x *Report) GetPeriodDays() int32 {
+	if x != nil {
+		return x.PeriodDays
+	}
+	return 0
+}
+
+func (x *Report) GetRequestedBy() string {
+	if x != nil {
+		return x.RequestedBy
+	}
+	return ""
+}
+
+func (x *Report) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Report) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *Report) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *Report) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type CreateReportRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Kind  ReportKind             `protobuf:"varint,1,opt,name=kind,proto3,enum=user.v1.ReportKind" json:"kind,omitempty"`
+	// Defaults to CSV
+	Format ReportFormat `protobuf:"varint,2,opt,name=format,proto3,enum=user.v1.ReportFormat" json:"format,omitempty"`
+	// Defaults to 30, at most 366
+	PeriodDays    int32 `protobuf:"varint,3,opt,name=period_days,json=periodDays,proto3" json:"period_days,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateReportRequest) Reset() {
+	*x = CreateReportRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[88]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteUserResponse) String() string {
+func (x *CreateReportRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteUserResponse) ProtoMessage() {}
+func (*CreateReportRequest) ProtoMessage() {}
 
-func (x *DeleteUserResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_user_v1_user_proto_msgTypes[12]
+func (x *CreateReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[88]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -743,16 +5052,327 @@ func (x *DeleteUserResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteUserResponse.ProtoReflect.Descriptor instead.
-func (*DeleteUserResponse) Descriptor() ([]byte, []int) {
-	return file_user_v1_user_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use CreateReportRequest.ProtoReflect.Descriptor instead.
+func (*CreateReportRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{88}
+}
+
+func (x *CreateReportRequest) GetKind() ReportKind {
+	if x != nil {
+		return x.Kind
+	}
+	return ReportKind_REPORT_KIND_UNSPECIFIED
+}
+
+func (x *CreateReportRequest) GetFormat() ReportFormat {
+	if x != nil {
+		return x.Format
+	}
+	return ReportFormat_REPORT_FORMAT_UNSPECIFIED
+}
+
+func (x *CreateReportRequest) GetPeriodDays() int32 {
+	if x != nil {
+		return x.PeriodDays
+	}
+	return 0
+}
+
+type CreateReportResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Report        *Report                `protobuf:"bytes,1,opt,name=report,proto3" json:"report,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateReportResponse) Reset() {
+	*x = CreateReportResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[89]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateReportResponse) ProtoMessage() {}
+
+func (x *CreateReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[89]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateReportResponse.ProtoReflect.Descriptor instead.
+func (*CreateReportResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{89}
+}
+
+func (x *CreateReportResponse) GetReport() *Report {
+	if x != nil {
+		return x.Report
+	}
+	return nil
+}
+
+type ListReportsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Defaults to 50, at most 200
+	Limit         int32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReportsRequest) Reset() {
+	*x = ListReportsRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[90]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReportsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReportsRequest) ProtoMessage() {}
+
+func (x *ListReportsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[90]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReportsRequest.ProtoReflect.Descriptor instead.
+func (*ListReportsRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{90}
+}
+
+func (x *ListReportsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListReportsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reports       []*Report              `protobuf:"bytes,1,rep,name=reports,proto3" json:"reports,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReportsResponse) Reset() {
+	*x = ListReportsResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[91]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReportsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReportsResponse) ProtoMessage() {}
+
+func (x *ListReportsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[91]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReportsResponse.ProtoReflect.Descriptor instead.
+func (*ListReportsResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{91}
+}
+
+func (x *ListReportsResponse) GetReports() []*Report {
+	if x != nil {
+		return x.Reports
+	}
+	return nil
+}
+
+type DownloadReportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadReportRequest) Reset() {
+	*x = DownloadReportRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[92]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadReportRequest) ProtoMessage() {}
+
+func (x *DownloadReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[92]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadReportRequest.ProtoReflect.Descriptor instead.
+func (*DownloadReportRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{92}
+}
+
+func (x *DownloadReportRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DownloadReportResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadReportResponse) Reset() {
+	*x = DownloadReportResponse{}
+	mi := &file_user_v1_user_proto_msgTypes[93]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadReportResponse) ProtoMessage() {}
+
+func (x *DownloadReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[93]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadReportResponse.ProtoReflect.Descriptor instead.
+func (*DownloadReportResponse) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{93}
+}
+
+func (x *DownloadReportResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *DownloadReportResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type GetReportContentRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Unix time the signed URL expires at
+	Expires       int64  `protobuf:"varint,2,opt,name=expires,proto3" json:"expires,omitempty"`
+	Signature     string `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetReportContentRequest) Reset() {
+	*x = GetReportContentRequest{}
+	mi := &file_user_v1_user_proto_msgTypes[94]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReportContentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReportContentRequest) ProtoMessage() {}
+
+func (x *GetReportContentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_v1_user_proto_msgTypes[94]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReportContentRequest.ProtoReflect.Descriptor instead.
+func (*GetReportContentRequest) Descriptor() ([]byte, []int) {
+	return file_user_v1_user_proto_rawDescGZIP(), []int{94}
+}
+
+func (x *GetReportContentRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetReportContentRequest) GetExpires() int64 {
+	if x != nil {
+		return x.Expires
+	}
+	return 0
+}
+
+func (x *GetReportContentRequest) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
 }
 
 var File_user_v1_user_proto protoreflect.FileDescriptor
 
 const file_user_v1_user_proto_rawDesc = "" +
 	"\n" +
-	"\x12user/v1/user.proto\x12\auser.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8d\x02\n" +
+	"\x12user/v1/user.proto\x12\auser.v1\x1a\x16audit/v1/options.proto\x1a\x16authz/v1/options.proto\x1a\x1cgoogle/api/annotations.proto\x1a\x19google/api/httpbody.proto\x1a google/protobuf/field_mask.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x87\a\n" +
 	"\x04User\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
 	"\n" +
@@ -762,9 +5382,28 @@ const file_user_v1_user_proto_rawDesc = "" +
 	"\x04name\x18\x04 \x01(\tR\x04name\x12\x14\n" +
 	"\x05email\x18\x05 \x01(\tR\x05email\x12%\n" +
 	"\x04role\x18\x06 \x01(\x0e2\x11.user.v1.UserRoleR\x04role\x12 \n" +
-	"\tgithub_id\x18\a \x01(\tH\x00R\bgithubId\x88\x01\x01B\f\n" +
+	"\tgithub_id\x18\a \x01(\tH\x00R\bgithubId\x88\x01\x01\x12S\n" +
+	"\x15deletion_scheduled_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampH\x01R\x13deletionScheduledAt\x88\x01\x01\x120\n" +
+	"\x14must_change_password\x18\t \x01(\bR\x12mustChangePassword\x12)\n" +
+	"\x10pending_approval\x18\n" +
+	" \x01(\bR\x0fpendingApproval\x12\x10\n" +
+	"\x03org\x18\v \x01(\tR\x03org\x12\x18\n" +
+	"\aversion\x18\f \x01(\x03R\aversion\x12A\n" +
+	"\flast_seen_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampH\x02R\n" +
+	"lastSeenAt\x88\x01\x01\x12F\n" +
+	"\x0edeactivated_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampH\x03R\rdeactivatedAt\x88\x01\x01\x12&\n" +
+	"\fphone_number\x18\x0f \x01(\tH\x04R\vphoneNumber\x88\x01\x01\x127\n" +
+	"\bmetadata\x18\x10 \x03(\v2\x1b.user.v1.User.MetadataEntryR\bmetadata\x12\"\n" +
+	"\fentitlements\x18\x11 \x03(\tR\fentitlements\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\f\n" +
 	"\n" +
-	"_github_id\"\xc2\x01\n" +
+	"_github_idB\x18\n" +
+	"\x16_deletion_scheduled_atB\x0f\n" +
+	"\r_last_seen_atB\x11\n" +
+	"\x0f_deactivated_atB\x0f\n" +
+	"\r_phone_number\"\xc2\x01\n" +
 	"\x11CreateUserRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
 	"\x05email\x18\x02 \x01(\tR\x05email\x12%\n" +
@@ -781,44 +5420,389 @@ const file_user_v1_user_proto_rawDesc = "" +
 	"\x0eGetUserRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"4\n" +
 	"\x0fGetUserResponse\x12!\n" +
-	"\x04user\x18\x01 \x01(\v2\r.user.v1.UserR\x04user\"C\n" +
+	"\x04user\x18\x01 \x01(\v2\r.user.v1.UserR\x04user\"p\n" +
+	"\x18ListInactiveUsersRequest\x12#\n" +
+	"\rinactive_days\x18\x01 \x01(\rR\finactiveDays\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x04R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x04R\bpageSize\"V\n" +
+	"\x19ListInactiveUsersResponse\x12#\n" +
+	"\x05users\x18\x01 \x03(\v2\r.user.v1.UserR\x05users\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x04R\x05total\"-\n" +
+	"\x15GetUserByEmailRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\";\n" +
+	"\x16GetUserByEmailResponse\x12!\n" +
+	"\x04user\x18\x01 \x01(\v2\r.user.v1.UserR\x04user\"(\n" +
+	"\x14BatchGetUsersRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\"<\n" +
+	"\x15BatchGetUsersResponse\x12#\n" +
+	"\x05users\x18\x01 \x03(\v2\r.user.v1.UserR\x05users\"\xaf\x01\n" +
 	"\x10ListUsersRequest\x12\x12\n" +
 	"\x04page\x18\x01 \x01(\x04R\x04page\x12\x1b\n" +
-	"\tpage_size\x18\x02 \x01(\x04R\bpageSize\"N\n" +
+	"\tpage_size\x18\x02 \x01(\x04R\bpageSize\x12.\n" +
+	"\x10pending_approval\x18\x03 \x01(\bH\x00R\x0fpendingApproval\x88\x01\x01\x12%\n" +
+	"\x04view\x18\x04 \x01(\x0e2\x11.user.v1.UserViewR\x04viewB\x13\n" +
+	"\x11_pending_approval\"N\n" +
 	"\x11ListUsersResponse\x12#\n" +
 	"\x05users\x18\x01 \x03(\v2\r.user.v1.UserR\x05users\x12\x14\n" +
-	"\x05total\x18\x02 \x01(\x04R\x05total\"\xfd\x01\n" +
+	"\x05total\x18\x02 \x01(\x04R\x05total\"\x9f\x01\n" +
+	"\x12ExportUsersRequest\x12.\n" +
+	"\x10pending_approval\x18\x01 \x01(\bH\x00R\x0fpendingApproval\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"chunk_size\x18\x02 \x01(\rR\tchunkSize\x12%\n" +
+	"\x04view\x18\x03 \x01(\x0e2\x11.user.v1.UserViewR\x04viewB\x13\n" +
+	"\x11_pending_approval\":\n" +
+	"\x13ExportUsersResponse\x12#\n" +
+	"\x05users\x18\x01 \x03(\v2\r.user.v1.UserR\x05users\"@\n" +
+	"\x12SearchUsersRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\rR\x05limit\":\n" +
+	"\x13SearchUsersResponse\x12#\n" +
+	"\x05users\x18\x01 \x03(\v2\r.user.v1.UserR\x05users\"\xd2\x04\n" +
 	"\x11UpdateUserRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
 	"\x04name\x18\x02 \x01(\tH\x00R\x04name\x88\x01\x01\x12\x19\n" +
 	"\x05email\x18\x03 \x01(\tH\x01R\x05email\x88\x01\x01\x12*\n" +
 	"\x04role\x18\x04 \x01(\x0e2\x11.user.v1.UserRoleH\x02R\x04role\x88\x01\x01\x12\x1f\n" +
 	"\bpassword\x18\x05 \x01(\tH\x03R\bpassword\x88\x01\x01\x12 \n" +
-	"\tgithub_id\x18\x06 \x01(\tH\x04R\bgithubId\x88\x01\x01B\a\n" +
+	"\tgithub_id\x18\x06 \x01(\tH\x04R\bgithubId\x88\x01\x01\x125\n" +
+	"\x14must_change_password\x18\a \x01(\bH\x05R\x12mustChangePassword\x88\x01\x01\x12.\n" +
+	"\x10pending_approval\x18\b \x01(\bH\x06R\x0fpendingApproval\x88\x01\x01\x12\x15\n" +
+	"\x03org\x18\t \x01(\tH\aR\x03org\x88\x01\x01\x12\x1d\n" +
+	"\aversion\x18\n" +
+	" \x01(\x03H\bR\aversion\x88\x01\x01\x12;\n" +
+	"\vupdate_mask\x18\v \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\x12&\n" +
+	"\fphone_number\x18\f \x01(\tH\tR\vphoneNumber\x88\x01\x01B\a\n" +
 	"\x05_nameB\b\n" +
 	"\x06_emailB\a\n" +
 	"\x05_roleB\v\n" +
 	"\t_passwordB\f\n" +
 	"\n" +
-	"_github_id\"\x14\n" +
+	"_github_idB\x17\n" +
+	"\x15_must_change_passwordB\x13\n" +
+	"\x11_pending_approvalB\x06\n" +
+	"\x04_orgB\n" +
+	"\n" +
+	"\b_versionB\x0f\n" +
+	"\r_phone_number\"\x14\n" +
 	"\x12UpdateUserResponse\"#\n" +
 	"\x11DeleteUserRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"\x14\n" +
-	"\x12DeleteUserResponse*N\n" +
+	"\x12DeleteUserResponse\"\x19\n" +
+	"\x17ListMyIdentitiesRequest\"M\n" +
+	"\x18ListMyIdentitiesResponse\x121\n" +
+	"\n" +
+	"identities\x18\x01 \x03(\v2\x11.user.v1.IdentityR\n" +
+	"identities\"\x8d\x01\n" +
+	"\bIdentity\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12,\n" +
+	"\x12masked_provider_id\x18\x02 \x01(\tR\x10maskedProviderId\x127\n" +
+	"\tlinked_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\blinkedAt\"\x1f\n" +
+	"\x1dRequestAccountDeletionRequest\"_\n" +
+	"\x1eRequestAccountDeletionResponse\x12=\n" +
+	"\fscheduled_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\vscheduledAt\"\x1e\n" +
+	"\x1cCancelAccountDeletionRequest\"\x1f\n" +
+	"\x1dCancelAccountDeletionResponse\"8\n" +
+	"\x19RequestEmailChangeRequest\x12\x1b\n" +
+	"\tnew_email\x18\x01 \x01(\tR\bnewEmail\"W\n" +
+	"\x1aRequestEmailChangeResponse\x129\n" +
+	"\n" +
+	"expires_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"1\n" +
+	"\x19ConfirmEmailChangeRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\":\n" +
+	"\x1aConfirmEmailChangeResponse\x12\x1c\n" +
+	"\tcompleted\x18\x01 \x01(\bR\tcompleted\"2\n" +
+	"\x1aRollbackEmailChangeRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"\x1d\n" +
+	"\x1bRollbackEmailChangeResponse\"e\n" +
+	"\x15ChangePasswordRequest\x12)\n" +
+	"\x10current_password\x18\x01 \x01(\tR\x0fcurrentPassword\x12!\n" +
+	"\fnew_password\x18\x02 \x01(\tR\vnewPassword\"\x18\n" +
+	"\x16ChangePasswordResponse\"\x8c\x01\n" +
+	"\x17NotificationPreferences\x12'\n" +
+	"\x0fsecurity_emails\x18\x01 \x01(\bR\x0esecurityEmails\x12%\n" +
+	"\x0eproduct_emails\x18\x02 \x01(\bR\rproductEmails\x12!\n" +
+	"\flogin_alerts\x18\x03 \x01(\bR\vloginAlerts\"#\n" +
+	"!GetNotificationPreferencesRequest\"h\n" +
+	"\"GetNotificationPreferencesResponse\x12B\n" +
+	"\vpreferences\x18\x01 \x01(\v2 .user.v1.NotificationPreferencesR\vpreferences\"\xe0\x01\n" +
+	"$UpdateNotificationPreferencesRequest\x12,\n" +
+	"\x0fsecurity_emails\x18\x01 \x01(\bH\x00R\x0esecurityEmails\x88\x01\x01\x12*\n" +
+	"\x0eproduct_emails\x18\x02 \x01(\bH\x01R\rproductEmails\x88\x01\x01\x12&\n" +
+	"\flogin_alerts\x18\x03 \x01(\bH\x02R\vloginAlerts\x88\x01\x01B\x12\n" +
+	"\x10_security_emailsB\x11\n" +
+	"\x0f_product_emailsB\x0f\n" +
+	"\r_login_alerts\"k\n" +
+	"%UpdateNotificationPreferencesResponse\x12B\n" +
+	"\vpreferences\x18\x01 \x01(\v2 .user.v1.NotificationPreferencesR\vpreferences\"9\n" +
+	"\x1eAdminRevokeUserSessionsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\";\n" +
+	"\x1fAdminRevokeUserSessionsResponse\x12\x18\n" +
+	"\arevoked\x18\x01 \x01(\rR\arevoked\":\n" +
+	"\x19AdminRevokeSessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"\x1c\n" +
+	"\x1aAdminRevokeSessionResponse\"\x85\x03\n" +
+	"\x0eTenantSettings\x12\x10\n" +
+	"\x03org\x18\x01 \x01(\tR\x03org\x12\x19\n" +
+	"\blogo_url\x18\x02 \x01(\tR\alogoUrl\x12+\n" +
+	"\x11allowed_providers\x18\x03 \x03(\tR\x10allowedProviders\x12A\n" +
+	"\x1daccess_token_lifetime_seconds\x18\x04 \x01(\x03R\x1aaccessTokenLifetimeSeconds\x128\n" +
+	"\x18session_lifetime_seconds\x18\x05 \x01(\x03R\x16sessionLifetimeSeconds\x12.\n" +
+	"\x13password_min_length\x18\x06 \x01(\x05R\x11passwordMinLength\x12>\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampH\x00R\tupdatedAt\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"updated_by\x18\b \x01(\tR\tupdatedByB\r\n" +
+	"\v_updated_at\"\x1b\n" +
+	"\x19ListTenantSettingsRequest\"O\n" +
+	"\x1aListTenantSettingsResponse\x121\n" +
+	"\atenants\x18\x01 \x03(\v2\x17.user.v1.TenantSettingsR\atenants\",\n" +
+	"\x18GetTenantSettingsRequest\x12\x10\n" +
+	"\x03org\x18\x01 \x01(\tR\x03org\"P\n" +
+	"\x19GetTenantSettingsResponse\x123\n" +
+	"\bsettings\x18\x01 \x01(\v2\x17.user.v1.TenantSettingsR\bsettings\"'\n" +
+	"\x0fTenantProviders\x12\x14\n" +
+	"\x05names\x18\x01 \x03(\tR\x05names\"\xb6\x03\n" +
+	"\x1bUpdateTenantSettingsRequest\x12\x10\n" +
+	"\x03org\x18\x01 \x01(\tR\x03org\x12\x1e\n" +
+	"\blogo_url\x18\x02 \x01(\tH\x00R\alogoUrl\x88\x01\x01\x12E\n" +
+	"\x11allowed_providers\x18\x03 \x01(\v2\x18.user.v1.TenantProvidersR\x10allowedProviders\x12F\n" +
+	"\x1daccess_token_lifetime_seconds\x18\x04 \x01(\x03H\x01R\x1aaccessTokenLifetimeSeconds\x88\x01\x01\x12=\n" +
+	"\x18session_lifetime_seconds\x18\x05 \x01(\x03H\x02R\x16sessionLifetimeSeconds\x88\x01\x01\x123\n" +
+	"\x13password_min_length\x18\x06 \x01(\x05H\x03R\x11passwordMinLength\x88\x01\x01B\v\n" +
+	"\t_logo_urlB \n" +
+	"\x1e_access_token_lifetime_secondsB\x1b\n" +
+	"\x19_session_lifetime_secondsB\x16\n" +
+	"\x14_password_min_length\"S\n" +
+	"\x1cUpdateTenantSettingsResponse\x123\n" +
+	"\bsettings\x18\x01 \x01(\v2\x17.user.v1.TenantSettingsR\bsettings\"/\n" +
+	"\x1bDeleteTenantSettingsRequest\x12\x10\n" +
+	"\x03org\x18\x01 \x01(\tR\x03org\"\x1e\n" +
+	"\x1cDeleteTenantSettingsResponse\"0\n" +
+	"\x1cGetTenantPublicConfigRequest\x12\x10\n" +
+	"\x03org\x18\x01 \x01(\tR\x03org\"\xa5\x01\n" +
+	"\x1dGetTenantPublicConfigResponse\x12\x10\n" +
+	"\x03org\x18\x01 \x01(\tR\x03org\x12\x19\n" +
+	"\blogo_url\x18\x02 \x01(\tR\alogoUrl\x12'\n" +
+	"\x0foauth_providers\x18\x03 \x03(\tR\x0eoauthProviders\x12.\n" +
+	"\x13password_min_length\x18\x04 \x01(\x05R\x11passwordMinLength\".\n" +
+	"\x16AdminInviteUserRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"T\n" +
+	"\x17AdminInviteUserResponse\x129\n" +
+	"\n" +
+	"expires_at\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\";\n" +
+	"\vFeatureFlag\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aenabled\x18\x02 \x01(\bR\aenabled\"\x1e\n" +
+	"\x1cAdminListFeatureFlagsRequest\"K\n" +
+	"\x1dAdminListFeatureFlagsResponse\x12*\n" +
+	"\x05flags\x18\x01 \x03(\v2\x14.user.v1.FeatureFlagR\x05flags\"J\n" +
+	"\x1aAdminSetFeatureFlagRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aenabled\x18\x02 \x01(\bR\aenabled\"G\n" +
+	"\x1bAdminSetFeatureFlagResponse\x12(\n" +
+	"\x04flag\x18\x01 \x01(\v2\x14.user.v1.FeatureFlagR\x04flag\"\xbd\x01\n" +
+	"\tFailpoint\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04fail\x18\x02 \x01(\bR\x04fail\x121\n" +
+	"\x14latency_milliseconds\x18\x03 \x01(\rR\x13latencyMilliseconds\x12\x18\n" +
+	"\apercent\x18\x04 \x01(\rR\apercent\x12;\n" +
+	"\vexpire_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"expireTime\"\x1c\n" +
+	"\x1aAdminListFailpointsRequest\"Q\n" +
+	"\x1bAdminListFailpointsResponse\x122\n" +
+	"\n" +
+	"failpoints\x18\x01 \x03(\v2\x12.user.v1.FailpointR\n" +
+	"failpoints\"\xba\x01\n" +
+	"\x18AdminSetFailpointRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04fail\x18\x02 \x01(\bR\x04fail\x121\n" +
+	"\x14latency_milliseconds\x18\x03 \x01(\rR\x13latencyMilliseconds\x12\x18\n" +
+	"\apercent\x18\x04 \x01(\rR\apercent\x12)\n" +
+	"\x10duration_seconds\x18\x05 \x01(\rR\x0fdurationSeconds\"M\n" +
+	"\x19AdminSetFailpointResponse\x120\n" +
+	"\tfailpoint\x18\x01 \x01(\v2\x12.user.v1.FailpointR\tfailpoint\"\x1e\n" +
+	"\x1cAdminGetRuntimeConfigRequest\"\xe2\x01\n" +
+	"\x1dAdminGetRuntimeConfigResponse\x12/\n" +
+	"\x06config\x18\x01 \x01(\v2\x17.google.protobuf.StructR\x06config\x12\x12\n" +
+	"\x04mode\x18\x02 \x01(\tR\x04mode\x12!\n" +
+	"\fconfig_files\x18\x03 \x03(\tR\vconfigFiles\x12#\n" +
+	"\renv_overrides\x18\x04 \x03(\tR\fenvOverrides\x12\x1a\n" +
+	"\bhostname\x18\x05 \x01(\tR\bhostname\x12\x18\n" +
+	"\aversion\x18\x06 \x01(\tR\aversion\"\xe4\x01\n" +
+	"\n" +
+	"OAuthState\x12!\n" +
+	"\fstate_prefix\x18\x01 \x01(\tR\vstatePrefix\x12\x1a\n" +
+	"\bprovider\x18\x02 \x01(\tR\bprovider\x12!\n" +
+	"\fredirect_url\x18\x03 \x01(\tR\vredirectUrl\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"9\n" +
+	"\x1bAdminListOAuthStatesRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\"\xbb\x02\n" +
+	"\x1cAdminListOAuthStatesResponse\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\rR\x05total\x12f\n" +
+	"\x11count_by_provider\x18\x02 \x03(\v2:.user.v1.AdminListOAuthStatesResponse.CountByProviderEntryR\x0fcountByProvider\x12,\n" +
+	"\x12oldest_age_seconds\x18\x03 \x01(\rR\x10oldestAgeSeconds\x12+\n" +
+	"\x06states\x18\x04 \x03(\v2\x13.user.v1.OAuthStateR\x06states\x1aB\n" +
+	"\x14CountByProviderEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\rR\x05value:\x028\x01\"h\n" +
+	"\x1cAdminPurgeOAuthStatesRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12,\n" +
+	"\x12older_than_seconds\x18\x02 \x01(\rR\x10olderThanSeconds\"7\n" +
+	"\x1dAdminPurgeOAuthStatesResponse\x12\x16\n" +
+	"\x06purged\x18\x01 \x01(\rR\x06purged\"\x85\x01\n" +
+	"\x1eAdminSendSecurityNoticeRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x18\n" +
+	"\asubject\x18\x02 \x01(\tR\asubject\x12\x12\n" +
+	"\x04body\x18\x03 \x01(\tR\x04body\x12\x1c\n" +
+	"\tmandatory\x18\x04 \x01(\bR\tmandatory\"5\n" +
+	"\x1fAdminSendSecurityNoticeResponse\x12\x12\n" +
+	"\x04sent\x18\x01 \x01(\bR\x04sent\"g\n" +
+	"\"AdminUpdateUserEntitlementsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x10\n" +
+	"\x03add\x18\x02 \x03(\tR\x03add\x12\x16\n" +
+	"\x06remove\x18\x03 \x03(\tR\x06remove\"I\n" +
+	"#AdminUpdateUserEntitlementsResponse\x12\"\n" +
+	"\fentitlements\x18\x01 \x03(\tR\fentitlements\"&\n" +
+	"\x12GetKeyUsageRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"\xdb\x01\n" +
+	"\x13GetKeyUsageResponse\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x10\n" +
+	"\x03day\x18\x02 \x01(\tR\x03day\x12\x1f\n" +
+	"\vdaily_calls\x18\x03 \x01(\x03R\n" +
+	"dailyCalls\x12\x14\n" +
+	"\x05month\x18\x04 \x01(\tR\x05month\x12#\n" +
+	"\rmonthly_calls\x18\x05 \x01(\x03R\fmonthlyCalls\x12\x1f\n" +
+	"\vdaily_quota\x18\x06 \x01(\x03R\n" +
+	"dailyQuota\x12#\n" +
+	"\rmonthly_quota\x18\a \x01(\x03R\fmonthlyQuota\"\x14\n" +
+	"\x12GetLoginSLORequest\"\xf8\x02\n" +
+	"\x13GetLoginSLOResponse\x12=\n" +
+	"\fwindow_start\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\vwindowStart\x129\n" +
+	"\n" +
+	"window_end\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\twindowEnd\x12C\n" +
+	"\rlogin_success\x18\x03 \x01(\v2\x1e.user.v1.ServiceLevelIndicatorR\floginSuccess\x12L\n" +
+	"\x12token_issuance_p99\x18\x04 \x01(\v2\x1e.user.v1.ServiceLevelIndicatorR\x10tokenIssuanceP99\x12T\n" +
+	"\x16oauth_exchange_success\x18\x05 \x01(\v2\x1e.user.v1.ServiceLevelIndicatorR\x14oauthExchangeSuccess\"\xa3\x01\n" +
+	"\x15ServiceLevelIndicator\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\x01R\x05value\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\x01R\x06target\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x03R\x05total\x12\x10\n" +
+	"\x03bad\x18\x04 \x01(\x03R\x03bad\x124\n" +
+	"\x16error_budget_remaining\x18\x05 \x01(\x01R\x14errorBudgetRemaining\"\xa8\x03\n" +
+	"\x06Report\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12'\n" +
+	"\x04kind\x18\x02 \x01(\x0e2\x13.user.v1.ReportKindR\x04kind\x12-\n" +
+	"\x06format\x18\x03 \x01(\x0e2\x15.user.v1.ReportFormatR\x06format\x12-\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x15.user.v1.ReportStatusR\x06status\x12\x1f\n" +
+	"\vperiod_days\x18\x05 \x01(\x05R\n" +
+	"periodDays\x12!\n" +
+	"\frequested_by\x18\x06 \x01(\tR\vrequestedBy\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12B\n" +
+	"\fcompleted_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampH\x00R\vcompletedAt\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\t \x01(\x03R\tsizeBytes\x12\x14\n" +
+	"\x05error\x18\n" +
+	" \x01(\tR\x05errorB\x0f\n" +
+	"\r_completed_at\"\x8e\x01\n" +
+	"\x13CreateReportRequest\x12'\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x13.user.v1.ReportKindR\x04kind\x12-\n" +
+	"\x06format\x18\x02 \x01(\x0e2\x15.user.v1.ReportFormatR\x06format\x12\x1f\n" +
+	"\vperiod_days\x18\x03 \x01(\x05R\n" +
+	"periodDays\"?\n" +
+	"\x14CreateReportResponse\x12'\n" +
+	"\x06report\x18\x01 \x01(\v2\x0f.user.v1.ReportR\x06report\"*\n" +
+	"\x12ListReportsRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\"@\n" +
+	"\x13ListReportsResponse\x12)\n" +
+	"\areports\x18\x01 \x03(\v2\x0f.user.v1.ReportR\areports\"'\n" +
+	"\x15DownloadReportRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"e\n" +
+	"\x16DownloadReportResponse\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x129\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"a\n" +
+	"\x17GetReportContentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aexpires\x18\x02 \x01(\x03R\aexpires\x12\x1c\n" +
+	"\tsignature\x18\x03 \x01(\tR\tsignature*N\n" +
 	"\bUserRole\x12\x19\n" +
 	"\x15USER_ROLE_UNSPECIFIED\x10\x00\x12\x12\n" +
 	"\x0eUSER_ROLE_USER\x10\x01\x12\x13\n" +
-	"\x0fUSER_ROLE_ADMIN\x10\x022\xc1\x04\n" +
-	"\vUserService\x12[\n" +
+	"\x0fUSER_ROLE_ADMIN\x10\x02*M\n" +
+	"\bUserView\x12\x19\n" +
+	"\x15USER_VIEW_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eUSER_VIEW_FULL\x10\x01\x12\x12\n" +
+	"\x0eUSER_VIEW_LITE\x10\x02*\x85\x01\n" +
+	"\n" +
+	"ReportKind\x12\x1b\n" +
+	"\x17REPORT_KIND_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17REPORT_KIND_USER_ROSTER\x10\x01\x12\x1e\n" +
+	"\x1aREPORT_KIND_LOGIN_ACTIVITY\x10\x02\x12\x1d\n" +
+	"\x19REPORT_KIND_AUDIT_SUMMARY\x10\x03*[\n" +
+	"\fReportFormat\x12\x1d\n" +
+	"\x19REPORT_FORMAT_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11REPORT_FORMAT_CSV\x10\x01\x12\x15\n" +
+	"\x11REPORT_FORMAT_PDF\x10\x02*\x96\x01\n" +
+	"\fReportStatus\x12\x1d\n" +
+	"\x19REPORT_STATUS_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15REPORT_STATUS_PENDING\x10\x01\x12\x19\n" +
+	"\x15REPORT_STATUS_RUNNING\x10\x02\x12\x17\n" +
+	"\x13REPORT_STATUS_READY\x10\x03\x12\x18\n" +
+	"\x14REPORT_STATUS_FAILED\x10\x042\xb0'\n" +
+	"\vUserService\x12i\n" +
 	"\n" +
-	"CreateUser\x12\x1a.user.v1.CreateUserRequest\x1a\x1b.user.v1.CreateUserResponse\"\x14\x82\xd3\xe4\x93\x02\x0e:\x01*\"\t/v1/users\x12g\n" +
-	"\x0eGetCurrentUser\x12\x1e.user.v1.GetCurrentUserRequest\x1a\x1f.user.v1.GetCurrentUserResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/users/me\x12T\n" +
-	"\aGetUser\x12\x17.user.v1.GetUserRequest\x1a\x18.user.v1.GetUserResponse\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/v1/users/{id}\x12U\n" +
-	"\tListUsers\x12\x19.user.v1.ListUsersRequest\x1a\x1a.user.v1.ListUsersResponse\"\x11\x82\xd3\xe4\x93\x02\v\x12\t/v1/users\x12`\n" +
+	"CreateUser\x12\x1a.user.v1.CreateUserRequest\x1a\x1b.user.v1.CreateUserResponse\"\"\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x03\x82\xd3\xe4\x93\x02\x0e:\x01*\"\t/v1/users\x12m\n" +
+	"\x0eGetCurrentUser\x12\x1e.user.v1.GetCurrentUserRequest\x1a\x1f.user.v1.GetCurrentUserResponse\"\x1a\xc2\xf3\x18\x02\b\x02\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/users/me\x12Z\n" +
+	"\aGetUser\x12\x17.user.v1.GetUserRequest\x1a\x18.user.v1.GetUserResponse\"\x1c\xc2\xf3\x18\x02\b\x02\x82\xd3\xe4\x93\x02\x10\x12\x0e/v1/users/{id}\x12r\n" +
+	"\x0eGetUserByEmail\x12\x1e.user.v1.GetUserByEmailRequest\x1a\x1f.user.v1.GetUserByEmailResponse\"\x1f\xc2\xf3\x18\x02\b\x03\x82\xd3\xe4\x93\x02\x13\x12\x11/v1/users:byEmail\x12p\n" +
+	"\rBatchGetUsers\x12\x1d.user.v1.BatchGetUsersRequest\x1a\x1e.user.v1.BatchGetUsersResponse\" \xc2\xf3\x18\x02\b\x03\x82\xd3\xe4\x93\x02\x14\x12\x12/v1/users:batchGet\x12c\n" +
+	"\tListUsers\x12\x19.user.v1.ListUsersRequest\x1a\x1a.user.v1.ListUsersResponse\"\x1f\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x02\x82\xd3\xe4\x93\x02\v\x12\t/v1/users\x12h\n" +
+	"\vSearchUsers\x12\x1b.user.v1.SearchUsersRequest\x1a\x1c.user.v1.SearchUsersResponse\"\x1e\xc2\xf3\x18\x02\b\x03\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/users:search\x12\x84\x01\n" +
+	"\x11ListInactiveUsers\x12!.user.v1.ListInactiveUsersRequest\x1a\".user.v1.ListInactiveUsersResponse\"(\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x02\x82\xd3\xe4\x93\x02\x14\x12\x12/v1/users:inactive\x12r\n" +
+	"\vExportUsers\x12\x1b.user.v1.ExportUsersRequest\x1a\x1c.user.v1.ExportUsersResponse\"&\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x03\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/users:export0\x01\x12\x83\x01\n" +
 	"\n" +
-	"UpdateUser\x12\x1a.user.v1.UpdateUserRequest\x1a\x1b.user.v1.UpdateUserResponse\"\x19\x82\xd3\xe4\x93\x02\x13:\x01*2\x0e/v1/users/{id}\x12]\n" +
+	"UpdateUser\x12\x1a.user.v1.UpdateUserRequest\x1a\x1b.user.v1.UpdateUserResponse\"<\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x03\x82\xd3\xe4\x93\x02(:\x01*Z\x13:\x01*\x1a\x0e/v1/users/{id}2\x0e/v1/users/{id}\x12k\n" +
 	"\n" +
-	"DeleteUser\x12\x1a.user.v1.DeleteUserRequest\x1a\x1b.user.v1.DeleteUserResponse\"\x16\x82\xd3\xe4\x93\x02\x10*\x0e/v1/users/{id}B=Z;github.com/poly-workshop/auth-portal/gen/user/v1;user_v1_pbb\x06proto3"
+	"DeleteUser\x12\x1a.user.v1.DeleteUserRequest\x1a\x1b.user.v1.DeleteUserResponse\"$\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x03\x82\xd3\xe4\x93\x02\x10*\x0e/v1/users/{id}\x12~\n" +
+	"\x10ListMyIdentities\x12 .user.v1.ListMyIdentitiesRequest\x1a!.user.v1.ListMyIdentitiesResponse\"%\xc2\xf3\x18\x02\b\x02\x82\xd3\xe4\x93\x02\x19\x12\x17/v1/users/me/identities\x12\x91\x01\n" +
+	"\x16RequestAccountDeletion\x12&.user.v1.RequestAccountDeletionRequest\x1a'.user.v1.RequestAccountDeletionResponse\"&\xc2\xf3\x18\x02\b\x02\x82\xd3\xe4\x93\x02\x1a:\x01*\"\x15/v1/users/me/deletion\x12\x8b\x01\n" +
+	"\x15CancelAccountDeletion\x12%.user.v1.CancelAccountDeletionRequest\x1a&.user.v1.CancelAccountDeletionResponse\"#\xc2\xf3\x18\x02\b\x02\x82\xd3\xe4\x93\x02\x17*\x15/v1/users/me/deletion\x12\x89\x01\n" +
+	"\x12RequestEmailChange\x12\".user.v1.RequestEmailChangeRequest\x1a#.user.v1.RequestEmailChangeResponse\"*\xc2\xf3\x18\x02\b\x02\x82\xd3\xe4\x93\x02\x1e:\x01*\"\x19/v1/users/me/email-change\x12\x88\x01\n" +
+	"\x12ConfirmEmailChange\x12\".user.v1.ConfirmEmailChangeRequest\x1a#.user.v1.ConfirmEmailChangeResponse\")\xc2\xf3\x18\x02\b\x01\x82\xd3\xe4\x93\x02\x1d:\x01*\"\x18/v1/email-change/confirm\x12\x8c\x01\n" +
+	"\x13RollbackEmailChange\x12#.user.v1.RollbackEmailChangeRequest\x1a$.user.v1.RollbackEmailChangeResponse\"*\xc2\xf3\x18\x02\b\x01\x82\xd3\xe4\x93\x02\x1e:\x01*\"\x19/v1/email-change/rollback\x12y\n" +
+	"\x0eChangePassword\x12\x1e.user.v1.ChangePasswordRequest\x1a\x1f.user.v1.ChangePasswordResponse\"&\xc2\xf3\x18\x02\b\x02\x82\xd3\xe4\x93\x02\x1a:\x01*\"\x15/v1/users/me/password\x12\xaa\x01\n" +
+	"\x1aGetNotificationPreferences\x12*.user.v1.GetNotificationPreferencesRequest\x1a+.user.v1.GetNotificationPreferencesResponse\"3\xc2\xf3\x18\x02\b\x02\x82\xd3\xe4\x93\x02'\x12%/v1/users/me/notification-preferences\x12\xb6\x01\n" +
+	"\x1dUpdateNotificationPreferences\x12-.user.v1.UpdateNotificationPreferencesRequest\x1a..user.v1.UpdateNotificationPreferencesResponse\"6\xc2\xf3\x18\x02\b\x02\x82\xd3\xe4\x93\x02*:\x01*2%/v1/users/me/notification-preferences\x12\xa0\x01\n" +
+	"\x17AdminRevokeUserSessions\x12'.user.v1.AdminRevokeUserSessionsRequest\x1a(.user.v1.AdminRevokeUserSessionsResponse\"2\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x03\x82\xd3\xe4\x93\x02\x1e*\x1c/v1/users/{user_id}/sessions\x12\x8e\x01\n" +
+	"\x12AdminRevokeSession\x12\".user.v1.AdminRevokeSessionRequest\x1a#.user.v1.AdminRevokeSessionResponse\"/\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x03\x82\xd3\xe4\x93\x02\x1b*\x19/v1/sessions/{session_id}\x12z\n" +
+	"\x0fAdminInviteUser\x12\x1f.user.v1.AdminInviteUserRequest\x1a .user.v1.AdminInviteUserResponse\"$\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x02\x82\xd3\xe4\x93\x02\x10:\x01*\"\v/v1/invites\x12\x8f\x01\n" +
+	"\x15AdminListFeatureFlags\x12%.user.v1.AdminListFeatureFlagsRequest\x1a&.user.v1.AdminListFeatureFlagsResponse\"'\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x01\x82\xd3\xe4\x93\x02\x13\x12\x11/v1/feature-flags\x12\x93\x01\n" +
+	"\x13AdminSetFeatureFlag\x12#.user.v1.AdminSetFeatureFlagRequest\x1a$.user.v1.AdminSetFeatureFlagResponse\"1\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x03\x82\xd3\xe4\x93\x02\x1d:\x01*\x1a\x18/v1/feature-flags/{name}\x12~\n" +
+	"\x13AdminListFailpoints\x12#.user.v1.AdminListFailpointsRequest\x1a$.user.v1.AdminListFailpointsResponse\"\x1c\xc2\xf3\x18\x02\b\x03\x82\xd3\xe4\x93\x02\x10\x12\x0e/v1/failpoints\x12\x8a\x01\n" +
+	"\x11AdminSetFailpoint\x12!.user.v1.AdminSetFailpointRequest\x1a\".user.v1.AdminSetFailpointResponse\".\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x03\x82\xd3\xe4\x93\x02\x1a:\x01*\x1a\x15/v1/failpoints/{name}\x12\x90\x01\n" +
+	"\x15AdminGetRuntimeConfig\x12%.user.v1.AdminGetRuntimeConfigRequest\x1a&.user.v1.AdminGetRuntimeConfigResponse\"(\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x02\x82\xd3\xe4\x93\x02\x14\x12\x12/v1/runtime-config\x12\x8b\x01\n" +
+	"\x14AdminListOAuthStates\x12$.user.v1.AdminListOAuthStatesRequest\x1a%.user.v1.AdminListOAuthStatesResponse\"&\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x01\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/oauth-states\x12\x8e\x01\n" +
+	"\x15AdminPurgeOAuthStates\x12%.user.v1.AdminPurgeOAuthStatesRequest\x1a&.user.v1.AdminPurgeOAuthStatesResponse\"&\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x03\x82\xd3\xe4\x93\x02\x12*\x10/v1/oauth-states\x12\xab\x01\n" +
+	"\x17AdminSendSecurityNotice\x12'.user.v1.AdminSendSecurityNoticeRequest\x1a(.user.v1.AdminSendSecurityNoticeResponse\"=\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x02\x82\xd3\xe4\x93\x02):\x01*\"$/v1/users/{user_id}/security-notices\x12\xb3\x01\n" +
+	"\x1bAdminUpdateUserEntitlements\x12+.user.v1.AdminUpdateUserEntitlementsRequest\x1a,.user.v1.AdminUpdateUserEntitlementsResponse\"9\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x03\x82\xd3\xe4\x93\x02%:\x01*2 /v1/users/{user_id}/entitlements\x12l\n" +
+	"\vGetKeyUsage\x12\x1b.user.v1.GetKeyUsageRequest\x1a\x1c.user.v1.GetKeyUsageResponse\"\"\xc2\xf3\x18\x02\b\x03\x82\xd3\xe4\x93\x02\x16\x12\x14/v1/keys/{key}/usage\x12e\n" +
+	"\vGetLoginSLO\x12\x1b.user.v1.GetLoginSLORequest\x1a\x1c.user.v1.GetLoginSLOResponse\"\x1b\xc2\xf3\x18\x02\b\x03\x82\xd3\xe4\x93\x02\x0f\x12\r/v1/slo/login\x12q\n" +
+	"\fCreateReport\x12\x1c.user.v1.CreateReportRequest\x1a\x1d.user.v1.CreateReportResponse\"$\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x02\x82\xd3\xe4\x93\x02\x10:\x01*\"\v/v1/reports\x12c\n" +
+	"\vListReports\x12\x1b.user.v1.ListReportsRequest\x1a\x1c.user.v1.ListReportsResponse\"\x19\xc2\xf3\x18\x02\b\x03\x82\xd3\xe4\x93\x02\r\x12\v/v1/reports\x12\x82\x01\n" +
+	"\x0eDownloadReport\x12\x1e.user.v1.DownloadReportRequest\x1a\x1f.user.v1.DownloadReportResponse\"/\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x03\x82\xd3\xe4\x93\x02\x1b\x12\x19/v1/reports/{id}/download\x12r\n" +
+	"\x10GetReportContent\x12 .user.v1.GetReportContentRequest\x1a\x14.google.api.HttpBody\"&\xc2\xf3\x18\x02\b\x01\x82\xd3\xe4\x93\x02\x1a\x12\x18/v1/reports/{id}/content2\xdc\x05\n" +
+	"\x15TenantSettingsService\x12x\n" +
+	"\x12ListTenantSettings\x12\".user.v1.ListTenantSettingsRequest\x1a#.user.v1.ListTenantSettingsResponse\"\x19\xc2\xf3\x18\x02\b\x03\x82\xd3\xe4\x93\x02\r\x12\v/v1/tenants\x12\x84\x01\n" +
+	"\x11GetTenantSettings\x12!.user.v1.GetTenantSettingsRequest\x1a\".user.v1.GetTenantSettingsResponse\"(\xc2\xf3\x18\x02\b\x03\x82\xd3\xe4\x93\x02\x1c\x12\x1a/v1/tenants/{org}/settings\x12\x98\x01\n" +
+	"\x14UpdateTenantSettings\x12$.user.v1.UpdateTenantSettingsRequest\x1a%.user.v1.UpdateTenantSettingsResponse\"3\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x03\x82\xd3\xe4\x93\x02\x1f:\x01*2\x1a/v1/tenants/{org}/settings\x12\x95\x01\n" +
+	"\x14DeleteTenantSettings\x12$.user.v1.DeleteTenantSettingsRequest\x1a%.user.v1.DeleteTenantSettingsResponse\"0\xc2\xf3\x18\x02\b\x03\xca\xf3\x18\x04\b\x01\x10\x03\x82\xd3\xe4\x93\x02\x1c*\x1a/v1/tenants/{org}/settings\x12\x8e\x01\n" +
+	"\x15GetTenantPublicConfig\x12%.user.v1.GetTenantPublicConfigRequest\x1a&.user.v1.GetTenantPublicConfigResponse\"&\xc2\xf3\x18\x02\b\x01\x82\xd3\xe4\x93\x02\x1a\x12\x18/v1/tenants/{org}/configB=Z;github.com/poly-workshop/auth-portal/gen/user/v1;user_v1_pbb\x06proto3"
 
 var (
 	file_user_v1_user_proto_rawDescOnce sync.Once
@@ -832,51 +5816,265 @@ func file_user_v1_user_proto_rawDescGZIP() []byte {
 	return file_user_v1_user_proto_rawDescData
 }
 
-var file_user_v1_user_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_user_v1_user_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_user_v1_user_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_user_v1_user_proto_msgTypes = make([]protoimpl.MessageInfo, 97)
 var file_user_v1_user_proto_goTypes = []any{
-	(UserRole)(0),                  // 0: user.v1.UserRole
-	(*User)(nil),                   // 1: user.v1.User
-	(*CreateUserRequest)(nil),      // 2: user.v1.CreateUserRequest
-	(*CreateUserResponse)(nil),     // 3: user.v1.CreateUserResponse
-	(*GetCurrentUserRequest)(nil),  // 4: user.v1.GetCurrentUserRequest
-	(*GetCurrentUserResponse)(nil), // 5: user.v1.GetCurrentUserResponse
-	(*GetUserRequest)(nil),         // 6: user.v1.GetUserRequest
-	(*GetUserResponse)(nil),        // 7: user.v1.GetUserResponse
-	(*ListUsersRequest)(nil),       // 8: user.v1.ListUsersRequest
-	(*ListUsersResponse)(nil),      // 9: user.v1.ListUsersResponse
-	(*UpdateUserRequest)(nil),      // 10: user.v1.UpdateUserRequest
-	(*UpdateUserResponse)(nil),     // 11: user.v1.UpdateUserResponse
-	(*DeleteUserRequest)(nil),      // 12: user.v1.DeleteUserRequest
-	(*DeleteUserResponse)(nil),     // 13: user.v1.DeleteUserResponse
-	(*timestamppb.Timestamp)(nil),  // 14: google.protobuf.Timestamp
+	(UserRole)(0),                                 // 0: user.v1.UserRole
+	(UserView)(0),                                 // 1: user.v1.UserView
+	(ReportKind)(0),                               // 2: user.v1.ReportKind
+	(ReportFormat)(0),                             // 3: user.v1.ReportFormat
+	(ReportStatus)(0),                             // 4: user.v1.ReportStatus
+	(*User)(nil),                                  // 5: user.v1.User
+	(*CreateUserRequest)(nil),                     // 6: user.v1.CreateUserRequest
+	(*CreateUserResponse)(nil),                    // 7: user.v1.CreateUserResponse
+	(*GetCurrentUserRequest)(nil),                 // 8: user.v1.GetCurrentUserRequest
+	(*GetCurrentUserResponse)(nil),                // 9: user.v1.GetCurrentUserResponse
+	(*GetUserRequest)(nil),                        // 10: user.v1.GetUserRequest
+	(*GetUserResponse)(nil),                       // 11: user.v1.GetUserResponse
+	(*ListInactiveUsersRequest)(nil),              // 12: user.v1.ListInactiveUsersRequest
+	(*ListInactiveUsersResponse)(nil),             // 13: user.v1.ListInactiveUsersResponse
+	(*GetUserByEmailRequest)(nil),                 // 14: user.v1.GetUserByEmailRequest
+	(*GetUserByEmailResponse)(nil),                // 15: user.v1.GetUserByEmailResponse
+	(*BatchGetUsersRequest)(nil),                  // 16: user.v1.BatchGetUsersRequest
+	(*BatchGetUsersResponse)(nil),                 // 17: user.v1.BatchGetUsersResponse
+	(*ListUsersRequest)(nil),                      // 18: user.v1.ListUsersRequest
+	(*ListUsersResponse)(nil),                     // 19: user.v1.ListUsersResponse
+	(*ExportUsersRequest)(nil),                    // 20: user.v1.ExportUsersRequest
+	(*ExportUsersResponse)(nil),                   // 21: user.v1.ExportUsersResponse
+	(*SearchUsersRequest)(nil),                    // 22: user.v1.SearchUsersRequest
+	(*SearchUsersResponse)(nil),                   // 23: user.v1.SearchUsersResponse
+	(*UpdateUserRequest)(nil),                     // 24: user.v1.UpdateUserRequest
+	(*UpdateUserResponse)(nil),                    // 25: user.v1.UpdateUserResponse
+	(*DeleteUserRequest)(nil),                     // 26: user.v1.DeleteUserRequest
+	(*DeleteUserResponse)(nil),                    // 27: user.v1.DeleteUserResponse
+	(*ListMyIdentitiesRequest)(nil),               // 28: user.v1.ListMyIdentitiesRequest
+	(*ListMyIdentitiesResponse)(nil),              // 29: user.v1.ListMyIdentitiesResponse
+	(*Identity)(nil),                              // 30: user.v1.Identity
+	(*RequestAccountDeletionRequest)(nil),         // 31: user.v1.RequestAccountDeletionRequest
+	(*RequestAccountDeletionResponse)(nil),        // 32: user.v1.RequestAccountDeletionResponse
+	(*CancelAccountDeletionRequest)(nil),          // 33: user.v1.CancelAccountDeletionRequest
+	(*CancelAccountDeletionResponse)(nil),         // 34: user.v1.CancelAccountDeletionResponse
+	(*RequestEmailChangeRequest)(nil),             // 35: user.v1.RequestEmailChangeRequest
+	(*RequestEmailChangeResponse)(nil),            // 36: user.v1.RequestEmailChangeResponse
+	(*ConfirmEmailChangeRequest)(nil),             // 37: user.v1.ConfirmEmailChangeRequest
+	(*ConfirmEmailChangeResponse)(nil),            // 38: user.v1.ConfirmEmailChangeResponse
+	(*RollbackEmailChangeRequest)(nil),            // 39: user.v1.RollbackEmailChangeRequest
+	(*RollbackEmailChangeResponse)(nil),           // 40: user.v1.RollbackEmailChangeResponse
+	(*ChangePasswordRequest)(nil),                 // 41: user.v1.ChangePasswordRequest
+	(*ChangePasswordResponse)(nil),                // 42: user.v1.ChangePasswordResponse
+	(*NotificationPreferences)(nil),               // 43: user.v1.NotificationPreferences
+	(*GetNotificationPreferencesRequest)(nil),     // 44: user.v1.GetNotificationPreferencesRequest
+	(*GetNotificationPreferencesResponse)(nil),    // 45: user.v1.GetNotificationPreferencesResponse
+	(*UpdateNotificationPreferencesRequest)(nil),  // 46: user.v1.UpdateNotificationPreferencesRequest
+	(*UpdateNotificationPreferencesResponse)(nil), // 47: user.v1.UpdateNotificationPreferencesResponse
+	(*AdminRevokeUserSessionsRequest)(nil),        // 48: user.v1.AdminRevokeUserSessionsRequest
+	(*AdminRevokeUserSessionsResponse)(nil),       // 49: user.v1.AdminRevokeUserSessionsResponse
+	(*AdminRevokeSessionRequest)(nil),             // 50: user.v1.AdminRevokeSessionRequest
+	(*AdminRevokeSessionResponse)(nil),            // 51: user.v1.AdminRevokeSessionResponse
+	(*TenantSettings)(nil),                        // 52: user.v1.TenantSettings
+	(*ListTenantSettingsRequest)(nil),             // 53: user.v1.ListTenantSettingsRequest
+	(*ListTenantSettingsResponse)(nil),            // 54: user.v1.ListTenantSettingsResponse
+	(*GetTenantSettingsRequest)(nil),              // 55: user.v1.GetTenantSettingsRequest
+	(*GetTenantSettingsResponse)(nil),             // 56: user.v1.GetTenantSettingsResponse
+	(*TenantProviders)(nil),                       // 57: user.v1.TenantProviders
+	(*UpdateTenantSettingsRequest)(nil),           // 58: user.v1.UpdateTenantSettingsRequest
+	(*UpdateTenantSettingsResponse)(nil),          // 59: user.v1.UpdateTenantSettingsResponse
+	(*DeleteTenantSettingsRequest)(nil),           // 60: user.v1.DeleteTenantSettingsRequest
+	(*DeleteTenantSettingsResponse)(nil),          // 61: user.v1.DeleteTenantSettingsResponse
+	(*GetTenantPublicConfigRequest)(nil),          // 62: user.v1.GetTenantPublicConfigRequest
+	(*GetTenantPublicConfigResponse)(nil),         // 63: user.v1.GetTenantPublicConfigResponse
+	(*AdminInviteUserRequest)(nil),                // 64: user.v1.AdminInviteUserRequest
+	(*AdminInviteUserResponse)(nil),               // 65: user.v1.AdminInviteUserResponse
+	(*FeatureFlag)(nil),                           // 66: user.v1.FeatureFlag
+	(*AdminListFeatureFlagsRequest)(nil),          // 67: user.v1.AdminListFeatureFlagsRequest
+	(*AdminListFeatureFlagsResponse)(nil),         // 68: user.v1.AdminListFeatureFlagsResponse
+	(*AdminSetFeatureFlagRequest)(nil),            // 69: user.v1.AdminSetFeatureFlagRequest
+	(*AdminSetFeatureFlagResponse)(nil),           // 70: user.v1.AdminSetFeatureFlagResponse
+	(*Failpoint)(nil),                             // 71: user.v1.Failpoint
+	(*AdminListFailpointsRequest)(nil),            // 72: user.v1.AdminListFailpointsRequest
+	(*AdminListFailpointsResponse)(nil),           // 73: user.v1.AdminListFailpointsResponse
+	(*AdminSetFailpointRequest)(nil),              // 74: user.v1.AdminSetFailpointRequest
+	(*AdminSetFailpointResponse)(nil),             // 75: user.v1.AdminSetFailpointResponse
+	(*AdminGetRuntimeConfigRequest)(nil),          // 76: user.v1.AdminGetRuntimeConfigRequest
+	(*AdminGetRuntimeConfigResponse)(nil),         // 77: user.v1.AdminGetRuntimeConfigResponse
+	(*OAuthState)(nil),                            // 78: user.v1.OAuthState
+	(*AdminListOAuthStatesRequest)(nil),           // 79: user.v1.AdminListOAuthStatesRequest
+	(*AdminListOAuthStatesResponse)(nil),          // 80: user.v1.AdminListOAuthStatesResponse
+	(*AdminPurgeOAuthStatesRequest)(nil),          // 81: user.v1.AdminPurgeOAuthStatesRequest
+	(*AdminPurgeOAuthStatesResponse)(nil),         // 82: user.v1.AdminPurgeOAuthStatesResponse
+	(*AdminSendSecurityNoticeRequest)(nil),        // 83: user.v1.AdminSendSecurityNoticeRequest
+	(*AdminSendSecurityNoticeResponse)(nil),       // 84: user.v1.AdminSendSecurityNoticeResponse
+	(*AdminUpdateUserEntitlementsRequest)(nil),    // 85: user.v1.AdminUpdateUserEntitlementsRequest
+	(*AdminUpdateUserEntitlementsResponse)(nil),   // 86: user.v1.AdminUpdateUserEntitlementsResponse
+	(*GetKeyUsageRequest)(nil),                    // 87: user.v1.GetKeyUsageRequest
+	(*GetKeyUsageResponse)(nil),                   // 88: user.v1.GetKeyUsageResponse
+	(*GetLoginSLORequest)(nil),                    // 89: user.v1.GetLoginSLORequest
+	(*GetLoginSLOResponse)(nil),                   // 90: user.v1.GetLoginSLOResponse
+	(*ServiceLevelIndicator)(nil),                 // 91: user.v1.ServiceLevelIndicator
+	(*Report)(nil),                                // 92: user.v1.Report
+	(*CreateReportRequest)(nil),                   // 93: user.v1.CreateReportRequest
+	(*CreateReportResponse)(nil),                  // 94: user.v1.CreateReportResponse
+	(*ListReportsRequest)(nil),                    // 95: user.v1.ListReportsRequest
+	(*ListReportsResponse)(nil),                   // 96: user.v1.ListReportsResponse
+	(*DownloadReportRequest)(nil),                 // 97: user.v1.DownloadReportRequest
+	(*DownloadReportResponse)(nil),                // 98: user.v1.DownloadReportResponse
+	(*GetReportContentRequest)(nil),               // 99: user.v1.GetReportContentRequest
+	nil,                                           // 100: user.v1.User.MetadataEntry
+	nil,                                           // 101: user.v1.AdminListOAuthStatesResponse.CountByProviderEntry
+	(*timestamppb.Timestamp)(nil),                 // 102: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil),                 // 103: google.protobuf.FieldMask
+	(*structpb.Struct)(nil),                       // 104: google.protobuf.Struct
+	(*httpbody.HttpBody)(nil),                     // 105: google.api.HttpBody
 }
 var file_user_v1_user_proto_depIdxs = []int32{
-	14, // 0: user.v1.User.created_at:type_name -> google.protobuf.Timestamp
-	14, // 1: user.v1.User.updated_at:type_name -> google.protobuf.Timestamp
-	0,  // 2: user.v1.User.role:type_name -> user.v1.UserRole
-	0,  // 3: user.v1.CreateUserRequest.role:type_name -> user.v1.UserRole
-	1,  // 4: user.v1.GetCurrentUserResponse.user:type_name -> user.v1.User
-	1,  // 5: user.v1.GetUserResponse.user:type_name -> user.v1.User
-	1,  // 6: user.v1.ListUsersResponse.users:type_name -> user.v1.User
-	0,  // 7: user.v1.UpdateUserRequest.role:type_name -> user.v1.UserRole
-	2,  // 8: user.v1.UserService.CreateUser:input_type -> user.v1.CreateUserRequest
-	4,  // 9: user.v1.UserService.GetCurrentUser:input_type -> user.v1.GetCurrentUserRequest
-	6,  // 10: user.v1.UserService.GetUser:input_type -> user.v1.GetUserRequest
-	8,  // 11: user.v1.UserService.ListUsers:input_type -> user.v1.ListUsersRequest
-	10, // 12: user.v1.UserService.UpdateUser:input_type -> user.v1.UpdateUserRequest
-	12, // 13: user.v1.UserService.DeleteUser:input_type -> user.v1.DeleteUserRequest
-	3,  // 14: user.v1.UserService.CreateUser:output_type -> user.v1.CreateUserResponse
-	5,  // 15: user.v1.UserService.GetCurrentUser:output_type -> user.v1.GetCurrentUserResponse
-	7,  // 16: user.v1.UserService.GetUser:output_type -> user.v1.GetUserResponse
-	9,  // 17: user.v1.UserService.ListUsers:output_type -> user.v1.ListUsersResponse
-	11, // 18: user.v1.UserService.UpdateUser:output_type -> user.v1.UpdateUserResponse
-	13, // 19: user.v1.UserService.DeleteUser:output_type -> user.v1.DeleteUserResponse
-	14, // [14:20] is the sub-list for method output_type
-	8,  // [8:14] is the sub-list for method input_type
-	8,  // [8:8] is the sub-list for extension type_name
-	8,  // [8:8] is the sub-list for extension extendee
-	0,  // [0:8] is the sub-list for field type_name
+	102, // 0: user.v1.User.created_at:type_name -> google.protobuf.Timestamp
+	102, // 1: user.v1.User.updated_at:type_name -> google.protobuf.Timestamp
+	0,   // 2: user.v1.User.role:type_name -> user.v1.UserRole
+	102, // 3: user.v1.User.deletion_scheduled_at:type_name -> google.protobuf.Timestamp
+	102, // 4: user.v1.User.last_seen_at:type_name -> google.protobuf.Timestamp
+	102, // 5: user.v1.User.deactivated_at:type_name -> google.protobuf.Timestamp
+	100, // 6: user.v1.User.metadata:type_name -> user.v1.User.MetadataEntry
+	0,   // 7: user.v1.CreateUserRequest.role:type_name -> user.v1.UserRole
+	5,   // 8: user.v1.GetCurrentUserResponse.user:type_name -> user.v1.User
+	5,   // 9: user.v1.GetUserResponse.user:type_name -> user.v1.User
+	5,   // 10: user.v1.ListInactiveUsersResponse.users:type_name -> user.v1.User
+	5,   // 11: user.v1.GetUserByEmailResponse.user:type_name -> user.v1.User
+	5,   // 12: user.v1.BatchGetUsersResponse.users:type_name -> user.v1.User
+	1,   // 13: user.v1.ListUsersRequest.view:type_name -> user.v1.UserView
+	5,   // 14: user.v1.ListUsersResponse.users:type_name -> user.v1.User
+	1,   // 15: user.v1.ExportUsersRequest.view:type_name -> user.v1.UserView
+	5,   // 16: user.v1.ExportUsersResponse.users:type_name -> user.v1.User
+	5,   // 17: user.v1.SearchUsersResponse.users:type_name -> user.v1.User
+	0,   // 18: user.v1.UpdateUserRequest.role:type_name -> user.v1.UserRole
+	103, // 19: user.v1.UpdateUserRequest.update_mask:type_name -> google.protobuf.FieldMask
+	30,  // 20: user.v1.ListMyIdentitiesResponse.identities:type_name -> user.v1.Identity
+	102, // 21: user.v1.Identity.linked_at:type_name -> google.protobuf.Timestamp
+	102, // 22: user.v1.RequestAccountDeletionResponse.scheduled_at:type_name -> google.protobuf.Timestamp
+	102, // 23: user.v1.RequestEmailChangeResponse.expires_at:type_name -> google.protobuf.Timestamp
+	43,  // 24: user.v1.GetNotificationPreferencesResponse.preferences:type_name -> user.v1.NotificationPreferences
+	43,  // 25: user.v1.UpdateNotificationPreferencesResponse.preferences:type_name -> user.v1.NotificationPreferences
+	102, // 26: user.v1.TenantSettings.updated_at:type_name -> google.protobuf.Timestamp
+	52,  // 27: user.v1.ListTenantSettingsResponse.tenants:type_name -> user.v1.TenantSettings
+	52,  // 28: user.v1.GetTenantSettingsResponse.settings:type_name -> user.v1.TenantSettings
+	57,  // 29: user.v1.UpdateTenantSettingsRequest.allowed_providers:type_name -> user.v1.TenantProviders
+	52,  // 30: user.v1.UpdateTenantSettingsResponse.settings:type_name -> user.v1.TenantSettings
+	102, // 31: user.v1.AdminInviteUserResponse.expires_at:type_name -> google.protobuf.Timestamp
+	66,  // 32: user.v1.AdminListFeatureFlagsResponse.flags:type_name -> user.v1.FeatureFlag
+	66,  // 33: user.v1.AdminSetFeatureFlagResponse.flag:type_name -> user.v1.FeatureFlag
+	102, // 34: user.v1.Failpoint.expire_time:type_name -> google.protobuf.Timestamp
+	71,  // 35: user.v1.AdminListFailpointsResponse.failpoints:type_name -> user.v1.Failpoint
+	71,  // 36: user.v1.AdminSetFailpointResponse.failpoint:type_name -> user.v1.Failpoint
+	104, // 37: user.v1.AdminGetRuntimeConfigResponse.config:type_name -> google.protobuf.Struct
+	102, // 38: user.v1.OAuthState.created_at:type_name -> google.protobuf.Timestamp
+	102, // 39: user.v1.OAuthState.expires_at:type_name -> google.protobuf.Timestamp
+	101, // 40: user.v1.AdminListOAuthStatesResponse.count_by_provider:type_name -> user.v1.AdminListOAuthStatesResponse.CountByProviderEntry
+	78,  // 41: user.v1.AdminListOAuthStatesResponse.states:type_name -> user.v1.OAuthState
+	102, // 42: user.v1.GetLoginSLOResponse.window_start:type_name -> google.protobuf.Timestamp
+	102, // 43: user.v1.GetLoginSLOResponse.window_end:type_name -> google.protobuf.Timestamp
+	91,  // 44: user.v1.GetLoginSLOResponse.login_success:type_name -> user.v1.ServiceLevelIndicator
+	91,  // 45: user.v1.GetLoginSLOResponse.token_issuance_p99:type_name -> user.v1.ServiceLevelIndicator
+	91,  // 46: user.v1.GetLoginSLOResponse.oauth_exchange_success:type_name -> user.v1.ServiceLevelIndicator
+	2,   // 47: user.v1.Report.kind:type_name -> user.v1.ReportKind
+	3,   // 48: user.v1.Report.format:type_name -> user.v1.ReportFormat
+	4,   // 49: user.v1.Report.status:type_name -> user.v1.ReportStatus
+	102, // 50: user.v1.Report.created_at:type_name -> google.protobuf.Timestamp
+	102, // 51: user.v1.Report.completed_at:type_name -> google.protobuf.Timestamp
+	2,   // 52: user.v1.CreateReportRequest.kind:type_name -> user.v1.ReportKind
+	3,   // 53: user.v1.CreateReportRequest.format:type_name -> user.v1.ReportFormat
+	92,  // 54: user.v1.CreateReportResponse.report:type_name -> user.v1.Report
+	92,  // 55: user.v1.ListReportsResponse.reports:type_name -> user.v1.Report
+	102, // 56: user.v1.DownloadReportResponse.expires_at:type_name -> google.protobuf.Timestamp
+	6,   // 57: user.v1.UserService.CreateUser:input_type -> user.v1.CreateUserRequest
+	8,   // 58: user.v1.UserService.GetCurrentUser:input_type -> user.v1.GetCurrentUserRequest
+	10,  // 59: user.v1.UserService.GetUser:input_type -> user.v1.GetUserRequest
+	14,  // 60: user.v1.UserService.GetUserByEmail:input_type -> user.v1.GetUserByEmailRequest
+	16,  // 61: user.v1.UserService.BatchGetUsers:input_type -> user.v1.BatchGetUsersRequest
+	18,  // 62: user.v1.UserService.ListUsers:input_type -> user.v1.ListUsersRequest
+	22,  // 63: user.v1.UserService.SearchUsers:input_type -> user.v1.SearchUsersRequest
+	12,  // 64: user.v1.UserService.ListInactiveUsers:input_type -> user.v1.ListInactiveUsersRequest
+	20,  // 65: user.v1.UserService.ExportUsers:input_type -> user.v1.ExportUsersRequest
+	24,  // 66: user.v1.UserService.UpdateUser:input_type -> user.v1.UpdateUserRequest
+	26,  // 67: user.v1.UserService.DeleteUser:input_type -> user.v1.DeleteUserRequest
+	28,  // 68: user.v1.UserService.ListMyIdentities:input_type -> user.v1.ListMyIdentitiesRequest
+	31,  // 69: user.v1.UserService.RequestAccountDeletion:input_type -> user.v1.RequestAccountDeletionRequest
+	33,  // 70: user.v1.UserService.CancelAccountDeletion:input_type -> user.v1.CancelAccountDeletionRequest
+	35,  // 71: user.v1.UserService.RequestEmailChange:input_type -> user.v1.RequestEmailChangeRequest
+	37,  // 72: user.v1.UserService.ConfirmEmailChange:input_type -> user.v1.ConfirmEmailChangeRequest
+	39,  // 73: user.v1.UserService.RollbackEmailChange:input_type -> user.v1.RollbackEmailChangeRequest
+	41,  // 74: user.v1.UserService.ChangePassword:input_type -> user.v1.ChangePasswordRequest
+	44,  // 75: user.v1.UserService.GetNotificationPreferences:input_type -> user.v1.GetNotificationPreferencesRequest
+	46,  // 76: user.v1.UserService.UpdateNotificationPreferences:input_type -> user.v1.UpdateNotificationPreferencesRequest
+	48,  // 77: user.v1.UserService.AdminRevokeUserSessions:input_type -> user.v1.AdminRevokeUserSessionsRequest
+	50,  // 78: user.v1.UserService.AdminRevokeSession:input_type -> user.v1.AdminRevokeSessionRequest
+	64,  // 79: user.v1.UserService.AdminInviteUser:input_type -> user.v1.AdminInviteUserRequest
+	67,  // 80: user.v1.UserService.AdminListFeatureFlags:input_type -> user.v1.AdminListFeatureFlagsRequest
+	69,  // 81: user.v1.UserService.AdminSetFeatureFlag:input_type -> user.v1.AdminSetFeatureFlagRequest
+	72,  // 82: user.v1.UserService.AdminListFailpoints:input_type -> user.v1.AdminListFailpointsRequest
+	74,  // 83: user.v1.UserService.AdminSetFailpoint:input_type -> user.v1.AdminSetFailpointRequest
+	76,  // 84: user.v1.UserService.AdminGetRuntimeConfig:input_type -> user.v1.AdminGetRuntimeConfigRequest
+	79,  // 85: user.v1.UserService.AdminListOAuthStates:input_type -> user.v1.AdminListOAuthStatesRequest
+	81,  // 86: user.v1.UserService.AdminPurgeOAuthStates:input_type -> user.v1.AdminPurgeOAuthStatesRequest
+	83,  // 87: user.v1.UserService.AdminSendSecurityNotice:input_type -> user.v1.AdminSendSecurityNoticeRequest
+	85,  // 88: user.v1.UserService.AdminUpdateUserEntitlements:input_type -> user.v1.AdminUpdateUserEntitlementsRequest
+	87,  // 89: user.v1.UserService.GetKeyUsage:input_type -> user.v1.GetKeyUsageRequest
+	89,  // 90: user.v1.UserService.GetLoginSLO:input_type -> user.v1.GetLoginSLORequest
+	93,  // 91: user.v1.UserService.CreateReport:input_type -> user.v1.CreateReportRequest
+	95,  // 92: user.v1.UserService.ListReports:input_type -> user.v1.ListReportsRequest
+	97,  // 93: user.v1.UserService.DownloadReport:input_type -> user.v1.DownloadReportRequest
+	99,  // 94: user.v1.UserService.GetReportContent:input_type -> user.v1.GetReportContentRequest
+	53,  // 95: user.v1.TenantSettingsService.ListTenantSettings:input_type -> user.v1.ListTenantSettingsRequest
+	55,  // 96: user.v1.TenantSettingsService.GetTenantSettings:input_type -> user.v1.GetTenantSettingsRequest
+	58,  // 97: user.v1.TenantSettingsService.UpdateTenantSettings:input_type -> user.v1.UpdateTenantSettingsRequest
+	60,  // 98: user.v1.TenantSettingsService.DeleteTenantSettings:input_type -> user.v1.DeleteTenantSettingsRequest
+	62,  // 99: user.v1.TenantSettingsService.GetTenantPublicConfig:input_type -> user.v1.GetTenantPublicConfigRequest
+	7,   // 100: user.v1.UserService.CreateUser:output_type -> user.v1.CreateUserResponse
+	9,   // 101: user.v1.UserService.GetCurrentUser:output_type -> user.v1.GetCurrentUserResponse
+	11,  // 102: user.v1.UserService.GetUser:output_type -> user.v1.GetUserResponse
+	15,  // 103: user.v1.UserService.GetUserByEmail:output_type -> user.v1.GetUserByEmailResponse
+	17,  // 104: user.v1.UserService.BatchGetUsers:output_type -> user.v1.BatchGetUsersResponse
+	19,  // 105: user.v1.UserService.ListUsers:output_type -> user.v1.ListUsersResponse
+	23,  // 106: user.v1.UserService.SearchUsers:output_type -> user.v1.SearchUsersResponse
+	13,  // 107: user.v1.UserService.ListInactiveUsers:output_type -> user.v1.ListInactiveUsersResponse
+	21,  // 108: user.v1.UserService.ExportUsers:output_type -> user.v1.ExportUsersResponse
+	25,  // 109: user.v1.UserService.UpdateUser:output_type -> user.v1.UpdateUserResponse
+	27,  // 110: user.v1.UserService.DeleteUser:output_type -> user.v1.DeleteUserResponse
+	29,  // 111: user.v1.UserService.ListMyIdentities:output_type -> user.v1.ListMyIdentitiesResponse
+	32,  // 112: user.v1.UserService.RequestAccountDeletion:output_type -> user.v1.RequestAccountDeletionResponse
+	34,  // 113: user.v1.UserService.CancelAccountDeletion:output_type -> user.v1.CancelAccountDeletionResponse
+	36,  // 114: user.v1.UserService.RequestEmailChange:output_type -> user.v1.RequestEmailChangeResponse
+	38,  // 115: user.v1.UserService.ConfirmEmailChange:output_type -> user.v1.ConfirmEmailChangeResponse
+	40,  // 116: user.v1.UserService.RollbackEmailChange:output_type -> user.v1.RollbackEmailChangeResponse
+	42,  // 117: user.v1.UserService.ChangePassword:output_type -> user.v1.ChangePasswordResponse
+	45,  // 118: user.v1.UserService.GetNotificationPreferences:output_type -> user.v1.GetNotificationPreferencesResponse
+	47,  // 119: user.v1.UserService.UpdateNotificationPreferences:output_type -> user.v1.UpdateNotificationPreferencesResponse
+	49,  // 120: user.v1.UserService.AdminRevokeUserSessions:output_type -> user.v1.AdminRevokeUserSessionsResponse
+	51,  // 121: user.v1.UserService.AdminRevokeSession:output_type -> user.v1.AdminRevokeSessionResponse
+	65,  // 122: user.v1.UserService.AdminInviteUser:output_type -> user.v1.AdminInviteUserResponse
+	68,  // 123: user.v1.UserService.AdminListFeatureFlags:output_type -> user.v1.AdminListFeatureFlagsResponse
+	70,  // 124: user.v1.UserService.AdminSetFeatureFlag:output_type -> user.v1.AdminSetFeatureFlagResponse
+	73,  // 125: user.v1.UserService.AdminListFailpoints:output_type -> user.v1.AdminListFailpointsResponse
+	75,  // 126: user.v1.UserService.AdminSetFailpoint:output_type -> user.v1.AdminSetFailpointResponse
+	77,  // 127: user.v1.UserService.AdminGetRuntimeConfig:output_type -> user.v1.AdminGetRuntimeConfigResponse
+	80,  // 128: user.v1.UserService.AdminListOAuthStates:output_type -> user.v1.AdminListOAuthStatesResponse
+	82,  // 129: user.v1.UserService.AdminPurgeOAuthStates:output_type -> user.v1.AdminPurgeOAuthStatesResponse
+	84,  // 130: user.v1.UserService.AdminSendSecurityNotice:output_type -> user.v1.AdminSendSecurityNoticeResponse
+	86,  // 131: user.v1.UserService.AdminUpdateUserEntitlements:output_type -> user.v1.AdminUpdateUserEntitlementsResponse
+	88,  // 132: user.v1.UserService.GetKeyUsage:output_type -> user.v1.GetKeyUsageResponse
+	90,  // 133: user.v1.UserService.GetLoginSLO:output_type -> user.v1.GetLoginSLOResponse
+	94,  // 134: user.v1.UserService.CreateReport:output_type -> user.v1.CreateReportResponse
+	96,  // 135: user.v1.UserService.ListReports:output_type -> user.v1.ListReportsResponse
+	98,  // 136: user.v1.UserService.DownloadReport:output_type -> user.v1.DownloadReportResponse
+	105, // 137: user.v1.UserService.GetReportContent:output_type -> google.api.HttpBody
+	54,  // 138: user.v1.TenantSettingsService.ListTenantSettings:output_type -> user.v1.ListTenantSettingsResponse
+	56,  // 139: user.v1.TenantSettingsService.GetTenantSettings:output_type -> user.v1.GetTenantSettingsResponse
+	59,  // 140: user.v1.TenantSettingsService.UpdateTenantSettings:output_type -> user.v1.UpdateTenantSettingsResponse
+	61,  // 141: user.v1.TenantSettingsService.DeleteTenantSettings:output_type -> user.v1.DeleteTenantSettingsResponse
+	63,  // 142: user.v1.TenantSettingsService.GetTenantPublicConfig:output_type -> user.v1.GetTenantPublicConfigResponse
+	100, // [100:143] is the sub-list for method output_type
+	57,  // [57:100] is the sub-list for method input_type
+	57,  // [57:57] is the sub-list for extension type_name
+	57,  // [57:57] is the sub-list for extension extendee
+	0,   // [0:57] is the sub-list for field type_name
 }
 
 func init() { file_user_v1_user_proto_init() }
@@ -886,16 +6084,22 @@ func file_user_v1_user_proto_init() {
 	}
 	file_user_v1_user_proto_msgTypes[0].OneofWrappers = []any{}
 	file_user_v1_user_proto_msgTypes[1].OneofWrappers = []any{}
-	file_user_v1_user_proto_msgTypes[9].OneofWrappers = []any{}
+	file_user_v1_user_proto_msgTypes[13].OneofWrappers = []any{}
+	file_user_v1_user_proto_msgTypes[15].OneofWrappers = []any{}
+	file_user_v1_user_proto_msgTypes[19].OneofWrappers = []any{}
+	file_user_v1_user_proto_msgTypes[41].OneofWrappers = []any{}
+	file_user_v1_user_proto_msgTypes[47].OneofWrappers = []any{}
+	file_user_v1_user_proto_msgTypes[53].OneofWrappers = []any{}
+	file_user_v1_user_proto_msgTypes[87].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_user_v1_user_proto_rawDesc), len(file_user_v1_user_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   13,
+			NumEnums:      5,
+			NumMessages:   97,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_user_v1_user_proto_goTypes,
 		DependencyIndexes: file_user_v1_user_proto_depIdxs,
@@ -8,6 +8,7 @@ package user_v1_pb
 
 import (
 	context "context"
+	httpbody "google.golang.org/genproto/googleapis/api/httpbody"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -19,12 +20,44 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	UserService_CreateUser_FullMethodName     = "/user.v1.UserService/CreateUser"
-	UserService_GetCurrentUser_FullMethodName = "/user.v1.UserService/GetCurrentUser"
-	UserService_GetUser_FullMethodName        = "/user.v1.UserService/GetUser"
-	UserService_ListUsers_FullMethodName      = "/user.v1.UserService/ListUsers"
-	UserService_UpdateUser_FullMethodName     = "/user.v1.UserService/UpdateUser"
-	UserService_DeleteUser_FullMethodName     = "/user.v1.UserService/DeleteUser"
+	UserService_CreateUser_FullMethodName                    = "/user.v1.UserService/CreateUser"
+	UserService_GetCurrentUser_FullMethodName                = "/user.v1.UserService/GetCurrentUser"
+	UserService_GetUser_FullMethodName                       = "/user.v1.UserService/GetUser"
+	UserService_GetUserByEmail_FullMethodName                = "/user.v1.UserService/GetUserByEmail"
+	UserService_BatchGetUsers_FullMethodName                 = "/user.v1.UserService/BatchGetUsers"
+	UserService_ListUsers_FullMethodName                     = "/user.v1.UserService/ListUsers"
+	UserService_SearchUsers_FullMethodName                   = "/user.v1.UserService/SearchUsers"
+	UserService_ListInactiveUsers_FullMethodName             = "/user.v1.UserService/ListInactiveUsers"
+	UserService_ExportUsers_FullMethodName                   = "/user.v1.UserService/ExportUsers"
+	UserService_UpdateUser_FullMethodName                    = "/user.v1.UserService/UpdateUser"
+	UserService_DeleteUser_FullMethodName                    = "/user.v1.UserService/DeleteUser"
+	UserService_ListMyIdentities_FullMethodName              = "/user.v1.UserService/ListMyIdentities"
+	UserService_RequestAccountDeletion_FullMethodName        = "/user.v1.UserService/RequestAccountDeletion"
+	UserService_CancelAccountDeletion_FullMethodName         = "/user.v1.UserService/CancelAccountDeletion"
+	UserService_RequestEmailChange_FullMethodName            = "/user.v1.UserService/RequestEmailChange"
+	UserService_ConfirmEmailChange_FullMethodName            = "/user.v1.UserService/ConfirmEmailChange"
+	UserService_RollbackEmailChange_FullMethodName           = "/user.v1.UserService/RollbackEmailChange"
+	UserService_ChangePassword_FullMethodName                = "/user.v1.UserService/ChangePassword"
+	UserService_GetNotificationPreferences_FullMethodName    = "/user.v1.UserService/GetNotificationPreferences"
+	UserService_UpdateNotificationPreferences_FullMethodName = "/user.v1.UserService/UpdateNotificationPreferences"
+	UserService_AdminRevokeUserSessions_FullMethodName       = "/user.v1.UserService/AdminRevokeUserSessions"
+	UserService_AdminRevokeSession_FullMethodName            = "/user.v1.UserService/AdminRevokeSession"
+	UserService_AdminInviteUser_FullMethodName               = "/user.v1.UserService/AdminInviteUser"
+	UserService_AdminListFeatureFlags_FullMethodName         = "/user.v1.UserService/AdminListFeatureFlags"
+	UserService_AdminSetFeatureFlag_FullMethodName           = "/user.v1.UserService/AdminSetFeatureFlag"
+	UserService_AdminListFailpoints_FullMethodName           = "/user.v1.UserService/AdminListFailpoints"
+	UserService_AdminSetFailpoint_FullMethodName             = "/user.v1.UserService/AdminSetFailpoint"
+	UserService_AdminGetRuntimeConfig_FullMethodName         = "/user.v1.UserService/AdminGetRuntimeConfig"
+	UserService_AdminListOAuthStates_FullMethodName          = "/user.v1.UserService/AdminListOAuthStates"
+	UserService_AdminPurgeOAuthStates_FullMethodName         = "/user.v1.UserService/AdminPurgeOAuthStates"
+	UserService_AdminSendSecurityNotice_FullMethodName       = "/user.v1.UserService/AdminSendSecurityNotice"
+	UserService_AdminUpdateUserEntitlements_FullMethodName   = "/user.v1.UserService/AdminUpdateUserEntitlements"
+	UserService_GetKeyUsage_FullMethodName                   = "/user.v1.UserService/GetKeyUsage"
+	UserService_GetLoginSLO_FullMethodName                   = "/user.v1.UserService/GetLoginSLO"
+	UserService_CreateReport_FullMethodName                  = "/user.v1.UserService/CreateReport"
+	UserService_ListReports_FullMethodName                   = "/user.v1.UserService/ListReports"
+	UserService_DownloadReport_FullMethodName                = "/user.v1.UserService/DownloadReport"
+	UserService_GetReportContent_FullMethodName              = "/user.v1.UserService/GetReportContent"
 )
 
 // UserServiceClient is the client API for UserService service.
@@ -34,9 +67,66 @@ type UserServiceClient interface {
 	CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*CreateUserResponse, error)
 	GetCurrentUser(ctx context.Context, in *GetCurrentUserRequest, opts ...grpc.CallOption) (*GetCurrentUserResponse, error)
 	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*GetUserResponse, error)
+	GetUserByEmail(ctx context.Context, in *GetUserByEmailRequest, opts ...grpc.CallOption) (*GetUserByEmailResponse, error)
+	// BatchGetUsers resolves up to 100 user IDs with a single query
+	BatchGetUsers(ctx context.Context, in *BatchGetUsersRequest, opts ...grpc.CallOption) (*BatchGetUsersResponse, error)
 	ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error)
+	// SearchUsers finds users by a prefix of their name or email, for autocomplete
+	SearchUsers(ctx context.Context, in *SearchUsersRequest, opts ...grpc.CallOption) (*SearchUsersResponse, error)
+	// ListInactiveUsers lists accounts not seen for inactive_days, for cleanup campaigns
+	ListInactiveUsers(ctx context.Context, in *ListInactiveUsersRequest, opts ...grpc.CallOption) (*ListInactiveUsersResponse, error)
+	// ExportUsers streams all users in chunks, for exports too large for ListUsers pages
+	ExportUsers(ctx context.Context, in *ExportUsersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportUsersResponse], error)
 	UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*UpdateUserResponse, error)
 	DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*DeleteUserResponse, error)
+	// ListMyIdentities lists the external accounts linked to the caller's account
+	ListMyIdentities(ctx context.Context, in *ListMyIdentitiesRequest, opts ...grpc.CallOption) (*ListMyIdentitiesResponse, error)
+	RequestAccountDeletion(ctx context.Context, in *RequestAccountDeletionRequest, opts ...grpc.CallOption) (*RequestAccountDeletionResponse, error)
+	CancelAccountDeletion(ctx context.Context, in *CancelAccountDeletionRequest, opts ...grpc.CallOption) (*CancelAccountDeletionResponse, error)
+	RequestEmailChange(ctx context.Context, in *RequestEmailChangeRequest, opts ...grpc.CallOption) (*RequestEmailChangeResponse, error)
+	ConfirmEmailChange(ctx context.Context, in *ConfirmEmailChangeRequest, opts ...grpc.CallOption) (*ConfirmEmailChangeResponse, error)
+	RollbackEmailChange(ctx context.Context, in *RollbackEmailChangeRequest, opts ...grpc.CallOption) (*RollbackEmailChangeResponse, error)
+	ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*ChangePasswordResponse, error)
+	GetNotificationPreferences(ctx context.Context, in *GetNotificationPreferencesRequest, opts ...grpc.CallOption) (*GetNotificationPreferencesResponse, error)
+	UpdateNotificationPreferences(ctx context.Context, in *UpdateNotificationPreferencesRequest, opts ...grpc.CallOption) (*UpdateNotificationPreferencesResponse, error)
+	AdminRevokeUserSessions(ctx context.Context, in *AdminRevokeUserSessionsRequest, opts ...grpc.CallOption) (*AdminRevokeUserSessionsResponse, error)
+	AdminRevokeSession(ctx context.Context, in *AdminRevokeSessionRequest, opts ...grpc.CallOption) (*AdminRevokeSessionResponse, error)
+	AdminInviteUser(ctx context.Context, in *AdminInviteUserRequest, opts ...grpc.CallOption) (*AdminInviteUserResponse, error)
+	AdminListFeatureFlags(ctx context.Context, in *AdminListFeatureFlagsRequest, opts ...grpc.CallOption) (*AdminListFeatureFlagsResponse, error)
+	AdminSetFeatureFlag(ctx context.Context, in *AdminSetFeatureFlagRequest, opts ...grpc.CallOption) (*AdminSetFeatureFlagResponse, error)
+	// AdminListFailpoints returns the failpoints of the answering server, see
+	// the failpoints configuration
+	AdminListFailpoints(ctx context.Context, in *AdminListFailpointsRequest, opts ...grpc.CallOption) (*AdminListFailpointsResponse, error)
+	// AdminSetFailpoint injects failures or latency into the calls of the
+	// answering server to a dependency, in development only; one that neither
+	// fails nor delays calls clears the failpoint
+	AdminSetFailpoint(ctx context.Context, in *AdminSetFailpointRequest, opts ...grpc.CallOption) (*AdminSetFailpointResponse, error)
+	// AdminGetRuntimeConfig returns the configuration the answering server runs
+	// with, secrets redacted, and where it was loaded from
+	AdminGetRuntimeConfig(ctx context.Context, in *AdminGetRuntimeConfigRequest, opts ...grpc.CallOption) (*AdminGetRuntimeConfigResponse, error)
+	AdminListOAuthStates(ctx context.Context, in *AdminListOAuthStatesRequest, opts ...grpc.CallOption) (*AdminListOAuthStatesResponse, error)
+	AdminPurgeOAuthStates(ctx context.Context, in *AdminPurgeOAuthStatesRequest, opts ...grpc.CallOption) (*AdminPurgeOAuthStatesResponse, error)
+	// AdminSendSecurityNotice emails a security notice to a user, e.g. after an
+	// incident affecting their account
+	AdminSendSecurityNotice(ctx context.Context, in *AdminSendSecurityNoticeRequest, opts ...grpc.CallOption) (*AdminSendSecurityNoticeResponse, error)
+	// AdminUpdateUserEntitlements adds and removes entitlements of a user; also
+	// used by billing services with the internal token when plans change
+	AdminUpdateUserEntitlements(ctx context.Context, in *AdminUpdateUserEntitlementsRequest, opts ...grpc.CallOption) (*AdminUpdateUserEntitlementsResponse, error)
+	// GetKeyUsage returns the calls of an internal credential in the current day
+	// and month, e.g. "internal" for the internal token, along with its quota
+	GetKeyUsage(ctx context.Context, in *GetKeyUsageRequest, opts ...grpc.CallOption) (*GetKeyUsageResponse, error)
+	// GetLoginSLO summarizes the login SLIs of the last 24 hours, by the hour,
+	// against their objectives (see the slo configuration)
+	GetLoginSLO(ctx context.Context, in *GetLoginSLORequest, opts ...grpc.CallOption) (*GetLoginSLOResponse, error)
+	// CreateReport requests a report, which is generated in the background
+	CreateReport(ctx context.Context, in *CreateReportRequest, opts ...grpc.CallOption) (*CreateReportResponse, error)
+	// ListReports returns the latest reports, newest first
+	ListReports(ctx context.Context, in *ListReportsRequest, opts ...grpc.CallOption) (*ListReportsResponse, error)
+	// DownloadReport returns a signed URL the report can be downloaded from for a
+	// while, without further authentication
+	DownloadReport(ctx context.Context, in *DownloadReportRequest, opts ...grpc.CallOption) (*DownloadReportResponse, error)
+	// GetReportContent serves the file of a report to holders of a signed URL
+	GetReportContent(ctx context.Context, in *GetReportContentRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error)
 }
 
 type userServiceClient struct {
@@ -77,233 +167,1688 @@ func (c *userServiceClient) GetUser(ctx context.Context, in *GetUserRequest, opt
 	return out, nil
 }
 
+func (c *userServiceClient) GetUserByEmail(ctx context.Context, in *GetUserByEmailRequest, opts ...grpc.CallOption) (*GetUserByEmailResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUserByEmailResponse)
+	err := c.cc.Invoke(ctx, UserService_GetUserByEmail_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) BatchGetUsers(ctx context.Context, in *BatchGetUsersRequest, opts ...grpc.CallOption) (*BatchGetUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetUsersResponse)
+	err := c.cc.Invoke(ctx, UserService_BatchGetUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *userServiceClient) ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(ListUsersResponse)
-	err := c.cc.Invoke(ctx, UserService_ListUsers_FullMethodName, in, out, cOpts...)
+	out := new(ListUsersResponse)
+	err := c.cc.Invoke(ctx, UserService_ListUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) SearchUsers(ctx context.Context, in *SearchUsersRequest, opts ...grpc.CallOption) (*SearchUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchUsersResponse)
+	err := c.cc.Invoke(ctx, UserService_SearchUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) ListInactiveUsers(ctx context.Context, in *ListInactiveUsersRequest, opts ...grpc.CallOption) (*ListInactiveUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListInactiveUsersResponse)
+	err := c.cc.Invoke(ctx, UserService_ListInactiveUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) ExportUsers(ctx context.Context, in *ExportUsersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportUsersResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &UserService_ServiceDesc.Streams[0], UserService_ExportUsers_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportUsersRequest, ExportUsersResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type UserService_ExportUsersClient = grpc.ServerStreamingClient[ExportUsersResponse]
+
+func (c *userServiceClient) UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*UpdateUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateUserResponse)
+	err := c.cc.Invoke(ctx, UserService_UpdateUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*DeleteUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteUserResponse)
+	err := c.cc.Invoke(ctx, UserService_DeleteUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) ListMyIdentities(ctx context.Context, in *ListMyIdentitiesRequest, opts ...grpc.CallOption) (*ListMyIdentitiesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMyIdentitiesResponse)
+	err := c.cc.Invoke(ctx, UserService_ListMyIdentities_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) RequestAccountDeletion(ctx context.Context, in *RequestAccountDeletionRequest, opts ...grpc.CallOption) (*RequestAccountDeletionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RequestAccountDeletionResponse)
+	err := c.cc.Invoke(ctx, UserService_RequestAccountDeletion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) CancelAccountDeletion(ctx context.Context, in *CancelAccountDeletionRequest, opts ...grpc.CallOption) (*CancelAccountDeletionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelAccountDeletionResponse)
+	err := c.cc.Invoke(ctx, UserService_CancelAccountDeletion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) RequestEmailChange(ctx context.Context, in *RequestEmailChangeRequest, opts ...grpc.CallOption) (*RequestEmailChangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RequestEmailChangeResponse)
+	err := c.cc.Invoke(ctx, UserService_RequestEmailChange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) ConfirmEmailChange(ctx context.Context, in *ConfirmEmailChangeRequest, opts ...grpc.CallOption) (*ConfirmEmailChangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConfirmEmailChangeResponse)
+	err := c.cc.Invoke(ctx, UserService_ConfirmEmailChange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) RollbackEmailChange(ctx context.Context, in *RollbackEmailChangeRequest, opts ...grpc.CallOption) (*RollbackEmailChangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RollbackEmailChangeResponse)
+	err := c.cc.Invoke(ctx, UserService_RollbackEmailChange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*ChangePasswordResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChangePasswordResponse)
+	err := c.cc.Invoke(ctx, UserService_ChangePassword_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) GetNotificationPreferences(ctx context.Context, in *GetNotificationPreferencesRequest, opts ...grpc.CallOption) (*GetNotificationPreferencesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetNotificationPreferencesResponse)
+	err := c.cc.Invoke(ctx, UserService_GetNotificationPreferences_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) UpdateNotificationPreferences(ctx context.Context, in *UpdateNotificationPreferencesRequest, opts ...grpc.CallOption) (*UpdateNotificationPreferencesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateNotificationPreferencesResponse)
+	err := c.cc.Invoke(ctx, UserService_UpdateNotificationPreferences_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) AdminRevokeUserSessions(ctx context.Context, in *AdminRevokeUserSessionsRequest, opts ...grpc.CallOption) (*AdminRevokeUserSessionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminRevokeUserSessionsResponse)
+	err := c.cc.Invoke(ctx, UserService_AdminRevokeUserSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) AdminRevokeSession(ctx context.Context, in *AdminRevokeSessionRequest, opts ...grpc.CallOption) (*AdminRevokeSessionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminRevokeSessionResponse)
+	err := c.cc.Invoke(ctx, UserService_AdminRevokeSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) AdminInviteUser(ctx context.Context, in *AdminInviteUserRequest, opts ...grpc.CallOption) (*AdminInviteUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminInviteUserResponse)
+	err := c.cc.Invoke(ctx, UserService_AdminInviteUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) AdminListFeatureFlags(ctx context.Context, in *AdminListFeatureFlagsRequest, opts ...grpc.CallOption) (*AdminListFeatureFlagsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminListFeatureFlagsResponse)
+	err := c.cc.Invoke(ctx, UserService_AdminListFeatureFlags_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) AdminSetFeatureFlag(ctx context.Context, in *AdminSetFeatureFlagRequest, opts ...grpc.CallOption) (*AdminSetFeatureFlagResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminSetFeatureFlagResponse)
+	err := c.cc.Invoke(ctx, UserService_AdminSetFeatureFlag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) AdminListFailpoints(ctx context.Context, in *AdminListFailpointsRequest, opts ...grpc.CallOption) (*AdminListFailpointsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminListFailpointsResponse)
+	err := c.cc.Invoke(ctx, UserService_AdminListFailpoints_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) AdminSetFailpoint(ctx context.Context, in *AdminSetFailpointRequest, opts ...grpc.CallOption) (*AdminSetFailpointResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminSetFailpointResponse)
+	err := c.cc.Invoke(ctx, UserService_AdminSetFailpoint_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) AdminGetRuntimeConfig(ctx context.Context, in *AdminGetRuntimeConfigRequest, opts ...grpc.CallOption) (*AdminGetRuntimeConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminGetRuntimeConfigResponse)
+	err := c.cc.Invoke(ctx, UserService_AdminGetRuntimeConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) AdminListOAuthStates(ctx context.Context, in *AdminListOAuthStatesRequest, opts ...grpc.CallOption) (*AdminListOAuthStatesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminListOAuthStatesResponse)
+	err := c.cc.Invoke(ctx, UserService_AdminListOAuthStates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) AdminPurgeOAuthStates(ctx context.Context, in *AdminPurgeOAuthStatesRequest, opts ...grpc.CallOption) (*AdminPurgeOAuthStatesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminPurgeOAuthStatesResponse)
+	err := c.cc.Invoke(ctx, UserService_AdminPurgeOAuthStates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) AdminSendSecurityNotice(ctx context.Context, in *AdminSendSecurityNoticeRequest, opts ...grpc.CallOption) (*AdminSendSecurityNoticeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminSendSecurityNoticeResponse)
+	err := c.cc.Invoke(ctx, UserService_AdminSendSecurityNotice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) AdminUpdateUserEntitlements(ctx context.Context, in *AdminUpdateUserEntitlementsRequest, opts ...grpc.CallOption) (*AdminUpdateUserEntitlementsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminUpdateUserEntitlementsResponse)
+	err := c.cc.Invoke(ctx, UserService_AdminUpdateUserEntitlements_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) GetKeyUsage(ctx context.Context, in *GetKeyUsageRequest, opts ...grpc.CallOption) (*GetKeyUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetKeyUsageResponse)
+	err := c.cc.Invoke(ctx, UserService_GetKeyUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) GetLoginSLO(ctx context.Context, in *GetLoginSLORequest, opts ...grpc.CallOption) (*GetLoginSLOResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetLoginSLOResponse)
+	err := c.cc.Invoke(ctx, UserService_GetLoginSLO_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) CreateReport(ctx context.Context, in *CreateReportRequest, opts ...grpc.CallOption) (*CreateReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateReportResponse)
+	err := c.cc.Invoke(ctx, UserService_CreateReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) ListReports(ctx context.Context, in *ListReportsRequest, opts ...grpc.CallOption) (*ListReportsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReportsResponse)
+	err := c.cc.Invoke(ctx, UserService_ListReports_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) DownloadReport(ctx context.Context, in *DownloadReportRequest, opts ...grpc.CallOption) (*DownloadReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DownloadReportResponse)
+	err := c.cc.Invoke(ctx, UserService_DownloadReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) GetReportContent(ctx context.Context, in *GetReportContentRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(httpbody.HttpBody)
+	err := c.cc.Invoke(ctx, UserService_GetReportContent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UserServiceServer is the server API for UserService service.
+// All implementations must embed UnimplementedUserServiceServer
+// for forward compatibility.
+type UserServiceServer interface {
+	CreateUser(context.Context, *CreateUserRequest) (*CreateUserResponse, error)
+	GetCurrentUser(context.Context, *GetCurrentUserRequest) (*GetCurrentUserResponse, error)
+	GetUser(context.Context, *GetUserRequest) (*GetUserResponse, error)
+	GetUserByEmail(context.Context, *GetUserByEmailRequest) (*GetUserByEmailResponse, error)
+	// BatchGetUsers resolves up to 100 user IDs with a single query
+	BatchGetUsers(context.Context, *BatchGetUsersRequest) (*BatchGetUsersResponse, error)
+	ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error)
+	// SearchUsers finds users by a prefix of their name or email, for autocomplete
+	SearchUsers(context.Context, *SearchUsersRequest) (*SearchUsersResponse, error)
+	// ListInactiveUsers lists accounts not seen for inactive_days, for cleanup campaigns
+	ListInactiveUsers(context.Context, *ListInactiveUsersRequest) (*ListInactiveUsersResponse, error)
+	// ExportUsers streams all users in chunks, for exports too large for ListUsers pages
+	ExportUsers(*ExportUsersRequest, grpc.ServerStreamingServer[ExportUsersResponse]) error
+	UpdateUser(context.Context, *UpdateUserRequest) (*UpdateUserResponse, error)
+	DeleteUser(context.Context, *DeleteUserRequest) (*DeleteUserResponse, error)
+	// ListMyIdentities lists the external accounts linked to the caller's account
+	ListMyIdentities(context.Context, *ListMyIdentitiesRequest) (*ListMyIdentitiesResponse, error)
+	RequestAccountDeletion(context.Context, *RequestAccountDeletionRequest) (*RequestAccountDeletionResponse, error)
+	CancelAccountDeletion(context.Context, *CancelAccountDeletionRequest) (*CancelAccountDeletionResponse, error)
+	RequestEmailChange(context.Context, *RequestEmailChangeRequest) (*RequestEmailChangeResponse, error)
+	ConfirmEmailChange(context.Context, *ConfirmEmailChangeRequest) (*ConfirmEmailChangeResponse, error)
+	RollbackEmailChange(context.Context, *RollbackEmailChangeRequest) (*RollbackEmailChangeResponse, error)
+	ChangePassword(context.Context, *ChangePasswordRequest) (*ChangePasswordResponse, error)
+	GetNotificationPreferences(context.Context, *GetNotificationPreferencesRequest) (*GetNotificationPreferencesResponse, error)
+	UpdateNotificationPreferences(context.Context, *UpdateNotificationPreferencesRequest) (*UpdateNotificationPreferencesResponse, error)
+	AdminRevokeUserSessions(context.Context, *AdminRevokeUserSessionsRequest) (*AdminRevokeUserSessionsResponse, error)
+	AdminRevokeSession(context.Context, *AdminRevokeSessionRequest) (*AdminRevokeSessionResponse, error)
+	AdminInviteUser(context.Context, *AdminInviteUserRequest) (*AdminInviteUserResponse, error)
+	AdminListFeatureFlags(context.Context, *AdminListFeatureFlagsRequest) (*AdminListFeatureFlagsResponse, error)
+	AdminSetFeatureFlag(context.Context, *AdminSetFeatureFlagRequest) (*AdminSetFeatureFlagResponse, error)
+	// AdminListFailpoints returns the failpoints of the answering server, see
+	// the failpoints configuration
+	AdminListFailpoints(context.Context, *AdminListFailpointsRequest) (*AdminListFailpointsResponse, error)
+	// AdminSetFailpoint injects failures or latency into the calls of the
+	// answering server to a dependency, in development only; one that neither
+	// fails nor delays calls clears the failpoint
+	AdminSetFailpoint(context.Context, *AdminSetFailpointRequest) (*AdminSetFailpointResponse, error)
+	// AdminGetRuntimeConfig returns the configuration the answering server runs
+	// with, secrets redacted, and where it was loaded from
+	AdminGetRuntimeConfig(context.Context, *AdminGetRuntimeConfigRequest) (*AdminGetRuntimeConfigResponse, error)
+	AdminListOAuthStates(context.Context, *AdminListOAuthStatesRequest) (*AdminListOAuthStatesResponse, error)
+	AdminPurgeOAuthStates(context.Context, *AdminPurgeOAuthStatesRequest) (*AdminPurgeOAuthStatesResponse, error)
+	// AdminSendSecurityNotice emails a security notice to a user, e.g. after an
+	// incident affecting their account
+	AdminSendSecurityNotice(context.Context, *AdminSendSecurityNoticeRequest) (*AdminSendSecurityNoticeResponse, error)
+	// AdminUpdateUserEntitlements adds and removes entitlements of a user; also
+	// used by billing services with the internal token when plans change
+	AdminUpdateUserEntitlements(context.Context, *AdminUpdateUserEntitlementsRequest) (*AdminUpdateUserEntitlementsResponse, error)
+	// GetKeyUsage returns the calls of an internal credential in the current day
+	// and month, e.g. "internal" for the internal token, along with its quota
+	GetKeyUsage(context.Context, *GetKeyUsageRequest) (*GetKeyUsageResponse, error)
+	// GetLoginSLO summarizes the login SLIs of the last 24 hours, by the hour,
+	// against their objectives (see the slo configuration)
+	GetLoginSLO(context.Context, *GetLoginSLORequest) (*GetLoginSLOResponse, error)
+	// CreateReport requests a report, which is generated in the background
+	CreateReport(context.Context, *CreateReportRequest) (*CreateReportResponse, error)
+	// ListReports returns the latest reports, newest first
+	ListReports(context.Context, *ListReportsRequest) (*ListReportsResponse, error)
+	// DownloadReport returns a signed URL the report can be downloaded from for a
+	// while, without further authentication
+	DownloadReport(context.Context, *DownloadReportRequest) (*DownloadReportResponse, error)
+	// GetReportContent serves the file of a report to holders of a signed URL
+	GetReportContent(context.Context, *GetReportContentRequest) (*httpbody.HttpBody, error)
+	mustEmbedUnimplementedUserServiceServer()
+}
+
+// UnimplementedUserServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedUserServiceServer struct{}
+
+func (UnimplementedUserServiceServer) CreateUser(context.Context, *CreateUserRequest) (*CreateUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUser not implemented")
+}
+func (UnimplementedUserServiceServer) GetCurrentUser(context.Context, *GetCurrentUserRequest) (*GetCurrentUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCurrentUser not implemented")
+}
+func (UnimplementedUserServiceServer) GetUser(context.Context, *GetUserRequest) (*GetUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+}
+func (UnimplementedUserServiceServer) GetUserByEmail(context.Context, *GetUserByEmailRequest) (*GetUserByEmailResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserByEmail not implemented")
+}
+func (UnimplementedUserServiceServer) BatchGetUsers(context.Context, *BatchGetUsersRequest) (*BatchGetUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetUsers not implemented")
+}
+func (UnimplementedUserServiceServer) ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUsers not implemented")
+}
+func (UnimplementedUserServiceServer) SearchUsers(context.Context, *SearchUsersRequest) (*SearchUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchUsers not implemented")
+}
+func (UnimplementedUserServiceServer) ListInactiveUsers(context.Context, *ListInactiveUsersRequest) (*ListInactiveUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListInactiveUsers not implemented")
+}
+func (UnimplementedUserServiceServer) ExportUsers(*ExportUsersRequest, grpc.ServerStreamingServer[ExportUsersResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportUsers not implemented")
+}
+func (UnimplementedUserServiceServer) UpdateUser(context.Context, *UpdateUserRequest) (*UpdateUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateUser not implemented")
+}
+func (UnimplementedUserServiceServer) DeleteUser(context.Context, *DeleteUserRequest) (*DeleteUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteUser not implemented")
+}
+func (UnimplementedUserServiceServer) ListMyIdentities(context.Context, *ListMyIdentitiesRequest) (*ListMyIdentitiesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMyIdentities not implemented")
+}
+func (UnimplementedUserServiceServer) RequestAccountDeletion(context.Context, *RequestAccountDeletionRequest) (*RequestAccountDeletionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestAccountDeletion not implemented")
+}
+func (UnimplementedUserServiceServer) CancelAccountDeletion(context.Context, *CancelAccountDeletionRequest) (*CancelAccountDeletionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelAccountDeletion not implemented")
+}
+func (UnimplementedUserServiceServer) RequestEmailChange(context.Context, *RequestEmailChangeRequest) (*RequestEmailChangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestEmailChange not implemented")
+}
+func (UnimplementedUserServiceServer) ConfirmEmailChange(context.Context, *ConfirmEmailChangeRequest) (*ConfirmEmailChangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmEmailChange not implemented")
+}
+func (UnimplementedUserServiceServer) RollbackEmailChange(context.Context, *RollbackEmailChangeRequest) (*RollbackEmailChangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RollbackEmailChange not implemented")
+}
+func (UnimplementedUserServiceServer) ChangePassword(context.Context, *ChangePasswordRequest) (*ChangePasswordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangePassword not implemented")
+}
+func (UnimplementedUserServiceServer) GetNotificationPreferences(context.Context, *GetNotificationPreferencesRequest) (*GetNotificationPreferencesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNotificationPreferences not implemented")
+}
+func (UnimplementedUserServiceServer) UpdateNotificationPreferences(context.Context, *UpdateNotificationPreferencesRequest) (*UpdateNotificationPreferencesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateNotificationPreferences not implemented")
+}
+func (UnimplementedUserServiceServer) AdminRevokeUserSessions(context.Context, *AdminRevokeUserSessionsRequest) (*AdminRevokeUserSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdminRevokeUserSessions not implemented")
+}
+func (UnimplementedUserServiceServer) AdminRevokeSession(context.Context, *AdminRevokeSessionRequest) (*AdminRevokeSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdminRevokeSession not implemented")
+}
+func (UnimplementedUserServiceServer) AdminInviteUser(context.Context, *AdminInviteUserRequest) (*AdminInviteUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdminInviteUser not implemented")
+}
+func (UnimplementedUserServiceServer) AdminListFeatureFlags(context.Context, *AdminListFeatureFlagsRequest) (*AdminListFeatureFlagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdminListFeatureFlags not implemented")
+}
+func (UnimplementedUserServiceServer) AdminSetFeatureFlag(context.Context, *AdminSetFeatureFlagRequest) (*AdminSetFeatureFlagResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdminSetFeatureFlag not implemented")
+}
+func (UnimplementedUserServiceServer) AdminListFailpoints(context.Context, *AdminListFailpointsRequest) (*AdminListFailpointsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdminListFailpoints not implemented")
+}
+func (UnimplementedUserServiceServer) AdminSetFailpoint(context.Context, *AdminSetFailpointRequest) (*AdminSetFailpointResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdminSetFailpoint not implemented")
+}
+func (UnimplementedUserServiceServer) AdminGetRuntimeConfig(context.Context, *AdminGetRuntimeConfigRequest) (*AdminGetRuntimeConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdminGetRuntimeConfig not implemented")
+}
+func (UnimplementedUserServiceServer) AdminListOAuthStates(context.Context, *AdminListOAuthStatesRequest) (*AdminListOAuthStatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdminListOAuthStates not implemented")
+}
+func (UnimplementedUserServiceServer) AdminPurgeOAuthStates(context.Context, *AdminPurgeOAuthStatesRequest) (*AdminPurgeOAuthStatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdminPurgeOAuthStates not implemented")
+}
+func (UnimplementedUserServiceServer) AdminSendSecurityNotice(context.Context, *AdminSendSecurityNoticeRequest) (*AdminSendSecurityNoticeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdminSendSecurityNotice not implemented")
+}
+func (UnimplementedUserServiceServer) AdminUpdateUserEntitlements(context.Context, *AdminUpdateUserEntitlementsRequest) (*AdminUpdateUserEntitlementsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdminUpdateUserEntitlements not implemented")
+}
+func (UnimplementedUserServiceServer) GetKeyUsage(context.Context, *GetKeyUsageRequest) (*GetKeyUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetKeyUsage not implemented")
+}
+func (UnimplementedUserServiceServer) GetLoginSLO(context.Context, *GetLoginSLORequest) (*GetLoginSLOResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLoginSLO not implemented")
+}
+func (UnimplementedUserServiceServer) CreateReport(context.Context, *CreateReportRequest) (*CreateReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateReport not implemented")
+}
+func (UnimplementedUserServiceServer) ListReports(context.Context, *ListReportsRequest) (*ListReportsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReports not implemented")
+}
+func (UnimplementedUserServiceServer) DownloadReport(context.Context, *DownloadReportRequest) (*DownloadReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DownloadReport not implemented")
+}
+func (UnimplementedUserServiceServer) GetReportContent(context.Context, *GetReportContentRequest) (*httpbody.HttpBody, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReportContent not implemented")
+}
+func (UnimplementedUserServiceServer) mustEmbedUnimplementedUserServiceServer() {}
+func (UnimplementedUserServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeUserServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to UserServiceServer will
+// result in compilation errors.
+type UnsafeUserServiceServer interface {
+	mustEmbedUnimplementedUserServiceServer()
+}
+
+func RegisterUserServiceServer(s grpc.ServiceRegistrar, srv UserServiceServer) {
+	// If the following call pancis, it indicates UnimplementedUserServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&UserService_ServiceDesc, srv)
+}
+
+func _UserService_CreateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).CreateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_CreateUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).CreateUser(ctx, req.(*CreateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_GetCurrentUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCurrentUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).GetCurrentUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_GetCurrentUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).GetCurrentUser(ctx, req.(*GetCurrentUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_GetUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_GetUserByEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserByEmailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).GetUserByEmail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_GetUserByEmail_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).GetUserByEmail(ctx, req.(*GetUserByEmailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_BatchGetUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).BatchGetUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_BatchGetUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).BatchGetUsers(ctx, req.(*BatchGetUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_ListUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).ListUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_ListUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).ListUsers(ctx, req.(*ListUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_SearchUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).SearchUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_SearchUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).SearchUsers(ctx, req.(*SearchUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_ListInactiveUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListInactiveUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).ListInactiveUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_ListInactiveUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).ListInactiveUsers(ctx, req.(*ListInactiveUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_ExportUsers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportUsersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UserServiceServer).ExportUsers(m, &grpc.GenericServerStream[ExportUsersRequest, ExportUsersResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type UserService_ExportUsersServer = grpc.ServerStreamingServer[ExportUsersResponse]
+
+func _UserService_UpdateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).UpdateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_UpdateUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).UpdateUser(ctx, req.(*UpdateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_DeleteUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).DeleteUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_DeleteUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).DeleteUser(ctx, req.(*DeleteUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_ListMyIdentities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMyIdentitiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).ListMyIdentities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_ListMyIdentities_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).ListMyIdentities(ctx, req.(*ListMyIdentitiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_RequestAccountDeletion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestAccountDeletionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).RequestAccountDeletion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_RequestAccountDeletion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).RequestAccountDeletion(ctx, req.(*RequestAccountDeletionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_CancelAccountDeletion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelAccountDeletionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).CancelAccountDeletion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_CancelAccountDeletion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).CancelAccountDeletion(ctx, req.(*CancelAccountDeletionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_RequestEmailChange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestEmailChangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).RequestEmailChange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_RequestEmailChange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).RequestEmailChange(ctx, req.(*RequestEmailChangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_ConfirmEmailChange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmEmailChangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).ConfirmEmailChange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_ConfirmEmailChange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).ConfirmEmailChange(ctx, req.(*ConfirmEmailChangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_RollbackEmailChange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackEmailChangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).RollbackEmailChange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_RollbackEmailChange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).RollbackEmailChange(ctx, req.(*RollbackEmailChangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_ChangePassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangePasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).ChangePassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_ChangePassword_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).ChangePassword(ctx, req.(*ChangePasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_GetNotificationPreferences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNotificationPreferencesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).GetNotificationPreferences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_GetNotificationPreferences_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).GetNotificationPreferences(ctx, req.(*GetNotificationPreferencesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_UpdateNotificationPreferences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateNotificationPreferencesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).UpdateNotificationPreferences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_UpdateNotificationPreferences_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).UpdateNotificationPreferences(ctx, req.(*UpdateNotificationPreferencesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_AdminRevokeUserSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminRevokeUserSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).AdminRevokeUserSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_AdminRevokeUserSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).AdminRevokeUserSessions(ctx, req.(*AdminRevokeUserSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_AdminRevokeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminRevokeSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).AdminRevokeSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_AdminRevokeSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).AdminRevokeSession(ctx, req.(*AdminRevokeSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_AdminInviteUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminInviteUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).AdminInviteUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_AdminInviteUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).AdminInviteUser(ctx, req.(*AdminInviteUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_AdminListFeatureFlags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminListFeatureFlagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).AdminListFeatureFlags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_AdminListFeatureFlags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).AdminListFeatureFlags(ctx, req.(*AdminListFeatureFlagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_AdminSetFeatureFlag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminSetFeatureFlagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).AdminSetFeatureFlag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_AdminSetFeatureFlag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).AdminSetFeatureFlag(ctx, req.(*AdminSetFeatureFlagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_AdminListFailpoints_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminListFailpointsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).AdminListFailpoints(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_AdminListFailpoints_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).AdminListFailpoints(ctx, req.(*AdminListFailpointsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_AdminSetFailpoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminSetFailpointRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).AdminSetFailpoint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_AdminSetFailpoint_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).AdminSetFailpoint(ctx, req.(*AdminSetFailpointRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_AdminGetRuntimeConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminGetRuntimeConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).AdminGetRuntimeConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_AdminGetRuntimeConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).AdminGetRuntimeConfig(ctx, req.(*AdminGetRuntimeConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_AdminListOAuthStates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminListOAuthStatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).AdminListOAuthStates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_AdminListOAuthStates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).AdminListOAuthStates(ctx, req.(*AdminListOAuthStatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_AdminPurgeOAuthStates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminPurgeOAuthStatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).AdminPurgeOAuthStates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_AdminPurgeOAuthStates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).AdminPurgeOAuthStates(ctx, req.(*AdminPurgeOAuthStatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_AdminSendSecurityNotice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminSendSecurityNoticeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).AdminSendSecurityNotice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_AdminSendSecurityNotice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).AdminSendSecurityNotice(ctx, req.(*AdminSendSecurityNoticeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_AdminUpdateUserEntitlements_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminUpdateUserEntitlementsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).AdminUpdateUserEntitlements(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_AdminUpdateUserEntitlements_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).AdminUpdateUserEntitlements(ctx, req.(*AdminUpdateUserEntitlementsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_GetKeyUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetKeyUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).GetKeyUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_GetKeyUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).GetKeyUsage(ctx, req.(*GetKeyUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_GetLoginSLO_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLoginSLORequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).GetLoginSLO(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_GetLoginSLO_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).GetLoginSLO(ctx, req.(*GetLoginSLORequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_CreateReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).CreateReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_CreateReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).CreateReport(ctx, req.(*CreateReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_ListReports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReportsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).ListReports(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_ListReports_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).ListReports(ctx, req.(*ListReportsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_DownloadReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DownloadReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).DownloadReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_DownloadReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).DownloadReport(ctx, req.(*DownloadReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_GetReportContent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReportContentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).GetReportContent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_GetReportContent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).GetReportContent(ctx, req.(*GetReportContentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// UserService_ServiceDesc is the grpc.ServiceDesc for UserService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var UserService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "user.v1.UserService",
+	HandlerType: (*UserServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateUser",
+			Handler:    _UserService_CreateUser_Handler,
+		},
+		{
+			MethodName: "GetCurrentUser",
+			Handler:    _UserService_GetCurrentUser_Handler,
+		},
+		{
+			MethodName: "GetUser",
+			Handler:    _UserService_GetUser_Handler,
+		},
+		{
+			MethodName: "GetUserByEmail",
+			Handler:    _UserService_GetUserByEmail_Handler,
+		},
+		{
+			MethodName: "BatchGetUsers",
+			Handler:    _UserService_BatchGetUsers_Handler,
+		},
+		{
+			MethodName: "ListUsers",
+			Handler:    _UserService_ListUsers_Handler,
+		},
+		{
+			MethodName: "SearchUsers",
+			Handler:    _UserService_SearchUsers_Handler,
+		},
+		{
+			MethodName: "ListInactiveUsers",
+			Handler:    _UserService_ListInactiveUsers_Handler,
+		},
+		{
+			MethodName: "UpdateUser",
+			Handler:    _UserService_UpdateUser_Handler,
+		},
+		{
+			MethodName: "DeleteUser",
+			Handler:    _UserService_DeleteUser_Handler,
+		},
+		{
+			MethodName: "ListMyIdentities",
+			Handler:    _UserService_ListMyIdentities_Handler,
+		},
+		{
+			MethodName: "RequestAccountDeletion",
+			Handler:    _UserService_RequestAccountDeletion_Handler,
+		},
+		{
+			MethodName: "CancelAccountDeletion",
+			Handler:    _UserService_CancelAccountDeletion_Handler,
+		},
+		{
+			MethodName: "RequestEmailChange",
+			Handler:    _UserService_RequestEmailChange_Handler,
+		},
+		{
+			MethodName: "ConfirmEmailChange",
+			Handler:    _UserService_ConfirmEmailChange_Handler,
+		},
+		{
+			MethodName: "RollbackEmailChange",
+			Handler:    _UserService_RollbackEmailChange_Handler,
+		},
+		{
+			MethodName: "ChangePassword",
+			Handler:    _UserService_ChangePassword_Handler,
+		},
+		{
+			MethodName: "GetNotificationPreferences",
+			Handler:    _UserService_GetNotificationPreferences_Handler,
+		},
+		{
+			MethodName: "UpdateNotificationPreferences",
+			Handler:    _UserService_UpdateNotificationPreferences_Handler,
+		},
+		{
+			MethodName: "AdminRevokeUserSessions",
+			Handler:    _UserService_AdminRevokeUserSessions_Handler,
+		},
+		{
+			MethodName: "AdminRevokeSession",
+			Handler:    _UserService_AdminRevokeSession_Handler,
+		},
+		{
+			MethodName: "AdminInviteUser",
+			Handler:    _UserService_AdminInviteUser_Handler,
+		},
+		{
+			MethodName: "AdminListFeatureFlags",
+			Handler:    _UserService_AdminListFeatureFlags_Handler,
+		},
+		{
+			MethodName: "AdminSetFeatureFlag",
+			Handler:    _UserService_AdminSetFeatureFlag_Handler,
+		},
+		{
+			MethodName: "AdminListFailpoints",
+			Handler:    _UserService_AdminListFailpoints_Handler,
+		},
+		{
+			MethodName: "AdminSetFailpoint",
+			Handler:    _UserService_AdminSetFailpoint_Handler,
+		},
+		{
+			MethodName: "AdminGetRuntimeConfig",
+			Handler:    _UserService_AdminGetRuntimeConfig_Handler,
+		},
+		{
+			MethodName: "AdminListOAuthStates",
+			Handler:    _UserService_AdminListOAuthStates_Handler,
+		},
+		{
+			MethodName: "AdminPurgeOAuthStates",
+			Handler:    _UserService_AdminPurgeOAuthStates_Handler,
+		},
+		{
+			MethodName: "AdminSendSecurityNotice",
+			Handler:    _UserService_AdminSendSecurityNotice_Handler,
+		},
+		{
+			MethodName: "AdminUpdateUserEntitlements",
+			Handler:    _UserService_AdminUpdateUserEntitlements_Handler,
+		},
+		{
+			MethodName: "GetKeyUsage",
+			Handler:    _UserService_GetKeyUsage_Handler,
+		},
+		{
+			MethodName: "GetLoginSLO",
+			Handler:    _UserService_GetLoginSLO_Handler,
+		},
+		{
+			MethodName: "CreateReport",
+			Handler:    _UserService_CreateReport_Handler,
+		},
+		{
+			MethodName: "ListReports",
+			Handler:    _UserService_ListReports_Handler,
+		},
+		{
+			MethodName: "DownloadReport",
+			Handler:    _UserService_DownloadReport_Handler,
+		},
+		{
+			MethodName: "GetReportContent",
+			Handler:    _UserService_GetReportContent_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportUsers",
+			Handler:       _UserService_ExportUsers_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "user/v1/user.proto",
+}
+
+const (
+	TenantSettingsService_ListTenantSettings_FullMethodName    = "/user.v1.TenantSettingsService/ListTenantSettings"
+	TenantSettingsService_GetTenantSettings_FullMethodName     = "/user.v1.TenantSettingsService/GetTenantSettings"
+	TenantSettingsService_UpdateTenantSettings_FullMethodName  = "/user.v1.TenantSettingsService/UpdateTenantSettings"
+	TenantSettingsService_DeleteTenantSettings_FullMethodName  = "/user.v1.TenantSettingsService/DeleteTenantSettings"
+	TenantSettingsService_GetTenantPublicConfig_FullMethodName = "/user.v1.TenantSettingsService/GetTenantPublicConfig"
+)
+
+// TenantSettingsServiceClient is the client API for TenantSettingsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TenantSettingsService holds the settings of tenants, the organizations users
+// belong to (see User.org). They can only restrict the configuration of the
+// deployment, e.g. shorten session lifetimes.
+type TenantSettingsServiceClient interface {
+	// ListTenantSettings returns the settings of the tenants that have any
+	ListTenantSettings(ctx context.Context, in *ListTenantSettingsRequest, opts ...grpc.CallOption) (*ListTenantSettingsResponse, error)
+	// GetTenantSettings returns the settings of a tenant, the defaults if it has none
+	GetTenantSettings(ctx context.Context, in *GetTenantSettingsRequest, opts ...grpc.CallOption) (*GetTenantSettingsResponse, error)
+	UpdateTenantSettings(ctx context.Context, in *UpdateTenantSettingsRequest, opts ...grpc.CallOption) (*UpdateTenantSettingsResponse, error)
+	// DeleteTenantSettings resets the settings of a tenant to the defaults
+	DeleteTenantSettings(ctx context.Context, in *DeleteTenantSettingsRequest, opts ...grpc.CallOption) (*DeleteTenantSettingsResponse, error)
+	// GetTenantPublicConfig describes the branding and login methods of a
+	// tenant for its login page. Unknown tenants get the defaults, so tenants
+	// can't be enumerated.
+	GetTenantPublicConfig(ctx context.Context, in *GetTenantPublicConfigRequest, opts ...grpc.CallOption) (*GetTenantPublicConfigResponse, error)
+}
+
+type tenantSettingsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTenantSettingsServiceClient(cc grpc.ClientConnInterface) TenantSettingsServiceClient {
+	return &tenantSettingsServiceClient{cc}
+}
+
+func (c *tenantSettingsServiceClient) ListTenantSettings(ctx context.Context, in *ListTenantSettingsRequest, opts ...grpc.CallOption) (*ListTenantSettingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTenantSettingsResponse)
+	err := c.cc.Invoke(ctx, TenantSettingsService_ListTenantSettings_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *userServiceClient) UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*UpdateUserResponse, error) {
+func (c *tenantSettingsServiceClient) GetTenantSettings(ctx context.Context, in *GetTenantSettingsRequest, opts ...grpc.CallOption) (*GetTenantSettingsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(UpdateUserResponse)
-	err := c.cc.Invoke(ctx, UserService_UpdateUser_FullMethodName, in, out, cOpts...)
+	out := new(GetTenantSettingsResponse)
+	err := c.cc.Invoke(ctx, TenantSettingsService_GetTenantSettings_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *userServiceClient) DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*DeleteUserResponse, error) {
+func (c *tenantSettingsServiceClient) UpdateTenantSettings(ctx context.Context, in *UpdateTenantSettingsRequest, opts ...grpc.CallOption) (*UpdateTenantSettingsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(DeleteUserResponse)
-	err := c.cc.Invoke(ctx, UserService_DeleteUser_FullMethodName, in, out, cOpts...)
+	out := new(UpdateTenantSettingsResponse)
+	err := c.cc.Invoke(ctx, TenantSettingsService_UpdateTenantSettings_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// UserServiceServer is the server API for UserService service.
-// All implementations must embed UnimplementedUserServiceServer
+func (c *tenantSettingsServiceClient) DeleteTenantSettings(ctx context.Context, in *DeleteTenantSettingsRequest, opts ...grpc.CallOption) (*DeleteTenantSettingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteTenantSettingsResponse)
+	err := c.cc.Invoke(ctx, TenantSettingsService_DeleteTenantSettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tenantSettingsServiceClient) GetTenantPublicConfig(ctx context.Context, in *GetTenantPublicConfigRequest, opts ...grpc.CallOption) (*GetTenantPublicConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTenantPublicConfigResponse)
+	err := c.cc.Invoke(ctx, TenantSettingsService_GetTenantPublicConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TenantSettingsServiceServer is the server API for TenantSettingsService service.
+// All implementations must embed UnimplementedTenantSettingsServiceServer
 // for forward compatibility.
-type UserServiceServer interface {
-	CreateUser(context.Context, *CreateUserRequest) (*CreateUserResponse, error)
-	GetCurrentUser(context.Context, *GetCurrentUserRequest) (*GetCurrentUserResponse, error)
-	GetUser(context.Context, *GetUserRequest) (*GetUserResponse, error)
-	ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error)
-	UpdateUser(context.Context, *UpdateUserRequest) (*UpdateUserResponse, error)
-	DeleteUser(context.Context, *DeleteUserRequest) (*DeleteUserResponse, error)
-	mustEmbedUnimplementedUserServiceServer()
+//
+// TenantSettingsService holds the settings of tenants, the organizations users
+// belong to (see User.org). They can only restrict the configuration of the
+// deployment, e.g. shorten session lifetimes.
+type TenantSettingsServiceServer interface {
+	// ListTenantSettings returns the settings of the tenants that have any
+	ListTenantSettings(context.Context, *ListTenantSettingsRequest) (*ListTenantSettingsResponse, error)
+	// GetTenantSettings returns the settings of a tenant, the defaults if it has none
+	GetTenantSettings(context.Context, *GetTenantSettingsRequest) (*GetTenantSettingsResponse, error)
+	UpdateTenantSettings(context.Context, *UpdateTenantSettingsRequest) (*UpdateTenantSettingsResponse, error)
+	// DeleteTenantSettings resets the settings of a tenant to the defaults
+	DeleteTenantSettings(context.Context, *DeleteTenantSettingsRequest) (*DeleteTenantSettingsResponse, error)
+	// GetTenantPublicConfig describes the branding and login methods of a
+	// tenant for its login page. Unknown tenants get the defaults, so tenants
+	// can't be enumerated.
+	GetTenantPublicConfig(context.Context, *GetTenantPublicConfigRequest) (*GetTenantPublicConfigResponse, error)
+	mustEmbedUnimplementedTenantSettingsServiceServer()
 }
 
-// UnimplementedUserServiceServer must be embedded to have
+// UnimplementedTenantSettingsServiceServer must be embedded to have
 // forward compatible implementations.
 //
 // NOTE: this should be embedded by value instead of pointer to avoid a nil
 // pointer dereference when methods are called.
-type UnimplementedUserServiceServer struct{}
+type UnimplementedTenantSettingsServiceServer struct{}
 
-func (UnimplementedUserServiceServer) CreateUser(context.Context, *CreateUserRequest) (*CreateUserResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CreateUser not implemented")
-}
-func (UnimplementedUserServiceServer) GetCurrentUser(context.Context, *GetCurrentUserRequest) (*GetCurrentUserResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetCurrentUser not implemented")
+func (UnimplementedTenantSettingsServiceServer) ListTenantSettings(context.Context, *ListTenantSettingsRequest) (*ListTenantSettingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTenantSettings not implemented")
 }
-func (UnimplementedUserServiceServer) GetUser(context.Context, *GetUserRequest) (*GetUserResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+func (UnimplementedTenantSettingsServiceServer) GetTenantSettings(context.Context, *GetTenantSettingsRequest) (*GetTenantSettingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTenantSettings not implemented")
 }
-func (UnimplementedUserServiceServer) ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ListUsers not implemented")
+func (UnimplementedTenantSettingsServiceServer) UpdateTenantSettings(context.Context, *UpdateTenantSettingsRequest) (*UpdateTenantSettingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateTenantSettings not implemented")
 }
-func (UnimplementedUserServiceServer) UpdateUser(context.Context, *UpdateUserRequest) (*UpdateUserResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method UpdateUser not implemented")
+func (UnimplementedTenantSettingsServiceServer) DeleteTenantSettings(context.Context, *DeleteTenantSettingsRequest) (*DeleteTenantSettingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteTenantSettings not implemented")
 }
-func (UnimplementedUserServiceServer) DeleteUser(context.Context, *DeleteUserRequest) (*DeleteUserResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method DeleteUser not implemented")
+func (UnimplementedTenantSettingsServiceServer) GetTenantPublicConfig(context.Context, *GetTenantPublicConfigRequest) (*GetTenantPublicConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTenantPublicConfig not implemented")
 }
-func (UnimplementedUserServiceServer) mustEmbedUnimplementedUserServiceServer() {}
-func (UnimplementedUserServiceServer) testEmbeddedByValue()                     {}
+func (UnimplementedTenantSettingsServiceServer) mustEmbedUnimplementedTenantSettingsServiceServer() {}
+func (UnimplementedTenantSettingsServiceServer) testEmbeddedByValue()                               {}
 
-// UnsafeUserServiceServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to UserServiceServer will
+// UnsafeTenantSettingsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TenantSettingsServiceServer will
 // result in compilation errors.
-type UnsafeUserServiceServer interface {
-	mustEmbedUnimplementedUserServiceServer()
+type UnsafeTenantSettingsServiceServer interface {
+	mustEmbedUnimplementedTenantSettingsServiceServer()
 }
 
-func RegisterUserServiceServer(s grpc.ServiceRegistrar, srv UserServiceServer) {
-	// If the following call pancis, it indicates UnimplementedUserServiceServer was
+func RegisterTenantSettingsServiceServer(s grpc.ServiceRegistrar, srv TenantSettingsServiceServer) {
+	// If the following call pancis, it indicates UnimplementedTenantSettingsServiceServer was
 	// embedded by pointer and is nil.  This will cause panics if an
 	// unimplemented method is ever invoked, so we test this at initialization
 	// time to prevent it from happening at runtime later due to I/O.
 	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
 		t.testEmbeddedByValue()
 	}
-	s.RegisterService(&UserService_ServiceDesc, srv)
-}
-
-func _UserService_CreateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateUserRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(UserServiceServer).CreateUser(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: UserService_CreateUser_FullMethodName,
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(UserServiceServer).CreateUser(ctx, req.(*CreateUserRequest))
-	}
-	return interceptor(ctx, in, info, handler)
+	s.RegisterService(&TenantSettingsService_ServiceDesc, srv)
 }
 
-func _UserService_GetCurrentUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetCurrentUserRequest)
+func _TenantSettingsService_ListTenantSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTenantSettingsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(UserServiceServer).GetCurrentUser(ctx, in)
+		return srv.(TenantSettingsServiceServer).ListTenantSettings(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: UserService_GetCurrentUser_FullMethodName,
+		FullMethod: TenantSettingsService_ListTenantSettings_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(UserServiceServer).GetCurrentUser(ctx, req.(*GetCurrentUserRequest))
+		return srv.(TenantSettingsServiceServer).ListTenantSettings(ctx, req.(*ListTenantSettingsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _UserService_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetUserRequest)
+func _TenantSettingsService_GetTenantSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTenantSettingsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(UserServiceServer).GetUser(ctx, in)
+		return srv.(TenantSettingsServiceServer).GetTenantSettings(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: UserService_GetUser_FullMethodName,
+		FullMethod: TenantSettingsService_GetTenantSettings_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(UserServiceServer).GetUser(ctx, req.(*GetUserRequest))
+		return srv.(TenantSettingsServiceServer).GetTenantSettings(ctx, req.(*GetTenantSettingsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _UserService_ListUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ListUsersRequest)
+func _TenantSettingsService_UpdateTenantSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTenantSettingsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(UserServiceServer).ListUsers(ctx, in)
+		return srv.(TenantSettingsServiceServer).UpdateTenantSettings(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: UserService_ListUsers_FullMethodName,
+		FullMethod: TenantSettingsService_UpdateTenantSettings_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(UserServiceServer).ListUsers(ctx, req.(*ListUsersRequest))
+		return srv.(TenantSettingsServiceServer).UpdateTenantSettings(ctx, req.(*UpdateTenantSettingsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _UserService_UpdateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UpdateUserRequest)
+func _TenantSettingsService_DeleteTenantSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTenantSettingsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(UserServiceServer).UpdateUser(ctx, in)
+		return srv.(TenantSettingsServiceServer).DeleteTenantSettings(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: UserService_UpdateUser_FullMethodName,
+		FullMethod: TenantSettingsService_DeleteTenantSettings_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(UserServiceServer).UpdateUser(ctx, req.(*UpdateUserRequest))
+		return srv.(TenantSettingsServiceServer).DeleteTenantSettings(ctx, req.(*DeleteTenantSettingsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _UserService_DeleteUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteUserRequest)
+func _TenantSettingsService_GetTenantPublicConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTenantPublicConfigRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(UserServiceServer).DeleteUser(ctx, in)
+		return srv.(TenantSettingsServiceServer).GetTenantPublicConfig(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: UserService_DeleteUser_FullMethodName,
+		FullMethod: TenantSettingsService_GetTenantPublicConfig_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(UserServiceServer).DeleteUser(ctx, req.(*DeleteUserRequest))
+		return srv.(TenantSettingsServiceServer).GetTenantPublicConfig(ctx, req.(*GetTenantPublicConfigRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-// UserService_ServiceDesc is the grpc.ServiceDesc for UserService service.
+// TenantSettingsService_ServiceDesc is the grpc.ServiceDesc for TenantSettingsService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
-var UserService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "user.v1.UserService",
-	HandlerType: (*UserServiceServer)(nil),
+var TenantSettingsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "user.v1.TenantSettingsService",
+	HandlerType: (*TenantSettingsServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "CreateUser",
-			Handler:    _UserService_CreateUser_Handler,
-		},
-		{
-			MethodName: "GetCurrentUser",
-			Handler:    _UserService_GetCurrentUser_Handler,
+			MethodName: "ListTenantSettings",
+			Handler:    _TenantSettingsService_ListTenantSettings_Handler,
 		},
 		{
-			MethodName: "GetUser",
-			Handler:    _UserService_GetUser_Handler,
+			MethodName: "GetTenantSettings",
+			Handler:    _TenantSettingsService_GetTenantSettings_Handler,
 		},
 		{
-			MethodName: "ListUsers",
-			Handler:    _UserService_ListUsers_Handler,
+			MethodName: "UpdateTenantSettings",
+			Handler:    _TenantSettingsService_UpdateTenantSettings_Handler,
 		},
 		{
-			MethodName: "UpdateUser",
-			Handler:    _UserService_UpdateUser_Handler,
+			MethodName: "DeleteTenantSettings",
+			Handler:    _TenantSettingsService_DeleteTenantSettings_Handler,
 		},
 		{
-			MethodName: "DeleteUser",
-			Handler:    _UserService_DeleteUser_Handler,
+			MethodName: "GetTenantPublicConfig",
+			Handler:    _TenantSettingsService_GetTenantPublicConfig_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
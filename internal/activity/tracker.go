@@ -0,0 +1,80 @@
+// Package activity records when users were last seen, at most once per
+// interval so busy clients don't turn every call into a database write.
+package activity
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/redis/go-redis/v9"
+)
+
+// Tracker updates last_seen_at of users. A Redis key per user makes servers
+// agree on who writes in each interval; a local cache spares most calls the
+// Redis round trip.
+type Tracker struct {
+	rdb      redis.UniversalClient
+	users    repository.UserRepository
+	interval time.Duration
+
+	mu      sync.Mutex
+	written map[string]time.Time
+}
+
+func NewTracker(
+	rdb redis.UniversalClient,
+	users repository.UserRepository,
+	interval time.Duration,
+) *Tracker {
+	return &Tracker{
+		rdb:      rdb,
+		users:    users,
+		interval: interval,
+		written:  make(map[string]time.Time),
+	}
+}
+
+// RecordActivity marks the user as seen now, unless that was already recorded
+// within the interval. Failures are only logged, since activity tracking must
+// never fail a call.
+func (t *Tracker) RecordActivity(ctx context.Context, userID string) {
+	now := time.Now()
+	if !t.due(userID, now) {
+		return
+	}
+	claimed, err := t.rdb.SetNX(ctx, "last_seen:"+userID, 1, t.interval).Result()
+	if err != nil {
+		slog.WarnContext(ctx, "failed to claim last seen update", "error", err, "user_id", userID)
+		return
+	}
+	if !claimed {
+		return
+	}
+	if err := t.users.TouchLastSeen(ctx, userID, now); err != nil {
+		slog.WarnContext(ctx, "failed to update last seen", "error", err, "user_id", userID)
+	}
+}
+
+// due reports whether this server hasn't handled the user within the interval,
+// and if so remembers it did now.
+func (t *Tracker) due(userID string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if last, ok := t.written[userID]; ok && now.Sub(last) < t.interval {
+		return false
+	}
+	// Drop expired entries now and then, so the cache doesn't grow with every
+	// user ever seen
+	if len(t.written) >= 10000 {
+		for id, last := range t.written {
+			if now.Sub(last) >= t.interval {
+				delete(t.written, id)
+			}
+		}
+	}
+	t.written[userID] = now
+	return true
+}
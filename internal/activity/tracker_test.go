@@ -0,0 +1,38 @@
+package activity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+)
+
+func TestTracker(t *testing.T) {
+	rdb, mr := testutil.NewRedis(t)
+	users := testutil.NewUserRepository(&model.UserModel{ID: "user-1", Email: "a@example.com"})
+	ctx := context.Background()
+	lastSeen := func() *time.Time {
+		user, _ := users.GetByID(ctx, "user-1")
+		return user.LastSeenAt
+	}
+
+	NewTracker(rdb, users, time.Hour).RecordActivity(ctx, "user-1")
+	first := lastSeen()
+	if first == nil {
+		t.Fatal("expected the first activity to be recorded")
+	}
+
+	// Another server within the interval leaves the timestamp alone
+	NewTracker(rdb, users, time.Hour).RecordActivity(ctx, "user-1")
+	if !lastSeen().Equal(*first) {
+		t.Error("expected activity within the interval not to be written again")
+	}
+
+	mr.FastForward(time.Hour)
+	NewTracker(rdb, users, time.Hour).RecordActivity(ctx, "user-1")
+	if !lastSeen().After(*first) {
+		t.Error("expected activity after the interval to be written")
+	}
+}
@@ -0,0 +1,74 @@
+// Package buildinfo describes the build of the running binary. Release builds
+// set it with ldflags, e.g.
+//
+//	go build -ldflags "-X github.com/poly-workshop/auth-portal/internal/buildinfo.version=v1.4.0
+//	  -X github.com/poly-workshop/auth-portal/internal/buildinfo.commit=$(git rev-parse HEAD)
+//	  -X github.com/poly-workshop/auth-portal/internal/buildinfo.date=$(date -u +%FT%TZ)"
+//
+// as the Makefile does; otherwise the commit and date are taken from the VCS
+// information the go command stamps into binaries built in a checkout.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Set with ldflags
+var (
+	version = "dev"
+	commit  string
+	date    string
+)
+
+// Info is the build of the running binary.
+type Info struct {
+	// Version is the release, "dev" if the build set none
+	Version string
+	// Commit is the revision built, with a "-dirty" suffix if the checkout was
+	// modified; empty if unknown
+	Commit string
+	// Date is when the binary was built or, failing that, committed, in RFC
+	// 3339; empty if unknown
+	Date      string
+	GoVersion string
+}
+
+var get = sync.OnceValue(func() Info {
+	info := Info{Version: version, Commit: commit, Date: date, GoVersion: runtime.Version()}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info.fillFromVCS(build.Settings)
+	}
+	return info
+})
+
+// Get returns the build of the running binary.
+func Get() Info {
+	return get()
+}
+
+// fillFromVCS fills the commit and date the ldflags left unset from the VCS
+// build settings.
+func (i *Info) fillFromVCS(settings []debug.BuildSetting) {
+	var revision, modified, time string
+	for _, setting := range settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value
+		case "vcs.time":
+			time = setting.Value
+		}
+	}
+	if i.Commit == "" && revision != "" {
+		i.Commit = revision
+		if modified == "true" {
+			i.Commit += "-dirty"
+		}
+	}
+	if i.Date == "" {
+		i.Date = time
+	}
+}
@@ -0,0 +1,30 @@
+package buildinfo
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestFillFromVCS(t *testing.T) {
+	settings := []debug.BuildSetting{
+		{Key: "vcs.revision", Value: "abc123"},
+		{Key: "vcs.modified", Value: "true"},
+		{Key: "vcs.time", Value: "2026-10-16T12:00:00Z"},
+	}
+
+	var info Info
+	info.fillFromVCS(settings)
+	if info.Commit != "abc123-dirty" || info.Date != "2026-10-16T12:00:00Z" {
+		t.Errorf("expected the VCS commit and date, got %+v", info)
+	}
+
+	info = Info{Commit: "def456", Date: "2026-10-17T08:00:00Z"}
+	info.fillFromVCS(settings)
+	if info.Commit != "def456" || info.Date != "2026-10-17T08:00:00Z" {
+		t.Errorf("expected the ldflags to take precedence, got %+v", info)
+	}
+
+	if got := Get(); got.Version == "" || got.GoVersion == "" {
+		t.Errorf("expected a version and Go version, got %+v", got)
+	}
+}
@@ -0,0 +1,74 @@
+// Package captcha verifies CAPTCHA responses with the siteverify API shared by
+// reCAPTCHA, hCaptcha and Cloudflare Turnstile.
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+)
+
+// Verifier checks the response token a CAPTCHA widget gave the client.
+type Verifier interface {
+	Verify(ctx context.Context, token, remoteIP string) (bool, error)
+}
+
+// NewVerifier returns a siteverify client for cfg, or nil if no provider is
+// configured.
+func NewVerifier(cfg configs.CaptchaConfig) Verifier {
+	if cfg.VerifyURL == "" {
+		return nil
+	}
+	return &siteVerifier{
+		url:    cfg.VerifyURL,
+		secret: cfg.Secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type siteVerifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func (v *siteVerifier) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		v.url,
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("siteverify answered %s", resp.Status)
+	}
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("failed to decode siteverify response: %w", err)
+	}
+	return result.Success, nil
+}
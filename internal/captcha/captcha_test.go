@@ -0,0 +1,33 @@
+package captcha
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/configs"
+)
+
+func TestSiteVerifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok := r.PostFormValue("secret") == "secret" && r.PostFormValue("response") == "solved"
+		_, _ = fmt.Fprintf(w, `{"success": %t}`, ok)
+	}))
+	defer server.Close()
+
+	if NewVerifier(configs.CaptchaConfig{}) != nil {
+		t.Error("expected no verifier without a verify URL")
+	}
+	verifier := NewVerifier(configs.CaptchaConfig{VerifyURL: server.URL, Secret: "secret"})
+	for token, want := range map[string]bool{"solved": true, "wrong": false, "": false} {
+		ok, err := verifier.Verify(context.Background(), token, "10.0.0.1")
+		if err != nil {
+			t.Fatalf("Verify(%q) failed: %v", token, err)
+		}
+		if ok != want {
+			t.Errorf("Verify(%q) = %v, expected %v", token, ok, want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Package clientip determines the address of the client behind a call: the
+// peer itself, or the address a trusted proxy such as the gateway forwarded in
+// X-Forwarded-For. Hops a client adds itself are never taken for its address.
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// InProcessNetwork is the network of the addresses of in-process peers.
+const InProcessNetwork = "inprocess"
+
+// Resolver resolves client addresses, trusting the X-Forwarded-For hops
+// appended by proxies in its networks.
+type Resolver struct {
+	trusted []netip.Prefix
+}
+
+// NewResolver returns a resolver trusting the proxies in cidrs; it fails if a
+// CIDR is invalid.
+func NewResolver(cidrs []string) (*Resolver, error) {
+	trusted := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy CIDR %q: %w", cidr, err)
+		}
+		trusted = append(trusted, prefix.Masked())
+	}
+	return &Resolver{trusted: trusted}, nil
+}
+
+// Trusted reports whether ip is the address of a trusted proxy.
+func (r *Resolver) Trusted(ip netip.Addr) bool {
+	for _, prefix := range r.trusted {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the client address of a call from the peer at addr carrying
+// the X-Forwarded-For values forwardedFor. Calls of untrusted peers come from
+// the peer itself. For trusted proxies the hops are walked from the right, as
+// each proxy appends the address it saw, and the first hop not a trusted proxy
+// is the client. In-process peers, such as the gateway of a binary serving
+// both, are trusted proxies too. It returns "" if the address is unknown or
+// only trusted proxies are involved, so the client cannot be told apart.
+func (r *Resolver) Resolve(addr net.Addr, forwardedFor []string) string {
+	if addr == nil || addr.Network() != InProcessNetwork {
+		ip, ok := addrIP(addr)
+		if !ok {
+			return ""
+		}
+		if !r.Trusted(ip) {
+			return ip.String()
+		}
+	}
+	var hops []string
+	for _, value := range forwardedFor {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// Hops left of one no proxy could have appended are the client's
+			return ""
+		}
+		hop = hop.Unmap()
+		if !r.Trusted(hop) {
+			return hop.String()
+		}
+	}
+	return ""
+}
+
+// ResolveContext resolves the client address of the incoming call of ctx.
+func (r *Resolver) ResolveContext(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	return r.Resolve(p.Addr, md.Get("x-forwarded-for"))
+}
+
+func addrIP(addr net.Addr) (netip.Addr, bool) {
+	var ip net.IP
+	switch addr := addr.(type) {
+	case *net.TCPAddr:
+		ip = addr.IP
+	case *net.UDPAddr:
+		ip = addr.IP
+	default:
+		return netip.Addr{}, false
+	}
+	parsed, ok := netip.AddrFromSlice(ip)
+	return parsed.Unmap(), ok
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying the client address ip, "" if unknown.
+func NewContext(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, contextKey{}, ip)
+}
+
+// FromContext returns the client address of the context and whether it was
+// resolved at all.
+func FromContext(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(contextKey{}).(string)
+	return ip, ok
+}
+
+// UnaryServerInterceptor puts the client address resolved by resolver into the
+// context, where the services throttle, bind and assess logins by it.
+func UnaryServerInterceptor(resolver *Resolver) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		return handler(NewContext(ctx, resolver.ResolveContext(ctx)), req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streams.
+func StreamServerInterceptor(resolver *Resolver) grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx := stream.Context()
+		wrapped := middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = NewContext(ctx, resolver.ResolveContext(ctx))
+		return handler(srv, wrapped)
+	}
+}
@@ -0,0 +1,63 @@
+package clientip
+
+import (
+	"net"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	resolver, err := NewResolver([]string{"10.0.0.0/8", "::1/128"})
+	if err != nil {
+		t.Fatalf("NewResolver failed: %v", err)
+	}
+	proxy := &net.TCPAddr{IP: net.ParseIP("10.1.2.3"), Port: 40000}
+	for name, tc := range map[string]struct {
+		addr         net.Addr
+		forwardedFor []string
+		want         string
+	}{
+		"untrusted peer": {
+			addr:         &net.TCPAddr{IP: net.ParseIP("203.0.113.7")},
+			forwardedFor: []string{"198.51.100.9"},
+			want:         "203.0.113.7",
+		},
+		"proxied": {
+			addr:         proxy,
+			forwardedFor: []string{"198.51.100.9, 203.0.113.7"},
+			want:         "203.0.113.7",
+		},
+		"chain of proxies": {
+			addr:         proxy,
+			forwardedFor: []string{"198.51.100.9", "203.0.113.7, 10.4.5.6"},
+			want:         "203.0.113.7",
+		},
+		"mapped IPv4": {
+			addr:         &net.TCPAddr{IP: net.ParseIP("::1")},
+			forwardedFor: []string{"::ffff:203.0.113.7"},
+			want:         "203.0.113.7",
+		},
+		"in-process": {
+			addr:         inProcessAddr{},
+			forwardedFor: []string{"198.51.100.9, 203.0.113.7"},
+			want:         "203.0.113.7",
+		},
+		"proxy itself":  {addr: proxy, want: ""},
+		"invalid hop":   {addr: proxy, forwardedFor: []string{"203.0.113.7, unknown"}, want: ""},
+		"unknown peer":  {addr: &net.UnixAddr{Name: "/tmp/grpc.sock"}, want: ""},
+		"only proxies":  {addr: proxy, forwardedFor: []string{"10.4.5.6"}, want: ""},
+		"without peers": {want: ""},
+	} {
+		if got := resolver.Resolve(tc.addr, tc.forwardedFor); got != tc.want {
+			t.Errorf("%s: expected %q, got %q", name, tc.want, got)
+		}
+	}
+
+	if _, err := NewResolver([]string{"10.0.0.0"}); err == nil {
+		t.Error("expected an invalid CIDR to be refused")
+	}
+}
+
+type inProcessAddr struct{}
+
+func (inProcessAddr) Network() string { return InProcessNetwork }
+func (inProcessAddr) String() string  { return InProcessNetwork }
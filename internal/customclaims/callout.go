@@ -0,0 +1,79 @@
+package customclaims
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/model"
+)
+
+// maxCalloutResponseSize bounds the callout answers that are read; the claims
+// themselves are bounded by claims.max_bytes.
+const maxCalloutResponseSize = 64 << 10
+
+type calloutRequest struct {
+	UserID string         `json:"user_id"`
+	Email  string         `json:"email"`
+	Role   model.UserRole `json:"role"`
+}
+
+type calloutResponse struct {
+	Claims map[string]any `json:"claims"`
+}
+
+// callout asks an internal HTTP endpoint for the custom claims of users.
+type callout struct {
+	url           string
+	authorization string
+	client        *http.Client
+}
+
+// NewCallout returns the provider calling claims.callout_url, nil if it is not set.
+func NewCallout(cfg configs.ClaimsConfig) Provider {
+	if cfg.CalloutURL == "" {
+		return nil
+	}
+	return &callout{
+		url:           cfg.CalloutURL,
+		authorization: cfg.CalloutAuthorization,
+		client:        &http.Client{Timeout: cfg.CalloutTimeout},
+	}
+}
+
+func (c *callout) Claims(ctx context.Context, user *model.UserModel) (map[string]any, error) {
+	payload, err := json.Marshal(calloutRequest{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   user.Role,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.authorization != "" {
+		req.Header.Set("Authorization", c.authorization)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("claims callout failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("claims callout answered %s", resp.Status)
+	}
+	var answer calloutResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxCalloutResponseSize)).
+		Decode(&answer); err != nil {
+		return nil, fmt.Errorf("invalid claims callout answer: %w", err)
+	}
+	return answer.Claims, nil
+}
@@ -0,0 +1,113 @@
+// Package customclaims adds the custom claims of deployments, e.g. team IDs or
+// quotas kept by another service, to the user tokens the portal issues.
+package customclaims
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrTooLarge is returned for custom claims exceeding claims.max_bytes.
+var ErrTooLarge = errors.New("custom claims exceed the size limit")
+
+// Provider supplies custom claims for the tokens of a user.
+type Provider interface {
+	Claims(ctx context.Context, user *model.UserModel) (map[string]any, error)
+}
+
+// ProviderFunc adapts a function to a Provider.
+type ProviderFunc func(ctx context.Context, user *model.UserModel) (map[string]any, error)
+
+func (f ProviderFunc) Claims(ctx context.Context, user *model.UserModel) (map[string]any, error) {
+	return f(ctx, user)
+}
+
+// reserved are the registered JWT claims and those set by the portal, which
+// custom claims can't override.
+var reserved = map[string]bool{
+	"iss": true, "sub": true, "aud": true, "exp": true, "nbf": true, "iat": true, "jti": true,
+	utils.ClaimUserID:             true,
+	utils.ClaimUserRole:           true,
+	utils.ClaimMustChangePassword: true,
+	utils.ClaimRoleVersion:        true,
+	utils.ClaimSessionRef:         true,
+	utils.ClaimScope:              true,
+	utils.ClaimEntitlements:       true,
+}
+
+// Enricher merges the claims of its providers, bounds their size and caches
+// them per user in Redis.
+type Enricher struct {
+	providers []Provider
+	rdb       redis.UniversalClient
+	cfg       configs.ClaimsConfig
+}
+
+func NewEnricher(
+	rdb redis.UniversalClient,
+	cfg configs.ClaimsConfig,
+	providers ...Provider,
+) *Enricher {
+	return &Enricher{providers: providers, rdb: rdb, cfg: cfg}
+}
+
+func cacheKey(userID string) string {
+	return fmt.Sprintf("custom_claims:%s", userID)
+}
+
+// Claims returns the custom claims of the user, from the cache while it is
+// fresh. Claims of later providers win; reserved claims are dropped.
+func (e *Enricher) Claims(ctx context.Context, user *model.UserModel) (map[string]any, error) {
+	if len(e.providers) == 0 {
+		return nil, nil
+	}
+	if e.cfg.CacheTTL > 0 {
+		data, err := e.rdb.Get(ctx, cacheKey(user.ID)).Bytes()
+		if err == nil {
+			var cached map[string]any
+			if err := json.Unmarshal(data, &cached); err == nil {
+				return cached, nil
+			}
+		} else if !errors.Is(err, redis.Nil) {
+			slog.WarnContext(ctx, "failed to read cached custom claims", "error", err)
+		}
+	}
+
+	claims := make(map[string]any)
+	for _, provider := range e.providers {
+		provided, err := provider.Claims(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(claims, provided)
+	}
+	for name := range claims {
+		if reserved[name] {
+			slog.WarnContext(ctx, "reserved claim dropped from custom claims", "claim", name)
+			delete(claims, name)
+		}
+	}
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	if e.cfg.MaxBytes > 0 && len(data) > e.cfg.MaxBytes {
+		return nil, fmt.Errorf("%w: %d bytes", ErrTooLarge, len(data))
+	}
+
+	if e.cfg.CacheTTL > 0 {
+		if err := e.rdb.Set(ctx, cacheKey(user.ID), data, e.cfg.CacheTTL).Err(); err != nil {
+			slog.WarnContext(ctx, "failed to cache custom claims", "error", err)
+		}
+	}
+	return claims, nil
+}
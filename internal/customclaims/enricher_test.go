@@ -0,0 +1,85 @@
+package customclaims
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+)
+
+func TestEnricher(t *testing.T) {
+	rdb, mr := testutil.NewRedis(t)
+	ctx := context.Background()
+	user := &model.UserModel{ID: "user-1", Email: "user@example.com", Role: model.UserRoleUser}
+	calls := 0
+	teams := []any{"platform"}
+	enricher := NewEnricher(rdb, configs.ClaimsConfig{MaxBytes: 100, CacheTTL: time.Minute},
+		ProviderFunc(func(context.Context, *model.UserModel) (map[string]any, error) {
+			calls++
+			return map[string]any{"teams": teams, "sub": "someone-else"}, nil
+		}),
+	)
+
+	claims, err := enricher.Claims(ctx, user)
+	if err != nil {
+		t.Fatalf("Claims failed: %v", err)
+	}
+	if _, ok := claims["sub"]; ok {
+		t.Errorf("expected reserved claims to be dropped, got %v", claims)
+	}
+	if _, err := enricher.Claims(ctx, user); err != nil || calls != 1 {
+		t.Errorf("expected cached claims, got %d calls, %v", calls, err)
+	}
+
+	mr.FastForward(time.Minute)
+	teams = []any{strings.Repeat("x", 100)}
+	if _, err := enricher.Claims(ctx, user); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected claims over the size limit to be rejected, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the cache to expire, got %d calls", calls)
+	}
+}
+
+func TestCallout(t *testing.T) {
+	if NewCallout(configs.ClaimsConfig{}) != nil {
+		t.Error("expected no callout without a URL")
+	}
+	var got calloutRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"claims": map[string]any{"team_ids": []string{"reports"}},
+		})
+	}))
+	defer srv.Close()
+
+	callout := NewCallout(configs.ClaimsConfig{
+		CalloutURL:           srv.URL,
+		CalloutAuthorization: "Bearer secret",
+		CalloutTimeout:       time.Second,
+	})
+	user := &model.UserModel{ID: "user-1", Email: "user@example.com", Role: model.UserRoleAdmin}
+	claims, err := callout.Claims(context.Background(), user)
+	if err != nil {
+		t.Fatalf("Claims failed: %v", err)
+	}
+	if got.UserID != user.ID || got.Role != model.UserRoleAdmin {
+		t.Errorf("unexpected callout request %+v", got)
+	}
+	if teams, ok := claims["team_ids"].([]any); !ok || teams[0] != "reports" {
+		t.Errorf("expected the team_ids claim, got %v", claims)
+	}
+}
@@ -0,0 +1,162 @@
+package errreport
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/logctx"
+)
+
+// Event is the part of the Sentry event schema filled in from log records.
+type Event struct {
+	ID          string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Message     string            `json:"message,omitempty"`
+	Exception   *exceptions       `json:"exception,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	User        *eventUser        `json:"user,omitempty"`
+	Extra       map[string]any    `json:"extra,omitempty"`
+}
+
+type exceptions struct {
+	Values []exception `json:"values"`
+}
+
+type exception struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type eventUser struct {
+	ID string `json:"id"`
+}
+
+// filtered replaces the values of sensitive attributes.
+const filtered = "[Filtered]"
+
+// sensitiveKeys are attributes that identify people or grant access; their
+// values never leave the server. User IDs are kept, as they are pseudonymous.
+var sensitiveKeys = []string{
+	"email", "ip", "user_agent", "name", "password", "token", "secret",
+	"authorization", "session", "cookie", "code", "state", "github_id",
+}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+func sensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if key == s || strings.HasPrefix(key, s+"_") || strings.HasSuffix(key, "_"+s) {
+			return true
+		}
+	}
+	return false
+}
+
+// scrub removes email addresses from free text such as error messages.
+func scrub(text string) string {
+	return emailPattern.ReplaceAllString(text, filtered)
+}
+
+// newEvent turns an error log record into an event. Its request fields become
+// tags, its "error" (or "panic") attribute the exception and the remaining
+// attributes extra data, without the sensitive ones.
+func newEvent(r slog.Record, attrs []slog.Attr) *Event {
+	event := &Event{
+		Timestamp: r.Time,
+		Level:     "error",
+		Platform:  "go",
+		Message:   scrub(r.Message),
+		Tags:      make(map[string]string),
+		Extra:     make(map[string]any),
+	}
+	add := func(a slog.Attr) {
+		value := a.Value.Resolve()
+		switch a.Key {
+		case logctx.KeyRequestID, logctx.KeyMethod, logctx.KeyTenant, "cmd":
+			event.Tags[a.Key] = value.String()
+		case logctx.KeyUserID:
+			event.User = &eventUser{ID: value.String()}
+		case "error", "panic":
+			errType := a.Key
+			if err, ok := value.Any().(error); ok && a.Key == "error" {
+				errType = fmt.Sprintf("%T", err)
+			}
+			event.Exception = &exceptions{Values: []exception{{
+				Type:  errType,
+				Value: scrub(value.String()),
+			}}}
+		case "hostname":
+			// Sent as the server name
+		default:
+			if sensitive(a.Key) {
+				event.Extra[a.Key] = filtered
+			} else {
+				event.Extra[a.Key] = scrub(value.String())
+			}
+		}
+	}
+	for _, a := range attrs {
+		add(a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		add(a)
+		return true
+	})
+	return event
+}
+
+// Handler reports the records of error level and above, whatever level is
+// logged, and passes the records on. Installed below logctx.Handler, it sees
+// the request fields.
+type Handler struct {
+	slog.Handler
+	reporter *Reporter
+	attrs    []slog.Attr
+}
+
+// Middleware returns a function wrapping log handlers with a Handler, or
+// leaving them as they are if reporter is nil.
+func Middleware(reporter *Reporter) func(slog.Handler) slog.Handler {
+	return func(next slog.Handler) slog.Handler {
+		if reporter == nil {
+			return next
+		}
+		return &Handler{Handler: next, reporter: reporter}
+	}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelError || h.Handler.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelError {
+		h.reporter.Capture(newEvent(r, h.attrs))
+	}
+	if !h.Handler.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{
+		Handler:  h.Handler.WithAttrs(attrs),
+		reporter: h.reporter,
+		attrs:    append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...),
+	}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: h.Handler.WithGroup(name), reporter: h.reporter, attrs: h.attrs}
+}
@@ -0,0 +1,196 @@
+// Package errreport reports errors to Sentry or a compatible service, so that
+// panics and failures logged at error level reach the on-call developers with
+// their request context.
+package errreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// bufferSize bounds the reports waiting to be sent; further ones are dropped.
+const bufferSize = 100
+
+var (
+	sentReports = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "auth_error_reports_sent_total",
+		Help: "Errors delivered to the error reporting service.",
+	})
+	droppedReports = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_error_reports_dropped_total",
+		Help: "Errors that could not be reported, by reason.",
+	}, []string{"reason"})
+)
+
+// Reporter sends events to the envelope endpoint of a Sentry project. Events are
+// sent one by one in the background; when the service cannot keep up, further
+// events are dropped rather than slowing down requests. A nil Reporter drops
+// all events.
+type Reporter struct {
+	endpoint    string
+	auth        string
+	environment string
+	release     string
+	serverName  string
+	client      *http.Client
+	events      chan *Event
+	done        chan struct{}
+}
+
+// NewReporter creates a reporter for the configured DSN, or returns nil if no
+// DSN is configured.
+func NewReporter(cfg configs.ErrorReportingConfig) (*Reporter, error) {
+	if cfg.DSN == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(cfg.DSN)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid error reporting DSN")
+	}
+	key := u.User.Username()
+	// Self-hosted services may serve the API below a path
+	prefix, project := path.Split(u.Path)
+	prefix = strings.TrimSuffix(prefix, "/")
+	if key == "" || project == "" {
+		return nil, fmt.Errorf("error reporting DSN lacks the public key or project ID")
+	}
+
+	release := cfg.Release
+	if release == "" {
+		release = vcsRevision()
+	}
+	hostname, _ := os.Hostname()
+	return &Reporter{
+		endpoint: fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, prefix, project),
+		auth: fmt.Sprintf(
+			"Sentry sentry_version=7, sentry_client=auth-portal/1.0, sentry_key=%s",
+			key,
+		),
+		environment: cfg.Environment,
+		release:     release,
+		serverName:  hostname,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		events:      make(chan *Event, bufferSize),
+		done:        make(chan struct{}),
+	}, nil
+}
+
+// vcsRevision returns the commit the binary was built from, if known.
+func vcsRevision() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return ""
+}
+
+// Start sends queued events in the background until ctx is cancelled.
+func (r *Reporter) Start(ctx context.Context) {
+	if r == nil {
+		return
+	}
+	go r.run(ctx)
+}
+
+// Wait blocks until the reporter has stopped after its context was cancelled.
+func (r *Reporter) Wait() {
+	if r == nil {
+		return
+	}
+	<-r.done
+}
+
+// Capture queues an event without blocking.
+func (r *Reporter) Capture(event *Event) {
+	if r == nil {
+		return
+	}
+	event.ID = strings.ReplaceAll(uuid.NewString(), "-", "")
+	event.Environment = r.environment
+	event.Release = r.release
+	event.ServerName = r.serverName
+	select {
+	case r.events <- event:
+	default:
+		droppedReports.WithLabelValues("buffer_full").Inc()
+	}
+}
+
+func (r *Reporter) run(ctx context.Context) {
+	defer close(r.done)
+	for {
+		select {
+		case event := <-r.events:
+			if err := r.send(ctx, event); err != nil {
+				// Logged below error level, which would be reported again
+				slog.WarnContext(ctx, "failed to report error", "error", err)
+				droppedReports.WithLabelValues("send_error").Inc()
+				continue
+			}
+			sentReports.Inc()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// send posts an event as a Sentry envelope: a header, an item header and the
+// event, each on a line.
+func (r *Reporter) send(ctx context.Context, event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	var body bytes.Buffer
+	header := map[string]string{
+		"event_id": event.ID,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339),
+	}
+	item := map[string]any{"type": "event", "length": len(payload)}
+	for _, part := range []any{header, item} {
+		line, err := json.Marshal(part)
+		if err != nil {
+			return err
+		}
+		body.Write(line)
+		body.WriteByte('\n')
+	}
+	body.Write(payload)
+	body.WriteByte('\n')
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", r.auth)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("error reporting service answered %s", resp.Status)
+	}
+	return nil
+}
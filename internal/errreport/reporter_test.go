@@ -0,0 +1,125 @@
+package errreport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/logctx"
+)
+
+func TestNewReporter(t *testing.T) {
+	for dsn, want := range map[string]string{
+		"https://key@sentry.example.com/42": "https://sentry.example.com/api/42/envelope/",
+		"https://key@example.com/sentry/42": "https://example.com/sentry/api/42/envelope/",
+		"http://key@localhost:9000/a/b/7":   "http://localhost:9000/a/b/api/7/envelope/",
+	} {
+		r, err := NewReporter(configs.ErrorReportingConfig{DSN: dsn})
+		if err != nil {
+			t.Errorf("%s: %v", dsn, err)
+			continue
+		}
+		if r.endpoint != want {
+			t.Errorf("%s: expected endpoint %s, got %s", dsn, want, r.endpoint)
+		}
+	}
+	for _, dsn := range []string{
+		"sentry.example.com/42",
+		"https://sentry.example.com/42",
+		"https://key@example.com/",
+	} {
+		if _, err := NewReporter(configs.ErrorReportingConfig{DSN: dsn}); err == nil {
+			t.Errorf("%s: expected an error", dsn)
+		}
+	}
+	if r, err := NewReporter(configs.ErrorReportingConfig{}); r != nil || err != nil {
+		t.Errorf("expected no reporter without a DSN, got %v, %v", r, err)
+	}
+}
+
+func TestErrorLogsAreReported(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var lines []string
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		received <- r
+		bodies <- lines
+	}))
+	defer srv.Close()
+
+	dsn := strings.Replace(srv.URL, "://", "://public@", 1) + "/7"
+	reporter, err := NewReporter(configs.ErrorReportingConfig{
+		DSN:         dsn,
+		Environment: "test",
+		Release:     "v1.2.3",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		reporter.Wait()
+	}()
+	reporter.Start(ctx)
+
+	logger := slog.New(logctx.NewHandler(Middleware(reporter)(slog.DiscardHandler)))
+	reqCtx := logctx.WithUserID(logctx.WithRequestID(context.Background(), "req-1"), "user-1")
+	logger.InfoContext(reqCtx, "not reported")
+	logger.ErrorContext(reqCtx, "failed to notify alice@example.com",
+		"error", errors.New("smtp: rejected alice@example.com"),
+		"email", "alice@example.com",
+		"ip_address", "192.0.2.1",
+		"attempt", 2)
+
+	var req *http.Request
+	select {
+	case req = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the error to be reported")
+	}
+	lines := <-bodies
+	if req.URL.Path != "/api/7/envelope/" {
+		t.Errorf("unexpected path %s", req.URL.Path)
+	}
+	if auth := req.Header.Get("X-Sentry-Auth"); !strings.Contains(auth, "sentry_key=public") {
+		t.Errorf("expected the public key in the auth header, got %q", auth)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("expected an envelope of 3 lines, got %q", lines)
+	}
+	if strings.Contains(lines[2], "alice") || strings.Contains(lines[2], "192.0.2.1") {
+		t.Errorf("expected personal data to be scrubbed, got %s", lines[2])
+	}
+
+	var event Event
+	if err := json.Unmarshal([]byte(lines[2]), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Environment != "test" || event.Release != "v1.2.3" {
+		t.Errorf("expected environment and release tags, got %q and %q",
+			event.Environment, event.Release)
+	}
+	if event.Tags[logctx.KeyRequestID] != "req-1" ||
+		event.User == nil || event.User.ID != "user-1" {
+		t.Errorf("expected the request fields, got tags %v and user %v", event.Tags, event.User)
+	}
+	if event.Exception == nil || event.Exception.Values[0].Value != "smtp: rejected [Filtered]" {
+		t.Errorf("expected the scrubbed error as exception, got %+v", event.Exception)
+	}
+	if event.Extra["attempt"] != "2" {
+		t.Errorf("expected other attributes as extra data, got %v", event.Extra)
+	}
+}
@@ -0,0 +1,229 @@
+// Package failpoint injects failures and latency into the calls of the server
+// to its dependencies (Redis, the database and the OAuth providers), so the
+// behavior of clients under a degraded auth service can be tried out. It is
+// meant for development: NewRegistry only enables it in the development and
+// test modes.
+package failpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	mathrand "math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+)
+
+// The points failures can be injected into.
+const (
+	// PointRedis is qualified by the command, e.g. "redis:get", or "pipeline"
+	PointRedis = "redis"
+	// PointDB is qualified by the operation: query, create, update, delete,
+	// row or raw
+	PointDB = "db"
+	// PointProvider is qualified by the host called, e.g.
+	// "provider:api.github.com"
+	PointProvider = "provider"
+)
+
+// Points are the points failures can be injected into.
+var Points = []string{PointRedis, PointDB, PointProvider}
+
+// ErrInjected is the error of failed calls.
+var ErrInjected = errors.New("failure injected by failpoint")
+
+// modes are the MODEs failpoints can be enabled in. Any other mode, e.g.
+// "production" or "staging", refuses them, so a deployment naming its mode
+// differently isn't degraded by a stray failpoints section.
+var modes = []string{"development", "test"}
+
+// Failpoint is what happens to the calls of a point.
+type Failpoint struct {
+	// Fail fails the calls with ErrInjected, after Latency
+	Fail    bool
+	Latency time.Duration
+	// Percent of the calls affected, all of them if 0
+	Percent int
+	// ExpiresAt clears the failpoint, so one making the server unusable
+	// doesn't outlive the test; zero never
+	ExpiresAt time.Time
+}
+
+// ParseSpec parses a failpoint as set by failpoints.points, e.g.
+// "redis:get fail,percent=50" or "provider latency=2s,for=10m", into its name
+// and the failpoint.
+func ParseSpec(spec string, now time.Time) (string, Failpoint, error) {
+	name, terms, _ := strings.Cut(strings.TrimSpace(spec), " ")
+	name, err := normalizeName(name)
+	if err != nil {
+		return "", Failpoint{}, err
+	}
+	var fp Failpoint
+	for term := range strings.SplitSeq(terms, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(term), "=")
+		var err error
+		switch key {
+		case "":
+		case "fail":
+			fp.Fail = true
+		case "latency":
+			fp.Latency, err = time.ParseDuration(value)
+		case "percent":
+			fp.Percent, err = strconv.Atoi(value)
+			if err == nil && (fp.Percent < 0 || fp.Percent > 100) {
+				err = errors.New("out of range")
+			}
+		case "for":
+			var d time.Duration
+			d, err = time.ParseDuration(value)
+			fp.ExpiresAt = now.Add(d)
+		default:
+			err = errors.New("unknown term")
+		}
+		if err != nil {
+			return "", Failpoint{}, fmt.Errorf("invalid failpoint %q: %s: %w", spec, term, err)
+		}
+	}
+	if !fp.Fail && fp.Latency <= 0 {
+		return "", Failpoint{}, fmt.Errorf("failpoint %q neither fails nor delays calls", spec)
+	}
+	return name, fp, nil
+}
+
+// normalizeName checks that name is a point, optionally qualified, and
+// returns it in lower case.
+func normalizeName(name string) (string, error) {
+	name = strings.ToLower(name)
+	point, _, _ := strings.Cut(name, ":")
+	if !slices.Contains(Points, point) {
+		return "", fmt.Errorf("unknown failpoint %q, points are %v", name, Points)
+	}
+	return name, nil
+}
+
+// Registry holds the failpoints of a server.
+type Registry struct {
+	// now is time.Now, but for tests
+	now func() time.Time
+
+	mu     sync.RWMutex
+	points map[string]Failpoint
+}
+
+// NewRegistry returns the registry of the failpoints configured by cfg, nil
+// if they are disabled. mode is the MODE the server runs in; failpoints are
+// refused outside of development and test.
+func NewRegistry(cfg configs.FailpointsConfig, mode string) (*Registry, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if !slices.Contains(modes, mode) {
+		return nil, fmt.Errorf("failpoints can't be enabled in mode %q, only in %v", mode, modes)
+	}
+	r := &Registry{now: time.Now, points: make(map[string]Failpoint)}
+	for _, spec := range cfg.Points {
+		name, fp, err := ParseSpec(spec, r.now())
+		if err != nil {
+			return nil, err
+		}
+		r.points[name] = fp
+	}
+	return r, nil
+}
+
+// Set sets the failpoint name, e.g. "redis:get"; a failpoint that neither
+// fails nor delays calls clears it.
+func (r *Registry) Set(name string, fp Failpoint) error {
+	name, err := normalizeName(name)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !fp.Fail && fp.Latency <= 0 {
+		delete(r.points, name)
+	} else {
+		r.points[name] = fp
+	}
+	return nil
+}
+
+// List returns the failpoints set, expired ones left out.
+func (r *Registry) List() map[string]Failpoint {
+	now := r.now()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	points := make(map[string]Failpoint, len(r.points))
+	for name, fp := range r.points {
+		if !fp.expired(now) {
+			points[name] = fp
+		}
+	}
+	return points
+}
+
+func (fp Failpoint) expired(now time.Time) bool {
+	return !fp.ExpiresAt.IsZero() && !now.Before(fp.ExpiresAt)
+}
+
+// lookup returns the failpoint of point qualified by qualifier, else that of
+// point itself.
+func (r *Registry) lookup(point, qualifier string) (Failpoint, bool) {
+	now := r.now()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if fp, ok := r.points[point+":"+strings.ToLower(qualifier)]; ok && !fp.expired(now) {
+		return fp, true
+	}
+	fp, ok := r.points[point]
+	return fp, ok && !fp.expired(now)
+}
+
+// Inject applies the failpoint of point and qualifier to a call: it waits for
+// its latency, then returns ErrInjected if it fails the call.
+func (r *Registry) Inject(ctx context.Context, point, qualifier string) error {
+	if r == nil {
+		return nil
+	}
+	fp, ok := r.lookup(point, qualifier)
+	if !ok || (fp.Percent > 0 && mathrand.IntN(100) >= fp.Percent) {
+		return nil
+	}
+	if fp.Latency > 0 {
+		timer := time.NewTimer(fp.Latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if fp.Fail {
+		return fmt.Errorf("%w: %s:%s", ErrInjected, point, qualifier)
+	}
+	return nil
+}
+
+var installed atomic.Pointer[Registry]
+
+// Install makes r the registry of the hooks of this package; nil disables
+// them.
+func Install(r *Registry) {
+	installed.Store(r)
+}
+
+// Installed returns the installed registry, nil if failpoints are disabled.
+func Installed() *Registry {
+	return installed.Load()
+}
+
+// Inject applies the failpoints of the installed registry to a call.
+func Inject(ctx context.Context, point, qualifier string) error {
+	return Installed().Inject(ctx, point, qualifier)
+}
@@ -0,0 +1,146 @@
+package failpoint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"github.com/poly-workshop/go-webmods/gorm_client"
+)
+
+func TestParseSpec(t *testing.T) {
+	now := time.Now()
+	name, fp, err := ParseSpec("Redis:GET fail,latency=20ms,percent=50,for=1m", now)
+	if err != nil {
+		t.Fatalf("ParseSpec failed: %v", err)
+	}
+	want := Failpoint{
+		Fail:      true,
+		Latency:   20 * time.Millisecond,
+		Percent:   50,
+		ExpiresAt: now.Add(time.Minute),
+	}
+	if name != "redis:get" || fp != want {
+		t.Errorf("unexpected failpoint %q %+v", name, fp)
+	}
+
+	for _, spec := range []string{
+		"cache fail",
+		"redis",
+		"redis percent=50",
+		"redis fail,percent=101",
+		"redis fail,latency=soon",
+		"redis explode",
+	} {
+		if _, _, err := ParseSpec(spec, now); err == nil {
+			t.Errorf("expected %q to be refused", spec)
+		}
+	}
+}
+
+func TestNewRegistry(t *testing.T) {
+	cfg := configs.FailpointsConfig{Points: []string{"db fail"}}
+	if r, err := NewRegistry(cfg, "development"); r != nil || err != nil {
+		t.Errorf("expected no registry while disabled, got %v", err)
+	}
+	cfg.Enabled = true
+	for _, mode := range []string{"production", "staging", ""} {
+		if _, err := NewRegistry(cfg, mode); err == nil {
+			t.Errorf("expected failpoints to be refused in mode %q", mode)
+		}
+	}
+	if _, err := NewRegistry(cfg, "test"); err != nil {
+		t.Errorf("expected failpoints in the test mode, got %v", err)
+	}
+	r, err := NewRegistry(cfg, "development")
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	if _, ok := r.List()["db"]; !ok {
+		t.Errorf("expected the configured failpoint, got %v", r.List())
+	}
+}
+
+func TestInject(t *testing.T) {
+	now := time.Now()
+	r := &Registry{now: func() time.Time { return now }, points: make(map[string]Failpoint)}
+	ctx := context.Background()
+	if err := r.Set("redis", Failpoint{Fail: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Set("redis:get", Failpoint{Latency: time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Inject(ctx, PointRedis, "SET"); !errors.Is(err, ErrInjected) {
+		t.Errorf("expected the point to fail its calls, got %v", err)
+	}
+	if err := r.Inject(ctx, PointRedis, "GET"); err != nil {
+		t.Errorf("expected the qualified failpoint to apply, got %v", err)
+	}
+	if err := r.Inject(ctx, PointDB, "query"); err != nil {
+		t.Errorf("expected other points to be left alone, got %v", err)
+	}
+
+	if err := r.Set("redis", Failpoint{Fail: true, ExpiresAt: now.Add(time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Minute)
+	if err := r.Inject(ctx, PointRedis, "SET"); err != nil {
+		t.Errorf("expected the failpoint to expire, got %v", err)
+	}
+	if err := r.Set("redis:get", Failpoint{}); err != nil || len(r.List()) != 0 {
+		t.Errorf("expected the failpoint to be cleared, got %v (%v)", r.List(), err)
+	}
+	if err := r.Set("cache", Failpoint{Fail: true}); err == nil {
+		t.Error("expected an unknown point to be refused")
+	}
+}
+
+func TestHooks(t *testing.T) {
+	r := &Registry{now: time.Now, points: make(map[string]Failpoint)}
+	Install(r)
+	t.Cleanup(func() { Install(nil) })
+	ctx := context.Background()
+	for _, name := range []string{"redis:get", "db:query", "provider:127.0.0.1"} {
+		if err := r.Set(name, Failpoint{Fail: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rdb, _ := testutil.NewRedis(t)
+	rdb.AddHook(RedisHook{})
+	if err := rdb.Set(ctx, "key", "value", 0).Err(); err != nil {
+		t.Fatalf("expected other commands to pass, got %v", err)
+	}
+	if err := rdb.Get(ctx, "key").Err(); !errors.Is(err, ErrInjected) {
+		t.Errorf("expected GET to fail, got %v", err)
+	}
+
+	db := gorm_client.NewDB(gorm_client.Config{
+		Driver: "sqlite",
+		Name:   filepath.Join(t.TempDir(), "test.db"),
+	})
+	if err := RegisterGorm(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("CREATE TABLE items (id INTEGER)").Error; err != nil {
+		t.Fatalf("expected other operations to pass, got %v", err)
+	}
+	var ids []int
+	if err := db.Table("items").Pluck("id", &ids).Error; !errors.Is(err, ErrInjected) {
+		t.Errorf("expected queries to fail, got %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+	client := &http.Client{Transport: Transport(http.DefaultTransport)}
+	if _, err := client.Get(server.URL); !errors.Is(err, ErrInjected) {
+		t.Errorf("expected the provider call to fail, got %v", err)
+	}
+}
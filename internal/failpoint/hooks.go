@@ -0,0 +1,87 @@
+package failpoint
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// RedisHook injects the redis failpoints into the commands of a client it is
+// added to.
+type RedisHook struct{}
+
+func (RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := Inject(ctx, PointRedis, cmd.Name()); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if err := Inject(ctx, PointRedis, "pipeline"); err != nil {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+			return err
+		}
+		return next(ctx, cmds)
+	}
+}
+
+// RegisterGorm injects the db failpoints into the operations of db.
+func RegisterGorm(db *gorm.DB) error {
+	inject := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			if err := Inject(tx.Statement.Context, PointDB, operation); err != nil {
+				_ = tx.AddError(err)
+			}
+		}
+	}
+	callbacks := db.Callback()
+	for _, register := range []error{
+		callbacks.Query().Before("gorm:query").Register("failpoint:query", inject("query")),
+		callbacks.Create().Before("gorm:create").Register("failpoint:create", inject("create")),
+		callbacks.Update().Before("gorm:update").Register("failpoint:update", inject("update")),
+		callbacks.Delete().Before("gorm:delete").Register("failpoint:delete", inject("delete")),
+		callbacks.Row().Before("gorm:row").Register("failpoint:row", inject("row")),
+		callbacks.Raw().Before("gorm:raw").Register("failpoint:raw", inject("raw")),
+	} {
+		if register != nil {
+			return register
+		}
+	}
+	return nil
+}
+
+// Transport injects the provider failpoints into the requests of next,
+// qualified by their host.
+func Transport(next http.RoundTripper) http.RoundTripper {
+	return transport{next: next}
+}
+
+type transport struct {
+	next http.RoundTripper
+}
+
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Hostname()
+	if err := Inject(req.Context(), PointProvider, host); err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		// Failing like an unreachable provider
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: err}
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,115 @@
+package featureflags
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/redis/go-redis/v9"
+)
+
+// Known flags
+const (
+	// MaintenanceMode refuses logins and new tokens for everyone but admins
+	MaintenanceMode = "maintenance_mode"
+	// SignupBlocked refuses self-service signups; admins can still create users
+	SignupBlocked = "signup_blocked"
+	// SignupInviteOnly only lets invited email addresses sign up
+	SignupInviteOnly = "signup_invite_only"
+)
+
+// Known lists every flag that can be set.
+var Known = []string{MaintenanceMode, SignupBlocked, SignupInviteOnly}
+
+var ErrUnknownFlag = errors.New("unknown feature flag")
+
+// flagsKey is a Redis hash of the flags overridden at runtime.
+const flagsKey = "feature_flags"
+
+// Store resolves feature flags from runtime overrides kept in Redis, falling
+// back to the configured defaults. Values are cached per server for a short
+// time, so flipping a flag takes effect everywhere within the cache duration.
+type Store struct {
+	rdb redis.UniversalClient
+	cfg configs.FeatureFlagsConfig
+
+	mu        sync.Mutex
+	overrides map[string]bool
+	loadedAt  time.Time
+}
+
+func NewStore(rdb redis.UniversalClient, cfg configs.FeatureFlagsConfig) *Store {
+	return &Store{rdb: rdb, cfg: cfg}
+}
+
+// Enabled reports whether the flag is on. If the overrides cannot be read, the
+// configured default is used.
+func (s *Store) Enabled(ctx context.Context, name string) bool {
+	overrides, err := s.loadOverrides(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load feature flags, using defaults", "error", err)
+	}
+	if enabled, ok := overrides[name]; ok {
+		return enabled
+	}
+	return s.cfg.Defaults[name]
+}
+
+// All returns the current value of every known flag.
+func (s *Store) All(ctx context.Context) (map[string]bool, error) {
+	overrides, err := s.loadOverrides(ctx)
+	if err != nil {
+		return nil, err
+	}
+	flags := make(map[string]bool, len(Known))
+	for _, name := range Known {
+		flags[name] = s.cfg.Defaults[name]
+		if enabled, ok := overrides[name]; ok {
+			flags[name] = enabled
+		}
+	}
+	return flags, nil
+}
+
+// Set overrides a flag at runtime. The change is visible to this server right
+// away and to other servers once their cache expires.
+func (s *Store) Set(ctx context.Context, name string, enabled bool) error {
+	if !slices.Contains(Known, name) {
+		return ErrUnknownFlag
+	}
+	if err := s.rdb.HSet(ctx, flagsKey, name, strconv.FormatBool(enabled)).Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Store) loadOverrides(ctx context.Context) (map[string]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loadedAt.IsZero() && time.Since(s.loadedAt) < s.cfg.CacheDuration {
+		return s.overrides, nil
+	}
+
+	values, err := s.rdb.HGetAll(ctx, flagsKey).Result()
+	if err != nil {
+		// Keep serving the last known values rather than flipping back to defaults
+		return s.overrides, err
+	}
+	overrides := make(map[string]bool, len(values))
+	for name, value := range values {
+		if enabled, err := strconv.ParseBool(value); err == nil {
+			overrides[name] = enabled
+		}
+	}
+	s.overrides = overrides
+	s.loadedAt = time.Now()
+	return overrides, nil
+}
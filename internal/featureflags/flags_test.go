@@ -0,0 +1,78 @@
+package featureflags
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestStore(t *testing.T, cfg configs.FeatureFlagsConfig) (*Store, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return NewStore(rdb, cfg), mr
+}
+
+func TestStoreOverridesDefaults(t *testing.T) {
+	store, _ := newTestStore(t, configs.FeatureFlagsConfig{
+		Defaults:      map[string]bool{SignupBlocked: true},
+		CacheDuration: time.Minute,
+	})
+	ctx := context.Background()
+
+	if !store.Enabled(ctx, SignupBlocked) {
+		t.Fatal("expected the configured default to apply")
+	}
+	if err := store.Set(ctx, SignupBlocked, false); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if store.Enabled(ctx, SignupBlocked) {
+		t.Fatal("expected the override to apply right away on this server")
+	}
+
+	flags, err := store.All(ctx)
+	if err != nil {
+		t.Fatalf("All failed: %v", err)
+	}
+	if len(flags) != len(Known) || flags[SignupBlocked] || flags[MaintenanceMode] {
+		t.Errorf("unexpected flags %v", flags)
+	}
+}
+
+func TestStoreCachesOverrides(t *testing.T) {
+	store, mr := newTestStore(t, configs.FeatureFlagsConfig{CacheDuration: time.Minute})
+	ctx := context.Background()
+
+	if store.Enabled(ctx, MaintenanceMode) {
+		t.Fatal("expected flags to be off by default")
+	}
+	// Another server flips the flag
+	mr.HSet(flagsKey, MaintenanceMode, "true")
+	if store.Enabled(ctx, MaintenanceMode) {
+		t.Fatal("expected the cached value until the cache expires")
+	}
+}
+
+func TestStoreRejectsUnknownFlags(t *testing.T) {
+	store, _ := newTestStore(t, configs.FeatureFlagsConfig{})
+	err := store.Set(context.Background(), "unknown", true)
+	if !errors.Is(err, ErrUnknownFlag) {
+		t.Fatalf("expected ErrUnknownFlag, got %v", err)
+	}
+}
+
+func TestStoreFallsBackToDefaults(t *testing.T) {
+	store, mr := newTestStore(t, configs.FeatureFlagsConfig{
+		Defaults: map[string]bool{MaintenanceMode: true},
+	})
+	mr.Close()
+	if !store.Enabled(context.Background(), MaintenanceMode) {
+		t.Fatal("expected the default when redis is unavailable")
+	}
+}
@@ -0,0 +1,192 @@
+// Package fieldcrypt encrypts sensitive columns in the application with
+// AES-256-GCM, so they are unreadable in database dumps and backups, and
+// computes the blind indexes that keep encrypted email addresses searchable.
+//
+// Encrypted values look like "enc:v1:<key id>:<base64 of nonce and
+// ciphertext>", naming the key they were encrypted with so keys can be
+// rotated, and are bound to their column so they can't be moved to another.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/poly-workshop/auth-portal/configs"
+)
+
+const (
+	// Prefix starts every encrypted value.
+	Prefix = "enc:v1:"
+
+	// EmailColumn is the column of the email addresses of users, which is only
+	// encrypted if configured.
+	EmailColumn = "users.email"
+)
+
+var (
+	// ErrNoKeys is returned when decrypting without keys configured.
+	ErrNoKeys = errors.New("no encryption keys configured")
+	// ErrUnknownKey is returned when decrypting a value encrypted with a key
+	// that is no longer configured.
+	ErrUnknownKey = errors.New("value encrypted with an unknown key")
+)
+
+var keyID = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// Keyring encrypts with its primary key and decrypts with any of its keys. A
+// nil Keyring encrypts nothing.
+type Keyring struct {
+	primary  string
+	ciphers  map[string]cipher.AEAD
+	indexKey []byte
+	email    bool
+}
+
+// NewKeyring returns the keyring configured by cfg, or nil if no keys are
+// configured.
+func NewKeyring(cfg configs.EncryptionConfig) (*Keyring, error) {
+	if len(cfg.Keys) == 0 {
+		if cfg.EncryptEmail || cfg.IndexKey != "" {
+			return nil, errors.New("encryption.keys are required to encrypt email addresses")
+		}
+		return nil, nil
+	}
+	if cfg.EncryptEmail && cfg.IndexKey == "" {
+		return nil, errors.New("encryption.index_key is required to encrypt email addresses")
+	}
+	k := &Keyring{
+		ciphers: make(map[string]cipher.AEAD, len(cfg.Keys)),
+		email:   cfg.EncryptEmail,
+	}
+	if cfg.IndexKey != "" {
+		k.indexKey = []byte(cfg.IndexKey)
+	}
+	for i, entry := range cfg.Keys {
+		id, encoded, _ := strings.Cut(entry, ":")
+		if !keyID.MatchString(id) {
+			return nil, fmt.Errorf("encryption key %d: id must be letters, digits and -", i)
+		}
+		if _, ok := k.ciphers[id]; ok {
+			return nil, fmt.Errorf("encryption key %s: duplicate id", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("encryption key %s: must be 32 bytes encoded in base64", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		k.ciphers[id] = gcm
+		if i == 0 {
+			k.primary = id
+		}
+	}
+	return k, nil
+}
+
+var installed atomic.Pointer[Keyring]
+
+// Install makes k the keyring of the "encrypted" serializer and the blind
+// indexes of models.
+func Install(k *Keyring) {
+	installed.Store(k)
+}
+
+// Installed returns the keyring set by Install, nil if none.
+func Installed() *Keyring {
+	return installed.Load()
+}
+
+// IsEncrypted reports whether value was encrypted by a Keyring.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+// Encrypts reports whether values of column are encrypted.
+func (k *Keyring) Encrypts(column string) bool {
+	if k == nil {
+		return false
+	}
+	return column != EmailColumn || k.email
+}
+
+// PrimaryPrefix is the prefix of the values encrypted with the primary key,
+// e.g. to find those that need to be re-encrypted.
+func (k *Keyring) PrimaryPrefix() string {
+	return Prefix + k.primary + ":"
+}
+
+// Encrypt encrypts plaintext for column with the primary key. The empty string
+// stays empty.
+func (k *Keyring) Encrypt(plaintext, column string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	gcm := k.ciphers[k.primary]
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), []byte(column))
+	return k.PrimaryPrefix() + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value of column encrypted by Encrypt. Values that aren't
+// encrypted are returned as they are, so columns can be encrypted gradually.
+func (k *Keyring) Decrypt(value, column string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if k == nil {
+		return "", ErrNoKeys
+	}
+	id, encoded, _ := strings.Cut(strings.TrimPrefix(value, Prefix), ":")
+	gcm, ok := k.ciphers[id]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownKey, id)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("encrypted value is too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, []byte(column))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// IndexesEmail reports whether email addresses have a blind index.
+func (k *Keyring) IndexesEmail() bool {
+	return k != nil && k.indexKey != nil
+}
+
+// BlindIndex returns the HMAC of the email address email, to look it up while
+// it is encrypted; it is empty without an index key.
+func (k *Keyring) BlindIndex(email string) string {
+	if !k.IndexesEmail() {
+		return ""
+	}
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(email))
+	return hex.EncodeToString(mac.Sum(nil))
+}
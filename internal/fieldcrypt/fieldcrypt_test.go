@@ -0,0 +1,129 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/configs"
+)
+
+func testKey(id string, b byte) string {
+	return id + ":" + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, 32))
+}
+
+func TestNewKeyring(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   configs.EncryptionConfig
+		valid bool
+	}{
+		{name: "disabled", valid: true},
+		{
+			name:  "one key",
+			cfg:   configs.EncryptionConfig{Keys: []string{testKey("k1", 1)}},
+			valid: true,
+		},
+		{
+			name: "email",
+			cfg: configs.EncryptionConfig{
+				Keys:         []string{testKey("k1", 1)},
+				IndexKey:     "index",
+				EncryptEmail: true,
+			},
+			valid: true,
+		},
+		{name: "email without keys", cfg: configs.EncryptionConfig{EncryptEmail: true}},
+		{
+			name: "email without index key",
+			cfg:  configs.EncryptionConfig{Keys: []string{testKey("k1", 1)}, EncryptEmail: true},
+		},
+		{name: "short key", cfg: configs.EncryptionConfig{Keys: []string{"k1:c2hvcnQ="}}},
+		{name: "bad id", cfg: configs.EncryptionConfig{Keys: []string{testKey("k_1", 1)}}},
+		{
+			name: "duplicate id",
+			cfg:  configs.EncryptionConfig{Keys: []string{testKey("k1", 1), testKey("k1", 2)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyring(tt.cfg)
+			if (err == nil) != tt.valid {
+				t.Errorf("expected valid=%v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	old, err := NewKeyring(configs.EncryptionConfig{Keys: []string{testKey("k1", 1)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := old.Encrypt("refresh-token", "provider_tokens.refresh_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encrypted, "enc:v1:k1:") || strings.Contains(encrypted, "refresh-token") {
+		t.Fatalf("unexpected encrypted value %q", encrypted)
+	}
+
+	rotated, err := NewKeyring(configs.EncryptionConfig{
+		Keys: []string{testKey("k2", 2), testKey("k1", 1)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := rotated.Decrypt(encrypted, "provider_tokens.refresh_token")
+	if err != nil || decrypted != "refresh-token" {
+		t.Fatalf("expected the old key to decrypt, got %q, %v", decrypted, err)
+	}
+	reencrypted, err := rotated.Encrypt(decrypted, "provider_tokens.refresh_token")
+	if err != nil || !strings.HasPrefix(reencrypted, rotated.PrimaryPrefix()) {
+		t.Fatalf("expected the new key to encrypt, got %q, %v", reencrypted, err)
+	}
+
+	if _, err := old.Decrypt(reencrypted, "provider_tokens.refresh_token"); !errors.Is(
+		err,
+		ErrUnknownKey,
+	) {
+		t.Errorf("expected ErrUnknownKey, got %v", err)
+	}
+	if _, err := rotated.Decrypt(encrypted, "provider_tokens.access_token"); err == nil {
+		t.Error("expected values moved to another column not to decrypt")
+	}
+	var none *Keyring
+	if _, err := none.Decrypt(encrypted, "provider_tokens.refresh_token"); !errors.Is(
+		err,
+		ErrNoKeys,
+	) {
+		t.Errorf("expected ErrNoKeys, got %v", err)
+	}
+	if plain, err := none.Decrypt("plain", EmailColumn); err != nil || plain != "plain" {
+		t.Errorf("expected plaintext to be returned as is, got %q, %v", plain, err)
+	}
+}
+
+func TestKeyringEmail(t *testing.T) {
+	k, err := NewKeyring(configs.EncryptionConfig{
+		Keys:     []string{testKey("k1", 1)},
+		IndexKey: "index",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Encrypts(EmailColumn) || !k.Encrypts("provider_tokens.access_token") {
+		t.Error("expected only email addresses to be left in plaintext")
+	}
+	index := k.BlindIndex("alice@example.com")
+	if len(index) != 64 || index != k.BlindIndex("alice@example.com") ||
+		index == k.BlindIndex("bob@example.com") {
+		t.Errorf("unexpected blind index %q", index)
+	}
+	var none *Keyring
+	if none.Encrypts(EmailColumn) || none.BlindIndex("alice@example.com") != "" {
+		t.Error("expected a nil keyring not to encrypt nor index")
+	}
+}
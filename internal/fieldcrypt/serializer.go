@@ -0,0 +1,65 @@
+package fieldcrypt
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+func init() {
+	schema.RegisterSerializer("encrypted", Serializer{})
+}
+
+// Serializer is the gorm serializer "encrypted" of string fields, which
+// encrypts them with the installed keyring if their column is encrypted and
+// decrypts them whether or not it is, so encryption can be turned on and off
+// while the rotation job rewrites the stored values.
+type Serializer struct{}
+
+// Scan implements schema.SerializerInterface.
+func (Serializer) Scan(
+	ctx context.Context,
+	field *schema.Field,
+	dst reflect.Value,
+	dbValue any,
+) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("unexpected type %T of encrypted column %s", dbValue, field.DBName)
+	}
+	plaintext, err := Installed().Decrypt(value, column(field))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s: %w", column(field), err)
+	}
+	return field.Set(ctx, dst, plaintext)
+}
+
+// Value implements schema.SerializerValuerInterface.
+func (Serializer) Value(
+	_ context.Context,
+	field *schema.Field,
+	_ reflect.Value,
+	fieldValue any,
+) (any, error) {
+	value, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T of encrypted field %s", fieldValue, field.Name)
+	}
+	keyring := Installed()
+	if !keyring.Encrypts(column(field)) {
+		return value, nil
+	}
+	return keyring.Encrypt(value, column(field))
+}
+
+func column(field *schema.Field) string {
+	return field.Schema.Table + "." + field.DBName
+}
@@ -0,0 +1,260 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/service"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	upstreamAvailable = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "auth_gateway_upstream_available",
+		Help: "Whether the gateway passes requests to the gRPC server (1) or its circuit " +
+			"breaker is open or maintenance is on (0).",
+	})
+	upstreamFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "auth_gateway_upstream_failures_total",
+		Help: "API calls that failed because the gRPC server was unavailable.",
+	})
+	upstreamRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_gateway_upstream_rejected_total",
+		Help: "Requests answered by the gateway while the gRPC server was unavailable, by kind.",
+	}, []string{"kind"})
+)
+
+// defaultMaintenancePage is served to browsers when no maintenance page is
+// configured.
+const defaultMaintenancePage = `<!doctype html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Down for maintenance</title></head>
+<body>
+<h1>Down for maintenance</h1>
+<p>The portal is temporarily unavailable. Please try again in a few minutes.</p>
+</body>
+</html>
+`
+
+// upstreamBreaker stops passing API calls to the gRPC server once that many
+// calls in a row failed because the server was unavailable, answering them
+// with a 503
+// UPSTREAM_UNAVAILABLE error, and browsers with a maintenance page, both with
+// Retry-After. After the cooldown, one call at a time is let through as probe;
+// the first to succeed closes the breaker again, a failed one reopens it. In
+// maintenance mode, the breaker stays open. Calls answered before they reach
+// the gRPC server, e.g. from the cache, are unaffected.
+type upstreamBreaker struct {
+	mux         *runtime.ServeMux
+	failures    int
+	cooldown    time.Duration
+	maintenance bool
+	page        []byte
+	now         func() time.Time
+
+	mu          sync.Mutex
+	consecutive int
+	// openUntil is when the breaker lets a probe through; zero while closed
+	openUntil time.Time
+	probing   bool
+}
+
+// newUpstreamBreaker returns the breaker configured by cfg, nil if it is
+// disabled and maintenance is off. It fails if the maintenance page can't be
+// read.
+func newUpstreamBreaker(
+	mux *runtime.ServeMux,
+	cfg configs.GatewayConfig,
+) (*upstreamBreaker, error) {
+	if cfg.BreakerFailures <= 0 && !cfg.Maintenance {
+		upstreamAvailable.Set(1)
+		return nil, nil
+	}
+	page := []byte(defaultMaintenancePage)
+	if cfg.MaintenancePage != "" {
+		var err error
+		if page, err = os.ReadFile(cfg.MaintenancePage); err != nil {
+			return nil, fmt.Errorf("failed to read maintenance page: %w", err)
+		}
+	}
+	b := &upstreamBreaker{
+		mux:         mux,
+		failures:    cfg.BreakerFailures,
+		cooldown:    cfg.BreakerCooldown,
+		maintenance: cfg.Maintenance,
+		page:        page,
+		now:         time.Now,
+	}
+	if cfg.Maintenance {
+		upstreamAvailable.Set(0)
+		slog.Warn("maintenance mode is on, API calls are rejected")
+	} else {
+		upstreamAvailable.Set(1)
+	}
+	return b, nil
+}
+
+// allow reports whether a call may go to the gRPC server and whether it is the
+// probe of an open breaker.
+func (b *upstreamBreaker) allow() (allowed, probe bool) {
+	if b.maintenance {
+		return false, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.openUntil.IsZero():
+		return true, false
+	case b.now().Before(b.openUntil) || b.probing:
+		return false, false
+	default:
+		b.probing = true
+		return true, true
+	}
+}
+
+// open reports whether the breaker rejects calls without probing.
+func (b *upstreamBreaker) open() bool {
+	if b.maintenance {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.openUntil.IsZero() && b.now().Before(b.openUntil)
+}
+
+// upstreamCallKey is the context key of the upstreamCall of a request.
+type upstreamCallKey struct{}
+
+// upstreamCall records whether the gRPC server failed to answer an API call.
+type upstreamCall struct {
+	failed bool
+}
+
+// markUpstreamFailure records that the gRPC server didn't answer the call of
+// ctx: an UNAVAILABLE status without an ErrorInfo, which only the transport
+// and the server failing on its own (e.g. Redis down) produce. UNAVAILABLE
+// statuses of handlers carry a reason, e.g. DEPENDENCY_UNAVAILABLE when a
+// third party fails, and don't count, so one flaky provider doesn't take the
+// whole API down.
+func markUpstreamFailure(ctx context.Context) {
+	if call, ok := ctx.Value(upstreamCallKey{}).(*upstreamCall); ok {
+		call.failed = true
+	}
+}
+
+// record counts the outcome of a call passed to the gRPC server; a status
+// code of 0 (no response) counts as neither failure nor success.
+func (b *upstreamBreaker) record(code int, failed, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe {
+		b.probing = false
+	}
+	switch {
+	case failed:
+		upstreamFailures.Inc()
+		b.consecutive++
+		if probe || (b.openUntil.IsZero() && b.consecutive >= b.failures) {
+			if b.openUntil.IsZero() {
+				slog.Warn("gRPC server unavailable, circuit breaker opened",
+					"failures", b.consecutive)
+			}
+			b.openUntil = b.now().Add(b.cooldown)
+			b.consecutive = 0
+			upstreamAvailable.Set(0)
+		}
+	case code != 0:
+		b.consecutive = 0
+		if !b.openUntil.IsZero() && probe {
+			b.openUntil = time.Time{}
+			upstreamAvailable.Set(1)
+			slog.Info("gRPC server available again, circuit breaker closed")
+		}
+	}
+}
+
+// retryAfter returns the Retry-After header value, in seconds.
+func (b *upstreamBreaker) retryAfter() string {
+	return strconv.Itoa(max(int(b.cooldown.Seconds()), 1))
+}
+
+// api guards the API calls next passes to the gRPC server. A nil breaker
+// passes all calls through.
+func (b *upstreamBreaker) api(next http.Handler) http.Handler {
+	if b == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed, probe := b.allow()
+		if !allowed {
+			b.reject(w, r)
+			return
+		}
+		cw := &countingWriter{ResponseWriter: w}
+		call := &upstreamCall{}
+		defer func() { b.record(cw.status, call.failed, probe) }()
+		next.ServeHTTP(cw, r.WithContext(context.WithValue(r.Context(), upstreamCallKey{}, call)))
+	})
+}
+
+// frontend serves the maintenance page instead of the frontend to browsers
+// while the breaker is open, as the frontend could not do anything.
+func (b *upstreamBreaker) frontend(next http.Handler) http.Handler {
+	if b == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if wantsHTML(r) && b.open() {
+			b.reject(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// reject answers a request the breaker doesn't pass on: browsers get the
+// maintenance page, API clients an UPSTREAM_UNAVAILABLE error.
+func (b *upstreamBreaker) reject(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Retry-After", b.retryAfter())
+	if wantsHTML(r) {
+		upstreamRejected.WithLabelValues("page").Inc()
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write(b.page)
+		return
+	}
+	upstreamRejected.WithLabelValues("api").Inc()
+	msg := "service temporarily unavailable"
+	if b.maintenance {
+		msg = "service down for maintenance"
+	}
+	st := status.New(codes.Unavailable, msg)
+	if detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   service.ErrorReasonUpstreamUnavailable,
+		Domain:   service.ErrorDomain,
+		Metadata: map[string]string{"retry_after_seconds": b.retryAfter()},
+	}); err == nil {
+		st = detailed
+	}
+	runtime.HTTPError(r.Context(), b.mux, &runtime.JSONPb{}, w, r, st.Err())
+}
+
+// wantsHTML reports whether r is a browser navigation expecting a page.
+func wantsHTML(r *http.Request) bool {
+	return r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/html")
+}
@@ -0,0 +1,145 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUpstreamBreaker(t *testing.T) {
+	breaker, err := newUpstreamBreaker(runtime.NewServeMux(), configs.GatewayConfig{
+		BreakerFailures: 3,
+		BreakerCooldown: 10 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+	// As the transport fails when the gRPC server is down
+	down := status.Error(codes.Unavailable, "connection refused")
+	backendErr, calls := down, 0
+	handler := breaker.api(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if backendErr != nil {
+			errorHandler(r.Context(), runtime.NewServeMux(), &runtime.JSONPb{}, w, r, backendErr)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	call := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/users/me", nil))
+		return rec
+	}
+
+	// Third parties failing don't count
+	backendErr = reasonError(codes.Unavailable, service.ErrorReasonDependencyUnavailable)
+	for range 5 {
+		call()
+	}
+	if calls != 5 {
+		t.Errorf("expected failing dependencies not to open the breaker, backend called %d times",
+			calls)
+	}
+	backendErr, calls = down, 0
+
+	for range 3 {
+		call()
+	}
+	rec := call()
+	if calls != 3 {
+		t.Errorf("expected the breaker to open after 3 failures, backend called %d times", calls)
+	}
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "10" {
+		t.Errorf("expected 503 with Retry-After: 10, got %d %v", rec.Code, rec.Header())
+	}
+	if body := rec.Body.String(); !strings.Contains(body, service.ErrorReasonUpstreamUnavailable) {
+		t.Errorf("expected an %s error, got %s", service.ErrorReasonUpstreamUnavailable, body)
+	}
+
+	// After the cooldown a failing probe reopens it
+	now = now.Add(11 * time.Second)
+	call()
+	call()
+	if calls != 4 {
+		t.Errorf("expected one probe after the cooldown, backend called %d times", calls)
+	}
+
+	// A successful probe closes it
+	now = now.Add(11 * time.Second)
+	backendErr = nil
+	call()
+	if rec := call(); rec.Code != http.StatusOK || calls != 6 {
+		t.Errorf("expected the breaker to close, got %d after %d calls", rec.Code, calls)
+	}
+}
+
+func TestUpstreamBreakerMaintenance(t *testing.T) {
+	breaker, err := newUpstreamBreaker(runtime.NewServeMux(), configs.GatewayConfig{
+		BreakerFailures: -1,
+		BreakerCooldown: time.Minute,
+		Maintenance:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	rec := httptest.NewRecorder()
+	breaker.frontend(ok).ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable ||
+		!strings.Contains(rec.Body.String(), "Down for maintenance") {
+		t.Errorf("expected the maintenance page, got %d %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, want 60", got)
+	}
+
+	// Assets of the page are still served
+	rec = httptest.NewRecorder()
+	breaker.frontend(ok).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/app.js", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected assets to be served, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	breaker.api(ok).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/config", nil))
+	if rec.Code != http.StatusServiceUnavailable ||
+		!strings.Contains(rec.Body.String(), "maintenance") {
+		t.Errorf("expected API calls to be rejected, got %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestUpstreamBreakerDisabled(t *testing.T) {
+	breaker, err := newUpstreamBreaker(runtime.NewServeMux(), configs.GatewayConfig{
+		BreakerFailures: -1,
+	})
+	if err != nil || breaker != nil {
+		t.Fatalf("expected no breaker, got %v, %v", breaker, err)
+	}
+}
+
+// reasonError returns a status error of code with an ErrorInfo reason.
+func reasonError(code codes.Code, reason string) error {
+	st, err := status.New(code, "failed").WithDetails(
+		&errdetails.ErrorInfo{Reason: reason, Domain: service.ErrorDomain},
+	)
+	if err != nil {
+		panic(err)
+	}
+	return st.Err()
+}
@@ -0,0 +1,268 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// revalidationLockTTL bounds how long one gateway may take to refresh an entry
+// before another one tries.
+const revalidationLockTTL = 10 * time.Second
+
+// cachedResponse is a response stored in Redis.
+type cachedResponse struct {
+	ContentType string    `json:"content_type"`
+	Body        []byte    `json:"body"`
+	StoredAt    time.Time `json:"stored_at"`
+}
+
+// responseCache serves the responses of public GET endpoints from Redis, so
+// many SPA instances loading them don't all reach the gRPC server. Entries are
+// shared by all gateways; stale entries are served while one gateway refreshes
+// them in the background. Only endpoints whose responses don't depend on the
+// caller may be cached.
+type responseCache struct {
+	rdb   redis.UniversalClient
+	paths []cachedPath
+	ttl   time.Duration
+	stale time.Duration
+}
+
+// cachedPath is a cached path template and the query params it accepts.
+type cachedPath struct {
+	// segments are the segments of the template; "{name}" matches any segment
+	segments []string
+	params   []string
+}
+
+// newResponseCache caches the responses of paths, each optionally followed by
+// the query params it accepts, e.g. "/config?tenant&locale". Segments in
+// braces match any segment, as in the HTTP rules of the API, e.g.
+// "/v1/tenants/{org}/config"; each path matched has entries of its own.
+func newResponseCache(
+	rdb redis.UniversalClient,
+	paths []string,
+	ttl, stale time.Duration,
+) *responseCache {
+	c := &responseCache{rdb: rdb, ttl: ttl, stale: stale}
+	for _, path := range paths {
+		path, params, _ := strings.Cut(path, "?")
+		cached := cachedPath{segments: strings.Split(path, "/")}
+		if params != "" {
+			cached.params = strings.Split(params, "&")
+		}
+		c.paths = append(c.paths, cached)
+	}
+	return c
+}
+
+// match returns the cached path template matching path, if any.
+func (c *responseCache) match(path string) (cachedPath, bool) {
+	segments := strings.Split(path, "/")
+	for _, cached := range c.paths {
+		if cached.matches(segments) {
+			return cached, true
+		}
+	}
+	return cachedPath{}, false
+}
+
+func (p cachedPath) matches(segments []string) bool {
+	if len(segments) != len(p.segments) {
+		return false
+	}
+	for i, segment := range p.segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if segments[i] == "" {
+				return false
+			}
+		} else if segments[i] != segment {
+			return false
+		}
+	}
+	return true
+}
+
+// acceptedQuery returns the query of u reduced to the params accepted on its
+// path, so other params can neither split nor poison the entry.
+func (p cachedPath) acceptedQuery(u *url.URL) url.Values {
+	query := u.Query()
+	accepted := make(url.Values)
+	for _, param := range p.params {
+		if values, ok := query[param]; ok {
+			accepted[param] = values
+		}
+	}
+	return accepted
+}
+
+func responseCacheKey(path string, query url.Values) string {
+	if len(query) == 0 {
+		return fmt.Sprintf("gateway_cache:%s", path)
+	}
+	return fmt.Sprintf("gateway_cache:%s?%s", path, query.Encode())
+}
+
+// middleware answers GET requests of the cached paths from the cache. A nil
+// cache passes all requests through.
+func (c *responseCache) middleware(next http.Handler) http.Handler {
+	if c == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cached, ok := c.match(r.URL.Path)
+		if r.Method != http.MethodGet || !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		// The backend sees the request the entry is keyed by
+		query := cached.acceptedQuery(r.URL)
+		r = r.Clone(r.Context())
+		r.URL.RawQuery = query.Encode()
+		r.RequestURI = r.URL.RequestURI()
+		key := responseCacheKey(r.URL.Path, query)
+		entry, err := c.get(r.Context(), key)
+		if err != nil {
+			slog.WarnContext(r.Context(), "failed to read cached response", "error", err)
+		}
+		if entry != nil {
+			age := time.Since(entry.StoredAt)
+			if age < c.ttl {
+				c.write(w, entry, "HIT", c.ttl-age, c.stale)
+				return
+			}
+			if age < c.ttl+c.stale {
+				c.revalidate(r, next, key)
+				c.write(w, entry, "STALE", 0, c.ttl+c.stale-age)
+				return
+			}
+		}
+
+		resp := fetch(next, r)
+		if resp.status == http.StatusOK {
+			entry = &cachedResponse{
+				ContentType: resp.header.Get("Content-Type"),
+				Body:        resp.body.Bytes(),
+				StoredAt:    time.Now(),
+			}
+			c.store(r.Context(), key, entry)
+			c.write(w, entry, "MISS", c.ttl, c.stale)
+			return
+		}
+		for name, values := range resp.header {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(resp.status)
+		_, _ = w.Write(resp.body.Bytes())
+	})
+}
+
+func (c *responseCache) get(ctx context.Context, key string) (*cachedResponse, error) {
+	data, err := c.rdb.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (c *responseCache) store(ctx context.Context, key string, entry *cachedResponse) {
+	data, err := json.Marshal(entry)
+	if err == nil {
+		err = c.rdb.Set(ctx, key, data, c.ttl+c.stale).Err()
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to cache response", "error", err, "key", key)
+	}
+}
+
+// revalidate refreshes a stale entry in the background, unless another request
+// or gateway already does.
+func (c *responseCache) revalidate(r *http.Request, next http.Handler, key string) {
+	ctx := context.WithoutCancel(r.Context())
+	locked, err := c.rdb.SetNX(ctx, key+":lock", 1, revalidationLockTTL).Result()
+	if err != nil || !locked {
+		return
+	}
+	r = r.Clone(ctx)
+	go func() {
+		defer c.rdb.Del(ctx, key+":lock")
+		resp := fetch(next, r)
+		if resp.status != http.StatusOK {
+			slog.WarnContext(ctx, "failed to revalidate cached response",
+				"status", resp.status, "key", key)
+			return
+		}
+		c.store(ctx, key, &cachedResponse{
+			ContentType: resp.header.Get("Content-Type"),
+			Body:        resp.body.Bytes(),
+			StoredAt:    time.Now(),
+		})
+	}()
+}
+
+// write sends a cached response, telling clients and CDNs how long they may
+// reuse it.
+func (c *responseCache) write(
+	w http.ResponseWriter,
+	entry *cachedResponse,
+	result string,
+	maxAge, staleWhileRevalidate time.Duration,
+) {
+	w.Header().Set("Content-Type", entry.ContentType)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, stale-while-revalidate=%d",
+		int(maxAge.Seconds()), int(staleWhileRevalidate.Seconds())))
+	w.Header().Set("Age", fmt.Sprint(int(time.Since(entry.StoredAt).Seconds())))
+	w.Header().Set("X-Cache", result)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(entry.Body)
+}
+
+// bufferedResponse collects a response, so it can be cached before it is sent.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func fetch(next http.Handler, r *http.Request) *bufferedResponse {
+	resp := &bufferedResponse{header: make(http.Header)}
+	next.ServeHTTP(resp, r)
+	if resp.status == 0 {
+		resp.status = http.StatusOK
+	}
+	return resp
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
@@ -0,0 +1,125 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+)
+
+func TestResponseCache(t *testing.T) {
+	rdb, _ := testutil.NewRedis(t)
+	cache := newResponseCache(rdb, []string{"/config?fail"}, time.Minute, 5*time.Minute)
+	var calls atomic.Int32
+	var query atomic.Value
+	handler := cache.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		query.Store(r.URL.RawQuery)
+		if r.URL.Query().Get("fail") != "" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version":1}`))
+	}))
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	first := get("/config")
+	second := get("/config")
+	if first.Header().Get("X-Cache") != "MISS" || second.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("expected a miss and then a hit, got %q and %q",
+			first.Header().Get("X-Cache"), second.Header().Get("X-Cache"))
+	}
+	if calls.Load() != 1 || second.Body.String() != `{"version":1}` {
+		t.Errorf("expected the cached body from 1 backend call, got %d calls and %q",
+			calls.Load(), second.Body)
+	}
+	if second.Header().Get("Cache-Control") == "" ||
+		second.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("expected caching headers and the content type, got %v", second.Header())
+	}
+
+	// Params not accepted share the entry and don't reach the backend
+	if rec := get("/config?nonce=1"); rec.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("expected other params not to split the entry, got %q", rec.Header().Get("X-Cache"))
+	}
+
+	// Errors are not cached, other paths and methods are not cached
+	get("/config?fail=1")
+	get("/config?fail=1&nonce=2")
+	if got := query.Load(); got != "fail=1" {
+		t.Errorf("expected the backend to get the accepted params only, got %q", got)
+	}
+	get("/users")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/config", nil))
+	if calls.Load() != 5 {
+		t.Errorf("expected uncached requests to reach the backend, got %d calls", calls.Load())
+	}
+
+	// Stale entries are served while they are refreshed in the background
+	key := responseCacheKey("/config", nil)
+	cache.store(context.Background(), key, &cachedResponse{
+		ContentType: "application/json",
+		Body:        []byte(`{"version":0}`),
+		StoredAt:    time.Now().Add(-2 * time.Minute),
+	})
+	stale := get("/config")
+	if stale.Header().Get("X-Cache") != "STALE" || stale.Body.String() != `{"version":0}` {
+		t.Errorf("expected the stale entry, got %q %q", stale.Header().Get("X-Cache"), stale.Body)
+	}
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if entry, _ := cache.get(context.Background(), key); string(entry.Body) == `{"version":1}` {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Error("expected the stale entry to be revalidated")
+}
+
+func TestResponseCachePathTemplate(t *testing.T) {
+	rdb, _ := testutil.NewRedis(t)
+	cache := newResponseCache(rdb, []string{"/v1/tenants/{org}/config"}, time.Minute, time.Minute)
+	var calls atomic.Int32
+	handler := cache.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	get("/v1/tenants/acme/config")
+	if rec := get("/v1/tenants/acme/config"); rec.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("expected a path matching the template to be cached, got %q",
+			rec.Header().Get("X-Cache"))
+	}
+	// Each tenant has its own entry
+	if rec := get("/v1/tenants/globex/config"); rec.Header().Get("X-Cache") != "MISS" ||
+		rec.Body.String() != "/v1/tenants/globex/config" {
+		t.Errorf("expected another tenant to miss, got %q %q", rec.Header().Get("X-Cache"), rec.Body)
+	}
+	for _, path := range []string{
+		"/v1/tenants//config",
+		"/v1/tenants/acme/config/extra",
+		"/v1/tenants/acme/members",
+	} {
+		if rec := get(path); rec.Header().Get("X-Cache") != "" {
+			t.Errorf("expected %s not to be cached, got %q", path, rec.Header().Get("X-Cache"))
+		}
+	}
+	if calls.Load() != 5 {
+		t.Errorf("expected 5 backend calls, got %d", calls.Load())
+	}
+}
@@ -0,0 +1,166 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/rpcmeta"
+	"google.golang.org/grpc"
+	// Registers the client side of health checks
+	_ "google.golang.org/grpc/health"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// maxRetries is the most retries gRPC allows (5 attempts per call).
+const maxRetries = 4
+
+// endpointsScheme is the scheme of the target resolving to the configured list
+// of gRPC endpoints.
+const endpointsScheme = "gateway-endpoints"
+
+// ClientTarget returns the target the gateway calls: the configured list of
+// endpoints, the configured target, or the gRPC server on this host.
+func ClientTarget(cfg configs.GatewayConfig, serverPort uint) string {
+	switch {
+	case len(cfg.GRPCEndpoints) > 0:
+		return endpointsScheme + ":///grpc-server"
+	case cfg.GRPCTarget != "":
+		return cfg.GRPCTarget
+	default:
+		return fmt.Sprintf("localhost:%d", serverPort)
+	}
+}
+
+// DialOptions tunes the gateway's connections to the gRPC server: keepalive
+// pings detect dead connections behind load balancers, and the service config
+// selects the load balancing policy and retries of idempotent calls. A list
+// of endpoints is resolved by a static resolver.
+func DialOptions(cfg configs.GatewayConfig) ([]grpc.DialOption, error) {
+	serviceConfig, err := clientServiceConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.DialOption{grpc.WithDefaultServiceConfig(serviceConfig)}
+	if len(cfg.GRPCEndpoints) > 0 {
+		endpoints, err := endpointsResolver(cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithResolvers(endpoints))
+	}
+	if cfg.GRPCKeepalive > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                cfg.GRPCKeepalive,
+			Timeout:             cfg.GRPCKeepaliveTimeout,
+			PermitWithoutStream: true,
+		}))
+	}
+	if cfg.GRPCIdleTimeout > 0 {
+		opts = append(opts, grpc.WithIdleTimeout(cfg.GRPCIdleTimeout))
+	}
+	return opts, nil
+}
+
+// endpointsResolver resolves the target of ClientTarget to the configured
+// endpoints, each a host:port.
+func endpointsResolver(cfg configs.GatewayConfig) (*manual.Resolver, error) {
+	if cfg.GRPCTarget != "" {
+		return nil, fmt.Errorf("gRPC target and endpoints are both configured")
+	}
+	var state resolver.State
+	for _, endpoint := range cfg.GRPCEndpoints {
+		if _, _, err := net.SplitHostPort(endpoint); err != nil {
+			return nil, fmt.Errorf("invalid gRPC endpoint %q: %w", endpoint, err)
+		}
+		state.Endpoints = append(state.Endpoints, resolver.Endpoint{
+			Addresses: []resolver.Address{{Addr: endpoint}},
+		})
+	}
+	endpoints := manual.NewBuilderWithScheme(endpointsScheme)
+	endpoints.InitialState(state)
+	return endpoints, nil
+}
+
+// clientServiceConfig returns the gRPC service config JSON of the gateway's client.
+func clientServiceConfig(cfg configs.GatewayConfig) (string, error) {
+	switch cfg.GRPCLoadBalancing {
+	case configs.LoadBalancingPickFirst, configs.LoadBalancingRoundRobin:
+	default:
+		return "", fmt.Errorf("unknown load balancing policy %q", cfg.GRPCLoadBalancing)
+	}
+	serviceConfig := map[string]any{
+		"loadBalancingConfig": []map[string]any{{cfg.GRPCLoadBalancing: map[string]any{}}},
+	}
+	if cfg.GRPCHealthCheck {
+		// Round robin skips the servers whose health service reports them as not
+		// serving. gRPC's pick first doesn't support health checks: it ignores
+		// this and keeps calling its server until the connection fails, so a
+		// server that lost its database or is shutting down still gets calls
+		serviceConfig["healthCheckConfig"] = map[string]any{"serviceName": ""}
+	}
+	if cfg.GRPCMaxRetries > 0 {
+		attempts := min(cfg.GRPCMaxRetries, maxRetries) + 1
+		idempotent := map[string]any{"name": idempotentMethods()}
+		if cfg.GRPCHedgingDelay > 0 {
+			idempotent["hedgingPolicy"] = map[string]any{
+				"maxAttempts":         attempts,
+				"hedgingDelay":        durationJSON(cfg.GRPCHedgingDelay),
+				"nonFatalStatusCodes": []string{"UNAVAILABLE", "DEADLINE_EXCEEDED"},
+			}
+		} else {
+			idempotent["retryPolicy"] = map[string]any{
+				"maxAttempts":          attempts,
+				"initialBackoff":       durationJSON(cfg.GRPCRetryInitialBackoff),
+				"maxBackoff":           durationJSON(cfg.GRPCRetryMaxBackoff),
+				"backoffMultiplier":    2,
+				"retryableStatusCodes": []string{"UNAVAILABLE", "DEADLINE_EXCEEDED"},
+			}
+		}
+		// Other calls aren't retried: UNAVAILABLE doesn't tell whether the server
+		// already did the work, e.g. when a connection breaks while it answers.
+		// gRPC still transparently retries calls that never left the gateway
+		serviceConfig["methodConfig"] = []map[string]any{idempotent}
+	}
+	data, err := json.Marshal(serviceConfig)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// idempotentMethods names the idempotent RPCs of the services the gateway serves.
+func idempotentMethods() []map[string]string {
+	var names []map[string]string
+	for _, file := range []protoreflect.FileDescriptor{
+		auth_v1_pb.File_auth_v1_auth_proto,
+		user_v1_pb.File_user_v1_user_proto,
+	} {
+		services := file.Services()
+		for i := range services.Len() {
+			service := services.Get(i)
+			methods := service.Methods()
+			for j := range methods.Len() {
+				if rpcmeta.Idempotent(methods.Get(j)) {
+					names = append(names, map[string]string{
+						"service": string(service.FullName()),
+						"method":  string(methods.Get(j).Name()),
+					})
+				}
+			}
+		}
+	}
+	return names
+}
+
+// durationJSON formats d as a protobuf JSON duration, e.g. "0.1s".
+func durationJSON(d time.Duration) string {
+	return fmt.Sprintf("%gs", d.Seconds())
+}
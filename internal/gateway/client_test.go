@@ -0,0 +1,195 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestDialOptions(t *testing.T) {
+	cfg := configs.GatewayConfig{
+		GRPCKeepalive:           30 * time.Second,
+		GRPCKeepaliveTimeout:    10 * time.Second,
+		GRPCIdleTimeout:         time.Minute,
+		GRPCLoadBalancing:       configs.LoadBalancingRoundRobin,
+		GRPCMaxRetries:          10,
+		GRPCRetryInitialBackoff: 100 * time.Millisecond,
+		GRPCRetryMaxBackoff:     time.Second,
+	}
+	opts, err := DialOptions(cfg)
+	if err != nil {
+		t.Fatalf("DialOptions failed: %v", err)
+	}
+	// gRPC rejects invalid service configs when the client is created
+	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient("dns:///localhost:50051", opts...)
+	if err != nil {
+		t.Fatalf("expected a valid client configuration, got %v", err)
+	}
+	_ = conn.Close()
+
+	serviceConfig, _ := clientServiceConfig(cfg)
+	var parsed struct {
+		LoadBalancingConfig []map[string]any `json:"loadBalancingConfig"`
+		MethodConfig        []struct {
+			Name        []map[string]string `json:"name"`
+			RetryPolicy struct {
+				MaxAttempts          int      `json:"maxAttempts"`
+				InitialBackoff       string   `json:"initialBackoff"`
+				RetryableStatusCodes []string `json:"retryableStatusCodes"`
+			} `json:"retryPolicy"`
+		} `json:"methodConfig"`
+	}
+	if err := json.Unmarshal([]byte(serviceConfig), &parsed); err != nil {
+		t.Fatalf("invalid service config %s: %v", serviceConfig, err)
+	}
+	if _, ok := parsed.LoadBalancingConfig[0]["round_robin"]; !ok {
+		t.Errorf("expected round_robin, got %s", serviceConfig)
+	}
+	if len(parsed.MethodConfig) != 1 {
+		t.Fatalf("expected a policy of idempotent methods only, got %s", serviceConfig)
+	}
+	idempotent := parsed.MethodConfig[0]
+	if idempotent.RetryPolicy.MaxAttempts != 5 || idempotent.RetryPolicy.InitialBackoff != "0.1s" {
+		t.Errorf("expected retries capped at 5 attempts, got %s", serviceConfig)
+	}
+	getUser := slices.ContainsFunc(idempotent.Name, func(name map[string]string) bool {
+		return name["service"] == "user.v1.UserService" && name["method"] == "GetUser"
+	})
+	if !getUser || len(idempotent.RetryPolicy.RetryableStatusCodes) != 2 {
+		t.Errorf("expected GET routes to retry on deadlines too, got %s", serviceConfig)
+	}
+	for _, name := range idempotent.Name {
+		if name["method"] == "UpdateUser" || name["method"] == "LoginByPassword" {
+			t.Errorf("expected %s not to be treated as idempotent", name["method"])
+		}
+	}
+	for _, name := range idempotent.Name {
+		if len(name) == 0 {
+			t.Errorf("expected no catch-all retry policy, got %s", serviceConfig)
+		}
+	}
+
+	cfg.GRPCHedgingDelay = 50 * time.Millisecond
+	opts, _ = DialOptions(cfg)
+	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if conn, err := grpc.NewClient("dns:///localhost:50051", opts...); err != nil {
+		t.Errorf("expected a valid hedging configuration, got %v", err)
+	} else {
+		_ = conn.Close()
+	}
+	if serviceConfig, _ := clientServiceConfig(cfg); !strings.Contains(serviceConfig, `"0.05s"`) {
+		t.Errorf("expected a hedging policy, got %s", serviceConfig)
+	}
+
+	cfg.GRPCMaxRetries = 0
+	if serviceConfig, _ := clientServiceConfig(cfg); strings.Contains(serviceConfig, "retry") {
+		t.Errorf("expected no retry policy without retries, got %s", serviceConfig)
+	}
+
+	cfg.GRPCLoadBalancing = "random"
+	if _, err := DialOptions(cfg); err == nil {
+		t.Error("expected an unknown load balancing policy to be rejected")
+	}
+}
+
+func TestDialOptionsEndpoints(t *testing.T) {
+	// Two replicas, the second reporting itself as not serving
+	var calls [2]atomic.Int32
+	endpoints := make([]string, 2)
+	for i := range endpoints {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		server := grpc.NewServer(grpc.UnaryInterceptor(func(
+			ctx context.Context,
+			req any,
+			_ *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler,
+		) (any, error) {
+			calls[i].Add(1)
+			return handler(ctx, req)
+		}))
+		healthServer := health.NewServer()
+		if i == 1 {
+			healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+		}
+		healthpb.RegisterHealthServer(server, healthServer)
+		go func() { _ = server.Serve(lis) }()
+		t.Cleanup(server.Stop)
+		endpoints[i] = lis.Addr().String()
+	}
+
+	cfg := configs.GatewayConfig{
+		GRPCEndpoints:     endpoints,
+		GRPCHealthCheck:   true,
+		GRPCLoadBalancing: configs.LoadBalancingRoundRobin,
+	}
+	opts, err := DialOptions(cfg)
+	if err != nil {
+		t.Fatalf("DialOptions failed: %v", err)
+	}
+	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(ClientTarget(cfg, 50051), opts...)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	client := healthpb.NewHealthClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for range 10 {
+		if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{},
+			grpc.WaitForReady(true)); err != nil {
+			t.Fatalf("Check failed: %v", err)
+		}
+	}
+	if calls[0].Load() != 10 || calls[1].Load() != 0 {
+		t.Errorf("expected all calls on the serving replica, got %d and %d",
+			calls[0].Load(), calls[1].Load())
+	}
+
+	if _, err := DialOptions(configs.GatewayConfig{
+		GRPCEndpoints:     []string{"10.0.0.1"},
+		GRPCLoadBalancing: configs.LoadBalancingPickFirst,
+	}); err == nil {
+		t.Error("expected an endpoint without port to be rejected")
+	}
+	if _, err := DialOptions(configs.GatewayConfig{
+		GRPCTarget:        "dns:///auth-portal:50051",
+		GRPCEndpoints:     endpoints,
+		GRPCLoadBalancing: configs.LoadBalancingPickFirst,
+	}); err == nil {
+		t.Error("expected a target and endpoints together to be rejected")
+	}
+}
+
+func TestClientTarget(t *testing.T) {
+	for _, tt := range []struct {
+		cfg  configs.GatewayConfig
+		want string
+	}{
+		{configs.GatewayConfig{}, "localhost:50051"},
+		{configs.GatewayConfig{GRPCTarget: "dns:///auth:50051"}, "dns:///auth:50051"},
+		{
+			configs.GatewayConfig{GRPCEndpoints: []string{"10.0.0.1:50051"}},
+			endpointsScheme + ":///grpc-server",
+		},
+	} {
+		if got := ClientTarget(tt.cfg, 50051); got != tt.want {
+			t.Errorf("ClientTarget(%+v) = %q, want %q", tt.cfg, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package gateway
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// userPath matches the routes of a single user, e.g. /v1/users/{id}.
+var userPath = regexp.MustCompile(`^/v1/users/[^/:]+$`)
+
+// requireIfMatch rejects PUT requests replacing a user without an If-Match
+// header with 428 Precondition Required, so REST clients can't overwrite
+// changes they haven't seen. The header is forwarded to UpdateUser, which
+// compares it with the user's version.
+func requireIfMatch(mux *runtime.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && userPath.MatchString(r.URL.Path) &&
+			r.Header.Get("If-Match") == "" {
+			err := status.Error(codes.FailedPrecondition, "If-Match header is required")
+			runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r, &runtime.HTTPStatusError{
+				HTTPStatus: http.StatusPreconditionRequired,
+				Err:        err,
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
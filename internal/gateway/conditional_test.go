@@ -0,0 +1,38 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+)
+
+func TestRequireIfMatch(t *testing.T) {
+	mux := runtime.NewServeMux()
+	handler := requireIfMatch(mux, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for _, tc := range []struct {
+		method, path, ifMatch string
+		want                  int
+	}{
+		{http.MethodPut, "/v1/users/user-1", "", http.StatusPreconditionRequired},
+		{http.MethodPut, "/v1/users/user-1", `"3"`, http.StatusNoContent},
+		{http.MethodPatch, "/v1/users/user-1", "", http.StatusNoContent},
+		{http.MethodGet, "/v1/users/user-1", "", http.StatusNoContent},
+		{http.MethodPut, "/v1/feature-flags/signup_blocked", "", http.StatusNoContent},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		if tc.ifMatch != "" {
+			req.Header.Set("If-Match", tc.ifMatch)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("%s %s (If-Match %q): expected %d, got %d",
+				tc.method, tc.path, tc.ifMatch, tc.want, rec.Code)
+		}
+	}
+}
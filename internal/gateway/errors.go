@@ -0,0 +1,108 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/poly-workshop/auth-portal/internal/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorHandler writes errors like runtime.DefaultHTTPErrorHandler, except that
+//   - failed If-Match preconditions are answered with 412 Precondition Failed
+//     instead of the 400 FAILED_PRECONDITION maps to;
+//   - 401 and 403 responses always carry an ErrorInfo reason, LOGIN_REQUIRED
+//     and FORBIDDEN unless the error has one, so clients can tell whether to
+//     log in again or to show that access is denied;
+//   - 401 responses carry a proper WWW-Authenticate challenge;
+//   - UNAVAILABLE statuses without a reason count against the upstream breaker.
+func errorHandler(
+	ctx context.Context,
+	mux *runtime.ServeMux,
+	marshaler runtime.Marshaler,
+	w http.ResponseWriter,
+	r *http.Request,
+	err error,
+) {
+	var statusErr *runtime.HTTPStatusError
+	if errors.As(err, &statusErr) {
+		err = statusErr.Err
+	}
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.Unauthenticated:
+		st = withDefaultReason(st, service.ErrorReasonLoginRequired)
+		// The default handler sets the error message as challenge
+		w = &challengeWriter{ResponseWriter: w, challenge: bearerChallenge(r)}
+	case codes.PermissionDenied:
+		st = withDefaultReason(st, service.ErrorReasonForbidden)
+	case codes.Unavailable:
+		if errorReason(st) == "" {
+			markUpstreamFailure(ctx)
+		}
+	case codes.FailedPrecondition:
+		if errorReason(st) == service.ErrorReasonVersionMismatch && statusErr == nil {
+			statusErr = &runtime.HTTPStatusError{HTTPStatus: http.StatusPreconditionFailed}
+		}
+	}
+	err = st.Err()
+	if statusErr != nil {
+		err = &runtime.HTTPStatusError{HTTPStatus: statusErr.HTTPStatus, Err: err}
+	}
+	runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, w, r, err)
+}
+
+// errorReason returns the reason of the ErrorInfo of st, if any.
+func errorReason(st *status.Status) string {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+// withDefaultReason adds an ErrorInfo with reason to st unless it has one.
+func withDefaultReason(st *status.Status, reason string) *status.Status {
+	if errorReason(st) != "" {
+		return st
+	}
+	detailed, err := st.WithDetails(
+		&errdetails.ErrorInfo{Reason: reason, Domain: service.ErrorDomain},
+	)
+	if err != nil {
+		return st
+	}
+	return detailed
+}
+
+// bearerChallenge is the WWW-Authenticate challenge of a request that failed
+// authentication; a token it carried is reported invalid (RFC 6750).
+func bearerChallenge(r *http.Request) string {
+	challenge := fmt.Sprintf(`Bearer realm=%q`, service.ErrorDomain)
+	if r.Header.Get("Authorization") != "" {
+		challenge += `, error="invalid_token"`
+	}
+	return challenge
+}
+
+// challengeWriter sets the WWW-Authenticate header of a response to challenge.
+type challengeWriter struct {
+	http.ResponseWriter
+	challenge string
+}
+
+func (w *challengeWriter) WriteHeader(code int) {
+	w.Header().Set("WWW-Authenticate", w.challenge)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *challengeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,101 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/poly-workshop/auth-portal/internal/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorHandlerVersionMismatch(t *testing.T) {
+	mismatch, _ := status.New(codes.FailedPrecondition, "user was modified").WithDetails(
+		&errdetails.ErrorInfo{Reason: service.ErrorReasonVersionMismatch},
+	)
+	for _, tc := range []struct {
+		err  error
+		want int
+	}{
+		{mismatch.Err(), http.StatusPreconditionFailed},
+		{status.Error(codes.FailedPrecondition, "other"), http.StatusBadRequest},
+	} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/v1/users/user-1", nil)
+		mux := runtime.NewServeMux()
+		errorHandler(context.Background(), mux, &runtime.JSONPb{}, rec, req, tc.err)
+		if rec.Code != tc.want {
+			t.Errorf("%v: expected %d, got %d", tc.err, tc.want, rec.Code)
+		}
+	}
+}
+
+func TestErrorHandlerAuthReasons(t *testing.T) {
+	denied, _ := status.New(codes.PermissionDenied, "denied").WithDetails(
+		&errdetails.ErrorInfo{Reason: service.ErrorReasonAccessDenied},
+	)
+	for _, tc := range []struct {
+		name          string
+		err           error
+		authorization string
+		wantStatus    int
+		wantReason    string
+		wantChallenge string
+	}{
+		{
+			name:          "missing token",
+			err:           status.Error(codes.Unauthenticated, "missing authorization token"),
+			wantStatus:    http.StatusUnauthorized,
+			wantReason:    service.ErrorReasonLoginRequired,
+			wantChallenge: `Bearer realm="auth-portal"`,
+		},
+		{
+			name:          "invalid token",
+			err:           status.Error(codes.Unauthenticated, "token is outdated"),
+			authorization: "Bearer expired",
+			wantStatus:    http.StatusUnauthorized,
+			wantReason:    service.ErrorReasonLoginRequired,
+			wantChallenge: `Bearer realm="auth-portal", error="invalid_token"`,
+		},
+		{
+			name:       "insufficient permissions",
+			err:        status.Error(codes.PermissionDenied, "insufficient permissions"),
+			wantStatus: http.StatusForbidden,
+			wantReason: service.ErrorReasonForbidden,
+		},
+		{
+			name:       "own reason",
+			err:        denied.Err(),
+			wantStatus: http.StatusForbidden,
+			wantReason: service.ErrorReasonAccessDenied,
+		},
+	} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+		if tc.authorization != "" {
+			req.Header.Set("Authorization", tc.authorization)
+		}
+		errorHandler(context.Background(), runtime.NewServeMux(), &runtime.JSONPb{}, rec, req, tc.err)
+
+		var body struct {
+			Details []struct {
+				Reason string `json:"reason"`
+			} `json:"details"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		if rec.Code != tc.wantStatus {
+			t.Errorf("%s: expected %d, got %d", tc.name, tc.wantStatus, rec.Code)
+		}
+		if len(body.Details) != 1 || body.Details[0].Reason != tc.wantReason {
+			t.Errorf("%s: expected reason %s, got %s", tc.name, tc.wantReason, rec.Body)
+		}
+		if got := rec.Header().Get("WWW-Authenticate"); got != tc.wantChallenge {
+			t.Errorf("%s: WWW-Authenticate = %q, want %q", tc.name, got, tc.wantChallenge)
+		}
+	}
+}
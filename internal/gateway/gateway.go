@@ -0,0 +1,359 @@
+// Package gateway serves the gRPC services over HTTP with grpc-gateway, along
+// with the frontend, proxy routes to other backends and provider webhooks. The
+// gateway server runs it in front of gRPC servers, and the gRPC server runs it
+// itself with gateway.in_process, calling its services in-process.
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/poly-workshop/auth-portal/configs"
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/go-webmods/redis_client"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/cors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Gateway wraps the grpc-gateway mux and provides HTTP endpoints for gRPC services
+type Gateway struct {
+	mux *runtime.ServeMux
+	// grpcConn calls the gRPC services, over the network or in-process
+	grpcConn  grpc.ClientConnInterface
+	staticDir string
+	apiPrefix string
+	// proxies serve the path prefixes routed to other backends
+	proxies map[string]http.Handler
+	// protectedPrefixes are API paths requiring a valid user token
+	protectedPrefixes []string
+	loginURL          string
+	parseToken        tokenParser
+	// cache serves public endpoints from Redis, if enabled
+	cache *responseCache
+	// webhooks receive provider events by path, e.g. "/webhooks/github"
+	webhooks map[string]http.Handler
+	// headers are the security headers added to responses, if enabled
+	headers *securityHeaders
+	// assetOrigin serves the frontend assets instead of the gateway, if set
+	assetOrigin *url.URL
+	// breaker stops calling the gRPC server while it is unavailable, if enabled
+	breaker *upstreamBreaker
+}
+
+// NewGateway creates a new gateway instance
+func NewGateway(
+	grpcEndpoint, staticDir, apiPrefix string,
+	dialOpts ...grpc.DialOption,
+) (*Gateway, error) {
+	// Create gRPC connection
+	dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(grpcEndpoint, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to gRPC server: %w", err)
+	}
+	g, err := NewGatewayWithConn(conn, staticDir, apiPrefix)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return g, nil
+}
+
+// NewGatewayWithConn creates a gateway calling the services through conn,
+// e.g. one to the in-process listener of the gRPC server (server.ServeInProcess),
+// so a binary running the services serves the gateway without a network hop.
+// Calls still run the server's interceptors.
+func NewGatewayWithConn(
+	conn grpc.ClientConnInterface,
+	staticDir, apiPrefix string,
+) (*Gateway, error) {
+	// Create gateway mux with custom options
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+			switch key {
+			case "Authorization":
+				return key, true
+			case "X-Request-Id":
+				return key, true
+			case "If-Match":
+				return key, true
+			case "Idempotency-Key":
+				return key, true
+			default:
+				return "", false
+			}
+		}),
+		runtime.WithOutgoingHeaderMatcher(func(key string) (string, bool) {
+			switch key {
+			case "X-Request-Id":
+				return key, true
+			case "etag":
+				return "ETag", true
+			case "content-disposition":
+				return "Content-Disposition", true
+			default:
+				return "", false
+			}
+		}),
+		runtime.WithErrorHandler(errorHandler),
+		runtime.WithForwardResponseOption(addGatewayVersion),
+	)
+
+	// Register services
+	ctx := context.Background()
+	userClient := user_v1_pb.NewUserServiceClient(conn)
+	if err := user_v1_pb.RegisterUserServiceHandlerClient(ctx, mux, userClient); err != nil {
+		return nil, fmt.Errorf("failed to register user service handler: %w", err)
+	}
+
+	tenantClient := user_v1_pb.NewTenantSettingsServiceClient(conn)
+	err := user_v1_pb.RegisterTenantSettingsServiceHandlerClient(ctx, mux, tenantClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register tenant settings service handler: %w", err)
+	}
+
+	authClient := auth_v1_pb.NewAuthServiceClient(conn)
+	if err := auth_v1_pb.RegisterAuthServiceHandlerClient(ctx, mux, authClient); err != nil {
+		return nil, fmt.Errorf("failed to register auth service handler: %w", err)
+	}
+
+	return &Gateway{
+		mux:       mux,
+		grpcConn:  conn,
+		staticDir: staticDir,
+		apiPrefix: apiPrefix,
+		proxies:   make(map[string]http.Handler),
+		webhooks:  make(map[string]http.Handler),
+	}, nil
+}
+
+// Configure applies the gateway settings of cfg beyond the gRPC connection:
+// response caching, required logins, proxy routes, webhooks, security headers,
+// the breaker and the asset origin.
+func (g *Gateway) Configure(cfg configs.Config) error {
+	if len(cfg.Gateway.CachePaths) > 0 || cfg.Webhooks.GithubSecret != "" {
+		redis_client.SetConfig(cfg.Redis.Urls, cfg.Redis.Password)
+	}
+	if len(cfg.Gateway.CachePaths) > 0 {
+		g.EnableCache(
+			redis_client.GetRDB(),
+			cfg.Gateway.CachePaths,
+			cfg.Gateway.CacheTTL,
+			cfg.Gateway.CacheStale,
+		)
+	}
+	g.RequireLogin(cfg.Gateway.ProtectedPrefixes, cfg.Gateway.LoginURL, cfg.Auth)
+	if err := g.AddProxyRoutes(cfg.Gateway.Routes, cfg.Auth); err != nil {
+		return fmt.Errorf("invalid gateway routes: %w", err)
+	}
+	if cfg.Webhooks.GithubSecret != "" {
+		g.EnableWebhooks(cfg.Webhooks, cfg.Auth.InternalToken, redis_client.GetRDB())
+	}
+	g.EnableSecurityHeaders(cfg.Gateway.SecurityHeaders)
+	if err := g.EnableBreaker(cfg.Gateway); err != nil {
+		return fmt.Errorf("invalid gateway configuration: %w", err)
+	}
+	if cfg.Gateway.AssetOrigin != "" {
+		if err := g.UseAssetOrigin(cfg.Gateway.AssetOrigin); err != nil {
+			return fmt.Errorf("invalid gateway configuration: %w", err)
+		}
+	}
+	return nil
+}
+
+// HTTPServer returns the HTTP server serving the gateway on cfg.Server.HTTPPort.
+func (g *Gateway) HTTPServer(cfg configs.Config) *http.Server {
+	httpServer := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.Server.HTTPPort),
+		Handler: g.Handler(),
+	}
+	if cfg.Gateway.H2C {
+		httpServer.Protocols = new(http.Protocols)
+		httpServer.Protocols.SetHTTP1(true)
+		httpServer.Protocols.SetUnencryptedHTTP2(true)
+	}
+	return httpServer
+}
+
+// RequireLogin answers requests to the API paths under prefixes that carry no
+// valid user token with 401 before they reach the gRPC server.
+func (g *Gateway) RequireLogin(prefixes []string, loginURL string, authCfg configs.AuthConfig) {
+	g.protectedPrefixes = prefixes
+	g.loginURL = loginURL
+	g.parseToken = newTokenParser(authCfg)
+}
+
+// EnableSecurityHeaders adds the configured security headers to responses.
+func (g *Gateway) EnableSecurityHeaders(cfg configs.SecurityHeadersConfig) {
+	g.headers = newSecurityHeaders(cfg)
+}
+
+// EnableCache caches the responses of the public GET endpoints under paths in Redis.
+func (g *Gateway) EnableCache(
+	rdb redis.UniversalClient,
+	paths []string,
+	ttl, stale time.Duration,
+) {
+	g.cache = newResponseCache(rdb, paths, ttl, stale)
+}
+
+// UseAssetOrigin leaves serving the frontend assets to origin, e.g. a CDN, while
+// the gateway keeps serving index.html, pointed at it, and the API.
+func (g *Gateway) UseAssetOrigin(origin string) error {
+	u, err := parseAssetOrigin(origin)
+	if err != nil {
+		return err
+	}
+	g.assetOrigin = u
+	return nil
+}
+
+// EnableBreaker answers API calls with an error and browsers with a
+// maintenance page while the gRPC server is unavailable or under maintenance.
+func (g *Gateway) EnableBreaker(cfg configs.GatewayConfig) error {
+	breaker, err := newUpstreamBreaker(g.mux, cfg)
+	if err != nil {
+		return err
+	}
+	g.breaker = breaker
+	return nil
+}
+
+// EnableWebhooks receives the webhooks of the providers with a configured
+// secret, recording the deliveries processed in rdb.
+func (g *Gateway) EnableWebhooks(
+	cfg configs.WebhooksConfig,
+	internalToken string,
+	rdb redis.UniversalClient,
+) {
+	if cfg.GithubSecret != "" {
+		client := auth_v1_pb.NewAuthServiceClient(g.grpcConn)
+		g.webhooks["/webhooks/github"] = newGitHubWebhook(cfg, internalToken, client, rdb)
+	}
+}
+
+// AddProxyRoutes routes further path prefixes to other backends, authenticating
+// their requests with the same user tokens as the API.
+func (g *Gateway) AddProxyRoutes(routes []configs.GatewayRoute, authCfg configs.AuthConfig) error {
+	parseToken := newTokenParser(authCfg)
+	for _, route := range routes {
+		if strings.HasPrefix(route.Prefix, g.apiPrefix) ||
+			strings.HasPrefix(g.apiPrefix, route.Prefix) {
+			return fmt.Errorf("route %s overlaps the API prefix %s", route.Prefix, g.apiPrefix)
+		}
+		if _, ok := g.proxies[route.Prefix]; ok {
+			return fmt.Errorf("route %s is configured twice", route.Prefix)
+		}
+		handler, err := newProxyHandler(g.mux, route, parseToken)
+		if err != nil {
+			return err
+		}
+		g.proxies[route.Prefix] = handler
+		slog.Info("proxy route added", "prefix", route.Prefix, "backend", route.Backend,
+			"auth", route.Auth)
+	}
+	return nil
+}
+
+// Handler returns an HTTP handler with CORS support and static file serving
+func (g *Gateway) Handler() http.Handler {
+	// Setup CORS
+	c := cors.New(cors.Options{
+		AllowedOrigins: []string{"*"}, // In production, specify your frontend domains
+		AllowedMethods: []string{
+			http.MethodGet,
+			http.MethodPost,
+			http.MethodPut,
+			http.MethodPatch,
+			http.MethodDelete,
+			http.MethodOptions,
+		},
+		AllowedHeaders: []string{
+			"Accept",
+			"Accept-Language",
+			"Content-Language",
+			"Content-Type",
+			"Authorization",
+			"X-Request-Id",
+			"If-Match",
+			"Idempotency-Key",
+			"Grpc-Timeout",
+		},
+		ExposedHeaders: []string{
+			"X-Request-Id",
+			gatewayVersionHeader,
+			"ETag",
+			"Content-Disposition",
+		},
+		AllowCredentials: true,
+	})
+
+	// Create a multiplexer that handles both API and static files
+	mux := http.NewServeMux()
+
+	// Handle API routes with the gRPC gateway
+	api := requireLogin(
+		g.mux,
+		g.protectedPrefixes,
+		g.loginURL,
+		g.parseToken,
+		g.cache.middleware(requireIfMatch(g.mux, g.breaker.api(g.mux))),
+	)
+	api = g.headers.api(api)
+	mux.Handle(g.apiPrefix, http.StripPrefix(strings.TrimSuffix(g.apiPrefix, "/"), api))
+
+	// Handle the routes to other backends
+	for prefix, handler := range g.proxies {
+		mux.Handle(prefix, g.headers.proxied(handler))
+	}
+
+	// Handle provider webhooks
+	for path, handler := range g.webhooks {
+		mux.Handle(path, g.headers.api(handler))
+	}
+
+	// Handle static files for the frontend
+	if g.staticDir != "" {
+		// Check if static directory exists
+		if _, err := os.Stat(g.staticDir); err == nil {
+			// Serve static files, with index.html as fallback for SPA
+			headers, assetOrigin := g.headers, ""
+			if g.assetOrigin != nil {
+				assetOrigin = g.assetOrigin.String()
+				headers = headers.withAssetSource(g.assetOrigin.Scheme + "://" + g.assetOrigin.Host)
+			}
+			static := newStaticFiles(g.staticDir, g.apiPrefix, assetOrigin)
+			mux.Handle("/", headers.frontend(g.breaker.frontend(static)))
+			slog.Info("Static file serving enabled", "directory", g.staticDir,
+				"asset_origin", assetOrigin)
+		} else {
+			slog.Warn("Static directory not found, serving API only", "directory", g.staticDir)
+			// If static directory doesn't exist, just serve the API
+			mux.Handle("/", api)
+		}
+	} else {
+		// If no static directory specified, just serve the API
+		mux.Handle("/", api)
+	}
+
+	return c.Handler(mux)
+}
+
+// Close closes the gRPC connection
+func (g *Gateway) Close() error {
+	if closer, ok := g.grpcConn.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
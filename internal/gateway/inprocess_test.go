@@ -0,0 +1,73 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/server"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc"
+)
+
+// callerService answers GetUser with the user calling it.
+type callerService struct {
+	user_v1_pb.UnimplementedUserServiceServer
+}
+
+func (callerService) GetUser(
+	ctx context.Context,
+	_ *user_v1_pb.GetUserRequest,
+) (*user_v1_pb.GetUserResponse, error) {
+	userInfo, _ := ctx.Value(auth.ContextKeyUserInfo).(*auth.UserInfo)
+	return &user_v1_pb.GetUserResponse{
+		User: &user_v1_pb.User{Id: userInfo.UserID},
+	}, nil
+}
+
+// newInProcessGateway returns a gateway calling the services register
+// registers on a gRPC server in-process.
+func newInProcessGateway(t *testing.T, register func(*grpc.Server)) *Gateway {
+	t.Helper()
+	srv := server.NewBuilder(configs.Config{Auth: testAuthConfig}).Build()
+	register(srv)
+	conn, err := server.ServeInProcess(srv)
+	if err != nil {
+		t.Fatalf("ServeInProcess failed: %v", err)
+	}
+	gateway, err := NewGatewayWithConn(conn, "", "/api/")
+	if err != nil {
+		t.Fatalf("NewGatewayWithConn failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = gateway.Close()
+		srv.Stop()
+	})
+	return gateway
+}
+
+func TestInProcessGateway(t *testing.T) {
+	gateway := newInProcessGateway(t, func(srv *grpc.Server) {
+		user_v1_pb.RegisterUserServiceServer(srv, callerService{})
+	})
+	handler := gateway.Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/users/user-1", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a token, got %d %s", rec.Code, rec.Body)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/user-1", nil)
+	req.Header.Set("Authorization", "Bearer "+signProxyTestToken(t, model.UserRoleUser, false))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"user-1"`) {
+		t.Errorf("expected the caller, got %d %s", rec.Code, rec.Body)
+	}
+}
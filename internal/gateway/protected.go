@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/poly-workshop/auth-portal/internal/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// requireLogin rejects requests to the protected API paths that carry no valid
+// user token with 401, without calling the gRPC server. The LOGIN_REQUIRED
+// ErrorInfo tells clients where to log in; the error handler of mux adds the
+// WWW-Authenticate challenge. Everything else, including the authorization of
+// valid tokens, is left to the gRPC server.
+func requireLogin(
+	mux *runtime.ServeMux,
+	prefixes []string,
+	loginURL string,
+	parseToken tokenParser,
+	next http.Handler,
+) http.Handler {
+	if len(prefixes) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !matchesPrefix(r.URL.Path, prefixes) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if ok && token != "" {
+			if _, err := parseToken(token); err == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		st := status.New(codes.Unauthenticated, "login required")
+		if detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+			Reason:   service.ErrorReasonLoginRequired,
+			Domain:   service.ErrorDomain,
+			Metadata: map[string]string{"login_url": loginURL},
+		}); err == nil {
+			st = detailed
+		}
+		runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r, st.Err())
+	})
+}
+
+// matchesPrefix reports whether path is one of the prefixes or below one, e.g.
+// "/v1/users" matches "/v1/users", "/v1/users/me" and "/v1/users:batchGet".
+func matchesPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if path == prefix || strings.HasPrefix(path, prefix+"/") ||
+			strings.HasPrefix(path, prefix+":") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/service"
+)
+
+func TestRequireLogin(t *testing.T) {
+	gateway, err := NewGateway("localhost:0", "", "/api/")
+	if err != nil {
+		t.Fatalf("NewGateway failed: %v", err)
+	}
+	t.Cleanup(func() { _ = gateway.Close() })
+	gateway.RequireLogin([]string{"/v1/users"}, "/login", testAuthConfig)
+	handler := gateway.Handler()
+	token := signProxyTestToken(t, model.UserRoleUser, false)
+
+	for _, tc := range []struct {
+		path, token string
+		rejected    bool
+	}{
+		{"/api/v1/users/me", "", true},
+		{"/api/v1/users:batchGet", "", true},
+		{"/api/v1/users/me", "not-a-token", true},
+		{"/v1/users/me", "", true},
+		// Valid tokens and other paths are left to the gRPC server
+		{"/api/v1/users/me", token, false},
+		{"/api/v1/usersettings", "", false},
+		{"/api/v1/public-config", "", false},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		var body struct {
+			Details []struct {
+				Reason   string            `json:"reason"`
+				Metadata map[string]string `json:"metadata"`
+			} `json:"details"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		rejected := rec.Code == http.StatusUnauthorized && len(body.Details) == 1 &&
+			body.Details[0].Reason == service.ErrorReasonLoginRequired
+		if rejected != tc.rejected {
+			t.Errorf("%s (token %q): expected rejected=%v, got %d %s",
+				tc.path, tc.token, tc.rejected, rec.Code, rec.Body)
+			continue
+		}
+		if rejected && body.Details[0].Metadata["login_url"] != "/login" {
+			t.Errorf("%s: expected the login url in the error, got %v", tc.path, body.Details)
+		}
+		challenge := rec.Header().Get("WWW-Authenticate")
+		if rejected && !strings.HasPrefix(challenge, `Bearer realm="auth-portal"`) {
+			t.Errorf("%s: expected a bearer challenge, got %q", tc.path, challenge)
+		}
+	}
+}
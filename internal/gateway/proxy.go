@@ -0,0 +1,137 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Headers telling proxied backends who the user is. They are removed from
+// incoming requests, so backends only reachable through the gateway can trust them.
+const (
+	headerUserID   = "X-Auth-User-Id"
+	headerUserRole = "X-Auth-User-Role"
+)
+
+// proxyUserKey is the context key of the user authenticated for a proxied request.
+type proxyUserKey struct{}
+
+// tokenParser validates user tokens like the gRPC server does, without the
+// session and role version checks that need Redis.
+type tokenParser func(token string) (*auth.UserInfo, error)
+
+func newTokenParser(cfg configs.AuthConfig) tokenParser {
+	opts := []utils.ValidationOption{
+		utils.WithExpectedIssuer(cfg.JWTIssuer),
+		utils.WithExpectedAudience(cfg.JWTAudience),
+		utils.WithLegacyTokens(!cfg.JWTRejectLegacyTokens),
+		utils.WithValidMethods(cfg.JWTValidMethods...),
+		utils.WithLeeway(cfg.JWTLeeway),
+	}
+	return func(token string) (*auth.UserInfo, error) {
+		return auth.ParseUserToken(token, cfg.JWTSecret, opts...)
+	}
+}
+
+// newProxyHandler forwards the requests of a route to its backend, after
+// authenticating them as configured.
+func newProxyHandler(
+	mux *runtime.ServeMux,
+	route configs.GatewayRoute,
+	parseToken tokenParser,
+) (http.Handler, error) {
+	if !strings.HasPrefix(route.Prefix, "/") || !strings.HasSuffix(route.Prefix, "/") ||
+		route.Prefix == "/" {
+		return nil, fmt.Errorf("prefix %q must start and end with a slash", route.Prefix)
+	}
+	target, err := url.Parse(route.Backend)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("backend %q of %s is not an http(s) URL", route.Backend,
+			route.Prefix)
+	}
+	switch route.Auth {
+	case configs.GatewayAuthRequired, configs.GatewayAuthOptional, configs.GatewayAuthNone:
+	default:
+		return nil, fmt.Errorf("unknown auth mode %q of %s", route.Auth, route.Prefix)
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			pr.Out.Header.Del(headerUserID)
+			pr.Out.Header.Del(headerUserRole)
+			// Like the API, internal tokens can't be used through the gateway
+			pr.Out.Header.Del("X-Token-Type")
+			if user, ok := pr.In.Context().Value(proxyUserKey{}).(*auth.UserInfo); ok {
+				var role model.UserRole
+				role.FromPb(user.Role)
+				pr.Out.Header.Set(headerUserID, user.UserID)
+				pr.Out.Header.Set(headerUserRole, string(role))
+			}
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			slog.WarnContext(r.Context(), "proxied request failed",
+				"error", err, "prefix", route.Prefix, "backend", route.Backend)
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+
+	var handler http.Handler = proxy
+	if route.StripPrefix {
+		handler = http.StripPrefix(strings.TrimSuffix(route.Prefix, "/"), handler)
+	}
+	if route.Auth != configs.GatewayAuthNone {
+		required := route.Auth == configs.GatewayAuthRequired
+		handler = authenticateProxied(mux, required, parseToken, handler)
+	}
+	return handler, nil
+}
+
+// authenticateProxied validates the bearer token of a proxied request and puts
+// the user into its context. Requests with an invalid token are rejected, and
+// so are anonymous ones if required is set.
+func authenticateProxied(
+	mux *runtime.ServeMux,
+	required bool,
+	parseToken tokenParser,
+	next http.Handler,
+) http.Handler {
+	reject := func(w http.ResponseWriter, r *http.Request, err error) {
+		runtime.HTTPError(r.Context(), mux, &runtime.JSONPb{}, w, r, err)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			if required {
+				reject(w, r, status.Error(codes.Unauthenticated, "missing authorization token"))
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		user, err := parseToken(token)
+		if err != nil {
+			reject(w, r, status.Error(codes.Unauthenticated, "invalid token"))
+			return
+		}
+		// Restricted tokens only grant the password change
+		if user.MustChangePassword {
+			reject(w, r, auth.ErrPasswordChangeRequired)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), proxyUserKey{}, user)))
+	})
+}
@@ -0,0 +1,168 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/utils"
+)
+
+var testAuthConfig = configs.AuthConfig{
+	JWTSecret:       "test-secret",
+	JWTIssuer:       "auth-portal",
+	JWTAudience:     "auth-portal",
+	JWTValidMethods: []string{"HS256"},
+}
+
+// seen is what the backend received.
+type seen struct {
+	Path          string `json:"path"`
+	UserID        string `json:"user_id"`
+	UserRole      string `json:"user_role"`
+	Authorization string `json:"authorization"`
+}
+
+func newProxyTestGateway(t *testing.T, routes ...configs.GatewayRoute) *httptest.Server {
+	t.Helper()
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(seen{
+			Path:          r.URL.Path,
+			UserID:        r.Header.Get(headerUserID),
+			UserRole:      r.Header.Get(headerUserRole),
+			Authorization: r.Header.Get("Authorization"),
+		})
+	}))
+	t.Cleanup(backend.Close)
+
+	gateway, err := NewGateway("localhost:0", "", "/api/")
+	if err != nil {
+		t.Fatalf("NewGateway failed: %v", err)
+	}
+	t.Cleanup(func() { _ = gateway.Close() })
+	for i := range routes {
+		routes[i].Backend = backend.URL
+	}
+	if err := gateway.AddProxyRoutes(routes, testAuthConfig); err != nil {
+		t.Fatalf("AddProxyRoutes failed: %v", err)
+	}
+	srv := httptest.NewServer(gateway.Handler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func signProxyTestToken(t *testing.T, role model.UserRole, mustChangePassword bool) string {
+	t.Helper()
+	expiresAt := time.Now().Add(time.Hour)
+	claims := utils.NewUserTokenClaimsWithExpiration("user-1", role, expiresAt)
+	claims.SetIssuer(testAuthConfig.JWTIssuer, testAuthConfig.JWTAudience)
+	if mustChangePassword {
+		claims.MapClaims[utils.ClaimMustChangePassword] = true
+	}
+	token, err := utils.SignUserToken(claims, testAuthConfig.JWTSecret, expiresAt)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token.Token
+}
+
+func TestProxyRoutes(t *testing.T) {
+	srv := newProxyTestGateway(t,
+		configs.GatewayRoute{Prefix: "/workshop/", StripPrefix: true, Auth: "required"},
+		configs.GatewayRoute{Prefix: "/public/", Auth: "optional"},
+	)
+	adminToken := signProxyTestToken(t, model.UserRoleAdmin, false)
+
+	for _, tc := range []struct {
+		name, path, token, spoofedUser string
+		wantStatus                     int
+		want                           seen
+	}{
+		{
+			name:       "authenticated",
+			path:       "/workshop/v1/items",
+			token:      adminToken,
+			wantStatus: http.StatusOK,
+			want: seen{
+				Path:          "/v1/items",
+				UserID:        "user-1",
+				UserRole:      "admin",
+				Authorization: "Bearer " + adminToken,
+			},
+		},
+		{name: "anonymous", path: "/workshop/v1/items", wantStatus: http.StatusUnauthorized},
+		{
+			name:       "invalid token",
+			path:       "/workshop/v1/items",
+			token:      "not-a-token",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "password change pending",
+			path:       "/workshop/v1/items",
+			token:      signProxyTestToken(t, model.UserRoleUser, true),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:        "optional auth drops spoofed identity",
+			path:        "/public/page",
+			spoofedUser: "admin-1",
+			wantStatus:  http.StatusOK,
+			want:        seen{Path: "/public/page"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+tc.path, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			if tc.spoofedUser != "" {
+				req.Header.Set(headerUserID, tc.spoofedUser)
+				req.Header.Set(headerUserRole, "admin")
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+			if resp.StatusCode != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, resp.StatusCode)
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			var got seen
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("failed to decode backend response: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("expected the backend to see %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestAddProxyRoutesRejectsInvalidRoutes(t *testing.T) {
+	for _, route := range []configs.GatewayRoute{
+		{Prefix: "workshop/", Backend: "http://localhost:8090", Auth: "required"},
+		{Prefix: "/workshop", Backend: "http://localhost:8090", Auth: "required"},
+		{Prefix: "/api/other/", Backend: "http://localhost:8090", Auth: "required"},
+		{Prefix: "/", Backend: "http://localhost:8090", Auth: "required"},
+		{Prefix: "/workshop/", Backend: "localhost:8090", Auth: "required"},
+		{Prefix: "/workshop/", Backend: "http://localhost:8090", Auth: "sometimes"},
+	} {
+		gateway, err := NewGateway("localhost:0", "", "/api/")
+		if err != nil {
+			t.Fatalf("NewGateway failed: %v", err)
+		}
+		err = gateway.AddProxyRoutes([]configs.GatewayRoute{route}, testAuthConfig)
+		if err == nil {
+			t.Errorf("expected %+v to be rejected", route)
+		}
+		_ = gateway.Close()
+	}
+}
@@ -0,0 +1,177 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/poly-workshop/auth-portal/configs"
+)
+
+// apiContentSecurityPolicy allows API responses nothing, so a JSON response
+// rendered as a document can't run or load anything.
+const apiContentSecurityPolicy = "default-src 'none'"
+
+// securityHeaders adds the configured security headers to responses; nil adds
+// none.
+type securityHeaders struct {
+	// common are sent with every response
+	common http.Header
+	// frontendCSP and apiCSP are the Content-Security-Policy of the frontend and
+	// of API responses
+	frontendCSP string
+	apiCSP      string
+}
+
+func newSecurityHeaders(cfg configs.SecurityHeadersConfig) *securityHeaders {
+	if !cfg.Enabled {
+		return nil
+	}
+	common := http.Header{}
+	common.Set("X-Content-Type-Options", "nosniff")
+	if cfg.ReferrerPolicy != "" {
+		common.Set("Referrer-Policy", cfg.ReferrerPolicy)
+	}
+	if cfg.HSTSMaxAge > 0 {
+		hsts := fmt.Sprintf("max-age=%d", int64(cfg.HSTSMaxAge.Seconds()))
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if cfg.HSTSPreload {
+			hsts += "; preload"
+		}
+		common.Set("Strict-Transport-Security", hsts)
+	}
+	frameAncestors := "frame-ancestors 'none'"
+	if len(cfg.FrameAncestors) > 0 {
+		frameAncestors = "frame-ancestors " + strings.Join(cfg.FrameAncestors, " ")
+	} else {
+		// For browsers predating frame-ancestors
+		common.Set("X-Frame-Options", "DENY")
+	}
+	return &securityHeaders{
+		common:      common,
+		frontendCSP: withDirective(cfg.ContentSecurityPolicy, frameAncestors),
+		apiCSP:      withDirective(apiContentSecurityPolicy, frameAncestors),
+	}
+}
+
+// withDirective appends a directive to a policy, unless the policy has one of
+// the same name already.
+func withDirective(policy, directive string) string {
+	name, _, _ := strings.Cut(directive, " ")
+	for _, existing := range strings.Split(policy, ";") {
+		existingName, _, _ := strings.Cut(strings.TrimSpace(existing), " ")
+		if existingName == name {
+			return policy
+		}
+	}
+	policy = strings.TrimSuffix(strings.TrimSpace(policy), ";")
+	if policy == "" {
+		return directive
+	}
+	return policy + "; " + directive
+}
+
+// assetSourceDirectives are the directives of the frontend's policy allowing the
+// sources of its assets.
+var assetSourceDirectives = []string{
+	"default-src",
+	"script-src",
+	"style-src",
+	"img-src",
+	"font-src",
+}
+
+// withAssetSource returns headers whose frontend policy also allows assets
+// from source, e.g. "https://cdn.example.com", in the directives of
+// assetSourceDirectives it has.
+func (s *securityHeaders) withAssetSource(source string) *securityHeaders {
+	if s == nil {
+		return nil
+	}
+	directives := strings.Split(s.frontendCSP, ";")
+	for i, directive := range directives {
+		name, _, _ := strings.Cut(strings.TrimSpace(directive), " ")
+		if slices.Contains(assetSourceDirectives, name) {
+			directives[i] = strings.TrimRight(directive, " ") + " " + source
+		}
+	}
+	headers := *s
+	headers.frontendCSP = strings.Join(directives, ";")
+	return &headers
+}
+
+// frontend adds the headers to the responses of the frontend.
+func (s *securityHeaders) frontend(next http.Handler) http.Handler {
+	if s == nil {
+		return next
+	}
+	return s.wrap(s.frontendCSP, next)
+}
+
+// api adds the headers to API responses.
+func (s *securityHeaders) api(next http.Handler) http.Handler {
+	if s == nil {
+		return next
+	}
+	return s.wrap(s.apiCSP, next)
+}
+
+// proxied adds the headers the backend didn't send to the responses of proxied
+// routes, but no Content-Security-Policy, which depends on the backend's content.
+func (s *securityHeaders) proxied(next http.Handler) http.Handler {
+	if s == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&defaultHeaderWriter{ResponseWriter: w, defaults: s.common}, r)
+	})
+}
+
+func (s *securityHeaders) wrap(csp string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		for name, values := range s.common {
+			header[name] = values
+		}
+		if csp != "" {
+			header.Set("Content-Security-Policy", csp)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// defaultHeaderWriter sets the default headers the handler left unset when the
+// response header is written.
+type defaultHeaderWriter struct {
+	http.ResponseWriter
+	defaults    http.Header
+	wroteHeader bool
+}
+
+func (w *defaultHeaderWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		header := w.Header()
+		for name, values := range w.defaults {
+			if _, ok := header[name]; !ok {
+				header[name] = values
+			}
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *defaultHeaderWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController flush streamed responses.
+func (w *defaultHeaderWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
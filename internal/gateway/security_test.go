@@ -0,0 +1,144 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+)
+
+var testSecurityHeadersConfig = configs.SecurityHeadersConfig{
+	Enabled:               true,
+	HSTSMaxAge:            365 * 24 * time.Hour,
+	HSTSIncludeSubdomains: true,
+	ReferrerPolicy:        configs.DefaultReferrerPolicy,
+	ContentSecurityPolicy: "default-src 'self'",
+}
+
+func serveWith(wrap func(http.Handler) http.Handler, next http.HandlerFunc) http.Header {
+	rec := httptest.NewRecorder()
+	wrap(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	return rec.Result().Header
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	headers := newSecurityHeaders(testSecurityHeadersConfig)
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	frontend := serveWith(headers.frontend, ok)
+	for name, want := range map[string]string{
+		"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+		"X-Content-Type-Options":    "nosniff",
+		"Referrer-Policy":           configs.DefaultReferrerPolicy,
+		"X-Frame-Options":           "DENY",
+		"Content-Security-Policy":   "default-src 'self'; frame-ancestors 'none'",
+	} {
+		if got := frontend.Get(name); got != want {
+			t.Errorf("frontend %s = %q, want %q", name, got, want)
+		}
+	}
+
+	api := serveWith(headers.api, ok)
+	if got, want := api.Get("Content-Security-Policy"),
+		"default-src 'none'; frame-ancestors 'none'"; got != want {
+		t.Errorf("API Content-Security-Policy = %q, want %q", got, want)
+	}
+	if got := api.Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Errorf("API X-Content-Type-Options = %q, want nosniff", got)
+	}
+}
+
+func TestSecurityHeadersProxiedKeepBackendHeaders(t *testing.T) {
+	headers := newSecurityHeaders(testSecurityHeadersConfig)
+	proxied := serveWith(headers.proxied, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		_, _ = w.Write([]byte("ok"))
+	})
+	if got := proxied.Get("Referrer-Policy"); got != "no-referrer" {
+		t.Errorf("Referrer-Policy = %q, want the backend's", got)
+	}
+	if got := proxied.Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Errorf("X-Content-Type-Options = %q, want nosniff", got)
+	}
+	if got := proxied.Get("Content-Security-Policy"); got != "" {
+		t.Errorf("Content-Security-Policy = %q, want none", got)
+	}
+}
+
+func TestSecurityHeadersFrameAncestors(t *testing.T) {
+	cfg := testSecurityHeadersConfig
+	cfg.HSTSMaxAge = 0
+	cfg.FrameAncestors = []string{"'self'", "https://portal.example.com"}
+	cfg.ContentSecurityPolicy = "default-src 'self';"
+	headers := newSecurityHeaders(cfg)
+	frontend := serveWith(headers.frontend, func(http.ResponseWriter, *http.Request) {})
+
+	want := "default-src 'self'; frame-ancestors 'self' https://portal.example.com"
+	if got := frontend.Get("Content-Security-Policy"); got != want {
+		t.Errorf("Content-Security-Policy = %q, want %q", got, want)
+	}
+	if got := frontend.Get("X-Frame-Options"); got != "" {
+		t.Errorf("X-Frame-Options = %q, want none with frame ancestors", got)
+	}
+	if got := frontend.Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("Strict-Transport-Security = %q, want none without max age", got)
+	}
+}
+
+func TestSecurityHeadersDisabled(t *testing.T) {
+	headers := newSecurityHeaders(configs.SecurityHeadersConfig{})
+	if headers != nil {
+		t.Fatalf("newSecurityHeaders returned headers when disabled")
+	}
+	frontend := serveWith(headers.frontend, func(http.ResponseWriter, *http.Request) {})
+	if len(frontend) != 0 {
+		t.Errorf("disabled headers added %v", frontend)
+	}
+}
+
+func TestWithDirective(t *testing.T) {
+	tests := []struct {
+		policy, directive, want string
+	}{
+		{"", "frame-ancestors 'none'", "frame-ancestors 'none'"},
+		{
+			"default-src 'self'",
+			"frame-ancestors 'none'",
+			"default-src 'self'; frame-ancestors 'none'",
+		},
+		{
+			"frame-ancestors 'self'; img-src *",
+			"frame-ancestors 'none'",
+			"frame-ancestors 'self'; img-src *",
+		},
+	}
+	for _, tt := range tests {
+		if got := withDirective(tt.policy, tt.directive); got != tt.want {
+			t.Errorf("withDirective(%q, %q) = %q, want %q", tt.policy, tt.directive, got, tt.want)
+		}
+	}
+}
+
+func TestSecurityHeadersWithAssetSource(t *testing.T) {
+	cfg := testSecurityHeadersConfig
+	cfg.ContentSecurityPolicy = "default-src 'self'; script-src 'self'; connect-src 'self'"
+	headers := newSecurityHeaders(cfg).withAssetSource("https://cdn.example.com")
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	frontend := serveWith(headers.frontend, ok)
+	if got, want := frontend.Get("Content-Security-Policy"),
+		"default-src 'self' https://cdn.example.com; script-src 'self' https://cdn.example.com; "+
+			"connect-src 'self'; frame-ancestors 'none'"; got != want {
+		t.Errorf("frontend Content-Security-Policy = %q, want %q", got, want)
+	}
+	api := serveWith(headers.api, ok)
+	if got, want := api.Get("Content-Security-Policy"),
+		"default-src 'none'; frame-ancestors 'none'"; got != want {
+		t.Errorf("API Content-Security-Policy = %q, want %q", got, want)
+	}
+	if (*securityHeaders)(nil).withAssetSource("https://cdn.example.com") != nil {
+		t.Error("expected disabled headers to stay disabled")
+	}
+}
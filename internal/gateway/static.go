@@ -0,0 +1,198 @@
+package gateway
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	staticRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_gateway_static_requests_total",
+		Help: "Frontend requests served by the gateway, by result.",
+	}, []string{"result"})
+	staticBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_gateway_static_bytes_total",
+		Help: "Bytes of frontend files served by the gateway, by result.",
+	}, []string{"result"})
+)
+
+// Results of frontend requests
+const (
+	// staticHit is a file served as requested
+	staticHit = "hit"
+	// staticNotModified is a file the browser had cached, revalidated with 304
+	staticNotModified = "not_modified"
+	// staticFallback is index.html served for a route of the SPA
+	staticFallback = "fallback"
+	// staticRedirect is an asset request redirected to the asset origin
+	staticRedirect = "redirect"
+	staticNotFound = "not_found"
+	staticError    = "error"
+)
+
+// rootRelativeURL matches the src and href attributes of index.html holding
+// root-relative URLs, e.g. src="/assets/index-4f2a.js".
+var rootRelativeURL = regexp.MustCompile(`(\s(?:src|href)=["'])(/[^/"'][^"']*)`)
+
+// staticFiles serves the frontend files in a directory, with index.html as
+// fallback for the routes of the SPA, and counts the requests and bytes it
+// serves by result. Paths under the API prefix are never answered with the
+// fallback.
+//
+// With an asset origin, e.g. a CDN, the gateway serves index.html only: the
+// root-relative asset URLs in it are rewritten to the origin, whose base URL is
+// also injected as <meta name="asset-base-url">, and requests of assets still
+// reaching the gateway are redirected there. Assets are the paths with a file
+// extension.
+type staticFiles struct {
+	dir        string
+	apiPath    string
+	fileServer http.Handler
+	// assetOrigin is the base URL of the assets without trailing slash, if any
+	assetOrigin string
+}
+
+func newStaticFiles(dir, apiPrefix, assetOrigin string) *staticFiles {
+	return &staticFiles{
+		dir:         dir,
+		apiPath:     strings.TrimSuffix(apiPrefix, "/"),
+		fileServer:  http.FileServer(http.Dir(dir)),
+		assetOrigin: strings.TrimSuffix(assetOrigin, "/"),
+	}
+}
+
+// parseAssetOrigin checks that origin is an absolute HTTP(S) URL.
+func parseAssetOrigin(origin string) (*url.URL, error) {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset origin %q: %w", origin, err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("asset origin %q is not an absolute HTTP(S) URL", origin)
+	}
+	return u, nil
+}
+
+func isAsset(urlPath string) bool {
+	return path.Ext(urlPath) != "" && urlPath != "/index.html"
+}
+
+func (s *staticFiles) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cw := &countingWriter{ResponseWriter: w}
+	fallback := false
+	// Check if the requested file exists
+	file := filepath.Join(s.dir, r.URL.Path)
+	_, err := os.Stat(file)
+	switch {
+	case s.assetOrigin != "" && isAsset(r.URL.Path):
+		http.Redirect(cw, r, s.assetOrigin+r.URL.RequestURI(), http.StatusFound)
+	case os.IsNotExist(err) && !strings.HasPrefix(r.URL.Path, s.apiPath):
+		s.serveIndex(cw, r)
+		fallback = true
+	case s.assetOrigin != "" && (r.URL.Path == "/" || r.URL.Path == "/index.html"):
+		s.serveIndex(cw, r)
+	default:
+		s.fileServer.ServeHTTP(cw, r)
+	}
+	result := staticResult(cw.status, fallback)
+	staticRequests.WithLabelValues(result).Inc()
+	staticBytes.WithLabelValues(result).Add(float64(cw.written))
+}
+
+// serveIndex serves index.html, pointed at the asset origin if there is one.
+func (s *staticFiles) serveIndex(w http.ResponseWriter, r *http.Request) {
+	index := filepath.Join(s.dir, "index.html")
+	if s.assetOrigin == "" {
+		http.ServeFile(w, r, index)
+		return
+	}
+	info, err := os.Stat(index)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	content, err := os.ReadFile(index)
+	if err != nil {
+		http.Error(w, "failed to read index.html", http.StatusInternalServerError)
+		return
+	}
+	content = rewriteAssetURLs(content, s.assetOrigin)
+	http.ServeContent(w, r, "index.html", info.ModTime(), bytes.NewReader(content))
+}
+
+// rewriteAssetURLs points the root-relative asset URLs of an HTML document at
+// origin and injects origin as <meta name="asset-base-url"> into its head.
+func rewriteAssetURLs(document []byte, origin string) []byte {
+	document = rootRelativeURL.ReplaceAllFunc(document, func(attr []byte) []byte {
+		match := rootRelativeURL.FindSubmatch(attr)
+		urlPath, _, _ := strings.Cut(string(match[2]), "?")
+		urlPath, _, _ = strings.Cut(urlPath, "#")
+		if !isAsset(urlPath) {
+			return attr
+		}
+		rewritten := append([]byte(nil), match[1]...)
+		return append(append(rewritten, origin...), match[2]...)
+	})
+	meta := fmt.Sprintf(`<meta name="asset-base-url" content="%s/">`, html.EscapeString(origin))
+	if i := bytes.Index(document, []byte("</head>")); i >= 0 {
+		document = append(document[:i:i], append([]byte(meta), document[i:]...)...)
+	}
+	return document
+}
+
+// staticResult classifies a frontend response by its status.
+func staticResult(status int, fallback bool) string {
+	switch {
+	case status == http.StatusNotFound:
+		return staticNotFound
+	case status >= http.StatusBadRequest:
+		return staticError
+	case status == http.StatusNotModified:
+		return staticNotModified
+	case status == http.StatusFound:
+		return staticRedirect
+	case fallback:
+		return staticFallback
+	default:
+		return staticHit
+	}
+}
+
+// countingWriter records the status and the body size of a response.
+type countingWriter struct {
+	http.ResponseWriter
+	status  int
+	written int64
+}
+
+func (w *countingWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *countingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,126 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestStaticHandler(t *testing.T) {
+	dir := t.TempDir()
+	index := "<html>portal</html>"
+	asset := "console.log('portal')"
+	for name, content := range map[string]string{
+		"index.html": index,
+		"app.js":     asset,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	handler := newStaticFiles(dir, "/api/", "")
+
+	for _, tc := range []struct {
+		path   string
+		header http.Header
+		status int
+		result string
+		bytes  int
+	}{
+		{path: "/app.js", status: http.StatusOK, result: staticHit, bytes: len(asset)},
+		{path: "/users/42", status: http.StatusOK, result: staticFallback, bytes: len(index)},
+		{
+			path: "/app.js",
+			header: http.Header{"If-Modified-Since": {
+				time.Now().Add(time.Hour).UTC().Format(http.TimeFormat),
+			}},
+			status: http.StatusNotModified,
+			result: staticNotModified,
+		},
+		{path: "/api", status: http.StatusNotFound, result: staticNotFound},
+	} {
+		requests := testutil.ToFloat64(staticRequests.WithLabelValues(tc.result))
+		served := testutil.ToFloat64(staticBytes.WithLabelValues(tc.result))
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		for name, values := range tc.header {
+			req.Header[name] = values
+		}
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != tc.status {
+			t.Errorf("%s: status %d, want %d", tc.path, rec.Code, tc.status)
+		}
+		if got := testutil.ToFloat64(staticRequests.WithLabelValues(tc.result)); got != requests+1 {
+			t.Errorf("%s: expected a %s request to be counted", tc.path, tc.result)
+		}
+		if tc.bytes > 0 {
+			got := testutil.ToFloat64(staticBytes.WithLabelValues(tc.result)) - served
+			if got != float64(tc.bytes) {
+				t.Errorf("%s: counted %v bytes, want %d", tc.path, got, tc.bytes)
+			}
+		}
+	}
+}
+
+func TestStaticFilesAssetOrigin(t *testing.T) {
+	dir := t.TempDir()
+	index := `<html><head><script type="module" src="/assets/index-4f2a.js"></script>` +
+		`<link rel="stylesheet" href="/assets/index-9c1e.css?v=2"><link rel="manifest" ` +
+		`href="https://example.com/app.webmanifest"></head><body><a href="/login">` +
+		`Log in</a></body></html>`
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte(index), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	handler := newStaticFiles(dir, "/api/", "https://cdn.example.com/portal/")
+
+	for _, path := range []string{"/", "/index.html", "/users/42"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d, want 200", path, rec.Code)
+		}
+		body := rec.Body.String()
+		for _, want := range []string{
+			`src="https://cdn.example.com/portal/assets/index-4f2a.js"`,
+			`href="https://cdn.example.com/portal/assets/index-9c1e.css?v=2"`,
+			`href="https://example.com/app.webmanifest"`,
+			`href="/login"`,
+			`<meta name="asset-base-url" content="https://cdn.example.com/portal/"></head>`,
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("%s: expected %s in %s", path, want, body)
+			}
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/assets/index-4f2a.js?v=1", nil))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("asset: status %d, want 302", rec.Code)
+	}
+	if got, want := rec.Header().Get("Location"),
+		"https://cdn.example.com/portal/assets/index-4f2a.js?v=1"; got != want {
+		t.Errorf("asset redirected to %s, want %s", got, want)
+	}
+}
+
+func TestParseAssetOrigin(t *testing.T) {
+	for origin, valid := range map[string]bool{
+		"https://cdn.example.com":        true,
+		"http://localhost:5173/portal/":  true,
+		"cdn.example.com":                false,
+		"/static":                        false,
+		"ftp://cdn.example.com/frontend": false,
+	} {
+		if _, err := parseAssetOrigin(origin); (err == nil) != valid {
+			t.Errorf("%s: expected valid=%v, got %v", origin, valid, err)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/buildinfo"
+	"google.golang.org/protobuf/proto"
+)
+
+// gatewayVersionHeader carries the version of the gateway on the responses of
+// /api/version, which report that of the gRPC server, so both can be compared.
+const gatewayVersionHeader = "X-Gateway-Version"
+
+// addGatewayVersion is a forward response option adding gatewayVersionHeader
+// to GetServerInfo responses.
+func addGatewayVersion(_ context.Context, w http.ResponseWriter, msg proto.Message) error {
+	if _, ok := msg.(*auth_v1_pb.GetServerInfoResponse); ok {
+		w.Header().Set(gatewayVersionHeader, buildinfo.Get().Version)
+	}
+	return nil
+}
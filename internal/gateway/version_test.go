@@ -0,0 +1,40 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/buildinfo"
+	"google.golang.org/grpc"
+)
+
+// versionService reports a server version other than the gateway's.
+type versionService struct {
+	auth_v1_pb.UnimplementedAuthServiceServer
+}
+
+func (versionService) GetServerInfo(
+	context.Context,
+	*auth_v1_pb.GetServerInfoRequest,
+) (*auth_v1_pb.GetServerInfoResponse, error) {
+	return &auth_v1_pb.GetServerInfoResponse{Version: "v1.2.3", Commit: "abc123"}, nil
+}
+
+func TestVersionEndpoint(t *testing.T) {
+	gateway := newInProcessGateway(t, func(srv *grpc.Server) {
+		auth_v1_pb.RegisterAuthServiceServer(srv, versionService{})
+	})
+
+	rec := httptest.NewRecorder()
+	gateway.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/version", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"v1.2.3"`) {
+		t.Fatalf("expected the server version without a token, got %d %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get(gatewayVersionHeader); got != buildinfo.Get().Version {
+		t.Errorf("expected the gateway version %q, got %q", buildinfo.Get().Version, got)
+	}
+}
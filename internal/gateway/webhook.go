@@ -0,0 +1,194 @@
+package gateway
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxWebhookBody bounds the webhook payloads read; the events handled are small
+	maxWebhookBody = 1 << 20
+	// webhookTimeout bounds the call of the gRPC server per webhook
+	webhookTimeout = 10 * time.Second
+	// webhookDeliveryTTL is how long deliveries are remembered; GitHub
+	// redelivers for up to three days
+	webhookDeliveryTTL = 72 * time.Hour
+)
+
+// identityRevoker is the part of the auth service client the webhooks call.
+type identityRevoker interface {
+	RevokeProviderIdentity(
+		ctx context.Context,
+		in *auth_v1_pb.RevokeProviderIdentityRequest,
+		opts ...grpc.CallOption,
+	) (*auth_v1_pb.RevokeProviderIdentityResponse, error)
+}
+
+// githubWebhook receives the webhook of the GitHub App users log in with. When
+// a user revokes the app's authorization, their sessions are revoked (and the
+// identity unlinked, if configured) through the gRPC server, called with the
+// internal token. Deliveries are processed once: redeliveries of one already
+// handled are answered without calling the server again.
+type githubWebhook struct {
+	secret        []byte
+	unlink        bool
+	internalToken string
+	revoker       identityRevoker
+	rdb           redis.UniversalClient
+}
+
+func newGitHubWebhook(
+	cfg configs.WebhooksConfig,
+	internalToken string,
+	revoker identityRevoker,
+	rdb redis.UniversalClient,
+) *githubWebhook {
+	return &githubWebhook{
+		secret:        []byte(cfg.GithubSecret),
+		unlink:        cfg.GithubRevocation == configs.RevocationUnlink,
+		internalToken: internalToken,
+		revoker:       revoker,
+		rdb:           rdb,
+	}
+}
+
+// githubEvent holds the fields of the handled events.
+type githubEvent struct {
+	Action string `json:"action"`
+	Sender struct {
+		ID int64 `json:"id"`
+	} `json:"sender"`
+}
+
+func (h *githubWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	if !h.validSignature(r.Header.Get("X-Hub-Signature-256"), body) {
+		slog.WarnContext(r.Context(), "github webhook with invalid signature",
+			"delivery", r.Header.Get("X-GitHub-Delivery"))
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	delivery := r.Header.Get("X-GitHub-Delivery")
+	if !h.claimDelivery(r.Context(), delivery) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	eventType := r.Header.Get("X-GitHub-Event")
+	var event githubEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if eventType != "github_app_authorization" || event.Action != "revoked" {
+		// Pings and events subscribed to for other purposes
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if event.Sender.ID == 0 {
+		http.Error(w, "missing sender", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), webhookTimeout)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx,
+		"authorization", "Bearer "+h.internalToken,
+		"x-token-type", "internal")
+	resp, err := h.revoker.RevokeProviderIdentity(ctx, &auth_v1_pb.RevokeProviderIdentityRequest{
+		Provider:       "github",
+		ProviderUserId: strconv.FormatInt(event.Sender.ID, 10),
+		Unlink:         h.unlink,
+		Reason:         "authorization_revoked",
+	})
+	switch {
+	case status.Code(err) == codes.NotFound:
+		// The GitHub account isn't linked to any user
+	case err != nil:
+		slog.ErrorContext(r.Context(), "failed to revoke github identity", "error", err,
+			"delivery", delivery)
+		// Let the delivery be redelivered
+		h.releaseDelivery(r.Context(), delivery)
+		http.Error(w, "failed to process event", http.StatusBadGateway)
+		return
+	default:
+		slog.InfoContext(r.Context(), "github authorization revoked",
+			"sessions", resp.RevokedSessions, "unlinked", resp.Unlinked)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// claimDelivery records the delivery as processed and reports whether it was
+// new. Deliveries without an ID, or whose claim fails, are processed anyway:
+// revoking twice is harmless, missing a revocation is not.
+func (h *githubWebhook) claimDelivery(ctx context.Context, delivery string) bool {
+	if delivery == "" {
+		return true
+	}
+	claimed, err := h.rdb.SetNX(ctx, deliveryKey(delivery), 1, webhookDeliveryTTL).Result()
+	if err != nil {
+		slog.WarnContext(ctx, "failed to record github delivery", "error", err,
+			"delivery", delivery)
+		return true
+	}
+	if !claimed {
+		slog.InfoContext(ctx, "github delivery already processed", "delivery", delivery)
+	}
+	return claimed
+}
+
+func (h *githubWebhook) releaseDelivery(ctx context.Context, delivery string) {
+	if delivery == "" {
+		return
+	}
+	if err := h.rdb.Del(context.WithoutCancel(ctx), deliveryKey(delivery)).Err(); err != nil {
+		slog.WarnContext(ctx, "failed to release github delivery", "error", err,
+			"delivery", delivery)
+	}
+}
+
+func deliveryKey(delivery string) string {
+	return "webhook_delivery:github:" + delivery
+}
+
+// validSignature checks the X-Hub-Signature-256 header, the hex HMAC-SHA256 of
+// the body keyed with the webhook secret.
+func (h *githubWebhook) validSignature(header string, body []byte) bool {
+	signature, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
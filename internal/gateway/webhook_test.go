@@ -0,0 +1,137 @@
+package gateway
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type recordingRevoker struct {
+	requests []*auth_v1_pb.RevokeProviderIdentityRequest
+	md       metadata.MD
+	err      error
+}
+
+func (r *recordingRevoker) RevokeProviderIdentity(
+	ctx context.Context,
+	in *auth_v1_pb.RevokeProviderIdentityRequest,
+	_ ...grpc.CallOption,
+) (*auth_v1_pb.RevokeProviderIdentityResponse, error) {
+	r.requests = append(r.requests, in)
+	r.md, _ = metadata.FromOutgoingContext(ctx)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &auth_v1_pb.RevokeProviderIdentityResponse{RevokedSessions: 1}, nil
+}
+
+func signWebhook(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestGitHubWebhook(t *testing.T) {
+	revoker := &recordingRevoker{}
+	rdb, _ := testutil.NewRedis(t)
+	webhook := newGitHubWebhook(configs.WebhooksConfig{
+		GithubSecret:     "webhook-secret",
+		GithubRevocation: configs.RevocationUnlink,
+	}, "internal-token", revoker, rdb)
+	revoked := `{"action":"revoked","sender":{"id":42}}`
+
+	post := func(event, body, signature string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/github", strings.NewReader(body))
+		req.Header.Set("X-GitHub-Event", event)
+		req.Header.Set("X-Hub-Signature-256", signature)
+		rec := httptest.NewRecorder()
+		webhook.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	forged := signWebhook("other-secret", revoked)
+	if code := post("github_app_authorization", revoked, forged); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for an invalid signature, got %d", code)
+	}
+	ping := `{"zen":"Keep it simple."}`
+	code := post("ping", ping, signWebhook("webhook-secret", ping))
+	if code != http.StatusNoContent {
+		t.Errorf("expected 204 for a ping, got %d", code)
+	}
+	if len(revoker.requests) != 0 {
+		t.Fatalf("expected no revocation yet, got %v", revoker.requests)
+	}
+
+	signature := signWebhook("webhook-secret", revoked)
+	if code := post("github_app_authorization", revoked, signature); code != http.StatusNoContent {
+		t.Errorf("expected 204 for a revocation, got %d", code)
+	}
+	if len(revoker.requests) != 1 {
+		t.Fatalf("expected a revocation, got %v", revoker.requests)
+	}
+	req := revoker.requests[0]
+	if req.Provider != "github" || req.ProviderUserId != "42" || !req.Unlink {
+		t.Errorf("unexpected revocation request %v", req)
+	}
+	if got := revoker.md.Get("authorization"); len(got) != 1 || got[0] != "Bearer internal-token" {
+		t.Errorf("expected the internal token, got %v", got)
+	}
+
+	revoker.err = status.Error(codes.NotFound, "no user")
+	if code := post("github_app_authorization", revoked, signature); code != http.StatusNoContent {
+		t.Errorf("expected 204 for an unlinked account, got %d", code)
+	}
+	revoker.err = status.Error(codes.Unavailable, "down")
+	if code := post("github_app_authorization", revoked, signature); code != http.StatusBadGateway {
+		t.Errorf("expected 502 when the revocation fails, got %d", code)
+	}
+}
+
+func TestGitHubWebhookRedelivery(t *testing.T) {
+	revoker := &recordingRevoker{}
+	rdb, _ := testutil.NewRedis(t)
+	webhook := newGitHubWebhook(configs.WebhooksConfig{
+		GithubSecret: "webhook-secret",
+	}, "internal-token", revoker, rdb)
+	revoked := `{"action":"revoked","sender":{"id":42}}`
+	post := func(delivery string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/github", strings.NewReader(revoked))
+		req.Header.Set("X-GitHub-Event", "github_app_authorization")
+		req.Header.Set("X-Hub-Signature-256", signWebhook("webhook-secret", revoked))
+		req.Header.Set("X-GitHub-Delivery", delivery)
+		rec := httptest.NewRecorder()
+		webhook.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	revoker.err = status.Error(codes.Unavailable, "down")
+	if code := post("delivery-1"); code != http.StatusBadGateway {
+		t.Errorf("expected 502 when the revocation fails, got %d", code)
+	}
+	revoker.err = nil
+	if code := post("delivery-1"); code != http.StatusNoContent {
+		t.Errorf("expected a failed delivery to be processed again, got %d", code)
+	}
+	if code := post("delivery-1"); code != http.StatusNoContent {
+		t.Errorf("expected 204 for a redelivery, got %d", code)
+	}
+	if len(revoker.requests) != 2 {
+		t.Errorf("expected the redelivery not to revoke again, got %d calls", len(revoker.requests))
+	}
+	if code := post("delivery-2"); code != http.StatusNoContent || len(revoker.requests) != 3 {
+		t.Errorf("expected another delivery to be processed, got %d", code)
+	}
+}
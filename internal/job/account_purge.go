@@ -0,0 +1,102 @@
+package job
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/utils"
+)
+
+// accountPurgeBatchSize bounds how many accounts are purged per run.
+const accountPurgeBatchSize = 100
+
+// AccountPurgeJob permanently deletes accounts whose deletion grace period has
+// passed, along with the data kept for them: sessions, provider tokens and
+// notification preferences. Audit events that referenced them are
+// pseudonymized instead of being left pointing at a user that no longer exists.
+type AccountPurgeJob struct {
+	userRepo          repository.UserRepository
+	auditRepo         repository.AuditRepository
+	sessionRepo       repository.SessionRepository
+	providerTokenRepo repository.ProviderTokenRepository
+	prefsRepo         repository.NotificationPreferencesRepository
+	pseudonymKey      string
+}
+
+func NewAccountPurgeJob(
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditRepository,
+	sessionRepo repository.SessionRepository,
+	providerTokenRepo repository.ProviderTokenRepository,
+	prefsRepo repository.NotificationPreferencesRepository,
+	pseudonymKey string,
+) *AccountPurgeJob {
+	return &AccountPurgeJob{
+		userRepo:          userRepo,
+		auditRepo:         auditRepo,
+		sessionRepo:       sessionRepo,
+		providerTokenRepo: providerTokenRepo,
+		prefsRepo:         prefsRepo,
+		pseudonymKey:      pseudonymKey,
+	}
+}
+
+func (j *AccountPurgeJob) Name() string {
+	return "account_purge"
+}
+
+func (j *AccountPurgeJob) Run(ctx context.Context) error {
+	users, err := j.userRepo.ListDeletionDue(ctx, time.Now(), accountPurgeBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if err := j.purge(ctx, user); err != nil {
+			return err
+		}
+	}
+	if len(users) > 0 {
+		slog.InfoContext(ctx, "accounts purged", "count", len(users))
+	}
+	return nil
+}
+
+func (j *AccountPurgeJob) purge(ctx context.Context, user *model.UserModel) error {
+	// Sessions were revoked when deletion was requested; this catches any created since
+	if _, err := j.sessionRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+	// Provider tokens grant access to the user's accounts elsewhere
+	if err := j.providerTokenRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := j.prefsRepo.Delete(ctx, user.ID); err != nil {
+		return err
+	}
+	pseudonym := utils.Pseudonymize(j.pseudonymKey, user.ID)
+	anonymized, err := j.auditRepo.AnonymizeByUserID(ctx, user.ID, pseudonym)
+	if err != nil {
+		return err
+	}
+	if err := j.userRepo.Purge(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := j.auditRepo.Create(ctx, &model.AuditEventModel{
+		Type:      model.AuditEventAccountPurged,
+		Pseudonym: &pseudonym,
+	}); err != nil {
+		return err
+	}
+	slog.InfoContext(
+		ctx,
+		"account purged",
+		"user_id",
+		user.ID,
+		"anonymized_audit_events",
+		anonymized,
+	)
+	return nil
+}
@@ -0,0 +1,231 @@
+package job
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/objectstore"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var auditChainBreaks = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "auth_audit_chain_breaks_total",
+	Help: "Runs of the audit chain job that found the audit hash chain tampered with.",
+})
+
+const (
+	auditChainBatchSize = 500
+	// auditAnchorLatestPath is the latest anchor, which verification resumes
+	// from after a restart
+	auditAnchorLatestPath = "audit-anchors/latest.json"
+)
+
+// AuditChainJob verifies the audit hash chain as events are appended, so
+// tampering with audit history is detected: changing, removing or inserting
+// an event breaks the chain. Each run verifies the events appended since the
+// previous one; every AuditConfig.ChainFullVerifyInterval, and on the first
+// run, it verifies the whole chain again from the oldest retained event, so
+// already verified events being changed or deleted are detected too. With an
+// object store it also anchors the head of the chain there after each run, so
+// that rewriting the whole chain from an event on is detected too: the
+// rewritten chain no longer matches the anchors.
+type AuditChainJob struct {
+	auditRepo repository.AuditRepository
+	objects   objectstore.Store
+	audit     configs.AuditConfig
+	// last is the last verified event, nil until the chain has one
+	last         *model.AuditEventModel
+	anchored     int64
+	anchoredHash string
+	// verifiedAll is when the whole chain was last verified
+	verifiedAll time.Time
+}
+
+// NewAuditChainJob returns the job; objects is nil to verify without anchoring.
+func NewAuditChainJob(
+	auditRepo repository.AuditRepository,
+	objects objectstore.Store,
+	audit configs.AuditConfig,
+) *AuditChainJob {
+	return &AuditChainJob{auditRepo: auditRepo, objects: objects, audit: audit}
+}
+
+func (j *AuditChainJob) Name() string {
+	return "audit_chain"
+}
+
+func (j *AuditChainJob) Run(ctx context.Context) error {
+	if j.last == nil && j.objects != nil {
+		if err := j.resume(ctx); err != nil {
+			return err
+		}
+	}
+	if j.verifiedAll.IsZero() || time.Since(j.verifiedAll) >= j.audit.ChainFullVerifyInterval {
+		if err := j.VerifyAll(ctx); err != nil {
+			return err
+		}
+	} else {
+		last, err := j.verifyChain(ctx, j.last)
+		if err != nil {
+			return err
+		}
+		j.last = last
+	}
+	if j.objects == nil || j.last == nil || j.last.Sequence == j.anchored {
+		return nil
+	}
+	return j.anchor(ctx)
+}
+
+// VerifyAll verifies the whole chain from the oldest retained event. Beyond
+// the events themselves, it checks that the chain still reaches the last
+// event verified before, unless retention deleted it.
+func (j *AuditChainJob) VerifyAll(ctx context.Context) error {
+	last, err := j.verifyChain(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if j.last != nil {
+		switch {
+		case last == nil && !j.expired(j.last.CreatedAt):
+			return j.broken(ctx, fmt.Errorf("events up to %d are missing", j.last.Sequence))
+		case last != nil && last.Sequence < j.last.Sequence:
+			return j.broken(ctx, fmt.Errorf(
+				"events %d to %d are missing", last.Sequence+1, j.last.Sequence))
+		}
+	}
+	j.last = last
+	j.verifiedAll = time.Now()
+	return nil
+}
+
+// verifyChain verifies the events following prev, from the oldest retained
+// one if prev is nil, and returns the last event of the chain.
+func (j *AuditChainJob) verifyChain(
+	ctx context.Context,
+	prev *model.AuditEventModel,
+) (*model.AuditEventModel, error) {
+	var after int64
+	if prev != nil {
+		after = prev.Sequence
+	}
+	for {
+		events, err := j.auditRepo.ListChain(ctx, after, auditChainBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			if err := j.verify(ctx, event, prev); err != nil {
+				return nil, err
+			}
+			prev = event
+			after = event.Sequence
+		}
+		if len(events) < auditChainBatchSize {
+			return prev, nil
+		}
+	}
+}
+
+// resume starts verifying from the event of the latest anchor, checking that
+// it is still the anchored one. Without an anchor, or once retention deleted
+// its event, verification starts from the oldest retained event; the anchored
+// event missing otherwise breaks the chain.
+func (j *AuditChainJob) resume(ctx context.Context) error {
+	file, err := j.objects.Open(auditAnchorLatestPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open audit anchor: %w", err)
+	}
+	defer file.Close()
+	var anchor model.AuditAnchor
+	if err := json.NewDecoder(file).Decode(&anchor); err != nil {
+		return fmt.Errorf("failed to read audit anchor: %w", err)
+	}
+	j.anchored = anchor.Sequence
+	j.anchoredHash = anchor.Hash
+	events, err := j.auditRepo.ListChain(ctx, anchor.Sequence-1, 1)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 || events[0].Sequence != anchor.Sequence {
+		// Anchors saved before the event's creation time was recorded only
+		// have the later anchoring time
+		createdAt := anchor.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = anchor.AnchoredAt
+		}
+		if j.expired(createdAt) {
+			return nil
+		}
+		return j.broken(ctx, fmt.Errorf(
+			"event %d of the anchor taken at %s is missing",
+			anchor.Sequence, anchor.AnchoredAt))
+	}
+	anchored := events[0]
+	if err := j.verify(ctx, anchored, nil); err != nil {
+		return err
+	}
+	j.last = anchored
+	return nil
+}
+
+// expired tells whether retention deletes events created at createdAt.
+func (j *AuditChainJob) expired(createdAt time.Time) bool {
+	return createdAt.Before(time.Now().Add(-j.audit.Retention))
+}
+
+// verify verifies the event following prev, or the oldest retained event if
+// prev is nil, and that the anchored event still matches its anchor.
+func (j *AuditChainJob) verify(ctx context.Context, event, prev *model.AuditEventModel) error {
+	if err := event.VerifySeal(prev); err != nil {
+		return j.broken(ctx, err)
+	}
+	if event.Sequence == j.anchored && event.Hash != j.anchoredHash {
+		return j.broken(ctx, fmt.Errorf(
+			"event %d does not match its anchor", event.Sequence))
+	}
+	return nil
+}
+
+func (j *AuditChainJob) broken(ctx context.Context, err error) error {
+	auditChainBreaks.Inc()
+	slog.ErrorContext(ctx, "audit hash chain broken", "error", err)
+	return fmt.Errorf("audit hash chain broken: %w", err)
+}
+
+// anchor saves the last verified event as an anchor of its own and as the
+// latest one.
+func (j *AuditChainJob) anchor(ctx context.Context) error {
+	data, err := json.Marshal(model.AuditAnchor{
+		Sequence:   j.last.Sequence,
+		Hash:       j.last.Hash,
+		CreatedAt:  j.last.CreatedAt,
+		AnchoredAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("audit-anchors/%020d.json", j.last.Sequence)
+	for _, path := range []string{path, auditAnchorLatestPath} {
+		if _, err := j.objects.Save(path, bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("failed to save audit anchor: %w", err)
+		}
+	}
+	j.anchored = j.last.Sequence
+	j.anchoredHash = j.last.Hash
+	slog.InfoContext(ctx, "audit hash chain anchored", "sequence", j.last.Sequence)
+	return nil
+}
@@ -0,0 +1,149 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/objectstore"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+)
+
+var auditChainConfig = configs.AuditConfig{
+	Retention:               time.Hour,
+	ChainFullVerifyInterval: time.Hour,
+}
+
+// createAuditEvents appends n events created at createdAt to the chain.
+func createAuditEvents(t *testing.T, auditRepo *testutil.AuditRepository, n int, createdAt time.Time) {
+	t.Helper()
+	userID := "user-1"
+	for range n {
+		event := &model.AuditEventModel{
+			Type:      model.AuditEventLoginSucceeded,
+			UserID:    &userID,
+			IPAddress: "203.0.113.7",
+			CreatedAt: createdAt,
+		}
+		if err := auditRepo.Create(context.Background(), event); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAuditChainJob(t *testing.T) {
+	ctx := context.Background()
+	objects, err := objectstore.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditRepo := testutil.NewAuditRepository()
+	createAuditEvents(t, auditRepo, 3, time.Now())
+
+	if err := NewAuditChainJob(auditRepo, objects, auditChainConfig).Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	file, err := objects.Open(auditAnchorLatestPath)
+	if err != nil {
+		t.Fatalf("expected an anchor, got %v", err)
+	}
+	var anchor model.AuditAnchor
+	if err := json.NewDecoder(file).Decode(&anchor); err != nil {
+		t.Fatal(err)
+	}
+	_ = file.Close()
+	events := auditRepo.Events()
+	if anchor.Sequence != 3 || anchor.Hash != events[2].Hash {
+		t.Errorf("expected the anchor of the third event, got %+v", anchor)
+	}
+
+	// Scrubbing keeps the chain intact
+	if _, err := auditRepo.ScrubBefore(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewAuditChainJob(auditRepo, objects, auditChainConfig).Run(ctx); err != nil {
+		t.Fatalf("expected the scrubbed chain to verify, got %v", err)
+	}
+
+	// Editing an event breaks the chain
+	events[1].Type = model.AuditEventLoginFailed
+	if err := NewAuditChainJob(auditRepo, nil, auditChainConfig).Run(ctx); err == nil {
+		t.Error("expected an edited event to break the chain")
+	}
+
+	// So does rewriting the chain from an event on, once it was anchored
+	events[1].Seal(events[0].Sequence, events[0].Hash)
+	events[2].Seal(events[1].Sequence, events[1].Hash)
+	if err := NewAuditChainJob(auditRepo, nil, auditChainConfig).Run(ctx); err != nil {
+		t.Fatalf("expected the rewritten chain to verify on its own, got %v", err)
+	}
+	if err := NewAuditChainJob(auditRepo, objects, auditChainConfig).Run(ctx); err == nil {
+		t.Error("expected the rewritten chain not to match the anchor")
+	}
+}
+
+func TestAuditChainJobVerifyAll(t *testing.T) {
+	ctx := context.Background()
+	auditRepo := testutil.NewAuditRepository()
+	createAuditEvents(t, auditRepo, 3, time.Now())
+	job := NewAuditChainJob(auditRepo, nil, auditChainConfig)
+	if err := job.Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// Runs only verify the events appended since, until the whole chain is
+	// due again
+	events := auditRepo.Events()
+	events[0].Type = model.AuditEventLoginFailed
+	if err := job.Run(ctx); err != nil {
+		t.Fatalf("expected the run to skip verified events, got %v", err)
+	}
+	if err := job.VerifyAll(ctx); err == nil {
+		t.Error("expected an edited verified event to break the chain")
+	}
+	events[0].Type = model.AuditEventLoginSucceeded
+
+	// Deleting the last verified event breaks the chain too
+	auditRepo.Remove(3)
+	if err := job.VerifyAll(ctx); err == nil {
+		t.Error("expected a deleted verified event to break the chain")
+	}
+}
+
+func TestAuditChainJobMissingAnchoredEvent(t *testing.T) {
+	ctx := context.Background()
+	objects, err := objectstore.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditRepo := testutil.NewAuditRepository()
+	createAuditEvents(t, auditRepo, 3, time.Now())
+	if err := NewAuditChainJob(auditRepo, objects, auditChainConfig).Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	auditRepo.Remove(3)
+	if err := NewAuditChainJob(auditRepo, objects, auditChainConfig).Run(ctx); err == nil {
+		t.Error("expected a deleted anchored event to break the chain")
+	}
+
+	// Unless retention deleted it
+	objects, err = objectstore.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditRepo = testutil.NewAuditRepository()
+	createAuditEvents(t, auditRepo, 3, time.Now().Add(-2*time.Hour))
+	if err := NewAuditChainJob(auditRepo, objects, auditChainConfig).Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if _, err := auditRepo.DeleteBefore(ctx, time.Now().Add(-auditChainConfig.Retention)); err != nil {
+		t.Fatal(err)
+	}
+	createAuditEvents(t, auditRepo, 1, time.Now())
+	if err := NewAuditChainJob(auditRepo, objects, auditChainConfig).Run(ctx); err != nil {
+		t.Errorf("expected the chain to verify after retention, got %v", err)
+	}
+}
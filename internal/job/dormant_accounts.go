@@ -0,0 +1,168 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/mailer"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+)
+
+// dormantAccountsBatchSize is how many users are read per query.
+const dormantAccountsBatchSize = 100
+
+// DormantAccountJob deactivates accounts that have been inactive for longer
+// than account.dormant_days. Users are warned by email account.dormant_warning_days
+// beforehand, and deactivation only happens once a warning sent after their
+// last activity is that old, so nobody is deactivated unannounced. Deactivated
+// accounts are not deleted: their sessions are revoked and the next login
+// reactivates them.
+type DormantAccountJob struct {
+	userRepo    repository.UserRepository
+	sessionRepo repository.SessionRepository
+	auditRepo   repository.AuditRepository
+	mailer      mailer.Mailer
+	cfg         configs.AccountConfig
+	linkBaseURL string
+}
+
+func NewDormantAccountJob(
+	userRepo repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	auditRepo repository.AuditRepository,
+	mailer mailer.Mailer,
+	cfg configs.AccountConfig,
+	linkBaseURL string,
+) *DormantAccountJob {
+	return &DormantAccountJob{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		auditRepo:   auditRepo,
+		mailer:      mailer,
+		cfg:         cfg,
+		linkBaseURL: strings.TrimSuffix(linkBaseURL, "/"),
+	}
+}
+
+func (j *DormantAccountJob) Name() string {
+	return "dormant_accounts"
+}
+
+func (j *DormantAccountJob) Run(ctx context.Context) error {
+	now := time.Now()
+	warnSince := now.Add(-max(j.cfg.DormantAfter-j.cfg.DormantWarning, 0))
+	active := false
+	filter := repository.UserFilter{InactiveSince: &warnSince, Deactivated: &active}
+
+	var warned, deactivated int
+	afterID := ""
+	for {
+		users, err := j.userRepo.ListAfter(ctx, filter, afterID, dormantAccountsBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			switch {
+			case j.deactivationDue(user, now):
+				err := j.deactivate(ctx, user, now)
+				if errors.Is(err, repository.ErrVersionConflict) {
+					// Changed since it was listed, e.g. by a login; the next run
+					// looks at it again
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				deactivated++
+			case user.DormancyWarnedAt == nil || user.DormancyWarnedAt.Before(user.LastActivity()):
+				err := j.warn(ctx, user, now)
+				if errors.Is(err, repository.ErrVersionConflict) {
+					continue
+				}
+				if err != nil {
+					// A failed email must not hold back the other users
+					slog.ErrorContext(ctx, "failed to warn dormant account",
+						"error", err, "user_id", user.ID)
+					continue
+				}
+				warned++
+			}
+		}
+		if len(users) < dormantAccountsBatchSize {
+			break
+		}
+		afterID = users[len(users)-1].ID
+	}
+
+	if warned > 0 || deactivated > 0 {
+		slog.InfoContext(ctx, "dormant accounts processed",
+			"warned", warned, "deactivated", deactivated)
+	}
+	return nil
+}
+
+// deactivationDue reports whether the user has been inactive for long enough
+// and was warned after their last activity, long enough ago.
+func (j *DormantAccountJob) deactivationDue(user *model.UserModel, now time.Time) bool {
+	last := user.LastActivity()
+	return last.Before(now.Add(-j.cfg.DormantAfter)) &&
+		user.DormancyWarnedAt != nil &&
+		!user.DormancyWarnedAt.Before(last) &&
+		now.Sub(*user.DormancyWarnedAt) >= j.cfg.DormantWarning
+}
+
+func (j *DormantAccountJob) warn(ctx context.Context, user *model.UserModel, now time.Time) error {
+	deactivation := user.LastActivity().Add(j.cfg.DormantAfter)
+	if earliest := now.Add(j.cfg.DormantWarning); deactivation.Before(earliest) {
+		deactivation = earliest
+	}
+	if err := j.mailer.Send(
+		ctx,
+		user.Email,
+		"Your account will be deactivated",
+		fmt.Sprintf(
+			"Your account has not been used since %s and will be deactivated on %s.\n\n"+
+				"Log in to keep it active: %s/login\n\n"+
+				"A deactivated account is not deleted; logging in again reactivates it.\n",
+			user.LastActivity().Format(time.DateOnly),
+			deactivation.Format(time.DateOnly),
+			j.linkBaseURL,
+		),
+	); err != nil {
+		return err
+	}
+	// The user as listed, so changes made since are not overwritten; a
+	// conflict leaves the warning unrecorded, to be sent again if still due
+	user.DormancyWarnedAt = &now
+	return j.userRepo.UpdateIfVersion(ctx, user, user.Version)
+}
+
+func (j *DormantAccountJob) deactivate(
+	ctx context.Context,
+	user *model.UserModel,
+	now time.Time,
+) error {
+	// The user as listed, so changes made since, such as a login, are not
+	// overwritten and prevent the deactivation
+	user.DeactivatedAt = &now
+	if err := j.userRepo.UpdateIfVersion(ctx, user, user.Version); err != nil {
+		return err
+	}
+	if _, err := j.sessionRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := j.auditRepo.Create(ctx, &model.AuditEventModel{
+		Type:   model.AuditEventAccountDeactivated,
+		UserID: &user.ID,
+	}); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "dormant account deactivated", "user_id", user.ID)
+	return nil
+}
@@ -0,0 +1,170 @@
+package job
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+)
+
+// recordingMailer remembers the recipients of sent emails.
+type recordingMailer struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (m *recordingMailer) Send(_ context.Context, to, _, _ string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, to)
+	return nil
+}
+
+func TestDormantAccountJob(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	daysAgo := func(days int) *time.Time {
+		at := now.Add(-time.Duration(days) * 24 * time.Hour)
+		return &at
+	}
+	user := func(id string, lastSeen, warned *time.Time) *model.UserModel {
+		return &model.UserModel{
+			ID:               id,
+			Email:            id + "@example.com",
+			CreatedAt:        *daysAgo(400),
+			LastSeenAt:       lastSeen,
+			DormancyWarnedAt: warned,
+		}
+	}
+	userRepo := testutil.NewUserRepository(
+		user("active", daysAgo(10), nil),
+		// Inactive long enough to be warned, not yet to be deactivated
+		user("warn", daysAgo(80), nil),
+		// Past the threshold but never warned: warned first
+		user("unwarned", daysAgo(200), nil),
+		// Warned, but active again since
+		user("returned", daysAgo(85), daysAgo(100)),
+		// Warned recently, the warning period has not passed
+		user("pending", daysAgo(200), daysAgo(3)),
+		user("due", daysAgo(200), daysAgo(20)),
+	)
+	sessionRepo, _ := testutil.NewSessionRepository(t)
+	sessionID, err := sessionRepo.Create(ctx, "due", time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	auditRepo := testutil.NewAuditRepository()
+	mail := &recordingMailer{}
+	job := NewDormantAccountJob(userRepo, sessionRepo, auditRepo, mail, configs.AccountConfig{
+		DormantAfter:   90 * 24 * time.Hour,
+		DormantWarning: 14 * 24 * time.Hour,
+	}, "https://portal.example.com/")
+
+	if err := job.Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	want := map[string]bool{
+		"warn@example.com":     true,
+		"unwarned@example.com": true,
+		"returned@example.com": true,
+	}
+	if len(mail.sent) != len(want) {
+		t.Errorf("expected warnings to %v, sent %v", want, mail.sent)
+	}
+	for _, to := range mail.sent {
+		if !want[to] {
+			t.Errorf("unexpected warning to %s", to)
+		}
+	}
+	for id, deactivated := range map[string]bool{
+		"active":   false,
+		"warn":     false,
+		"unwarned": false,
+		"returned": false,
+		"pending":  false,
+		"due":      true,
+	} {
+		stored, _ := userRepo.GetByID(ctx, id)
+		if (stored.DeactivatedAt != nil) != deactivated {
+			t.Errorf("%s: expected deactivated=%v, got %v", id, deactivated, stored.DeactivatedAt)
+		}
+	}
+	if _, err := sessionRepo.GetUserID(ctx, sessionID); err == nil {
+		t.Error("expected the sessions of the deactivated account to be revoked")
+	}
+	if n := auditRepo.Count(model.AuditEventAccountDeactivated); n != 1 {
+		t.Errorf("expected 1 deactivation event, got %d", n)
+	}
+
+	// Warnings are not repeated
+	mail.sent = nil
+	if err := job.Run(ctx); err != nil {
+		t.Fatalf("second Run failed: %v", err)
+	}
+	if len(mail.sent) != 0 {
+		t.Errorf("expected no repeated warnings, sent %v", mail.sent)
+	}
+}
+
+// changingUserRepository changes a user right after listing it, as a login or
+// an admin racing the job would.
+type changingUserRepository struct {
+	*testutil.UserRepository
+	change func(ctx context.Context)
+}
+
+func (r *changingUserRepository) ListAfter(
+	ctx context.Context,
+	filter repository.UserFilter,
+	afterID string,
+	limit int,
+) ([]*model.UserModel, error) {
+	users, err := r.UserRepository.ListAfter(ctx, filter, afterID, limit)
+	if err == nil && r.change != nil {
+		r.change(ctx)
+		r.change = nil
+	}
+	return users, err
+}
+
+func TestDormantAccountJobConcurrentChange(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	lastSeen := now.Add(-200 * 24 * time.Hour)
+	warned := now.Add(-20 * 24 * time.Hour)
+	users := testutil.NewUserRepository(&model.UserModel{
+		ID:               "due",
+		Email:            "due@example.com",
+		Role:             model.UserRoleUser,
+		CreatedAt:        lastSeen,
+		LastSeenAt:       &lastSeen,
+		DormancyWarnedAt: &warned,
+	})
+	userRepo := &changingUserRepository{UserRepository: users, change: func(ctx context.Context) {
+		user, _ := users.GetByID(ctx, "due")
+		user.Role = model.UserRoleAdmin
+		if err := users.Update(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}}
+	sessionRepo, _ := testutil.NewSessionRepository(t)
+	job := NewDormantAccountJob(userRepo, sessionRepo, testutil.NewAuditRepository(),
+		&recordingMailer{}, configs.AccountConfig{
+			DormantAfter:   90 * 24 * time.Hour,
+			DormantWarning: 14 * 24 * time.Hour,
+		}, "https://portal.example.com/")
+
+	if err := job.Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	stored, _ := users.GetByID(ctx, "due")
+	if stored.Role != model.UserRoleAdmin || stored.DeactivatedAt != nil {
+		t.Errorf("expected the concurrent change to win over the deactivation, got %+v", stored)
+	}
+}
@@ -0,0 +1,62 @@
+package job
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	encryptionStaleValues = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "auth_encryption_stale_values",
+		Help: "Rows with values not encrypted as configured, as of the last reencryption.",
+	})
+	encryptionRewritten = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "auth_encryption_reencrypted_total",
+		Help: "Rows rewritten by the reencryption job.",
+	})
+)
+
+const reencryptionBatchSize = 500
+
+// ReencryptionJob rewrites the values of encrypted columns that aren't
+// encrypted as configured, e.g. with a key being rotated out, so the old key
+// can be dropped once auth_encryption_stale_values is 0.
+type ReencryptionJob struct {
+	repo repository.ReencryptionRepository
+}
+
+func NewReencryptionJob(repo repository.ReencryptionRepository) *ReencryptionJob {
+	return &ReencryptionJob{repo: repo}
+}
+
+func (j *ReencryptionJob) Name() string {
+	return "reencryption"
+}
+
+func (j *ReencryptionJob) Run(ctx context.Context) error {
+	total := 0
+	for ctx.Err() == nil {
+		n, err := j.repo.Reencrypt(ctx, reencryptionBatchSize)
+		total += n
+		encryptionRewritten.Add(float64(n))
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+	}
+	stale, err := j.repo.CountStale(ctx)
+	if err != nil {
+		return err
+	}
+	encryptionStaleValues.Set(float64(stale))
+	if total > 0 {
+		slog.InfoContext(ctx, "values reencrypted", "count", total, "stale", stale)
+	}
+	return nil
+}
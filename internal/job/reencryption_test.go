@@ -0,0 +1,46 @@
+package job
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeReencryptionRepository has stale rows left to rewrite in batches.
+type fakeReencryptionRepository struct {
+	stale   int
+	batches int
+}
+
+func (r *fakeReencryptionRepository) CountStale(context.Context) (int64, error) {
+	return int64(r.stale), nil
+}
+
+func (r *fakeReencryptionRepository) Reencrypt(_ context.Context, limit int) (int, error) {
+	n := min(r.stale, limit)
+	r.stale -= n
+	r.batches++
+	return n, nil
+}
+
+func TestReencryptionJob(t *testing.T) {
+	repo := &fakeReencryptionRepository{stale: 2*reencryptionBatchSize + 1}
+	encryptionStaleValues.Set(42)
+	before := testutil.ToFloat64(encryptionRewritten)
+
+	if err := NewReencryptionJob(repo).Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if repo.stale != 0 || repo.batches != 4 {
+		t.Errorf("expected every row rewritten in 3 batches and a last empty one, got %+v", repo)
+	}
+	if rewritten := testutil.ToFloat64(encryptionRewritten) - before; rewritten != float64(
+		2*reencryptionBatchSize+1,
+	) {
+		t.Errorf("expected the rewritten rows to be counted, got %v", rewritten)
+	}
+	if stale := testutil.ToFloat64(encryptionStaleValues); stale != 0 {
+		t.Errorf("expected no stale values left, got %v", stale)
+	}
+}
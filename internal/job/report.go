@@ -0,0 +1,100 @@
+package job
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/objectstore"
+	"github.com/poly-workshop/auth-portal/internal/report"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// maxReportAttempts is how often a report is claimed before it is failed
+// rather than generated: one that keeps taking its server down would otherwise
+// take down the next one after every claim lease.
+const maxReportAttempts = 3
+
+var reportsGenerated = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "auth_reports_generated_total",
+	Help: "Reports generated, by kind and result.",
+}, []string{"kind", "result"})
+
+// ReportJob generates the pending reports and stores them in the object store.
+// Reports are claimed one at a time, so several servers can run the job.
+type ReportJob struct {
+	reportRepo repository.ReportRepository
+	generator  *report.Generator
+	objects    objectstore.Store
+}
+
+func NewReportJob(
+	reportRepo repository.ReportRepository,
+	generator *report.Generator,
+	objects objectstore.Store,
+) *ReportJob {
+	return &ReportJob{reportRepo: reportRepo, generator: generator, objects: objects}
+}
+
+func (j *ReportJob) Name() string {
+	return "report"
+}
+
+func (j *ReportJob) Run(ctx context.Context) error {
+	for {
+		pending, err := j.reportRepo.ClaimPending(ctx)
+		if err != nil {
+			return err
+		}
+		if pending == nil {
+			return nil
+		}
+		if err := j.generate(ctx, pending); err != nil {
+			return err
+		}
+	}
+}
+
+// generate generates a claimed report. A failure to generate it fails the
+// report; only a failure to record the outcome fails the run.
+func (j *ReportJob) generate(ctx context.Context, claimed *model.ReportModel) error {
+	path := fmt.Sprintf("reports/%s.%s", claimed.ID, claimed.Format)
+	var size int64
+	var err error
+	if claimed.Attempts > maxReportAttempts {
+		err = fmt.Errorf("generation was interrupted %d times", claimed.Attempts-1)
+	} else {
+		size, err = j.write(ctx, claimed, path)
+	}
+	now := time.Now()
+	claimed.CompletedAt = &now
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to generate report", "error", err, "report_id", claimed.ID)
+		claimed.Status = model.ReportStatusFailed
+		claimed.Error = err.Error()
+		reportsGenerated.WithLabelValues(string(claimed.Kind), "failed").Inc()
+	} else {
+		claimed.Status = model.ReportStatusReady
+		claimed.ObjectPath = path
+		claimed.SizeBytes = size
+		reportsGenerated.WithLabelValues(string(claimed.Kind), "ready").Inc()
+	}
+	return j.reportRepo.Update(ctx, claimed)
+}
+
+func (j *ReportJob) write(
+	ctx context.Context,
+	claimed *model.ReportModel,
+	path string,
+) (int64, error) {
+	var buf bytes.Buffer
+	if err := j.generator.Generate(ctx, claimed, &buf); err != nil {
+		return 0, err
+	}
+	return j.objects.Save(path, &buf)
+}
@@ -0,0 +1,70 @@
+package job
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/objectstore"
+	"github.com/poly-workshop/auth-portal/internal/report"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+)
+
+func TestReportJob(t *testing.T) {
+	ctx := context.Background()
+	objects, err := objectstore.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	reportRepo := testutil.NewReportRepository()
+	users := testutil.NewUserRepository(&model.UserModel{ID: "user-1", Email: "a@example.com"})
+	generator := report.NewGenerator(users, testutil.NewAuditRepository())
+	roster := &model.ReportModel{
+		Kind:   model.ReportKindUserRoster,
+		Format: model.ReportFormatCSV,
+		Status: model.ReportStatusPending,
+	}
+	unknown := &model.ReportModel{
+		Kind:   "unknown",
+		Format: model.ReportFormatCSV,
+		Status: model.ReportStatusPending,
+	}
+	// Claimed before by servers that died generating it
+	interrupted := &model.ReportModel{
+		Kind:     model.ReportKindUserRoster,
+		Format:   model.ReportFormatCSV,
+		Status:   model.ReportStatusPending,
+		Attempts: maxReportAttempts,
+	}
+	for _, pending := range []*model.ReportModel{roster, unknown, interrupted} {
+		if err := reportRepo.Create(ctx, pending); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := NewReportJob(reportRepo, generator, objects).Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	ready, _ := reportRepo.GetByID(ctx, roster.ID)
+	if ready.Status != model.ReportStatusReady || ready.CompletedAt == nil || ready.SizeBytes == 0 {
+		t.Fatalf("expected the roster to be ready, got %+v", ready)
+	}
+	file, err := objects.Open(ready.ObjectPath)
+	if err != nil {
+		t.Fatalf("failed to open the report: %v", err)
+	}
+	defer file.Close()
+	if data, _ := io.ReadAll(file); !strings.Contains(string(data), "a@example.com") {
+		t.Errorf("expected the user in the report, got %q", data)
+	}
+	failed, _ := reportRepo.GetByID(ctx, unknown.ID)
+	if failed.Status != model.ReportStatusFailed || failed.Error == "" {
+		t.Errorf("expected the unknown report to fail, got %+v", failed)
+	}
+	failed, _ = reportRepo.GetByID(ctx, interrupted.ID)
+	if failed.Status != model.ReportStatusFailed || failed.ObjectPath != "" {
+		t.Errorf("expected the interrupted report to fail, got %+v", failed)
+	}
+}
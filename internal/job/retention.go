@@ -0,0 +1,141 @@
+package job
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var retentionPurged = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "auth_retention_purged_total",
+	Help: "Records deleted or scrubbed by the retention job, by data category.",
+}, []string{"category"})
+
+// Data categories of the retention job
+const (
+	retentionAuditEvents    = "audit_events"
+	retentionAuditPII       = "audit_pii"
+	retentionLoginHistory   = "login_history"
+	retentionDeletedUsers   = "deleted_users"
+	retentionExpiredInvites = "expired_invites"
+)
+
+// loginHistoryTypes are the audit events making up the users' login history.
+var loginHistoryTypes = []model.AuditEventType{
+	model.AuditEventLoginSucceeded,
+	model.AuditEventLoginFailed,
+}
+
+// RetentionJob enforces the retention policy of each data category:
+//   - client information is scrubbed from audit events older than the PII
+//     retention window and audit events older than the retention window are
+//     deleted;
+//   - login events older than the login history window are detached from
+//     their user, so they no longer make up anyone's login history; they are
+//     kept, unlinked, as long as other audit events;
+//   - deleted users are purged like accounts whose deletion was requested;
+//   - expired signup invites are deleted.
+type RetentionJob struct {
+	auditRepo  repository.AuditRepository
+	userRepo   repository.UserRepository
+	inviteRepo repository.InviteRepository
+	purger     *AccountPurgeJob
+	audit      configs.AuditConfig
+	retention  configs.RetentionConfig
+}
+
+func NewRetentionJob(
+	auditRepo repository.AuditRepository,
+	userRepo repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	providerTokenRepo repository.ProviderTokenRepository,
+	prefsRepo repository.NotificationPreferencesRepository,
+	inviteRepo repository.InviteRepository,
+	audit configs.AuditConfig,
+	retention configs.RetentionConfig,
+) *RetentionJob {
+	return &RetentionJob{
+		auditRepo:  auditRepo,
+		userRepo:   userRepo,
+		inviteRepo: inviteRepo,
+		purger: NewAccountPurgeJob(
+			userRepo,
+			auditRepo,
+			sessionRepo,
+			providerTokenRepo,
+			prefsRepo,
+			audit.PseudonymizationKey,
+		),
+		audit:     audit,
+		retention: retention,
+	}
+}
+
+func (j *RetentionJob) Name() string {
+	return "retention"
+}
+
+func (j *RetentionJob) Run(ctx context.Context) error {
+	now := time.Now()
+	counts := make(map[string]int64)
+	var err error
+	counts[retentionAuditPII], err = j.auditRepo.ScrubBefore(ctx, now.Add(-j.audit.PIIRetention))
+	if err != nil {
+		return err
+	}
+	counts[retentionLoginHistory], err = j.auditRepo.DetachBefore(
+		ctx,
+		loginHistoryTypes,
+		now.Add(-j.retention.LoginHistory),
+	)
+	if err != nil {
+		return err
+	}
+	counts[retentionAuditEvents], err = j.auditRepo.DeleteBefore(ctx, now.Add(-j.audit.Retention))
+	if err != nil {
+		return err
+	}
+	users, err := j.userRepo.ListDeletedBefore(
+		ctx,
+		now.Add(-j.retention.DeletedUsers),
+		accountPurgeBatchSize,
+	)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if err := j.purger.purge(ctx, user); err != nil {
+			return err
+		}
+		counts[retentionDeletedUsers]++
+	}
+	invites, err := j.inviteRepo.DeleteExpiredBefore(ctx, now.Add(-j.retention.ExpiredInvites))
+	if err != nil {
+		return err
+	}
+	counts[retentionExpiredInvites] = int64(invites)
+
+	var logged []any
+	for _, category := range []string{
+		retentionAuditPII,
+		retentionLoginHistory,
+		retentionAuditEvents,
+		retentionDeletedUsers,
+		retentionExpiredInvites,
+	} {
+		if count := counts[category]; count > 0 {
+			retentionPurged.WithLabelValues(category).Add(float64(count))
+			logged = append(logged, category, count)
+		}
+	}
+	if len(logged) > 0 {
+		slog.InfoContext(ctx, "retention enforced", logged...)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestRetentionJob(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	daysAgo := func(days int) time.Time {
+		return now.Add(-time.Duration(days) * 24 * time.Hour)
+	}
+	userRepo := testutil.NewUserRepository(
+		&model.UserModel{ID: "active", Email: "active@example.com"},
+		&model.UserModel{
+			ID:        "recently-deleted",
+			Email:     "recent@example.com",
+			DeletedAt: gorm.DeletedAt{Time: daysAgo(5), Valid: true},
+		},
+		&model.UserModel{
+			ID:        "long-deleted",
+			Email:     "long@example.com",
+			DeletedAt: gorm.DeletedAt{Time: daysAgo(40), Valid: true},
+		},
+	)
+	sessionRepo, _ := testutil.NewSessionRepository(t)
+	auditRepo := testutil.NewAuditRepository()
+	userID := "active"
+	for _, event := range []*model.AuditEventModel{
+		{Type: model.AuditEventLoginSucceeded, UserID: &userID, IPAddress: "10.0.0.1",
+			CreatedAt: daysAgo(500)},
+		{Type: model.AuditEventLoginFailed, UserID: &userID, IPAddress: "10.0.0.1",
+			CreatedAt: daysAgo(200)},
+		{Type: model.AuditEventRoleChanged, UserID: &userID, IPAddress: "10.0.0.1",
+			CreatedAt: daysAgo(200)},
+		{Type: model.AuditEventLoginSucceeded, UserID: &userID, IPAddress: "10.0.0.1",
+			CreatedAt: daysAgo(1)},
+	} {
+		if err := auditRepo.Create(ctx, event); err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+	}
+	rdb, _ := testutil.NewRedis(t)
+	inviteRepo := repository.NewInviteRepository(rdb)
+	if err := inviteRepo.Create(ctx, &repository.Invite{Email: "expired@example.com"},
+		time.Millisecond); err != nil {
+		t.Fatalf("failed to create invite: %v", err)
+	}
+	if err := inviteRepo.Create(ctx, &repository.Invite{Email: "pending@example.com"},
+		time.Hour); err != nil {
+		t.Fatalf("failed to create invite: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	providerTokenRepo := testutil.NewProviderTokenRepository()
+	prefsRepo := testutil.NewNotificationPreferencesRepository()
+	for _, userID := range []string{"recently-deleted", "long-deleted"} {
+		token := &repository.ProviderToken{UserID: userID, Provider: "github", AccessToken: "t"}
+		if err := providerTokenRepo.Save(ctx, token); err != nil {
+			t.Fatal(err)
+		}
+		prefs := model.DefaultNotificationPreferences(userID)
+		prefs.ProductEmails = true
+		if err := prefsRepo.Save(ctx, prefs); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	job := NewRetentionJob(auditRepo, userRepo, sessionRepo, providerTokenRepo, prefsRepo,
+		inviteRepo, configs.AuditConfig{
+			Retention:           365 * 24 * time.Hour,
+			PIIRetention:        90 * 24 * time.Hour,
+			PseudonymizationKey: "key",
+		}, configs.RetentionConfig{
+			LoginHistory:   180 * 24 * time.Hour,
+			DeletedUsers:   30 * 24 * time.Hour,
+			ExpiredInvites: 0,
+		})
+	if err := job.Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	events := auditRepo.Events()
+	// The 500 days old login is deleted, the purge is recorded
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(events))
+	}
+	failed, changed, recent := events[0], events[1], events[2]
+	if failed.UserID != nil || failed.IPAddress != "" {
+		t.Errorf("expected the old login to be detached, got %+v", failed)
+	}
+	if changed.UserID == nil || changed.IPAddress != "" {
+		t.Errorf("expected the old role change to be scrubbed but kept, got %+v", changed)
+	}
+	if recent.UserID == nil || recent.IPAddress == "" {
+		t.Errorf("expected the recent login to be untouched, got %+v", recent)
+	}
+	if events[3].Type != model.AuditEventAccountPurged {
+		t.Errorf("expected the purge to be recorded, got %s", events[3].Type)
+	}
+	for i := 1; i < len(events); i++ {
+		if err := events[i].VerifySeal(events[i-1]); err != nil {
+			t.Errorf("expected the chain to stay intact: %v", err)
+		}
+	}
+
+	if users, _ := userRepo.ListDeletedBefore(ctx, now, 10); len(users) != 1 ||
+		users[0].ID != "recently-deleted" {
+		t.Errorf("expected only the recently deleted user to be left, got %v", users)
+	}
+	// The data kept for the purged user goes with it
+	if _, err := providerTokenRepo.Get(ctx, "long-deleted", "github"); err == nil {
+		t.Error("expected the provider token of the purged user to be deleted")
+	}
+	if prefs, _ := prefsRepo.Get(ctx, "long-deleted"); prefs.ProductEmails {
+		t.Error("expected the notification preferences of the purged user to be deleted")
+	}
+	if _, err := providerTokenRepo.Get(ctx, "recently-deleted", "github"); err != nil {
+		t.Errorf("expected the provider token of a user not purged to be kept: %v", err)
+	}
+	if _, err := inviteRepo.Get(ctx, "pending@example.com"); err != nil {
+		t.Errorf("expected the pending invite to be kept: %v", err)
+	}
+	if n, _ := inviteRepo.DeleteExpiredBefore(ctx, time.Now()); n != 0 {
+		t.Errorf("expected the expired invite to be deleted, %d left", n)
+	}
+}
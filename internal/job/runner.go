@@ -0,0 +1,73 @@
+package job
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job is a unit of background work that is executed periodically by a Runner.
+type Job interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+type scheduledJob struct {
+	job      Job
+	interval time.Duration
+}
+
+// Runner executes registered jobs on their own interval until its context is cancelled.
+type Runner struct {
+	jobs []scheduledJob
+	wg   sync.WaitGroup
+}
+
+func NewRunner() *Runner {
+	return &Runner{}
+}
+
+// Register adds a job that runs every interval, starting right after Start is called.
+func (r *Runner) Register(job Job, interval time.Duration) {
+	r.jobs = append(r.jobs, scheduledJob{job: job, interval: interval})
+}
+
+// Start launches all registered jobs in the background.
+func (r *Runner) Start(ctx context.Context) {
+	for _, sj := range r.jobs {
+		r.wg.Add(1)
+		go func(sj scheduledJob) {
+			defer r.wg.Done()
+			r.loop(ctx, sj)
+		}(sj)
+	}
+	slog.InfoContext(ctx, "job runner started", "jobs", len(r.jobs))
+}
+
+// Wait blocks until all jobs have stopped after the context was cancelled.
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+func (r *Runner) loop(ctx context.Context, sj scheduledJob) {
+	ticker := time.NewTicker(sj.interval)
+	defer ticker.Stop()
+	for {
+		r.runOnce(ctx, sj.job)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Runner) runOnce(ctx context.Context, job Job) {
+	start := time.Now()
+	if err := job.Run(ctx); err != nil {
+		slog.ErrorContext(ctx, "job failed", "job", job.Name(), "error", err)
+		return
+	}
+	slog.DebugContext(ctx, "job finished", "job", job.Name(), "duration", time.Since(start))
+}
@@ -0,0 +1,53 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingJob struct {
+	runs atomic.Int32
+	err  error
+}
+
+func (j *countingJob) Name() string {
+	return "counting"
+}
+
+func (j *countingJob) Run(ctx context.Context) error {
+	j.runs.Add(1)
+	return j.err
+}
+
+func TestRunner(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ok := &countingJob{}
+	failing := &countingJob{err: errors.New("boom")}
+	runner := NewRunner()
+	runner.Register(ok, 10*time.Millisecond)
+	runner.Register(failing, 10*time.Millisecond)
+	runner.Start(ctx)
+
+	time.Sleep(55 * time.Millisecond)
+	cancel()
+	runner.Wait()
+
+	// Jobs run immediately and then on every tick, failures don't stop the loop
+	if runs := ok.runs.Load(); runs < 2 {
+		t.Errorf("Expected job to run at least twice, got %d", runs)
+	}
+	if runs := failing.runs.Load(); runs < 2 {
+		t.Errorf("Expected failing job to keep running, got %d runs", runs)
+	}
+
+	// No further runs after the runner stopped
+	stopped := ok.runs.Load()
+	time.Sleep(30 * time.Millisecond)
+	if runs := ok.runs.Load(); runs != stopped {
+		t.Errorf("Expected no runs after stop, got %d more", runs-stopped)
+	}
+}
@@ -0,0 +1,80 @@
+package job
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	sessionsTotal = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "auth_sessions",
+		Help: "Login sessions stored in Redis, as of the last session cleanup.",
+	})
+	sessionUsers = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "auth_session_users",
+		Help: "Users with at least one login session, as of the last session cleanup.",
+	})
+	sessionsPerUserP95 = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "auth_sessions_per_user_p95",
+		Help: "95th percentile of the sessions per user with sessions.",
+	})
+	sessionEntriesRemoved = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "auth_session_orphans_removed_total",
+		Help: "Session index entries removed because their session was gone.",
+	})
+)
+
+// SessionCleanupJob removes the index entries that expired or deleted sessions
+// leave behind in Redis, migrates sessions stored in the legacy layout and
+// publishes session gauges.
+type SessionCleanupJob struct {
+	sessionRepo repository.SessionRepository
+}
+
+func NewSessionCleanupJob(sessionRepo repository.SessionRepository) *SessionCleanupJob {
+	return &SessionCleanupJob{sessionRepo: sessionRepo}
+}
+
+func (j *SessionCleanupJob) Name() string {
+	return "session_cleanup"
+}
+
+func (j *SessionCleanupJob) Run(ctx context.Context) error {
+	stats, err := j.sessionRepo.Reconcile(ctx)
+	if err != nil {
+		return err
+	}
+	sessionsTotal.Set(float64(stats.Sessions))
+	sessionUsers.Set(float64(len(stats.PerUser)))
+	sessionsPerUserP95.Set(float64(percentile(stats.PerUser, 95)))
+	sessionEntriesRemoved.Add(float64(stats.Removed))
+	if stats.Removed > 0 || stats.Indexed > 0 || stats.Migrated > 0 {
+		slog.InfoContext(
+			ctx,
+			"session indexes reconciled",
+			"removed",
+			stats.Removed,
+			"indexed",
+			stats.Indexed,
+			"migrated",
+			stats.Migrated,
+		)
+	}
+	return nil
+}
+
+// percentile returns the p-th percentile of the values (nearest rank), 0 if
+// there are none.
+func percentile(values []int, p int) int {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Sorted(slices.Values(values))
+	rank := (len(sorted)*p + 99) / 100
+	return sorted[max(rank-1, 0)]
+}
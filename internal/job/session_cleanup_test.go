@@ -0,0 +1,142 @@
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+)
+
+func TestSessionCleanupJob(t *testing.T) {
+	ctx := context.Background()
+	sessionRepo, mr := testutil.NewSessionRepository(t)
+	create := func(userID string) string {
+		sessionID, err := sessionRepo.Create(ctx, userID, time.Hour)
+		if err != nil {
+			t.Fatalf("failed to create session: %v", err)
+		}
+		return sessionID
+	}
+	for range 3 {
+		create("user-1")
+	}
+	expired := create("user-2")
+	deleted := create("user-2")
+	kept := create("user-2")
+	unindexed := create("user-3")
+
+	// An expired or deleted session leaves its index entry behind and a failed
+	// index update leaves the session unindexed
+	mr.Del(repository.SessionKey(expired))
+	mr.Del(repository.SessionKey(deleted))
+	mr.Del("us:user-3")
+
+	stats, err := sessionRepo.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if stats.Sessions != 5 || len(stats.PerUser) != 3 {
+		t.Errorf("expected 5 sessions of 3 users, got %+v", stats)
+	}
+	if stats.Removed != 2 || stats.Indexed != 1 {
+		t.Errorf("expected 2 removed and 1 indexed entries, got %+v", stats)
+	}
+	if members, _ := mr.Members("us:user-2"); len(members) != 1 ||
+		"s:"+members[0] != repository.SessionKey(kept) {
+		t.Errorf("expected only the existing session in the index, got %v", members)
+	}
+	if members, _ := mr.Members("us:user-3"); len(members) != 1 ||
+		"s:"+members[0] != repository.SessionKey(unindexed) {
+		t.Errorf("expected the unindexed session to be indexed again, got %v", members)
+	}
+
+	// Revoking a user's sessions reaches the reindexed session
+	if _, err := sessionRepo.DeleteByUserID(ctx, "user-3"); err != nil {
+		t.Fatalf("DeleteByUserID failed: %v", err)
+	}
+	if _, err := sessionRepo.GetUserID(ctx, unindexed); err == nil {
+		t.Error("expected the reindexed session to be revoked")
+	}
+
+	if err := NewSessionCleanupJob(sessionRepo).Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	stats, _ = sessionRepo.Reconcile(ctx)
+	if stats.Removed != 0 || stats.Indexed != 0 {
+		t.Errorf("expected nothing left to reconcile, got %+v", stats)
+	}
+}
+
+func TestSessionCleanupJobMigratesLegacySessions(t *testing.T) {
+	ctx := context.Background()
+	sessionRepo, mr := testutil.NewSessionRepository(t)
+	// Sessions as stored before keys were named after digests
+	legacy := func(sessionID, userID string, ttl time.Duration) {
+		mr.Set("session:"+sessionID, userID)
+		mr.SetTTL("session:"+sessionID, ttl)
+		mr.Set("session_ref:"+repository.SessionRef(sessionID), userID)
+		mr.SetTTL("session_ref:"+repository.SessionRef(sessionID), ttl)
+		if _, err := mr.SAdd("user_sessions:"+userID, sessionID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	legacy("read", "user-1", time.Hour)
+	legacy("scanned", "user-1", 2*time.Hour)
+	legacy("revoked", "user-2", time.Hour)
+
+	// Legacy sessions keep working, and are migrated when read
+	if userID, err := sessionRepo.GetUserID(ctx, "read"); err != nil || userID != "user-1" {
+		t.Fatalf("expected the legacy session of user-1, got %q, %v", userID, err)
+	}
+	if mr.Exists("session:read") || !mr.Exists(repository.SessionKey("read")) {
+		t.Error("expected the session read to be migrated")
+	}
+	active, err := sessionRepo.Active(ctx, repository.SessionRef("revoked"))
+	if err != nil || !active {
+		t.Errorf("expected the legacy session to be active, got %v, %v", active, err)
+	}
+	if userID, err := sessionRepo.DeleteByRef(ctx, repository.SessionRef("revoked")); err != nil ||
+		userID != "user-2" {
+		t.Fatalf("expected to revoke the legacy session of user-2, got %q, %v", userID, err)
+	}
+	if mr.Exists("session:revoked") || mr.Exists("session_ref:"+repository.SessionRef("revoked")) {
+		t.Error("expected the legacy session to be revoked")
+	}
+
+	if err := NewSessionCleanupJob(sessionRepo).Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if mr.Exists("session:scanned") || !mr.Exists(repository.SessionKey("scanned")) {
+		t.Fatal("expected the remaining legacy session to be migrated")
+	}
+	if ttl, _ := sessionRepo.TTL(ctx, "scanned"); ttl != 2*time.Hour {
+		t.Errorf("expected the session to keep its TTL, got %v", ttl)
+	}
+	if members, _ := mr.Members("us:user-1"); len(members) != 2 {
+		t.Errorf("expected both sessions of user-1 in the index, got %v", members)
+	}
+	if mr.Exists("user_sessions:user-1") {
+		t.Error("expected the legacy index to be emptied")
+	}
+	if deleted, err := sessionRepo.DeleteByUserID(ctx, "user-1"); err != nil || deleted != 2 {
+		t.Errorf("expected 2 sessions revoked, got %d, %v", deleted, err)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	if got := percentile(nil, 95); got != 0 {
+		t.Errorf("expected 0 without values, got %d", got)
+	}
+	values := make([]int, 0, 100)
+	for i := 100; i > 0; i-- {
+		values = append(values, i)
+	}
+	if got := percentile(values, 95); got != 95 {
+		t.Errorf("expected 95, got %d", got)
+	}
+	if got := percentile([]int{1, 1, 7}, 95); got != 7 {
+		t.Errorf("expected 7, got %d", got)
+	}
+}
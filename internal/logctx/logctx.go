@@ -0,0 +1,103 @@
+// Package logctx adds the fields of the current request to every log line, so
+// that handlers don't need to repeat them in each slog call.
+package logctx
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc"
+)
+
+// Field keys added by Handler.
+const (
+	KeyRequestID = "request_id"
+	KeyUserID    = "user_id"
+	KeyMethod    = "method"
+	KeyTenant    = "tenant"
+)
+
+type fieldsKey struct{}
+
+// fields are the request fields carried by a context.
+type fields struct {
+	requestID string
+	userID    string
+}
+
+func fromContext(ctx context.Context) fields {
+	f, _ := ctx.Value(fieldsKey{}).(fields)
+	return f
+}
+
+// WithRequestID returns a context whose log lines carry the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	f := fromContext(ctx)
+	f.requestID = requestID
+	return context.WithValue(ctx, fieldsKey{}, f)
+}
+
+// WithUserID returns a context whose log lines carry the user ID, for requests
+// that learn their user before or without authentication, e.g. logins.
+// Authenticated calls carry the ID of their token's user anyway.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	f := fromContext(ctx)
+	f.userID = userID
+	return context.WithValue(ctx, fieldsKey{}, f)
+}
+
+// RequestID returns the request ID of the context, if any.
+func RequestID(ctx context.Context) string {
+	return fromContext(ctx).requestID
+}
+
+// Handler adds the request ID, user ID, gRPC method and tenant of the context
+// to the records it passes on. The tenant is the organizations the auth
+// interceptor restricted the call of an org admin to. Fields a record already
+// has are not repeated, and fields that are unknown are left out.
+type Handler struct {
+	slog.Handler
+}
+
+// NewHandler wraps next, e.g. the handler of slog.Default.
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{Handler: next}
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx == nil {
+		return h.Handler.Handle(ctx, r)
+	}
+	f := fromContext(ctx)
+	if user, ok := ctx.Value(auth.ContextKeyUserInfo).(*auth.UserInfo); ok && user.UserID != "" {
+		f.userID = user.UserID
+	}
+	method, _ := grpc.Method(ctx)
+	orgs, _ := auth.OrgScopeFromContext(ctx)
+
+	present := make(map[string]bool, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		present[a.Key] = true
+		return true
+	})
+	add := func(key, value string) {
+		if value != "" && !present[key] {
+			r.AddAttrs(slog.String(key, value))
+		}
+	}
+	add(KeyRequestID, f.requestID)
+	add(KeyUserID, f.userID)
+	add(KeyMethod, method)
+	add(KeyTenant, strings.Join(orgs, ","))
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: h.Handler.WithGroup(name)}
+}
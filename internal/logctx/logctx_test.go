@@ -0,0 +1,70 @@
+package logctx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+)
+
+func logLine(t *testing.T, ctx context.Context, args ...any) map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil)))
+	logger.InfoContext(ctx, "test", args...)
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("failed to parse log line %q: %v", buf.String(), err)
+	}
+	return line
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("fields from context", func(t *testing.T) {
+		ctx := WithRequestID(context.Background(), "req-1")
+		ctx = WithUserID(ctx, "user-1")
+		ctx = context.WithValue(ctx, auth.ContextKeyOrgScope, []string{"acme", "globex"})
+		line := logLine(t, ctx)
+		for key, want := range map[string]string{
+			KeyRequestID: "req-1",
+			KeyUserID:    "user-1",
+			KeyTenant:    "acme,globex",
+		} {
+			if line[key] != want {
+				t.Errorf("expected %s %q, got %v", key, want, line[key])
+			}
+		}
+		if _, ok := line[KeyMethod]; ok {
+			t.Errorf("expected no method outside of gRPC calls, got %v", line[KeyMethod])
+		}
+	})
+
+	t.Run("authenticated user", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), auth.ContextKeyUserInfo,
+			&auth.UserInfo{UserID: "user-2"})
+		if line := logLine(t, WithUserID(ctx, "user-1")); line[KeyUserID] != "user-2" {
+			t.Errorf("expected the token's user, got %v", line[KeyUserID])
+		}
+	})
+
+	t.Run("fields are not repeated", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(NewHandler(slog.NewTextHandler(&buf, nil)))
+		logger.InfoContext(WithUserID(context.Background(), "user-1"), "test", "user_id", "other")
+		if got := bytes.Count(buf.Bytes(), []byte("user_id=")); got != 1 {
+			t.Errorf("expected one user_id field, got %d in %q", got, buf.String())
+		}
+	})
+
+	t.Run("no fields", func(t *testing.T) {
+		line := logLine(t, context.Background())
+		for _, key := range []string{KeyRequestID, KeyUserID, KeyMethod, KeyTenant} {
+			if _, ok := line[key]; ok {
+				t.Errorf("expected no %s, got %v", key, line[key])
+			}
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package logctx
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// samplerWindow is the period a Sampler's limit applies to.
+const samplerWindow = time.Minute
+
+var suppressedLines = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "auth_log_lines_suppressed_total",
+	Help: "Log lines left out by log sampling, by message.",
+}, []string{"message"})
+
+// Sampler limits high-volume warnings, e.g. of failed-login storms, to a number
+// of lines per minute and key, such as a client network. The lines that get
+// through report how many were suppressed before them; exact counts remain in
+// metrics and the audit log. A nil Sampler logs every line.
+type Sampler struct {
+	perMinute int
+
+	mu      sync.Mutex
+	windows map[string]*sampleWindow
+	sweepAt time.Time
+}
+
+type sampleWindow struct {
+	start      time.Time
+	logged     int
+	suppressed int
+}
+
+// NewSampler returns a sampler logging up to perMinute lines per key and minute,
+// or nil if perMinute is not positive.
+func NewSampler(perMinute int) *Sampler {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &Sampler{perMinute: perMinute, windows: make(map[string]*sampleWindow)}
+}
+
+// Allow reports whether a line of the key may be logged now and, if so, how
+// many lines of the key were suppressed since the last one.
+func (s *Sampler) Allow(key string) (bool, int) {
+	if s == nil {
+		return true, 0
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.After(s.sweepAt) {
+		// Forget keys whose window ended; their suppressed lines stay counted in
+		// the metric
+		for k, w := range s.windows {
+			if now.Sub(w.start) >= samplerWindow {
+				delete(s.windows, k)
+			}
+		}
+		s.sweepAt = now.Add(samplerWindow)
+	}
+
+	w := s.windows[key]
+	if w == nil {
+		w = &sampleWindow{start: now}
+		s.windows[key] = w
+	}
+	if now.Sub(w.start) >= samplerWindow {
+		w.start, w.logged = now, 0
+	}
+	if w.logged >= s.perMinute {
+		w.suppressed++
+		return false, 0
+	}
+	w.logged++
+	suppressed := w.suppressed
+	w.suppressed = 0
+	return true, suppressed
+}
+
+// Warn logs a warning unless the key exceeded its lines of the minute. Lines
+// following suppressed ones carry their number as "suppressed".
+func (s *Sampler) Warn(ctx context.Context, key, msg string, args ...any) {
+	ok, suppressed := s.Allow(key)
+	if !ok {
+		suppressedLines.WithLabelValues(msg).Inc()
+		return
+	}
+	if suppressed > 0 {
+		args = append(args, "suppressed", suppressed)
+	}
+	slog.WarnContext(ctx, msg, args...)
+}
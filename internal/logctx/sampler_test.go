@@ -0,0 +1,33 @@
+package logctx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSampler(t *testing.T) {
+	s := NewSampler(2)
+	for i, want := range []bool{true, true, false, false} {
+		if ok, _ := s.Allow("10.0.0.0"); ok != want {
+			t.Errorf("line %d: expected allowed %v, got %v", i, want, ok)
+		}
+	}
+	if ok, _ := s.Allow("10.0.1.0"); !ok {
+		t.Error("expected other keys to be limited separately")
+	}
+
+	// The next window reports the suppressed lines
+	s.windows["10.0.0.0"].start = time.Now().Add(-samplerWindow)
+	ok, suppressed := s.Allow("10.0.0.0")
+	if !ok || suppressed != 2 {
+		t.Errorf("expected the line to pass reporting 2 suppressed, got %v and %d", ok, suppressed)
+	}
+
+	var disabled *Sampler
+	if NewSampler(0) != nil {
+		t.Error("expected no sampler without a limit")
+	}
+	if ok, _ := disabled.Allow("10.0.0.0"); !ok {
+		t.Error("expected a nil sampler to allow every line")
+	}
+}
@@ -0,0 +1,68 @@
+package logctx
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/poly-workshop/auth-portal/configs"
+)
+
+// level is the minimum level of the logger installed by Setup.
+var level = new(slog.LevelVar)
+
+// Setup makes the default logger write lines of the configured format and
+// level to stdout, carrying the name of the command and the request fields of
+// Handler. The wrappers, e.g. error reporting, receive records with those
+// fields before they are written.
+func Setup(cmd string, cfg configs.LogConfig, wrappers ...func(slog.Handler) slog.Handler) error {
+	if err := SetLevel(cfg.Level); err != nil {
+		return err
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch cfg.Format {
+	case configs.LogFormatText:
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	case configs.LogFormatJSON:
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	default:
+		return fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+	for _, wrap := range wrappers {
+		handler = wrap(handler)
+	}
+	hostname, _ := os.Hostname()
+	slog.SetDefault(slog.New(NewHandler(handler)).With("cmd", cmd, "hostname", hostname))
+	return nil
+}
+
+// SetLevel changes the level of the logger installed by Setup at runtime, e.g.
+// to debug a production incident without a restart.
+func SetLevel(name string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(name)); err != nil {
+		return fmt.Errorf("unknown log level %q", name)
+	}
+	level.Set(l)
+	return nil
+}
+
+// ReloadLevel applies the log level of the configuration files in dir, so it
+// can be raised to debug an incident without a restart.
+func ReloadLevel(dir string) error {
+	cfg, err := configs.ReadLogConfig(dir)
+	if err != nil {
+		return err
+	}
+	if err := SetLevel(cfg.Level); err != nil {
+		return err
+	}
+	slog.Info("log level reloaded", "level", cfg.Level)
+	return nil
+}
+
+// Level returns the current level of the logger installed by Setup.
+func Level() slog.Level {
+	return level.Level()
+}
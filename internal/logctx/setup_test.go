@@ -0,0 +1,45 @@
+package logctx
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReloadLevel(t *testing.T) {
+	t.Cleanup(func() { level.Set(slog.LevelInfo) })
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("default.toml", "[log]\nlevel = \"debug\"\n")
+	t.Setenv("MODE", "production")
+
+	if err := ReloadLevel(dir); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	if Level() != slog.LevelDebug {
+		t.Errorf("expected debug, got %v", Level())
+	}
+
+	// The file of the mode overrides the defaults
+	write("production.toml", "[log]\nlevel = \"warn\"\n")
+	if err := ReloadLevel(dir); err != nil {
+		t.Fatalf("failed to reload: %v", err)
+	}
+	if Level() != slog.LevelWarn {
+		t.Errorf("expected warn, got %v", Level())
+	}
+
+	// Invalid levels leave the level unchanged
+	write("production.toml", "[log]\nlevel = \"verbose\"\n")
+	if err := ReloadLevel(dir); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+	if Level() != slog.LevelWarn {
+		t.Errorf("expected warn to be kept, got %v", Level())
+	}
+}
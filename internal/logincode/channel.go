@@ -0,0 +1,118 @@
+// Package logincode delivers the one-time codes users log in with when they
+// can't use OAuth, by email or by SMS through an HTTP gateway.
+package logincode
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/mailer"
+	"github.com/poly-workshop/auth-portal/internal/model"
+)
+
+// ErrNoAddress is returned by channels that can't reach the user, e.g. SMS for
+// users without a phone number.
+var ErrNoAddress = errors.New("user has no address for this channel")
+
+// Channel delivers login codes to users.
+type Channel interface {
+	Send(ctx context.Context, user *model.UserModel, code string, expiration time.Duration) error
+}
+
+// NewChannels creates the configured channels by name.
+func NewChannels(cfg configs.LoginCodeConfig, mail mailer.Mailer) (map[string]Channel, error) {
+	channels := make(map[string]Channel)
+	for _, name := range cfg.Channels {
+		switch name {
+		case configs.LoginCodeChannelEmail:
+			channels[name] = &emailChannel{mailer: mail}
+		case configs.LoginCodeChannelSMS:
+			if cfg.SMSURL == "" {
+				return nil, fmt.Errorf("sms login codes require login_code.sms_url")
+			}
+			channels[name] = &smsChannel{
+				url:           cfg.SMSURL,
+				authorization: cfg.SMSAuthorization,
+				client:        &http.Client{Timeout: 10 * time.Second},
+			}
+		default:
+			return nil, fmt.Errorf("login code channel %s not supported", name)
+		}
+	}
+	return channels, nil
+}
+
+func message(code string, expiration time.Duration) string {
+	return fmt.Sprintf(
+		"Your login code is %s. It is valid for %d minutes; never share it with anyone.",
+		code,
+		int(expiration.Minutes()),
+	)
+}
+
+type emailChannel struct {
+	mailer mailer.Mailer
+}
+
+func (c *emailChannel) Send(
+	ctx context.Context,
+	user *model.UserModel,
+	code string,
+	expiration time.Duration,
+) error {
+	return c.mailer.Send(
+		ctx,
+		user.Email,
+		"Your login code",
+		message(code, expiration)+"\n\nIf you did not request it, you can ignore this email.\n",
+	)
+}
+
+// smsChannel posts messages to an SMS gateway, e.g. a small adapter in front of
+// the API of the SMS provider.
+type smsChannel struct {
+	url           string
+	authorization string
+	client        *http.Client
+}
+
+func (c *smsChannel) Send(
+	ctx context.Context,
+	user *model.UserModel,
+	code string,
+	expiration time.Duration,
+) error {
+	if user.PhoneNumber == nil {
+		return ErrNoAddress
+	}
+	payload, err := json.Marshal(map[string]string{
+		"to":      *user.PhoneNumber,
+		"message": message(code, expiration),
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.authorization != "" {
+		req.Header.Set("Authorization", c.authorization)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sms gateway answered %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package logincode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/model"
+)
+
+func TestNewChannels(t *testing.T) {
+	channels, err := NewChannels(configs.LoginCodeConfig{Channels: []string{"email"}}, nil)
+	if err != nil || len(channels) != 1 {
+		t.Errorf("expected the email channel, got %v, %v", channels, err)
+	}
+	for _, cfg := range []configs.LoginCodeConfig{
+		{Channels: []string{"sms"}},
+		{Channels: []string{"pigeon"}},
+	} {
+		if _, err := NewChannels(cfg, nil); err == nil {
+			t.Errorf("expected %v to be rejected", cfg.Channels)
+		}
+	}
+}
+
+func TestSMSChannel(t *testing.T) {
+	var got map[string]string
+	var authorization string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	channels, err := NewChannels(configs.LoginCodeConfig{
+		Channels:         []string{"sms"},
+		SMSURL:           srv.URL,
+		SMSAuthorization: "Bearer gateway",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sms := channels["sms"]
+	ctx := context.Background()
+
+	user := &model.UserModel{Email: "user@example.com"}
+	if err := sms.Send(ctx, user, "123456", time.Minute); !errors.Is(err, ErrNoAddress) {
+		t.Errorf("expected users without phone number to be unreachable, got %v", err)
+	}
+	phone := "+15551234567"
+	user.PhoneNumber = &phone
+	if err := sms.Send(ctx, user, "123456", 10*time.Minute); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if got["to"] != phone || !strings.Contains(got["message"], "123456") {
+		t.Errorf("unexpected message %v", got)
+	}
+	if authorization != "Bearer gateway" {
+		t.Errorf("expected the configured authorization, got %q", authorization)
+	}
+}
@@ -0,0 +1,77 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/poly-workshop/auth-portal/configs"
+)
+
+const (
+	DriverLog  = "log"
+	DriverSMTP = "smtp"
+)
+
+// Mailer delivers plain text emails.
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// NewMailer creates the mailer selected by the configured driver.
+func NewMailer(cfg configs.MailerConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case "", DriverLog:
+		return &logMailer{}, nil
+	case DriverSMTP:
+		if cfg.SMTPHost == "" || cfg.From == "" {
+			return nil, fmt.Errorf("smtp mailer requires host and from address")
+		}
+		return &smtpMailer{cfg: cfg}, nil
+	default:
+		return nil, fmt.Errorf("mailer driver %s not supported", cfg.Driver)
+	}
+}
+
+// logMailer writes emails to the log instead of sending them, for development.
+type logMailer struct{}
+
+func (m *logMailer) Send(ctx context.Context, to, subject, body string) error {
+	slog.InfoContext(ctx, "email not sent, log mailer in use",
+		"to", to,
+		"subject", subject,
+		"body", body)
+	return nil
+}
+
+type smtpMailer struct {
+	cfg configs.MailerConfig
+}
+
+func (m *smtpMailer) Send(ctx context.Context, to, subject, body string) error {
+	addr := net.JoinHostPort(m.cfg.SMTPHost, strconv.Itoa(int(m.cfg.SMTPPort)))
+
+	var auth smtp.Auth
+	if m.cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", m.cfg.SMTPUsername, m.cfg.SMTPPassword, m.cfg.SMTPHost)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", m.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	msg.WriteString(body)
+
+	if err := smtp.SendMail(addr, auth, m.cfg.From, []string{to}, []byte(msg.String())); err != nil {
+		slog.ErrorContext(ctx, "failed to send email", "error", err, "subject", subject)
+		return err
+	}
+	slog.DebugContext(ctx, "email sent", "subject", subject)
+	return nil
+}
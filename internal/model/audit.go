@@ -0,0 +1,88 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditEventType string
+
+const (
+	AuditEventAccountDeletionRequested AuditEventType = "account.deletion_requested"
+	AuditEventAccountDeletionCancelled AuditEventType = "account.deletion_cancelled"
+	AuditEventAccountPurged            AuditEventType = "account.purged"
+	AuditEventAccountDeactivated       AuditEventType = "account.deactivated"
+	AuditEventAccountReactivated       AuditEventType = "account.reactivated"
+	AuditEventLoginSucceeded           AuditEventType = "login.succeeded"
+	AuditEventLoginFailed              AuditEventType = "login.failed"
+	AuditEventEmailChangeRequested     AuditEventType = "email.change_requested"
+	AuditEventEmailChanged             AuditEventType = "email.changed"
+	AuditEventEmailChangeRolledBack    AuditEventType = "email.change_rolled_back"
+	AuditEventPasswordChanged          AuditEventType = "password.changed"
+	AuditEventPasswordSetByAdmin       AuditEventType = "password.set_by_admin"
+	AuditEventOAuthStateMismatch       AuditEventType = "oauth.state_mismatch"
+	AuditEventSignupPendingApproval    AuditEventType = "signup.pending_approval"
+	AuditEventSignupRejected           AuditEventType = "signup.rejected"
+	AuditEventRoleChanged              AuditEventType = "role.changed"
+	AuditEventSessionsRevokedByAdmin   AuditEventType = "session.revoked_by_admin"
+	AuditEventTenantSettingsChanged    AuditEventType = "tenant_settings.changed"
+	AuditEventFeatureFlagChanged       AuditEventType = "feature_flag.changed"
+	AuditEventUserInvited              AuditEventType = "user.invited"
+	AuditEventOAuthStatesPurged        AuditEventType = "oauth.states_purged"
+	AuditEventProviderTokenIssued      AuditEventType = "provider_token.issued"
+	AuditEventIdentityRevoked          AuditEventType = "identity.revoked_by_provider"
+	AuditEventMagicLinkSent            AuditEventType = "magic_link.sent"
+	AuditEventLoginCodeSent            AuditEventType = "login_code.sent"
+	AuditEventNotificationsUpdated     AuditEventType = "notifications.updated"
+	AuditEventSecurityNoticeSent       AuditEventType = "security_notice.sent"
+	AuditEventEntitlementsChanged      AuditEventType = "entitlements.changed"
+	// AuditEventEnumerationAlert is recorded when an admin read unusually many
+	// users in a day
+	AuditEventEnumerationAlert AuditEventType = "enumeration.alert"
+	// AuditEventRPCCalled is recorded for RPCs with the audit.v1.audit option
+	AuditEventRPCCalled AuditEventType = "rpc.called"
+)
+
+// AuditEventModel is an append-only record of a security relevant action;
+// login events double as the users' login history.
+// UserID, IPAddress, UserAgent and Metadata are personal data: when the owning
+// account is purged the user reference is replaced by a pseudonym and the rest
+// is scrubbed, and the retention job scrubs them for all events after a while.
+type AuditEventModel struct {
+	ID        string         `gorm:"type:varchar(36);primaryKey"       json:"id"`
+	CreatedAt time.Time      `gorm:"index"                             json:"created_at"`
+	Type      AuditEventType `gorm:"type:varchar(64);index;not null"   json:"type"`
+	UserID    *string        `gorm:"type:varchar(36);index"            json:"user_id"`
+	Pseudonym *string        `gorm:"type:varchar(64);index"            json:"pseudonym"`
+	IPAddress string         `gorm:"type:varchar(64)"                  json:"ip_address"`
+	UserAgent string         `gorm:"type:varchar(512)"                 json:"user_agent"`
+	Metadata  string         `gorm:"type:text"                         json:"metadata"`
+	// Sequence is the position of the event in the hash chain, from 1; events
+	// recorded before the chain was introduced have none
+	Sequence int64 `gorm:"index" json:"sequence,omitempty"`
+	// DataHash is the hash of the personal data of the event when it was
+	// recorded, which the chain covers instead of the data so it survives
+	// scrubbing
+	DataHash string `gorm:"type:varchar(64)" json:"-"`
+	// PrevHash is the Hash of the previous event in the chain and Hash that of
+	// this event, covering PrevHash (see Seal)
+	PrevHash string `gorm:"type:varchar(64)" json:"-"`
+	Hash     string `gorm:"type:varchar(64)" json:"-"`
+	// Scrubbed is set once personal data was scrubbed, so it no longer matches
+	// DataHash
+	Scrubbed bool `gorm:"not null;default:false" json:"-"`
+}
+
+func (AuditEventModel) TableName() string {
+	return "audit_events"
+}
+
+// BeforeCreate generates a UUID for the event before creating
+func (e *AuditEventModel) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditChainHeadID is the ID of the only row of AuditChainHeadModel.
+const AuditChainHeadID = 1
+
+// AuditChainHeadModel is the last event of the audit hash chain; appending to
+// the chain locks it.
+type AuditChainHeadModel struct {
+	ID       int    `gorm:"primaryKey;autoIncrement:false"`
+	Sequence int64  `gorm:"not null;default:0"`
+	Hash     string `gorm:"type:varchar(64)"`
+}
+
+func (AuditChainHeadModel) TableName() string {
+	return "audit_chain_head"
+}
+
+// AuditAnchor records the head of the audit hash chain outside the database,
+// so a chain rewritten from an earlier event on can be told from the original.
+type AuditAnchor struct {
+	Sequence int64  `json:"sequence"`
+	Hash     string `json:"hash"`
+	// CreatedAt is the creation time of the anchored event, which tells
+	// whether retention deleted it; zero in anchors saved before it was
+	// recorded
+	CreatedAt  time.Time `json:"created_at"`
+	AnchoredAt time.Time `json:"anchored_at"`
+}
+
+// Seal appends the event to the chain whose last event has sequence and hash
+// ("" for the first event): it fills in the ID and creation time if they are
+// unset, then the chain fields. The creation time is truncated to the
+// precision databases keep.
+func (e *AuditEventModel) Seal(sequence int64, hash string) {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	e.CreatedAt = e.CreatedAt.Truncate(time.Microsecond)
+	e.Sequence = sequence + 1
+	e.DataHash = e.personalDataHash()
+	e.PrevHash = hash
+	e.Hash = e.chainHash()
+}
+
+// VerifySeal checks that the event is sealed as the successor of prev, the
+// previous event of the chain or nil if it is not known, and that its personal
+// data wasn't changed unless it was scrubbed.
+func (e *AuditEventModel) VerifySeal(prev *AuditEventModel) error {
+	if prev != nil && (e.Sequence != prev.Sequence+1 || e.PrevHash != prev.Hash) {
+		return fmt.Errorf("event %d does not follow event %d", e.Sequence, prev.Sequence)
+	}
+	if e.Hash != e.chainHash() {
+		return fmt.Errorf("event %d does not match its hash", e.Sequence)
+	}
+	if !e.Scrubbed && e.DataHash != e.personalDataHash() {
+		return fmt.Errorf("personal data of event %d does not match its hash", e.Sequence)
+	}
+	return nil
+}
+
+func (e *AuditEventModel) chainHash() string {
+	return hashFields(
+		"v1",
+		e.PrevHash,
+		strconv.FormatInt(e.Sequence, 10),
+		e.ID,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		string(e.Type),
+		e.DataHash,
+	)
+}
+
+func (e *AuditEventModel) personalDataHash() string {
+	userID := ""
+	if e.UserID != nil {
+		userID = *e.UserID
+	}
+	return hashFields(userID, e.IPAddress, e.UserAgent, e.Metadata)
+}
+
+// hashFields hashes the fields, each prefixed with its length so that moving
+// data from one field to the next changes the hash.
+func hashFields(fields ...string) string {
+	var b strings.Builder
+	for _, field := range fields {
+		b.WriteString(strconv.Itoa(len(field)))
+		b.WriteByte(':')
+		b.WriteString(field)
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"regexp"
+	"slices"
+)
+
+// MaxEntitlements bounds the entitlements of a user, as they are carried in
+// every token.
+const MaxEntitlements = 64
+
+var entitlementPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._:-]{0,63}$`)
+
+// ValidEntitlement reports whether name can be used as an entitlement, e.g.
+// "plan:pro" or "reports.export".
+func ValidEntitlement(name string) bool {
+	return entitlementPattern.MatchString(name)
+}
+
+// HasEntitlement reports whether the user is entitled to name.
+func (u *UserModel) HasEntitlement(name string) bool {
+	_, found := slices.BinarySearch(u.Entitlements, name)
+	return found
+}
+
+// UpdateEntitlements adds and then removes entitlements, keeping them sorted
+// and free of duplicates, and reports whether they changed.
+func (u *UserModel) UpdateEntitlements(add, remove []string) bool {
+	updated := slices.Concat(u.Entitlements, add)
+	slices.Sort(updated)
+	updated = slices.Compact(updated)
+	updated = slices.DeleteFunc(updated, func(name string) bool {
+		return slices.Contains(remove, name)
+	})
+	if slices.Equal(updated, u.Entitlements) {
+		return false
+	}
+	if len(updated) == 0 {
+		updated = nil
+	}
+	u.Entitlements = updated
+	return true
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+)
+
+// NotificationCategory groups the emails users may opt out of.
+type NotificationCategory string
+
+const (
+	NotificationCategorySecurity   NotificationCategory = "security"
+	NotificationCategoryProduct    NotificationCategory = "product"
+	NotificationCategoryLoginAlert NotificationCategory = "login_alert"
+)
+
+// NotificationPreferencesModel is what a user wants to be emailed about. Users
+// without a row have the DefaultNotificationPreferences.
+type NotificationPreferencesModel struct {
+	UserID         string    `gorm:"type:varchar(36);primaryKey" json:"user_id"`
+	UpdatedAt      time.Time `                                   json:"updated_at"`
+	SecurityEmails bool      `gorm:"not null"                    json:"security_emails"`
+	ProductEmails  bool      `gorm:"not null"                    json:"product_emails"`
+	LoginAlerts    bool      `gorm:"not null"                    json:"login_alerts"`
+}
+
+func (NotificationPreferencesModel) TableName() string {
+	return "notification_preferences"
+}
+
+// DefaultNotificationPreferences opts users in to everything but product emails.
+func DefaultNotificationPreferences(userID string) *NotificationPreferencesModel {
+	return &NotificationPreferencesModel{
+		UserID:         userID,
+		SecurityEmails: true,
+		LoginAlerts:    true,
+	}
+}
+
+// Allows reports whether the user wants to be emailed about the category.
+func (p *NotificationPreferencesModel) Allows(category NotificationCategory) bool {
+	switch category {
+	case NotificationCategorySecurity:
+		return p.SecurityEmails
+	case NotificationCategoryProduct:
+		return p.ProductEmails
+	case NotificationCategoryLoginAlert:
+		return p.LoginAlerts
+	default:
+		return false
+	}
+}
+
+func (p *NotificationPreferencesModel) ToPb() *user_v1_pb.NotificationPreferences {
+	return &user_v1_pb.NotificationPreferences{
+		SecurityEmails: p.SecurityEmails,
+		ProductEmails:  p.ProductEmails,
+		LoginAlerts:    p.LoginAlerts,
+	}
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// ProviderTokenModel is the OAuth token a provider issued at a user's last
+// login with it, kept so other services can call the provider on the user's
+// behalf. AccessToken and RefreshToken are encrypted (see fieldcrypt, and
+// utils.EncryptSecret without encryption keys).
+type ProviderTokenModel struct {
+	UserID       string    `gorm:"type:varchar(36);primaryKey" json:"user_id"`
+	Provider     string    `gorm:"type:varchar(32);primaryKey" json:"provider"`
+	CreatedAt    time.Time `                                   json:"created_at"`
+	UpdatedAt    time.Time `                                   json:"updated_at"`
+	AccessToken  string    `gorm:"type:text;not null"          json:"-"`
+	RefreshToken string    `gorm:"type:text"                   json:"-"`
+	TokenType    string    `gorm:"type:varchar(32)"            json:"token_type"`
+	// Scopes are the granted scopes, separated by spaces
+	Scopes    string     `gorm:"type:varchar(1024)" json:"scopes"`
+	ExpiresAt *time.Time `                          json:"expires_at"`
+}
+
+func (ProviderTokenModel) TableName() string {
+	return "provider_tokens"
+}
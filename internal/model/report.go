@@ -0,0 +1,132 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+type ReportKind string
+
+const (
+	ReportKindUserRoster    ReportKind = "user_roster"
+	ReportKindLoginActivity ReportKind = "login_activity"
+	ReportKindAuditSummary  ReportKind = "audit_summary"
+)
+
+var reportKinds = map[ReportKind]user_v1_pb.ReportKind{
+	ReportKindUserRoster:    user_v1_pb.ReportKind_REPORT_KIND_USER_ROSTER,
+	ReportKindLoginActivity: user_v1_pb.ReportKind_REPORT_KIND_LOGIN_ACTIVITY,
+	ReportKindAuditSummary:  user_v1_pb.ReportKind_REPORT_KIND_AUDIT_SUMMARY,
+}
+
+// ReportKindFromPb returns the kind for pbKind, false if it is unspecified.
+func ReportKindFromPb(pbKind user_v1_pb.ReportKind) (ReportKind, bool) {
+	for kind, pb := range reportKinds {
+		if pb == pbKind {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+type ReportFormat string
+
+const (
+	ReportFormatCSV ReportFormat = "csv"
+	ReportFormatPDF ReportFormat = "pdf"
+)
+
+// ReportFormatFromPb returns the format for pbFormat, CSV if it is unspecified.
+func ReportFormatFromPb(pbFormat user_v1_pb.ReportFormat) ReportFormat {
+	if pbFormat == user_v1_pb.ReportFormat_REPORT_FORMAT_PDF {
+		return ReportFormatPDF
+	}
+	return ReportFormatCSV
+}
+
+// ContentType is the MIME type of reports in the format.
+func (f ReportFormat) ContentType() string {
+	if f == ReportFormatPDF {
+		return "application/pdf"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+type ReportStatus string
+
+const (
+	ReportStatusPending ReportStatus = "pending"
+	ReportStatusRunning ReportStatus = "running"
+	ReportStatusReady   ReportStatus = "ready"
+	ReportStatusFailed  ReportStatus = "failed"
+)
+
+var reportStatuses = map[ReportStatus]user_v1_pb.ReportStatus{
+	ReportStatusPending: user_v1_pb.ReportStatus_REPORT_STATUS_PENDING,
+	ReportStatusRunning: user_v1_pb.ReportStatus_REPORT_STATUS_RUNNING,
+	ReportStatusReady:   user_v1_pb.ReportStatus_REPORT_STATUS_READY,
+	ReportStatusFailed:  user_v1_pb.ReportStatus_REPORT_STATUS_FAILED,
+}
+
+// ReportModel is a report requested by an admin; the report job generates it
+// and stores the file in object storage under ObjectPath. ClaimedAt is when a
+// server last claimed the report for generation, Attempts how often one did.
+type ReportModel struct {
+	ID          string       `gorm:"type:varchar(36);primaryKey"      json:"id"`
+	CreatedAt   time.Time    `gorm:"index"                            json:"created_at"`
+	UpdatedAt   time.Time    `                                        json:"updated_at"`
+	Kind        ReportKind   `gorm:"type:varchar(32);not null"        json:"kind"`
+	Format      ReportFormat `gorm:"type:varchar(8);not null"         json:"format"`
+	Status      ReportStatus `gorm:"type:varchar(16);index;not null"  json:"status"`
+	PeriodDays  int          `gorm:"not null"                         json:"period_days"`
+	RequestedBy string       `gorm:"type:varchar(36)"                 json:"requested_by"`
+	ObjectPath  string       `gorm:"type:varchar(255)"                json:"-"`
+	SizeBytes   int64        `                                        json:"size_bytes"`
+	Error       string       `gorm:"type:text"                        json:"error"`
+	CompletedAt *time.Time   `                                        json:"completed_at"`
+	ClaimedAt   *time.Time   `                                        json:"claimed_at"`
+	Attempts    int          `gorm:"not null;default:0"               json:"attempts"`
+}
+
+func (ReportModel) TableName() string {
+	return "reports"
+}
+
+// BeforeCreate generates a UUID for the report before creating
+func (r *ReportModel) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Since is the start of the period the report covers.
+func (r *ReportModel) Since() time.Time {
+	return r.CreatedAt.AddDate(0, 0, -r.PeriodDays)
+}
+
+func (r *ReportModel) ToPb() *user_v1_pb.Report {
+	pb := &user_v1_pb.Report{
+		Id:          r.ID,
+		Kind:        reportKinds[r.Kind],
+		Status:      reportStatuses[r.Status],
+		PeriodDays:  int32(r.PeriodDays),
+		RequestedBy: r.RequestedBy,
+		CreatedAt:   timestamppb.New(r.CreatedAt),
+		SizeBytes:   r.SizeBytes,
+		Error:       r.Error,
+	}
+	if r.Format == ReportFormatPDF {
+		pb.Format = user_v1_pb.ReportFormat_REPORT_FORMAT_PDF
+	} else {
+		pb.Format = user_v1_pb.ReportFormat_REPORT_FORMAT_CSV
+	}
+	if r.CompletedAt != nil {
+		pb.CompletedAt = timestamppb.New(*r.CompletedAt)
+	}
+	return pb
+}
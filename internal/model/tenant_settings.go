@@ -0,0 +1,87 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TenantSettingsModel are the settings of a tenant, the organization users
+// belong to (see UserModel.Org). They can only restrict the configuration of
+// the deployment: zero values keep it. Tenants without a row, and users
+// without an organization, have none of their own.
+type TenantSettingsModel struct {
+	Org       string    `gorm:"type:varchar(100);primaryKey" json:"org"`
+	UpdatedAt time.Time `                                    json:"updated_at"`
+	// UpdatedBy is the ID of the admin who last updated the settings
+	UpdatedBy string `gorm:"type:varchar(36)"  json:"updated_by"`
+	LogoURL   string `gorm:"type:varchar(2048)" json:"logo_url"`
+	// AllowedProviders are the OAuth providers the users of the tenant may log
+	// in with, all of them if empty
+	AllowedProviders []string `gorm:"serializer:json" json:"allowed_providers"`
+	// AccessTokenLifetime and SessionLifetime cap those of the deployment, in
+	// seconds
+	AccessTokenLifetime int64 `gorm:"not null;default:0" json:"access_token_lifetime"`
+	SessionLifetime     int64 `gorm:"not null;default:0" json:"session_lifetime"`
+	// PasswordMinLength raises the minimum length of passwords
+	PasswordMinLength int `gorm:"not null;default:0" json:"password_min_length"`
+}
+
+func (TenantSettingsModel) TableName() string {
+	return "tenant_settings"
+}
+
+// AllowsProvider reports whether the users of the tenant may log in with the
+// OAuth provider. A nil tenant allows every provider.
+func (t *TenantSettingsModel) AllowsProvider(provider string) bool {
+	return t == nil || len(t.AllowedProviders) == 0 || slices.Contains(t.AllowedProviders, provider)
+}
+
+// CapAccessTokenLifetime returns lifetime, shortened to that of the tenant.
+func (t *TenantSettingsModel) CapAccessTokenLifetime(lifetime time.Duration) time.Duration {
+	if t == nil {
+		return lifetime
+	}
+	return capLifetime(lifetime, t.AccessTokenLifetime)
+}
+
+// CapSessionLifetime returns lifetime, shortened to that of the tenant.
+func (t *TenantSettingsModel) CapSessionLifetime(lifetime time.Duration) time.Duration {
+	if t == nil {
+		return lifetime
+	}
+	return capLifetime(lifetime, t.SessionLifetime)
+}
+
+func capLifetime(lifetime time.Duration, seconds int64) time.Duration {
+	if seconds <= 0 {
+		return lifetime
+	}
+	return min(lifetime, time.Duration(seconds)*time.Second)
+}
+
+// MinPasswordLength returns minLength, raised to that of the tenant.
+func (t *TenantSettingsModel) MinPasswordLength(minLength int) int {
+	if t == nil {
+		return minLength
+	}
+	return max(minLength, t.PasswordMinLength)
+}
+
+func (t *TenantSettingsModel) ToPb() *user_v1_pb.TenantSettings {
+	settings := &user_v1_pb.TenantSettings{
+		Org:                        t.Org,
+		LogoUrl:                    t.LogoURL,
+		AllowedProviders:           t.AllowedProviders,
+		AccessTokenLifetimeSeconds: t.AccessTokenLifetime,
+		SessionLifetimeSeconds:     t.SessionLifetime,
+		PasswordMinLength:          int32(t.PasswordMinLength),
+		UpdatedBy:                  t.UpdatedBy,
+	}
+	if !t.UpdatedAt.IsZero() {
+		settings.UpdatedAt = timestamppb.New(t.UpdatedAt)
+	}
+	return settings
+}
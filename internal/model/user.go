@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/fieldcrypt"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"gorm.io/gorm"
 )
@@ -44,11 +45,44 @@ type UserModel struct {
 	UpdatedAt      time.Time      `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 	Name           string         `gorm:"type:varchar(100);not null"             json:"name"`
-	Email          string         `gorm:"type:varchar(255);uniqueIndex;not null" json:"email"`
+	Email          string         `gorm:"type:varchar(512);uniqueIndex;not null;serializer:encrypted" json:"email"`
 	HashedPassword *string        `gorm:"column:hashed_password"                 json:"-"`
 	GithubID       *string        `gorm:"column:github_id;unique"                json:"github_id"`
 	LastLoginAt    *time.Time     `                                              json:"last_login_at"`
+	LastSeenAt     *time.Time     `gorm:"index"                                  json:"last_seen_at"`
 	Role           UserRole       `gorm:"type:varchar(20);default:'user'"        json:"role"`
+	// GithubLinkedAt is when GithubID was set; nil for accounts linked before
+	// it was recorded
+	GithubLinkedAt *time.Time `json:"github_linked_at"`
+	// DeletionScheduledAt is set when the user requested account deletion; the
+	// account is purged once this time has passed unless the request is cancelled.
+	DeletionScheduledAt *time.Time `gorm:"index" json:"deletion_scheduled_at"`
+	// MustChangePassword restricts the user's tokens to ChangePassword until cleared
+	MustChangePassword bool `gorm:"not null;default:false" json:"must_change_password"`
+	// PendingApproval blocks logins of signups outside the allowed email domains
+	// until an admin approves them
+	PendingApproval bool `gorm:"not null;default:false;index" json:"pending_approval"`
+	// Org is the organization the user belongs to; its org admins administer
+	// the users of the organization and its tenant settings apply to them
+	Org string `gorm:"type:varchar(100);index" json:"org,omitempty"`
+	// DeactivatedAt is set when the account was deactivated for inactivity; its
+	// sessions are revoked and the next successful login reactivates it
+	DeactivatedAt *time.Time `gorm:"index" json:"deactivated_at"`
+	// DormancyWarnedAt is when the user was last warned of the deactivation
+	DormancyWarnedAt *time.Time `json:"-"`
+	// Version is incremented by every update; clients send it back (If-Match)
+	// so concurrent updates are detected instead of overwriting each other
+	Version int64 `gorm:"not null;default:1" json:"version"`
+	// PhoneNumber is the E.164 number login codes may be sent to by SMS
+	PhoneNumber *string `gorm:"column:phone_number" json:"phone_number"`
+	// Metadata is set by provisioning hooks, e.g. the organization of the user
+	Metadata map[string]string `gorm:"serializer:json" json:"metadata,omitempty"`
+	// Entitlements are the features of the user's plan, kept sorted; they are
+	// carried in tokens so product services can gate features on them
+	Entitlements []string `gorm:"serializer:json" json:"entitlements,omitempty"`
+	// EmailIndex is the blind index of Email, to look it up while it is
+	// encrypted; nil without an index key configured
+	EmailIndex *string `gorm:"type:varchar(64);uniqueIndex" json:"-"`
 }
 
 func (UserModel) TableName() string {
@@ -63,8 +97,17 @@ func (u *UserModel) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// BeforeSave keeps the blind index of the email address up to date.
+func (u *UserModel) BeforeSave(tx *gorm.DB) error {
+	u.EmailIndex = nil
+	if index := fieldcrypt.Installed().BlindIndex(u.Email); index != "" && u.Email != "" {
+		u.EmailIndex = &index
+	}
+	return nil
+}
+
 func (u *UserModel) ToPb() *user_v1_pb.User {
-	return &user_v1_pb.User{
+	pb := &user_v1_pb.User{
 		Id:        u.ID,
 		Name:      u.Name,
 		Email:     u.Email,
@@ -72,7 +115,58 @@ func (u *UserModel) ToPb() *user_v1_pb.User {
 		Role:      u.Role.ToPb(),
 		CreatedAt: timestamppb.New(u.CreatedAt),
 		UpdatedAt: timestamppb.New(u.UpdatedAt),
+
+		MustChangePassword: u.MustChangePassword,
+		PendingApproval:    u.PendingApproval,
+		Org:                u.Org,
+		Version:            u.Version,
+		PhoneNumber:        u.PhoneNumber,
+		Metadata:           u.Metadata,
+		Entitlements:       u.Entitlements,
+	}
+	if u.DeletionScheduledAt != nil {
+		pb.DeletionScheduledAt = timestamppb.New(*u.DeletionScheduledAt)
+	}
+	if u.LastSeenAt != nil {
+		pb.LastSeenAt = timestamppb.New(*u.LastSeenAt)
 	}
+	if u.DeactivatedAt != nil {
+		pb.DeactivatedAt = timestamppb.New(*u.DeactivatedAt)
+	}
+	return pb
+}
+
+// ToPbView converts the user for view: the lite view has its id, name and
+// email only.
+func (u *UserModel) ToPbView(view user_v1_pb.UserView) *user_v1_pb.User {
+	if view == user_v1_pb.UserView_USER_VIEW_LITE {
+		return &user_v1_pb.User{Id: u.ID, Name: u.Name, Email: u.Email}
+	}
+	return u.ToPb()
+}
+
+// LastActivity is when the user was last seen, or created if never seen.
+func (u *UserModel) LastActivity() time.Time {
+	if u.LastSeenAt != nil {
+		return *u.LastSeenAt
+	}
+	return u.CreatedAt
+}
+
+// SetGithubID links the GitHub account, or unlinks it for nil, and records
+// when it was linked.
+func (u *UserModel) SetGithubID(githubID *string) {
+	if githubID == nil {
+		u.GithubID = nil
+		u.GithubLinkedAt = nil
+		return
+	}
+	if u.GithubID != nil && *u.GithubID == *githubID {
+		return
+	}
+	now := time.Now()
+	u.GithubID = githubID
+	u.GithubLinkedAt = &now
 }
 
 func (u *UserModel) UpdateFromPb(req *user_v1_pb.UpdateUserRequest) {
@@ -83,9 +177,30 @@ func (u *UserModel) UpdateFromPb(req *user_v1_pb.UpdateUserRequest) {
 		u.Email = *req.Email
 	}
 	if req.GithubId != nil {
-		u.GithubID = req.GithubId
+		u.SetGithubID(req.GithubId)
 	}
 	if req.Role != nil {
 		u.Role.FromPb(*req.Role)
 	}
+	if req.MustChangePassword != nil {
+		u.MustChangePassword = *req.MustChangePassword
+	}
+	if req.PendingApproval != nil {
+		u.PendingApproval = *req.PendingApproval
+	}
+	if req.Org != nil {
+		u.Org = *req.Org
+	}
+	if req.PhoneNumber != nil {
+		u.SetPhoneNumber(req.PhoneNumber)
+	}
+}
+
+// SetPhoneNumber sets the phone number, or removes it for nil or "".
+func (u *UserModel) SetPhoneNumber(number *string) {
+	if number == nil || *number == "" {
+		u.PhoneNumber = nil
+		return
+	}
+	u.PhoneNumber = number
 }
@@ -0,0 +1,39 @@
+package model
+
+import "testing"
+
+func TestSetGithubIDRecordsLinkDate(t *testing.T) {
+	user := &UserModel{}
+	githubID := "4242"
+	user.SetGithubID(&githubID)
+	if user.GithubLinkedAt == nil {
+		t.Fatal("expected linking to record the date")
+	}
+	linkedAt := *user.GithubLinkedAt
+	same := "4242"
+	user.SetGithubID(&same)
+	if !user.GithubLinkedAt.Equal(linkedAt) {
+		t.Error("expected setting the same ID to keep the link date")
+	}
+	user.SetGithubID(nil)
+	if user.GithubID != nil || user.GithubLinkedAt != nil {
+		t.Error("expected unlinking to clear the ID and the link date")
+	}
+}
+
+func TestUpdateEntitlements(t *testing.T) {
+	user := &UserModel{}
+	if !user.UpdateEntitlements([]string{"b", "a", "b"}, nil) {
+		t.Fatal("expected adding entitlements to change them")
+	}
+	if !user.HasEntitlement("a") || len(user.Entitlements) != 2 {
+		t.Errorf("expected sorted entitlements without duplicates, got %v", user.Entitlements)
+	}
+	if user.UpdateEntitlements([]string{"a"}, []string{"c"}) {
+		t.Error("expected no change for present additions and absent removals")
+	}
+	user.UpdateEntitlements(nil, []string{"a", "b"})
+	if user.Entitlements != nil {
+		t.Errorf("expected no entitlements left, got %v", user.Entitlements)
+	}
+}
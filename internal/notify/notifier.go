@@ -0,0 +1,71 @@
+// Package notify sends users the emails they may opt out of, respecting their
+// notification preferences.
+package notify
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/poly-workshop/auth-portal/internal/mailer"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var notifications = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "auth_notifications_total",
+	Help: "Notifications by category and whether they were sent, opted out of or failed.",
+}, []string{"category", "result"})
+
+// Notification is an email to a user about their account or the product.
+type Notification struct {
+	Category model.NotificationCategory
+	Subject  string
+	Body     string
+	// Mandatory security notices are sent even if the user turned security
+	// emails off; other categories can't be mandatory
+	Mandatory bool
+}
+
+// Notifier emails notifications to users who did not opt out of them.
+type Notifier struct {
+	mailer mailer.Mailer
+	prefs  repository.NotificationPreferencesRepository
+}
+
+func NewNotifier(mail mailer.Mailer, prefs repository.NotificationPreferencesRepository) *Notifier {
+	return &Notifier{mailer: mail, prefs: prefs}
+}
+
+// Notify sends the notification unless the user opted out of its category, and
+// reports whether it was sent.
+func (n *Notifier) Notify(
+	ctx context.Context,
+	user *model.UserModel,
+	notification Notification,
+) (bool, error) {
+	category := string(notification.Category)
+	mandatory := notification.Mandatory &&
+		notification.Category == model.NotificationCategorySecurity
+	if !mandatory {
+		prefs, err := n.prefs.Get(ctx, user.ID)
+		if err != nil {
+			notifications.WithLabelValues(category, "failed").Inc()
+			return false, err
+		}
+		if !prefs.Allows(notification.Category) {
+			notifications.WithLabelValues(category, "opted_out").Inc()
+			slog.DebugContext(ctx, "notification not sent, user opted out", "category", category)
+			return false, nil
+		}
+	}
+
+	err := n.mailer.Send(ctx, user.Email, notification.Subject, notification.Body)
+	if err != nil {
+		notifications.WithLabelValues(category, "failed").Inc()
+		return false, err
+	}
+	notifications.WithLabelValues(category, "sent").Inc()
+	return true, nil
+}
@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+)
+
+type countingMailer struct {
+	sent int
+}
+
+func (m *countingMailer) Send(context.Context, string, string, string) error {
+	m.sent++
+	return nil
+}
+
+func TestNotify(t *testing.T) {
+	ctx := context.Background()
+	mail := &countingMailer{}
+	prefs := testutil.NewNotificationPreferencesRepository()
+	notifier := NewNotifier(mail, prefs)
+	user := &model.UserModel{ID: "user-1", Email: "user@example.com"}
+
+	tests := []struct {
+		name         string
+		notification Notification
+		want         bool
+	}{
+		{"opted in", Notification{Category: model.NotificationCategoryLoginAlert}, true},
+		{"opted out", Notification{Category: model.NotificationCategorySecurity}, false},
+		{
+			"mandatory security notice",
+			Notification{Category: model.NotificationCategorySecurity, Mandatory: true},
+			true,
+		},
+		{
+			"mandatory product email",
+			Notification{Category: model.NotificationCategoryProduct, Mandatory: true},
+			false,
+		},
+		{"unknown category", Notification{Category: "other"}, false},
+	}
+	if err := prefs.Save(ctx, &model.NotificationPreferencesModel{
+		UserID:      user.ID,
+		LoginAlerts: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := mail.sent
+			sent, err := notifier.Notify(ctx, user, tt.notification)
+			if err != nil {
+				t.Fatalf("Notify failed: %v", err)
+			}
+			if sent != tt.want || (mail.sent > before) != tt.want {
+				t.Errorf("expected sent to be %v, got %v", tt.want, sent)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Package objectstore keeps generated files, such as reports, outside the
+// database.
+package objectstore
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/poly-workshop/auth-portal/configs"
+)
+
+// Store saves and serves objects by path. Open fails with an error wrapping
+// fs.ErrNotExist for missing objects.
+type Store interface {
+	Save(path string, r io.Reader) (int64, error)
+	Open(path string) (io.ReadCloser, error)
+	Delete(path string) error
+}
+
+// New returns the store selected by object_storage.provider.
+func New(cfg configs.ObjectStorageConfig) (Store, error) {
+	switch cfg.Provider {
+	case configs.ObjectStorageLocal:
+		return NewLocalStore(cfg.BasePath)
+	default:
+		return nil, fmt.Errorf("unsupported object storage provider %q", cfg.Provider)
+	}
+}
+
+// localStore keeps objects as files under a directory.
+type localStore struct {
+	basePath string
+}
+
+func NewLocalStore(basePath string) (Store, error) {
+	if err := os.MkdirAll(basePath, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create object storage directory: %w", err)
+	}
+	return &localStore{basePath: basePath}, nil
+}
+
+// file returns the file of the object at path, refusing paths that leave the
+// base path.
+func (s *localStore) file(path string) (string, error) {
+	clean := filepath.Clean("/" + path)
+	if clean == "/" || strings.Contains(path, "..") {
+		return "", fmt.Errorf("invalid object path %q", path)
+	}
+	return filepath.Join(s.basePath, clean), nil
+}
+
+// Save writes the object to a temporary file first, so readers never see it
+// half written.
+func (s *localStore) Save(path string, r io.Reader) (int64, error) {
+	file, err := s.file(path)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+		return 0, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	written, err := io.Copy(tmp, r)
+	if err != nil {
+		_ = tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	return written, os.Rename(tmp.Name(), file)
+}
+
+func (s *localStore) Open(path string) (io.ReadCloser, error) {
+	file, err := s.file(path)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(file)
+}
+
+func (s *localStore) Delete(path string) error {
+	file, err := s.file(path)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package objectstore
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore failed: %v", err)
+	}
+	written, err := store.Save("reports/r-1.csv", strings.NewReader("id,name\n"))
+	if err != nil || written != 8 {
+		t.Fatalf("Save failed: %d bytes, %v", written, err)
+	}
+	object, err := store.Open("reports/r-1.csv")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	data, _ := io.ReadAll(object)
+	_ = object.Close()
+	if string(data) != "id,name\n" {
+		t.Errorf("unexpected object %q", data)
+	}
+
+	if _, err := store.Save("../escape.csv", strings.NewReader("")); err == nil {
+		t.Error("expected paths leaving the base path to be refused")
+	}
+	if err := store.Delete("reports/r-1.csv"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Open("reports/r-1.csv"); !os.IsNotExist(err) {
+		t.Errorf("expected the object to be gone, got %v", err)
+	}
+}
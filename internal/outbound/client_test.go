@@ -0,0 +1,76 @@
+package outbound
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"golang.org/x/oauth2"
+)
+
+func TestNewClientProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token": "token", "token_type": "bearer"}`))
+	}))
+	defer proxy.Close()
+
+	client, err := NewClient(configs.OutboundConfig{ProxyURL: proxy.URL, Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &oauth2.Config{
+		ClientID: "client",
+		Endpoint: oauth2.Endpoint{TokenURL: "http://provider.invalid/token"},
+	}
+	token, err := cfg.Exchange(Context(context.Background(), client), "code")
+	if err != nil || token.AccessToken != "token" {
+		t.Fatalf("expected the exchange to go through the proxy, got %v", err)
+	}
+	if proxied != "http://provider.invalid/token" {
+		t.Errorf("expected the proxy to get the token request, got %q", proxied)
+	}
+
+	if _, err := NewClient(configs.OutboundConfig{ProxyURL: "proxy:3128"}); err == nil {
+		t.Error("expected a proxy URL without scheme to be refused")
+	}
+}
+
+func TestNewClientCAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := NewClient(configs.OutboundConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plain.Get(server.URL); err == nil {
+		t.Fatal("expected the test CA not to be trusted by default")
+	}
+	client, err := NewClient(configs.OutboundConfig{CAFile: caFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the configured CA to be trusted, got %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if _, err := NewClient(configs.OutboundConfig{CAFile: server.URL}); err == nil {
+		t.Error("expected a missing CA file to be refused")
+	}
+}
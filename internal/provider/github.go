@@ -2,23 +2,135 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/google/go-github/v73/github"
 )
 
-type GitHubProvider struct{}
+var ErrNoVerifiedEmail = errors.New("no verified primary email")
+
+type GitHubProvider struct {
+	// AllowedOrgs restricts logins to active members of any of these organizations
+	AllowedOrgs []string
+	// AllowedTeams restricts logins to active members of any of these teams ("org/team-slug")
+	AllowedTeams []string
+
+	// baseURL overrides the GitHub API endpoint, for GitHub Enterprise Server and tests
+	baseURL *url.URL
+	// httpClient calls the API, through the outbound proxy if configured
+	httpClient *http.Client
+}
+
+func (g *GitHubProvider) client(token string) *github.Client {
+	client := github.NewClient(g.httpClient).WithAuthToken(token)
+	if g.baseURL != nil {
+		client.BaseURL = g.baseURL
+	}
+	return client
+}
 
 func (g *GitHubProvider) GetUserInfo(ctx context.Context, token string) (UserInfo, error) {
-	client := github.NewClient(nil).WithAuthToken(token)
+	client := g.client(token)
 	user, _, err := client.Users.Get(ctx, "")
 	if err != nil {
 		return UserInfo{}, err
 	}
+
+	// The profile email is empty for users with a private address and is not
+	// necessarily verified, so use the primary verified address instead
+	email, err := g.primaryVerifiedEmail(ctx, client)
+	if err != nil {
+		return UserInfo{}, err
+	}
+
+	if err := g.checkMembership(ctx, client, user.GetLogin()); err != nil {
+		return UserInfo{}, err
+	}
+
 	return UserInfo{
 		ID:        fmt.Sprintf("%d", user.GetID()),
+		Login:     user.GetLogin(),
 		Name:      user.GetName(),
-		Email:     user.GetEmail(),
+		Email:     email,
 		AvatarURL: user.GetAvatarURL(),
 	}, nil
 }
+
+func (g *GitHubProvider) primaryVerifiedEmail(
+	ctx context.Context,
+	client *github.Client,
+) (string, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		emails, resp, err := client.Users.ListEmails(ctx, opts)
+		if err != nil {
+			return "", fmt.Errorf("failed to list emails: %w", err)
+		}
+		for _, email := range emails {
+			if email.GetPrimary() && email.GetVerified() {
+				return email.GetEmail(), nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return "", ErrNoVerifiedEmail
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// checkMembership verifies the org and team restrictions, if any are configured.
+// The user must be an active member of at least one allowed org or team.
+func (g *GitHubProvider) checkMembership(
+	ctx context.Context,
+	client *github.Client,
+	login string,
+) error {
+	if len(g.AllowedOrgs) == 0 && len(g.AllowedTeams) == 0 {
+		return nil
+	}
+
+	for _, org := range g.AllowedOrgs {
+		membership, resp, err := client.Organizations.GetOrgMembership(ctx, "", org)
+		if isNotMember(resp, err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get membership of org %s: %w", org, err)
+		}
+		if membership.GetState() == "active" {
+			return nil
+		}
+	}
+
+	for _, team := range g.AllowedTeams {
+		org, slug, ok := strings.Cut(team, "/")
+		if !ok {
+			return fmt.Errorf("invalid team %q, expected org/team-slug", team)
+		}
+		membership, resp, err := client.Teams.GetTeamMembershipBySlug(ctx, org, slug, login)
+		if isNotMember(resp, err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get membership of team %s: %w", team, err)
+		}
+		if membership.GetState() == "active" {
+			return nil
+		}
+	}
+
+	return ErrNotAllowed
+}
+
+// isNotMember reports whether GitHub answered that the membership does not exist.
+// GitHub also answers 403 if the organization restricts third-party access.
+func isNotMember(resp *github.Response, err error) bool {
+	if err == nil || resp == nil {
+		return false
+	}
+	return resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden
+}
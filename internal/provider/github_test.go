@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/configs"
+)
+
+func newTestGitHubProvider(t *testing.T, mux *http.ServeMux) *GitHubProvider {
+	t.Helper()
+	mux.HandleFunc("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 42, "login": "octocat", "name": "Octo Cat", "email": null}`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &GitHubProvider{baseURL: baseURL}
+}
+
+func TestGitHubProviderPrimaryVerifiedEmail(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /user/emails", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+			{"email": "old@example.com", "primary": false, "verified": true},
+			{"email": "octocat@example.com", "primary": true, "verified": true}
+		]`)
+	})
+	g := newTestGitHubProvider(t, mux)
+
+	info, err := g.GetUserInfo(context.Background(), "token")
+	if err != nil {
+		t.Fatalf("GetUserInfo failed: %v", err)
+	}
+	if info.Email != "octocat@example.com" {
+		t.Errorf("Expected primary verified email, got %q", info.Email)
+	}
+	if info.ID != "42" {
+		t.Errorf("Expected ID 42, got %q", info.ID)
+	}
+}
+
+func TestGitHubProviderUnverifiedPrimaryEmail(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /user/emails", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"email": "octocat@example.com", "primary": true, "verified": false}]`)
+	})
+	g := newTestGitHubProvider(t, mux)
+
+	_, err := g.GetUserInfo(context.Background(), "token")
+	if !errors.Is(err, ErrNoVerifiedEmail) {
+		t.Errorf("Expected ErrNoVerifiedEmail, got %v", err)
+	}
+}
+
+func TestGitHubProviderMembership(t *testing.T) {
+	tests := []struct {
+		name         string
+		allowedOrgs  []string
+		allowedTeams []string
+		wantErr      error
+	}{
+		{"no restriction", nil, nil, nil},
+		{"active org member", []string{"other-org", "poly-workshop"}, nil, nil},
+		{"pending org member", []string{"pending-org"}, nil, ErrNotAllowed},
+		{"not an org member", []string{"other-org"}, nil, ErrNotAllowed},
+		{"active team member", []string{"other-org"}, []string{"poly-workshop/core"}, nil},
+		{"not a team member", nil, []string{"poly-workshop/admins"}, ErrNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /user/emails", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `[{"email": "octocat@example.com", "primary": true, "verified": true}]`)
+			})
+			mux.HandleFunc(
+				"GET /user/memberships/orgs/{org}",
+				func(w http.ResponseWriter, r *http.Request) {
+					switch r.PathValue("org") {
+					case "poly-workshop":
+						fmt.Fprint(w, `{"state": "active"}`)
+					case "pending-org":
+						fmt.Fprint(w, `{"state": "pending"}`)
+					default:
+						http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+					}
+				},
+			)
+			mux.HandleFunc(
+				"GET /orgs/poly-workshop/teams/{team}/memberships/octocat",
+				func(w http.ResponseWriter, r *http.Request) {
+					if r.PathValue("team") == "core" {
+						fmt.Fprint(w, `{"state": "active"}`)
+						return
+					}
+					http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+				},
+			)
+			g := newTestGitHubProvider(t, mux)
+			g.AllowedOrgs = tt.allowedOrgs
+			g.AllowedTeams = tt.allowedTeams
+
+			_, err := g.GetUserInfo(context.Background(), "token")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGetUserProviderGitHubBaseURL(t *testing.T) {
+	userProvider, err := GetUserProvider(
+		"github",
+		configs.AuthConfig{GithubBaseURL: "https://github.example.com/"},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("GetUserProvider failed: %v", err)
+	}
+	baseURL := userProvider.(*GitHubProvider).baseURL
+	if baseURL == nil || baseURL.String() != "https://github.example.com/api/v3/" {
+		t.Errorf("expected the enterprise API url, got %v", baseURL)
+	}
+}
@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	mathrand "math/rand/v2"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v73/github"
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// ErrUnavailable is returned without calling a provider whose breaker is open
+// after repeated failures.
+var ErrUnavailable = errors.New("provider is unavailable")
+
+var (
+	userInfoRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_userinfo_retries_total",
+		Help: "User info calls retried after a server error of the provider.",
+	}, []string{"provider"})
+	userInfoRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_userinfo_breaker_rejections_total",
+		Help: "User info calls failed without calling the provider, its breaker being open.",
+	}, []string{"provider"})
+)
+
+// Guard bounds, retries and circuit-breaks user info calls as configured by
+// userinfo. Its breakers outlive the providers it wraps, so one Guard serves
+// all the logins of a server.
+type Guard struct {
+	cfg configs.UserInfoConfig
+	// now is time.Now, but for tests
+	now func() time.Time
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+func NewGuard(cfg configs.UserInfoConfig) *Guard {
+	return &Guard{cfg: cfg, now: time.Now, breakers: make(map[string]*breaker)}
+}
+
+// Wrap returns p guarded as the provider name. A nil Guard returns p as is.
+func (g *Guard) Wrap(name string, p UserProvider) UserProvider {
+	if g == nil {
+		return p
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b, ok := g.breakers[name]
+	if !ok {
+		b = &breaker{}
+		g.breakers[name] = b
+	}
+	return &guardedProvider{guard: g, name: name, next: p, breaker: b}
+}
+
+type guardedProvider struct {
+	guard   *Guard
+	name    string
+	next    UserProvider
+	breaker *breaker
+}
+
+func (p *guardedProvider) GetUserInfo(ctx context.Context, token string) (UserInfo, error) {
+	cfg := p.guard.cfg
+	if !p.breaker.allow(p.guard.now(), cfg.BreakerFailures) {
+		userInfoRejected.WithLabelValues(p.name).Inc()
+		return UserInfo{}, fmt.Errorf("%w: %s failed repeatedly", ErrUnavailable, p.name)
+	}
+
+	timeout := cfg.Timeout
+	if byProvider, ok := cfg.TimeoutByProvider[p.name]; ok {
+		timeout = byProvider
+	}
+	callCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var info UserInfo
+	var err error
+	for attempt := 0; ; attempt++ {
+		info, err = p.next.GetUserInfo(callCtx, token)
+		if !isServerError(err) || attempt >= cfg.MaxRetries ||
+			!sleep(callCtx, cfg.RetryBackoff<<attempt) {
+			break
+		}
+		userInfoRetries.WithLabelValues(p.name).Inc()
+	}
+
+	// Logins abandoned by the client say nothing about the provider
+	if ctx.Err() != nil {
+		p.breaker.release()
+		return info, err
+	}
+	p.breaker.record(p.guard.now(), isFailure(err), cfg.BreakerFailures, cfg.BreakerOpen)
+	return info, err
+}
+
+// sleep waits for backoff and up to half of it more, which spreads out the
+// retries of logins that failed together, and reports whether ctx is still
+// live.
+func sleep(ctx context.Context, backoff time.Duration) bool {
+	backoff += time.Duration(mathrand.Int64N(int64(backoff)/2 + 1))
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// isServerError reports whether err is a server error of the provider, which
+// may well not happen again.
+func isServerError(err error) bool {
+	var errResp *github.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response != nil &&
+		errResp.Response.StatusCode >= http.StatusInternalServerError
+}
+
+// isFailure reports whether err is a failure of the provider, rather than an
+// answer it gave about the user, such as a missing membership.
+func isFailure(err error) bool {
+	var urlErr *url.Error
+	return isServerError(err) || errors.As(err, &urlErr) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// breaker counts the consecutive failures of a provider. Once they reach the
+// threshold it is open: calls are rejected until it has been open for the
+// configured time, then one call at a time is let through until one succeeds.
+type breaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func (b *breaker) allow(now time.Time, threshold int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if threshold <= 0 || b.failures < threshold {
+		return true
+	}
+	if now.Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *breaker) record(now time.Time, failed bool, threshold int, open time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if threshold > 0 && b.failures >= threshold {
+		b.openUntil = now.Add(open)
+	}
+}
+
+// release ends a call that neither succeeded nor failed.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v73/github"
+	"github.com/poly-workshop/auth-portal/configs"
+)
+
+// flakyProvider answers with errs in turn, then with the user.
+type flakyProvider struct {
+	errs  []error
+	calls int
+}
+
+func (p *flakyProvider) GetUserInfo(ctx context.Context, _ string) (UserInfo, error) {
+	p.calls++
+	if len(p.errs) == 0 {
+		return UserInfo{ID: "1"}, nil
+	}
+	err := p.errs[0]
+	p.errs = p.errs[1:]
+	if err == context.DeadlineExceeded {
+		<-ctx.Done()
+		return UserInfo{}, ctx.Err()
+	}
+	return UserInfo{}, err
+}
+
+func statusError(code int) error {
+	return &github.ErrorResponse{Response: &http.Response{StatusCode: code}}
+}
+
+func TestGuardRetries(t *testing.T) {
+	guard := NewGuard(configs.UserInfoConfig{MaxRetries: 2, RetryBackoff: time.Millisecond})
+	ctx := context.Background()
+
+	flaky := &flakyProvider{errs: []error{statusError(502), statusError(503)}}
+	if info, err := guard.Wrap("github", flaky).GetUserInfo(ctx, "token"); err != nil ||
+		info.ID != "1" || flaky.calls != 3 {
+		t.Errorf("expected server errors to be retried, got %v after %d calls", err, flaky.calls)
+	}
+
+	flaky = &flakyProvider{errs: []error{statusError(502), statusError(502), statusError(502)}}
+	if _, err := guard.Wrap("github", flaky).GetUserInfo(ctx, "token"); err == nil ||
+		flaky.calls != 3 {
+		t.Errorf("expected 2 retries at most, got %v after %d calls", err, flaky.calls)
+	}
+
+	for _, err := range []error{statusError(404), ErrNotAllowed} {
+		flaky = &flakyProvider{errs: []error{err}}
+		if _, got := guard.Wrap("github", flaky).GetUserInfo(ctx, "token"); !errors.Is(
+			got,
+			err,
+		) || flaky.calls != 1 {
+			t.Errorf("expected %v not to be retried, got %v after %d calls", err, got, flaky.calls)
+		}
+	}
+}
+
+func TestGuardTimeout(t *testing.T) {
+	guard := NewGuard(configs.UserInfoConfig{
+		Timeout:           time.Hour,
+		TimeoutByProvider: map[string]time.Duration{"github": 10 * time.Millisecond},
+	})
+	flaky := &flakyProvider{errs: []error{context.DeadlineExceeded}}
+	_, err := guard.Wrap("github", flaky).GetUserInfo(context.Background(), "token")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the provider timeout to apply, got %v", err)
+	}
+}
+
+func TestGuardBreaker(t *testing.T) {
+	now := time.Now()
+	guard := NewGuard(configs.UserInfoConfig{BreakerFailures: 2, BreakerOpen: time.Minute})
+	guard.now = func() time.Time { return now }
+	ctx := context.Background()
+	failing := &flakyProvider{errs: []error{statusError(500), statusError(500)}}
+	for range 2 {
+		if _, err := guard.Wrap("github", failing).GetUserInfo(ctx, "token"); err == nil {
+			t.Fatal("expected the provider to fail")
+		}
+	}
+
+	if _, err := guard.Wrap("github", failing).GetUserInfo(ctx, "token"); !errors.Is(
+		err,
+		ErrUnavailable,
+	) || failing.calls != 2 {
+		t.Fatalf("expected the breaker to open, got %v after %d calls", err, failing.calls)
+	}
+	other := &flakyProvider{}
+	if _, err := guard.Wrap("other", other).GetUserInfo(ctx, "token"); err != nil {
+		t.Errorf("expected other providers to be called, got %v", err)
+	}
+
+	// A login abandoned while trying again doesn't close nor reopen the breaker
+	now = now.Add(time.Minute)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	abandoned := &flakyProvider{errs: []error{context.DeadlineExceeded}}
+	_, _ = guard.Wrap("github", abandoned).GetUserInfo(canceled, "token")
+	if _, err := guard.Wrap("github", failing).GetUserInfo(ctx, "token"); err != nil {
+		t.Fatalf("expected the breaker to let a call through, got %v", err)
+	}
+	if _, err := guard.Wrap("github", failing).GetUserInfo(ctx, "token"); err != nil {
+		t.Errorf("expected the breaker to close, got %v", err)
+	}
+}
@@ -2,11 +2,22 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/poly-workshop/auth-portal/configs"
 )
 
+// ErrNotAllowed is returned when the user is authenticated by the provider but
+// does not satisfy the configured restrictions.
+var ErrNotAllowed = errors.New("user is not allowed to log in")
+
 type UserInfo struct {
 	ID        string
+	Login     string
 	Name      string
 	Email     string
 	AvatarURL string
@@ -16,10 +27,29 @@ type UserProvider interface {
 	GetUserInfo(ctx context.Context, token string) (UserInfo, error)
 }
 
-func GetUserProvider(name string) (UserProvider, error) {
+// GetUserProvider returns the user info provider name, calling it with client
+// (nil for http.DefaultClient).
+func GetUserProvider(
+	name string,
+	cfg configs.AuthConfig,
+	client *http.Client,
+) (UserProvider, error) {
 	switch name {
 	case "github":
-		return &GitHubProvider{}, nil
+		githubProvider := &GitHubProvider{
+			AllowedOrgs:  cfg.GithubAllowedOrgs,
+			AllowedTeams: cfg.GithubAllowedTeams,
+			httpClient:   client,
+		}
+		// GitHub Enterprise Server serves the REST API under /api/v3
+		if cfg.GithubBaseURL != "" {
+			baseURL, err := url.Parse(strings.TrimSuffix(cfg.GithubBaseURL, "/") + "/api/v3/")
+			if err != nil {
+				return nil, fmt.Errorf("invalid github base url: %w", err)
+			}
+			githubProvider.baseURL = baseURL
+		}
+		return githubProvider, nil
 	default:
 		return nil, fmt.Errorf("provider %s not supported", name)
 	}
@@ -0,0 +1,118 @@
+// Package provisioning runs the hooks deployments use to enrich or reject the
+// users created on their first OAuth login, e.g. to derive the role or the
+// organization of a user from their GitHub teams.
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/model"
+)
+
+// HookWebhook is the name of the hook calling provisioning.webhook_url.
+const HookWebhook = "webhook"
+
+// Identity is what the provider told about a user logging in for the first time.
+type Identity struct {
+	Provider string `json:"provider"`
+	ID       string `json:"id"`
+	Login    string `json:"login"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	// AccessToken lets hooks call the provider on the user's behalf, e.g. to
+	// list their GitHub teams; it is never sent to the webhook
+	AccessToken string `json:"-"`
+}
+
+// Hook is invoked before a user signing up by OAuth is created. It may change
+// the user, e.g. the role, name or metadata, or reject the signup by returning
+// a RejectedError. Any other error fails the login.
+type Hook interface {
+	Provision(ctx context.Context, identity Identity, user *model.UserModel) error
+}
+
+// HookFunc adapts a function to a Hook.
+type HookFunc func(ctx context.Context, identity Identity, user *model.UserModel) error
+
+func (f HookFunc) Provision(ctx context.Context, identity Identity, user *model.UserModel) error {
+	return f(ctx, identity, user)
+}
+
+// RejectedError rejects the provisioning of a user; the reason is shown to them.
+type RejectedError struct {
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("provisioning rejected: %s", e.Reason)
+}
+
+// Reject returns a RejectedError with the reason.
+func Reject(reason string) error {
+	return &RejectedError{Reason: reason}
+}
+
+// IsRejected reports whether err rejects the provisioning, and its reason.
+func IsRejected(err error) (string, bool) {
+	var rejected *RejectedError
+	if errors.As(err, &rejected) {
+		return rejected.Reason, true
+	}
+	return "", false
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Hook)
+)
+
+// Register makes a hook available to provisioning.hooks by name, for builds of
+// the server that compile in their own hooks, typically from an init function.
+// It panics if the name is taken, like database/sql.Register.
+func Register(name string, hook Hook) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, taken := registry[name]; taken || name == HookWebhook {
+		panic(fmt.Sprintf("provisioning: hook %s registered twice", name))
+	}
+	registry[name] = hook
+}
+
+// Hooks run in order; the first error stops the provisioning.
+type Hooks []Hook
+
+// NewHooks resolves the configured hooks.
+func NewHooks(cfg configs.ProvisioningConfig) (Hooks, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	hooks := make(Hooks, 0, len(cfg.Hooks))
+	for _, name := range cfg.Hooks {
+		if name == HookWebhook {
+			webhook, err := newWebhook(cfg)
+			if err != nil {
+				return nil, err
+			}
+			hooks = append(hooks, webhook)
+			continue
+		}
+		hook, ok := registry[name]
+		if !ok {
+			return nil, fmt.Errorf("provisioning hook %s is not registered", name)
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+func (h Hooks) Provision(ctx context.Context, identity Identity, user *model.UserModel) error {
+	for _, hook := range h {
+		if err := hook.Provision(ctx, identity, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
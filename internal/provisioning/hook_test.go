@@ -0,0 +1,99 @@
+package provisioning
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/model"
+)
+
+func TestNewHooks(t *testing.T) {
+	Register("test-org", HookFunc(
+		func(_ context.Context, identity Identity, user *model.UserModel) error {
+			user.Metadata = map[string]string{"org": identity.Login}
+			return nil
+		},
+	))
+	hooks, err := NewHooks(configs.ProvisioningConfig{Hooks: []string{"test-org"}})
+	if err != nil {
+		t.Fatalf("NewHooks failed: %v", err)
+	}
+	user := &model.UserModel{}
+	if err := hooks.Provision(context.Background(), Identity{Login: "octo"}, user); err != nil {
+		t.Fatalf("Provision failed: %v", err)
+	}
+	if user.Metadata["org"] != "octo" {
+		t.Errorf("expected the hook to set the metadata, got %v", user.Metadata)
+	}
+
+	for _, cfg := range []configs.ProvisioningConfig{
+		{Hooks: []string{"unknown"}},
+		{Hooks: []string{HookWebhook}},
+	} {
+		if _, err := NewHooks(cfg); err == nil {
+			t.Errorf("expected %v to be rejected", cfg.Hooks)
+		}
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var answer string
+	var got webhookRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(body)
+		if r.Header.Get("X-Signature") != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.Unmarshal(body, &got)
+		_, _ = io.WriteString(w, answer)
+	}))
+	defer srv.Close()
+	hooks, err := NewHooks(configs.ProvisioningConfig{
+		Hooks:          []string{HookWebhook},
+		WebhookURL:     srv.URL,
+		WebhookSecret:  "secret",
+		WebhookTimeout: time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewHooks failed: %v", err)
+	}
+	identity := Identity{Provider: "github", ID: "42", Login: "octo", AccessToken: "token"}
+	provision := func(response string) (*model.UserModel, error) {
+		answer = response
+		user := &model.UserModel{Name: "Octo", Email: "octo@example.com", Role: model.UserRoleUser}
+		return user, hooks.Provision(context.Background(), identity, user)
+	}
+
+	user, err := provision(`{"allow": true, "role": "admin", "metadata": {"org": "platform"}}`)
+	if err != nil {
+		t.Fatalf("Provision failed: %v", err)
+	}
+	if user.Role != model.UserRoleAdmin || user.Name != "Octo" || user.Metadata["org"] != "platform" {
+		t.Errorf("expected the answer to be applied, got %+v", user)
+	}
+	if got.Identity.Login != "octo" || got.Identity.AccessToken != "" ||
+		got.User.Email != "octo@example.com" {
+		t.Errorf("unexpected webhook request %+v", got)
+	}
+
+	_, err = provision(`{"allow": false, "reason": "not in an engineering team"}`)
+	if reason, rejected := IsRejected(err); !rejected || reason != "not in an engineering team" {
+		t.Errorf("expected the signup to be rejected, got %v", err)
+	}
+	_, err = provision(`{"allow": true, "role": "owner"}`)
+	if _, rejected := IsRejected(err); err == nil || rejected {
+		t.Errorf("expected unknown roles to fail, got %v", err)
+	}
+}
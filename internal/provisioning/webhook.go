@@ -0,0 +1,118 @@
+package provisioning
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/model"
+)
+
+// maxWebhookResponseSize bounds the webhook answers that are read.
+const maxWebhookResponseSize = 64 << 10
+
+// webhookUser is the user to create as sent to the webhook.
+type webhookUser struct {
+	Name     string            `json:"name"`
+	Email    string            `json:"email"`
+	Role     model.UserRole    `json:"role"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+type webhookRequest struct {
+	Identity Identity    `json:"identity"`
+	User     webhookUser `json:"user"`
+}
+
+// webhookResponse is the answer of the webhook; unset fields keep the user as is.
+type webhookResponse struct {
+	Allow    bool              `json:"allow"`
+	Reason   string            `json:"reason"`
+	Role     model.UserRole    `json:"role"`
+	Name     string            `json:"name"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// webhook lets an HTTP endpoint of the deployment decide on new users.
+type webhook struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func newWebhook(cfg configs.ProvisioningConfig) (*webhook, error) {
+	if cfg.WebhookURL == "" {
+		return nil, fmt.Errorf("the webhook provisioning hook requires provisioning.webhook_url")
+	}
+	return &webhook{
+		url:    cfg.WebhookURL,
+		secret: cfg.WebhookSecret,
+		client: &http.Client{Timeout: cfg.WebhookTimeout},
+	}, nil
+}
+
+func (w *webhook) Provision(ctx context.Context, identity Identity, user *model.UserModel) error {
+	payload, err := json.Marshal(webhookRequest{
+		Identity: identity,
+		User: webhookUser{
+			Name:     user.Name,
+			Email:    user.Email,
+			Role:     user.Role,
+			Metadata: user.Metadata,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.secret))
+		mac.Write(payload)
+		req.Header.Set("X-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("provisioning webhook failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("provisioning webhook answered %s", resp.Status)
+	}
+
+	var answer webhookResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxWebhookResponseSize)).
+		Decode(&answer); err != nil {
+		return fmt.Errorf("invalid provisioning webhook answer: %w", err)
+	}
+	if !answer.Allow {
+		return Reject(answer.Reason)
+	}
+	switch answer.Role {
+	case "":
+	case model.UserRoleUser, model.UserRoleAdmin:
+		user.Role = answer.Role
+	default:
+		return fmt.Errorf("provisioning webhook answered unknown role %q", answer.Role)
+	}
+	if answer.Name != "" {
+		user.Name = answer.Name
+	}
+	for key, value := range answer.Metadata {
+		if user.Metadata == nil {
+			user.Metadata = make(map[string]string)
+		}
+		user.Metadata[key] = value
+	}
+	return nil
+}
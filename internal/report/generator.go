@@ -0,0 +1,178 @@
+// Package report generates the reports admins request (user roster, login
+// activity and audit summaries) as CSV or PDF files.
+package report
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+)
+
+// pageSize is how many users or events are loaded at a time.
+const pageSize = 500
+
+// Generator writes reports from the user and audit repositories.
+type Generator struct {
+	users repository.UserRepository
+	audit repository.AuditRepository
+}
+
+func NewGenerator(users repository.UserRepository, audit repository.AuditRepository) *Generator {
+	return &Generator{users: users, audit: audit}
+}
+
+// Generate writes the report to w in its format.
+func (g *Generator) Generate(ctx context.Context, report *model.ReportModel, w io.Writer) error {
+	table := NewTableWriter(report.Format, title(report), w)
+	var err error
+	switch report.Kind {
+	case model.ReportKindUserRoster:
+		err = g.userRoster(ctx, table)
+	case model.ReportKindLoginActivity:
+		err = g.loginActivity(ctx, report.Since(), table)
+	case model.ReportKindAuditSummary:
+		err = g.auditSummary(ctx, report.Since(), table)
+	default:
+		err = fmt.Errorf("unknown report kind %q", report.Kind)
+	}
+	if err != nil {
+		return err
+	}
+	return table.Close()
+}
+
+func title(report *model.ReportModel) string {
+	name := strings.ReplaceAll(string(report.Kind), "_", " ")
+	if report.Kind == model.ReportKindUserRoster {
+		return fmt.Sprintf("%s as of %s", name, formatTime(report.CreatedAt))
+	}
+	return fmt.Sprintf("%s from %s to %s", name, formatTime(report.Since()),
+		formatTime(report.CreatedAt))
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return formatTime(*t)
+}
+
+func (g *Generator) userRoster(ctx context.Context, table TableWriter) error {
+	err := table.Write([]string{
+		"id", "name", "email", "role", "status", "created_at", "last_seen_at", "entitlements",
+	})
+	if err != nil {
+		return err
+	}
+	after := ""
+	for {
+		users, err := g.users.ListAfter(ctx, repository.UserFilter{}, after, pageSize)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			err := table.Write([]string{
+				user.ID,
+				user.Name,
+				user.Email,
+				string(user.Role),
+				userStatus(user),
+				formatTime(user.CreatedAt),
+				formatOptionalTime(user.LastSeenAt),
+				strings.Join(user.Entitlements, " "),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if len(users) < pageSize {
+			return nil
+		}
+		after = users[len(users)-1].ID
+	}
+}
+
+func userStatus(user *model.UserModel) string {
+	switch {
+	case user.DeletionScheduledAt != nil:
+		return "deletion_scheduled"
+	case user.DeactivatedAt != nil:
+		return "deactivated"
+	case user.PendingApproval:
+		return "pending_approval"
+	default:
+		return "active"
+	}
+}
+
+func (g *Generator) loginActivity(ctx context.Context, since time.Time, table TableWriter) error {
+	err := table.Write([]string{"time", "result", "user_id", "ip_address", "user_agent", "details"})
+	if err != nil {
+		return err
+	}
+	filter := repository.AuditFilter{
+		Since: since,
+		Types: []model.AuditEventType{model.AuditEventLoginSucceeded, model.AuditEventLoginFailed},
+	}
+	var after *model.AuditEventModel
+	for {
+		events, err := g.audit.ListAfter(ctx, filter, after, pageSize)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			result := "succeeded"
+			if event.Type == model.AuditEventLoginFailed {
+				result = "failed"
+			}
+			var userID string
+			if event.UserID != nil {
+				userID = *event.UserID
+			}
+			err := table.Write([]string{
+				formatTime(event.CreatedAt),
+				result,
+				userID,
+				event.IPAddress,
+				event.UserAgent,
+				event.Metadata,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if len(events) < pageSize {
+			return nil
+		}
+		after = events[len(events)-1]
+	}
+}
+
+func (g *Generator) auditSummary(ctx context.Context, since time.Time, table TableWriter) error {
+	counts, err := g.audit.CountByType(ctx, repository.AuditFilter{Since: since})
+	if err != nil {
+		return err
+	}
+	if err := table.Write([]string{"event_type", "count"}); err != nil {
+		return err
+	}
+	for _, eventType := range slices.Sorted(maps.Keys(counts)) {
+		count := strconv.FormatInt(counts[eventType], 10)
+		if err := table.Write([]string{string(eventType), count}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// The PDF layout: landscape A4 in Courier, so columns line up without font
+// metrics.
+const (
+	pdfPageWidth    = 842
+	pdfPageHeight   = 595
+	pdfMargin       = 36
+	pdfFontSize     = 7
+	pdfLeading      = 9
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+	// Courier glyphs are 0.6 em wide
+	pdfCharsPerLine = (pdfPageWidth - 2*pdfMargin) * 10 / (pdfFontSize * 6)
+	pdfMaxCellWidth = 40
+)
+
+// pdfWriter lays the rows out as a text table once all are known; the first
+// row is the header, repeated on every page.
+type pdfWriter struct {
+	title string
+	w     io.Writer
+	rows  [][]string
+}
+
+func newPDFWriter(title string, w io.Writer) *pdfWriter {
+	return &pdfWriter{title: title, w: w}
+}
+
+func (p *pdfWriter) Write(row []string) error {
+	p.rows = append(p.rows, row)
+	return nil
+}
+
+func (p *pdfWriter) Close() error {
+	header, body := p.layout()
+	var pages [][]string
+	page := append([]string{p.title, ""}, header...)
+	for _, line := range body {
+		if len(page) == pdfLinesPerPage {
+			pages = append(pages, page)
+			page = append([]string(nil), header...)
+		}
+		page = append(page, line)
+	}
+	pages = append(pages, page)
+	return writePDF(p.w, pages)
+}
+
+// layout formats the rows as fixed width lines: the header with a rule below
+// it, and the body.
+func (p *pdfWriter) layout() (header, body []string) {
+	if len(p.rows) == 0 {
+		return nil, nil
+	}
+	var widths []int
+	for _, row := range p.rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = min(max(widths[i], utf8.RuneCountInString(cell)), pdfMaxCellWidth)
+		}
+	}
+	lines := make([]string, len(p.rows))
+	for i, row := range p.rows {
+		cells := make([]string, len(row))
+		for j, cell := range row {
+			cells[j] = pad(cell, widths[j])
+		}
+		lines[i] = truncate(strings.Join(cells, "  "), pdfCharsPerLine)
+	}
+	rule := strings.Repeat("-", utf8.RuneCountInString(lines[0]))
+	return []string{lines[0], rule}, lines[1:]
+}
+
+func pad(cell string, width int) string {
+	cell = truncate(cell, width)
+	return cell + strings.Repeat(" ", width-utf8.RuneCountInString(cell))
+}
+
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "~"
+}
+
+// writePDF writes a PDF with a page of text per entry of pages.
+func writePDF(w io.Writer, pages [][]string) error {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		// Each page is followed by its content stream
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>",
+		strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	for i, lines := range pages {
+		object(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+				"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 5+2*i,
+		))
+		var content strings.Builder
+		fmt.Fprintf(&content, "BT /F1 %d Tf %d TL %d %d Td\n",
+			pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin)
+		for _, line := range lines {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", pdfString(line))
+		}
+		content.WriteString("ET")
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream",
+			content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(offsets)+1, xref)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// pdfString escapes s for a PDF string literal; characters outside of
+// printable ASCII become "?", as the standard fonts can't be relied on for them.
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
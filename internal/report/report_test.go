@@ -0,0 +1,123 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+)
+
+func TestGenerateUserRosterCSV(t *testing.T) {
+	users := testutil.NewUserRepository(
+		&model.UserModel{ID: "user-1", Name: "=HYPERLINK(\"x\")", Email: "a@example.com"},
+		&model.UserModel{ID: "user-2", Name: "Bob", Email: "b@example.com", PendingApproval: true},
+	)
+	generator := NewGenerator(users, testutil.NewAuditRepository())
+	var buf bytes.Buffer
+	report := &model.ReportModel{Kind: model.ReportKindUserRoster, Format: model.ReportFormatCSV}
+	if err := generator.Generate(context.Background(), report, &buf); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(rows) != 3 || rows[0][0] != "id" {
+		t.Fatalf("expected a header and 2 users, got %v", rows)
+	}
+	if rows[1][1] != "'=HYPERLINK(\"x\")" {
+		t.Errorf("expected the formula to be escaped, got %q", rows[1][1])
+	}
+	if rows[2][4] != "pending_approval" {
+		t.Errorf("expected the pending user's status, got %q", rows[2][4])
+	}
+}
+
+func TestGenerateAuditSummaryPDF(t *testing.T) {
+	ctx := context.Background()
+	audit := testutil.NewAuditRepository()
+	for _, eventType := range []model.AuditEventType{
+		model.AuditEventLoginSucceeded,
+		model.AuditEventLoginFailed,
+		model.AuditEventLoginSucceeded,
+	} {
+		if err := audit.Create(ctx, &model.AuditEventModel{Type: eventType}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	generator := NewGenerator(testutil.NewUserRepository(), audit)
+	report := &model.ReportModel{
+		Kind:       model.ReportKindAuditSummary,
+		Format:     model.ReportFormatPDF,
+		PeriodDays: 30,
+		CreatedAt:  time.Now().Add(time.Minute),
+	}
+	var buf bytes.Buffer
+	if err := generator.Generate(ctx, report, &buf); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	pdf := buf.String()
+	if !strings.HasPrefix(pdf, "%PDF-1.4\n") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatalf("expected a PDF document, got %q", pdf)
+	}
+	if !strings.Contains(pdf, "(login.succeeded  2    ) Tj") {
+		t.Errorf("expected the count of successful logins in the PDF, got %q", pdf)
+	}
+}
+
+func TestPDFPagination(t *testing.T) {
+	var buf bytes.Buffer
+	writer := newPDFWriter("title", &buf)
+	_ = writer.Write([]string{"header"})
+	for range pdfLinesPerPage * 2 {
+		_ = writer.Write([]string{"row (1)"})
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	pdf := buf.String()
+	if !strings.Contains(pdf, "/Count 3") {
+		t.Errorf("expected 3 pages")
+	}
+	// The header repeats on every page and parentheses are escaped
+	if n := strings.Count(pdf, "(header ) Tj"); n != 3 {
+		t.Errorf("expected the header on 3 pages, got %d", n)
+	}
+	if !strings.Contains(pdf, `(row \(1\)) Tj`) {
+		t.Errorf("expected escaped parentheses")
+	}
+}
+
+func TestSigner(t *testing.T) {
+	if _, err := NewSigner(""); err == nil {
+		t.Error("expected an empty key to be refused")
+	}
+	signer, err := NewSigner("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	expires := now.Add(time.Minute)
+	signature := signer.Sign("report-1", expires)
+	if !signer.Verify("report-1", expires, signature, now) {
+		t.Error("expected the signature to be valid")
+	}
+	if signer.Verify("report-2", expires, signature, now) {
+		t.Error("expected the signature to be invalid for another report")
+	}
+	if signer.Verify("report-1", expires.Add(time.Second), signature, now) {
+		t.Error("expected the signature to be invalid for another expiry")
+	}
+	if signer.Verify("report-1", expires, signature, expires) {
+		t.Error("expected the signature to be invalid once expired")
+	}
+	other, _ := NewSigner("other")
+	if other.Verify("report-1", expires, signature, now) {
+		t.Error("expected the signature to be invalid with another key")
+	}
+}
@@ -0,0 +1,46 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+)
+
+// TableWriter writes the rows of a report in a file format; Close completes
+// the file.
+type TableWriter interface {
+	Write(row []string) error
+	Close() error
+}
+
+// NewTableWriter returns the writer of format writing to w.
+func NewTableWriter(format model.ReportFormat, title string, w io.Writer) TableWriter {
+	if format == model.ReportFormatPDF {
+		return newPDFWriter(title, w)
+	}
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+// Write escapes cells spreadsheets would take for formulas, since names and
+// emails are chosen by users.
+func (c *csvWriter) Write(row []string) error {
+	escaped := make([]string, len(row))
+	for i, cell := range row {
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			cell = "'" + cell
+		}
+		escaped[i] = cell
+	}
+	return c.w.Write(escaped)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
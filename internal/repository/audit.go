@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AuditRepository interface {
+	Create(ctx context.Context, event *model.AuditEventModel) error
+	AnonymizeByUserID(ctx context.Context, userID, pseudonym string) (int64, error)
+	ScrubBefore(ctx context.Context, before time.Time) (int64, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+	// DetachBefore removes the user reference and client information of the
+	// events of the types created before the given time.
+	DetachBefore(
+		ctx context.Context,
+		types []model.AuditEventType,
+		before time.Time,
+	) (int64, error)
+	// ListAfter returns up to limit events in the order they were created,
+	// starting after the event after (from the first one for nil).
+	ListAfter(
+		ctx context.Context,
+		filter AuditFilter,
+		after *model.AuditEventModel,
+		limit int,
+	) ([]*model.AuditEventModel, error)
+	CountByType(ctx context.Context, filter AuditFilter) (map[model.AuditEventType]int64, error)
+	// ListChain returns up to limit events of the hash chain in its order,
+	// starting after the event with sequence after (from the first one for 0).
+	ListChain(ctx context.Context, after int64, limit int) ([]*model.AuditEventModel, error)
+}
+
+// AuditFilter narrows down ListAfter and CountByType; zero fields don't filter.
+type AuditFilter struct {
+	// Since matches events created at or after this time
+	Since time.Time
+	Types []model.AuditEventType
+}
+
+func (f AuditFilter) apply(db *gorm.DB) *gorm.DB {
+	if !f.Since.IsZero() {
+		db = db.Where("created_at >= ?", f.Since)
+	}
+	if len(f.Types) > 0 {
+		db = db.Where("type IN ?", f.Types)
+	}
+	return db
+}
+
+// Matches reports whether event passes the filter.
+func (f AuditFilter) Matches(event *model.AuditEventModel) bool {
+	if event.CreatedAt.Before(f.Since) {
+		return false
+	}
+	return len(f.Types) == 0 || slices.Contains(f.Types, event.Type)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// Create appends the event to the hash chain. Appends are serialized by
+// locking the head of the chain.
+func (r *auditRepository) Create(ctx context.Context, event *model.AuditEventModel) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		head, err := lockChainHead(tx)
+		if err != nil {
+			return err
+		}
+		event.Seal(head.Sequence, head.Hash)
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		head.Sequence, head.Hash = event.Sequence, event.Hash
+		return tx.Save(head).Error
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create audit event", "error", err, "type", event.Type)
+		return err
+	}
+	return nil
+}
+
+// lockChainHead returns the head of the hash chain, locked until tx ends, and
+// creates it for the first event.
+func lockChainHead(tx *gorm.DB) (*model.AuditChainHeadModel, error) {
+	head := &model.AuditChainHeadModel{ID: model.AuditChainHeadID}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(head).Error
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return head, err
+	}
+	// Another first event may create it concurrently
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(head).Error; err != nil {
+		return nil, err
+	}
+	return head, tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(head).Error
+}
+
+// AnonymizeByUserID replaces the user reference of all events with a pseudonym
+// and scrubs the client information they carry. The events stay correlatable
+// with each other but can no longer be linked to a person.
+func (r *auditRepository) AnonymizeByUserID(
+	ctx context.Context,
+	userID, pseudonym string,
+) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.AuditEventModel{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]any{
+			"user_id":    nil,
+			"pseudonym":  pseudonym,
+			"ip_address": "",
+			"user_agent": "",
+			"metadata":   "",
+			"scrubbed":   true,
+		})
+	if result.Error != nil {
+		slog.ErrorContext(
+			ctx,
+			"failed to anonymize audit events",
+			"error",
+			result.Error,
+			"user_id",
+			userID,
+		)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// ScrubBefore clears client information and metadata of events created before the given time.
+func (r *auditRepository) ScrubBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.AuditEventModel{}).
+		Where("created_at < ?", before).
+		Where("ip_address <> '' OR user_agent <> '' OR metadata <> ''").
+		Updates(map[string]any{
+			"ip_address": "",
+			"user_agent": "",
+			"metadata":   "",
+			"scrubbed":   true,
+		})
+	if result.Error != nil {
+		slog.ErrorContext(ctx, "failed to scrub audit events", "error", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// DeleteBefore removes events created before the given time.
+func (r *auditRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("created_at < ?", before).
+		Delete(&model.AuditEventModel{})
+	if result.Error != nil {
+		slog.ErrorContext(ctx, "failed to delete audit events", "error", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *auditRepository) DetachBefore(
+	ctx context.Context,
+	types []model.AuditEventType,
+	before time.Time,
+) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.AuditEventModel{}).
+		Where("created_at < ? AND type IN ?", before, types).
+		Where("user_id IS NOT NULL OR ip_address <> '' OR user_agent <> '' OR metadata <> ''").
+		Updates(map[string]any{
+			"user_id":    nil,
+			"ip_address": "",
+			"user_agent": "",
+			"metadata":   "",
+			"scrubbed":   true,
+		})
+	if result.Error != nil {
+		slog.ErrorContext(ctx, "failed to detach audit events", "error", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *auditRepository) ListAfter(
+	ctx context.Context,
+	filter AuditFilter,
+	after *model.AuditEventModel,
+	limit int,
+) ([]*model.AuditEventModel, error) {
+	db := filter.apply(r.db.WithContext(ctx))
+	if after != nil {
+		db = db.Where(
+			"created_at > ? OR (created_at = ? AND id > ?)",
+			after.CreatedAt,
+			after.CreatedAt,
+			after.ID,
+		)
+	}
+	var events []*model.AuditEventModel
+	err := db.Order("created_at, id").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+func (r *auditRepository) CountByType(
+	ctx context.Context,
+	filter AuditFilter,
+) (map[model.AuditEventType]int64, error) {
+	var rows []struct {
+		Type  model.AuditEventType
+		Count int64
+	}
+	err := filter.apply(r.db.WithContext(ctx).Model(&model.AuditEventModel{})).
+		Select("type, COUNT(*) AS count").
+		Group("type").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[model.AuditEventType]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Type] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *auditRepository) ListChain(
+	ctx context.Context,
+	after int64,
+	limit int,
+) ([]*model.AuditEventModel, error) {
+	var events []*model.AuditEventModel
+	err := r.db.WithContext(ctx).
+		Where("sequence > ?", after).
+		Order("sequence").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrDeviceAuthorizationNotFound = errors.New("device authorization not found")
+	// ErrUserCodeTaken is returned by Create when the user code is in use
+	ErrUserCodeTaken = errors.New("user code is taken")
+)
+
+// Device authorization states
+const (
+	DeviceAuthorizationPending  = "pending"
+	DeviceAuthorizationApproved = "approved"
+	DeviceAuthorizationDenied   = "denied"
+)
+
+// DeviceAuthorization is a pending login of a device (RFC 8628): the device
+// polls with its device code until a logged-in user approves the user code.
+type DeviceAuthorization struct {
+	// ID is the hash of the device code
+	ID         string    `json:"-"`
+	UserCode   string    `json:"user_code"`
+	ClientName string    `json:"client_name,omitempty"`
+	Status     string    `json:"status"`
+	UserID     string    `json:"user_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// Interval is the least time between polls, raised when the device polls faster
+	Interval     time.Duration `json:"interval"`
+	LastPolledAt time.Time     `json:"last_polled_at"`
+}
+
+// DeviceAuthorizationRepository stores device authorizations in Redis, keyed by
+// a hash of the device code and found by user code for the approval.
+type DeviceAuthorizationRepository interface {
+	Create(ctx context.Context, deviceCode string, auth *DeviceAuthorization) error
+	Get(ctx context.Context, deviceCode string) (*DeviceAuthorization, error)
+	GetByUserCode(ctx context.Context, userCode string) (*DeviceAuthorization, error)
+	// Save updates an authorization, keeping its expiration
+	Save(ctx context.Context, auth *DeviceAuthorization) error
+	// RecordPoll updates the interval and time of the last poll of auth if it
+	// is still pending; a decision made since auth was read is kept
+	RecordPoll(ctx context.Context, auth *DeviceAuthorization) error
+	// Delete removes an authorization, returning ErrDeviceAuthorizationNotFound
+	// if it was already removed, so only one caller redeems it
+	Delete(ctx context.Context, auth *DeviceAuthorization) error
+}
+
+type deviceAuthorizationRepository struct {
+	rdb redis.UniversalClient
+}
+
+func NewDeviceAuthorizationRepository(rdb redis.UniversalClient) DeviceAuthorizationRepository {
+	return &deviceAuthorizationRepository{rdb: rdb}
+}
+
+func deviceAuthorizationKey(id string) string {
+	return fmt.Sprintf("device_auth:%s", id)
+}
+
+func deviceUserCodeKey(userCode string) string {
+	return fmt.Sprintf("device_user_code:%s", userCode)
+}
+
+func (r *deviceAuthorizationRepository) Create(
+	ctx context.Context,
+	deviceCode string,
+	auth *DeviceAuthorization,
+) error {
+	auth.ID = utils.HashToken(deviceCode)
+	data, err := json.Marshal(auth)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(auth.ExpiresAt)
+	ok, err := r.rdb.SetNX(ctx, deviceUserCodeKey(auth.UserCode), auth.ID, ttl).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrUserCodeTaken
+	}
+	return r.rdb.Set(ctx, deviceAuthorizationKey(auth.ID), data, ttl).Err()
+}
+
+func (r *deviceAuthorizationRepository) Get(
+	ctx context.Context,
+	deviceCode string,
+) (*DeviceAuthorization, error) {
+	return r.get(ctx, utils.HashToken(deviceCode))
+}
+
+func (r *deviceAuthorizationRepository) GetByUserCode(
+	ctx context.Context,
+	userCode string,
+) (*DeviceAuthorization, error) {
+	id, err := r.rdb.Get(ctx, deviceUserCodeKey(userCode)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrDeviceAuthorizationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.get(ctx, id)
+}
+
+func (r *deviceAuthorizationRepository) get(
+	ctx context.Context,
+	id string,
+) (*DeviceAuthorization, error) {
+	data, err := r.rdb.Get(ctx, deviceAuthorizationKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrDeviceAuthorizationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	auth := DeviceAuthorization{ID: id}
+	if err := json.Unmarshal(data, &auth); err != nil {
+		return nil, err
+	}
+	return &auth, nil
+}
+
+func (r *deviceAuthorizationRepository) Save(ctx context.Context, auth *DeviceAuthorization) error {
+	data, err := json.Marshal(auth)
+	if err != nil {
+		return err
+	}
+	err = r.rdb.SetArgs(ctx, deviceAuthorizationKey(auth.ID), data, redis.SetArgs{
+		Mode:    "XX",
+		KeepTTL: true,
+	}).Err()
+	if errors.Is(err, redis.Nil) {
+		return ErrDeviceAuthorizationNotFound
+	}
+	return err
+}
+
+func (r *deviceAuthorizationRepository) RecordPoll(
+	ctx context.Context,
+	auth *DeviceAuthorization,
+) error {
+	key := deviceAuthorizationKey(auth.ID)
+	err := r.rdb.Watch(ctx, func(tx *redis.Tx) error {
+		data, err := tx.Get(ctx, key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			return ErrDeviceAuthorizationNotFound
+		}
+		if err != nil {
+			return err
+		}
+		var stored DeviceAuthorization
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return err
+		}
+		if stored.Status != DeviceAuthorizationPending {
+			return nil
+		}
+		stored.Interval = auth.Interval
+		stored.LastPolledAt = auth.LastPolledAt
+		updated, err := json.Marshal(stored)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, updated, redis.KeepTTL)
+			return nil
+		})
+		return err
+	}, key)
+	if errors.Is(err, redis.TxFailedErr) {
+		// Changed meanwhile, e.g. decided; the next poll sees the change
+		return nil
+	}
+	return err
+}
+
+func (r *deviceAuthorizationRepository) Delete(
+	ctx context.Context,
+	auth *DeviceAuthorization,
+) error {
+	deleted, err := r.rdb.Del(ctx, deviceAuthorizationKey(auth.ID)).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrDeviceAuthorizationNotFound
+	}
+	return r.rdb.Del(ctx, deviceUserCodeKey(auth.UserCode)).Err()
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrEmailChangeNotFound = errors.New("email change not found")
+
+const (
+	EmailChangeSideOld = "old"
+	EmailChangeSideNew = "new"
+)
+
+// EmailChange is a pending email change that both the old and the new address must confirm.
+type EmailChange struct {
+	ID           string `json:"id"`
+	UserID       string `json:"user_id"`
+	OldEmail     string `json:"old_email"`
+	NewEmail     string `json:"new_email"`
+	OldConfirmed bool   `json:"old_confirmed"`
+	NewConfirmed bool   `json:"new_confirmed"`
+}
+
+// EmailChangeTokens are the single-use tokens mailed out for an email change.
+type EmailChangeTokens struct {
+	// OldToken confirms the change from the old address
+	OldToken string
+	// NewToken confirms the change from the new address
+	NewToken string
+	// RollbackToken lets the old address undo the change, even after it was applied
+	RollbackToken string
+}
+
+// EmailChangeRepository stores pending email changes in Redis. Only token
+// hashes are stored so a leaked Redis dump can't be used to confirm changes.
+type EmailChangeRepository interface {
+	Create(
+		ctx context.Context,
+		change *EmailChange,
+		ttl, rollbackTTL time.Duration,
+	) (EmailChangeTokens, error)
+	Confirm(ctx context.Context, token string) (*EmailChange, error)
+	Delete(ctx context.Context, change *EmailChange) error
+	ConsumeRollback(ctx context.Context, token string) (*EmailChange, error)
+}
+
+type emailChangeRepository struct {
+	rdb redis.UniversalClient
+}
+
+func NewEmailChangeRepository(rdb redis.UniversalClient) EmailChangeRepository {
+	return &emailChangeRepository{rdb: rdb}
+}
+
+func emailChangeKey(id string) string {
+	return fmt.Sprintf("email_change:%s", id)
+}
+
+func emailChangeTokenKey(token string) string {
+	return fmt.Sprintf("email_change_token:%s", utils.HashToken(token))
+}
+
+func emailRollbackKey(token string) string {
+	return fmt.Sprintf("email_rollback:%s", utils.HashToken(token))
+}
+
+func userEmailChangeKey(userID string) string {
+	return fmt.Sprintf("user_email_change:%s", userID)
+}
+
+// Create stores a new pending change, replacing any pending change of the same user.
+func (r *emailChangeRepository) Create(
+	ctx context.Context,
+	change *EmailChange,
+	ttl, rollbackTTL time.Duration,
+) (EmailChangeTokens, error) {
+	var tokens EmailChangeTokens
+	var err error
+	if change.ID, err = utils.GenerateToken(16); err != nil {
+		return tokens, err
+	}
+	for _, token := range []*string{&tokens.OldToken, &tokens.NewToken, &tokens.RollbackToken} {
+		if *token, err = utils.GenerateToken(32); err != nil {
+			return tokens, err
+		}
+	}
+
+	// Only one change per user may be pending at a time
+	if previousID, err := r.rdb.Get(ctx, userEmailChangeKey(change.UserID)).Result(); err == nil {
+		if err := r.rdb.Del(ctx, emailChangeKey(previousID)).Err(); err != nil {
+			return tokens, err
+		}
+	}
+
+	data, err := json.Marshal(change)
+	if err != nil {
+		return tokens, err
+	}
+	pipe := r.rdb.TxPipeline()
+	pipe.Set(ctx, emailChangeKey(change.ID), data, ttl)
+	pipe.Set(ctx, userEmailChangeKey(change.UserID), change.ID, ttl)
+	pipe.Set(ctx, emailChangeTokenKey(tokens.OldToken), change.ID+":"+EmailChangeSideOld, ttl)
+	pipe.Set(ctx, emailChangeTokenKey(tokens.NewToken), change.ID+":"+EmailChangeSideNew, ttl)
+	pipe.Set(ctx, emailRollbackKey(tokens.RollbackToken), data, rollbackTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return tokens, err
+	}
+	return tokens, nil
+}
+
+// Confirm consumes a confirmation token and returns the change with the
+// corresponding side marked as confirmed.
+func (r *emailChangeRepository) Confirm(ctx context.Context, token string) (*EmailChange, error) {
+	ref, err := r.rdb.GetDel(ctx, emailChangeTokenKey(token)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrEmailChangeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	id, side, ok := strings.Cut(ref, ":")
+	if !ok {
+		return nil, ErrEmailChangeNotFound
+	}
+
+	var change EmailChange
+	err = r.rdb.Watch(ctx, func(tx *redis.Tx) error {
+		data, err := tx.Get(ctx, emailChangeKey(id)).Bytes()
+		if errors.Is(err, redis.Nil) {
+			return ErrEmailChangeNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &change); err != nil {
+			return err
+		}
+		switch side {
+		case EmailChangeSideOld:
+			change.OldConfirmed = true
+		case EmailChangeSideNew:
+			change.NewConfirmed = true
+		}
+		updated, err := json.Marshal(change)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, emailChangeKey(id), updated, redis.KeepTTL)
+			return nil
+		})
+		return err
+	}, emailChangeKey(id))
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+func (r *emailChangeRepository) Delete(ctx context.Context, change *EmailChange) error {
+	pipe := r.rdb.TxPipeline()
+	pipe.Del(ctx, emailChangeKey(change.ID))
+	pipe.Del(ctx, userEmailChangeKey(change.UserID))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ConsumeRollback consumes a rollback token and returns the change it belongs to.
+func (r *emailChangeRepository) ConsumeRollback(
+	ctx context.Context,
+	token string,
+) (*EmailChange, error) {
+	data, err := r.rdb.GetDel(ctx, emailRollbackKey(token)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrEmailChangeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var change EmailChange
+	if err := json.Unmarshal(data, &change); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrHandoffTokenNotFound = errors.New("handoff token not found")
+
+// HandoffToken passes the login of the web app on to a native app.
+type HandoffToken struct {
+	UserID string `json:"user_id"`
+	// SessionRef references the web session the token was created with
+	SessionRef    string    `json:"session_ref,omitempty"`
+	ClientName    string    `json:"client_name,omitempty"`
+	CodeChallenge string    `json:"code_challenge,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// HandoffTokenRepository stores handoff tokens in Redis by the hash of the token.
+type HandoffTokenRepository interface {
+	// Create stores a handoff token and returns the token
+	Create(ctx context.Context, handoff *HandoffToken, ttl time.Duration) (string, error)
+	// Consume removes a handoff token and returns it, so each token is redeemed once
+	Consume(ctx context.Context, token string) (*HandoffToken, error)
+}
+
+type handoffTokenRepository struct {
+	rdb redis.UniversalClient
+}
+
+func NewHandoffTokenRepository(rdb redis.UniversalClient) HandoffTokenRepository {
+	return &handoffTokenRepository{rdb: rdb}
+}
+
+func handoffTokenKey(token string) string {
+	return fmt.Sprintf("handoff_token:%s", utils.HashToken(token))
+}
+
+func (r *handoffTokenRepository) Create(
+	ctx context.Context,
+	handoff *HandoffToken,
+	ttl time.Duration,
+) (string, error) {
+	token, err := utils.GenerateToken(32)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(handoff)
+	if err != nil {
+		return "", err
+	}
+	if err := r.rdb.Set(ctx, handoffTokenKey(token), data, ttl).Err(); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (r *handoffTokenRepository) Consume(ctx context.Context, token string) (*HandoffToken, error) {
+	data, err := r.rdb.GetDel(ctx, handoffTokenKey(token)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrHandoffTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var handoff HandoffToken
+	if err := json.Unmarshal(data, &handoff); err != nil {
+		return nil, err
+	}
+	return &handoff, nil
+}
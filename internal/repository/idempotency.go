@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// maxBeginAttempts bounds how often Begin tries to claim a key that keeps
+// being freed between its attempts.
+const maxBeginAttempts = 3
+
+// ErrIdempotencyKeyContended is returned by Begin when the key kept being
+// claimed and freed by other requests.
+var ErrIdempotencyKeyContended = errors.New("idempotency key is contended")
+
+// IdempotentRequest is the first request sent with an idempotency key.
+type IdempotentRequest struct {
+	// Fingerprint identifies the request, so the key isn't answered to
+	// another one
+	Fingerprint string `json:"fingerprint"`
+	// Done is set with the Response once the request succeeded. The
+	// Response, e.g. a session, is stored encrypted under the key, which
+	// Redis only holds hashed, so what is stored can't be replayed without it
+	Done     bool   `json:"done,omitempty"`
+	Response []byte `json:"response,omitempty"`
+}
+
+// IdempotencyRepository stores the responses of requests sent with an
+// idempotency key in Redis by the hash of the key.
+type IdempotencyRepository interface {
+	// Begin records the request of key as in progress for ttl. It returns
+	// nil if the key was free, the request recorded first otherwise, and
+	// ErrIdempotencyKeyContended if neither held over a few attempts.
+	Begin(
+		ctx context.Context,
+		scope, key, fingerprint string,
+		ttl time.Duration,
+	) (*IdempotentRequest, error)
+	// Complete stores the response of the request begun with key for ttl
+	Complete(
+		ctx context.Context,
+		scope, key string,
+		request *IdempotentRequest,
+		ttl time.Duration,
+	) error
+	// Abandon frees key, e.g. after the request failed, so it can be sent again
+	Abandon(ctx context.Context, scope, key string) error
+}
+
+type idempotencyRepository struct {
+	rdb redis.UniversalClient
+}
+
+func NewIdempotencyRepository(rdb redis.UniversalClient) IdempotencyRepository {
+	return &idempotencyRepository{rdb: rdb}
+}
+
+func idempotencyKey(scope, key string) string {
+	return fmt.Sprintf("idempotency:%s:%s", scope, utils.HashToken(key))
+}
+
+// responseKey is the key the response of key is encrypted under. It must
+// differ from key: EncryptSecret derives its cipher key by hashing, and the
+// hash of key is part of the Redis key.
+func responseKey(scope, key string) string {
+	return "idempotency-response:" + scope + ":" + key
+}
+
+func (r *idempotencyRepository) Begin(
+	ctx context.Context,
+	scope, key, fingerprint string,
+	ttl time.Duration,
+) (*IdempotentRequest, error) {
+	data, err := json.Marshal(&IdempotentRequest{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, err
+	}
+	redisKey := idempotencyKey(scope, key)
+	for range maxBeginAttempts {
+		free, err := r.rdb.SetNX(ctx, redisKey, data, ttl).Result()
+		if err != nil || free {
+			return nil, err
+		}
+		stored, err := r.rdb.Get(ctx, redisKey).Bytes()
+		if errors.Is(err, redis.Nil) {
+			// Abandoned or expired in the meantime; try again
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var first IdempotentRequest
+		if err := json.Unmarshal(stored, &first); err != nil {
+			return nil, err
+		}
+		if len(first.Response) > 0 {
+			response, err := utils.DecryptSecret(responseKey(scope, key), string(first.Response))
+			if err != nil {
+				return nil, fmt.Errorf("decrypt idempotent response: %w", err)
+			}
+			first.Response = []byte(response)
+		}
+		return &first, nil
+	}
+	return nil, ErrIdempotencyKeyContended
+}
+
+func (r *idempotencyRepository) Complete(
+	ctx context.Context,
+	scope, key string,
+	request *IdempotentRequest,
+	ttl time.Duration,
+) error {
+	stored := *request
+	if len(request.Response) > 0 {
+		response, err := utils.EncryptSecret(responseKey(scope, key), string(request.Response))
+		if err != nil {
+			return err
+		}
+		stored.Response = []byte(response)
+	}
+	data, err := json.Marshal(&stored)
+	if err != nil {
+		return err
+	}
+	return r.rdb.Set(ctx, idempotencyKey(scope, key), data, ttl).Err()
+}
+
+func (r *idempotencyRepository) Abandon(ctx context.Context, scope, key string) error {
+	return r.rdb.Del(ctx, idempotencyKey(scope, key)).Err()
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrInviteNotFound = errors.New("invite not found")
+
+// inviteRetentionBackstop is how long past their retention expired invites
+// are kept at most, should they not be deleted by DeleteExpiredBefore.
+const inviteRetentionBackstop = 24 * time.Hour
+
+// Invite allows an email address to sign up while signups are invite-only.
+type Invite struct {
+	Email     string    `json:"email"`
+	InvitedBy string    `json:"invited_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt is unset for invites stored before it was recorded, which are
+	// deleted when they expire
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+}
+
+// InviteRepository stores signup invites in Redis, keyed by a hash of the
+// normalized email address. Expired invites can't be used but are kept until
+// DeleteExpiredBefore deletes them.
+type InviteRepository interface {
+	Create(ctx context.Context, invite *Invite, ttl time.Duration) error
+	// Get returns the invite of email, ErrInviteNotFound if it has none or it
+	// expired.
+	Get(ctx context.Context, email string) (*Invite, error)
+	Delete(ctx context.Context, email string) error
+	// DeleteExpiredBefore deletes the invites that expired before the given time
+	// and returns how many it deleted.
+	DeleteExpiredBefore(ctx context.Context, before time.Time) (int, error)
+}
+
+type InviteOption func(*inviteRepository)
+
+// WithExpiredInviteRetention keeps expired invites for retention, until
+// DeleteExpiredBefore deletes them.
+func WithExpiredInviteRetention(retention time.Duration) InviteOption {
+	return func(r *inviteRepository) {
+		r.retention = retention
+	}
+}
+
+type inviteRepository struct {
+	rdb       redis.UniversalClient
+	retention time.Duration
+}
+
+func NewInviteRepository(rdb redis.UniversalClient, opts ...InviteOption) InviteRepository {
+	r := &inviteRepository{rdb: rdb}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+const inviteKeyPrefix = "signup_invite:"
+
+func inviteKey(email string) string {
+	return inviteKeyPrefix + utils.HashToken(strings.ToLower(strings.TrimSpace(email)))
+}
+
+// Create stores the invite, replacing a previous invite of the same address.
+// The invite expires after ttl.
+func (r *inviteRepository) Create(ctx context.Context, invite *Invite, ttl time.Duration) error {
+	invite.ExpiresAt = time.Now().Add(ttl)
+	data, err := json.Marshal(invite)
+	if err != nil {
+		return err
+	}
+	keep := ttl
+	if r.retention > 0 {
+		keep += r.retention + inviteRetentionBackstop
+	}
+	return r.rdb.Set(ctx, inviteKey(invite.Email), data, keep).Err()
+}
+
+func (r *inviteRepository) Get(ctx context.Context, email string) (*Invite, error) {
+	data, err := r.rdb.Get(ctx, inviteKey(email)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrInviteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var invite Invite
+	if err := json.Unmarshal(data, &invite); err != nil {
+		return nil, err
+	}
+	if !invite.ExpiresAt.IsZero() && !time.Now().Before(invite.ExpiresAt) {
+		return nil, ErrInviteNotFound
+	}
+	return &invite, nil
+}
+
+func (r *inviteRepository) Delete(ctx context.Context, email string) error {
+	return r.rdb.Del(ctx, inviteKey(email)).Err()
+}
+
+func (r *inviteRepository) DeleteExpiredBefore(ctx context.Context, before time.Time) (int, error) {
+	deleted := 0
+	err := scanKeys(ctx, r.rdb, inviteKeyPrefix+"*", func(keys []string) error {
+		for _, key := range keys {
+			data, err := r.rdb.Get(ctx, key).Bytes()
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			var invite Invite
+			if err := json.Unmarshal(data, &invite); err != nil {
+				return err
+			}
+			if invite.ExpiresAt.IsZero() || !invite.ExpiresAt.Before(before) {
+				continue
+			}
+			n, err := r.rdb.Del(ctx, key).Result()
+			if err != nil {
+				return err
+			}
+			deleted += int(n)
+		}
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,38 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+)
+
+func TestInviteExpiredRetention(t *testing.T) {
+	ctx := context.Background()
+	rdb, mr := testutil.NewRedis(t)
+	inviteRepo := repository.NewInviteRepository(
+		rdb,
+		repository.WithExpiredInviteRetention(time.Hour),
+	)
+	if err := inviteRepo.Create(ctx, &repository.Invite{Email: "a@example.com"},
+		time.Millisecond); err != nil {
+		t.Fatalf("failed to create invite: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := inviteRepo.Get(ctx, "a@example.com"); !errors.Is(err,
+		repository.ErrInviteNotFound) {
+		t.Errorf("expected an expired invite not to be found, got %v", err)
+	}
+	// Kept for the retention window, then dropped by its TTL at the latest
+	if n, _ := inviteRepo.DeleteExpiredBefore(ctx, time.Now().Add(-time.Hour)); n != 0 {
+		t.Errorf("expected the invite to be retained, deleted %d", n)
+	}
+	mr.FastForward(26 * time.Hour)
+	if n, _ := inviteRepo.DeleteExpiredBefore(ctx, time.Now()); n != 0 {
+		t.Errorf("expected the invite to have expired, deleted %d", n)
+	}
+}
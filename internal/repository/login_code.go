@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrLoginCodeNotFound = errors.New("login code not found")
+
+// LoginCodeRepository stores the pending login code of each user in Redis, as
+// a hash with the attempts made at it. A new code replaces the pending one.
+type LoginCodeRepository interface {
+	Create(ctx context.Context, userID, code string, ttl time.Duration) error
+	// Attempt counts an attempt at the user's code and reports whether code
+	// matches it, along with the attempts counted so far
+	Attempt(ctx context.Context, userID, code string) (bool, int, error)
+	// Delete removes the user's code, returning ErrLoginCodeNotFound if it was
+	// already removed, so only one caller redeems it
+	Delete(ctx context.Context, userID string) error
+}
+
+type loginCodeRepository struct {
+	rdb redis.UniversalClient
+}
+
+func NewLoginCodeRepository(rdb redis.UniversalClient) LoginCodeRepository {
+	return &loginCodeRepository{rdb: rdb}
+}
+
+func loginCodeKey(userID string) string {
+	return fmt.Sprintf("login_code:%s", userID)
+}
+
+// loginCodeHash binds the hash to the user, so equal codes of different users
+// don't hash alike.
+func loginCodeHash(userID, code string) string {
+	return utils.HashToken(userID + ":" + code)
+}
+
+func (r *loginCodeRepository) Create(
+	ctx context.Context,
+	userID, code string,
+	ttl time.Duration,
+) error {
+	key := loginCodeKey(userID)
+	pipe := r.rdb.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.HSet(ctx, key, "hash", loginCodeHash(userID, code), "attempts", 0)
+	pipe.Expire(ctx, key, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *loginCodeRepository) Attempt(
+	ctx context.Context,
+	userID, code string,
+) (bool, int, error) {
+	key := loginCodeKey(userID)
+	pipe := r.rdb.TxPipeline()
+	attempts := pipe.HIncrBy(ctx, key, "attempts", 1)
+	hash := pipe.HGet(ctx, key, "hash")
+	_, err := pipe.Exec(ctx)
+	if errors.Is(err, redis.Nil) {
+		// No code is pending; drop the counter HIncrBy created
+		r.rdb.Del(ctx, key)
+		return false, 0, ErrLoginCodeNotFound
+	}
+	if err != nil {
+		return false, 0, err
+	}
+	return hash.Val() == loginCodeHash(userID, code), int(attempts.Val()), nil
+}
+
+func (r *loginCodeRepository) Delete(ctx context.Context, userID string) error {
+	deleted, err := r.rdb.Del(ctx, loginCodeKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrLoginCodeNotFound
+	}
+	return nil
+}
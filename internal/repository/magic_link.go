@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrMagicLinkNotFound = errors.New("magic link not found")
+
+// MagicLink is a login link mailed to a user.
+type MagicLink struct {
+	UserID string `json:"user_id"`
+	// Email is the address the link was sent to; the link is void once the
+	// account's email changed
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MagicLinkRepository stores magic links in Redis by the hash of their token.
+type MagicLinkRepository interface {
+	// Create stores a link and returns its token
+	Create(ctx context.Context, link *MagicLink, ttl time.Duration) (string, error)
+	// Consume removes a link and returns it, so each link logs in once
+	Consume(ctx context.Context, token string) (*MagicLink, error)
+}
+
+type magicLinkRepository struct {
+	rdb redis.UniversalClient
+}
+
+func NewMagicLinkRepository(rdb redis.UniversalClient) MagicLinkRepository {
+	return &magicLinkRepository{rdb: rdb}
+}
+
+func magicLinkKey(token string) string {
+	return fmt.Sprintf("magic_link:%s", utils.HashToken(token))
+}
+
+func (r *magicLinkRepository) Create(
+	ctx context.Context,
+	link *MagicLink,
+	ttl time.Duration,
+) (string, error) {
+	token, err := utils.GenerateToken(32)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(link)
+	if err != nil {
+		return "", err
+	}
+	if err := r.rdb.Set(ctx, magicLinkKey(token), data, ttl).Err(); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (r *magicLinkRepository) Consume(ctx context.Context, token string) (*MagicLink, error) {
+	data, err := r.rdb.GetDel(ctx, magicLinkKey(token)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMagicLinkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var link MagicLink
+	if err := json.Unmarshal(data, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationPreferencesRepository stores the notification preferences of users.
+type NotificationPreferencesRepository interface {
+	// Get returns the preferences of a user, the defaults if they never set any
+	Get(ctx context.Context, userID string) (*model.NotificationPreferencesModel, error)
+	Save(ctx context.Context, prefs *model.NotificationPreferencesModel) error
+	// Delete deletes the preferences of a user, who has the defaults again
+	Delete(ctx context.Context, userID string) error
+}
+
+type notificationPreferencesRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationPreferencesRepository(db *gorm.DB) NotificationPreferencesRepository {
+	return &notificationPreferencesRepository{db: db}
+}
+
+func (r *notificationPreferencesRepository) Get(
+	ctx context.Context,
+	userID string,
+) (*model.NotificationPreferencesModel, error) {
+	var prefs model.NotificationPreferencesModel
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&prefs).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.DefaultNotificationPreferences(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+func (r *notificationPreferencesRepository) Save(
+	ctx context.Context,
+	prefs *model.NotificationPreferencesModel,
+) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"updated_at", "security_emails", "product_emails", "login_alerts",
+		}),
+	}).Create(prefs).Error
+}
+
+func (r *notificationPreferencesRepository) Delete(ctx context.Context, userID string) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Delete(&model.NotificationPreferencesModel{}).Error
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// OAuthState is a pending OAuth login, stored by GetOAuthCodeURL until the
+// provider redirects back to LoginByOAuth. Only the fields needed to inspect
+// states are decoded here; the client binding stays with the auth service.
+type OAuthState struct {
+	// State is the value sent through the provider
+	State       string    `json:"-"`
+	Provider    string    `json:"provider"`
+	RedirectURL string    `json:"redirect_url,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// OAuthStateRepository inspects and purges the pending OAuth states in Redis,
+// e.g. to debug stuck logins or after the credentials of a provider were
+// rotated. States are scanned, so it is meant for admins, not for logins.
+type OAuthStateRepository interface {
+	List(ctx context.Context) ([]OAuthState, error)
+	// Delete removes the states and returns how many still existed
+	Delete(ctx context.Context, states ...string) (int, error)
+}
+
+type oauthStateRepository struct {
+	rdb redis.UniversalClient
+}
+
+func NewOAuthStateRepository(rdb redis.UniversalClient) OAuthStateRepository {
+	return &oauthStateRepository{rdb: rdb}
+}
+
+// OAuthStateKey is the Redis key of an OAuth state.
+func OAuthStateKey(state string) string {
+	return "oauth_state:" + state
+}
+
+func (r *oauthStateRepository) List(ctx context.Context) ([]OAuthState, error) {
+	var states []OAuthState
+	err := scanKeys(ctx, r.rdb, OAuthStateKey("*"), func(keys []string) error {
+		pipe := r.rdb.Pipeline()
+		values := make([]*redis.StringCmd, len(keys))
+		for i, key := range keys {
+			values[i] = pipe.Get(ctx, key)
+		}
+		if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		for i, key := range keys {
+			data, err := values[i].Bytes()
+			if err != nil {
+				// Consumed or expired since it was scanned
+				continue
+			}
+			state := OAuthState{State: strings.TrimPrefix(key, OAuthStateKey(""))}
+			if err := json.Unmarshal(data, &state); err != nil {
+				// Listed anyway, so that it can be purged
+				state.Provider = "unknown"
+			}
+			states = append(states, state)
+		}
+		return nil
+	})
+	return states, err
+}
+
+func (r *oauthStateRepository) Delete(ctx context.Context, states ...string) (int, error) {
+	if len(states) == 0 {
+		return 0, nil
+	}
+	keys := make([]string, len(states))
+	for i, state := range states {
+		keys[i] = OAuthStateKey(state)
+	}
+	// Keys are deleted one by one, as they may live on different cluster nodes
+	pipe := r.rdb.Pipeline()
+	deleted := make([]*redis.IntCmd, len(keys))
+	for i, key := range keys {
+		deleted[i] = pipe.Del(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, cmd := range deleted {
+		n += int(cmd.Val())
+	}
+	return n, nil
+}
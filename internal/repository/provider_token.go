@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/fieldcrypt"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrProviderTokenNotFound = errors.New("provider token not found")
+
+// ProviderToken is a decrypted provider token.
+type ProviderToken struct {
+	UserID       string
+	Provider     string
+	AccessToken  string
+	RefreshToken string
+	TokenType    string
+	Scopes       []string
+	// ExpiresAt is zero for tokens that don't expire
+	ExpiresAt time.Time
+}
+
+// ProviderTokenRepository stores the provider tokens of users, encrypting them
+// at rest. Each user keeps the latest token of every provider.
+type ProviderTokenRepository interface {
+	Save(ctx context.Context, token *ProviderToken) error
+	Get(ctx context.Context, userID, provider string) (*ProviderToken, error)
+	Delete(ctx context.Context, userID, provider string) error
+	// DeleteIfRefreshToken deletes the token unless its refresh token is no
+	// longer refreshToken, e.g. as a concurrent refresh replaced it, and
+	// reports whether it did.
+	DeleteIfRefreshToken(ctx context.Context, userID, provider, refreshToken string) (bool, error)
+	// DeleteByUserID deletes the tokens of all providers of the user
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+// The columns of provider tokens encrypted by the installed keyring, or by the
+// provider token key without one.
+const (
+	providerAccessTokenColumn  = "provider_tokens.access_token"
+	providerRefreshTokenColumn = "provider_tokens.refresh_token"
+)
+
+type providerTokenRepository struct {
+	db  *gorm.DB
+	key string
+}
+
+func NewProviderTokenRepository(db *gorm.DB, key string) ProviderTokenRepository {
+	return &providerTokenRepository{db: db, key: key}
+}
+
+func (r *providerTokenRepository) encrypt(token, column string) (string, error) {
+	if token == "" {
+		return "", nil
+	}
+	if keyring := fieldcrypt.Installed(); keyring != nil {
+		return keyring.Encrypt(token, column)
+	}
+	return utils.EncryptSecret(r.key, token)
+}
+
+func (r *providerTokenRepository) decrypt(encrypted, column string) (string, error) {
+	return decryptProviderToken(fieldcrypt.Installed(), r.key, encrypted, column)
+}
+
+// decryptProviderToken decrypts a provider token encrypted by keyring, or by
+// key for tokens stored before the keyring was configured.
+func decryptProviderToken(
+	keyring *fieldcrypt.Keyring,
+	key, encrypted, column string,
+) (string, error) {
+	if encrypted == "" {
+		return "", nil
+	}
+	if fieldcrypt.IsEncrypted(encrypted) {
+		return keyring.Decrypt(encrypted, column)
+	}
+	if key == "" {
+		return "", errors.New("provider token was encrypted with auth.provider_token_key")
+	}
+	return utils.DecryptSecret(key, encrypted)
+}
+
+func (r *providerTokenRepository) Save(ctx context.Context, token *ProviderToken) error {
+	accessToken, err := r.encrypt(token.AccessToken, providerAccessTokenColumn)
+	if err != nil {
+		return err
+	}
+	refreshToken, err := r.encrypt(token.RefreshToken, providerRefreshTokenColumn)
+	if err != nil {
+		return err
+	}
+	row := &model.ProviderTokenModel{
+		UserID:       token.UserID,
+		Provider:     token.Provider,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    token.TokenType,
+		Scopes:       strings.Join(token.Scopes, " "),
+	}
+	if !token.ExpiresAt.IsZero() {
+		row.ExpiresAt = &token.ExpiresAt
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "provider"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"updated_at", "access_token", "refresh_token", "token_type", "scopes", "expires_at",
+		}),
+	}).Create(row).Error
+}
+
+func (r *providerTokenRepository) Get(
+	ctx context.Context,
+	userID, provider string,
+) (*ProviderToken, error) {
+	var row model.ProviderTokenModel
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND provider = ?", userID, provider).
+		First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrProviderTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	token := &ProviderToken{
+		UserID:    row.UserID,
+		Provider:  row.Provider,
+		TokenType: row.TokenType,
+		Scopes:    strings.Fields(row.Scopes),
+	}
+	token.AccessToken, err = r.decrypt(row.AccessToken, providerAccessTokenColumn)
+	if err != nil {
+		return nil, err
+	}
+	token.RefreshToken, err = r.decrypt(row.RefreshToken, providerRefreshTokenColumn)
+	if err != nil {
+		return nil, err
+	}
+	if row.ExpiresAt != nil {
+		token.ExpiresAt = *row.ExpiresAt
+	}
+	return token, nil
+}
+
+func (r *providerTokenRepository) Delete(ctx context.Context, userID, provider string) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND provider = ?", userID, provider).
+		Delete(&model.ProviderTokenModel{}).Error
+}
+
+func (r *providerTokenRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Delete(&model.ProviderTokenModel{}).Error
+}
+
+func (r *providerTokenRepository) DeleteIfRefreshToken(
+	ctx context.Context,
+	userID, provider, refreshToken string,
+) (bool, error) {
+	deleted := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var row model.ProviderTokenModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND provider = ?", userID, provider).
+			First(&row).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// Tokens are encrypted with a random nonce, so they are compared decrypted
+		stored, err := r.decrypt(row.RefreshToken, providerRefreshTokenColumn)
+		if err != nil || stored != refreshToken {
+			return err
+		}
+		deleted = true
+		return tx.Delete(&row).Error
+	})
+	return deleted, err
+}
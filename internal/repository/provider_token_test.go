@@ -0,0 +1,52 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/go-webmods/gorm_client"
+)
+
+func TestProviderTokenDeleteIfRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	db := gorm_client.NewDB(gorm_client.Config{
+		Driver: "sqlite",
+		Name:   filepath.Join(t.TempDir(), "tokens.db"),
+	})
+	if err := db.AutoMigrate(&model.ProviderTokenModel{}); err != nil {
+		t.Fatal(err)
+	}
+	tokens := repository.NewProviderTokenRepository(db, "key")
+	if err := tokens.Save(ctx, &repository.ProviderToken{
+		UserID:       "user-1",
+		Provider:     "github",
+		AccessToken:  "access",
+		RefreshToken: "rotated",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := tokens.DeleteIfRefreshToken(ctx, "user-1", "github", "previous")
+	if err != nil || deleted {
+		t.Fatalf("expected a replaced token to be kept, got %v (%v)", deleted, err)
+	}
+	if _, err := tokens.Get(ctx, "user-1", "github"); err != nil {
+		t.Fatalf("expected the token to be kept, got %v", err)
+	}
+	deleted, err = tokens.DeleteIfRefreshToken(ctx, "user-1", "github", "rotated")
+	if err != nil || !deleted {
+		t.Fatalf("expected the token to be deleted, got %v (%v)", deleted, err)
+	}
+	_, err = tokens.Get(ctx, "user-1", "github")
+	if !errors.Is(err, repository.ErrProviderTokenNotFound) {
+		t.Errorf("expected the token to be gone, got %v", err)
+	}
+	deleted, err = tokens.DeleteIfRefreshToken(ctx, "user-1", "github", "rotated")
+	if err != nil || deleted {
+		t.Errorf("expected nothing to delete, got %v (%v)", deleted, err)
+	}
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/poly-workshop/auth-portal/internal/fieldcrypt"
+	"gorm.io/gorm"
+)
+
+// ReencryptionRepository rewrites the values of encrypted columns that aren't
+// stored as configured: encrypted with another key than the primary one, or
+// stored before their column was encrypted, or encrypted after email
+// encryption was turned off. It also fills in the blind index of email
+// addresses stored before it was configured.
+type ReencryptionRepository interface {
+	// CountStale counts the rows with values to rewrite.
+	CountStale(ctx context.Context) (int64, error)
+	// Reencrypt rewrites up to limit stale rows of each table and returns how
+	// many it rewrote.
+	Reencrypt(ctx context.Context, limit int) (int, error)
+}
+
+type reencryptionRepository struct {
+	db      *gorm.DB
+	keyring *fieldcrypt.Keyring
+	// providerTokenKey decrypts the provider tokens stored before the keyring
+	// was configured
+	providerTokenKey string
+}
+
+func NewReencryptionRepository(
+	db *gorm.DB,
+	keyring *fieldcrypt.Keyring,
+	providerTokenKey string,
+) ReencryptionRepository {
+	return &reencryptionRepository{db: db, keyring: keyring, providerTokenKey: providerTokenKey}
+}
+
+// The rows are read and written through the tables rather than the models, so
+// values are seen as stored and rewriting them isn't an update of the user.
+type (
+	staleUser struct {
+		ID    string
+		Email string
+	}
+	staleProviderToken struct {
+		UserID       string
+		Provider     string
+		AccessToken  string
+		RefreshToken string
+	}
+)
+
+func (r *reencryptionRepository) staleUsers(ctx context.Context) *gorm.DB {
+	db := r.db.WithContext(ctx).Table("users")
+	if r.keyring.Encrypts(fieldcrypt.EmailColumn) {
+		db = db.Where("email <> '' AND email NOT LIKE ?", r.keyring.PrimaryPrefix()+"%")
+	} else {
+		db = db.Where("email LIKE ?", fieldcrypt.Prefix+"%")
+	}
+	if r.keyring.IndexesEmail() {
+		return db.Or("email_index IS NULL")
+	}
+	return db.Or("email_index IS NOT NULL")
+}
+
+func (r *reencryptionRepository) staleProviderTokens(ctx context.Context) *gorm.DB {
+	pattern := r.keyring.PrimaryPrefix() + "%"
+	return r.db.WithContext(ctx).Table("provider_tokens").
+		Where("access_token <> '' AND access_token NOT LIKE ?", pattern).
+		Or("COALESCE(refresh_token, '') <> '' AND refresh_token NOT LIKE ?", pattern)
+}
+
+func (r *reencryptionRepository) CountStale(ctx context.Context) (int64, error) {
+	var users, tokens int64
+	if err := r.staleUsers(ctx).Count(&users).Error; err != nil {
+		return 0, err
+	}
+	if err := r.staleProviderTokens(ctx).Count(&tokens).Error; err != nil {
+		return 0, err
+	}
+	return users + tokens, nil
+}
+
+func (r *reencryptionRepository) Reencrypt(ctx context.Context, limit int) (int, error) {
+	users, err := r.reencryptUsers(ctx, limit)
+	if err != nil {
+		return users, err
+	}
+	tokens, err := r.reencryptProviderTokens(ctx, limit)
+	return users + tokens, err
+}
+
+func (r *reencryptionRepository) reencryptUsers(ctx context.Context, limit int) (int, error) {
+	var rows []staleUser
+	err := r.staleUsers(ctx).Select("id", "email").Order("id").Limit(limit).Find(&rows).Error
+	if err != nil {
+		return 0, err
+	}
+	rewritten := 0
+	for _, row := range rows {
+		email, err := r.keyring.Decrypt(row.Email, fieldcrypt.EmailColumn)
+		if err != nil {
+			return rewritten, fmt.Errorf("failed to decrypt the email of user %s: %w", row.ID, err)
+		}
+		stored := email
+		if r.keyring.Encrypts(fieldcrypt.EmailColumn) {
+			if stored, err = r.keyring.Encrypt(email, fieldcrypt.EmailColumn); err != nil {
+				return rewritten, err
+			}
+		}
+		var index *string
+		if r.keyring.IndexesEmail() {
+			blind := r.keyring.BlindIndex(email)
+			index = &blind
+		}
+		// Users whose email changed in the meantime are left to the next run
+		result := r.db.WithContext(ctx).Table("users").
+			Where("id = ? AND email = ?", row.ID, row.Email).
+			Updates(map[string]any{"email": stored, "email_index": index})
+		if result.Error != nil {
+			return rewritten, result.Error
+		}
+		rewritten += int(result.RowsAffected)
+	}
+	return rewritten, nil
+}
+
+func (r *reencryptionRepository) reencryptProviderTokens(
+	ctx context.Context,
+	limit int,
+) (int, error) {
+	var rows []staleProviderToken
+	err := r.staleProviderTokens(ctx).
+		Select("user_id, provider, access_token, refresh_token").
+		Order("user_id").
+		Order("provider").
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		return 0, err
+	}
+	rewritten := 0
+	for _, row := range rows {
+		accessToken, err := r.reencryptProviderToken(row.AccessToken, providerAccessTokenColumn)
+		if err != nil {
+			return rewritten, fmt.Errorf(
+				"failed to reencrypt the %s token of user %s: %w", row.Provider, row.UserID, err,
+			)
+		}
+		refreshToken, err := r.reencryptProviderToken(row.RefreshToken, providerRefreshTokenColumn)
+		if err != nil {
+			return rewritten, fmt.Errorf(
+				"failed to reencrypt the %s token of user %s: %w", row.Provider, row.UserID, err,
+			)
+		}
+		// Tokens saved at a login in the meantime are encrypted already
+		result := r.db.WithContext(ctx).Table("provider_tokens").
+			Where("user_id = ? AND provider = ? AND access_token = ?",
+				row.UserID, row.Provider, row.AccessToken).
+			Updates(map[string]any{"access_token": accessToken, "refresh_token": refreshToken})
+		if result.Error != nil {
+			return rewritten, result.Error
+		}
+		rewritten += int(result.RowsAffected)
+	}
+	return rewritten, nil
+}
+
+func (r *reencryptionRepository) reencryptProviderToken(encrypted, column string) (string, error) {
+	token, err := decryptProviderToken(r.keyring, r.providerTokenKey, encrypted, column)
+	if err != nil {
+		return "", err
+	}
+	return r.keyring.Encrypt(token, column)
+}
@@ -0,0 +1,130 @@
+package repository_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/fieldcrypt"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/go-webmods/gorm_client"
+	"gorm.io/gorm"
+)
+
+func newKeyring(t *testing.T, ids ...string) *fieldcrypt.Keyring {
+	t.Helper()
+	cfg := configs.EncryptionConfig{IndexKey: "index", EncryptEmail: true}
+	for _, id := range ids {
+		key := bytes.Repeat([]byte(id[len(id)-1:]), 32)
+		cfg.Keys = append(cfg.Keys, id+":"+base64.StdEncoding.EncodeToString(key))
+	}
+	keyring, err := fieldcrypt.NewKeyring(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func storedEmail(t *testing.T, db *gorm.DB, id string) string {
+	t.Helper()
+	var email string
+	if err := db.Table("users").Select("email").Where("id = ?", id).Scan(&email).Error; err != nil {
+		t.Fatal(err)
+	}
+	return email
+}
+
+func TestReencrypt(t *testing.T) {
+	ctx := context.Background()
+	db := gorm_client.NewDB(gorm_client.Config{
+		Driver: "sqlite",
+		Name:   filepath.Join(t.TempDir(), "users.db"),
+	})
+	if err := db.AutoMigrate(&model.UserModel{}, &model.ProviderTokenModel{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { fieldcrypt.Install(nil) })
+	users := repository.NewUserRepository(db)
+	tokens := repository.NewProviderTokenRepository(db, "legacy-key")
+
+	// Stored before encryption was configured
+	alice := &model.UserModel{Name: "Alice", Email: "alice@example.com"}
+	if err := users.Create(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+	token := &repository.ProviderToken{
+		UserID:       alice.ID,
+		Provider:     "github",
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+	}
+	if err := tokens.Save(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+
+	k1 := newKeyring(t, "k1")
+	fieldcrypt.Install(k1)
+	reencryption := repository.NewReencryptionRepository(db, k1, "legacy-key")
+	if stale, err := reencryption.CountStale(ctx); err != nil || stale != 2 {
+		t.Fatalf("expected the user and the token to be stale, got %d, %v", stale, err)
+	}
+	if user, err := users.GetByEmail(ctx, "alice@example.com"); err != nil || user.ID != alice.ID {
+		t.Fatalf("expected plaintext addresses to be found, got %v", err)
+	}
+	if n, err := reencryption.Reencrypt(ctx, 10); err != nil || n != 2 {
+		t.Fatalf("expected 2 rows rewritten, got %d, %v", n, err)
+	}
+	if email := storedEmail(t, db, alice.ID); !strings.HasPrefix(email, "enc:v1:k1:") {
+		t.Errorf("expected the email to be encrypted, got %q", email)
+	}
+
+	bob := &model.UserModel{Name: "Bob", Email: "bob@example.com"}
+	if err := users.Create(ctx, bob); err != nil {
+		t.Fatal(err)
+	}
+	if email := storedEmail(t, db, bob.ID); !strings.HasPrefix(email, "enc:v1:k1:") {
+		t.Errorf("expected new addresses to be encrypted, got %q", email)
+	}
+	bob.Name = "Robert"
+	if err := users.Update(ctx, bob); err != nil {
+		t.Fatal(err)
+	}
+	if email := storedEmail(t, db, bob.ID); !strings.HasPrefix(email, "enc:v1:k1:") {
+		t.Errorf("expected updated addresses to stay encrypted, got %q", email)
+	}
+	found, err := users.Search(ctx, repository.UserFilter{}, "bob@example.com", 10)
+	if err != nil || len(found) != 1 || found[0].Email != "bob@example.com" {
+		t.Errorf("expected to find bob by the exact address, got %v, %v", found, err)
+	}
+
+	// Rotate k1 out
+	k2 := newKeyring(t, "k2", "k1")
+	fieldcrypt.Install(k2)
+	reencryption = repository.NewReencryptionRepository(db, k2, "legacy-key")
+	if n, err := reencryption.Reencrypt(ctx, 1); err != nil || n != 2 {
+		t.Fatalf("expected a batch of a user and a token, got %d, %v", n, err)
+	}
+	if n, err := reencryption.Reencrypt(ctx, 10); err != nil || n != 1 {
+		t.Fatalf("expected the remaining user, got %d, %v", n, err)
+	}
+	if stale, err := reencryption.CountStale(ctx); err != nil || stale != 0 {
+		t.Fatalf("expected nothing left, got %d, %v", stale, err)
+	}
+
+	fieldcrypt.Install(newKeyring(t, "k2"))
+	for _, user := range []*model.UserModel{alice, bob} {
+		got, err := users.GetByEmail(ctx, user.Email)
+		if err != nil || got.ID != user.ID || got.Email != user.Email {
+			t.Errorf("expected %s to be found without k1, got %v", user.Email, err)
+		}
+	}
+	got, err := tokens.Get(ctx, alice.ID, "github")
+	if err != nil || got.AccessToken != "access" || got.RefreshToken != "refresh" {
+		t.Errorf("expected the token to decrypt without k1, got %+v, %v", got, err)
+	}
+}
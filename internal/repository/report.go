@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"gorm.io/gorm"
+)
+
+// ReportRepository stores the reports requested by admins.
+type ReportRepository interface {
+	Create(ctx context.Context, report *model.ReportModel) error
+	GetByID(ctx context.Context, id string) (*model.ReportModel, error)
+	// List returns the latest reports, newest first.
+	List(ctx context.Context, limit int) ([]*model.ReportModel, error)
+	// ClaimPending marks the oldest pending report as running and returns it,
+	// nil if there is none. Reports running for longer than the claim lease
+	// are claimed again. Servers never claim the same report at once.
+	ClaimPending(ctx context.Context) (*model.ReportModel, error)
+	Update(ctx context.Context, report *model.ReportModel) error
+}
+
+// ReportOption configures a report repository.
+type ReportOption func(*reportRepository)
+
+// WithReportClaimLease lets ClaimPending take over reports claimed longer than
+// lease ago that are still running, as the server generating them presumably
+// died.
+func WithReportClaimLease(lease time.Duration) ReportOption {
+	return func(r *reportRepository) {
+		r.lease = lease
+	}
+}
+
+type reportRepository struct {
+	db    *gorm.DB
+	lease time.Duration
+}
+
+func NewReportRepository(db *gorm.DB, opts ...ReportOption) ReportRepository {
+	r := &reportRepository{db: db}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *reportRepository) Create(ctx context.Context, report *model.ReportModel) error {
+	return r.db.WithContext(ctx).Create(report).Error
+}
+
+func (r *reportRepository) GetByID(ctx context.Context, id string) (*model.ReportModel, error) {
+	var report model.ReportModel
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&report).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *reportRepository) List(ctx context.Context, limit int) ([]*model.ReportModel, error) {
+	var reports []*model.ReportModel
+	err := r.db.WithContext(ctx).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&reports).Error
+	return reports, err
+}
+
+func (r *reportRepository) ClaimPending(ctx context.Context) (*model.ReportModel, error) {
+	for {
+		now := time.Now()
+		query := r.db.WithContext(ctx).Where("status = ?", model.ReportStatusPending)
+		if r.lease > 0 {
+			query = query.Or(
+				"status = ? AND claimed_at < ?",
+				model.ReportStatusRunning,
+				now.Add(-r.lease),
+			)
+		}
+		var report model.ReportModel
+		err := query.Order("created_at").First(&report).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Only the server whose update matches the attempts read gets the report
+		result := r.db.WithContext(ctx).
+			Model(&model.ReportModel{}).
+			Where(
+				"id = ? AND status = ? AND attempts = ?",
+				report.ID,
+				report.Status,
+				report.Attempts,
+			).
+			Updates(map[string]any{
+				"status":     model.ReportStatusRunning,
+				"claimed_at": now,
+				"attempts":   report.Attempts + 1,
+			})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			report.Status = model.ReportStatusRunning
+			report.ClaimedAt = &now
+			report.Attempts++
+			return &report, nil
+		}
+	}
+}
+
+func (r *reportRepository) Update(ctx context.Context, report *model.ReportModel) error {
+	return r.db.WithContext(ctx).Save(report).Error
+}
@@ -0,0 +1,58 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/go-webmods/gorm_client"
+)
+
+func TestClaimPendingReport(t *testing.T) {
+	ctx := context.Background()
+	db := gorm_client.NewDB(gorm_client.Config{
+		Driver: "sqlite",
+		Name:   filepath.Join(t.TempDir(), "reports.db"),
+	})
+	if err := db.AutoMigrate(&model.ReportModel{}); err != nil {
+		t.Fatal(err)
+	}
+	reports := repository.NewReportRepository(db, repository.WithReportClaimLease(time.Hour))
+	pending := &model.ReportModel{
+		Kind:   model.ReportKindUserRoster,
+		Format: model.ReportFormatCSV,
+		Status: model.ReportStatusPending,
+	}
+	if err := reports.Create(ctx, pending); err != nil {
+		t.Fatal(err)
+	}
+
+	claimed, err := reports.ClaimPending(ctx)
+	if err != nil || claimed == nil || claimed.ID != pending.ID || claimed.Attempts != 1 {
+		t.Fatalf("expected the pending report to be claimed, got %+v (%v)", claimed, err)
+	}
+	if again, err := reports.ClaimPending(ctx); again != nil || err != nil {
+		t.Fatalf("expected a running report not to be claimed again, got %+v (%v)", again, err)
+	}
+
+	// The server generating it died and the lease expired
+	err = db.Model(&model.ReportModel{}).
+		Where("id = ?", pending.ID).
+		Update("claimed_at", time.Now().Add(-2*time.Hour)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	reclaimed, err := reports.ClaimPending(ctx)
+	if err != nil || reclaimed == nil || reclaimed.ID != pending.ID {
+		t.Fatalf("expected the stale report to be claimed again, got %+v (%v)", reclaimed, err)
+	}
+	if reclaimed.Attempts != 2 || time.Since(*reclaimed.ClaimedAt) > time.Minute {
+		t.Errorf("expected a fresh second claim, got %+v", reclaimed)
+	}
+	if again, err := reports.ClaimPending(ctx); again != nil || err != nil {
+		t.Errorf("expected the report to be claimed once, got %+v (%v)", again, err)
+	}
+}
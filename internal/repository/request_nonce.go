@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RequestNonceRepository remembers the nonces of signed internal requests, so
+// a request can't be replayed.
+type RequestNonceRepository interface {
+	Claim(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+type requestNonceRepository struct {
+	rdb redis.UniversalClient
+}
+
+func NewRequestNonceRepository(rdb redis.UniversalClient) RequestNonceRepository {
+	return &requestNonceRepository{rdb: rdb}
+}
+
+func requestNonceKey(nonce string) string {
+	return fmt.Sprintf("request_nonce:%s", nonce)
+}
+
+// Claim records nonce for ttl and reports whether it was not recorded yet.
+func (r *requestNonceRepository) Claim(
+	ctx context.Context,
+	nonce string,
+	ttl time.Duration,
+) (bool, error) {
+	return r.rdb.SetNX(ctx, requestNonceKey(nonce), 1, ttl).Result()
+}
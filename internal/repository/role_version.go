@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RoleVersionRepository tracks a per-user counter that is bumped whenever the
+// user's role changes. Tokens carry the version they were issued with, so tokens
+// issued before a role change can be told apart from current ones.
+type RoleVersionRepository interface {
+	Get(ctx context.Context, userID string) (int64, error)
+	Bump(ctx context.Context, userID string) (int64, error)
+}
+
+type roleVersionRepository struct {
+	rdb redis.UniversalClient
+}
+
+func NewRoleVersionRepository(rdb redis.UniversalClient) RoleVersionRepository {
+	return &roleVersionRepository{rdb: rdb}
+}
+
+func roleVersionKey(userID string) string {
+	return fmt.Sprintf("user_role_version:%s", userID)
+}
+
+// Get returns the current role version; users whose role never changed are at version 0.
+func (r *roleVersionRepository) Get(ctx context.Context, userID string) (int64, error) {
+	version, err := r.rdb.Get(ctx, roleVersionKey(userID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return version, err
+}
+
+func (r *roleVersionRepository) Bump(ctx context.Context, userID string) (int64, error) {
+	return r.rdb.Incr(ctx, roleVersionKey(userID)).Result()
+}
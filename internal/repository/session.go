@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	mathrand "math/rand/v2"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"github.com/redis/go-redis/v9"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionRepository stores login sessions in Redis. Besides the session keys
+// themselves it maintains a per-user index so all sessions of a user can be
+// revoked at once.
+type SessionRepository interface {
+	Create(ctx context.Context, userID string, ttl time.Duration) (string, error)
+	GetUserID(ctx context.Context, sessionID string) (string, error)
+	Refresh(ctx context.Context, sessionID, userID string, ttl time.Duration) error
+	TTL(ctx context.Context, sessionID string) (time.Duration, error)
+	Delete(ctx context.Context, sessionID string) error
+	DeleteByUserID(ctx context.Context, userID string) (int, error)
+	DeleteByRef(ctx context.Context, ref string) (string, error)
+	Active(ctx context.Context, ref string) (bool, error)
+	Reconcile(ctx context.Context) (SessionStats, error)
+}
+
+// SessionOption configures a session repository.
+type SessionOption func(*sessionRepository)
+
+// WithTTLJitter shortens the TTL of every session by a random fraction of up to
+// jitter, so sessions created together, e.g. after a deploy or an outage, don't
+// expire together.
+func WithTTLJitter(jitter float64) SessionOption {
+	return func(r *sessionRepository) {
+		r.ttlJitter = min(max(jitter, 0), 1)
+	}
+}
+
+type sessionRepository struct {
+	rdb       redis.UniversalClient
+	ttlJitter float64
+}
+
+func NewSessionRepository(rdb redis.UniversalClient, opts ...SessionOption) SessionRepository {
+	r := &sessionRepository{rdb: rdb}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// sessionDigestSize is how many bytes of the session reference name the key of
+// a session; 128 bits don't collide even across billions of sessions.
+const sessionDigestSize = 16
+
+// sessionData is the value of a session key, encoded with MessagePack as an
+// array, which takes a few bytes more than the user ID alone.
+type sessionData struct {
+	_msgpack  struct{} `msgpack:",as_array"`
+	UserID    string
+	CreatedAt int64
+}
+
+// SessionRef derives the reference to a session that may be handed out, e.g.
+// in tokens, without revealing the session ID itself.
+func SessionRef(sessionID string) string {
+	return utils.HashToken(sessionID)
+}
+
+// sessionDigest shortens a session reference to the name of the session's
+// key. Keys are named after the reference rather than the session ID, so a key
+// listing doesn't reveal session IDs and sessions can be found by reference.
+func sessionDigest(ref string) string {
+	sum, err := hex.DecodeString(ref)
+	if err != nil || len(sum) < sessionDigestSize {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(sum[:sessionDigestSize])
+}
+
+func sessionKey(digest string) string {
+	return "s:" + digest
+}
+
+// SessionKey returns the Redis key of a session.
+func SessionKey(sessionID string) string {
+	return sessionKey(sessionDigest(SessionRef(sessionID)))
+}
+
+// userSessionsKey is the index of the digests of a user's sessions.
+func userSessionsKey(userID string) string {
+	return "us:" + userID
+}
+
+// jitter returns ttl shortened by a random fraction of up to the TTL jitter.
+func (r *sessionRepository) jitter(ttl time.Duration) time.Duration {
+	if r.ttlJitter == 0 {
+		return ttl
+	}
+	return ttl - time.Duration(mathrand.Float64()*r.ttlJitter*float64(ttl))
+}
+
+func (r *sessionRepository) Create(
+	ctx context.Context,
+	userID string,
+	ttl time.Duration,
+) (string, error) {
+	sessionBytes := make([]byte, 32)
+	if _, err := rand.Read(sessionBytes); err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	sessionID := hex.EncodeToString(sessionBytes)
+	digest := sessionDigest(SessionRef(sessionID))
+	if err := r.set(ctx, digest, userID, time.Now(), r.jitter(ttl)); err != nil {
+		return "", err
+	}
+
+	// The index only needs to live as long as the newest session in it
+	indexKey := userSessionsKey(userID)
+	pipe := r.rdb.Pipeline()
+	pipe.SAdd(ctx, indexKey, digest)
+	pipe.Expire(ctx, indexKey, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		slog.WarnContext(ctx, "failed to index session", "error", err, "user_id", userID)
+	}
+	return sessionID, nil
+}
+
+func (r *sessionRepository) set(
+	ctx context.Context,
+	digest, userID string,
+	createdAt time.Time,
+	ttl time.Duration,
+) error {
+	value, err := encodeSession(userID, createdAt)
+	if err != nil {
+		return err
+	}
+	return r.rdb.Set(ctx, sessionKey(digest), value, ttl).Err()
+}
+
+func encodeSession(userID string, createdAt time.Time) ([]byte, error) {
+	value, err := msgpack.Marshal(&sessionData{UserID: userID, CreatedAt: createdAt.Unix()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode session: %w", err)
+	}
+	return value, nil
+}
+
+// get returns the user ID of the session with digest.
+func (r *sessionRepository) get(ctx context.Context, digest string) (string, error) {
+	value, err := r.rdb.Get(ctx, sessionKey(digest)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrSessionNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return decodeSession(value)
+}
+
+func decodeSession(value []byte) (string, error) {
+	var data sessionData
+	if err := msgpack.Unmarshal(value, &data); err != nil {
+		return "", fmt.Errorf("failed to decode session: %w", err)
+	}
+	return data.UserID, nil
+}
+
+func (r *sessionRepository) GetUserID(ctx context.Context, sessionID string) (string, error) {
+	userID, err := r.get(ctx, sessionDigest(SessionRef(sessionID)))
+	if errors.Is(err, ErrSessionNotFound) {
+		return r.migrateLegacy(ctx, sessionID)
+	}
+	return userID, err
+}
+
+// Refresh extends a session; sessions still stored in the legacy layout were
+// migrated by the GetUserID call that precedes it.
+func (r *sessionRepository) Refresh(
+	ctx context.Context,
+	sessionID, userID string,
+	ttl time.Duration,
+) error {
+	digest := sessionDigest(SessionRef(sessionID))
+	if err := r.rdb.Expire(ctx, sessionKey(digest), r.jitter(ttl)).Err(); err != nil {
+		return err
+	}
+	return r.rdb.Expire(ctx, userSessionsKey(userID), ttl).Err()
+}
+
+// TTL returns the remaining lifetime of a session. Like the Redis command it
+// returns -2 if the session does not exist and -1 if it has no expiration.
+func (r *sessionRepository) TTL(ctx context.Context, sessionID string) (time.Duration, error) {
+	ttl, err := r.rdb.TTL(ctx, SessionKey(sessionID)).Result()
+	if err != nil || ttl != -2 {
+		return ttl, err
+	}
+	return r.rdb.TTL(ctx, legacySessionKey(sessionID)).Result()
+}
+
+func (r *sessionRepository) Delete(ctx context.Context, sessionID string) error {
+	userID, err := r.GetUserID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return nil
+		}
+		return err
+	}
+	return r.delete(ctx, sessionDigest(SessionRef(sessionID)), userID)
+}
+
+func (r *sessionRepository) delete(ctx context.Context, digest, userID string) error {
+	if err := r.rdb.Del(ctx, sessionKey(digest)).Err(); err != nil {
+		return err
+	}
+	return r.rdb.SRem(ctx, userSessionsKey(userID), digest).Err()
+}
+
+// DeleteByUserID revokes every session of the user and returns how many were removed.
+func (r *sessionRepository) DeleteByUserID(ctx context.Context, userID string) (int, error) {
+	// Legacy sessions go first: one migrated meanwhile is indexed by the time
+	// the index is read below
+	deleted, err := r.deleteLegacyByUserID(ctx, userID)
+	if err != nil {
+		return deleted, err
+	}
+
+	indexKey := userSessionsKey(userID)
+	digests, err := r.rdb.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return deleted, err
+	}
+	// Keys are deleted one by one since they may live in different cluster slots
+	for _, digest := range digests {
+		n, err := r.rdb.Del(ctx, sessionKey(digest)).Result()
+		if err != nil {
+			return deleted, err
+		}
+		deleted += int(n)
+	}
+	if err := r.rdb.Del(ctx, indexKey).Err(); err != nil {
+		return deleted, err
+	}
+	slog.InfoContext(ctx, "user sessions revoked", "user_id", userID, "count", deleted)
+	return deleted, nil
+}
+
+// DeleteByRef revokes the session behind a SessionRef and returns its user ID.
+func (r *sessionRepository) DeleteByRef(ctx context.Context, ref string) (string, error) {
+	digest := sessionDigest(ref)
+	userID, err := r.get(ctx, digest)
+	if errors.Is(err, ErrSessionNotFound) {
+		return r.deleteLegacyByRef(ctx, ref)
+	}
+	if err != nil {
+		return "", err
+	}
+	return userID, r.delete(ctx, digest, userID)
+}
+
+// Active reports whether the session behind a SessionRef still exists.
+func (r *sessionRepository) Active(ctx context.Context, ref string) (bool, error) {
+	n, err := r.rdb.Exists(ctx, sessionKey(sessionDigest(ref))).Result()
+	if err != nil || n > 0 {
+		return n > 0, err
+	}
+	n, err = r.rdb.Exists(ctx, legacySessionRefKey(ref)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
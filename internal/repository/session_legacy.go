@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Sessions were stored under their full ID with the user ID as value, with a
+// separate reference key per session and an index of session IDs per user.
+// Such sessions are moved to the current layout when they are read and by
+// Reconcile; once the longest session expiration has passed since the
+// upgrade, none are left and this file can go.
+
+func legacySessionKey(sessionID string) string {
+	return fmt.Sprintf("session:%s", sessionID)
+}
+
+func legacySessionRefKey(ref string) string {
+	return fmt.Sprintf("session_ref:%s", ref)
+}
+
+func legacyUserSessionsKey(userID string) string {
+	return fmt.Sprintf("user_sessions:%s", userID)
+}
+
+// migrateLegacyScript moves a legacy session to the current layout and
+// returns its remaining TTL in milliseconds, 0 if it is gone. Being one step,
+// a concurrent DeleteByUserID either deletes the legacy session or finds it
+// indexed in the current layout.
+//
+// KEYS are the legacy session, then the session and the user's index in the
+// current layout; ARGV the user ID of the session, the encoded session and
+// its digest. Redis Cluster runs no scripts across slots, so there only the
+// legacy session is passed, which is then just claimed.
+var migrateLegacyScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+-- Sessions always expire; a session expiring right now is gone
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl <= 0 then
+	return 0
+end
+redis.call("DEL", KEYS[1])
+if #KEYS == 1 then
+	return ttl
+end
+redis.call("SET", KEYS[2], ARGV[2], "PX", ttl)
+redis.call("SADD", KEYS[3], ARGV[3])
+-- Only ever extend the index, which may hold longer lived sessions
+if redis.call("PTTL", KEYS[3]) < ttl then
+	redis.call("PEXPIRE", KEYS[3], ttl)
+end
+return ttl
+`)
+
+// migrateLegacy moves a session stored in the legacy layout to the current one
+// and returns its user ID, ErrSessionNotFound if there is no such session. It
+// keeps the remaining TTL of the session.
+func (r *sessionRepository) migrateLegacy(ctx context.Context, sessionID string) (string, error) {
+	key := legacySessionKey(sessionID)
+	userID, err := r.rdb.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrSessionNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	value, err := encodeSession(userID, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	digest := sessionDigest(SessionRef(sessionID))
+	keys := []string{key, sessionKey(digest), userSessionsKey(userID)}
+	_, cluster := r.rdb.(*redis.ClusterClient)
+	if cluster {
+		keys = keys[:1]
+	}
+	ms, err := migrateLegacyScript.Run(ctx, r.rdb, keys, userID, value, digest).Int64()
+	if err != nil {
+		return "", err
+	}
+	if ms <= 0 {
+		// Revoked, expired or migrated by a concurrent request since it was read
+		return r.get(ctx, digest)
+	}
+	if cluster {
+		err = r.completeMigration(ctx, sessionID, digest, userID, value,
+			time.Duration(ms)*time.Millisecond)
+	} else {
+		err = r.deleteLegacy(ctx, sessionID, userID)
+	}
+	if err != nil {
+		return "", err
+	}
+	return userID, nil
+}
+
+// completeMigration writes a session claimed by migrateLegacyScript on Redis
+// Cluster. DeleteByUserID drops the legacy index before it reads the current
+// one, so a session no longer in the legacy index once it is written here was
+// revoked meanwhile and is deleted again; so is one that was never indexed,
+// which only logs its user out.
+func (r *sessionRepository) completeMigration(
+	ctx context.Context,
+	sessionID, digest, userID string,
+	value []byte,
+	ttl time.Duration,
+) error {
+	if err := r.rdb.Set(ctx, sessionKey(digest), value, ttl).Err(); err != nil {
+		return err
+	}
+	indexKey := userSessionsKey(userID)
+	pipe := r.rdb.Pipeline()
+	pipe.SAdd(ctx, indexKey, digest)
+	pipe.ExpireNX(ctx, indexKey, ttl)
+	pipe.ExpireGT(ctx, indexKey, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	removed, err := r.rdb.SRem(ctx, legacyUserSessionsKey(userID), sessionID).Result()
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		if err := r.delete(ctx, digest, userID); err != nil {
+			return err
+		}
+		return ErrSessionNotFound
+	}
+	return r.rdb.Del(ctx, legacySessionRefKey(SessionRef(sessionID))).Err()
+}
+
+// deleteLegacy deletes what is left of a migrated legacy session.
+func (r *sessionRepository) deleteLegacy(ctx context.Context, sessionID, userID string) error {
+	if err := r.rdb.Del(ctx, legacySessionRefKey(SessionRef(sessionID))).Err(); err != nil {
+		return err
+	}
+	return r.rdb.SRem(ctx, legacyUserSessionsKey(userID), sessionID).Err()
+}
+
+// deleteLegacyByUserID revokes the legacy sessions of a user.
+func (r *sessionRepository) deleteLegacyByUserID(ctx context.Context, userID string) (int, error) {
+	indexKey := legacyUserSessionsKey(userID)
+	sessionIDs, err := r.rdb.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, sessionID := range sessionIDs {
+		n, err := r.rdb.Del(ctx, legacySessionKey(sessionID)).Result()
+		if err != nil {
+			return deleted, err
+		}
+		if err := r.rdb.Del(ctx, legacySessionRefKey(SessionRef(sessionID))).Err(); err != nil {
+			return deleted, err
+		}
+		deleted += int(n)
+	}
+	return deleted, r.rdb.Del(ctx, indexKey).Err()
+}
+
+// deleteLegacyByRef revokes the legacy session behind a SessionRef.
+func (r *sessionRepository) deleteLegacyByRef(ctx context.Context, ref string) (string, error) {
+	userID, err := r.rdb.Get(ctx, legacySessionRefKey(ref)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrSessionNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	sessionIDs, err := r.rdb.SMembers(ctx, legacyUserSessionsKey(userID)).Result()
+	if err != nil {
+		return "", err
+	}
+	for _, sessionID := range sessionIDs {
+		if SessionRef(sessionID) == ref {
+			if err := r.rdb.Del(ctx, legacySessionKey(sessionID)).Err(); err != nil {
+				return "", err
+			}
+			break
+		}
+	}
+	// Without an index entry the session cannot be found; dropping the reference
+	// still revokes its tokens when they are bound to sessions
+	if err := r.rdb.Del(ctx, legacySessionRefKey(ref)).Err(); err != nil {
+		return "", err
+	}
+	return userID, nil
+}
+
+// migrateLegacySessions moves every session still stored in the legacy layout
+// to the current one and returns how many were moved. Legacy references and
+// indexes left behind expire with the sessions they were created for.
+func (r *sessionRepository) migrateLegacySessions(ctx context.Context) (int, error) {
+	migrated := 0
+	err := scanKeys(ctx, r.rdb, legacySessionKey("*"), func(keys []string) error {
+		for _, key := range keys {
+			sessionID := strings.TrimPrefix(key, legacySessionKey(""))
+			_, err := r.migrateLegacy(ctx, sessionID)
+			if errors.Is(err, ErrSessionNotFound) {
+				// Expired or migrated by a request since it was scanned
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			migrated++
+		}
+		return nil
+	})
+	return migrated, err
+}
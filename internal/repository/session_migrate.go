@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// migratedKeys are the patterns of the keys MigrateSessions copies: sessions in
+// the current and the legacy layout with their indexes, the state of logins in
+// progress, pending invites and email changes, so neither users nor logins
+// started before the switch notice it. Role versions and the keys refusing
+// replays go along, as tokens and requests from before the switch must stay
+// refused after it.
+var migratedKeys = []string{
+	sessionKey("*"),
+	userSessionsKey("*"),
+	legacySessionKey("*"),
+	legacySessionRefKey("*"),
+	legacyUserSessionsKey("*"),
+	OAuthStateKey("*"),
+	deviceAuthorizationKey("*"),
+	deviceUserCodeKey("*"),
+	// Named after token hashes
+	"handoff_token:*",
+	"magic_link:*",
+	loginCodeKey("*"),
+	inviteKeyPrefix + "*",
+	emailChangeKey("*"),
+	userEmailChangeKey("*"),
+	// Named after token hashes
+	"email_change_token:*",
+	"email_rollback:*",
+	roleVersionKey("*"),
+	requestNonceKey("*"),
+	"oauth_code:*",
+	"idempotency:*",
+}
+
+// keepHigherScript sets KEYS[1] to ARGV[1] unless it holds a higher number.
+var keepHigherScript = redis.NewScript(`
+local current = tonumber(redis.call("GET", KEYS[1]) or "0")
+if tonumber(ARGV[1]) > current then
+	redis.call("SET", KEYS[1], ARGV[1])
+	return 1
+end
+return 0
+`)
+
+// SessionMigration counts the keys MigrateSessions went through.
+type SessionMigration struct {
+	// Copied keys didn't exist at the destination
+	Copied int
+	// Merged keys are indexes whose members were added to those at the
+	// destination, and role versions
+	Merged int
+	// Skipped keys already existed at the destination and were left as is
+	Skipped int
+	// Expired keys expired before they could be copied
+	Expired int
+}
+
+// MigrateSessionsOptions tune MigrateSessions.
+type MigrateSessionsOptions struct {
+	// DryRun only counts the keys that would be copied
+	DryRun bool
+	// Progress is called with the counts so far after every batch of keys
+	Progress func(SessionMigration)
+}
+
+// MigrateSessions copies the sessions and login states from src to dst, e.g.
+// to replace a Redis instance without logging everyone out, keeping their
+// remaining TTL. Keys that exist at dst are left alone, except indexes, which
+// are merged, so it can run again after the servers were switched to dst to
+// copy the sessions created on src in the meantime. Sessions revoked on src
+// after they were copied stay valid at dst until they expire, so a migration
+// shouldn't take longer than needed. Role versions are merged as well, keeping
+// the higher one.
+func MigrateSessions(
+	ctx context.Context,
+	src, dst redis.UniversalClient,
+	opts MigrateSessionsOptions,
+) (SessionMigration, error) {
+	var stats SessionMigration
+	for _, pattern := range migratedKeys {
+		err := scanKeys(ctx, src, pattern, func(keys []string) error {
+			if err := migrateKeys(ctx, src, dst, keys, opts.DryRun, &stats); err != nil {
+				return err
+			}
+			if opts.Progress != nil {
+				opts.Progress(stats)
+			}
+			return nil
+		})
+		if err != nil {
+			return stats, fmt.Errorf("failed to migrate %s: %w", pattern, err)
+		}
+	}
+	return stats, nil
+}
+
+func migrateKeys(
+	ctx context.Context,
+	src, dst redis.UniversalClient,
+	keys []string,
+	dryRun bool,
+	stats *SessionMigration,
+) error {
+	pipe := src.Pipeline()
+	types := make([]*redis.StatusCmd, len(keys))
+	ttls := make([]*redis.DurationCmd, len(keys))
+	dumps := make([]*redis.StringCmd, len(keys))
+	for i, key := range keys {
+		types[i] = pipe.Type(ctx, key)
+		ttls[i] = pipe.PTTL(ctx, key)
+		dumps[i] = pipe.Dump(ctx, key)
+	}
+	// Errors are checked per key: sets are merged rather than restored, and
+	// keys expiring meanwhile fail DUMP with redis.Nil
+	_, _ = pipe.Exec(ctx)
+
+	for i, key := range keys {
+		if err := types[i].Err(); err != nil {
+			return err
+		}
+		// PTTL is -1 for keys without a TTL, which are restored without one, and
+		// -2 for keys that are gone
+		ttl := max(ttls[i].Val(), 0)
+		if types[i].Val() == "none" || ttls[i].Val() == -2 {
+			stats.Expired++
+			continue
+		}
+		if strings.HasPrefix(key, roleVersionKey("")) {
+			// Versions bumped at dst since the last run are kept if higher
+			if err := mergeRoleVersion(ctx, src, dst, key, dryRun); err != nil {
+				return err
+			}
+			stats.Merged++
+			continue
+		}
+		if types[i].Val() == "set" {
+			if err := mergeSet(ctx, src, dst, key, ttl, dryRun); err != nil {
+				return err
+			}
+			stats.Merged++
+			continue
+		}
+		if errors.Is(dumps[i].Err(), redis.Nil) {
+			stats.Expired++
+			continue
+		}
+		if err := dumps[i].Err(); err != nil {
+			return fmt.Errorf("failed to dump %s: %w", key, err)
+		}
+		if dryRun {
+			n, err := dst.Exists(ctx, key).Result()
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				stats.Skipped++
+			} else {
+				stats.Copied++
+			}
+			continue
+		}
+		err := dst.Restore(ctx, key, ttl, dumps[i].Val()).Err()
+		if err != nil && strings.HasPrefix(err.Error(), "BUSYKEY") {
+			stats.Skipped++
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to restore %s: %w", key, err)
+		}
+		stats.Copied++
+	}
+	return nil
+}
+
+// mergeRoleVersion raises the role version key at dst to that at src, so no
+// token issued before a role change becomes current again.
+func mergeRoleVersion(
+	ctx context.Context,
+	src, dst redis.UniversalClient,
+	key string,
+	dryRun bool,
+) error {
+	version, err := src.Get(ctx, key).Int64()
+	if errors.Is(err, redis.Nil) || dryRun {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return keepHigherScript.Run(ctx, dst, []string{key}, version).Err()
+}
+
+// mergeSet adds the members of the set key at src to that at dst, extending
+// its TTL to ttl.
+func mergeSet(
+	ctx context.Context,
+	src, dst redis.UniversalClient,
+	key string,
+	ttl time.Duration,
+	dryRun bool,
+) error {
+	members, err := src.SMembers(ctx, key).Result()
+	if err != nil || len(members) == 0 || dryRun {
+		return err
+	}
+	current, err := dst.PTTL(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	add := make([]any, len(members))
+	for i, member := range members {
+		add[i] = member
+	}
+	pipe := dst.TxPipeline()
+	pipe.SAdd(ctx, key, add...)
+	// -2 is a new set, -1 one without a TTL, which is left as is
+	if ttl > 0 && (current == -2 || current >= 0 && current < ttl) {
+		pipe.PExpire(ctx, key, ttl)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,119 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+)
+
+func TestMigrateSessions(t *testing.T) {
+	ctx := context.Background()
+	src, srcMR := testutil.NewRedis(t)
+	dst, dstMR := testutil.NewRedis(t)
+	srcSessions := repository.NewSessionRepository(src)
+	dstSessions := repository.NewSessionRepository(dst)
+
+	var sessionIDs []string
+	for _, userID := range []string{"user-1", "user-1", "user-2"} {
+		sessionID, err := srcSessions.Create(ctx, userID, time.Hour)
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	err := src.Set(ctx, repository.OAuthStateKey("state-1"), "{}", 10*time.Minute).Err()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = src.Set(ctx, "feature_flag:maintenance", "1", 0).Err(); err != nil {
+		t.Fatal(err)
+	}
+	invites := repository.NewInviteRepository(src)
+	err = invites.Create(ctx, &repository.Invite{Email: "new@example.com"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srcVersions := repository.NewRoleVersionRepository(src)
+	dstVersions := repository.NewRoleVersionRepository(dst)
+	for range 3 {
+		if _, err := srcVersions.Bump(ctx, "user-1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Role changes at the destination after the servers were switched
+	for range 2 {
+		if _, err := dstVersions.Bump(ctx, "user-2"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := srcVersions.Bump(ctx, "user-2"); err != nil {
+		t.Fatal(err)
+	}
+	// A session created at the destination after the servers were switched
+	newSessionID, err := dstSessions.Create(ctx, "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	stats, err := repository.MigrateSessions(ctx, src, dst,
+		repository.MigrateSessionsOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("MigrateSessions failed: %v", err)
+	}
+	if stats.Copied != 5 || stats.Merged != 4 || len(dstMR.Keys()) != 3 {
+		t.Fatalf("expected a dry run to copy nothing, got %+v and %v", stats, dstMR.Keys())
+	}
+
+	srcMR.FastForward(5 * time.Minute)
+	stats, err = repository.MigrateSessions(ctx, src, dst, repository.MigrateSessionsOptions{})
+	if err != nil {
+		t.Fatalf("MigrateSessions failed: %v", err)
+	}
+	if stats.Copied != 5 || stats.Merged != 4 || stats.Skipped != 0 {
+		t.Errorf("expected 5 copied and 4 merged keys, got %+v", stats)
+	}
+	for i, sessionID := range sessionIDs {
+		if _, err := dstSessions.GetUserID(ctx, sessionID); err != nil {
+			t.Errorf("expected session %d at the destination, got %v", i, err)
+		}
+	}
+	ttl, err := dstSessions.TTL(ctx, sessionIDs[0])
+	if err != nil || ttl > 55*time.Minute || ttl < 54*time.Minute {
+		t.Errorf("expected the remaining TTL to be kept, got %v (%v)", ttl, err)
+	}
+	if dstMR.Exists("feature_flag:maintenance") {
+		t.Error("expected keys other than sessions and states to be left behind")
+	}
+	if !dstMR.Exists(repository.OAuthStateKey("state-1")) {
+		t.Error("expected the OAuth state to be copied")
+	}
+	if _, err := repository.NewInviteRepository(dst).Get(ctx, "new@example.com"); err != nil {
+		t.Errorf("expected the invite to be copied, got %v", err)
+	}
+	for userID, want := range map[string]int64{"user-1": 3, "user-2": 2} {
+		if got, err := dstVersions.Get(ctx, userID); err != nil || got != want {
+			t.Errorf("expected role version %d of %s, got %d (%v)", want, userID, got, err)
+		}
+	}
+
+	// Running again copies nothing twice
+	stats, err = repository.MigrateSessions(ctx, src, dst, repository.MigrateSessionsOptions{})
+	if err != nil {
+		t.Fatalf("MigrateSessions failed: %v", err)
+	}
+	if stats.Copied != 0 || stats.Skipped != 5 {
+		t.Errorf("expected existing keys to be skipped, got %+v", stats)
+	}
+
+	// The indexes were merged, so revoking covers old and new sessions
+	n, err := dstSessions.DeleteByUserID(ctx, "user-1")
+	if err != nil || n != 3 {
+		t.Errorf("expected 3 sessions of user-1 to be revoked, got %d (%v)", n, err)
+	}
+	if _, err := dstSessions.GetUserID(ctx, newSessionID); err == nil {
+		t.Error("expected the new session to be revoked as well")
+	}
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// reconcileScanCount is the SCAN batch size hint used by Reconcile.
+const reconcileScanCount = 1000
+
+// SessionStats describes the sessions found by Reconcile.
+type SessionStats struct {
+	// Sessions is the number of existing sessions
+	Sessions int
+	// PerUser holds the session count of every user that has sessions
+	PerUser []int
+	// Removed counts index entries whose session no longer exists
+	Removed int
+	// Indexed counts sessions that were missing from their user's index
+	Indexed int
+	// Migrated counts sessions moved from the legacy layout
+	Migrated int
+}
+
+// Reconcile brings the per-user session indexes in line with the sessions that
+// exist: entries of expired or deleted sessions are removed and sessions
+// missing from their user's index are added back. Sessions still stored in the
+// legacy layout are migrated first. The keys are scanned, so it is meant for a
+// background job, not for requests.
+func (r *sessionRepository) Reconcile(ctx context.Context) (SessionStats, error) {
+	var stats SessionStats
+	migrated, err := r.migrateLegacySessions(ctx)
+	stats.Migrated = migrated
+	if err != nil {
+		return stats, err
+	}
+	live, err := r.liveSessions(ctx)
+	if err != nil {
+		return stats, err
+	}
+
+	// Reconcile the existing indexes
+	indexed := make(map[string]bool, len(live))
+	err = scanKeys(ctx, r.rdb, userSessionsKey("*"), func(keys []string) error {
+		for _, key := range keys {
+			userID := strings.TrimPrefix(key, userSessionsKey(""))
+			indexed[userID] = true
+			removed, added, err := r.reconcileIndex(ctx, userID, live[userID])
+			if err != nil {
+				return err
+			}
+			stats.Removed += removed
+			stats.Indexed += added
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	// Recreate indexes that are missing altogether
+	for userID, sessions := range live {
+		if indexed[userID] {
+			continue
+		}
+		_, added, err := r.reconcileIndex(ctx, userID, sessions)
+		if err != nil {
+			return stats, err
+		}
+		stats.Indexed += added
+	}
+
+	for _, sessions := range live {
+		stats.Sessions += len(sessions)
+		stats.PerUser = append(stats.PerUser, len(sessions))
+	}
+	return stats, nil
+}
+
+// liveSessions returns the TTLs of the existing sessions by user and digest.
+func (r *sessionRepository) liveSessions(
+	ctx context.Context,
+) (map[string]map[string]time.Duration, error) {
+	live := make(map[string]map[string]time.Duration)
+	err := scanKeys(ctx, r.rdb, sessionKey("*"), func(keys []string) error {
+		pipe := r.rdb.Pipeline()
+		values := make([]*redis.StringCmd, len(keys))
+		ttls := make([]*redis.DurationCmd, len(keys))
+		for i, key := range keys {
+			values[i] = pipe.Get(ctx, key)
+			ttls[i] = pipe.TTL(ctx, key)
+		}
+		if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		for i, key := range keys {
+			value, err := values[i].Bytes()
+			if err != nil {
+				// Expired since it was scanned
+				continue
+			}
+			userID, err := decodeSession(value)
+			if err != nil {
+				slog.WarnContext(ctx, "invalid session", "error", err, "key", key)
+				continue
+			}
+			if live[userID] == nil {
+				live[userID] = make(map[string]time.Duration)
+			}
+			live[userID][strings.TrimPrefix(key, sessionKey(""))] = ttls[i].Val()
+		}
+		return nil
+	})
+	return live, err
+}
+
+// reconcileIndex removes the entries of sessions that no longer exist from the
+// user's index and adds the sessions missing from it.
+func (r *sessionRepository) reconcileIndex(
+	ctx context.Context,
+	userID string,
+	sessions map[string]time.Duration,
+) (removed, added int, err error) {
+	indexKey := userSessionsKey(userID)
+	members, err := r.rdb.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var stale []any
+	inIndex := make(map[string]bool, len(members))
+	for _, digest := range members {
+		inIndex[digest] = true
+		if _, ok := sessions[digest]; ok {
+			continue
+		}
+		// Sessions created after the scan are indexed after their key is set, so
+		// checking again keeps their entries
+		exists, err := r.rdb.Exists(ctx, sessionKey(digest)).Result()
+		if err != nil {
+			return 0, 0, err
+		}
+		if exists == 0 {
+			stale = append(stale, digest)
+		}
+	}
+	if len(stale) > 0 {
+		if err := r.rdb.SRem(ctx, indexKey, stale...).Err(); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	var missing []any
+	var maxTTL time.Duration
+	for digest, ttl := range sessions {
+		maxTTL = max(maxTTL, ttl)
+		if !inIndex[digest] {
+			missing = append(missing, digest)
+		}
+	}
+	if len(missing) > 0 {
+		pipe := r.rdb.Pipeline()
+		pipe.SAdd(ctx, indexKey, missing...)
+		if maxTTL > 0 {
+			pipe.Expire(ctx, indexKey, maxTTL)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return len(stale), 0, err
+		}
+		slog.WarnContext(ctx, "unindexed sessions found", "user_id", userID, "count", len(missing))
+	}
+	return len(stale), len(missing), nil
+}
+
+// scanKeys calls fn with batches of the keys matching pattern, scanning every
+// master of a cluster.
+func scanKeys(
+	ctx context.Context,
+	rdb redis.UniversalClient,
+	pattern string,
+	fn func(keys []string) error,
+) error {
+	// Masters are scanned concurrently
+	var mu sync.Mutex
+	scan := func(ctx context.Context, client redis.Cmdable) error {
+		var cursor uint64
+		for {
+			keys, next, err := client.Scan(ctx, cursor, pattern, reconcileScanCount).Result()
+			if err != nil {
+				return err
+			}
+			if len(keys) > 0 {
+				mu.Lock()
+				err := fn(keys)
+				mu.Unlock()
+				if err != nil {
+					return err
+				}
+			}
+			if next == 0 {
+				return nil
+			}
+			cursor = next
+		}
+	}
+	if cluster, ok := rdb.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+			return scan(ctx, client)
+		})
+	}
+	return scan(ctx, rdb)
+}
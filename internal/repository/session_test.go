@@ -0,0 +1,166 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestSessionTTLJitter(t *testing.T) {
+	ctx := context.Background()
+	rdb, _ := testutil.NewRedis(t)
+	sessionRepo := repository.NewSessionRepository(rdb, repository.WithTTLJitter(0.1))
+	ttls := make(map[time.Duration]bool)
+	for range 20 {
+		sessionID, err := sessionRepo.Create(ctx, "user-1", 10*time.Hour)
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		ttl, err := sessionRepo.TTL(ctx, sessionID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ttl > 10*time.Hour || ttl < 9*time.Hour {
+			t.Fatalf("expected a TTL between 9 and 10 hours, got %v", ttl)
+		}
+		ttls[ttl] = true
+	}
+	if len(ttls) == 1 {
+		t.Error("expected the TTLs to differ")
+	}
+}
+
+func TestSessionKeys(t *testing.T) {
+	ctx := context.Background()
+	rdb, mr := testutil.NewRedis(t)
+	sessionRepo := repository.NewSessionRepository(rdb)
+	sessionID, err := sessionRepo.Create(ctx, "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	// One key per session and the index of the user, neither naming the session ID
+	if keys := mr.Keys(); len(keys) != 2 {
+		t.Errorf("expected 2 keys, got %v", keys)
+	}
+	key := repository.SessionKey(sessionID)
+	if len(key) != 24 {
+		t.Errorf("expected a 24 byte key, got %q", key)
+	}
+	if active, _ := sessionRepo.Active(ctx, repository.SessionRef(sessionID)); !active {
+		t.Error("expected the session to be active by reference")
+	}
+	if active, _ := sessionRepo.Active(ctx, "not-a-ref"); active {
+		t.Error("expected an invalid reference not to be active")
+	}
+}
+
+// afterCommand calls fn once, after the first command named name.
+type afterCommand struct {
+	name string
+	fn   func()
+}
+
+func (h *afterCommand) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *afterCommand) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		if h.fn != nil && cmd.Name() == h.name {
+			fn := h.fn
+			h.fn = nil
+			fn()
+		}
+		return err
+	}
+}
+
+func (h *afterCommand) ProcessPipelineHook(
+	next redis.ProcessPipelineHook,
+) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestLegacySessionMigration(t *testing.T) {
+	ctx := context.Background()
+	_, mr := testutil.NewRedis(t)
+	clients := map[string]func() redis.UniversalClient{
+		"standalone": func() redis.UniversalClient {
+			return redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		},
+		// Scripts only claim the legacy session on a cluster
+		"cluster": func() redis.UniversalClient {
+			return redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{mr.Addr()}})
+		},
+	}
+	for name, newClient := range clients {
+		t.Run(name, func(t *testing.T) {
+			const sessionID = "legacy-session"
+			legacyKey := "session:" + sessionID
+			mr.FlushAll()
+			createLegacy := func() {
+				if err := mr.Set(legacyKey, "user-1"); err != nil {
+					t.Fatal(err)
+				}
+				mr.SetTTL(legacyKey, time.Hour)
+				if _, err := mr.SetAdd("user_sessions:user-1", sessionID); err != nil {
+					t.Fatal(err)
+				}
+			}
+			rdb := newClient()
+			t.Cleanup(func() { _ = rdb.Close() })
+			sessionRepo := repository.NewSessionRepository(rdb)
+
+			createLegacy()
+			if userID, err := sessionRepo.GetUserID(ctx, sessionID); userID != "user-1" {
+				t.Fatalf("expected the legacy session to be migrated, got %q (%v)", userID, err)
+			}
+			ttl, err := sessionRepo.TTL(ctx, sessionID)
+			if mr.Exists(legacyKey) || err != nil || ttl < 59*time.Minute {
+				t.Errorf("expected the session to be moved with its TTL, got %v (%v)", ttl, err)
+			}
+			if n, err := sessionRepo.DeleteByUserID(ctx, "user-1"); n != 1 || err != nil {
+				t.Errorf("expected the migrated session to be revoked, got %d (%v)", n, err)
+			}
+
+			// The sessions of the user are revoked between the migration reading
+			// the legacy session and moving it
+			createLegacy()
+			otherClient := newClient()
+			t.Cleanup(func() { _ = otherClient.Close() })
+			otherRepo := repository.NewSessionRepository(otherClient)
+			rdb.AddHook(&afterCommand{name: "get", fn: func() {
+				if _, err := otherRepo.DeleteByUserID(ctx, "user-1"); err != nil {
+					t.Errorf("DeleteByUserID failed: %v", err)
+				}
+			}})
+			_, err = sessionRepo.GetUserID(ctx, sessionID)
+			if !errors.Is(err, repository.ErrSessionNotFound) {
+				t.Errorf("expected the revoked session to be gone, got %v", err)
+			}
+			if keys := mr.Keys(); len(keys) != 0 {
+				t.Errorf("expected no session to be left, got %v", keys)
+			}
+
+			// The session is migrated once the revocation read its first index
+			createLegacy()
+			rdb.AddHook(&afterCommand{name: "smembers", fn: func() {
+				if _, err := otherRepo.GetUserID(ctx, sessionID); err != nil {
+					t.Errorf("GetUserID failed: %v", err)
+				}
+			}})
+			if n, err := sessionRepo.DeleteByUserID(ctx, "user-1"); n != 1 || err != nil {
+				t.Errorf("expected the session to be revoked, got %d (%v)", n, err)
+			}
+			if keys := mr.Keys(); len(keys) != 0 {
+				t.Errorf("expected no session to be left, got %v", keys)
+			}
+		})
+	}
+}
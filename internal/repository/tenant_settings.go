@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TenantSettingsRepository stores the settings of tenants, keyed by
+// organization.
+type TenantSettingsRepository interface {
+	// Get returns the settings of a tenant, nil if it has none
+	Get(ctx context.Context, org string) (*model.TenantSettingsModel, error)
+	// List returns the settings of the tenants in orgs that have any, of all
+	// tenants if orgs is nil, ordered by organization
+	List(ctx context.Context, orgs []string) ([]*model.TenantSettingsModel, error)
+	Save(ctx context.Context, settings *model.TenantSettingsModel) error
+	// Delete removes the settings of a tenant, reporting whether it had any
+	Delete(ctx context.Context, org string) (bool, error)
+}
+
+type tenantSettingsRepository struct {
+	db *gorm.DB
+}
+
+func NewTenantSettingsRepository(db *gorm.DB) TenantSettingsRepository {
+	return &tenantSettingsRepository{db: db}
+}
+
+func (r *tenantSettingsRepository) Get(
+	ctx context.Context,
+	org string,
+) (*model.TenantSettingsModel, error) {
+	var settings model.TenantSettingsModel
+	err := r.db.WithContext(ctx).Where("org = ?", org).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r *tenantSettingsRepository) List(
+	ctx context.Context,
+	orgs []string,
+) ([]*model.TenantSettingsModel, error) {
+	query := r.db.WithContext(ctx).Order("org")
+	if orgs != nil {
+		query = query.Where("org IN ?", orgs)
+	}
+	var settings []*model.TenantSettingsModel
+	if err := query.Find(&settings).Error; err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (r *tenantSettingsRepository) Save(
+	ctx context.Context,
+	settings *model.TenantSettingsModel,
+) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "org"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"updated_at", "updated_by", "logo_url", "allowed_providers",
+			"access_token_lifetime", "session_lifetime", "password_min_length",
+		}),
+	}).Create(settings).Error
+}
+
+func (r *tenantSettingsRepository) Delete(ctx context.Context, org string) (bool, error) {
+	result := r.db.WithContext(ctx).Where("org = ?", org).Delete(&model.TenantSettingsModel{})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,57 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/go-webmods/gorm_client"
+)
+
+func TestTenantSettingsRepository(t *testing.T) {
+	ctx := context.Background()
+	db := gorm_client.NewDB(gorm_client.Config{
+		Driver: "sqlite",
+		Name:   filepath.Join(t.TempDir(), "tenants.db"),
+	})
+	if err := db.AutoMigrate(&model.TenantSettingsModel{}); err != nil {
+		t.Fatal(err)
+	}
+	tenants := repository.NewTenantSettingsRepository(db)
+
+	if got, err := tenants.Get(ctx, "acme"); got != nil || err != nil {
+		t.Fatalf("expected no settings, got %+v (%v)", got, err)
+	}
+	for _, tenant := range []*model.TenantSettingsModel{
+		{Org: "acme", AllowedProviders: []string{"github"}, PasswordMinLength: 10},
+		{Org: "globex", SessionLifetime: 600},
+		{Org: "acme", AllowedProviders: []string{"gitlab"}, PasswordMinLength: 12},
+	} {
+		if err := tenants.Save(ctx, tenant); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	got, err := tenants.Get(ctx, "acme")
+	if err != nil || got == nil || got.PasswordMinLength != 12 ||
+		len(got.AllowedProviders) != 1 || got.AllowedProviders[0] != "gitlab" {
+		t.Fatalf("expected the settings to be replaced, got %+v (%v)", got, err)
+	}
+
+	listed, err := tenants.List(ctx, nil)
+	if err != nil || len(listed) != 2 || listed[0].Org != "acme" {
+		t.Errorf("expected both tenants, got %+v (%v)", listed, err)
+	}
+	listed, err = tenants.List(ctx, []string{"globex", "initech"})
+	if err != nil || len(listed) != 1 || listed[0].Org != "globex" {
+		t.Errorf("expected only globex, got %+v (%v)", listed, err)
+	}
+
+	if deleted, err := tenants.Delete(ctx, "acme"); !deleted || err != nil {
+		t.Errorf("expected the settings to be deleted, got %v (%v)", deleted, err)
+	}
+	if deleted, err := tenants.Delete(ctx, "acme"); deleted || err != nil {
+		t.Errorf("expected nothing left to delete, got %v (%v)", deleted, err)
+	}
+}
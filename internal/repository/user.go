@@ -2,21 +2,91 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
+	"github.com/poly-workshop/auth-portal/internal/fieldcrypt"
 	"github.com/poly-workshop/auth-portal/internal/model"
 	"gorm.io/gorm"
 )
 
+// ErrVersionConflict is returned by UpdateIfVersion when the user was updated
+// since the expected version was read.
+var ErrVersionConflict = errors.New("user version conflict")
+
 type UserRepository interface {
 	Create(ctx context.Context, user *model.UserModel) error
 	GetByID(ctx context.Context, id string) (*model.UserModel, error)
 	GetByEmail(ctx context.Context, email string) (*model.UserModel, error)
+	// GetByIDs returns the users with the given IDs in no particular order,
+	// leaving out unknown IDs.
+	GetByIDs(ctx context.Context, ids []string) ([]*model.UserModel, error)
 	GetByGithubID(ctx context.Context, githubID string) (*model.UserModel, error)
 	Update(ctx context.Context, user *model.UserModel) error
+	// TouchLastSeen sets the user's last_seen_at without counting as an update
+	// (the version and updated_at are kept).
+	TouchLastSeen(ctx context.Context, id string, at time.Time) error
+	// UpdateIfVersion is Update if the stored version is still version, and
+	// fails with ErrVersionConflict otherwise.
+	UpdateIfVersion(ctx context.Context, user *model.UserModel, version int64) error
 	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, offset, limit int) ([]*model.UserModel, error)
-	Count(ctx context.Context) (int64, error)
+	List(ctx context.Context, filter UserFilter, offset, limit int) ([]*model.UserModel, error)
+	// ListAfter returns up to limit users with an ID greater than afterID,
+	// ordered by ID; unlike List it stays fast deep into large tables.
+	ListAfter(
+		ctx context.Context,
+		filter UserFilter,
+		afterID string,
+		limit int,
+	) ([]*model.UserModel, error)
+	Count(ctx context.Context, filter UserFilter) (int64, error)
+	// Search returns up to limit users whose lowercased name or email starts
+	// with prefix, ordered by name.
+	Search(
+		ctx context.Context,
+		filter UserFilter,
+		prefix string,
+		limit int,
+	) ([]*model.UserModel, error)
+	ListDeletionDue(ctx context.Context, now time.Time, limit int) ([]*model.UserModel, error)
+	// ListDeletedBefore returns up to limit users deleted (see Delete) before
+	// the given time.
+	ListDeletedBefore(ctx context.Context, before time.Time, limit int) ([]*model.UserModel, error)
+	Purge(ctx context.Context, id string) error
+}
+
+// UserFilter narrows down List and Count; nil fields don't filter.
+type UserFilter struct {
+	PendingApproval *bool
+	// InactiveSince matches users last seen before this time, or never seen
+	// and created before it
+	InactiveSince *time.Time
+	Deactivated   *bool
+	// Orgs matches users of one of these organizations
+	Orgs []string
+}
+
+func (f UserFilter) apply(db *gorm.DB) *gorm.DB {
+	if f.PendingApproval != nil {
+		db = db.Where("pending_approval = ?", *f.PendingApproval)
+	}
+	if f.InactiveSince != nil {
+		db = db.Where("COALESCE(last_seen_at, created_at) < ?", *f.InactiveSince)
+	}
+	if f.Deactivated != nil {
+		if *f.Deactivated {
+			db = db.Where("deactivated_at IS NOT NULL")
+		} else {
+			db = db.Where("deactivated_at IS NULL")
+		}
+	}
+	if f.Orgs != nil {
+		db = db.Where("org IN ?", f.Orgs)
+	}
+	return db
 }
 
 type userRepository struct {
@@ -57,9 +127,24 @@ func (r *userRepository) GetByID(ctx context.Context, id string) (*model.UserMod
 	return &user, nil
 }
 
+func (r *userRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.UserModel, error) {
+	var users []*model.UserModel
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.UserModel, error) {
 	var user model.UserModel
-	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
+	db := r.db.WithContext(ctx).Where("email = ?", email)
+	// Encrypted addresses are found by their blind index, those stored before
+	// it was configured by themselves
+	if index := fieldcrypt.Installed().BlindIndex(email); index != "" {
+		db = db.Or("email_index = ?", index)
+	}
+	err := db.First(&user).Error
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +164,41 @@ func (r *userRepository) GetByGithubID(
 }
 
 func (r *userRepository) Update(ctx context.Context, user *model.UserModel) error {
-	err := r.db.WithContext(ctx).Save(user).Error
+	return r.update(ctx, user, nil)
+}
+
+func (r *userRepository) UpdateIfVersion(
+	ctx context.Context,
+	user *model.UserModel,
+	version int64,
+) error {
+	return r.update(ctx, user, &version)
+}
+
+// update saves all fields of the user and increments its version, only if the
+// stored version matches when expected is set.
+func (r *userRepository) update(ctx context.Context, user *model.UserModel, expected *int64) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(user).Select("*").Omit("id", "created_at", "version")
+		if expected != nil {
+			query = query.Where("version = ?", *expected)
+		}
+		result := query.Updates(user)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 && expected != nil {
+			return ErrVersionConflict
+		}
+		err := tx.Model(user).UpdateColumn("version", gorm.Expr("version + 1")).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(user).Select("version").Scan(&user.Version).Error
+	})
+	if errors.Is(err, ErrVersionConflict) {
+		return err
+	}
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to update user", "error", err, "user_id", user.ID)
 		return err
@@ -88,13 +207,24 @@ func (r *userRepository) Update(ctx context.Context, user *model.UserModel) erro
 	return nil
 }
 
+func (r *userRepository) TouchLastSeen(ctx context.Context, id string, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&model.UserModel{}).
+		Where("id = ?", id).
+		UpdateColumn("last_seen_at", at).Error
+}
+
 func (r *userRepository) Delete(ctx context.Context, id string) error {
 	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.UserModel{}).Error
 }
 
-func (r *userRepository) List(ctx context.Context, offset, limit int) ([]*model.UserModel, error) {
+func (r *userRepository) List(
+	ctx context.Context,
+	filter UserFilter,
+	offset, limit int,
+) ([]*model.UserModel, error) {
 	var users []*model.UserModel
-	err := r.db.WithContext(ctx).Offset(offset).Limit(limit).Find(&users).Error
+	err := filter.apply(r.db.WithContext(ctx)).Offset(offset).Limit(limit).Find(&users).Error
 	if err != nil {
 		slog.ErrorContext(
 			ctx,
@@ -121,11 +251,128 @@ func (r *userRepository) List(ctx context.Context, offset, limit int) ([]*model.
 	return users, nil
 }
 
-func (r *userRepository) Count(ctx context.Context) (int64, error) {
+func (r *userRepository) ListAfter(
+	ctx context.Context,
+	filter UserFilter,
+	afterID string,
+	limit int,
+) ([]*model.UserModel, error) {
+	var users []*model.UserModel
+	err := filter.apply(r.db.WithContext(ctx)).
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list users", "error", err, "after_id", afterID)
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *userRepository) Count(ctx context.Context, filter UserFilter) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&model.UserModel{}).Count(&count).Error
+	err := filter.apply(r.db.WithContext(ctx).Model(&model.UserModel{})).Count(&count).Error
 	if err != nil {
 		return 0, err
 	}
 	return count, nil
 }
+
+func (r *userRepository) Search(
+	ctx context.Context,
+	filter UserFilter,
+	prefix string,
+	limit int,
+) ([]*model.UserModel, error) {
+	pattern := likeEscaper.Replace(strings.ToLower(prefix)) + "%"
+	match := r.db.Where(`lower(name) LIKE ? ESCAPE '\'`, pattern)
+	// Encrypted addresses can't be matched by prefix, only exactly
+	if keyring := fieldcrypt.Installed(); keyring.Encrypts(fieldcrypt.EmailColumn) {
+		match = match.Or("email_index = ?", keyring.BlindIndex(prefix))
+	} else {
+		match = match.Or(`lower(email) LIKE ? ESCAPE '\'`, pattern)
+	}
+	var users []*model.UserModel
+	err := filter.apply(r.db.WithContext(ctx)).
+		Where(match).
+		Order("name").
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to search users", "error", err)
+		return nil, err
+	}
+	return users, nil
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// CreateSearchIndexes creates the indexes Search uses for prefix matches on
+// PostgreSQL, where AutoMigrate can't declare them. Other databases are left
+// as they are.
+func CreateSearchIndexes(db *gorm.DB) error {
+	if db.Dialector.Name() != "postgres" {
+		return nil
+	}
+	for _, column := range []string{"name", "email"} {
+		err := db.Exec(fmt.Sprintf(
+			"CREATE INDEX IF NOT EXISTS idx_users_%[1]s_prefix "+
+				"ON users (lower(%[1]s) text_pattern_ops)",
+			column,
+		)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListDeletionDue returns users whose scheduled account deletion is at or before now.
+func (r *userRepository) ListDeletionDue(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+) ([]*model.UserModel, error) {
+	var users []*model.UserModel
+	err := r.db.WithContext(ctx).
+		Unscoped().
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", now).
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list users due for deletion", "error", err)
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *userRepository) ListDeletedBefore(
+	ctx context.Context,
+	before time.Time,
+	limit int,
+) ([]*model.UserModel, error) {
+	var users []*model.UserModel
+	err := r.db.WithContext(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list deleted users", "error", err)
+		return nil, err
+	}
+	return users, nil
+}
+
+// Purge permanently removes the user row, bypassing soft delete.
+func (r *userRepository) Purge(ctx context.Context, id string) error {
+	err := r.db.WithContext(ctx).Unscoped().Where("id = ?", id).Delete(&model.UserModel{}).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to purge user", "error", err, "user_id", id)
+		return err
+	}
+	slog.InfoContext(ctx, "user purged successfully", "user_id", id)
+	return nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"gorm.io/gorm"
+)
+
+// UserBackend creates the UserRepository of a user store backend from the
+// options of user_store.options. db is the database of the service, which
+// backends storing users elsewhere can ignore.
+type UserBackend func(db *gorm.DB, options map[string]string) (UserRepository, error)
+
+var userBackends = struct {
+	sync.RWMutex
+	byName map[string]UserBackend
+}{byName: map[string]UserBackend{
+	configs.UserStoreDatabase: func(db *gorm.DB, _ map[string]string) (UserRepository, error) {
+		return NewUserRepository(db), nil
+	},
+}}
+
+// RegisterUserBackend makes a user store backend available to
+// user_store.backend under name, e.g. from the init function of a package
+// imported by a build of the server. It panics if name is taken.
+func RegisterUserBackend(name string, backend UserBackend) {
+	userBackends.Lock()
+	defer userBackends.Unlock()
+	if _, ok := userBackends.byName[name]; ok {
+		panic(fmt.Sprintf("user backend %q is already registered", name))
+	}
+	userBackends.byName[name] = backend
+}
+
+// UserBackends returns the names of the registered user store backends, sorted.
+func UserBackends() []string {
+	userBackends.RLock()
+	defer userBackends.RUnlock()
+	names := make([]string, 0, len(userBackends.byName))
+	for name := range userBackends.byName {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// OpenUserRepository returns the UserRepository of the backend selected by
+// user_store.backend, the database if unset.
+func OpenUserRepository(db *gorm.DB, cfg configs.UserStoreConfig) (UserRepository, error) {
+	name := cfg.Backend
+	if name == "" {
+		name = configs.UserStoreDatabase
+	}
+	userBackends.RLock()
+	backend, ok := userBackends.byName[name]
+	userBackends.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf(
+			"unknown user store backend %q, registered: %v",
+			name,
+			UserBackends(),
+		)
+	}
+	repo, err := backend(db, cfg.Options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open user store backend %q: %w", name, err)
+	}
+	return repo, nil
+}
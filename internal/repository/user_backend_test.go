@@ -0,0 +1,44 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"gorm.io/gorm"
+)
+
+func TestOpenUserRepository(t *testing.T) {
+	if _, err := repository.OpenUserRepository(nil, configs.UserStoreConfig{}); err != nil {
+		t.Fatalf("expected the database backend by default, got %v", err)
+	}
+	_, err := repository.OpenUserRepository(nil, configs.UserStoreConfig{Backend: "directory"})
+	if err == nil {
+		t.Fatal("expected unknown backends to fail")
+	}
+
+	directory := testutil.NewUserRepository()
+	var options map[string]string
+	repository.RegisterUserBackend(
+		"directory",
+		func(_ *gorm.DB, opts map[string]string) (repository.UserRepository, error) {
+			options = opts
+			return directory, nil
+		},
+	)
+	repo, err := repository.OpenUserRepository(nil, configs.UserStoreConfig{
+		Backend: "directory",
+		Options: map[string]string{"url": "https://users.example.com"},
+	})
+	if err != nil || repo != directory || options["url"] != "https://users.example.com" {
+		t.Fatalf("expected the registered backend with its options, got %v, %v", options, err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a backend twice to panic")
+		}
+	}()
+	repository.RegisterUserBackend("directory", nil)
+}
@@ -0,0 +1,222 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/throttle"
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+// Signal is an indicator contributing to the risk score of a login.
+type Signal string
+
+const (
+	// SignalNewDevice is set when the user agent was not seen for the user before
+	SignalNewDevice Signal = "new_device"
+	// SignalNewNetwork is set when the client network (IPv4 /24, IPv6 /48) was
+	// not seen for the user before; it stands in for a geo change
+	SignalNewNetwork Signal = "new_network"
+	// SignalVelocity is set when the user logs in unusually often
+	SignalVelocity Signal = "velocity"
+	// SignalFailedAttempts is set when the account recently had failed logins
+	SignalFailedAttempts Signal = "failed_attempts"
+)
+
+// signalWeights add up to 100, the highest possible score.
+var signalWeights = map[Signal]int{
+	SignalNewDevice:      20,
+	SignalNewNetwork:     30,
+	SignalVelocity:       25,
+	SignalFailedAttempts: 25,
+}
+
+// Action is what should happen with a login given its score.
+type Action string
+
+const (
+	ActionAllow Action = "allow"
+	// ActionChallenge asks for additional verification, e.g. a second factor
+	ActionChallenge Action = "challenge"
+	ActionBlock     Action = "block"
+)
+
+var loginActions = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "auth_login_risk_actions_total",
+	Help: "Logins by the action derived from their risk score.",
+}, []string{"action"})
+
+// Attempt describes a login whose credentials were verified.
+type Attempt struct {
+	UserID    string
+	Email     string
+	IPAddress string
+	UserAgent string
+}
+
+// Assessment is the risk score of a login and the action derived from it.
+type Assessment struct {
+	Score   int
+	Signals []Signal
+	Action  Action
+}
+
+// Metadata renders the assessment for audit events.
+func (a Assessment) Metadata() map[string]string {
+	signals := make([]string, len(a.Signals))
+	for i, signal := range a.Signals {
+		signals[i] = string(signal)
+	}
+	return map[string]string{
+		"risk_score":   strconv.Itoa(a.Score),
+		"risk_signals": strings.Join(signals, ","),
+		"risk_action":  string(a.Action),
+	}
+}
+
+// Scorer computes a simple risk score for logins from the user's login history.
+type Scorer struct {
+	rdb      redis.UniversalClient
+	throttle *throttle.LoginThrottle
+	cfg      configs.RiskConfig
+}
+
+func NewScorer(
+	rdb redis.UniversalClient,
+	loginThrottle *throttle.LoginThrottle,
+	cfg configs.RiskConfig,
+) *Scorer {
+	return &Scorer{rdb: rdb, throttle: loginThrottle, cfg: cfg}
+}
+
+func devicesKey(userID string) string {
+	return fmt.Sprintf("login_devices:%s", userID)
+}
+
+func networksKey(userID string) string {
+	return fmt.Sprintf("login_networks:%s", userID)
+}
+
+func velocityKey(userID string) string {
+	return fmt.Sprintf("login_velocity:%s", userID)
+}
+
+// Assess scores a login without recording it. Users without any login history
+// are not flagged for a new device or network.
+func (s *Scorer) Assess(ctx context.Context, attempt Attempt) (Assessment, error) {
+	if !s.cfg.Enabled {
+		return Assessment{Action: ActionAllow}, nil
+	}
+
+	var signals []Signal
+	newDevice, err := s.isNew(ctx, devicesKey(attempt.UserID), deviceID(attempt.UserAgent))
+	if err != nil {
+		return Assessment{}, err
+	}
+	if newDevice {
+		signals = append(signals, SignalNewDevice)
+	}
+	network := utils.TruncateIP(attempt.IPAddress)
+	newNetwork, err := s.isNew(ctx, networksKey(attempt.UserID), network)
+	if err != nil {
+		return Assessment{}, err
+	}
+	if newNetwork {
+		signals = append(signals, SignalNewNetwork)
+	}
+
+	logins, err := s.rdb.Get(ctx, velocityKey(attempt.UserID)).Int()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return Assessment{}, err
+	}
+	if logins >= s.cfg.VelocityMaxLogins {
+		signals = append(signals, SignalVelocity)
+	}
+
+	failures, err := s.throttle.AccountFailures(ctx, attempt.Email)
+	if err != nil {
+		return Assessment{}, err
+	}
+	if failures >= s.cfg.FailedAttempts {
+		signals = append(signals, SignalFailedAttempts)
+	}
+
+	assessment := s.assessment(signals)
+	loginActions.WithLabelValues(string(assessment.Action)).Inc()
+	return assessment, nil
+}
+
+func (s *Scorer) assessment(signals []Signal) Assessment {
+	score := 0
+	for _, signal := range signals {
+		score += signalWeights[signal]
+	}
+	action := ActionAllow
+	switch {
+	case s.cfg.BlockThreshold > 0 && score >= s.cfg.BlockThreshold:
+		action = ActionBlock
+	case s.cfg.ChallengeThreshold > 0 && score >= s.cfg.ChallengeThreshold:
+		action = ActionChallenge
+	}
+	return Assessment{Score: score, Signals: signals, Action: action}
+}
+
+// isNew reports whether member is missing from a non-empty history set.
+func (s *Scorer) isNew(ctx context.Context, key, member string) (bool, error) {
+	if member == "" {
+		return false, nil
+	}
+	pipe := s.rdb.Pipeline()
+	known := pipe.SIsMember(ctx, key, member)
+	size := pipe.SCard(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, err
+	}
+	return size.Val() > 0 && !known.Val(), nil
+}
+
+// Remember adds a successful login to the user's history.
+func (s *Scorer) Remember(ctx context.Context, attempt Attempt) error {
+	if !s.cfg.Enabled {
+		return nil
+	}
+	if err := s.remember(ctx, devicesKey(attempt.UserID), deviceID(attempt.UserAgent)); err != nil {
+		return err
+	}
+	network := utils.TruncateIP(attempt.IPAddress)
+	if err := s.remember(ctx, networksKey(attempt.UserID), network); err != nil {
+		return err
+	}
+
+	pipe := s.rdb.Pipeline()
+	pipe.Incr(ctx, velocityKey(attempt.UserID))
+	pipe.ExpireNX(ctx, velocityKey(attempt.UserID), s.cfg.VelocityWindow)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (s *Scorer) remember(ctx context.Context, key, member string) error {
+	if member == "" {
+		return nil
+	}
+	pipe := s.rdb.Pipeline()
+	pipe.SAdd(ctx, key, member)
+	pipe.Expire(ctx, key, s.cfg.History)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// deviceID identifies a device by its hashed user agent.
+func deviceID(userAgent string) string {
+	if userAgent == "" {
+		return ""
+	}
+	return utils.HashToken(userAgent)
+}
@@ -0,0 +1,128 @@
+package risk
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/throttle"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestScorer(t *testing.T, cfg configs.RiskConfig) (*Scorer, *throttle.LoginThrottle) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	loginThrottle := throttle.NewLoginThrottle(rdb, configs.ThrottleConfig{
+		Enabled:        true,
+		FreeAttempts:   100,
+		IPFreeAttempts: 100,
+		BaseDelay:      time.Second,
+		MaxDelay:       time.Second,
+		Window:         time.Hour,
+	})
+	return NewScorer(rdb, loginThrottle, cfg), loginThrottle
+}
+
+func testConfig() configs.RiskConfig {
+	return configs.RiskConfig{
+		Enabled:            true,
+		ChallengeThreshold: 50,
+		BlockThreshold:     70,
+		VelocityWindow:     time.Hour,
+		VelocityMaxLogins:  3,
+		FailedAttempts:     3,
+		History:            24 * time.Hour,
+	}
+}
+
+func testAttempt() Attempt {
+	return Attempt{
+		UserID:    "user-1",
+		Email:     "a@example.com",
+		IPAddress: "10.0.0.1",
+		UserAgent: "agent",
+	}
+}
+
+func TestAssessSignals(t *testing.T) {
+	s, _ := newTestScorer(t, testConfig())
+	ctx := context.Background()
+	known := testAttempt()
+
+	first, err := s.Assess(ctx, known)
+	if err != nil {
+		t.Fatalf("Assess failed: %v", err)
+	}
+	if first.Score != 0 || first.Action != ActionAllow {
+		t.Errorf("Expected a first login without history to be allowed, got %+v", first)
+	}
+	if err := s.Remember(ctx, known); err != nil {
+		t.Fatalf("Remember failed: %v", err)
+	}
+
+	sameNetwork := known
+	sameNetwork.IPAddress = "10.0.0.2"
+	if a, _ := s.Assess(ctx, sameNetwork); a.Score != 0 {
+		t.Errorf("Expected the same device and network to score 0, got %+v", a)
+	}
+
+	elsewhere := known
+	elsewhere.IPAddress = "192.0.2.1"
+	elsewhere.UserAgent = "other-agent"
+	a, err := s.Assess(ctx, elsewhere)
+	if err != nil {
+		t.Fatalf("Assess failed: %v", err)
+	}
+	if !slices.Equal(a.Signals, []Signal{SignalNewDevice, SignalNewNetwork}) {
+		t.Errorf("Expected new device and network, got %v", a.Signals)
+	}
+	if a.Score != 50 || a.Action != ActionChallenge {
+		t.Errorf("Expected a challenge at score 50, got %+v", a)
+	}
+}
+
+func TestAssessVelocityAndFailures(t *testing.T) {
+	s, loginThrottle := newTestScorer(t, testConfig())
+	ctx := context.Background()
+	attempt := testAttempt()
+
+	for range 3 {
+		if err := s.Remember(ctx, attempt); err != nil {
+			t.Fatalf("Remember failed: %v", err)
+		}
+	}
+	for range 3 {
+		if err := loginThrottle.RecordFailure(ctx, attempt.Email, attempt.IPAddress); err != nil {
+			t.Fatalf("RecordFailure failed: %v", err)
+		}
+	}
+
+	a, err := s.Assess(ctx, attempt)
+	if err != nil {
+		t.Fatalf("Assess failed: %v", err)
+	}
+	if !slices.Equal(a.Signals, []Signal{SignalVelocity, SignalFailedAttempts}) {
+		t.Errorf("Expected velocity and failed attempts, got %v", a.Signals)
+	}
+
+	attempt.UserAgent = "other-agent"
+	if a, _ := s.Assess(ctx, attempt); a.Action != ActionBlock {
+		t.Errorf("Expected a block at score %d, got %+v", a.Score, a)
+	}
+}
+
+func TestAssessDisabled(t *testing.T) {
+	cfg := testConfig()
+	cfg.Enabled = false
+	s, _ := newTestScorer(t, cfg)
+
+	a, err := s.Assess(context.Background(), Attempt{UserID: "user-1"})
+	if err != nil || a.Action != ActionAllow {
+		t.Errorf("Expected disabled scoring to allow, got %+v, %v", a, err)
+	}
+}
@@ -0,0 +1,36 @@
+// Package rpcmeta reads the descriptors of the RPCs compiled into this binary,
+// e.g. for the custom method options declared in proto/.
+package rpcmeta
+
+import (
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Method returns the descriptor of the RPC named by a gRPC full method name
+// such as "/user.v1.UserService/GetUser".
+func Method(fullMethod string) (protoreflect.MethodDescriptor, bool) {
+	name := strings.ReplaceAll(strings.TrimPrefix(fullMethod, "/"), "/", ".")
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, false
+	}
+	method, ok := desc.(protoreflect.MethodDescriptor)
+	return method, ok
+}
+
+// Idempotent reports whether calls of the RPC may safely be repeated, i.e. it
+// declares an idempotency_level or is mapped to HTTP GET.
+func Idempotent(method protoreflect.MethodDescriptor) bool {
+	opts, ok := method.Options().(*descriptorpb.MethodOptions)
+	if ok && opts.GetIdempotencyLevel() != descriptorpb.MethodOptions_IDEMPOTENCY_UNKNOWN {
+		return true
+	}
+	rule, ok := proto.GetExtension(method.Options(), annotations.E_Http).(*annotations.HttpRule)
+	return ok && rule.GetGet() != ""
+}
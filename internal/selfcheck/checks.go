@@ -0,0 +1,157 @@
+package selfcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/outbound"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"github.com/poly-workshop/go-webmods/gorm_client"
+	"github.com/poly-workshop/go-webmods/redis_client"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Config is a check of configuration that validate, e.g. a constructor of the
+// component configured, is run with.
+func Config(name string, validate func() error) Check {
+	return Check{Name: "config: " + name, Run: func(context.Context) (string, error) {
+		if err := validate(); err != nil {
+			return "", err
+		}
+		return "valid", nil
+	}}
+}
+
+// Database connects to the database and pings it.
+func Database(cfg gorm_client.Config) Check {
+	return Check{Name: "database", Run: func(ctx context.Context) (detail string, err error) {
+		defer func() {
+			// gorm_client panics if it can't open the database
+			if r := recover(); r != nil {
+				err = fmt.Errorf("failed to open %s database: %v", cfg.Driver, r)
+			}
+		}()
+		sqlDB, err := gorm_client.NewDB(cfg).DB()
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = sqlDB.Close() }()
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return "", fmt.Errorf("failed to reach %s database: %w", cfg.Driver, err)
+		}
+		detail = fmt.Sprintf("%s database %s", cfg.Driver, cfg.Name)
+		if cfg.Host != "" {
+			detail += " on " + cfg.Host
+		}
+		return detail + " reachable", nil
+	}}
+}
+
+// Redis connects to Redis and pings it.
+func Redis(cfg redis_client.Config) Check {
+	return Check{Name: "redis", Run: func(ctx context.Context) (string, error) {
+		if len(cfg.Urls) == 0 {
+			return "", errors.New("no redis hosts configured")
+		}
+		rdb := redis.NewUniversalClient(&redis.UniversalOptions{
+			Addrs:    cfg.Urls,
+			Password: cfg.Password,
+		})
+		defer func() { _ = rdb.Close() }()
+		if err := rdb.Ping(ctx).Err(); err != nil {
+			return "", fmt.Errorf("failed to reach redis: %w", err)
+		}
+		return fmt.Sprintf("%v reachable", cfg.Urls), nil
+	}}
+}
+
+// clientErrors are the token endpoint errors meaning the client credentials
+// are wrong, as opposed to the code made up to call it.
+var clientErrors = []string{
+	"invalid_client",
+	"unauthorized_client",
+	// GitHub's code for a wrong client secret
+	"incorrect_client_credentials",
+}
+
+// OAuthClient verifies the credentials of an OAuth client by exchanging a made
+// up code at the token endpoint: the provider rejects the code, but tells
+// whether it knows the client. The code is sent with client, as logins would
+// (nil for http.DefaultClient). Providers without a client ID are skipped.
+func OAuthClient(name string, cfg *oauth2.Config, client *http.Client) Check {
+	return Check{Name: "oauth: " + name, Run: func(ctx context.Context) (string, error) {
+		if cfg.ClientID == "" {
+			return "not configured, skipped", nil
+		}
+		_, err := cfg.Exchange(outbound.Context(ctx, client), "selfcheck")
+		var retrieveErr *oauth2.RetrieveError
+		switch {
+		case err == nil:
+			return "", errors.New("token endpoint accepted a made up code")
+		case !errors.As(err, &retrieveErr):
+			return "", fmt.Errorf("failed to reach token endpoint: %w", err)
+		case slices.Contains(clientErrors, retrieveErr.ErrorCode):
+			return "", fmt.Errorf("client %s rejected: %s", cfg.ClientID, retrieveErr.ErrorCode)
+		case retrieveErr.ErrorCode == "":
+			return "", fmt.Errorf("unexpected token endpoint response: %w", err)
+		}
+		return fmt.Sprintf("client %s accepted by %s", cfg.ClientID, cfg.Endpoint.TokenURL), nil
+	}}
+}
+
+// Policy loads the RBAC policy and lints it against the RPCs of the services.
+func Policy() Check {
+	return Check{Name: "policy", Run: func(context.Context) (string, error) {
+		enforcer, err := auth.SharedEnforcer()
+		if err != nil {
+			return "", fmt.Errorf("failed to load policy: %w", err)
+		}
+		issues, err := auth.NewPolicySet(enforcer).Lint(
+			user_v1_pb.File_user_v1_user_proto,
+			auth_v1_pb.File_auth_v1_auth_proto,
+		)
+		if err != nil {
+			return "", fmt.Errorf("failed to lint policy: %w", err)
+		}
+		rules, err := enforcer.GetPolicy()
+		if err != nil {
+			return "", fmt.Errorf("failed to read policy: %w", err)
+		}
+		if len(issues) > 0 {
+			return "", fmt.Errorf(
+				"%d rules, %d issues, e.g. %v",
+				len(rules),
+				len(issues),
+				issues[0],
+			)
+		}
+		return fmt.Sprintf("%d rules, no issues", len(rules)), nil
+	}}
+}
+
+// GRPCHealth asks the gRPC server at target whether it is serving.
+func GRPCHealth(target string, opts ...grpc.DialOption) Check {
+	return Check{Name: "grpc server", Run: func(ctx context.Context) (string, error) {
+		conn, err := grpc.NewClient(target, opts...)
+		if err != nil {
+			return "", fmt.Errorf("invalid target %s: %w", target, err)
+		}
+		defer func() { _ = conn.Close() }()
+		resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			return "", fmt.Errorf("failed to check health of %s: %w", target, err)
+		}
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			return "", fmt.Errorf("%s is %s", target, resp.Status)
+		}
+		return target + " serving", nil
+	}}
+}
@@ -0,0 +1,133 @@
+// Package selfcheck runs the checks behind the --check flag of the servers: it
+// validates their configuration and reaches their dependencies, then reports
+// every result at once, so deployments and CI can be gated on it.
+package selfcheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// Report formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Check is a named check; Run returns a short description of what it found,
+// or why it failed.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (string, error)
+}
+
+// Result is the outcome of a check.
+type Result struct {
+	Name     string
+	Detail   string
+	Err      error
+	Duration time.Duration
+}
+
+// Run runs the checks one after another, each within timeout, and returns
+// their results in order. Panics of a check fail it.
+func Run(ctx context.Context, timeout time.Duration, checks ...Check) []Result {
+	results := make([]Result, len(checks))
+	for i, check := range checks {
+		start := time.Now()
+		detail, err := run(ctx, timeout, check)
+		results[i] = Result{
+			Name:     check.Name,
+			Detail:   detail,
+			Err:      err,
+			Duration: time.Since(start),
+		}
+	}
+	return results
+}
+
+func run(ctx context.Context, timeout time.Duration, check Check) (detail string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return check.Run(ctx)
+}
+
+// Passed reports whether every check passed.
+func Passed(results []Result) bool {
+	for _, result := range results {
+		if result.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Report writes the results to w as a table (FormatText) or as a JSON object
+// (FormatJSON).
+func Report(w io.Writer, format string, results []Result) error {
+	switch format {
+	case FormatText:
+		return reportText(w, results)
+	case FormatJSON:
+		return reportJSON(w, results)
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+func reportText(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	failed := 0
+	for _, result := range results {
+		state, detail := "ok", result.Detail
+		if result.Err != nil {
+			state, detail = "FAIL", result.Err.Error()
+			failed++
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", state, result.Name,
+			result.Duration.Round(time.Millisecond), detail)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%d of %d checks failed\n", failed, len(results))
+	return err
+}
+
+type jsonResult struct {
+	Name       string `json:"name"`
+	OK         bool   `json:"ok"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+func reportJSON(w io.Writer, results []Result) error {
+	report := struct {
+		OK     bool         `json:"ok"`
+		Checks []jsonResult `json:"checks"`
+	}{OK: Passed(results), Checks: make([]jsonResult, len(results))}
+	for i, result := range results {
+		report.Checks[i] = jsonResult{
+			Name:       result.Name,
+			OK:         result.Err == nil,
+			Detail:     result.Detail,
+			DurationMS: result.Duration.Milliseconds(),
+		}
+		if result.Err != nil {
+			report.Checks[i].Error = result.Err.Error()
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
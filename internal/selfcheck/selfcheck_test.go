@@ -0,0 +1,141 @@
+package selfcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"github.com/poly-workshop/go-webmods/redis_client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestRunAndReport(t *testing.T) {
+	results := Run(context.Background(), time.Second,
+		Config("valid", func() error { return nil }),
+		Config("invalid", func() error { return errors.New("bad value") }),
+		Check{Name: "panics", Run: func(context.Context) (string, error) { panic("boom") }},
+		Check{Name: "slow", Run: func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}},
+	)
+	if Passed(results) {
+		t.Fatal("expected failed checks to fail the run")
+	}
+	for i, wantErr := range []bool{false, true, true, true} {
+		if (results[i].Err != nil) != wantErr {
+			t.Errorf("%s: expected error %v, got %v", results[i].Name, wantErr, results[i].Err)
+		}
+	}
+
+	var text bytes.Buffer
+	if err := Report(&text, FormatText, results); err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	for _, want := range []string{"ok    config: valid", "FAIL  config: invalid", "bad value",
+		"panic: boom", "3 of 4 checks failed"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("expected text report to contain %q, got:\n%s", want, text.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := Report(&out, FormatJSON, results[:1]); err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	var report struct {
+		OK     bool
+		Checks []struct {
+			Name   string
+			OK     bool
+			Detail string
+		}
+	}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("invalid json report: %v", err)
+	}
+	if !report.OK || len(report.Checks) != 1 || report.Checks[0].Detail != "valid" {
+		t.Errorf("unexpected json report: %s", out.String())
+	}
+
+	if err := Report(&out, "yaml", results); err == nil {
+		t.Error("expected unknown formats to be rejected")
+	}
+}
+
+func TestRedis(t *testing.T) {
+	_, mr := testutil.NewRedis(t)
+	ctx := context.Background()
+	check := Redis(redis_client.Config{Urls: []string{mr.Addr()}})
+	if _, err := check.Run(ctx); err != nil {
+		t.Errorf("expected reachable redis to pass, got %v", err)
+	}
+	mr.Close()
+	if _, err := check.Run(ctx); err == nil {
+		t.Error("expected unreachable redis to fail")
+	}
+	if _, err := Redis(redis_client.Config{}).Run(ctx); err == nil {
+		t.Error("expected missing redis hosts to fail")
+	}
+}
+
+func TestOAuthClient(t *testing.T) {
+	provider := testutil.NewOAuthProvider(t)
+	ctx := context.Background()
+
+	cfg := provider.Config("http://localhost/callback")
+	if _, err := OAuthClient("fake", cfg, nil).Run(ctx); err != nil {
+		t.Errorf("expected a known client to pass, got %v", err)
+	}
+
+	unknown := provider.Config("http://localhost/callback")
+	unknown.ClientID = "unknown-client"
+	_, err := OAuthClient("fake", unknown, nil).Run(ctx)
+	if err == nil || !strings.Contains(err.Error(), "invalid_client") {
+		t.Errorf("expected an unknown client to fail, got %v", err)
+	}
+
+	unknown.ClientID = ""
+	if detail, err := OAuthClient("fake", unknown, nil).Run(ctx); err != nil ||
+		!strings.Contains(detail, "skipped") {
+		t.Errorf("expected an unconfigured provider to be skipped, got %q, %v", detail, err)
+	}
+
+	provider.Server.Close()
+	if _, err := OAuthClient("fake", cfg, nil).Run(ctx); err == nil {
+		t.Error("expected an unreachable token endpoint to fail")
+	}
+}
+
+func TestGRPCHealth(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	srv := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(srv, healthServer)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	check := GRPCHealth(lis.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if _, err := check.Run(ctx); err != nil {
+		t.Errorf("expected a serving server to pass, got %v", err)
+	}
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	if _, err := check.Run(ctx); err == nil {
+		t.Error("expected a server not serving to fail")
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Probe checks a dependency the server can't serve calls without, e.g. by
+// pinging the database.
+type Probe func(ctx context.Context) error
+
+// WatchHealth reports the server through healthServer as serving while all
+// probes succeed and as not serving while any fails, probing every interval
+// until ctx is done. Gateways watching the health service then skip the
+// server until its dependencies are back.
+func WatchHealth(
+	ctx context.Context,
+	healthServer *health.Server,
+	interval time.Duration,
+	probes map[string]Probe,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	serving := true
+	for {
+		failed := probeAll(ctx, interval, probes)
+		switch {
+		case failed && serving:
+			healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+		case !failed && !serving:
+			healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+			slog.InfoContext(ctx, "dependencies reachable again, serving")
+		}
+		serving = !failed
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probeAll runs the probes, each for up to timeout, and tells whether any failed.
+func probeAll(ctx context.Context, timeout time.Duration, probes map[string]Probe) bool {
+	failed := false
+	for name, probe := range probes {
+		probeCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := probe(probeCtx)
+		cancel()
+		if err != nil && ctx.Err() == nil {
+			slog.WarnContext(ctx, "dependency unreachable, not serving",
+				"dependency", name, "error", err)
+			failed = true
+		}
+	}
+	return failed
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestWatchHealth(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	healthServer := health.NewServer()
+	var down atomic.Bool
+	go WatchHealth(ctx, healthServer, 5*time.Millisecond, map[string]Probe{
+		"database": func(context.Context) error {
+			if down.Load() {
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+	})
+	waitFor := func(want healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for {
+			resp, err := healthServer.Check(ctx, &healthpb.HealthCheckRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Status == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected %v, got %v", want, resp.Status)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	down.Store(true)
+	waitFor(healthpb.HealthCheckResponse_NOT_SERVING)
+	down.Store(false)
+	waitFor(healthpb.HealthCheckResponse_SERVING)
+
+	// Shutting down stays not serving, whatever the dependencies
+	healthServer.Shutdown()
+	time.Sleep(20 * time.Millisecond)
+	waitFor(healthpb.HealthCheckResponse_NOT_SERVING)
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/clientip"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// inProcessUsers answers GetCurrentUser with the caller and streams two pages
+// of users from ExportUsers.
+type inProcessUsers struct {
+	user_v1_pb.UnimplementedUserServiceServer
+}
+
+func (inProcessUsers) GetCurrentUser(
+	ctx context.Context,
+	_ *user_v1_pb.GetCurrentUserRequest,
+) (*user_v1_pb.GetCurrentUserResponse, error) {
+	userInfo, _ := ctx.Value(auth.ContextKeyUserInfo).(*auth.UserInfo)
+	md, _ := metadata.FromIncomingContext(ctx)
+	p, _ := peer.FromContext(ctx)
+	clientIP, _ := clientip.FromContext(ctx)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(
+		"x-seen-forwarded-for", md.Get("x-forwarded-for")[0],
+		"x-client-ip", clientIP,
+	))
+	_ = grpc.SetTrailer(ctx, metadata.Pairs("x-peer", p.Addr.String()))
+	return &user_v1_pb.GetCurrentUserResponse{
+		User: &user_v1_pb.User{Id: userInfo.UserID},
+	}, nil
+}
+
+func (inProcessUsers) ExportUsers(
+	_ *user_v1_pb.ExportUsersRequest,
+	stream user_v1_pb.UserService_ExportUsersServer,
+) error {
+	for _, id := range []string{"user-1", "user-2"} {
+		err := stream.Send(&user_v1_pb.ExportUsersResponse{
+			Users: []*user_v1_pb.User{{Id: id}},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestServeInProcess(t *testing.T) {
+	authCfg := configs.AuthConfig{JWTSecret: "test-secret", JWTValidMethods: []string{"HS256"}}
+	resolver, err := clientip.NewResolver(configs.DefaultTrustedProxies)
+	if err != nil {
+		t.Fatalf("NewResolver failed: %v", err)
+	}
+	srv := NewBuilder(configs.Config{Auth: authCfg}).
+		WithLogger(slog.New(slog.DiscardHandler)).
+		WithClientIPResolver(resolver).
+		Build()
+	user_v1_pb.RegisterUserServiceServer(srv, inProcessUsers{})
+	conn, err := ServeInProcess(srv)
+	if err != nil {
+		t.Fatalf("ServeInProcess failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+		srv.Stop()
+	})
+	client := user_v1_pb.NewUserServiceClient(conn)
+	token := func(role model.UserRole) context.Context {
+		signed, err := utils.NewUserTokenWithExpiration("user-1", role, authCfg.JWTSecret,
+			time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return metadata.AppendToOutgoingContext(context.Background(),
+			"authorization", "Bearer "+signed.Token, "x-forwarded-for", "198.51.100.9, 203.0.113.7")
+	}
+
+	// The interceptors run
+	_, err = client.GetCurrentUser(context.Background(), &user_v1_pb.GetCurrentUserRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated without token, got %v", err)
+	}
+
+	var header, trailer metadata.MD
+	resp, err := client.GetCurrentUser(token(model.UserRoleUser),
+		&user_v1_pb.GetCurrentUserRequest{}, grpc.Header(&header), grpc.Trailer(&trailer))
+	if err != nil {
+		t.Fatalf("GetCurrentUser failed: %v", err)
+	}
+	if resp.GetUser().GetId() != "user-1" {
+		t.Errorf("expected the caller, got %v", resp.GetUser())
+	}
+	if got := header.Get("x-seen-forwarded-for"); len(got) != 1 ||
+		got[0] != "198.51.100.9, 203.0.113.7" {
+		t.Errorf("expected the forwarded addresses in the header, got %v", header)
+	}
+	// The gateway appends the address it saw; hops on the left are the client's
+	if got := header.Get("x-client-ip"); len(got) != 1 || got[0] != "203.0.113.7" {
+		t.Errorf("expected the right-most hop as the client address, got %v", header)
+	}
+	if got := trailer.Get("x-peer"); len(got) != 1 || got[0] != "inprocess" {
+		t.Errorf("expected the in-process peer in the trailer, got %v", trailer)
+	}
+
+	// Streams run the stream interceptors too
+	stream, err := client.ExportUsers(token(model.UserRoleUser), &user_v1_pb.ExportUsersRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for a user, got %v", err)
+	}
+	stream, err = client.ExportUsers(token(model.UserRoleAdmin), &user_v1_pb.ExportUsersRequest{})
+	if err != nil {
+		t.Fatalf("ExportUsers failed: %v", err)
+	}
+	var ids []string
+	for {
+		page, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		ids = append(ids, page.GetUsers()[0].GetId())
+	}
+	if len(ids) != 2 || ids[0] != "user-1" || ids[1] != "user-2" {
+		t.Errorf("expected both pages, got %v", ids)
+	}
+
+	_, err = client.DeleteUser(token(model.UserRoleAdmin), &user_v1_pb.DeleteUserRequest{})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("expected Unimplemented from the service, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	handledCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "gRPC calls completed by the server, by method and status code.",
+	}, []string{"method", "code"})
+	handlingSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Time spent handling gRPC calls, by method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+)
+
+func metricsInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	handlingSeconds.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+	handledCalls.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+	return resp, err
+}
+
+func metricsStreamInterceptor(
+	srv any,
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	start := time.Now()
+	err := handler(srv, stream)
+	handlingSeconds.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+	handledCalls.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+	return err
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/tap"
+)
+
+var (
+	peersBlocked = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "auth_peer_guard_blocked_total",
+		Help: "Peers blocked by the peer guard for too many unauthenticated calls.",
+	})
+	peerGuardRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_peer_guard_rejected_total",
+		Help: "Calls and connections of blocked peers refused by the peer guard, by kind.",
+	}, []string{"kind"})
+)
+
+// maxTrackedPeers bounds the memory of the peer guard; peers beyond it are not
+// counted until expired entries are pruned.
+const maxTrackedPeers = 100_000
+
+var errPeerBlocked = status.Error(codes.ResourceExhausted, "too many unauthenticated calls")
+
+// PeerGuard protects the gRPC port from peers calling it directly, bypassing
+// the gateway, to guess credentials: a peer whose calls fail as
+// unauthenticated more than the limit within the window is blocked for a
+// while. The calls of blocked peers are refused before they are decoded and
+// their new connections are closed as soon as they are accepted. Counts are
+// kept per server, as are the connections they protect.
+type PeerGuard struct {
+	cfg    configs.PeerGuardConfig
+	exempt []netip.Prefix
+	now    func() time.Time
+
+	mu         sync.Mutex
+	peers      map[netip.Prefix]*peerStrikes
+	lastPruned time.Time
+}
+
+type peerStrikes struct {
+	count        int
+	windowStart  time.Time
+	blockedUntil time.Time
+}
+
+// NewPeerGuard returns a guard for cfg; it fails if an exempt CIDR is invalid.
+func NewPeerGuard(cfg configs.PeerGuardConfig) (*PeerGuard, error) {
+	exempt := make([]netip.Prefix, 0, len(cfg.ExemptCIDRs))
+	for _, cidr := range cfg.ExemptCIDRs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exempt CIDR %q: %w", cidr, err)
+		}
+		exempt = append(exempt, prefix.Masked())
+	}
+	return &PeerGuard{
+		cfg:    cfg,
+		exempt: exempt,
+		now:    time.Now,
+		peers:  make(map[netip.Prefix]*peerStrikes),
+	}, nil
+}
+
+// peerKey returns the key a peer is counted under, false if it is exempt or
+// not an IP peer. IPv6 peers are counted by /64, which a single client usually
+// has to itself.
+func (g *PeerGuard) peerKey(addr net.Addr) (netip.Prefix, bool) {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return netip.Prefix{}, false
+	}
+	ip, ok := netip.AddrFromSlice(tcpAddr.IP)
+	if !ok {
+		return netip.Prefix{}, false
+	}
+	ip = ip.Unmap()
+	for _, prefix := range g.exempt {
+		if prefix.Contains(ip) {
+			return netip.Prefix{}, false
+		}
+	}
+	bits := 32
+	if ip.Is6() {
+		bits = 64
+	}
+	prefix, _ := ip.Prefix(bits)
+	return prefix, true
+}
+
+// Blocked reports whether the peer at addr is blocked.
+func (g *PeerGuard) Blocked(addr net.Addr) bool {
+	key, ok := g.peerKey(addr)
+	if !ok {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	strikes := g.peers[key]
+	return strikes != nil && g.now().Before(strikes.blockedUntil)
+}
+
+// strike counts an unauthenticated call of the peer at addr, blocking it once
+// it exceeds the limit.
+func (g *PeerGuard) strike(addr net.Addr) {
+	key, ok := g.peerKey(addr)
+	if !ok {
+		return
+	}
+	now := g.now()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.prune(now)
+	strikes := g.peers[key]
+	if strikes == nil {
+		if len(g.peers) >= maxTrackedPeers {
+			return
+		}
+		strikes = &peerStrikes{}
+		g.peers[key] = strikes
+	}
+	if now.Before(strikes.blockedUntil) {
+		return
+	}
+	if now.Sub(strikes.windowStart) >= g.cfg.Window {
+		strikes.count = 0
+		strikes.windowStart = now
+	}
+	strikes.count++
+	if strikes.count > g.cfg.Limit {
+		strikes.blockedUntil = now.Add(g.cfg.BlockFor)
+		strikes.count = 0
+		peersBlocked.Inc()
+		slog.Warn("peer blocked for unauthenticated calls", "peer", key, "until",
+			strikes.blockedUntil)
+	}
+}
+
+// prune drops the peers whose window and block are over, at most once per
+// window.
+func (g *PeerGuard) prune(now time.Time) {
+	if now.Sub(g.lastPruned) < g.cfg.Window {
+		return
+	}
+	g.lastPruned = now
+	for key, strikes := range g.peers {
+		if now.Sub(strikes.windowStart) >= g.cfg.Window && !now.Before(strikes.blockedUntil) {
+			delete(g.peers, key)
+		}
+	}
+}
+
+// TapHandle refuses the calls of blocked peers before a stream is created for
+// them; it is meant for grpc.InTapHandle.
+func (g *PeerGuard) TapHandle(ctx context.Context, _ *tap.Info) (context.Context, error) {
+	if p, ok := peer.FromContext(ctx); ok && g.Blocked(p.Addr) {
+		peerGuardRejected.WithLabelValues("call").Inc()
+		return ctx, errPeerBlocked
+	}
+	return ctx, nil
+}
+
+// TagRPC implements stats.Handler.
+func (g *PeerGuard) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC counts the calls that end as unauthenticated against their peer.
+func (g *PeerGuard) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	end, ok := s.(*stats.End)
+	if !ok || status.Code(end.Error) != codes.Unauthenticated {
+		return
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		g.strike(p.Addr)
+	}
+}
+
+// TagConn implements stats.Handler.
+func (g *PeerGuard) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn implements stats.Handler.
+func (g *PeerGuard) HandleConn(context.Context, stats.ConnStats) {}
+
+// Listener closes the connections of blocked peers as soon as lis accepts
+// them, before any TLS or HTTP/2 handshake.
+func (g *PeerGuard) Listener(lis net.Listener) net.Listener {
+	return &guardedListener{Listener: lis, guard: g}
+}
+
+type guardedListener struct {
+	net.Listener
+	guard *PeerGuard
+}
+
+func (l *guardedListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if !l.guard.Blocked(conn.RemoteAddr()) {
+			return conn, nil
+		}
+		peerGuardRejected.WithLabelValues("connection").Inc()
+		_ = conn.Close()
+	}
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestPeerGuard(t *testing.T) {
+	cfg := configs.Config{Auth: configs.AuthConfig{JWTSecret: "test-secret"}}
+	cfg.Server.PeerGuard = configs.PeerGuardConfig{
+		Enabled:  true,
+		Limit:    2,
+		Window:   time.Minute,
+		BlockFor: time.Minute,
+	}
+	guard, err := NewPeerGuard(cfg.Server.PeerGuard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := NewBuilder(cfg).
+		WithLogger(slog.New(slog.DiscardHandler)).
+		WithPeerGuard(guard).
+		Build()
+	user_v1_pb.RegisterUserServiceServer(grpcServer, user_v1_pb.UnimplementedUserServiceServer{})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = grpcServer.Serve(guard.Listener(lis)) }()
+	t.Cleanup(grpcServer.Stop)
+
+	dial := func() user_v1_pb.UserServiceClient {
+		conn, err := grpc.NewClient(
+			lis.Addr().String(),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		return user_v1_pb.NewUserServiceClient(conn)
+	}
+	getCurrentUser := func(client user_v1_pb.UserServiceClient) codes.Code {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err := client.GetCurrentUser(ctx, &user_v1_pb.GetCurrentUserRequest{})
+		return status.Code(err)
+	}
+
+	client := dial()
+	for range 3 {
+		if code := getCurrentUser(client); code != codes.Unauthenticated {
+			t.Fatalf("expected Unauthenticated, got %v", code)
+		}
+	}
+	// Calls on the open connection are refused, and new connections closed
+	if code := getCurrentUser(client); code != codes.ResourceExhausted {
+		t.Errorf("expected the blocked peer's call to be refused, got %v", code)
+	}
+	if code := getCurrentUser(dial()); code != codes.Unavailable {
+		t.Errorf("expected the blocked peer's connection to be closed, got %v", code)
+	}
+}
+
+func TestPeerGuardStrikes(t *testing.T) {
+	guard, err := NewPeerGuard(configs.PeerGuardConfig{
+		Enabled:     true,
+		Limit:       1,
+		Window:      time.Minute,
+		BlockFor:    5 * time.Minute,
+		ExemptCIDRs: []string{"10.0.0.0/8"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	guard.now = func() time.Time { return now }
+	addr := func(ip string) net.Addr {
+		return &net.TCPAddr{IP: net.ParseIP(ip), Port: 1234}
+	}
+
+	guard.strike(addr("203.0.113.1"))
+	guard.strike(addr("203.0.113.2"))
+	if guard.Blocked(addr("203.0.113.1")) {
+		t.Error("expected a peer within the limit not to be blocked")
+	}
+	// Strikes of the same window add up, those of earlier windows don't
+	now = now.Add(time.Minute)
+	guard.strike(addr("203.0.113.1"))
+	guard.strike(addr("203.0.113.1"))
+	if !guard.Blocked(addr("203.0.113.1")) || guard.Blocked(addr("203.0.113.2")) {
+		t.Error("expected only the peer over the limit to be blocked")
+	}
+	now = now.Add(5 * time.Minute)
+	if guard.Blocked(addr("203.0.113.1")) {
+		t.Error("expected the block to end")
+	}
+
+	// IPv6 peers are counted by /64, and exempt networks never
+	guard.strike(addr("2001:db8::1"))
+	guard.strike(addr("2001:db8::2"))
+	if !guard.Blocked(addr("2001:db8::3")) {
+		t.Error("expected the /64 of the peer to be blocked")
+	}
+	for range 5 {
+		guard.strike(addr("10.1.2.3"))
+	}
+	if guard.Blocked(addr("10.1.2.3")) {
+		t.Error("expected the exempt peer not to be blocked")
+	}
+
+	invalid := configs.PeerGuardConfig{ExemptCIDRs: []string{"10.0.0.0"}}
+	if _, err := NewPeerGuard(invalid); err == nil {
+		t.Error("expected an invalid CIDR to be rejected")
+	}
+}
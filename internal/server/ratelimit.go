@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// rateLimitInterceptor rejects calls beyond perSecond across all clients, after
+// a burst of up to burst calls (perSecond if burst is not positive). Per-client
+// limits for logins are left to the login throttle.
+func rateLimitInterceptor(perSecond, burst int) grpc.UnaryServerInterceptor {
+	limiter := newLimiter(perSecond, burst)
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		if !limiter.Allow() {
+			return nil, errRateLimited
+		}
+		return handler(ctx, req)
+	}
+}
+
+// rateLimitStreamInterceptor limits the streams opened like rateLimitInterceptor.
+func rateLimitStreamInterceptor(perSecond, burst int) grpc.StreamServerInterceptor {
+	limiter := newLimiter(perSecond, burst)
+	return func(
+		srv any,
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if !limiter.Allow() {
+			return errRateLimited
+		}
+		return handler(srv, stream)
+	}
+}
+
+var errRateLimited = status.Error(codes.ResourceExhausted, "too many requests, retry later")
+
+func newLimiter(perSecond, burst int) *rate.Limiter {
+	if burst <= 0 {
+		burst = perSecond
+	}
+	return rate.NewLimiter(rate.Limit(perSecond), burst)
+}
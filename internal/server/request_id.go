@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"github.com/poly-workshop/auth-portal/internal/logctx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestIDHeader carries the request ID in both directions; the gateway
+// forwards the X-Request-Id header of HTTP requests as it.
+const requestIDHeader = "x-request-id"
+
+// maxRequestIDLength bounds the request IDs taken from clients; a UUID is 36
+const maxRequestIDLength = 64
+
+// requestIDInterceptor puts the request ID sent by the client, or a new one,
+// into the context so that all log lines of the call carry it, and returns it
+// in the response headers.
+func requestIDInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	return handler(withRequestID(ctx), req)
+}
+
+// requestIDStreamInterceptor is requestIDInterceptor for streams.
+func requestIDStreamInterceptor(
+	srv any,
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	wrapped := middleware.WrapServerStream(stream)
+	wrapped.WrappedContext = withRequestID(stream.Context())
+	return handler(srv, wrapped)
+}
+
+func withRequestID(ctx context.Context) context.Context {
+	var requestID string
+	if ids := metadata.ValueFromIncomingContext(ctx, requestIDHeader); len(ids) > 0 {
+		requestID = ids[0]
+	}
+	if !validRequestID(requestID) {
+		requestID = uuid.NewString()
+	}
+	ctx = logctx.WithRequestID(ctx, requestID)
+	if grpc.ServerTransportStreamFromContext(ctx) == nil {
+		// Not a call of a gRPC server, e.g. in tests
+		return ctx
+	}
+	if err := grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, requestID)); err != nil {
+		slog.WarnContext(ctx, "failed to set request ID header", "error", err)
+	}
+	return ctx
+}
+
+// validRequestID reports whether a request ID sent by a client may be used:
+// it ends up in logs, response headers and metric exemplars, so it must be
+// short printable ASCII.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] <= ' ' || requestID[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,300 @@
+// Package server assembles the gRPC server of auth-portal: the interceptor
+// chain derived from the configuration, and the server around it.
+package server
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/clientip"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/service"
+	"github.com/poly-workshop/auth-portal/internal/slo"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// Builder assembles the gRPC server. Interceptors always run in this order:
+// request ID, client IP, recovery, logging, metrics (and SLIs), timeout, rate limit, auth
+// and audit, so that rejected calls are logged and counted too and the audit
+// log knows the caller.
+type Builder struct {
+	cfg            configs.Config
+	logger         *slog.Logger
+	clientIP       *clientip.Resolver
+	roleVersions   auth.RoleVersionGetter
+	sessionChecker auth.SessionChecker
+	auditRepo      repository.AuditRepository
+	activity       auth.ActivityRecorder
+	usage          auth.UsageMeter
+	peerGuard      *PeerGuard
+	methodAccess   *auth.MethodAccess
+	internal       *auth.InternalCredentials
+	nonces         auth.NonceStore
+	workload       *auth.WorkloadVerifier
+	slo            *slo.Recorder
+}
+
+func NewBuilder(cfg configs.Config) *Builder {
+	return &Builder{cfg: cfg, logger: slog.Default()}
+}
+
+// WithLogger logs calls and recovered panics to logger instead of slog.Default.
+func (b *Builder) WithLogger(logger *slog.Logger) *Builder {
+	b.logger = logger
+	return b
+}
+
+// WithClientIPResolver resolves the client addresses services see with
+// resolver, trusting the X-Forwarded-For hops of server.trusted_proxies;
+// without it services see the addresses of their peers.
+func (b *Builder) WithClientIPResolver(resolver *clientip.Resolver) *Builder {
+	b.clientIP = resolver
+	return b
+}
+
+// WithRoleVersions rejects tokens issued before their user's last role change.
+func (b *Builder) WithRoleVersions(roleVersions auth.RoleVersionGetter) *Builder {
+	b.roleVersions = roleVersions
+	return b
+}
+
+// WithSessionChecker checks that the sessions of tokens still exist, if
+// session.bound_tokens is enabled.
+func (b *Builder) WithSessionChecker(checker auth.SessionChecker) *Builder {
+	b.sessionChecker = checker
+	return b
+}
+
+// WithAuditRepository records audit events for RPCs with the audit.v1.audit
+// option; without it no such events are recorded.
+func (b *Builder) WithAuditRepository(auditRepo repository.AuditRepository) *Builder {
+	b.auditRepo = auditRepo
+	return b
+}
+
+// WithActivityRecorder reports the users of authenticated calls to recorder.
+func (b *Builder) WithActivityRecorder(recorder auth.ActivityRecorder) *Builder {
+	b.activity = recorder
+	return b
+}
+
+// WithUsageMeter counts the calls of internal credentials and enforces their
+// quotas, if usage.enabled is set.
+func (b *Builder) WithUsageMeter(meter auth.UsageMeter) *Builder {
+	b.usage = meter
+	return b
+}
+
+// WithPeerGuard blocks peers making too many unauthenticated calls, if
+// server.peer_guard.enabled is set. Connections are only refused if the
+// listener is wrapped with PeerGuard.Listener too.
+func (b *Builder) WithPeerGuard(guard *PeerGuard) *Builder {
+	b.peerGuard = guard
+	return b
+}
+
+// WithMethodAccess applies auth.public_methods and auth.disabled_methods, as
+// parsed by auth.NewMethodAccess.
+func (b *Builder) WithMethodAccess(access *auth.MethodAccess) *Builder {
+	b.methodAccess = access
+	return b
+}
+
+// WithInternalCredentials authenticates internal calls with the credentials
+// of auth.internal_token and auth.internal_credentials, as parsed by
+// auth.NewInternalCredentials.
+func (b *Builder) WithInternalCredentials(credentials *auth.InternalCredentials) *Builder {
+	b.internal = credentials
+	return b
+}
+
+// WithNonceStore accepts internal calls signed by the credentials of
+// WithInternalCredentials, claiming their nonces in nonces; without it signed
+// calls are refused.
+func (b *Builder) WithNonceStore(nonces auth.NonceStore) *Builder {
+	b.nonces = nonces
+	return b
+}
+
+// WithWorkloadVerifier accepts the workload tokens of auth.workload_issuers
+// for the internal credentials with their issuers and subjects.
+func (b *Builder) WithWorkloadVerifier(verifier *auth.WorkloadVerifier) *Builder {
+	b.workload = verifier
+	return b
+}
+
+// WithSLORecorder records the outcome of logins and the latency of token
+// issuance with recorder.
+func (b *Builder) WithSLORecorder(recorder *slo.Recorder) *Builder {
+	b.slo = recorder
+	return b
+}
+
+// UnaryInterceptors returns the interceptor chain in the order it runs.
+func (b *Builder) UnaryInterceptors() []grpc.UnaryServerInterceptor {
+	interceptors := []grpc.UnaryServerInterceptor{requestIDInterceptor}
+	if b.clientIP != nil {
+		interceptors = append(interceptors, clientip.UnaryServerInterceptor(b.clientIP))
+	}
+	interceptors = append(
+		interceptors,
+		recovery.UnaryServerInterceptor(
+			recovery.WithRecoveryHandlerContext(b.recoverPanic),
+		),
+		logging.UnaryServerInterceptor(InterceptorLogger(b.logger)),
+		metricsInterceptor,
+	)
+	if b.slo != nil {
+		interceptors = append(interceptors, sloInterceptor(b.slo))
+	}
+	interceptors = append(
+		interceptors,
+		timeoutInterceptor(b.cfg.Server.RPCTimeout, b.cfg.Server.RPCTimeoutByMethod),
+	)
+	if b.cfg.Server.RateLimitPerSecond > 0 {
+		interceptors = append(interceptors, rateLimitInterceptor(
+			b.cfg.Server.RateLimitPerSecond,
+			b.cfg.Server.RateLimitBurst,
+		))
+	}
+	// Audit runs around auth, so calls auth denies are audited too
+	if b.auditRepo != nil {
+		interceptors = append(interceptors, service.BuildAuditInterceptor(b.auditRepo))
+	}
+	interceptors = append(
+		interceptors,
+		auth.BuildAuthInterceptor(b.cfg.Auth.JWTSecret, b.authOptions()...),
+	)
+	if b.auditRepo != nil {
+		interceptors = append(interceptors, service.BuildAuditCallerInterceptor())
+	}
+	return interceptors
+}
+
+// StreamInterceptors returns the interceptor chain of streaming RPCs in the
+// order it runs; it matches UnaryInterceptors.
+func (b *Builder) StreamInterceptors() []grpc.StreamServerInterceptor {
+	interceptors := []grpc.StreamServerInterceptor{requestIDStreamInterceptor}
+	if b.clientIP != nil {
+		interceptors = append(interceptors, clientip.StreamServerInterceptor(b.clientIP))
+	}
+	interceptors = append(
+		interceptors,
+		recovery.StreamServerInterceptor(
+			recovery.WithRecoveryHandlerContext(b.recoverPanic),
+		),
+		logging.StreamServerInterceptor(InterceptorLogger(b.logger)),
+		metricsStreamInterceptor,
+		timeoutStreamInterceptor(b.cfg.Server.RPCTimeoutByMethod),
+	)
+	if b.cfg.Server.RateLimitPerSecond > 0 {
+		interceptors = append(interceptors, rateLimitStreamInterceptor(
+			b.cfg.Server.RateLimitPerSecond,
+			b.cfg.Server.RateLimitBurst,
+		))
+	}
+	if b.auditRepo != nil {
+		interceptors = append(interceptors, service.BuildAuditStreamInterceptor(b.auditRepo))
+	}
+	interceptors = append(
+		interceptors,
+		auth.BuildAuthStreamInterceptor(b.cfg.Auth.JWTSecret, b.authOptions()...),
+	)
+	if b.auditRepo != nil {
+		interceptors = append(interceptors, service.BuildAuditCallerStreamInterceptor())
+	}
+	return interceptors
+}
+
+// Build returns a server with the interceptor chains and server reflection;
+// the services are left to the caller to register.
+func (b *Builder) Build(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(
+		opts,
+		grpc.ChainUnaryInterceptor(b.UnaryInterceptors()...),
+		grpc.ChainStreamInterceptor(b.StreamInterceptors()...),
+		// Accept the keepalive pings of gateways, which may ping idle connections
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             configs.MinGRPCKeepalive,
+			PermitWithoutStream: true,
+		}),
+	)
+	if b.peerGuard != nil && b.cfg.Server.PeerGuard.Enabled {
+		opts = append(
+			opts,
+			grpc.InTapHandle(b.peerGuard.TapHandle),
+			grpc.StatsHandler(b.peerGuard),
+		)
+	}
+	server := grpc.NewServer(opts...)
+	reflection.Register(server)
+	return server
+}
+
+func (b *Builder) authOptions() []auth.InterceptorOption {
+	opts := []auth.InterceptorOption{
+		auth.WithTokenValidation(
+			b.cfg.Auth.JWTIssuer,
+			b.cfg.Auth.JWTAudience,
+			!b.cfg.Auth.JWTRejectLegacyTokens,
+		),
+		auth.WithValidMethods(b.cfg.Auth.JWTValidMethods...),
+		auth.WithLeeway(b.cfg.Auth.JWTLeeway),
+		auth.WithDecisionLog(b.cfg.Auth.AuthzDecisionLog, b.cfg.Auth.AuthzDryRun),
+		auth.WithOrgScopedMethods(service.OrgScopedMethods...),
+	}
+	if b.methodAccess != nil {
+		opts = append(opts, auth.WithMethodAccess(b.methodAccess))
+	}
+	if b.internal != nil {
+		opts = append(opts, auth.WithInternalCredentials(b.internal))
+	}
+	if b.workload != nil {
+		opts = append(opts, auth.WithWorkloadIdentity(b.workload))
+	}
+	if b.nonces != nil {
+		opts = append(opts, auth.WithSignedRequests(b.nonces, b.cfg.Auth.SignedRequestMaxSkew))
+	}
+	if b.roleVersions != nil {
+		opts = append(opts, auth.WithRoleVersions(b.roleVersions))
+	}
+	if b.activity != nil {
+		opts = append(opts, auth.WithActivityRecorder(b.activity))
+	}
+	if b.cfg.Usage.Enabled && b.usage != nil {
+		opts = append(opts, auth.WithUsageMeter(b.usage))
+	}
+	if b.cfg.Session.BoundTokens && b.sessionChecker != nil {
+		opts = append(
+			opts,
+			auth.WithSessionCheck(b.sessionChecker, b.cfg.Session.CheckCacheDuration),
+		)
+	}
+	return opts
+}
+
+// recoverPanic turns a panic in a handler into an Internal error, so one bad
+// request cannot take the server down. Logged at error level, the panic is
+// reported along with its stack if error reporting is configured.
+func (b *Builder) recoverPanic(ctx context.Context, p any) error {
+	b.logger.ErrorContext(ctx, "recovered from panic", "panic", p, "stack", string(debug.Stack()))
+	return status.Error(codes.Internal, "internal error")
+}
+
+// InterceptorLogger adapts slog to the logging interceptor.
+func InterceptorLogger(l *slog.Logger) logging.Logger {
+	return logging.LoggerFunc(
+		func(ctx context.Context, lvl logging.Level, msg string, fields ...any) {
+			l.Log(ctx, slog.Level(lvl), msg, fields...)
+		},
+	)
+}
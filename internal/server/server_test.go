@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/logctx"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// call runs handler behind an interceptor chain.
+func call(
+	interceptors []grpc.UnaryServerInterceptor,
+	ctx context.Context,
+	method string,
+	handler grpc.UnaryHandler,
+) error {
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	next := handler
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := interceptors[i], next
+		next = func(ctx context.Context, req any) (any, error) {
+			return interceptor(ctx, req, info, inner)
+		}
+	}
+	_, err := next(ctx, nil)
+	return err
+}
+
+func ok(context.Context, any) (any, error) { return "ok", nil }
+
+func TestBuilderInterceptors(t *testing.T) {
+	cfg := configs.Config{Auth: configs.AuthConfig{JWTSecret: "test-secret"}}
+	quiet := slog.New(slog.DiscardHandler)
+	chain := func(cfg configs.Config) []grpc.UnaryServerInterceptor {
+		return NewBuilder(cfg).WithLogger(quiet).UnaryInterceptors()
+	}
+	incoming := metadata.NewIncomingContext(context.Background(), metadata.MD{})
+
+	t.Run("public RPC without token", func(t *testing.T) {
+		err := call(chain(cfg), incoming, auth_v1_pb.AuthService_GetPublicConfig_FullMethodName, ok)
+		if err != nil {
+			t.Errorf("expected the call to pass, got %v", err)
+		}
+	})
+
+	t.Run("user RPC without token", func(t *testing.T) {
+		err := call(chain(cfg), incoming, user_v1_pb.UserService_GetCurrentUser_FullMethodName, ok)
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("expected Unauthenticated, got %v", err)
+		}
+	})
+
+	t.Run("request ID", func(t *testing.T) {
+		md := metadata.Pairs("x-request-id", "req-1")
+		var requestID string
+		handler := func(ctx context.Context, _ any) (any, error) {
+			requestID = logctx.RequestID(ctx)
+			return "ok", nil
+		}
+		method := auth_v1_pb.AuthService_GetPublicConfig_FullMethodName
+		if err := call(chain(cfg), metadata.NewIncomingContext(context.Background(), md), method,
+			handler); err != nil {
+			t.Fatalf("expected the call to pass, got %v", err)
+		}
+		if requestID != "req-1" {
+			t.Errorf("expected the client's request ID, got %q", requestID)
+		}
+		if err := call(chain(cfg), incoming, method, handler); err != nil || requestID == "" {
+			t.Errorf("expected a new request ID, got %q (%v)", requestID, err)
+		}
+		for _, invalid := range []string{strings.Repeat("x", 200), "req\xff", "req 1"} {
+			md := metadata.Pairs("x-request-id", invalid)
+			err := call(chain(cfg), metadata.NewIncomingContext(context.Background(), md), method,
+				handler)
+			if err != nil || requestID == invalid || requestID == "" {
+				t.Errorf("expected a new request ID instead of %q, got %q (%v)",
+					invalid, requestID, err)
+			}
+		}
+	})
+
+	t.Run("panic is recovered", func(t *testing.T) {
+		panics := func(context.Context, any) (any, error) { panic("boom") }
+		method := auth_v1_pb.AuthService_GetPublicConfig_FullMethodName
+		err := call(chain(cfg), incoming, method, panics)
+		if status.Code(err) != codes.Internal {
+			t.Errorf("expected Internal, got %v", err)
+		}
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		limited := cfg
+		limited.Server.RateLimitPerSecond = 1
+		b := chain(limited)
+		method := auth_v1_pb.AuthService_GetPublicConfig_FullMethodName
+		if err := call(b, incoming, method, ok); err != nil {
+			t.Fatalf("expected the first call to pass, got %v", err)
+		}
+		if err := call(b, incoming, method, ok); status.Code(err) != codes.ResourceExhausted {
+			t.Errorf("expected ResourceExhausted, got %v", err)
+		}
+	})
+
+	t.Run("default deadline", func(t *testing.T) {
+		bounded := cfg
+		bounded.Server.RPCTimeout = time.Hour
+		bounded.Server.RPCTimeoutByMethod = map[string]time.Duration{
+			"getpublicconfig": time.Millisecond,
+		}
+		method := auth_v1_pb.AuthService_GetPublicConfig_FullMethodName
+		var deadline time.Time
+		slow := func(ctx context.Context, _ any) (any, error) {
+			deadline, _ = ctx.Deadline()
+			<-ctx.Done()
+			return nil, status.Error(codes.Unavailable, "backend canceled")
+		}
+		err := call(chain(bounded), incoming, method, slow)
+		if status.Code(err) != codes.DeadlineExceeded {
+			t.Errorf("expected DeadlineExceeded, got %v", err)
+		}
+		if time.Until(deadline) > time.Minute {
+			t.Errorf("expected the per-method timeout, got deadline %v", deadline)
+		}
+
+		// Shorter deadlines of the client are kept
+		short, cancel := context.WithTimeout(incoming, time.Second)
+		defer cancel()
+		want, _ := short.Deadline()
+		keep := func(ctx context.Context, _ any) (any, error) {
+			deadline, _ = ctx.Deadline()
+			return "ok", nil
+		}
+		bounded.Server.RPCTimeoutByMethod = nil
+		if err := call(chain(bounded), short, method, keep); err != nil {
+			t.Fatalf("expected the call to pass, got %v", err)
+		}
+		if !deadline.Equal(want) {
+			t.Errorf("expected the client's deadline %v, got %v", want, deadline)
+		}
+	})
+
+	t.Run("audit runs around auth", func(t *testing.T) {
+		auditRepo := testutil.NewAuditRepository()
+		b := NewBuilder(cfg).WithLogger(quiet).WithAuditRepository(auditRepo).UnaryInterceptors()
+		method := user_v1_pb.UserService_DeleteUser_FullMethodName
+		err := call(b, incoming, method, ok)
+		if status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("expected Unauthenticated, got %v", err)
+		}
+		events := auditRepo.Events()
+		if len(events) != 1 || events[0].UserID != nil ||
+			!strings.Contains(events[0].Metadata, `"code":"Unauthenticated"`) {
+			t.Fatalf("expected the denied call to be audited without a caller, got %v", events)
+		}
+
+		signed, err := utils.NewUserTokenWithExpiration("admin-1", model.UserRoleAdmin,
+			cfg.Auth.JWTSecret, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		authorized := metadata.NewIncomingContext(context.Background(),
+			metadata.Pairs("authorization", "Bearer "+signed.Token))
+		if err := call(b, authorized, method, ok); err != nil {
+			t.Fatalf("expected the call to pass, got %v", err)
+		}
+		events = auditRepo.Events()
+		if len(events) != 2 || events[1].UserID == nil || *events[1].UserID != "admin-1" {
+			t.Errorf("expected the call to be audited with the caller auth identified, got %v",
+				events)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/slo"
+	"google.golang.org/grpc"
+)
+
+// loginMethods are the RPCs starting sessions, by the login method they are
+// counted as.
+var loginMethods = map[string]string{
+	auth_v1_pb.AuthService_LoginByOAuth_FullMethodName:       "oauth",
+	auth_v1_pb.AuthService_LoginByPassword_FullMethodName:    "password",
+	auth_v1_pb.AuthService_ConsumeMagicLink_FullMethodName:   "magic_link",
+	auth_v1_pb.AuthService_VerifyLoginCode_FullMethodName:    "login_code",
+	auth_v1_pb.AuthService_RedeemHandoffToken_FullMethodName: "handoff",
+}
+
+// sloInterceptor records the outcome of logins and the latency of the tokens
+// issued by GetUserToken with recorder.
+func sloInterceptor(recorder *slo.Recorder) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		if method, ok := loginMethods[info.FullMethod]; ok {
+			recorder.Login(ctx, method, err)
+		} else if info.FullMethod == auth_v1_pb.AuthService_GetUserToken_FullMethodName &&
+			err == nil {
+			recorder.TokenIssued(ctx, time.Since(start))
+		}
+		return resp, err
+	}
+}
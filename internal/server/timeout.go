@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// timeoutInterceptor bounds calls by the timeout configured for their method,
+// or defaultTimeout, so slow queries can't hold resources indefinitely. Shorter
+// deadlines of the client, e.g. propagated by the gateway, are kept.
+func timeoutInterceptor(
+	defaultTimeout time.Duration,
+	byMethod map[string]time.Duration,
+) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		timeout, ok := byMethod[methodKey(info.FullMethod)]
+		if !ok {
+			timeout = defaultTimeout
+		}
+		ctx, cancel := withTimeout(ctx, timeout)
+		defer cancel()
+		resp, err := handler(ctx, req)
+		return resp, deadlineError(ctx, err)
+	}
+}
+
+// timeoutStreamInterceptor is timeoutInterceptor for streams, which may
+// legitimately run long and are only bounded by a per-method timeout.
+func timeoutStreamInterceptor(byMethod map[string]time.Duration) grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		timeout, ok := byMethod[methodKey(info.FullMethod)]
+		if !ok {
+			return handler(srv, stream)
+		}
+		ctx, cancel := withTimeout(stream.Context(), timeout)
+		defer cancel()
+		wrapped := middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = ctx
+		return deadlineError(ctx, handler(srv, wrapped))
+	}
+}
+
+// methodKey is the key of a method in the timeout overrides, e.g. "listusers"
+// for "/user.v1.UserService/ListUsers"; configuration keys are case-insensitive.
+func methodKey(fullMethod string) string {
+	return strings.ToLower(path.Base(fullMethod))
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// deadlineError reports calls that failed because their deadline passed as
+// DeadlineExceeded, whatever error the handler made of it.
+func deadlineError(ctx context.Context, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) &&
+		status.Code(err) != codes.DeadlineExceeded {
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	}
+	return err
+}
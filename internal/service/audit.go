@@ -0,0 +1,178 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	audit_v1_pb "github.com/poly-workshop/auth-portal/gen/audit/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/rpcmeta"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// recordAuditEvent stores an audit event enriched with the caller's client
+// information; failures are logged but never fail the request.
+func recordAuditEvent(
+	ctx context.Context,
+	auditRepo repository.AuditRepository,
+	eventType model.AuditEventType,
+	userID *string,
+	metadata map[string]string,
+) {
+	event := &model.AuditEventModel{
+		Type:      eventType,
+		UserID:    userID,
+		IPAddress: extractIPAddress(ctx),
+		UserAgent: extractUserAgent(ctx),
+	}
+	if len(metadata) > 0 {
+		data, err := json.Marshal(metadata)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to marshal audit metadata", "error", err)
+		} else {
+			event.Metadata = string(data)
+		}
+	}
+	if err := auditRepo.Create(ctx, event); err != nil {
+		slog.WarnContext(ctx, "failed to record audit event", "error", err, "type", eventType)
+	}
+}
+
+// auditedCallKey is the context key of the *auditedCall of a call.
+type auditedCallKey struct{}
+
+// auditedCall carries the caller of an audited call, once the auth interceptor
+// identified it, back to the audit interceptor running before it.
+type auditedCall struct {
+	callerID string
+}
+
+// BuildAuditInterceptor records an rpc.called audit event for calls of RPCs
+// with the audit.v1.audit option, so services need no wiring of their own. It
+// has to run before the auth interceptor, so that the calls auth denies are
+// recorded too, and BuildAuditCallerInterceptor right after it, which passes
+// on the caller auth identified.
+func BuildAuditInterceptor(auditRepo repository.AuditRepository) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		call := &auditedCall{}
+		resp, err := handler(context.WithValue(ctx, auditedCallKey{}, call), req)
+		recordRPCCall(ctx, auditRepo, info.FullMethod, call, err)
+		return resp, err
+	}
+}
+
+// BuildAuditStreamInterceptor is BuildAuditInterceptor for streaming RPCs; the
+// event is recorded once the stream has ended.
+func BuildAuditStreamInterceptor(
+	auditRepo repository.AuditRepository,
+) grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		call := &auditedCall{}
+		wrapped := middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = context.WithValue(stream.Context(), auditedCallKey{}, call)
+		err := handler(srv, wrapped)
+		recordRPCCall(stream.Context(), auditRepo, info.FullMethod, call, err)
+		return err
+	}
+}
+
+// BuildAuditCallerInterceptor passes the caller the auth interceptor
+// identified on to BuildAuditInterceptor; it has to run right after auth.
+func BuildAuditCallerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		setAuditedCaller(ctx)
+		return handler(ctx, req)
+	}
+}
+
+// BuildAuditCallerStreamInterceptor is BuildAuditCallerInterceptor for
+// streaming RPCs.
+func BuildAuditCallerStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		setAuditedCaller(stream.Context())
+		return handler(srv, stream)
+	}
+}
+
+func setAuditedCaller(ctx context.Context) {
+	if call, ok := ctx.Value(auditedCallKey{}).(*auditedCall); ok {
+		call.callerID = callerID(ctx)
+	}
+}
+
+// recordRPCCall records the rpc.called event of a call of fullMethod that
+// ended with err, if the RPC's audit option asks for it. The caller is the one
+// passed on in call, or else the one in ctx; calls denied before the caller
+// was identified have none.
+func recordRPCCall(
+	ctx context.Context,
+	auditRepo repository.AuditRepository,
+	fullMethod string,
+	call *auditedCall,
+	err error,
+) {
+	audit := methodAudit(fullMethod)
+	if audit == nil || !audit.Log {
+		return
+	}
+	sensitivity := max(audit.Sensitivity, audit_v1_pb.Sensitivity_SENSITIVITY_LOW)
+	if err != nil && sensitivity < audit_v1_pb.Sensitivity_SENSITIVITY_MEDIUM {
+		return
+	}
+	var userID *string
+	if id := cmp.Or(call.callerID, callerID(ctx)); id != "" {
+		userID = &id
+	}
+	recordAuditEvent(ctx, auditRepo, model.AuditEventRPCCalled, userID, map[string]string{
+		"method": fullMethod,
+		"code":   status.Code(err).String(),
+		"sensitivity": strings.ToLower(
+			strings.TrimPrefix(sensitivity.String(), "SENSITIVITY_"),
+		),
+	})
+}
+
+// methodAuditCache maps full method names to their *audit_v1_pb.MethodAudit,
+// nil for RPCs without the option.
+var methodAuditCache sync.Map
+
+func methodAudit(fullMethod string) *audit_v1_pb.MethodAudit {
+	if cached, ok := methodAuditCache.Load(fullMethod); ok {
+		return cached.(*audit_v1_pb.MethodAudit)
+	}
+	var audit *audit_v1_pb.MethodAudit
+	if method, ok := rpcmeta.Method(fullMethod); ok {
+		ext := proto.GetExtension(method.Options(), audit_v1_pb.E_Audit)
+		audit, _ = ext.(*audit_v1_pb.MethodAudit)
+	}
+	methodAuditCache.Store(fullMethod, audit)
+	return audit
+}
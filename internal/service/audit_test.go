@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuditInterceptor(t *testing.T) {
+	ctx := context.WithValue(
+		context.Background(),
+		auth.ContextKeyUserInfo,
+		&auth.UserInfo{UserID: "admin-1"},
+	)
+	ok := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	denied := func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		handler  grpc.UnaryHandler
+		recorded bool
+		code     string
+	}{
+		{"unannotated RPC", user_v1_pb.UserService_GetCurrentUser_FullMethodName, ok, false, ""},
+		{
+			"low sensitivity",
+			user_v1_pb.UserService_AdminListFeatureFlags_FullMethodName,
+			ok,
+			true,
+			"OK",
+		},
+		{
+			"low sensitivity failure",
+			user_v1_pb.UserService_AdminListFeatureFlags_FullMethodName,
+			denied,
+			false,
+			"",
+		},
+		{"high sensitivity", user_v1_pb.UserService_DeleteUser_FullMethodName, ok, true, "OK"},
+		{
+			"high sensitivity failure",
+			user_v1_pb.UserService_DeleteUser_FullMethodName,
+			denied,
+			true,
+			"NotFound",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditRepo := testutil.NewAuditRepository()
+			interceptor := BuildAuditInterceptor(auditRepo)
+			info := &grpc.UnaryServerInfo{FullMethod: tt.method}
+			_, _ = interceptor(ctx, nil, info, tt.handler)
+
+			events := auditRepo.Events()
+			if !tt.recorded {
+				if len(events) != 0 {
+					t.Errorf("expected no audit event, got %d", len(events))
+				}
+				return
+			}
+			if len(events) != 1 || events[0].Type != model.AuditEventRPCCalled {
+				t.Fatalf("expected one rpc.called event, got %v", events)
+			}
+			if events[0].UserID == nil || *events[0].UserID != "admin-1" {
+				t.Errorf("expected the caller as user, got %v", events[0].UserID)
+			}
+			var metadata map[string]string
+			_ = json.Unmarshal([]byte(events[0].Metadata), &metadata)
+			if metadata["method"] != tt.method || metadata["code"] != tt.code {
+				t.Errorf("unexpected metadata %v", metadata)
+			}
+		})
+	}
+}
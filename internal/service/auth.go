@@ -5,25 +5,49 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	mathrand "math/rand/v2"
 	"net"
+	"net/http"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/casbin/casbin/v2"
 	"github.com/poly-workshop/auth-portal/configs"
 	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/activity"
+	"github.com/poly-workshop/auth-portal/internal/captcha"
+	"github.com/poly-workshop/auth-portal/internal/clientip"
+	"github.com/poly-workshop/auth-portal/internal/customclaims"
+	"github.com/poly-workshop/auth-portal/internal/featureflags"
+	"github.com/poly-workshop/auth-portal/internal/fieldcrypt"
+	"github.com/poly-workshop/auth-portal/internal/logctx"
+	"github.com/poly-workshop/auth-portal/internal/logincode"
+	"github.com/poly-workshop/auth-portal/internal/mailer"
 	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/notify"
+	"github.com/poly-workshop/auth-portal/internal/outbound"
 	providerPkg "github.com/poly-workshop/auth-portal/internal/provider"
+	"github.com/poly-workshop/auth-portal/internal/provisioning"
 	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/risk"
+	"github.com/poly-workshop/auth-portal/internal/slo"
+	"github.com/poly-workshop/auth-portal/internal/throttle"
 	"github.com/poly-workshop/auth-portal/internal/utils"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
 	"github.com/redis/go-redis/v9"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/github"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"gorm.io/gorm"
 )
@@ -44,39 +68,247 @@ type AuthService interface {
 	LoginByOAuth(ctx context.Context, req *auth_v1_pb.LoginByOAuthRequest) (*auth_v1_pb.LoginByPasswordResponse, error)
 	LoginByPassword(ctx context.Context, req *auth_v1_pb.LoginByPasswordRequest) (*auth_v1_pb.LoginByPasswordResponse, error)
 	GetUserToken(ctx context.Context, req *auth_v1_pb.GetUserTokenRequest) (*auth_v1_pb.GetUserTokenResponse, error)
+	GetPublicConfig(ctx context.Context, req *auth_v1_pb.GetPublicConfigRequest) (*auth_v1_pb.GetPublicConfigResponse, error)
 }
 
 type authService struct {
 	db           *gorm.DB
 	rdb          redis.UniversalClient
 	userRepo     repository.UserRepository
-	config       configs.Config
-	oauthConfigs map[string]*oauth2.Config
+	sessionRepo  repository.SessionRepository
+	auditRepo    repository.AuditRepository
+	roleVersions repository.RoleVersionRepository
+	tenants      repository.TenantSettingsRepository
+	throttle     *throttle.LoginThrottle
+	emailChecks  *throttle.RateLimiter
+	captcha      captcha.Verifier
+	activity     *activity.Tracker
+	risk         *risk.Scorer
+	enforcer     *casbin.SyncedEnforcer
+	flags        *featureflags.Store
+	inviteRepo   repository.InviteRepository
+	deviceAuths  repository.DeviceAuthorizationRepository
+	magicLinks   repository.MagicLinkRepository
+	loginCodes   repository.LoginCodeRepository
+	handoffs     repository.HandoffTokenRepository
+	idempotency  repository.IdempotencyRepository
+	slo          *slo.Recorder
+	notifier     *notify.Notifier
+	provisioners provisioning.Hooks
+	customClaims *customclaims.Enricher
+	// codeChannels deliver login codes by name; empty if login codes are disabled
+	codeChannels map[string]logincode.Channel
+	mailer       mailer.Mailer
+	// providerTokens is nil unless provider tokens are stored
+	providerTokens repository.ProviderTokenRepository
+	// magicLinkLimit bounds the magic links mailed to an address
+	magicLinkLimit *throttle.RateLimiter
+	// loginCodeLimit bounds the login codes sent to an address
+	loginCodeLimit *throttle.RateLimiter
+	config         configs.Config
+	oauthConfigs   map[string]*oauth2.Config
+	// loginWarnings samples the warnings of failed logins per client network
+	loginWarnings *logctx.Sampler
+	// userProviders overrides the user info providers of oauthConfigs in tests
+	userProviders map[string]providerPkg.UserProvider
+	// httpClient calls the OAuth providers
+	httpClient *http.Client
+	// userInfoGuard bounds, retries and circuit-breaks user info calls
+	userInfoGuard *providerPkg.Guard
 	auth_v1_pb.UnimplementedAuthServiceServer
 }
 
+// AuthServiceOption customizes the auth service, e.g. in builds of the server
+// adding their own extensions.
+type AuthServiceOption func(*authServiceOptions)
+
+type authServiceOptions struct {
+	claimsProviders []customclaims.Provider
+	userRepo        repository.UserRepository
+	httpClient      *http.Client
+}
+
+// WithClaimsProvider adds the claims of provider to the user tokens issued,
+// after those of claims.callout_url.
+func WithClaimsProvider(provider customclaims.Provider) AuthServiceOption {
+	return func(o *authServiceOptions) {
+		o.claimsProviders = append(o.claimsProviders, provider)
+	}
+}
+
+// WithUserRepository stores users in repo, e.g. the backend selected by
+// user_store.backend (see repository.OpenUserRepository), instead of the
+// database.
+func WithUserRepository(repo repository.UserRepository) AuthServiceOption {
+	return func(o *authServiceOptions) {
+		o.userRepo = repo
+	}
+}
+
+// WithHTTPClient calls the OAuth providers with client, e.g. the one configured
+// by the outbound section (see outbound.NewClient), instead of a client going
+// to them directly.
+func WithHTTPClient(client *http.Client) AuthServiceOption {
+	return func(o *authServiceOptions) {
+		o.httpClient = client
+	}
+}
+
+// OAuthConfigs returns the OAuth client configurations of the providers, by
+// provider name.
+func OAuthConfigs(cfg configs.AuthConfig) map[string]*oauth2.Config {
+	githubScopes := []string{"user:email"}
+	if len(cfg.GithubAllowedOrgs) > 0 || len(cfg.GithubAllowedTeams) > 0 {
+		// Membership checks need to read the user's orgs and teams
+		githubScopes = append(githubScopes, "read:org")
+	}
+	githubScopes = append(githubScopes, cfg.GithubExtraScopes...)
+	githubEndpoint := github.Endpoint
+	if baseURL := strings.TrimSuffix(cfg.GithubBaseURL, "/"); baseURL != "" {
+		githubEndpoint = oauth2.Endpoint{
+			AuthURL:  baseURL + "/login/oauth/authorize",
+			TokenURL: baseURL + "/login/oauth/access_token",
+		}
+	}
+	return map[string]*oauth2.Config{
+		"github": {
+			ClientID:     cfg.GithubClientID,
+			ClientSecret: cfg.GithubClientSecret,
+			Scopes:       githubScopes,
+			Endpoint:     githubEndpoint,
+			RedirectURL:  cfg.GithubRedirectURL,
+		},
+	}
+}
+
 func NewAuthService(
 	db *gorm.DB,
 	rdb redis.UniversalClient,
+	auditRepo repository.AuditRepository,
+	tenants repository.TenantSettingsRepository,
+	flags *featureflags.Store,
+	mail mailer.Mailer,
+	opts ...AuthServiceOption,
 ) auth_v1_pb.AuthServiceServer {
 	config := configs.Load()
+	var options authServiceOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 
-	// Initialize OAuth configurations
-	oauthConfigs := make(map[string]*oauth2.Config)
-	oauthConfigs["github"] = &oauth2.Config{
-		ClientID:     config.Auth.GithubClientID,
-		ClientSecret: config.Auth.GithubClientSecret,
-		Scopes:       []string{"user:email"},
-		Endpoint:     github.Endpoint,
-		RedirectURL:  config.Auth.GithubRedirectURL,
+	oauthConfigs := OAuthConfigs(config.Auth)
+	httpClient := options.httpClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: config.Outbound.Timeout}
 	}
 
+	// The enforcer derives the scope claim; tokens go without it if the policy is missing
+	enforcer, err := auth.SharedEnforcer()
+	if err != nil {
+		slog.Warn("failed to create enforcer, issuing tokens without scopes", "error", err)
+	}
+
+	loginThrottle := throttle.NewLoginThrottle(rdb, config.Throttle)
+	emailChecks := throttle.NewRateLimiter(
+		rdb,
+		"email_check",
+		config.Account.EmailCheckPerMinute,
+		time.Minute,
+	)
+	magicLinkLimit := throttle.NewRateLimiter(
+		rdb,
+		"magic_link",
+		config.Auth.MagicLinksPerHour,
+		time.Hour,
+	)
+	codeChannels, err := logincode.NewChannels(config.LoginCode, mail)
+	if err != nil {
+		slog.Error("invalid login code configuration, login codes are disabled", "error", err)
+	}
+	provisioners, err := provisioning.NewHooks(config.Provisioning)
+	if err != nil {
+		slog.Error("invalid provisioning configuration, OAuth signups fail", "error", err)
+		configErr := err
+		provisioners = provisioning.Hooks{provisioning.HookFunc(
+			func(context.Context, provisioning.Identity, *model.UserModel) error {
+				return configErr
+			},
+		)}
+	}
+	var claimsProviders []customclaims.Provider
+	if callout := customclaims.NewCallout(config.Claims); callout != nil {
+		claimsProviders = append(claimsProviders, callout)
+	}
+	claimsProviders = append(claimsProviders, options.claimsProviders...)
+	var customClaims *customclaims.Enricher
+	if len(claimsProviders) > 0 {
+		customClaims = customclaims.NewEnricher(rdb, config.Claims, claimsProviders...)
+	}
+	var providerTokens repository.ProviderTokenRepository
+	if config.Auth.StoreProviderTokens {
+		// Tokens are encrypted with the encryption keys if configured, with
+		// the provider token key otherwise
+		if config.Auth.ProviderTokenKey == "" && fieldcrypt.Installed() == nil {
+			slog.Warn(
+				"neither auth.provider_token_key nor encryption.keys are set, " +
+					"provider tokens are not stored",
+			)
+		} else {
+			providerTokens = repository.NewProviderTokenRepository(
+				db,
+				config.Auth.ProviderTokenKey,
+			)
+		}
+	}
+	userRepo := options.userRepo
+	if userRepo == nil {
+		userRepo = repository.NewUserRepository(db)
+	}
+	sessionRepo := repository.NewSessionRepository(
+		rdb,
+		repository.WithTTLJitter(config.Session.TTLJitter),
+	)
+	notifier := notify.NewNotifier(mail, repository.NewNotificationPreferencesRepository(db))
 	return &authService{
-		db:           db,
-		rdb:          rdb,
-		userRepo:     repository.NewUserRepository(db),
-		config:       config,
-		oauthConfigs: oauthConfigs,
+		db:             db,
+		rdb:            rdb,
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		auditRepo:      auditRepo,
+		roleVersions:   repository.NewRoleVersionRepository(rdb),
+		tenants:        tenants,
+		throttle:       loginThrottle,
+		emailChecks:    emailChecks,
+		magicLinkLimit: magicLinkLimit,
+		captcha:        captcha.NewVerifier(config.Captcha),
+		activity:       activity.NewTracker(rdb, userRepo, config.Account.LastSeenInterval),
+		risk:           risk.NewScorer(rdb, loginThrottle, config.Risk),
+		enforcer:       enforcer,
+		flags:          flags,
+		inviteRepo:     repository.NewInviteRepository(rdb),
+		deviceAuths:    repository.NewDeviceAuthorizationRepository(rdb),
+		magicLinks:     repository.NewMagicLinkRepository(rdb),
+		loginCodes:     repository.NewLoginCodeRepository(rdb),
+		handoffs:       repository.NewHandoffTokenRepository(rdb),
+		idempotency:    repository.NewIdempotencyRepository(rdb),
+		slo:            slo.NewRecorder(rdb, config.SLO),
+		notifier:       notifier,
+		provisioners:   provisioners,
+		customClaims:   customClaims,
+		codeChannels:   codeChannels,
+		loginCodeLimit: throttle.NewRateLimiter(
+			rdb,
+			"login_code",
+			config.LoginCode.SendsPerHour,
+			time.Hour,
+		),
+		mailer:         mail,
+		providerTokens: providerTokens,
+		config:         config,
+		oauthConfigs:   oauthConfigs,
+		httpClient:     httpClient,
+		userInfoGuard:  providerPkg.NewGuard(config.UserInfo),
+		loginWarnings:  logctx.NewSampler(config.Log.WarnSamplesPerMinute),
 	}
 }
 
@@ -109,7 +341,7 @@ func (s *authService) generateState(
 		return "", status.Errorf(codes.Internal, "failed to marshal state data: %v", err)
 	}
 
-	stateKey := fmt.Sprintf("oauth_state:%s", state)
+	stateKey := repository.OAuthStateKey(state)
 	err = s.rdb.Set(ctx, stateKey, string(dataBytes), s.config.Auth.OAuthStateExpirationDuration).Err()
 	if err != nil {
 		return "", status.Errorf(codes.Internal, "failed to store state: %v", err)
@@ -118,15 +350,21 @@ func (s *authService) generateState(
 	return state, nil
 }
 
-func (s *authService) validateState(
+// consumeState atomically reads and deletes the state, so each state can redeem
+// at most one login even if the callback is replayed concurrently. A state that
+// fails validation is consumed as well.
+func (s *authService) consumeState(
 	ctx context.Context,
 	state, userAgent, ipAddress string,
 ) (*OAuthStateData, error) {
-	stateKey := fmt.Sprintf("oauth_state:%s", state)
-	dataStr, err := s.rdb.Get(ctx, stateKey).Result()
-	if err != nil {
+	stateKey := repository.OAuthStateKey(state)
+	dataStr, err := s.rdb.GetDel(ctx, stateKey).Result()
+	if errors.Is(err, redis.Nil) {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid or expired state")
 	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to consume state: %v", err)
+	}
 
 	var stateData OAuthStateData
 	if err := json.Unmarshal([]byte(dataStr), &stateData); err != nil {
@@ -135,64 +373,116 @@ func (s *authService) validateState(
 
 	// Validate expiration
 	if time.Now().After(stateData.ExpiresAt) {
-		// Clean up expired state
-		err = s.deleteState(ctx, state)
-		if err != nil {
-			slog.ErrorContext(ctx, "failed to delete expired oauth state",
-				"error", err,
-				"state_prefix", state[:min(16, len(state))],
-			)
-		}
 		return nil, status.Errorf(codes.InvalidArgument, "state has expired")
 	}
 
-	// Validate user agent if provided during creation (optional but recommended)
+	if err := s.checkStateBinding(ctx, &stateData, userAgent, ipAddress); err != nil {
+		return nil, err
+	}
+
+	return &stateData, nil
+}
+
+// checkStateBinding compares the client that requested the code URL with the
+// one completing the login. Mismatches are recorded as audit events and, unless
+// the binding is relaxed, reject the login.
+func (s *authService) checkStateBinding(
+	ctx context.Context,
+	stateData *OAuthStateData,
+	userAgent, ipAddress string,
+) error {
+	binding := s.config.Auth.OAuthStateBinding
+	if binding == configs.StateBindingOff {
+		return nil
+	}
+
+	var mismatches []string
 	if stateData.UserAgent != "" && userAgent != "" && stateData.UserAgent != userAgent {
-		return nil, status.Errorf(
-			codes.InvalidArgument,
-			"user agent mismatch - possible session hijacking",
-		)
+		mismatches = append(mismatches, "user_agent")
+	}
+	if stateData.IPAddress != "" && ipAddress != "" &&
+		!s.stateIPMatches(stateData.IPAddress, ipAddress) {
+		mismatches = append(mismatches, "ip_address")
+	}
+	if len(mismatches) == 0 {
+		return nil
 	}
 
-	// Validate IP address if provided during creation (optional but recommended)
-	if stateData.IPAddress != "" && ipAddress != "" && stateData.IPAddress != ipAddress {
-		return nil, status.Errorf(
+	slog.WarnContext(ctx, "oauth state client mismatch",
+		"mismatch", mismatches,
+		"binding", binding,
+		"provider", stateData.Provider,
+		"state_ip_address", stateData.IPAddress,
+		"ip_address", ipAddress)
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventOAuthStateMismatch,
+		nil,
+		map[string]string{
+			"mismatch":         strings.Join(mismatches, ","),
+			"binding":          binding,
+			"provider":         stateData.Provider,
+			"state_ip_address": stateData.IPAddress,
+			"state_user_agent": stateData.UserAgent,
+		},
+	)
+
+	if binding == configs.StateBindingWarn {
+		return nil
+	}
+	if mismatches[0] == "user_agent" {
+		return status.Errorf(
 			codes.InvalidArgument,
-			"IP address mismatch - possible session hijacking",
+			"user agent mismatch - possible session hijacking",
 		)
 	}
-
-	return &stateData, nil
+	return status.Errorf(
+		codes.InvalidArgument,
+		"IP address mismatch - possible session hijacking",
+	)
 }
 
-func (s *authService) deleteState(ctx context.Context, state string) error {
-	stateKey := fmt.Sprintf("oauth_state:%s", state)
-	return s.rdb.Del(ctx, stateKey).Err()
+func (s *authService) stateIPMatches(stateIP, ipAddress string) bool {
+	if s.config.Auth.OAuthStateIPMatch == configs.StateIPMatchPrefix {
+		network := utils.TruncateIP(ipAddress)
+		return network != "" && network == utils.TruncateIP(stateIP)
+	}
+	return stateIP == ipAddress
 }
 
-// Session management methods
-func (s *authService) createSession(ctx context.Context, userID string) (string, error) {
-	// Generate session ID
-	sessionBytes := make([]byte, 32)
-	if _, err := rand.Read(sessionBytes); err != nil {
-		slog.ErrorContext(ctx, "failed to generate session ID", "error", err, "user_id", userID)
-		return "", status.Errorf(codes.Internal, "failed to generate session ID: %v", err)
+// rejectReplayedCode remembers a hash of every authorization code for a short
+// window and rejects codes that were already presented. Providers should refuse
+// a second exchange anyway; this is defense in depth for codes leaked via logs
+// or referrers.
+func (s *authService) rejectReplayedCode(ctx context.Context, provider, code string) error {
+	codeKey := fmt.Sprintf("oauth_code:%s:%s", provider, utils.HashToken(code))
+	firstUse, err := s.rdb.SetNX(ctx, codeKey, 1, s.config.Auth.OAuthCodeReplayWindow).Result()
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to check authorization code: %v", err)
+	}
+	if !firstUse {
+		return status.Errorf(codes.InvalidArgument, "authorization code has already been used")
 	}
-	sessionID := hex.EncodeToString(sessionBytes)
+	return nil
+}
 
-	// Store session in Redis with configured expiration
-	sessionKey := fmt.Sprintf("session:%s", sessionID)
-	err := s.rdb.Set(ctx, sessionKey, userID, s.config.Session.ExpirationDuration).Err()
+func (s *authService) createSession(ctx context.Context, user *model.UserModel) (string, error) {
+	tenant, err := tenantOf(ctx, s.tenants, user.Org)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get tenant settings", "error", err)
+		return "", status.Errorf(codes.Internal, "failed to get tenant settings: %v", err)
+	}
+	// Store session in Redis with the expiration configured for the user's
+	// role, unless the tenant of the user shortens it
+	expiration := tenant.CapSessionLifetime(s.config.Session.ExpirationFor(string(user.Role)))
+	sessionID, err := s.sessionRepo.Create(ctx, user.ID, expiration)
 	if err != nil {
 		slog.ErrorContext(
 			ctx,
 			"failed to store session in redis",
 			"error",
 			err,
-			"user_id",
-			userID,
-			"session_id",
-			sessionID[:16],
 		)
 		return "", status.Errorf(codes.Internal, "failed to store session: %v", err)
 	}
@@ -200,75 +490,64 @@ func (s *authService) createSession(ctx context.Context, userID string) (string,
 	slog.InfoContext(
 		ctx,
 		"session created successfully",
-		"user_id",
-		userID,
 		"session_id",
 		sessionID[:16],
 		"expires_in_hours",
-		24,
+		expiration.Hours(),
 	)
 	return sessionID, nil
 }
 
 func (s *authService) getUserIDFromSession(ctx context.Context, sessionID string) (*string, error) {
-	sessionKey := fmt.Sprintf("session:%s", sessionID)
-	userIDStr, err := s.rdb.Get(ctx, sessionKey).Result()
+	userID, err := s.sessionRepo.GetUserID(ctx, sessionID)
 	if err != nil {
 		slog.WarnContext(
 			ctx,
 			"session lookup failed",
 			"error",
-			"invalid or expired session",
-			"session_id",
-			sessionID[:min(16, len(sessionID))],
-		)
-		return nil, status.Errorf(codes.Unauthenticated, "invalid or expired session")
-	}
-
-	var userID string
-	if _, err := fmt.Sscanf(userIDStr, "%s", &userID); err != nil {
-		slog.ErrorContext(
-			ctx,
-			"invalid session data format",
-			"error",
 			err,
 			"session_id",
 			sessionID[:min(16, len(sessionID))],
 		)
-		return nil, status.Errorf(codes.Internal, "invalid session data")
+		return nil, status.Errorf(codes.Unauthenticated, "invalid or expired session")
 	}
+	return &userID, nil
+}
 
-	// Automatically refresh session TTL when accessed
-	if err := s.refreshSession(ctx, sessionID); err != nil {
-		// Log the error but don't fail the request - session is still valid
+// refreshSession extends the session by the expiration configured for the user's
+// role, capped by the user's tenant; failures are only logged since the session
+// is still valid.
+func (s *authService) refreshSession(
+	ctx context.Context,
+	sessionID string,
+	user *model.UserModel,
+	tenant *model.TenantSettingsModel,
+) {
+	expiration := tenant.CapSessionLifetime(s.config.Session.ExpirationFor(string(user.Role)))
+	if err := s.sessionRepo.Refresh(ctx, sessionID, user.ID, expiration); err != nil {
 		slog.WarnContext(
 			ctx,
 			"session refresh failed but continuing",
 			"error",
 			err,
-			"user_id",
-			userID,
 			"session_id",
 			sessionID[:16],
 		)
-	} else {
-		slog.DebugContext(ctx, "session refreshed successfully", "user_id", userID, "session_id", sessionID[:16])
+		return
 	}
-
-	return &userID, nil
-}
-
-func (s *authService) refreshSession(ctx context.Context, sessionID string) error {
-	sessionKey := fmt.Sprintf("session:%s", sessionID)
-	return s.rdb.Expire(ctx, sessionKey, s.config.Session.ExpirationDuration).Err()
+	slog.DebugContext(
+		ctx,
+		"session refreshed successfully",
+		"session_id",
+		sessionID[:16],
+	)
 }
 
 func (s *authService) getSessionExpirationTime(
 	ctx context.Context,
 	sessionID string,
 ) (time.Time, error) {
-	sessionKey := fmt.Sprintf("session:%s", sessionID)
-	ttl, err := s.rdb.TTL(ctx, sessionKey).Result()
+	ttl, err := s.sessionRepo.TTL(ctx, sessionID)
 	if err != nil {
 		return time.Time{}, status.Errorf(codes.Internal, "failed to get session TTL: %v", err)
 	}
@@ -281,6 +560,15 @@ func (s *authService) getSessionExpirationTime(
 	return time.Now().Add(ttl), nil
 }
 
+// isRedirectURLAllowed reports whether the provider may redirect to redirectURL.
+// The provider's configured redirect URL is always allowed.
+func (s *authService) isRedirectURLAllowed(oauthConfig *oauth2.Config, redirectURL string) bool {
+	if redirectURL == oauthConfig.RedirectURL {
+		return true
+	}
+	return utils.MatchRedirectURL(s.config.Auth.AllowedRedirectURLs, redirectURL)
+}
+
 // GetOAuthCodeURL generates OAuth authorization URL with embedded CSRF protection
 func (s *authService) GetOAuthCodeURL(
 	ctx context.Context,
@@ -288,8 +576,8 @@ func (s *authService) GetOAuthCodeURL(
 ) (*auth_v1_pb.GetOAuthCodeURLResponse, error) {
 	slog.InfoContext(ctx, "oauth code url request started",
 		"provider", req.Provider,
-		"ip_address", s.extractIPAddress(ctx),
-		"user_agent", s.extractUserAgent(ctx))
+		"ip_address", extractIPAddress(ctx),
+		"user_agent", extractUserAgent(ctx))
 
 	if req.Provider == "" {
 		slog.WarnContext(ctx, "oauth code url request failed", "error", "provider is required")
@@ -309,14 +597,27 @@ func (s *authService) GetOAuthCodeURL(
 	}
 
 	// Extract client information for additional security
-	userAgent := s.extractUserAgent(ctx)
-	ipAddress := s.extractIPAddress(ctx)
+	userAgent := extractUserAgent(ctx)
+	ipAddress := extractIPAddress(ctx)
 
 	// Use custom redirect URL if provided, otherwise use default from config
 	redirectURL := req.GetRedirectUrl()
 	if redirectURL == "" {
 		redirectURL = oauthConfig.RedirectURL
 	}
+	if !s.isRedirectURLAllowed(oauthConfig, redirectURL) {
+		slog.WarnContext(
+			ctx,
+			"oauth code url request failed",
+			"error",
+			"redirect url not allowed",
+			"redirect_url",
+			redirectURL,
+			"ip_address",
+			ipAddress,
+		)
+		return nil, status.Errorf(codes.InvalidArgument, "redirect url is not allowed")
+	}
 
 	// Create a copy of the OAuth config with the custom redirect URL
 	customOauthConfig := *oauthConfig
@@ -355,8 +656,24 @@ func (s *authService) LoginByOAuth(
 	ctx context.Context,
 	req *auth_v1_pb.LoginByOAuthRequest,
 ) (*auth_v1_pb.LoginByOAuthResponse, error) {
-	ipAddress := s.extractIPAddress(ctx)
-	userAgent := s.extractUserAgent(ctx)
+	return idempotent(
+		ctx,
+		s.idempotency,
+		s.config.Auth.IdempotencyKeyTTL,
+		"login_oauth",
+		req,
+		func(ctx context.Context) (*auth_v1_pb.LoginByOAuthResponse, error) {
+			return s.loginByOAuth(ctx, req)
+		},
+	)
+}
+
+func (s *authService) loginByOAuth(
+	ctx context.Context,
+	req *auth_v1_pb.LoginByOAuthRequest,
+) (*auth_v1_pb.LoginByOAuthResponse, error) {
+	ipAddress := extractIPAddress(ctx)
+	userAgent := extractUserAgent(ctx)
 
 	slog.InfoContext(ctx, "oauth login attempt started",
 		"ip_address", ipAddress,
@@ -376,7 +693,7 @@ func (s *authService) LoginByOAuth(
 		return nil, status.Errorf(codes.InvalidArgument, "code and state are required")
 	}
 
-	stateData, err := s.validateState(ctx, req.State, userAgent, ipAddress)
+	stateData, err := s.consumeState(ctx, req.State, userAgent, ipAddress)
 	if err != nil {
 		slog.WarnContext(
 			ctx,
@@ -400,18 +717,25 @@ func (s *authService) LoginByOAuth(
 		stateData.RedirectURL,
 	)
 
-	// Delete used state
-	err = s.deleteState(ctx, req.State)
-	if err != nil {
-		slog.ErrorContext(
+	if err := s.rejectReplayedCode(ctx, stateData.Provider, req.Code); err != nil {
+		slog.WarnContext(
 			ctx,
-			"failed to delete used oauth state",
+			"oauth login failed",
 			"error",
 			err,
-			"state_prefix",
-			req.State[:min(16, len(req.State))],
+			"provider",
+			stateData.Provider,
+			"ip_address",
+			ipAddress,
+		)
+		recordAuditEvent(
+			ctx,
+			s.auditRepo,
+			model.AuditEventLoginFailed,
+			nil,
+			map[string]string{"method": "oauth", "reason": "code_replayed"},
 		)
-		return nil, status.Errorf(codes.Internal, "failed to delete used state: %v", err)
+		return nil, err
 	}
 
 	oauthConfig, exists := s.oauthConfigs[stateData.Provider]
@@ -429,13 +753,29 @@ func (s *authService) LoginByOAuth(
 	if redirectURL == "" {
 		redirectURL = oauthConfig.RedirectURL
 	}
+	// Re-check the state's redirect URL, since the allowlist may have changed
+	// since the state was issued
+	if !s.isRedirectURLAllowed(oauthConfig, redirectURL) {
+		slog.WarnContext(
+			ctx,
+			"oauth login failed",
+			"error",
+			"redirect url not allowed",
+			"redirect_url",
+			redirectURL,
+			"ip_address",
+			ipAddress,
+		)
+		return nil, status.Errorf(codes.InvalidArgument, "redirect url is not allowed")
+	}
 
 	// Create a copy of the OAuth config with the redirect URL from state
 	customOauthConfig := *oauthConfig
 	customOauthConfig.RedirectURL = redirectURL
 
 	// Exchange code for token
-	token, err := customOauthConfig.Exchange(ctx, req.Code)
+	token, err := customOauthConfig.Exchange(outbound.Context(ctx, s.httpClient), req.Code)
+	s.slo.OAuthExchange(ctx, stateData.Provider, err)
 	if err != nil {
 		slog.ErrorContext(
 			ctx,
@@ -451,7 +791,7 @@ func (s *authService) LoginByOAuth(
 	slog.DebugContext(ctx, "oauth token exchange successful", "provider", stateData.Provider)
 
 	// Get user info from provider
-	userProvider, err := providerPkg.GetUserProvider(stateData.Provider)
+	userProvider, err := s.userProvider(stateData.Provider)
 	if err != nil {
 		slog.ErrorContext(
 			ctx,
@@ -465,6 +805,30 @@ func (s *authService) LoginByOAuth(
 	}
 
 	userInfo, err := userProvider.GetUserInfo(ctx, token.AccessToken)
+	if errors.Is(err, providerPkg.ErrNotAllowed) || errors.Is(err, providerPkg.ErrNoVerifiedEmail) {
+		slog.WarnContext(
+			ctx,
+			"oauth login rejected by provider restrictions",
+			"error",
+			err,
+			"provider",
+			stateData.Provider,
+			"ip_address",
+			ipAddress,
+		)
+		recordAuditEvent(
+			ctx,
+			s.auditRepo,
+			model.AuditEventLoginFailed,
+			nil,
+			map[string]string{
+				"method":   "oauth",
+				"provider": stateData.Provider,
+				"reason":   err.Error(),
+			},
+		)
+		return nil, status.Errorf(codes.PermissionDenied, "%v", err)
+	}
 	if err != nil {
 		slog.ErrorContext(
 			ctx,
@@ -474,6 +838,12 @@ func (s *authService) LoginByOAuth(
 			"provider",
 			stateData.Provider,
 		)
+		// The provider failing or too slow, logins can be tried again later
+		if errors.Is(err, providerPkg.ErrUnavailable) ||
+			errors.Is(err, context.DeadlineExceeded) {
+			return nil, errorWithReason(codes.Unavailable, ErrorReasonDependencyUnavailable,
+				fmt.Sprintf("failed to get user info: %v", err))
+		}
 		return nil, status.Errorf(codes.Internal, "failed to get user info: %v", err)
 	}
 
@@ -482,7 +852,7 @@ func (s *authService) LoginByOAuth(
 		"user info retrieved from provider",
 		"provider",
 		stateData.Provider,
-		"user_id",
+		"github_id",
 		userInfo.ID,
 		"email",
 		userInfo.Email,
@@ -507,14 +877,39 @@ func (s *authService) LoginByOAuth(
 
 		if user == nil {
 			isNewUser = true
+			invited, err := s.checkSignupAllowed(ctx, userInfo.Email, stateData.Provider)
+			if err != nil {
+				return nil, err
+			}
+			// Invited addresses were vetted by an admin and skip the domain restriction
+			var pendingApproval bool
+			if !invited {
+				pendingApproval, err = s.checkSignupDomain(ctx, userInfo.Email, stateData.Provider)
+				if err != nil {
+					return nil, err
+				}
+			}
+
 			// Create new user
 			now := time.Now()
 			user = &model.UserModel{
+				Name:            userInfo.Name,
+				Email:           userInfo.Email,
+				GithubID:        &userInfo.ID,
+				GithubLinkedAt:  &now,
+				LastLoginAt:     &now,
+				Role:            model.UserRoleUser,
+				PendingApproval: pendingApproval,
+			}
+			if err := s.provisionUser(ctx, provisioning.Identity{
+				Provider:    stateData.Provider,
+				ID:          userInfo.ID,
+				Login:       userInfo.Login,
 				Name:        userInfo.Name,
 				Email:       userInfo.Email,
-				GithubID:    &userInfo.ID,
-				LastLoginAt: &now,
-				Role:        model.UserRoleUser,
+				AccessToken: token.AccessToken,
+			}, user); err != nil {
+				return nil, err
 			}
 
 			if err := s.userRepo.Create(ctx, user); err != nil {
@@ -530,37 +925,74 @@ func (s *authService) LoginByOAuth(
 				)
 				return nil, status.Errorf(codes.Internal, "failed to create user: %v", err)
 			}
+			ctx = logctx.WithUserID(ctx, user.ID)
+			if invited {
+				if err := s.inviteRepo.Delete(ctx, user.Email); err != nil {
+					slog.WarnContext(ctx, "failed to delete used invite", "error", err)
+				}
+			}
 			slog.InfoContext(
 				ctx,
 				"new user created successfully",
-				"user_id",
-				user.ID,
 				"email",
 				user.Email,
 				"provider",
 				stateData.Provider,
 			)
 		} else {
+			ctx = logctx.WithUserID(ctx, user.ID)
 			// Update last login
 			now := time.Now()
 			user.LastLoginAt = &now
 			if err := s.userRepo.Update(ctx, user); err != nil {
-				slog.ErrorContext(ctx, "failed to update user last login", "error", err, "user_id", user.ID)
+				slog.ErrorContext(ctx, "failed to update user last login", "error", err)
 				return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
 			}
-			slog.InfoContext(ctx, "existing user login successful", "user_id", user.ID, "email", user.Email, "provider", stateData.Provider)
+			slog.InfoContext(ctx, "existing user login successful", "email", user.Email, "provider", stateData.Provider)
 		}
 	}
 
+	if err := s.checkPendingApproval(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := s.checkMaintenance(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := s.checkTenantProvider(ctx, user, stateData.Provider); err != nil {
+		return nil, err
+	}
+
+	attempt := risk.Attempt{
+		UserID:    user.ID,
+		Email:     user.Email,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	}
+	assessment, err := s.assessLoginRisk(ctx, attempt, "oauth")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reactivateDormant(ctx, user); err != nil {
+		return nil, err
+	}
+
 	// Create login session
-	sessionID, err := s.createSession(ctx, user.ID)
+	sessionID, err := s.createSession(ctx, user)
 	if err != nil {
-		slog.ErrorContext(ctx, "failed to create login session", "error", err, "user_id", user.ID)
+		slog.ErrorContext(ctx, "failed to create login session", "error", err)
 		return nil, status.Errorf(codes.Internal, "failed to create session: %v", err)
 	}
 
+	s.saveProviderToken(ctx, user.ID, stateData.Provider, token)
+	s.rememberLogin(ctx, attempt)
+	s.alertNewLogin(ctx, user, attempt, assessment)
+	metadata := assessment.Metadata()
+	metadata["method"] = "oauth"
+	metadata["provider"] = stateData.Provider
+	recordAuditEvent(ctx, s.auditRepo, model.AuditEventLoginSucceeded, &user.ID, metadata)
+
 	slog.InfoContext(ctx, "oauth login completed successfully",
-		"user_id", user.ID,
 		"session_id", sessionID[:16],
 		"provider", stateData.Provider,
 		"is_new_user", isNewUser,
@@ -584,8 +1016,8 @@ func (s *authService) LoginByPassword(
 	ctx context.Context,
 	req *auth_v1_pb.LoginByPasswordRequest,
 ) (*auth_v1_pb.LoginByPasswordResponse, error) {
-	ipAddress := s.extractIPAddress(ctx)
-	userAgent := s.extractUserAgent(ctx)
+	ipAddress := extractIPAddress(ctx)
+	userAgent := extractUserAgent(ctx)
 
 	slog.InfoContext(ctx, "password login attempt started",
 		"email", req.Email,
@@ -593,8 +1025,9 @@ func (s *authService) LoginByPassword(
 		"user_agent", userAgent)
 
 	if req.Email == "" || req.Password == "" {
-		slog.WarnContext(
+		s.warnLoginFailure(
 			ctx,
+			ipAddress,
 			"password login failed",
 			"error",
 			"email and password are required",
@@ -606,12 +1039,17 @@ func (s *authService) LoginByPassword(
 		return nil, status.Errorf(codes.InvalidArgument, "email and password are required")
 	}
 
+	if err := s.checkLoginThrottle(ctx, req.Email, ipAddress); err != nil {
+		return nil, err
+	}
+
 	// Get user by email
 	user, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			slog.WarnContext(
+			s.warnLoginFailure(
 				ctx,
+				ipAddress,
 				"password login failed",
 				"error",
 				"user not found",
@@ -620,7 +1058,20 @@ func (s *authService) LoginByPassword(
 				"ip_address",
 				ipAddress,
 			)
-			return nil, status.Errorf(codes.NotFound, "invalid credentials")
+			recordAuditEvent(
+				ctx,
+				s.auditRepo,
+				model.AuditEventLoginFailed,
+				nil,
+				map[string]string{
+					"method": "password",
+					"email":  req.Email,
+					"reason": "user_not_found",
+				},
+			)
+			s.recordLoginFailure(ctx, req.Email, ipAddress)
+			s.equalizePasswordTiming(req.Password)
+			return nil, s.passwordLoginError(ctx, codes.NotFound, "invalid credentials")
 		}
 		slog.ErrorContext(
 			ctx,
@@ -632,20 +1083,24 @@ func (s *authService) LoginByPassword(
 		)
 		return nil, status.Errorf(codes.Internal, "failed to query user: %v", err)
 	}
+	ctx = logctx.WithUserID(ctx, user.ID)
 
 	// Check if user has a password set
 	if user.HashedPassword == nil {
-		slog.WarnContext(
+		s.warnLoginFailure(
 			ctx,
+			ipAddress,
 			"password login attempt for oauth-only account",
-			"user_id",
-			user.ID,
 			"email",
 			req.Email,
 			"ip_address",
 			ipAddress,
 		)
-		return nil, status.Errorf(
+		// Counted like wrong passwords, so probing accounts isn't free
+		s.recordLoginFailure(ctx, req.Email, ipAddress)
+		s.equalizePasswordTiming(req.Password)
+		return nil, s.passwordLoginError(
+			ctx,
 			codes.FailedPrecondition,
 			"password login not available for this account",
 		)
@@ -659,46 +1114,83 @@ func (s *authService) LoginByPassword(
 			"password verification error",
 			"error",
 			err,
-			"user_id",
-			user.ID,
 			"email",
 			req.Email,
 		)
 		return nil, status.Errorf(codes.Internal, "failed to verify password: %v", err)
 	}
 	if !valid {
-		slog.WarnContext(
+		s.warnLoginFailure(
 			ctx,
+			ipAddress,
 			"password login failed",
 			"error",
 			"invalid password",
-			"user_id",
-			user.ID,
 			"email",
 			req.Email,
 			"ip_address",
 			ipAddress,
 		)
-		return nil, status.Errorf(codes.Unauthenticated, "invalid credentials")
+		recordAuditEvent(
+			ctx,
+			s.auditRepo,
+			model.AuditEventLoginFailed,
+			&user.ID,
+			map[string]string{"method": "password", "reason": "invalid_password"},
+		)
+		s.recordLoginFailure(ctx, req.Email, ipAddress)
+		return nil, s.passwordLoginError(ctx, codes.Unauthenticated, "invalid credentials")
+	}
+
+	if err := s.checkPendingApproval(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := s.checkMaintenance(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// Scored before the throttle is reset, so recent failures are taken into account
+	attempt := risk.Attempt{
+		UserID:    user.ID,
+		Email:     req.Email,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	}
+	assessment, err := s.assessLoginRisk(ctx, attempt, "password")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.throttle.RecordSuccess(ctx, req.Email); err != nil {
+		slog.WarnContext(ctx, "failed to reset login throttle", "error", err)
 	}
 
 	// Update last login
 	now := time.Now()
 	user.LastLoginAt = &now
 	if err := s.userRepo.Update(ctx, user); err != nil {
-		slog.ErrorContext(ctx, "failed to update user last login", "error", err, "user_id", user.ID)
+		slog.ErrorContext(ctx, "failed to update user last login", "error", err)
 		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
 	}
 
+	if err := s.reactivateDormant(ctx, user); err != nil {
+		return nil, err
+	}
+
 	// Create login session
-	sessionID, err := s.createSession(ctx, user.ID)
+	sessionID, err := s.createSession(ctx, user)
 	if err != nil {
-		slog.ErrorContext(ctx, "failed to create login session", "error", err, "user_id", user.ID)
+		slog.ErrorContext(ctx, "failed to create login session", "error", err)
 		return nil, status.Errorf(codes.Internal, "failed to create session: %v", err)
 	}
 
+	s.rememberLogin(ctx, attempt)
+	s.alertNewLogin(ctx, user, attempt, assessment)
+	metadata := assessment.Metadata()
+	metadata["method"] = "password"
+	recordAuditEvent(ctx, s.auditRepo, model.AuditEventLoginSucceeded, &user.ID, metadata)
+
 	slog.InfoContext(ctx, "password login completed successfully",
-		"user_id", user.ID,
 		"email", req.Email,
 		"session_id", sessionID[:16],
 		"ip_address", ipAddress)
@@ -712,9 +1204,293 @@ func (s *authService) LoginByPassword(
 			Id:        sessionID,
 			ExpiresAt: timestamppb.New(expiresAt),
 		},
+		MustChangePassword: user.MustChangePassword,
 	}, nil
 }
 
+// assessLoginRisk scores a login whose credentials were verified and rejects it
+// if the score reaches the block threshold. Logins reaching the challenge
+// threshold are let through and flagged until a second factor can be required.
+// Scoring errors never fail the login.
+func (s *authService) assessLoginRisk(
+	ctx context.Context,
+	attempt risk.Attempt,
+	method string,
+) (risk.Assessment, error) {
+	assessment, err := s.risk.Assess(ctx, attempt)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to assess login risk", "error", err)
+		return risk.Assessment{Action: risk.ActionAllow}, nil
+	}
+
+	switch assessment.Action {
+	case risk.ActionBlock:
+		metadata := assessment.Metadata()
+		metadata["method"] = method
+		metadata["reason"] = "risk_blocked"
+		recordAuditEvent(ctx, s.auditRepo, model.AuditEventLoginFailed, &attempt.UserID, metadata)
+		slog.WarnContext(
+			ctx,
+			"login blocked due to suspicious activity",
+			"risk_score",
+			assessment.Score,
+			"risk_signals",
+			assessment.Signals,
+		)
+		return assessment, status.Errorf(
+			codes.PermissionDenied,
+			"login blocked due to suspicious activity",
+		)
+	case risk.ActionChallenge:
+		slog.WarnContext(
+			ctx,
+			"suspicious login requires additional verification",
+			"risk_score",
+			assessment.Score,
+			"risk_signals",
+			assessment.Signals,
+		)
+	}
+	return assessment, nil
+}
+
+// rememberLogin adds a successful login to the history risk scoring relies on.
+func (s *authService) rememberLogin(ctx context.Context, attempt risk.Attempt) {
+	if err := s.risk.Remember(ctx, attempt); err != nil {
+		slog.WarnContext(ctx, "failed to remember login", "error", err)
+	}
+}
+
+func (s *authService) userProvider(name string) (providerPkg.UserProvider, error) {
+	userProvider, ok := s.userProviders[name]
+	if !ok {
+		var err error
+		userProvider, err = providerPkg.GetUserProvider(name, s.config.Auth, s.httpClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.userInfoGuard.Wrap(name, userProvider), nil
+}
+
+// checkSignupDomain applies the email domain restriction to a self-service
+// signup. It returns whether the account has to wait for admin approval, or an
+// error if the signup is rejected.
+func (s *authService) checkSignupDomain(ctx context.Context, email, provider string) (bool, error) {
+	if utils.MatchEmailDomain(s.config.Account.AllowedEmailDomains, email) {
+		return false, nil
+	}
+	if s.config.Account.DisallowedDomainSignup == configs.DomainSignupApproval {
+		slog.InfoContext(ctx, "signup outside allowed domains queued for approval",
+			"email", email,
+			"provider", provider)
+		return true, nil
+	}
+
+	slog.WarnContext(ctx, "signup rejected, email domain not allowed",
+		"email", email,
+		"provider", provider)
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventSignupRejected,
+		nil,
+		map[string]string{"provider": provider, "email": email, "reason": "domain_not_allowed"},
+	)
+	return false, status.Errorf(codes.PermissionDenied, "email domain is not allowed")
+}
+
+// signupMode returns the effective signup mode: the configured mode, tightened
+// by the signup_blocked and signup_invite_only feature flags.
+func (s *authService) signupMode(ctx context.Context) string {
+	mode := s.config.Account.SignupMode
+	if mode == configs.SignupModeClosed || s.flags.Enabled(ctx, featureflags.SignupBlocked) {
+		return configs.SignupModeClosed
+	}
+	if mode == configs.SignupModeInviteOnly ||
+		s.flags.Enabled(ctx, featureflags.SignupInviteOnly) {
+		return configs.SignupModeInviteOnly
+	}
+	return configs.SignupModeOpen
+}
+
+// checkSignupAllowed applies the signup mode to a self-service signup. It
+// returns whether the address was invited, or an error carrying an ErrorInfo
+// reason the frontend can display if the signup is refused.
+func (s *authService) checkSignupAllowed(ctx context.Context, email, provider string) (bool, error) {
+	var reason string
+	switch s.signupMode(ctx) {
+	case configs.SignupModeOpen:
+		return false, nil
+	case configs.SignupModeInviteOnly:
+		_, err := s.inviteRepo.Get(ctx, email)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, repository.ErrInviteNotFound) {
+			slog.ErrorContext(ctx, "failed to get invite", "error", err)
+			return false, status.Errorf(codes.Internal, "failed to get invite: %v", err)
+		}
+		reason = ErrorReasonSignupInviteRequired
+	default:
+		reason = ErrorReasonSignupDisabled
+	}
+
+	slog.WarnContext(ctx, "signup rejected by signup mode",
+		"email", email,
+		"provider", provider,
+		"reason", reason)
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventSignupRejected,
+		nil,
+		map[string]string{"provider": provider, "email": email, "reason": strings.ToLower(reason)},
+	)
+	msg := "signups are currently disabled"
+	if reason == ErrorReasonSignupInviteRequired {
+		msg = "signups require an invite"
+	}
+	return false, errorWithReason(codes.PermissionDenied, reason, msg)
+}
+
+// checkMaintenance refuses logins and tokens of everyone but admins while the
+// maintenance_mode feature flag is on.
+func (s *authService) checkMaintenance(ctx context.Context, user *model.UserModel) error {
+	if user.Role == model.UserRoleAdmin || !s.flags.Enabled(ctx, featureflags.MaintenanceMode) {
+		return nil
+	}
+	slog.InfoContext(ctx, "request refused, maintenance mode")
+	return errorWithReason(codes.Unavailable, ErrorReasonMaintenance,
+		"the service is under maintenance")
+}
+
+// checkPendingApproval blocks logins of accounts waiting for admin approval.
+func (s *authService) checkPendingApproval(ctx context.Context, user *model.UserModel) error {
+	if !user.PendingApproval {
+		return nil
+	}
+	slog.InfoContext(ctx, "login blocked, account pending approval")
+	recordAuditEvent(ctx, s.auditRepo, model.AuditEventSignupPendingApproval, &user.ID, nil)
+	return status.Errorf(codes.PermissionDenied, "account is pending approval")
+}
+
+// reactivateDormant reactivates an account deactivated for inactivity, as
+// logging in again is what the warning email asks dormant users to do.
+func (s *authService) reactivateDormant(ctx context.Context, user *model.UserModel) error {
+	if user.DeactivatedAt == nil {
+		return nil
+	}
+	deactivatedAt := *user.DeactivatedAt
+	now := time.Now()
+	user.DeactivatedAt = nil
+	user.DormancyWarnedAt = nil
+	user.LastSeenAt = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		slog.ErrorContext(ctx, "failed to reactivate user", "error", err)
+		return status.Errorf(codes.Internal, "failed to update user: %v", err)
+	}
+	slog.InfoContext(ctx, "dormant account reactivated")
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventAccountReactivated,
+		&user.ID,
+		map[string]string{"deactivated_at": deactivatedAt.UTC().Format(time.RFC3339)},
+	)
+	return nil
+}
+
+// checkLoginThrottle rejects the attempt if the account or the client network
+// is still inside a backoff delay. Throttle failures never block logins.
+func (s *authService) checkLoginThrottle(ctx context.Context, email, ipAddress string) error {
+	wait, err := s.throttle.Check(ctx, email, ipAddress)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to check login throttle", "error", err)
+		return nil
+	}
+	if wait <= 0 {
+		return nil
+	}
+
+	s.warnLoginFailure(ctx, ipAddress, "password login throttled",
+		"email", email,
+		"ip_address", ipAddress,
+		"retry_after", wait)
+	st := status.Newf(
+		codes.ResourceExhausted,
+		"too many failed login attempts, retry in %d seconds",
+		int(math.Ceil(wait.Seconds())),
+	)
+	if detailed, err := st.WithDetails(
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(wait)},
+	); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// dummyPasswordHash is verified against when there is no password to check, so
+// such failures take as long as a wrong password.
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, err := utils.HashPassword("dummy-password-for-timing")
+	if err != nil {
+		return ""
+	}
+	return hash
+})
+
+// equalizePasswordTiming spends the time of a password verification if failures
+// must not be distinguishable.
+func (s *authService) equalizePasswordTiming(password string) {
+	if !s.config.Auth.LoginGenericErrors {
+		return
+	}
+	if hash := dummyPasswordHash(); hash != "" {
+		_, _ = utils.VerifyPassword(password, hash)
+	}
+}
+
+// passwordLoginError delays a failed password login by the configured tarpit and
+// returns its status; with generic errors every failure looks like a wrong password.
+func (s *authService) passwordLoginError(ctx context.Context, code codes.Code, msg string) error {
+	s.tarpit(ctx)
+	if s.config.Auth.LoginGenericErrors {
+		return status.Error(codes.Unauthenticated, "invalid credentials")
+	}
+	return status.Error(code, msg)
+}
+
+// tarpit waits a random time within the configured bounds, slowing down
+// credential stuffing and blurring timing differences between failures.
+func (s *authService) tarpit(ctx context.Context) {
+	maxDelay := s.config.Auth.LoginTarpitMax
+	if maxDelay <= 0 {
+		return
+	}
+	minDelay := min(s.config.Auth.LoginTarpitMin, maxDelay)
+	delay := minDelay + mathrand.N(maxDelay-minDelay+1)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// warnLoginFailure logs a warning about a failed login, sampled per client
+// network so that failed-login storms don't flood the logs.
+func (s *authService) warnLoginFailure(ctx context.Context, ipAddress, msg string, args ...any) {
+	s.loginWarnings.Warn(ctx, utils.TruncateIP(ipAddress), msg, args...)
+}
+
+func (s *authService) recordLoginFailure(ctx context.Context, email, ipAddress string) {
+	if err := s.throttle.RecordFailure(ctx, email, ipAddress); err != nil {
+		slog.WarnContext(ctx, "failed to record login failure", "error", err)
+	}
+}
+
 // GetUserToken generates JWT token for authenticated users
 func (s *authService) GetUserToken(
 	ctx context.Context,
@@ -732,7 +1508,7 @@ func (s *authService) GetUserToken(
 		return nil, status.Errorf(codes.InvalidArgument, "session_id is required")
 	}
 
-	// Get user ID from session (this automatically refreshes the session)
+	// Get user ID from session
 	userID, err := s.getUserIDFromSession(ctx, req.SessionId)
 	if err != nil {
 		slog.WarnContext(
@@ -745,63 +1521,130 @@ func (s *authService) GetUserToken(
 		)
 		return nil, err
 	}
+	ctx = logctx.WithUserID(ctx, *userID)
 
-	// Get session expiration time after refresh
-	sessionExpiresAt, err := s.getSessionExpirationTime(ctx, req.SessionId)
+	// Read the role version before the user: a role change updates the user
+	// and then bumps the version, so a token carrying a role read before the
+	// change can't carry the version bumped for it
+	roleVersion, err := s.roleVersions.Get(ctx, *userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get role version", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to get role version: %v", err)
+	}
+
+	// Get user details
+	user, err := s.userRepo.GetByID(ctx, *userID)
 	if err != nil {
 		slog.ErrorContext(
 			ctx,
-			"failed to get session expiration time",
+			"failed to get user details for token generation",
 			"error",
 			err,
-			"user_id",
-			userID,
-			"session_id",
-			req.SessionId[:min(16, len(req.SessionId))],
 		)
-		return nil, status.Errorf(codes.Internal, "failed to get session expiration: %v", err)
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+	if user.DeactivatedAt != nil {
+		slog.InfoContext(ctx, "user token refused, account deactivated")
+		return nil, status.Errorf(codes.PermissionDenied, "account is deactivated")
+	}
+	if err := s.checkMaintenance(ctx, user); err != nil {
+		return nil, err
 	}
 
-	// Get user details
-	user, err := s.userRepo.GetByID(ctx, *userID)
+	tenant, err := tenantOf(ctx, s.tenants, user.Org)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get tenant settings", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to get tenant settings: %v", err)
+	}
+
+	// Refresh the session when a token is requested
+	s.refreshSession(ctx, req.SessionId, user, tenant)
+	s.activity.RecordActivity(ctx, user.ID)
+
+	// Get session expiration time after refresh
+	sessionExpiresAt, err := s.getSessionExpirationTime(ctx, req.SessionId)
 	if err != nil {
 		slog.ErrorContext(
 			ctx,
-			"failed to get user details for token generation",
+			"failed to get session expiration time",
 			"error",
 			err,
-			"user_id",
-			userID,
+			"session_id",
+			req.SessionId[:min(16, len(req.SessionId))],
 		)
-		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+		return nil, status.Errorf(codes.Internal, "failed to get session expiration: %v", err)
 	}
 
-	// Generate JWT token with session expiration time
-	// This ensures the token expires when the session expires
-	userToken, err := utils.NewUserTokenWithExpiration(
-		user.ID,
-		user.Role,
-		s.config.Auth.JWTSecret,
-		sessionExpiresAt,
-	)
+	// Access tokens are short-lived and never outlive the session
+	tokenExpiresAt := s.accessTokenExpiration(time.Now(), sessionExpiresAt, user.Role, tenant)
+	claims := utils.NewUserTokenClaimsWithExpiration(user.ID, user.Role, tokenExpiresAt)
+	claims.SetIssuer(s.config.Auth.JWTIssuer, s.config.Auth.JWTAudience)
+	if user.MustChangePassword {
+		claims.MapClaims[utils.ClaimMustChangePassword] = true
+	}
+	if roleVersion > 0 {
+		claims.MapClaims[utils.ClaimRoleVersion] = roleVersion
+	}
+	claims.MapClaims[utils.ClaimSessionRef] = repository.SessionRef(req.SessionId)
+	scope, err := s.tokenScope(user.Role)
 	if err != nil {
-		slog.ErrorContext(ctx, "failed to generate JWT token", "error", err, "user_id", user.ID)
+		slog.ErrorContext(ctx, "failed to derive token scopes", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to derive token scopes: %v", err)
+	}
+	if scope != "" {
+		claims.MapClaims[utils.ClaimScope] = scope
+	}
+	if len(user.Entitlements) > 0 {
+		claims.MapClaims[utils.ClaimEntitlements] = user.Entitlements
+	}
+	s.addCustomClaims(ctx, user, claims)
+	userToken, err := utils.SignUserToken(claims, s.config.Auth.JWTSecret, tokenExpiresAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to generate JWT token", "error", err)
 		return nil, status.Errorf(codes.Internal, "failed to generate token: %v", err)
 	}
 
 	slog.InfoContext(ctx, "user token generated successfully",
-		"user_id", user.ID,
 		"role", user.Role,
 		"session_id", req.SessionId[:16],
-		"token_expires_at", sessionExpiresAt)
+		"token_expires_at", tokenExpiresAt)
 
 	return &auth_v1_pb.GetUserTokenResponse{
 		Token: userToken,
 	}, nil
 }
 
+// tokenScope returns the scope claim for users of role in the configured form,
+// empty if scopes are disabled.
+func (s *authService) tokenScope(role model.UserRole) (string, error) {
+	if s.enforcer == nil || s.config.Auth.TokenScopes == configs.TokenScopesOff {
+		return "", nil
+	}
+	return auth.ScopeClaim(
+		s.enforcer,
+		string(role),
+		s.config.Auth.TokenScopes == configs.TokenScopesHashed,
+	)
+}
+
+// accessTokenExpiration returns when an access token issued at now expires: after
+// the lifetime configured for the role, or the shorter one of the tenant, but no
+// later than the session.
+func (s *authService) accessTokenExpiration(
+	now, sessionExpiresAt time.Time,
+	role model.UserRole,
+	tenant *model.TenantSettingsModel,
+) time.Time {
+	lifetime := tenant.CapAccessTokenLifetime(s.config.Auth.AccessTokenLifetimeFor(string(role)))
+	expiresAt := now.Add(lifetime)
+	if expiresAt.After(sessionExpiresAt) {
+		return sessionExpiresAt
+	}
+	return expiresAt
+}
+
 // extractUserAgent extracts user agent from gRPC metadata
-func (s *authService) extractUserAgent(ctx context.Context) string {
+func extractUserAgent(ctx context.Context) string {
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
 		userAgents := md.Get("user-agent")
 		if len(userAgents) > 0 {
@@ -811,40 +1654,20 @@ func (s *authService) extractUserAgent(ctx context.Context) string {
 	return ""
 }
 
-// extractIPAddress extracts IP address from gRPC peer info
-func (s *authService) extractIPAddress(ctx context.Context) string {
+// extractIPAddress returns the client address of the call as resolved by the
+// client IP interceptor, which trusts X-Forwarded-For only from the configured
+// proxies; without it, the address of the peer. It is "" if the address is
+// unknown or a trusted proxy's, e.g. for calls of the gateway itself.
+func extractIPAddress(ctx context.Context) string {
+	if ip, ok := clientip.FromContext(ctx); ok {
+		return ip
+	}
 	if p, ok := peer.FromContext(ctx); ok {
-		if addr := p.Addr; addr != nil {
-			// Handle different address types
-			switch addr := addr.(type) {
-			case *net.TCPAddr:
-				return addr.IP.String()
-			case *net.UDPAddr:
-				return addr.IP.String()
-			default:
-				// Try to parse the string representation
-				addrStr := addr.String()
-				if host, _, err := net.SplitHostPort(addrStr); err == nil {
-					return host
-				}
-				// Check for X-Forwarded-For header in metadata
-				if md, ok := metadata.FromIncomingContext(ctx); ok {
-					xForwardedFor := md.Get("x-forwarded-for")
-					if len(xForwardedFor) > 0 {
-						// Get the first IP from the comma-separated list
-						ips := strings.Split(xForwardedFor[0], ",")
-						if len(ips) > 0 {
-							return strings.TrimSpace(ips[0])
-						}
-					}
-
-					xRealIP := md.Get("x-real-ip")
-					if len(xRealIP) > 0 {
-						return xRealIP[0]
-					}
-				}
-				return addrStr
-			}
+		switch addr := p.Addr.(type) {
+		case *net.TCPAddr:
+			return addr.IP.String()
+		case *net.UDPAddr:
+			return addr.IP.String()
 		}
 	}
 	return ""
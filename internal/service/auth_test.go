@@ -0,0 +1,616 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/poly-workshop/auth-portal/configs"
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/activity"
+	"github.com/poly-workshop/auth-portal/internal/featureflags"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	providerPkg "github.com/poly-workshop/auth-portal/internal/provider"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/risk"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"github.com/poly-workshop/auth-portal/internal/throttle"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"golang.org/x/oauth2"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestAuthService(t testing.TB) (*authService, *miniredis.Miniredis) {
+	t.Helper()
+	rdb, mr := testutil.NewRedis(t)
+	loginThrottle := throttle.NewLoginThrottle(rdb, configs.ThrottleConfig{})
+	userRepo := testutil.NewUserRepository()
+	return &authService{
+		rdb:          rdb,
+		userRepo:     userRepo,
+		sessionRepo:  repository.NewSessionRepository(rdb),
+		auditRepo:    testutil.NewAuditRepository(),
+		roleVersions: repository.NewRoleVersionRepository(rdb),
+		throttle:     loginThrottle,
+		activity:     activity.NewTracker(rdb, userRepo, time.Hour),
+		risk:         risk.NewScorer(rdb, loginThrottle, configs.RiskConfig{}),
+		flags:        featureflags.NewStore(rdb, configs.FeatureFlagsConfig{}),
+		inviteRepo:   repository.NewInviteRepository(rdb),
+		deviceAuths:  repository.NewDeviceAuthorizationRepository(rdb),
+		magicLinks:   repository.NewMagicLinkRepository(rdb),
+		loginCodes:   repository.NewLoginCodeRepository(rdb),
+		handoffs:     repository.NewHandoffTokenRepository(rdb),
+		idempotency:  repository.NewIdempotencyRepository(rdb),
+		config: configs.Config{
+			Auth: configs.AuthConfig{
+				OAuthStateExpirationDuration: 10 * time.Minute,
+				OAuthCodeReplayWindow:        15 * time.Minute,
+				AccessTokenLifetime:          15 * time.Minute,
+			},
+			Session: configs.SessionConfig{ExpirationDuration: time.Hour},
+		},
+	}, mr
+}
+
+func TestConsumeStateIsSingleUse(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	ctx := context.Background()
+
+	state, err := s.generateState(ctx, "github", "", "agent", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("generateState failed: %v", err)
+	}
+
+	stateData, err := s.consumeState(ctx, state, "agent", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("first consume failed: %v", err)
+	}
+	if stateData.Provider != "github" {
+		t.Errorf("Expected provider github, got %s", stateData.Provider)
+	}
+
+	_, err = s.consumeState(ctx, state, "agent", "10.0.0.1")
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument on reuse, got %v", err)
+	}
+}
+
+func TestConsumeStateConcurrently(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	ctx := context.Background()
+
+	state, err := s.generateState(ctx, "github", "", "", "")
+	if err != nil {
+		t.Fatalf("generateState failed: %v", err)
+	}
+
+	const attempts = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.consumeState(ctx, state, "", ""); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 1 {
+		t.Errorf("Expected exactly one successful consume, got %d", succeeded)
+	}
+}
+
+func TestConsumeStateMismatchBurnsState(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	ctx := context.Background()
+
+	state, err := s.generateState(ctx, "github", "", "agent", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("generateState failed: %v", err)
+	}
+
+	if _, err := s.consumeState(ctx, state, "other-agent", "10.0.0.1"); err == nil {
+		t.Fatal("Expected user agent mismatch to fail")
+	}
+	if _, err := s.consumeState(ctx, state, "agent", "10.0.0.1"); err == nil {
+		t.Error("Expected state to be consumed by the failed attempt")
+	}
+}
+
+func TestConsumeStateBinding(t *testing.T) {
+	const (
+		enforce = configs.StateBindingEnforce
+		exact   = configs.StateIPMatchExact
+		prefix  = configs.StateIPMatchPrefix
+	)
+
+	tests := []struct {
+		name      string
+		binding   string
+		ipMatch   string
+		userAgent string
+		ipAddress string
+		wantErr   bool
+		wantAudit bool
+	}{
+		{"enforce same client", enforce, exact, "agent", "10.0.0.1", false, false},
+		{"enforce ip change", enforce, exact, "agent", "10.0.0.2", true, true},
+		{"enforce ua change", enforce, exact, "other", "10.0.0.1", true, true},
+		{"enforce ip change in prefix", enforce, prefix, "agent", "10.0.0.2", false, false},
+		{"enforce ip change outside prefix", enforce, prefix, "agent", "10.0.1.1", true, true},
+		{"warn ip change", configs.StateBindingWarn, exact, "agent", "10.0.0.2", false, true},
+		{"off ip change", configs.StateBindingOff, exact, "other", "192.0.2.1", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestAuthService(t)
+			s.config.Auth.OAuthStateBinding = tt.binding
+			s.config.Auth.OAuthStateIPMatch = tt.ipMatch
+			auditRepo := s.auditRepo.(*testutil.AuditRepository)
+			ctx := context.Background()
+
+			state, err := s.generateState(ctx, "github", "", "agent", "10.0.0.1")
+			if err != nil {
+				t.Fatalf("generateState failed: %v", err)
+			}
+			_, err = s.consumeState(ctx, state, tt.userAgent, tt.ipAddress)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			audited := auditRepo.Count(model.AuditEventOAuthStateMismatch) > 0
+			if audited != tt.wantAudit {
+				t.Errorf("Expected audit event %v, got %v", tt.wantAudit, audited)
+			}
+		})
+	}
+}
+
+func TestConsumeStateExpired(t *testing.T) {
+	s, mr := newTestAuthService(t)
+	ctx := context.Background()
+
+	state, err := s.generateState(ctx, "github", "", "", "")
+	if err != nil {
+		t.Fatalf("generateState failed: %v", err)
+	}
+	mr.FastForward(11 * time.Minute)
+
+	_, err = s.consumeState(ctx, state, "", "")
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for expired state, got %v", err)
+	}
+}
+
+func TestRejectReplayedCode(t *testing.T) {
+	s, mr := newTestAuthService(t)
+	ctx := context.Background()
+
+	if err := s.rejectReplayedCode(ctx, "github", "code-1"); err != nil {
+		t.Fatalf("Expected first use to pass, got %v", err)
+	}
+	err := s.rejectReplayedCode(ctx, "github", "code-1")
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument on replay, got %v", err)
+	}
+	if err := s.rejectReplayedCode(ctx, "github", "code-2"); err != nil {
+		t.Errorf("Expected a different code to pass, got %v", err)
+	}
+
+	for _, key := range mr.Keys() {
+		if strings.Contains(key, "code-1") {
+			t.Errorf("Expected only the code hash to be stored, found key %s", key)
+		}
+	}
+
+	mr.FastForward(16 * time.Minute)
+	if err := s.rejectReplayedCode(ctx, "github", "code-1"); err != nil {
+		t.Errorf("Expected code to be forgotten after the window, got %v", err)
+	}
+}
+
+func TestIsRedirectURLAllowed(t *testing.T) {
+	s := &authService{
+		config: configs.Config{
+			Auth: configs.AuthConfig{
+				AllowedRedirectURLs: []string{"https://*.example.com/auth/callback"},
+			},
+		},
+	}
+	oauthConfig := &oauth2.Config{RedirectURL: "http://localhost:8080/auth/callback"}
+
+	if !s.isRedirectURLAllowed(oauthConfig, "http://localhost:8080/auth/callback") {
+		t.Error("Expected the provider redirect URL to be allowed")
+	}
+	if !s.isRedirectURLAllowed(oauthConfig, "https://app.example.com/auth/callback") {
+		t.Error("Expected an allowlisted redirect URL to be allowed")
+	}
+	if s.isRedirectURLAllowed(oauthConfig, "https://evil.com/auth/callback") {
+		t.Error("Expected an unknown redirect URL to be rejected")
+	}
+}
+
+func TestCheckSignupDomain(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	s.config.Account.AllowedEmailDomains = []string{"company.com"}
+	auditRepo := s.auditRepo.(*testutil.AuditRepository)
+	ctx := context.Background()
+
+	s.config.Account.DisallowedDomainSignup = configs.DomainSignupReject
+	pending, err := s.checkSignupDomain(ctx, "alice@company.com", "github")
+	if err != nil || pending {
+		t.Errorf("Expected allowed domain to sign up directly, got pending=%v err=%v", pending, err)
+	}
+	_, err = s.checkSignupDomain(ctx, "mallory@example.org", "github")
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected PermissionDenied, got %v", err)
+	}
+	if auditRepo.Count(model.AuditEventSignupRejected) != 1 {
+		t.Error("Expected rejected signup to be audited")
+	}
+
+	s.config.Account.DisallowedDomainSignup = configs.DomainSignupApproval
+	pending, err = s.checkSignupDomain(ctx, "bob@example.org", "github")
+	if err != nil || !pending {
+		t.Errorf("Expected signup to await approval, got pending=%v err=%v", pending, err)
+	}
+}
+
+func TestCheckPendingApproval(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	ctx := context.Background()
+
+	if err := s.checkPendingApproval(ctx, &model.UserModel{ID: "user-1"}); err != nil {
+		t.Errorf("Expected approved user to pass, got %v", err)
+	}
+	err := s.checkPendingApproval(ctx, &model.UserModel{ID: "user-2", PendingApproval: true})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected PermissionDenied for pending user, got %v", err)
+	}
+}
+
+func TestAccessTokenExpiration(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	now := time.Now()
+
+	s.config.Auth.AccessTokenLifetimeByRole = map[string]time.Duration{
+		string(model.UserRoleAdmin): 5 * time.Minute,
+	}
+	sessionExpiresAt := now.Add(24 * time.Hour)
+
+	got := s.accessTokenExpiration(now, sessionExpiresAt, model.UserRoleUser, nil)
+	if !got.Equal(now.Add(15 * time.Minute)) {
+		t.Errorf("expected the configured lifetime, got %v", got.Sub(now))
+	}
+	got = s.accessTokenExpiration(now, sessionExpiresAt, model.UserRoleAdmin, nil)
+	if !got.Equal(now.Add(5 * time.Minute)) {
+		t.Errorf("expected the admin lifetime, got %v", got.Sub(now))
+	}
+	sessionExpiresAt = now.Add(time.Minute)
+	got = s.accessTokenExpiration(now, sessionExpiresAt, model.UserRoleUser, nil)
+	if !got.Equal(sessionExpiresAt) {
+		t.Errorf("expected the session expiration, got %v", got.Sub(now))
+	}
+}
+
+func TestAssessLoginRiskBlocks(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	loginThrottle := throttle.NewLoginThrottle(s.rdb, configs.ThrottleConfig{})
+	s.risk = risk.NewScorer(s.rdb, loginThrottle, configs.RiskConfig{
+		Enabled:           true,
+		BlockThreshold:    20,
+		VelocityWindow:    time.Hour,
+		VelocityMaxLogins: 10,
+		FailedAttempts:    3,
+		History:           time.Hour,
+	})
+	auditRepo := s.auditRepo.(*testutil.AuditRepository)
+	ctx := context.Background()
+
+	attempt := risk.Attempt{UserID: "user-1", IPAddress: "10.0.0.1", UserAgent: "agent"}
+	if _, err := s.assessLoginRisk(ctx, attempt, "password"); err != nil {
+		t.Fatalf("Expected the first login to pass, got %v", err)
+	}
+	s.rememberLogin(ctx, attempt)
+
+	attempt.UserAgent = "other-agent"
+	_, err := s.assessLoginRisk(ctx, attempt, "password")
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected PermissionDenied for a login from a new device, got %v", err)
+	}
+	if n := auditRepo.Count(model.AuditEventLoginFailed); n != 1 {
+		t.Errorf("Expected 1 login.failed event, got %d", n)
+	}
+}
+
+func TestPasswordLoginError(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	ctx := context.Background()
+
+	err := s.passwordLoginError(ctx, codes.NotFound, "invalid credentials")
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected the specific status by default, got %v", err)
+	}
+
+	s.config.Auth.LoginGenericErrors = true
+	s.config.Auth.LoginTarpitMin = 20 * time.Millisecond
+	s.config.Auth.LoginTarpitMax = 20 * time.Millisecond
+	for _, code := range []codes.Code{codes.NotFound, codes.FailedPrecondition} {
+		start := time.Now()
+		err := s.passwordLoginError(ctx, code, "password login not available for this account")
+		st := status.Convert(err)
+		if st.Code() != codes.Unauthenticated || st.Message() != "invalid credentials" {
+			t.Errorf("Expected a generic Unauthenticated status, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+			t.Errorf("Expected the tarpit to delay the response, took %v", elapsed)
+		}
+	}
+}
+
+func TestPasswordLoginOAuthOnlyAccount(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	s.userRepo = testutil.NewUserRepository(
+		&model.UserModel{ID: "user-1", Email: "oauth@example.com"},
+	)
+	s.throttle = throttle.NewLoginThrottle(s.rdb, configs.ThrottleConfig{
+		Enabled:   true,
+		BaseDelay: time.Second,
+		MaxDelay:  time.Minute,
+		Window:    time.Hour,
+	})
+	ctx := context.Background()
+
+	_, err := s.LoginByPassword(ctx, &auth_v1_pb.LoginByPasswordRequest{
+		Email:    "oauth@example.com",
+		Password: "guessed-password",
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition, got %v", err)
+	}
+	// Throttled like other failures, so accounts without a password don't stand out
+	if failures, _ := s.throttle.AccountFailures(ctx, "oauth@example.com"); failures != 1 {
+		t.Errorf("Expected the attempt to count as a failure, got %d", failures)
+	}
+}
+
+func TestGetPublicConfig(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	s.oauthConfigs = map[string]*oauth2.Config{
+		"github": {
+			ClientID:    "client",
+			Scopes:      []string{"user:email"},
+			Endpoint:    oauth2.Endpoint{AuthURL: "https://github.com/login/oauth/authorize"},
+			RedirectURL: "https://portal.example.com/callback",
+		},
+		"unconfigured": {},
+	}
+	s.config.Account.AllowedEmailDomains = []string{"example.com"}
+	s.config.Account.DisallowedDomainSignup = configs.DomainSignupApproval
+
+	resp, err := s.GetPublicConfig(context.Background(), &auth_v1_pb.GetPublicConfigRequest{})
+	if err != nil {
+		t.Fatalf("GetPublicConfig failed: %v", err)
+	}
+	if len(resp.OauthProviders) != 1 || resp.OauthProviders[0].Name != "github" {
+		t.Fatalf("expected only the configured provider, got %v", resp.OauthProviders)
+	}
+	if resp.OauthProviders[0].RedirectUrl != "https://portal.example.com/callback" {
+		t.Errorf("unexpected redirect url %q", resp.OauthProviders[0].RedirectUrl)
+	}
+	if resp.PasswordPolicy.MinLength != minPasswordLength {
+		t.Errorf("expected min length %d, got %d", minPasswordLength, resp.PasswordPolicy.MinLength)
+	}
+	if !resp.Features[FeatureSignupApproval] || !resp.Features[FeatureEmailDomainRestricted] {
+		t.Errorf("expected signup approval and domain restriction, got %v", resp.Features)
+	}
+}
+
+func TestFeatureFlagChecks(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	ctx := context.Background()
+	user := &model.UserModel{ID: "user-1", Role: model.UserRoleUser}
+	admin := &model.UserModel{ID: "admin-1", Role: model.UserRoleAdmin}
+
+	if err := s.checkMaintenance(ctx, user); err != nil {
+		t.Fatalf("expected no maintenance by default, got %v", err)
+	}
+	if _, err := s.checkSignupAllowed(ctx, "new@example.com", "github"); err != nil {
+		t.Fatalf("expected signups to be open by default, got %v", err)
+	}
+
+	for _, flag := range []string{featureflags.MaintenanceMode, featureflags.SignupBlocked} {
+		if err := s.flags.Set(ctx, flag, true); err != nil {
+			t.Fatalf("Set(%s) failed: %v", flag, err)
+		}
+	}
+	if status.Code(s.checkMaintenance(ctx, user)) != codes.Unavailable {
+		t.Error("expected users to be refused during maintenance")
+	}
+	if err := s.checkMaintenance(ctx, admin); err != nil {
+		t.Errorf("expected admins to pass during maintenance, got %v", err)
+	}
+	_, err := s.checkSignupAllowed(ctx, "new@example.com", "github")
+	if reason := errorReason(err); reason != ErrorReasonSignupDisabled {
+		t.Errorf("expected signups to be rejected while blocked, got %v", err)
+	}
+	if n := s.auditRepo.(*testutil.AuditRepository).Count(model.AuditEventSignupRejected); n != 1 {
+		t.Errorf("expected 1 signup rejected event, got %d", n)
+	}
+}
+
+func TestCheckSignupAllowedInviteOnly(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	s.config.Account.SignupMode = configs.SignupModeInviteOnly
+	ctx := context.Background()
+
+	_, err := s.checkSignupAllowed(ctx, "new@example.com", "github")
+	if status.Code(err) != codes.PermissionDenied ||
+		errorReason(err) != ErrorReasonSignupInviteRequired {
+		t.Fatalf("expected an invite to be required, got %v", err)
+	}
+
+	invite := &repository.Invite{Email: "New@Example.com", CreatedAt: time.Now()}
+	if err := s.inviteRepo.Create(ctx, invite, time.Hour); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	invited, err := s.checkSignupAllowed(ctx, "new@example.com", "github")
+	if err != nil || !invited {
+		t.Fatalf("expected invited address to sign up, got invited=%v err=%v", invited, err)
+	}
+
+	// Closing signups at runtime wins over invites
+	if err := s.flags.Set(ctx, featureflags.SignupBlocked, true); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	_, err = s.checkSignupAllowed(ctx, "new@example.com", "github")
+	if errorReason(err) != ErrorReasonSignupDisabled {
+		t.Errorf("expected signups to be disabled, got %v", err)
+	}
+}
+
+func errorReason(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestLoginByOAuthCreatesUser(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	oauthProvider := testutil.NewOAuthProvider(t)
+	s.oauthConfigs = map[string]*oauth2.Config{
+		"github": oauthProvider.Config("https://portal.example.com/callback"),
+	}
+	s.userProviders = map[string]providerPkg.UserProvider{"github": oauthProvider}
+	ctx := context.Background()
+	userInfo := providerPkg.UserInfo{ID: "42", Name: "Octo", Email: "octo@example.com"}
+
+	login := func(code string) (*auth_v1_pb.LoginByOAuthResponse, error) {
+		state, err := s.generateState(ctx, "github", "", "", "")
+		if err != nil {
+			t.Fatalf("generateState failed: %v", err)
+		}
+		return s.LoginByOAuth(ctx, &auth_v1_pb.LoginByOAuthRequest{Code: code, State: state})
+	}
+
+	code := oauthProvider.IssueCode(userInfo)
+	resp, err := login(code)
+	if err != nil {
+		t.Fatalf("LoginByOAuth failed: %v", err)
+	}
+	user, err := s.userRepo.GetByGithubID(ctx, "42")
+	if err != nil {
+		t.Fatalf("expected the user to be created: %v", err)
+	}
+	userID, err := s.sessionRepo.GetUserID(ctx, resp.Session.Id)
+	if err != nil || userID != user.ID {
+		t.Errorf("expected a session of the new user, got %q (%v)", userID, err)
+	}
+
+	if _, err := login(code); err == nil {
+		t.Error("expected a replayed code to be rejected")
+	}
+	if _, err := login(oauthProvider.IssueCode(userInfo)); err != nil {
+		t.Fatalf("second login failed: %v", err)
+	}
+	if n, _ := s.userRepo.Count(ctx, repository.UserFilter{}); n != 1 {
+		t.Errorf("expected the second login to reuse the user, got %d users", n)
+	}
+}
+
+func TestDormantAccountReactivatedAtLogin(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	oauthProvider := testutil.NewOAuthProvider(t)
+	s.oauthConfigs = map[string]*oauth2.Config{
+		"github": oauthProvider.Config("https://portal.example.com/callback"),
+	}
+	s.userProviders = map[string]providerPkg.UserProvider{"github": oauthProvider}
+	ctx := context.Background()
+
+	deactivatedAt := time.Now().Add(-24 * time.Hour)
+	githubID := "42"
+	user := &model.UserModel{
+		ID:               "user-1",
+		Email:            "octo@example.com",
+		Role:             model.UserRoleUser,
+		DeactivatedAt:    &deactivatedAt,
+		DormancyWarnedAt: &deactivatedAt,
+	}
+	user.SetGithubID(&githubID)
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	// Sessions are revoked on deactivation, tokens are refused for any left over
+	sessionID, err := s.createSession(ctx, user)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	_, err = s.GetUserToken(ctx, &auth_v1_pb.GetUserTokenRequest{SessionId: sessionID})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied for a deactivated account, got %v", err)
+	}
+
+	state, err := s.generateState(ctx, "github", "", "", "")
+	if err != nil {
+		t.Fatalf("generateState failed: %v", err)
+	}
+	code := oauthProvider.IssueCode(providerPkg.UserInfo{ID: githubID, Email: user.Email})
+	resp, err := s.LoginByOAuth(ctx, &auth_v1_pb.LoginByOAuthRequest{Code: code, State: state})
+	if err != nil {
+		t.Fatalf("LoginByOAuth failed: %v", err)
+	}
+	stored, _ := s.userRepo.GetByID(ctx, user.ID)
+	if stored.DeactivatedAt != nil || stored.DormancyWarnedAt != nil {
+		t.Errorf("expected the login to reactivate the account, got %+v", stored)
+	}
+	auditRepo := s.auditRepo.(*testutil.AuditRepository)
+	if n := auditRepo.Count(model.AuditEventAccountReactivated); n != 1 {
+		t.Errorf("expected 1 reactivation event, got %d", n)
+	}
+	_, err = s.GetUserToken(ctx, &auth_v1_pb.GetUserTokenRequest{SessionId: resp.Session.Id})
+	if err != nil {
+		t.Errorf("expected a token after reactivation, got %v", err)
+	}
+}
+
+func BenchmarkGetUserToken(b *testing.B) {
+	s, _ := newTestAuthService(b)
+	ctx := context.Background()
+	enforcer, err := auth.NewEnforcer()
+	if err != nil {
+		b.Fatalf("failed to create enforcer: %v", err)
+	}
+	s.enforcer = enforcer
+	s.config.Auth.JWTSecret = "test-secret"
+	s.config.Auth.TokenScopes = configs.TokenScopesNames
+	user := &model.UserModel{ID: "user-1", Email: "user@example.com", Role: model.UserRoleUser}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		b.Fatalf("failed to create user: %v", err)
+	}
+	sessionID, err := s.createSession(ctx, user)
+	if err != nil {
+		b.Fatalf("failed to create session: %v", err)
+	}
+
+	req := &auth_v1_pb.GetUserTokenRequest{SessionId: sessionID}
+	for b.Loop() {
+		if _, err := s.GetUserToken(ctx, req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
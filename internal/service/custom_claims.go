@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/utils"
+)
+
+// addCustomClaims adds the custom claims of the user to a token being issued.
+// Tokens are issued without them if they can't be fetched or are too large, so
+// an outage of the claims source doesn't lock users out.
+func (s *authService) addCustomClaims(
+	ctx context.Context,
+	user *model.UserModel,
+	claims utils.UserTokenClaims,
+) {
+	if s.customClaims == nil {
+		return
+	}
+	custom, err := s.customClaims.Claims(ctx, user)
+	if err != nil {
+		slog.WarnContext(ctx, "issuing token without custom claims", "error", err)
+		return
+	}
+	maps.Copy(claims.MapClaims, custom)
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/customclaims"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/utils"
+)
+
+func TestGetUserTokenCustomClaims(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	s.config.Auth.JWTSecret = "test-secret"
+	ctx := context.Background()
+	var claimsErr error
+	s.customClaims = customclaims.NewEnricher(s.rdb, configs.ClaimsConfig{MaxBytes: 1024},
+		customclaims.ProviderFunc(func(context.Context, *model.UserModel) (map[string]any, error) {
+			return map[string]any{"team_ids": []string{"t-1"}}, claimsErr
+		}),
+	)
+	user := &model.UserModel{ID: "user-1", Email: "user@example.com", Role: model.UserRoleUser}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	sessionID, err := s.createSession(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenClaims := func() *utils.UserTokenClaims {
+		t.Helper()
+		resp, err := s.GetUserToken(ctx, &auth_v1_pb.GetUserTokenRequest{SessionId: sessionID})
+		if err != nil {
+			t.Fatalf("GetUserToken failed: %v", err)
+		}
+		claims, err := utils.ValidateUserToken(resp.Token.Token, s.config.Auth.JWTSecret)
+		if err != nil {
+			t.Fatalf("ValidateUserToken failed: %v", err)
+		}
+		return claims
+	}
+
+	if teams, ok := tokenClaims().MapClaims["team_ids"].([]any); !ok || teams[0] != "t-1" {
+		t.Errorf("expected the custom claims in the token")
+	}
+	claimsErr = errors.New("claims source down")
+	if _, ok := tokenClaims().MapClaims["team_ids"]; ok {
+		t.Errorf("expected tokens without custom claims while their source fails")
+	}
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/risk"
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// userCodeAlphabet has no vowels, so user codes never spell words, and no
+	// letters easily confused when typed from another screen
+	userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength   = 8
+	// maxClientNameLength bounds the client name shown to users for approval
+	maxClientNameLength = 64
+	// slowDownIncrement is added to the poll interval of devices polling too fast
+	slowDownIncrement = 5 * time.Second
+)
+
+// generateUserCode returns a random user code without its separator.
+func generateUserCode() (string, error) {
+	code := make([]byte, userCodeLength)
+	size := big.NewInt(int64(len(userCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", err
+		}
+		code[i] = userCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// normalizeUserCode accepts user codes as typed, in any case and with or
+// without separators.
+func normalizeUserCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+}
+
+// formatUserCode splits a user code in halves for display, e.g. "WDJB-MJHT".
+func formatUserCode(code string) string {
+	return code[:userCodeLength/2] + "-" + code[userCodeLength/2:]
+}
+
+// StartDeviceAuthorization starts the login of a device without a browser, such
+// as a CLI. The device shows the user code and polls PollDeviceAuthorization
+// until the user approves the login at the verification URL.
+func (s *authService) StartDeviceAuthorization(
+	ctx context.Context,
+	req *auth_v1_pb.StartDeviceAuthorizationRequest,
+) (*auth_v1_pb.StartDeviceAuthorizationResponse, error) {
+	clientName := strings.TrimSpace(req.ClientName)
+	if len(clientName) > maxClientNameLength {
+		clientName = clientName[:maxClientNameLength]
+	}
+	deviceCode, err := utils.GenerateToken(32)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate device code: %v", err)
+	}
+
+	now := time.Now()
+	auth := &repository.DeviceAuthorization{
+		ClientName: clientName,
+		Status:     repository.DeviceAuthorizationPending,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(s.config.Auth.DeviceCodeExpiration),
+		Interval:   s.config.Auth.DevicePollInterval,
+	}
+	// User codes are short, so retry the rare collision with a pending one
+	for attempt := 0; ; attempt++ {
+		auth.UserCode, err = generateUserCode()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to generate user code: %v", err)
+		}
+		err = s.deviceAuths.Create(ctx, deviceCode, auth)
+		if !errors.Is(err, repository.ErrUserCodeTaken) || attempt == 2 {
+			break
+		}
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to store device authorization", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to store device authorization: %v", err)
+	}
+
+	slog.InfoContext(ctx, "device authorization started",
+		"client_name", clientName,
+		"ip_address", extractIPAddress(ctx))
+	userCode := formatUserCode(auth.UserCode)
+	verificationURI := strings.TrimSuffix(s.config.Mailer.LinkBaseURL, "/") + "/activate"
+	return &auth_v1_pb.StartDeviceAuthorizationResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                userCode,
+		VerificationUri:         verificationURI,
+		VerificationUriComplete: verificationURI + "?user_code=" + url.QueryEscape(userCode),
+		ExpiresAt:               timestamppb.New(auth.ExpiresAt),
+		Interval:                uint32(auth.Interval.Seconds()),
+	}, nil
+}
+
+// ApproveDeviceAuthorization lets the logged-in user approve or deny the device
+// login showing the given user code.
+func (s *authService) ApproveDeviceAuthorization(
+	ctx context.Context,
+	req *auth_v1_pb.ApproveDeviceAuthorizationRequest,
+) (*auth_v1_pb.ApproveDeviceAuthorizationResponse, error) {
+	userID := callerID(ctx)
+	if userID == "" {
+		return nil, status.Errorf(codes.Unauthenticated, "user not authenticated")
+	}
+	userCode := normalizeUserCode(req.UserCode)
+	if len(userCode) != userCodeLength {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user code")
+	}
+
+	auth, err := s.deviceAuths.GetByUserCode(ctx, userCode)
+	if errors.Is(err, repository.ErrDeviceAuthorizationNotFound) {
+		return nil, status.Errorf(codes.NotFound, "unknown or expired user code")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get device authorization: %v", err)
+	}
+	if auth.Status != repository.DeviceAuthorizationPending {
+		return nil, status.Errorf(
+			codes.FailedPrecondition,
+			"device authorization was already approved or denied",
+		)
+	}
+
+	auth.Status = repository.DeviceAuthorizationApproved
+	if req.Deny {
+		auth.Status = repository.DeviceAuthorizationDenied
+	}
+	auth.UserID = userID
+	err = s.deviceAuths.Save(ctx, auth)
+	if errors.Is(err, repository.ErrDeviceAuthorizationNotFound) {
+		return nil, status.Errorf(codes.NotFound, "unknown or expired user code")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to save device authorization: %v", err)
+	}
+
+	slog.InfoContext(ctx, "device authorization decided",
+		"client_name", auth.ClientName,
+		"status", auth.Status)
+	return &auth_v1_pb.ApproveDeviceAuthorizationResponse{ClientName: auth.ClientName}, nil
+}
+
+// PollDeviceAuthorization returns the session of an approved device login. Until
+// the user decides, it fails with the AUTHORIZATION_PENDING reason, or SLOW_DOWN
+// if the device polls faster than the interval; each approval is redeemed once.
+func (s *authService) PollDeviceAuthorization(
+	ctx context.Context,
+	req *auth_v1_pb.PollDeviceAuthorizationRequest,
+) (*auth_v1_pb.PollDeviceAuthorizationResponse, error) {
+	expired := errorWithReason(
+		codes.NotFound,
+		ErrorReasonDeviceCodeExpired,
+		"device code is invalid or expired",
+	)
+	if req.DeviceCode == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "device code is required")
+	}
+	auth, err := s.deviceAuths.Get(ctx, req.DeviceCode)
+	if errors.Is(err, repository.ErrDeviceAuthorizationNotFound) {
+		return nil, expired
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get device authorization: %v", err)
+	}
+
+	switch auth.Status {
+	case repository.DeviceAuthorizationPending:
+		return nil, s.throttleDevicePoll(ctx, auth)
+	case repository.DeviceAuthorizationDenied:
+		if err := s.deviceAuths.Delete(ctx, auth); err != nil &&
+			!errors.Is(err, repository.ErrDeviceAuthorizationNotFound) {
+			slog.WarnContext(ctx, "failed to delete denied device authorization", "error", err)
+		}
+		return nil, errorWithReason(
+			codes.PermissionDenied,
+			ErrorReasonAccessDenied,
+			"the user denied the device login",
+		)
+	}
+
+	// Only the poll deleting the approval gets the session
+	err = s.deviceAuths.Delete(ctx, auth)
+	if errors.Is(err, repository.ErrDeviceAuthorizationNotFound) {
+		return nil, expired
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to redeem device authorization: %v", err)
+	}
+	user, err := s.userRepo.GetByID(ctx, auth.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "user not found")
+	}
+	if err := s.checkMaintenance(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// The device logging in is the one polling
+	attempt := risk.Attempt{
+		UserID:    user.ID,
+		Email:     user.Email,
+		IPAddress: extractIPAddress(ctx),
+		UserAgent: extractUserAgent(ctx),
+	}
+	assessment, err := s.assessLoginRisk(ctx, attempt, "device")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.LastLoginAt = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		slog.ErrorContext(ctx, "failed to update user last login", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
+	}
+	if err := s.reactivateDormant(ctx, user); err != nil {
+		return nil, err
+	}
+	sessionID, err := s.createSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.rememberLogin(ctx, attempt)
+	s.alertNewLogin(ctx, user, attempt, assessment)
+	metadata := assessment.Metadata()
+	metadata["method"] = "device"
+	if auth.ClientName != "" {
+		metadata["client_name"] = auth.ClientName
+	}
+	recordAuditEvent(ctx, s.auditRepo, model.AuditEventLoginSucceeded, &user.ID, metadata)
+	slog.InfoContext(ctx, "device login completed successfully",
+		"session_id", sessionID[:16],
+		"client_name", auth.ClientName)
+
+	expiresAt, err := s.getSessionExpirationTime(ctx, sessionID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get session expiration: %v", err)
+	}
+	return &auth_v1_pb.PollDeviceAuthorizationResponse{
+		Session: &auth_v1_pb.LoginSession{
+			Id:        sessionID,
+			ExpiresAt: timestamppb.New(expiresAt),
+		},
+	}, nil
+}
+
+// throttleDevicePoll records a poll of a pending authorization and returns the
+// error telling the device to keep polling, or to slow down if it polled within
+// the interval, which then grows as RFC 8628 requires.
+func (s *authService) throttleDevicePoll(
+	ctx context.Context,
+	auth *repository.DeviceAuthorization,
+) error {
+	now := time.Now()
+	tooFast := now.Sub(auth.LastPolledAt) < auth.Interval
+	if tooFast {
+		auth.Interval += slowDownIncrement
+	}
+	auth.LastPolledAt = now
+	// Only the poll is recorded, so an approval made since auth was read stays
+	if err := s.deviceAuths.RecordPoll(ctx, auth); err != nil &&
+		!errors.Is(err, repository.ErrDeviceAuthorizationNotFound) {
+		slog.WarnContext(ctx, "failed to record device poll", "error", err)
+	}
+	if tooFast {
+		return errorWithReason(
+			codes.ResourceExhausted,
+			ErrorReasonSlowDown,
+			"polling too fast, wait longer between polls",
+		)
+	}
+	return errorWithReason(
+		codes.FailedPrecondition,
+		ErrorReasonAuthorizationPending,
+		"the user has not approved the device login yet",
+	)
+}
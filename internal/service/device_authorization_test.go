@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/poly-workshop/auth-portal/configs"
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/notify"
+	"github.com/poly-workshop/auth-portal/internal/risk"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"github.com/poly-workshop/auth-portal/internal/throttle"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newTestDeviceAuthService(t *testing.T) (*authService, *miniredis.Miniredis) {
+	t.Helper()
+	s, mr := newTestAuthService(t)
+	s.config.Auth.DeviceCodeExpiration = 10 * time.Minute
+	s.config.Auth.DevicePollInterval = 5 * time.Second
+	s.config.Mailer.LinkBaseURL = "https://portal.example.com/"
+	if err := s.userRepo.Create(context.Background(), &model.UserModel{
+		ID:    "user-1",
+		Email: "user@example.com",
+		Role:  model.UserRoleUser,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return s, mr
+}
+
+func asUser(userID string) context.Context {
+	return context.WithValue(
+		context.Background(),
+		auth.ContextKeyUserInfo,
+		&auth.UserInfo{UserID: userID},
+	)
+}
+
+func TestDeviceAuthorization(t *testing.T) {
+	s, mr := newTestDeviceAuthService(t)
+	ctx := context.Background()
+
+	start, err := s.StartDeviceAuthorization(ctx, &auth_v1_pb.StartDeviceAuthorizationRequest{
+		ClientName: "authctl",
+	})
+	if err != nil {
+		t.Fatalf("StartDeviceAuthorization failed: %v", err)
+	}
+	if len(start.UserCode) != 9 || start.UserCode[4] != '-' {
+		t.Errorf("expected a user code like WDJB-MJHT, got %q", start.UserCode)
+	}
+	if start.VerificationUri != "https://portal.example.com/activate" ||
+		!strings.HasSuffix(start.VerificationUriComplete, "?user_code="+start.UserCode) {
+		t.Errorf("unexpected verification URLs %q and %q",
+			start.VerificationUri, start.VerificationUriComplete)
+	}
+	if start.Interval != 5 {
+		t.Errorf("expected an interval of 5 seconds, got %d", start.Interval)
+	}
+
+	poll := func() error {
+		_, err := s.PollDeviceAuthorization(ctx, &auth_v1_pb.PollDeviceAuthorizationRequest{
+			DeviceCode: start.DeviceCode,
+		})
+		return err
+	}
+	if err := poll(); errorReason(err) != ErrorReasonAuthorizationPending {
+		t.Fatalf("expected the authorization to be pending, got %v", err)
+	}
+	if err := poll(); errorReason(err) != ErrorReasonSlowDown {
+		t.Fatalf("expected to be told to slow down, got %v", err)
+	}
+	stored, _ := s.deviceAuths.Get(ctx, start.DeviceCode)
+	if stored.Interval != 10*time.Second {
+		t.Errorf("expected the interval to grow to 10s, got %v", stored.Interval)
+	}
+
+	// Users type codes as they like
+	typed := strings.ToLower(strings.ReplaceAll(start.UserCode, "-", " "))
+	approved, err := s.ApproveDeviceAuthorization(
+		asUser("user-1"),
+		&auth_v1_pb.ApproveDeviceAuthorizationRequest{UserCode: typed},
+	)
+	if err != nil {
+		t.Fatalf("ApproveDeviceAuthorization failed: %v", err)
+	}
+	if approved.ClientName != "authctl" {
+		t.Errorf("expected the client name, got %q", approved.ClientName)
+	}
+	_, err = s.ApproveDeviceAuthorization(
+		asUser("user-1"),
+		&auth_v1_pb.ApproveDeviceAuthorizationRequest{UserCode: start.UserCode, Deny: true},
+	)
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected a decided authorization to stay decided, got %v", err)
+	}
+
+	resp, err := s.PollDeviceAuthorization(ctx, &auth_v1_pb.PollDeviceAuthorizationRequest{
+		DeviceCode: start.DeviceCode,
+	})
+	if err != nil {
+		t.Fatalf("PollDeviceAuthorization failed: %v", err)
+	}
+	if userID, err := s.sessionRepo.GetUserID(ctx, resp.Session.Id); err != nil ||
+		userID != "user-1" {
+		t.Errorf("expected a session of user-1, got %q, %v", userID, err)
+	}
+	if err := poll(); errorReason(err) != ErrorReasonDeviceCodeExpired {
+		t.Errorf("expected the device code to be redeemed once, got %v", err)
+	}
+	if keys := mr.Keys(); strings.Contains(strings.Join(keys, " "), "device_") {
+		t.Errorf("expected the authorization to be removed, got keys %v", keys)
+	}
+}
+
+func TestDeviceAuthorizationDenied(t *testing.T) {
+	s, _ := newTestDeviceAuthService(t)
+	ctx := context.Background()
+
+	start, err := s.StartDeviceAuthorization(ctx, &auth_v1_pb.StartDeviceAuthorizationRequest{})
+	if err != nil {
+		t.Fatalf("StartDeviceAuthorization failed: %v", err)
+	}
+	_, err = s.ApproveDeviceAuthorization(
+		asUser("user-1"),
+		&auth_v1_pb.ApproveDeviceAuthorizationRequest{UserCode: start.UserCode, Deny: true},
+	)
+	if err != nil {
+		t.Fatalf("ApproveDeviceAuthorization failed: %v", err)
+	}
+	_, err = s.PollDeviceAuthorization(ctx, &auth_v1_pb.PollDeviceAuthorizationRequest{
+		DeviceCode: start.DeviceCode,
+	})
+	if errorReason(err) != ErrorReasonAccessDenied {
+		t.Errorf("expected the login to be denied, got %v", err)
+	}
+
+	_, err = s.ApproveDeviceAuthorization(
+		asUser("user-1"),
+		&auth_v1_pb.ApproveDeviceAuthorizationRequest{UserCode: "BCDF-GHJK"},
+	)
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected unknown user codes to be rejected, got %v", err)
+	}
+}
+
+func TestDevicePollKeepsConcurrentApproval(t *testing.T) {
+	s, _ := newTestDeviceAuthService(t)
+	ctx := context.Background()
+
+	start, err := s.StartDeviceAuthorization(ctx, &auth_v1_pb.StartDeviceAuthorizationRequest{})
+	if err != nil {
+		t.Fatalf("StartDeviceAuthorization failed: %v", err)
+	}
+	// The poll reads the authorization while it is pending...
+	polled, err := s.deviceAuths.Get(ctx, start.DeviceCode)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	// ...the user approves it...
+	_, err = s.ApproveDeviceAuthorization(
+		asUser("user-1"),
+		&auth_v1_pb.ApproveDeviceAuthorizationRequest{UserCode: start.UserCode},
+	)
+	if err != nil {
+		t.Fatalf("ApproveDeviceAuthorization failed: %v", err)
+	}
+	// ...and then the poll is recorded
+	if err := s.throttleDevicePoll(ctx, polled); errorReason(err) !=
+		ErrorReasonAuthorizationPending {
+		t.Fatalf("expected the poll to report the state it read, got %v", err)
+	}
+
+	resp, err := s.PollDeviceAuthorization(ctx, &auth_v1_pb.PollDeviceAuthorizationRequest{
+		DeviceCode: start.DeviceCode,
+	})
+	if err != nil || resp.Session == nil {
+		t.Fatalf("expected the approval to survive the poll, got %v", err)
+	}
+}
+
+func TestDeviceLoginAlert(t *testing.T) {
+	s, _ := newTestDeviceAuthService(t)
+	mail := &recordingMailer{}
+	s.notifier = notify.NewNotifier(mail, testutil.NewNotificationPreferencesRepository())
+	s.risk = risk.NewScorer(s.rdb, throttle.NewLoginThrottle(s.rdb, configs.ThrottleConfig{}),
+		configs.RiskConfig{
+			Enabled:           true,
+			BlockThreshold:    100,
+			VelocityWindow:    time.Hour,
+			VelocityMaxLogins: 10,
+			History:           time.Hour,
+		})
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs("user-agent", "new-agent"))
+	s.rememberLogin(ctx, risk.Attempt{UserID: "user-1", UserAgent: "known-agent"})
+
+	start, err := s.StartDeviceAuthorization(ctx, &auth_v1_pb.StartDeviceAuthorizationRequest{})
+	if err != nil {
+		t.Fatalf("StartDeviceAuthorization failed: %v", err)
+	}
+	if _, err := s.ApproveDeviceAuthorization(
+		asUser("user-1"),
+		&auth_v1_pb.ApproveDeviceAuthorizationRequest{UserCode: start.UserCode},
+	); err != nil {
+		t.Fatalf("ApproveDeviceAuthorization failed: %v", err)
+	}
+	if _, err := s.PollDeviceAuthorization(ctx, &auth_v1_pb.PollDeviceAuthorizationRequest{
+		DeviceCode: start.DeviceCode,
+	}); err != nil {
+		t.Fatalf("PollDeviceAuthorization failed: %v", err)
+	}
+	if len(mail.sent["user@example.com"]) != 1 {
+		t.Errorf("expected a login alert for the new device, got %v", mail.sent)
+	}
+	if user, _ := s.userRepo.GetByID(ctx, "user-1"); user.LastLoginAt == nil {
+		t.Error("expected the last login to be recorded")
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/mail"
+	"strings"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// CheckEmailAvailable reports whether no account uses the email address yet.
+// It only answers yes or no, and is rate limited per client network (and
+// optionally behind a CAPTCHA) so it can't be used to enumerate accounts.
+func (s *authService) CheckEmailAvailable(
+	ctx context.Context,
+	req *auth_v1_pb.CheckEmailAvailableRequest,
+) (*auth_v1_pb.CheckEmailAvailableResponse, error) {
+	ipAddress := extractIPAddress(ctx)
+	network := utils.TruncateIP(ipAddress)
+	if network == "" {
+		network = "unknown"
+	}
+	allowed, err := s.emailChecks.Allow(ctx, network)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to check email check rate limit", "error", err)
+	} else if !allowed {
+		return nil, status.Error(codes.ResourceExhausted, "too many email checks, try again later")
+	}
+	email := strings.TrimSpace(req.Email)
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid email address")
+	}
+
+	if s.config.Account.EmailCheckCaptcha {
+		if s.captcha == nil {
+			slog.ErrorContext(ctx, "email_check_captcha is enabled but no CAPTCHA is configured")
+			return nil, errorWithReason(codes.Unavailable, ErrorReasonDependencyUnavailable,
+				"CAPTCHA verification is unavailable")
+		}
+		solved, err := s.captcha.Verify(ctx, req.CaptchaToken, ipAddress)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to verify CAPTCHA", "error", err)
+			return nil, errorWithReason(codes.Unavailable, ErrorReasonDependencyUnavailable,
+				"CAPTCHA verification is unavailable")
+		}
+		if !solved {
+			return nil, errorWithReason(
+				codes.PermissionDenied,
+				ErrorReasonCaptchaRequired,
+				"CAPTCHA verification failed",
+			)
+		}
+	}
+
+	_, err = s.userRepo.GetByEmail(ctx, email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &auth_v1_pb.CheckEmailAvailableResponse{Available: true}, nil
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to look up email: %v", err)
+	}
+	return &auth_v1_pb.CheckEmailAvailableResponse{Available: false}, nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/throttle"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeCaptcha accepts the token "solved".
+type fakeCaptcha struct{}
+
+func (fakeCaptcha) Verify(_ context.Context, token, _ string) (bool, error) {
+	return token == "solved", nil
+}
+
+func TestCheckEmailAvailable(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	s.emailChecks = throttle.NewRateLimiter(s.rdb, "email_check", 3, time.Minute)
+	ctx := context.Background()
+	if err := s.userRepo.Create(ctx, &model.UserModel{Email: "taken@example.com"}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	check := func(email, captchaToken string) (bool, error) {
+		resp, err := s.CheckEmailAvailable(ctx, &auth_v1_pb.CheckEmailAvailableRequest{
+			Email:        email,
+			CaptchaToken: captchaToken,
+		})
+		return resp.GetAvailable(), err
+	}
+
+	if available, err := check("taken@example.com", ""); err != nil || available {
+		t.Errorf("expected a used email to be unavailable, got %v, %v", available, err)
+	}
+	if available, err := check("free@example.com", ""); err != nil || !available {
+		t.Errorf("expected an unused email to be available, got %v, %v", available, err)
+	}
+	if _, err := check("not an email", ""); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected an invalid email to be rejected, got %v", err)
+	}
+	if _, err := check("free@example.com", ""); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected the fourth check to be rate limited, got %v", err)
+	}
+
+	s.emailChecks = throttle.NewRateLimiter(s.rdb, "email_check_captcha", 0, time.Minute)
+	s.config.Account.EmailCheckCaptcha = true
+	s.captcha = fakeCaptcha{}
+	_, err := check("free@example.com", "wrong")
+	if errorReason(err) != ErrorReasonCaptchaRequired {
+		t.Errorf("expected an unsolved CAPTCHA to be rejected, got %v", err)
+	}
+	if available, err := check("free@example.com", "solved"); err != nil || !available {
+		t.Errorf("expected a solved CAPTCHA to be accepted, got %v, %v", available, err)
+	}
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"time"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+// RequestEmailChange starts an email change for the current user. The change is
+// only applied once both the old and the new address confirmed it, so a stolen
+// session alone is not enough to take over the account.
+func (s *userService) RequestEmailChange(
+	ctx context.Context,
+	req *user_v1_pb.RequestEmailChangeRequest,
+) (*user_v1_pb.RequestEmailChangeResponse, error) {
+	user, err := s.getCurrentUserModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if addr, err := mail.ParseAddress(newEmail); err != nil || addr.Address != newEmail {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid email address")
+	}
+	if strings.EqualFold(newEmail, user.Email) {
+		return nil, status.Errorf(codes.InvalidArgument, "new email equals the current email")
+	}
+	if err := s.ensureEmailAvailable(ctx, newEmail); err != nil {
+		return nil, err
+	}
+
+	change := &repository.EmailChange{
+		UserID:   user.ID,
+		OldEmail: user.Email,
+		NewEmail: newEmail,
+	}
+	tokens, err := s.emailChangeRepo.Create(
+		ctx,
+		change,
+		s.config.Account.EmailChangeExpiration,
+		s.config.Account.EmailChangeRollback,
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to store email change", "error", err, "user_id", user.ID)
+		return nil, status.Errorf(codes.Internal, "failed to store email change: %v", err)
+	}
+
+	baseURL := strings.TrimSuffix(s.config.Mailer.LinkBaseURL, "/")
+	if err := s.mailer.Send(
+		ctx,
+		change.OldEmail,
+		"Confirm your email change",
+		fmt.Sprintf(
+			"A change of your account email to %s was requested.\n\n"+
+				"Confirm the change: %s/email-change/confirm?token=%s\n\n"+
+				"If you did not request this, secure your account: %s/email-change/rollback?token=%s\n",
+			change.NewEmail,
+			baseURL,
+			tokens.OldToken,
+			baseURL,
+			tokens.RollbackToken,
+		),
+	); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to send confirmation email: %v", err)
+	}
+	if err := s.mailer.Send(
+		ctx,
+		change.NewEmail,
+		"Confirm your new email address",
+		fmt.Sprintf(
+			"Confirm this address for your account: %s/email-change/confirm?token=%s\n",
+			baseURL,
+			tokens.NewToken,
+		),
+	); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to send confirmation email: %v", err)
+	}
+
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventEmailChangeRequested,
+		&user.ID,
+		map[string]string{"new_email": change.NewEmail},
+	)
+	slog.InfoContext(ctx, "email change requested", "user_id", user.ID)
+
+	return &user_v1_pb.RequestEmailChangeResponse{
+		ExpiresAt: timestamppb.New(time.Now().Add(s.config.Account.EmailChangeExpiration)),
+	}, nil
+}
+
+// ConfirmEmailChange consumes a confirmation token from either address and
+// applies the change once both sides have confirmed.
+func (s *userService) ConfirmEmailChange(
+	ctx context.Context,
+	req *user_v1_pb.ConfirmEmailChangeRequest,
+) (*user_v1_pb.ConfirmEmailChangeResponse, error) {
+	if req.Token == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "token is required")
+	}
+
+	change, err := s.emailChangeRepo.Confirm(ctx, req.Token)
+	if err != nil {
+		if errors.Is(err, repository.ErrEmailChangeNotFound) {
+			return nil, status.Errorf(codes.NotFound, "invalid or expired token")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to confirm email change: %v", err)
+	}
+	if !change.OldConfirmed || !change.NewConfirmed {
+		return &user_v1_pb.ConfirmEmailChangeResponse{Completed: false}, nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, change.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "failed to get user: %v", err)
+	}
+	if user.Email != change.OldEmail {
+		return nil, status.Errorf(codes.FailedPrecondition, "email was changed in the meantime")
+	}
+	if err := s.ensureEmailAvailable(ctx, change.NewEmail); err != nil {
+		return nil, err
+	}
+
+	user.Email = change.NewEmail
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update email: %v", err)
+	}
+	if err := s.emailChangeRepo.Delete(ctx, change); err != nil {
+		slog.WarnContext(ctx, "failed to delete applied email change", "error", err)
+	}
+
+	recordAuditEvent(ctx, s.auditRepo, model.AuditEventEmailChanged, &user.ID, nil)
+	slog.InfoContext(ctx, "email change completed", "user_id", user.ID)
+	return &user_v1_pb.ConfirmEmailChangeResponse{Completed: true}, nil
+}
+
+// RollbackEmailChange restores the previous email (or cancels a pending change)
+// and revokes all sessions, since an unwanted change hints at a compromised session.
+func (s *userService) RollbackEmailChange(
+	ctx context.Context,
+	req *user_v1_pb.RollbackEmailChangeRequest,
+) (*user_v1_pb.RollbackEmailChangeResponse, error) {
+	if req.Token == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "token is required")
+	}
+
+	change, err := s.emailChangeRepo.ConsumeRollback(ctx, req.Token)
+	if err != nil {
+		if errors.Is(err, repository.ErrEmailChangeNotFound) {
+			return nil, status.Errorf(codes.NotFound, "invalid or expired token")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to roll back email change: %v", err)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, change.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "failed to get user: %v", err)
+	}
+	if user.Email == change.NewEmail {
+		user.Email = change.OldEmail
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to restore email: %v", err)
+		}
+	}
+	if err := s.emailChangeRepo.Delete(ctx, change); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to cancel email change: %v", err)
+	}
+	if _, err := s.sessionRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke sessions: %v", err)
+	}
+
+	recordAuditEvent(ctx, s.auditRepo, model.AuditEventEmailChangeRolledBack, &user.ID, nil)
+	slog.InfoContext(ctx, "email change rolled back", "user_id", user.ID)
+	return &user_v1_pb.RollbackEmailChangeResponse{}, nil
+}
+
+func (s *userService) ensureEmailAvailable(ctx context.Context, email string) error {
+	_, err := s.userRepo.GetByEmail(ctx, email)
+	if err == nil {
+		return status.Errorf(codes.AlreadyExists, "email is already in use")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return status.Errorf(codes.Internal, "failed to query user: %v", err)
+	}
+	return nil
+}
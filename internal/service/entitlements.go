@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AdminUpdateUserEntitlements adds and removes entitlements of a user. Tokens
+// issued before are outdated afterwards, so the change reaches product
+// services with the next token refresh.
+func (s *userService) AdminUpdateUserEntitlements(
+	ctx context.Context,
+	req *user_v1_pb.AdminUpdateUserEntitlementsRequest,
+) (*user_v1_pb.AdminUpdateUserEntitlementsResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id is required")
+	}
+	for _, name := range slices.Concat(req.Add, req.Remove) {
+		if !model.ValidEntitlement(name) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid entitlement %q", name)
+		}
+	}
+	user, err := s.userRepo.GetByID(ctx, req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "failed to get user: %v", err)
+	}
+
+	version := user.Version
+	previous := user.Entitlements
+	if !user.UpdateEntitlements(req.Add, req.Remove) {
+		return &user_v1_pb.AdminUpdateUserEntitlementsResponse{
+			Entitlements: user.Entitlements,
+		}, nil
+	}
+	if len(user.Entitlements) > model.MaxEntitlements {
+		return nil, status.Errorf(
+			codes.InvalidArgument,
+			"users can have at most %d entitlements",
+			model.MaxEntitlements,
+		)
+	}
+	err = s.userRepo.UpdateIfVersion(ctx, user, version)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return nil, status.Errorf(codes.Aborted, "user was modified concurrently, retry")
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to update entitlements", "error", err, "user_id", user.ID)
+		return nil, status.Errorf(codes.Internal, "failed to update entitlements: %v", err)
+	}
+	// Invalidate tokens carrying the previous entitlements
+	if _, err := s.roleVersions.Bump(ctx, user.ID); err != nil {
+		slog.ErrorContext(ctx, "failed to bump role version", "error", err, "user_id", user.ID)
+		return nil, status.Errorf(
+			codes.Internal,
+			"failed to propagate entitlement change: %v",
+			err,
+		)
+	}
+
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventEntitlementsChanged,
+		&user.ID,
+		map[string]string{
+			"admin_id": callerID(ctx),
+			"from":     strings.Join(previous, " "),
+			"to":       strings.Join(user.Entitlements, " "),
+		},
+	)
+	slog.InfoContext(ctx, "entitlements changed",
+		"user_id", user.ID,
+		"admin_id", callerID(ctx),
+		"entitlements", user.Entitlements)
+	return &user_v1_pb.AdminUpdateUserEntitlementsResponse{
+		Entitlements: user.Entitlements,
+	}, nil
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdminUpdateUserEntitlements(t *testing.T) {
+	rdb, _ := testutil.NewRedis(t)
+	user := &model.UserModel{ID: "user-1", Email: "user@example.com", Role: model.UserRoleUser}
+	auditRepo := testutil.NewAuditRepository()
+	s := &userService{
+		userRepo:     testutil.NewUserRepository(user),
+		auditRepo:    auditRepo,
+		roleVersions: repository.NewRoleVersionRepository(rdb),
+	}
+	ctx := asUser("admin-1")
+
+	resp, err := s.AdminUpdateUserEntitlements(ctx, &user_v1_pb.AdminUpdateUserEntitlementsRequest{
+		UserId: user.ID,
+		Add:    []string{"reports.export", "plan:pro", "reports.export"},
+	})
+	if err != nil {
+		t.Fatalf("AdminUpdateUserEntitlements failed: %v", err)
+	}
+	if !slices.Equal(resp.Entitlements, []string{"plan:pro", "reports.export"}) {
+		t.Errorf("expected sorted entitlements without duplicates, got %v", resp.Entitlements)
+	}
+	if version, _ := s.roleVersions.Get(ctx, user.ID); version != 1 {
+		t.Errorf("expected earlier tokens to be outdated, got role version %d", version)
+	}
+
+	resp, err = s.AdminUpdateUserEntitlements(ctx, &user_v1_pb.AdminUpdateUserEntitlementsRequest{
+		UserId: user.ID,
+		Remove: []string{"plan:pro"},
+	})
+	if err != nil || !slices.Equal(resp.Entitlements, []string{"reports.export"}) {
+		t.Errorf("expected plan:pro to be removed, got %v, %v", resp, err)
+	}
+	if n := auditRepo.Count(model.AuditEventEntitlementsChanged); n != 2 {
+		t.Errorf("expected 2 audit events, got %d", n)
+	}
+
+	_, err = s.AdminUpdateUserEntitlements(ctx, &user_v1_pb.AdminUpdateUserEntitlementsRequest{
+		UserId: user.ID,
+		Add:    []string{"Plan Pro"},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an invalid name, got %v", err)
+	}
+}
+
+func TestGetUserTokenEntitlements(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	s.config.Auth.JWTSecret = "test-secret"
+	ctx := context.Background()
+	user := &model.UserModel{
+		ID:           "user-1",
+		Email:        "user@example.com",
+		Role:         model.UserRoleUser,
+		Entitlements: []string{"plan:pro"},
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	sessionID, err := s.createSession(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := s.GetUserToken(ctx, &auth_v1_pb.GetUserTokenRequest{SessionId: sessionID})
+	if err != nil {
+		t.Fatalf("GetUserToken failed: %v", err)
+	}
+
+	info, err := auth.ParseUserToken(resp.Token.Token, s.config.Auth.JWTSecret)
+	if err != nil {
+		t.Fatalf("ParseUserToken failed: %v", err)
+	}
+	if !info.HasEntitlement("plan:pro") || info.HasEntitlement("plan:enterprise") {
+		t.Errorf("expected the token to carry the entitlements, got %v", info.Entitlements)
+	}
+}
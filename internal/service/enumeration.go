@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// checkEnumeration fails with ResourceExhausted if the calling admin read
+// their daily quota of users. Calls of internal credentials, bounded by their
+// own quotas, aren't counted.
+func (s *userService) checkEnumeration(ctx context.Context) error {
+	principal := callerID(ctx)
+	if principal == "" {
+		return nil
+	}
+	err := s.enumeration.Check(ctx, principal)
+	if err != nil && status.Code(err) != codes.ResourceExhausted {
+		slog.WarnContext(ctx, "failed to check enumeration quota", "error", err)
+		return nil
+	}
+	return err
+}
+
+// recordEnumeration counts the n users the calling admin read through method
+// and raises an alert if that made them read unusually many today.
+func (s *userService) recordEnumeration(ctx context.Context, method string, n int) {
+	principal := callerID(ctx)
+	if principal == "" {
+		return
+	}
+	alert, err := s.enumeration.Record(ctx, principal, method, n)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to record enumeration", "error", err)
+		return
+	}
+	if !alert {
+		return
+	}
+	threshold := strconv.FormatInt(s.config.Enumeration.AlertUsers, 10)
+	slog.WarnContext(
+		ctx,
+		"admin read unusually many users today",
+		"admin_id",
+		principal,
+		"method",
+		method,
+		"threshold",
+		threshold,
+	)
+	recordAuditEvent(ctx, s.auditRepo, model.AuditEventEnumerationAlert, &principal,
+		map[string]string{"method": method, "threshold": threshold})
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is the ErrorInfo domain of errors raised by this service and its gateway.
+const ErrorDomain = auth.ErrorDomain
+
+// ErrorInfo reasons clients can map to their own messages
+const (
+	ErrorReasonSignupDisabled       = "SIGNUP_DISABLED"
+	ErrorReasonSignupInviteRequired = "SIGNUP_INVITE_REQUIRED"
+	ErrorReasonCaptchaRequired      = "CAPTCHA_REQUIRED"
+	ErrorReasonLoginRequired        = "LOGIN_REQUIRED"
+	// ErrorReasonForbidden is set by the gateway on PERMISSION_DENIED errors
+	// without a reason of their own: logging in again won't help
+	ErrorReasonForbidden = "FORBIDDEN"
+	// ErrorReasonSignupRejected is raised when a provisioning hook rejects a signup
+	ErrorReasonSignupRejected = "SIGNUP_REJECTED"
+	// Device authorization (RFC 8628) polling outcomes
+	ErrorReasonAuthorizationPending = "AUTHORIZATION_PENDING"
+	ErrorReasonSlowDown             = "SLOW_DOWN"
+	ErrorReasonAccessDenied         = "ACCESS_DENIED"
+	ErrorReasonDeviceCodeExpired    = "DEVICE_CODE_EXPIRED"
+	// ErrorReasonUpstreamUnavailable is raised by the gateway while the gRPC
+	// server is down or under maintenance
+	ErrorReasonUpstreamUnavailable = "UPSTREAM_UNAVAILABLE"
+	// ErrorReasonDependencyUnavailable is raised when a third party a call
+	// relies on, such as an OAuth provider, fails; the server itself is up
+	ErrorReasonDependencyUnavailable = "DEPENDENCY_UNAVAILABLE"
+	// ErrorReasonMaintenance is raised while the maintenance_mode flag is on
+	ErrorReasonMaintenance = "MAINTENANCE"
+	// ErrorReasonPasswordChangeRequired is raised by the auth interceptor and
+	// the gateway proxy on calls of users with a pending forced password change
+	ErrorReasonPasswordChangeRequired = auth.ErrorReasonPasswordChangeRequired
+)
+
+// errorWithReason returns a status error with an ErrorInfo detail, so clients
+// can tell failures apart without parsing the message.
+func errorWithReason(code codes.Code, reason, msg string) error {
+	st := status.New(code, msg)
+	if detailed, err := st.WithDetails(
+		&errdetails.ErrorInfo{Reason: reason, Domain: ErrorDomain},
+	); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/failpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// AdminListFailpoints returns the failpoints of this server, sorted by name.
+func (s *userService) AdminListFailpoints(
+	ctx context.Context,
+	_ *user_v1_pb.AdminListFailpointsRequest,
+) (*user_v1_pb.AdminListFailpointsResponse, error) {
+	registry := failpoint.Installed()
+	if registry == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "failpoints are disabled")
+	}
+	resp := &user_v1_pb.AdminListFailpointsResponse{}
+	for name, fp := range registry.List() {
+		resp.Failpoints = append(resp.Failpoints, failpointToProto(name, fp))
+	}
+	slices.SortFunc(resp.Failpoints, func(a, b *user_v1_pb.Failpoint) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return resp, nil
+}
+
+// AdminSetFailpoint sets or clears a failpoint of this server. Other servers
+// keep theirs, so tests should go through a single one.
+func (s *userService) AdminSetFailpoint(
+	ctx context.Context,
+	req *user_v1_pb.AdminSetFailpointRequest,
+) (*user_v1_pb.AdminSetFailpointResponse, error) {
+	registry := failpoint.Installed()
+	if registry == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "failpoints are disabled")
+	}
+	if req.Percent > 100 {
+		return nil, status.Errorf(codes.InvalidArgument, "percent must be at most 100")
+	}
+	fp := failpoint.Failpoint{
+		Fail:    req.Fail,
+		Latency: time.Duration(req.LatencyMilliseconds) * time.Millisecond,
+		Percent: int(req.Percent),
+	}
+	if req.DurationSeconds > 0 {
+		fp.ExpiresAt = time.Now().Add(time.Duration(req.DurationSeconds) * time.Second)
+	}
+	if err := registry.Set(req.Name, fp); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	slog.WarnContext(
+		ctx,
+		"failpoint changed",
+		"failpoint",
+		req.Name,
+		"fail",
+		fp.Fail,
+		"latency",
+		fp.Latency,
+		"percent",
+		fp.Percent,
+		"admin_id",
+		callerID(ctx),
+	)
+	resp := &user_v1_pb.AdminSetFailpointResponse{}
+	if fp.Fail || fp.Latency > 0 {
+		resp.Failpoint = failpointToProto(strings.ToLower(req.Name), fp)
+	}
+	return resp, nil
+}
+
+func failpointToProto(name string, fp failpoint.Failpoint) *user_v1_pb.Failpoint {
+	pb := &user_v1_pb.Failpoint{
+		Name:                name,
+		Fail:                fp.Fail,
+		LatencyMilliseconds: uint32(fp.Latency.Milliseconds()),
+		Percent:             uint32(fp.Percent),
+	}
+	if !fp.ExpiresAt.IsZero() {
+		pb.ExpireTime = timestamppb.New(fp.ExpiresAt)
+	}
+	return pb
+}
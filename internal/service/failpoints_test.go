@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/failpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdminSetFailpoint(t *testing.T) {
+	s := &userService{}
+	ctx := context.Background()
+	set := func(req *user_v1_pb.AdminSetFailpointRequest) (*user_v1_pb.Failpoint, error) {
+		t.Helper()
+		resp, err := s.AdminSetFailpoint(ctx, req)
+		return resp.GetFailpoint(), err
+	}
+	req := &user_v1_pb.AdminSetFailpointRequest{Name: "redis:get", Fail: true, DurationSeconds: 60}
+	if _, err := set(req); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition while failpoints are disabled, got %v", err)
+	}
+
+	registry, err := failpoint.NewRegistry(configs.FailpointsConfig{Enabled: true}, "development")
+	if err != nil {
+		t.Fatal(err)
+	}
+	failpoint.Install(registry)
+	t.Cleanup(func() { failpoint.Install(nil) })
+	fp, err := set(req)
+	if err != nil || !fp.Fail || fp.ExpireTime == nil {
+		t.Fatalf("unexpected failpoint %v (%v)", fp, err)
+	}
+	if _, err := set(&user_v1_pb.AdminSetFailpointRequest{
+		Name: "cache",
+		Fail: true,
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected an unknown point to be refused, got %v", err)
+	}
+	list, err := s.AdminListFailpoints(ctx, &user_v1_pb.AdminListFailpointsRequest{})
+	if err != nil || len(list.Failpoints) != 1 || list.Failpoints[0].Name != "redis:get" {
+		t.Errorf("unexpected failpoints %v (%v)", list, err)
+	}
+
+	if fp, err := set(&user_v1_pb.AdminSetFailpointRequest{Name: "redis:get"}); err != nil ||
+		fp != nil {
+		t.Errorf("expected the failpoint to be cleared, got %v (%v)", fp, err)
+	}
+	if got := registry.List(); len(got) != 0 {
+		t.Errorf("expected no failpoints left, got %v", got)
+	}
+}
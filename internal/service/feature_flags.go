@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/featureflags"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AdminListFeatureFlags returns the current value of every known feature flag.
+func (s *userService) AdminListFeatureFlags(
+	ctx context.Context,
+	req *user_v1_pb.AdminListFeatureFlagsRequest,
+) (*user_v1_pb.AdminListFeatureFlagsResponse, error) {
+	flags, err := s.flags.All(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load feature flags", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to load feature flags: %v", err)
+	}
+
+	resp := &user_v1_pb.AdminListFeatureFlagsResponse{}
+	for _, name := range featureflags.Known {
+		resp.Flags = append(resp.Flags, &user_v1_pb.FeatureFlag{Name: name, Enabled: flags[name]})
+	}
+	return resp, nil
+}
+
+// AdminSetFeatureFlag turns a feature flag on or off at runtime.
+func (s *userService) AdminSetFeatureFlag(
+	ctx context.Context,
+	req *user_v1_pb.AdminSetFeatureFlagRequest,
+) (*user_v1_pb.AdminSetFeatureFlagResponse, error) {
+	err := s.flags.Set(ctx, req.Name, req.Enabled)
+	if errors.Is(err, featureflags.ErrUnknownFlag) {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown feature flag %q", req.Name)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to set feature flag", "error", err, "flag", req.Name)
+		return nil, status.Errorf(codes.Internal, "failed to set feature flag: %v", err)
+	}
+
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventFeatureFlagChanged,
+		nil,
+		map[string]string{
+			"admin_id": callerID(ctx),
+			"flag":     req.Name,
+			"enabled":  strconv.FormatBool(req.Enabled),
+		},
+	)
+	slog.InfoContext(
+		ctx,
+		"feature flag changed",
+		"flag",
+		req.Name,
+		"enabled",
+		req.Enabled,
+		"admin_id",
+		callerID(ctx),
+	)
+	return &user_v1_pb.AdminSetFeatureFlagResponse{
+		Flag: &user_v1_pb.FeatureFlag{Name: req.Name, Enabled: req.Enabled},
+	}, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/featureflags"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdminSetFeatureFlag(t *testing.T) {
+	rdb, _ := testutil.NewRedis(t)
+	auditRepo := testutil.NewAuditRepository()
+	s := &userService{
+		auditRepo: auditRepo,
+		flags:     featureflags.NewStore(rdb, configs.FeatureFlagsConfig{}),
+	}
+	ctx := context.Background()
+
+	_, err := s.AdminSetFeatureFlag(ctx, &user_v1_pb.AdminSetFeatureFlagRequest{
+		Name:    featureflags.MaintenanceMode,
+		Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("AdminSetFeatureFlag failed: %v", err)
+	}
+	if n := auditRepo.Count(model.AuditEventFeatureFlagChanged); n != 1 {
+		t.Errorf("Expected 1 audit event, got %d", n)
+	}
+
+	resp, err := s.AdminListFeatureFlags(ctx, &user_v1_pb.AdminListFeatureFlagsRequest{})
+	if err != nil {
+		t.Fatalf("AdminListFeatureFlags failed: %v", err)
+	}
+	if len(resp.Flags) != len(featureflags.Known) {
+		t.Fatalf("Expected %d flags, got %d", len(featureflags.Known), len(resp.Flags))
+	}
+	for _, flag := range resp.Flags {
+		if flag.Enabled != (flag.Name == featureflags.MaintenanceMode) {
+			t.Errorf("Unexpected value %v for flag %s", flag.Enabled, flag.Name)
+		}
+	}
+
+	_, err = s.AdminSetFeatureFlag(ctx, &user_v1_pb.AdminSetFeatureFlagRequest{Name: "unknown"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for unknown flags, got %v", err)
+	}
+}
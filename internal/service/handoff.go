@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/logctx"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// codeChallengeLength is the length of a base64url-encoded SHA-256 hash.
+const codeChallengeLength = 43
+
+// CreateHandoffToken issues a handoff token for the caller's web session.
+func (s *authService) CreateHandoffToken(
+	ctx context.Context,
+	req *auth_v1_pb.CreateHandoffTokenRequest,
+) (*auth_v1_pb.CreateHandoffTokenResponse, error) {
+	userInfo, ok := ctx.Value(auth.ContextKeyUserInfo).(*auth.UserInfo)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "user not authenticated")
+	}
+	if req.CodeChallenge != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(req.CodeChallenge)
+		if err != nil || len(req.CodeChallenge) != codeChallengeLength ||
+			len(decoded) != sha256.Size {
+			return nil, status.Errorf(
+				codes.InvalidArgument,
+				"code_challenge must be a base64url-encoded SHA-256 hash",
+			)
+		}
+	}
+	clientName := strings.TrimSpace(req.ClientName)
+	if len(clientName) > maxClientNameLength {
+		clientName = clientName[:maxClientNameLength]
+	}
+
+	now := time.Now()
+	expiration := s.config.Auth.HandoffExpiration
+	token, err := s.handoffs.Create(ctx, &repository.HandoffToken{
+		UserID:        userInfo.UserID,
+		SessionRef:    userInfo.SessionRef,
+		ClientName:    clientName,
+		CodeChallenge: req.CodeChallenge,
+		CreatedAt:     now,
+	}, expiration)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to store handoff token", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to store handoff token: %v", err)
+	}
+
+	slog.InfoContext(ctx, "handoff token created",
+		"client_name", clientName,
+		"code_challenge", req.CodeChallenge != "")
+	return &auth_v1_pb.CreateHandoffTokenResponse{
+		Token:     token,
+		ExpiresAt: timestamppb.New(now.Add(expiration)),
+	}, nil
+}
+
+// RedeemHandoffToken gives the native app a session of its own, so it stays
+// logged in independently of the web session and can be revoked on its own.
+func (s *authService) RedeemHandoffToken(
+	ctx context.Context,
+	req *auth_v1_pb.RedeemHandoffTokenRequest,
+) (*auth_v1_pb.RedeemHandoffTokenResponse, error) {
+	if req.Token == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "token is required")
+	}
+	handoff, err := s.handoffs.Consume(ctx, req.Token)
+	if errors.Is(err, repository.ErrHandoffTokenNotFound) {
+		return nil, status.Errorf(codes.NotFound, "invalid or expired handoff token")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to redeem handoff token: %v", err)
+	}
+	ctx = logctx.WithUserID(ctx, handoff.UserID)
+
+	if handoff.CodeChallenge != "" {
+		sum := sha256.Sum256([]byte(req.CodeVerifier))
+		challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+		if subtle.ConstantTimeCompare([]byte(challenge), []byte(handoff.CodeChallenge)) != 1 {
+			slog.WarnContext(ctx, "handoff token redeemed with wrong code verifier",
+				"client_name", handoff.ClientName,
+				"ip_address", extractIPAddress(ctx))
+			return nil, status.Errorf(codes.PermissionDenied, "code verifier does not match")
+		}
+	}
+	if handoff.SessionRef != "" {
+		active, err := s.sessionRepo.Active(ctx, handoff.SessionRef)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to check session: %v", err)
+		}
+		if !active {
+			return nil, status.Errorf(
+				codes.FailedPrecondition,
+				"the session the handoff token was created with has ended",
+			)
+		}
+	}
+
+	user, err := s.userRepo.GetByID(ctx, handoff.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "user not found")
+	}
+	if err := s.checkMaintenance(ctx, user); err != nil {
+		return nil, err
+	}
+	sessionID, err := s.createSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]string{"method": "handoff"}
+	if handoff.ClientName != "" {
+		metadata["client_name"] = handoff.ClientName
+	}
+	recordAuditEvent(ctx, s.auditRepo, model.AuditEventLoginSucceeded, &user.ID, metadata)
+	slog.InfoContext(ctx, "handoff token redeemed",
+		"session_id", sessionID[:16],
+		"client_name", handoff.ClientName)
+
+	expiresAt, err := s.getSessionExpirationTime(ctx, sessionID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get session expiration: %v", err)
+	}
+	return &auth_v1_pb.RedeemHandoffTokenResponse{
+		Session: &auth_v1_pb.LoginSession{
+			Id:        sessionID,
+			ExpiresAt: timestamppb.New(expiresAt),
+		},
+	}, nil
+}
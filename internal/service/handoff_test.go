@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHandoffToken(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	s.config.Auth.HandoffExpiration = time.Minute
+	ctx := context.Background()
+	user := &model.UserModel{ID: "user-1", Email: "user@example.com", Role: model.UserRoleUser}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	webSession, err := s.sessionRepo.Create(ctx, user.ID, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	webCtx := context.WithValue(ctx, auth.ContextKeyUserInfo, &auth.UserInfo{
+		UserID:     user.ID,
+		SessionRef: repository.SessionRef(webSession),
+	})
+
+	verifier := "a-code-verifier-known-only-to-the-native-app"
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+	create := func() string {
+		t.Helper()
+		resp, err := s.CreateHandoffToken(webCtx, &auth_v1_pb.CreateHandoffTokenRequest{
+			ClientName:    "Desktop app",
+			CodeChallenge: challenge,
+		})
+		if err != nil {
+			t.Fatalf("CreateHandoffToken failed: %v", err)
+		}
+		return resp.Token
+	}
+	redeem := func(token, verifier string) (*auth_v1_pb.RedeemHandoffTokenResponse, error) {
+		return s.RedeemHandoffToken(ctx, &auth_v1_pb.RedeemHandoffTokenRequest{
+			Token:        token,
+			CodeVerifier: verifier,
+		})
+	}
+
+	if _, err := s.CreateHandoffToken(webCtx, &auth_v1_pb.CreateHandoffTokenRequest{
+		CodeChallenge: "not-a-challenge",
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected an invalid code challenge to be rejected, got %v", err)
+	}
+
+	token := create()
+	resp, err := redeem(token, verifier)
+	if err != nil {
+		t.Fatalf("RedeemHandoffToken failed: %v", err)
+	}
+	if resp.Session.Id == webSession {
+		t.Error("expected the native app to get a session of its own")
+	}
+	if userID, err := s.sessionRepo.GetUserID(ctx, resp.Session.Id); err != nil ||
+		userID != user.ID {
+		t.Errorf("expected a session of the user, got %q, %v", userID, err)
+	}
+	if _, err := redeem(token, verifier); status.Code(err) != codes.NotFound {
+		t.Errorf("expected the token to work once, got %v", err)
+	}
+
+	token = create()
+	if _, err := redeem(token, "another-verifier"); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected a wrong code verifier to be rejected, got %v", err)
+	}
+	if _, err := redeem(token, verifier); status.Code(err) != codes.NotFound {
+		t.Errorf("expected the token to be void after a wrong verifier, got %v", err)
+	}
+
+	token = create()
+	if err := s.sessionRepo.Delete(ctx, webSession); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := redeem(token, verifier); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected tokens of ended sessions to be rejected, got %v", err)
+	}
+}
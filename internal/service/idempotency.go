@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// idempotencyKeyMetadataKey is the Idempotency-Key header forwarded by
+	// the gateway.
+	idempotencyKeyMetadataKey = "idempotency-key"
+	maxIdempotencyKeyLength   = 255
+	// idempotencyWait is how long a request waits for the response of the
+	// request sent first with its key, e.g. on a double click, before giving up.
+	idempotencyWait     = 5 * time.Second
+	idempotencyInterval = 50 * time.Millisecond
+)
+
+// idempotent calls call, unless req was sent before with the same
+// Idempotency-Key, in which case the response of the first call is answered
+// again: a login retried on a flaky network then gets its session instead of
+// an error about the state it already used. Failed calls are not remembered.
+func idempotent[Resp proto.Message](
+	ctx context.Context,
+	repo repository.IdempotencyRepository,
+	ttl time.Duration,
+	scope string,
+	req proto.Message,
+	call func(context.Context) (Resp, error),
+) (Resp, error) {
+	var zero Resp
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(idempotencyKeyMetadataKey)
+	if repo == nil || ttl <= 0 || len(values) == 0 || values[0] == "" {
+		return call(ctx)
+	}
+	key := values[0]
+	if len(key) > maxIdempotencyKeyLength {
+		return zero, status.Errorf(
+			codes.InvalidArgument,
+			"idempotency key must be at most %d characters",
+			maxIdempotencyKeyLength,
+		)
+	}
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return zero, status.Errorf(codes.Internal, "failed to fingerprint request: %v", err)
+	}
+	sum := sha256.Sum256(data)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	deadline := time.Now().Add(idempotencyWait)
+	for {
+		first, err := repo.Begin(ctx, scope, key, fingerprint, ttl)
+		if ctx.Err() != nil {
+			return zero, status.FromContextError(ctx.Err()).Err()
+		}
+		if errors.Is(err, repository.ErrIdempotencyKeyContended) {
+			return zero, status.Error(codes.Aborted, "idempotency key is in use, retry later")
+		}
+		if err != nil {
+			return zero, status.Errorf(codes.Internal, "failed to check idempotency key: %v", err)
+		}
+		if first == nil {
+			break
+		}
+		if first.Fingerprint != fingerprint {
+			return zero, status.Error(
+				codes.InvalidArgument,
+				"idempotency key was already used for another request",
+			)
+		}
+		if first.Done {
+			resp := zero.ProtoReflect().New().Interface().(Resp)
+			if err := proto.Unmarshal(first.Response, resp); err != nil {
+				return zero, status.Errorf(codes.Internal, "failed to read response: %v", err)
+			}
+			return resp, nil
+		}
+		if time.Now().After(deadline) {
+			return zero, status.Error(
+				codes.Aborted,
+				"a request with this idempotency key is in progress, retry",
+			)
+		}
+		select {
+		case <-ctx.Done():
+			return zero, status.FromContextError(ctx.Err()).Err()
+		case <-time.After(idempotencyInterval):
+		}
+	}
+
+	// The outcome is recorded even if the client went away, it may well
+	// send the request again
+	recordCtx := context.WithoutCancel(ctx)
+	resp, err := call(ctx)
+	if err != nil {
+		if abandonErr := repo.Abandon(recordCtx, scope, key); abandonErr != nil {
+			slog.WarnContext(ctx, "failed to free idempotency key", "error", abandonErr)
+		}
+		return resp, err
+	}
+	data, err = proto.Marshal(resp)
+	if err == nil {
+		err = repo.Complete(recordCtx, scope, key, &repository.IdempotentRequest{
+			Fingerprint: fingerprint,
+			Done:        true,
+			Response:    data,
+		}, ttl)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to store idempotent response", "error", err)
+		_ = repo.Abandon(recordCtx, scope, key)
+	}
+	return resp, nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	providerPkg "github.com/poly-workshop/auth-portal/internal/provider"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoginByOAuthIdempotencyKey(t *testing.T) {
+	s, mr := newTestAuthService(t)
+	s.config.Auth.IdempotencyKeyTTL = time.Minute
+	oauthProvider := testutil.NewOAuthProvider(t)
+	s.oauthConfigs = map[string]*oauth2.Config{
+		"github": oauthProvider.Config("https://portal.example.com/callback"),
+	}
+	s.userProviders = map[string]providerPkg.UserProvider{"github": oauthProvider}
+	withKey := func(key string) context.Context {
+		return metadata.NewIncomingContext(
+			context.Background(),
+			metadata.Pairs(idempotencyKeyMetadataKey, key),
+		)
+	}
+	ctx := withKey("double-click")
+	state, err := s.generateState(ctx, "github", "", "", "")
+	if err != nil {
+		t.Fatalf("generateState failed: %v", err)
+	}
+	req := &auth_v1_pb.LoginByOAuthRequest{
+		Code:  oauthProvider.IssueCode(providerPkg.UserInfo{ID: "42", Email: "octo@example.com"}),
+		State: state,
+	}
+
+	resp, err := s.LoginByOAuth(ctx, req)
+	if err != nil {
+		t.Fatalf("LoginByOAuth failed: %v", err)
+	}
+	again, err := s.LoginByOAuth(ctx, req)
+	if err != nil || again.Session.Id != resp.Session.Id {
+		t.Fatalf("expected the first response again, got %v", err)
+	}
+	value, err := mr.Get("idempotency:login_oauth:" + utils.HashToken("double-click"))
+	if err != nil {
+		t.Fatalf("expected the response to be stored: %v", err)
+	}
+	var stored repository.IdempotentRequest
+	if err := json.Unmarshal([]byte(value), &stored); err != nil {
+		t.Fatalf("failed to read the stored request: %v", err)
+	}
+	if bytes.Contains(stored.Response, []byte(resp.Session.Id)) {
+		t.Error("expected the session not to be stored in plaintext")
+	}
+	if n, _ := s.userRepo.Count(ctx, repository.UserFilter{}); n != 1 {
+		t.Errorf("expected 1 user, got %d", n)
+	}
+
+	other := &auth_v1_pb.LoginByOAuthRequest{Code: "other", State: state}
+	if _, err := s.LoginByOAuth(ctx, other); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected the key to be refused for another request, got %v", err)
+	}
+	if _, err := s.LoginByOAuth(withKey("another-key"), req); err == nil {
+		t.Error("expected the used state to be refused without the key")
+	}
+
+	// Failures are not remembered, the request is tried again
+	failing := withKey("failing")
+	if _, err := s.LoginByOAuth(failing, other); err == nil {
+		t.Fatal("expected an unknown state to be refused")
+	}
+	first, err := s.idempotency.Begin(failing, "login_oauth", "failing", "", time.Minute)
+	if err != nil || first != nil {
+		t.Errorf("expected the key of a failed request to be free, got %+v (%v)", first, err)
+	}
+}
+
+func TestIdempotentInProgress(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	ctx := metadata.NewIncomingContext(
+		context.Background(),
+		metadata.Pairs(idempotencyKeyMetadataKey, "slow"),
+	)
+	req := &auth_v1_pb.LoginByOAuthRequest{Code: "code", State: "state"}
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_, _ = idempotent(ctx, s.idempotency, time.Minute, "test", req,
+			func(context.Context) (*auth_v1_pb.LoginByOAuthResponse, error) {
+				close(started)
+				<-release
+				return &auth_v1_pb.LoginByOAuthResponse{
+					Session: &auth_v1_pb.LoginSession{Id: "session"},
+				}, nil
+			})
+	}()
+	<-started
+
+	waiting, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err := idempotent(waiting, s.idempotency, time.Minute, "test", req,
+		func(context.Context) (*auth_v1_pb.LoginByOAuthResponse, error) {
+			t.Fatal("expected the request in progress not to be sent again")
+			return nil, nil
+		})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("expected to wait for the request in progress, got %v", err)
+	}
+
+	close(release)
+	resp, err := idempotent(ctx, s.idempotency, time.Minute, "test", req,
+		func(context.Context) (*auth_v1_pb.LoginByOAuthResponse, error) {
+			t.Fatal("expected the response of the first request")
+			return nil, nil
+		})
+	if err != nil || resp.GetSession().GetId() != "session" {
+		t.Errorf("expected the response of the first request, got %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ListMyIdentities lists the login providers linked to the caller's account
+// for the "Connected accounts" settings. Provider IDs are masked, since the
+// page only needs to tell accounts apart.
+func (s *userService) ListMyIdentities(
+	ctx context.Context,
+	req *user_v1_pb.ListMyIdentitiesRequest,
+) (*user_v1_pb.ListMyIdentitiesResponse, error) {
+	user, err := s.getCurrentUserModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &user_v1_pb.ListMyIdentitiesResponse{}
+	if user.GithubID != nil {
+		identity := &user_v1_pb.Identity{
+			Provider:         "github",
+			MaskedProviderId: utils.MaskID(*user.GithubID),
+		}
+		if user.GithubLinkedAt != nil {
+			identity.LinkedAt = timestamppb.New(*user.GithubLinkedAt)
+		}
+		resp.Identities = append(resp.Identities, identity)
+	}
+	return resp, nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+)
+
+func TestListMyIdentities(t *testing.T) {
+	githubID := "12345678"
+	linkedAt := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	s := &userService{userRepo: testutil.NewUserRepository(
+		&model.UserModel{
+			ID:             "linked",
+			Email:          "linked@example.com",
+			GithubID:       &githubID,
+			GithubLinkedAt: &linkedAt,
+		},
+		&model.UserModel{ID: "password-only", Email: "password@example.com"},
+	)}
+	list := func(userID string) []*user_v1_pb.Identity {
+		t.Helper()
+		ctx := context.WithValue(
+			context.Background(),
+			auth.ContextKeyUserInfo,
+			&auth.UserInfo{UserID: userID},
+		)
+		resp, err := s.ListMyIdentities(ctx, &user_v1_pb.ListMyIdentitiesRequest{})
+		if err != nil {
+			t.Fatalf("ListMyIdentities failed: %v", err)
+		}
+		return resp.Identities
+	}
+
+	identities := list("linked")
+	if len(identities) != 1 {
+		t.Fatalf("expected the GitHub identity, got %v", identities)
+	}
+	github := identities[0]
+	if github.Provider != "github" || github.MaskedProviderId != "****5678" ||
+		!github.LinkedAt.AsTime().Equal(linkedAt) {
+		t.Errorf("unexpected identity %v", github)
+	}
+	if identities := list("password-only"); len(identities) != 0 {
+		t.Errorf("expected no identities without linked providers, got %v", identities)
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListInactiveUsers pages through the accounts without activity in the last
+// inactive_days, as recorded in last_seen_at. Accounts that were never seen
+// count from their creation.
+func (s *userService) ListInactiveUsers(
+	ctx context.Context,
+	req *user_v1_pb.ListInactiveUsersRequest,
+) (*user_v1_pb.ListInactiveUsersResponse, error) {
+	if req.InactiveDays < 1 {
+		return nil, status.Error(codes.InvalidArgument, "inactive_days must be at least 1")
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 {
+		req.PageSize = 10
+	}
+	since := time.Now().Add(-time.Duration(req.InactiveDays) * 24 * time.Hour)
+	filter := repository.UserFilter{InactiveSince: &since}
+
+	result := &user_v1_pb.ListInactiveUsersResponse{}
+	count, err := s.userRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count users: %v", err)
+	}
+	if count == 0 {
+		return result, nil
+	}
+	result.Total = uint64(count)
+	offset := int((req.Page - 1) * req.PageSize)
+	users, err := s.userRepo.List(ctx, filter, offset, int(req.PageSize))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list users: %v", err)
+	}
+	result.Users = make([]*user_v1_pb.User, len(users))
+	for i, user := range users {
+		result.Users[i] = user.ToPb()
+	}
+	return result, nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListInactiveUsers(t *testing.T) {
+	now := time.Now()
+	longAgo := now.Add(-100 * 24 * time.Hour)
+	recently := now.Add(-time.Hour)
+	s := &userService{userRepo: testutil.NewUserRepository(
+		&model.UserModel{ID: "dormant", Email: "a@example.com", LastSeenAt: &longAgo},
+		&model.UserModel{ID: "active", Email: "b@example.com", LastSeenAt: &recently},
+		&model.UserModel{ID: "never-seen", Email: "c@example.com", CreatedAt: longAgo},
+		&model.UserModel{ID: "new", Email: "d@example.com"},
+	)}
+
+	resp, err := s.ListInactiveUsers(
+		context.Background(),
+		&user_v1_pb.ListInactiveUsersRequest{InactiveDays: 90},
+	)
+	if err != nil {
+		t.Fatalf("ListInactiveUsers failed: %v", err)
+	}
+	ids := map[string]bool{}
+	for _, user := range resp.Users {
+		ids[user.Id] = true
+	}
+	if resp.Total != 2 || !ids["dormant"] || !ids["never-seen"] {
+		t.Errorf("expected the dormant and the never seen user, got %v", resp.Users)
+	}
+
+	_, err = s.ListInactiveUsers(context.Background(), &user_v1_pb.ListInactiveUsersRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected inactive_days 0 to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"time"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// AdminInviteUser lets an email address sign up while signups are invite-only
+// and notifies the address. Inviting an address again renews its invite.
+func (s *userService) AdminInviteUser(
+	ctx context.Context,
+	req *user_v1_pb.AdminInviteUserRequest,
+) (*user_v1_pb.AdminInviteUserResponse, error) {
+	email := strings.TrimSpace(req.Email)
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid email address")
+	}
+	if err := s.ensureEmailAvailable(ctx, email); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	invite := &repository.Invite{Email: email, InvitedBy: callerID(ctx), CreatedAt: now}
+	if err := s.inviteRepo.Create(ctx, invite, s.config.Account.InviteExpiration); err != nil {
+		slog.ErrorContext(ctx, "failed to store invite", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to store invite: %v", err)
+	}
+
+	baseURL := strings.TrimSuffix(s.config.Mailer.LinkBaseURL, "/")
+	if err := s.mailer.Send(
+		ctx,
+		email,
+		"You have been invited",
+		fmt.Sprintf("You have been invited to create an account: %s/login\n", baseURL),
+	); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to send invite email: %v", err)
+	}
+
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventUserInvited,
+		nil,
+		map[string]string{"admin_id": callerID(ctx), "email": email},
+	)
+	slog.InfoContext(ctx, "user invited", "admin_id", callerID(ctx))
+
+	return &user_v1_pb.AdminInviteUserResponse{
+		ExpiresAt: timestamppb.New(now.Add(s.config.Account.InviteExpiration)),
+	}, nil
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetKeyUsage returns the calls of an internal credential in the current day
+// and month along with its quota.
+func (s *userService) GetKeyUsage(
+	ctx context.Context,
+	req *user_v1_pb.GetKeyUsageRequest,
+) (*user_v1_pb.GetKeyUsageResponse, error) {
+	if !s.config.Usage.Enabled || s.keyUsage == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "key usage is not tracked")
+	}
+	if req.Key == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "key is required")
+	}
+	usage, err := s.keyUsage.Usage(ctx, req.Key)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get key usage", "error", err, "key", req.Key)
+		return nil, status.Errorf(codes.Internal, "failed to get key usage: %v", err)
+	}
+	return &user_v1_pb.GetKeyUsageResponse{
+		Key:          req.Key,
+		Day:          usage.Day,
+		DailyCalls:   usage.Daily,
+		Month:        usage.Month,
+		MonthlyCalls: usage.Monthly,
+		DailyQuota:   usage.Quota.Daily,
+		MonthlyQuota: usage.Quota.Monthly,
+	}, nil
+}
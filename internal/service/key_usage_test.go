@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"github.com/poly-workshop/auth-portal/internal/usage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetKeyUsage(t *testing.T) {
+	rdb, _ := testutil.NewRedis(t)
+	ctx := context.Background()
+	cfg := configs.UsageConfig{
+		Enabled: true,
+		Quotas:  map[string]configs.Quota{"internal": {Daily: 100}},
+	}
+	meter := usage.NewMeter(rdb, cfg)
+	s := &userService{keyUsage: meter}
+
+	req := &user_v1_pb.GetKeyUsageRequest{Key: "internal"}
+	if _, err := s.GetKeyUsage(ctx, req); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition while usage isn't tracked, got %v", err)
+	}
+
+	s.config.Usage = cfg
+	if err := meter.Record(ctx, "internal"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := s.GetKeyUsage(ctx, req)
+	if err != nil {
+		t.Fatalf("GetKeyUsage failed: %v", err)
+	}
+	if resp.DailyCalls != 1 || resp.MonthlyCalls != 1 || resp.DailyQuota != 100 {
+		t.Errorf("unexpected usage %v", resp)
+	}
+}
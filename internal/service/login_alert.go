@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/notify"
+	"github.com/poly-workshop/auth-portal/internal/risk"
+)
+
+// alertNewLogin emails the user a login alert for logins from a device or
+// network they did not log in from before. New devices and networks are only
+// detected while risk scoring is enabled. Failing to alert never fails the login.
+func (s *authService) alertNewLogin(
+	ctx context.Context,
+	user *model.UserModel,
+	attempt risk.Attempt,
+	assessment risk.Assessment,
+) {
+	if s.notifier == nil ||
+		!slices.Contains(assessment.Signals, risk.SignalNewDevice) &&
+			!slices.Contains(assessment.Signals, risk.SignalNewNetwork) {
+		return
+	}
+	baseURL := strings.TrimSuffix(s.config.Mailer.LinkBaseURL, "/")
+	sent, err := s.notifier.Notify(ctx, user, notify.Notification{
+		Category: model.NotificationCategoryLoginAlert,
+		Subject:  "New login to your account",
+		Body: fmt.Sprintf(
+			"Your account was logged in to at %s from a new device or network.\n\n"+
+				"IP address: %s\nDevice: %s\n\n"+
+				"If this was not you, change your password: %s/login\n",
+			time.Now().UTC().Format(time.RFC1123),
+			attempt.IPAddress,
+			attempt.UserAgent,
+			baseURL,
+		),
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "failed to send login alert", "error", err)
+		return
+	}
+	if sent {
+		slog.InfoContext(ctx, "login alert sent", "risk_signals", assessment.Signals)
+	}
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/mail"
+	"strings"
+	"time"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/logctx"
+	"github.com/poly-workshop/auth-portal/internal/logincode"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/risk"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+// loginCodeSpace is the number of 6-digit login codes.
+var loginCodeSpace = big.NewInt(1_000_000)
+
+func generateLoginCode() (string, error) {
+	n, err := rand.Int(rand.Reader, loginCodeSpace)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func (s *authService) loginCodesEnabled() error {
+	if len(s.codeChannels) == 0 {
+		return status.Error(codes.FailedPrecondition, "login codes are not enabled")
+	}
+	return nil
+}
+
+// SendLoginCode sends a login code to the account using the email address, by
+// the requested channel. Like RequestMagicLink, it answers the same for unknown
+// addresses and for accounts the channel can't reach.
+func (s *authService) SendLoginCode(
+	ctx context.Context,
+	req *auth_v1_pb.SendLoginCodeRequest,
+) (*auth_v1_pb.SendLoginCodeResponse, error) {
+	if err := s.loginCodesEnabled(); err != nil {
+		return nil, err
+	}
+	email := strings.TrimSpace(req.Email)
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return nil, status.Error(codes.InvalidArgument, "invalid email address")
+	}
+	channelName := req.Channel
+	if channelName == "" {
+		channelName = s.config.LoginCode.Channels[0]
+	}
+	channel, ok := s.codeChannels[channelName]
+	if !ok {
+		return nil, status.Errorf(
+			codes.InvalidArgument,
+			"login code channel %q is not enabled",
+			channelName,
+		)
+	}
+	allowed, err := s.loginCodeLimit.Allow(ctx, strings.ToLower(email))
+	if err != nil {
+		slog.WarnContext(ctx, "failed to check login code rate limit", "error", err)
+	} else if !allowed {
+		return nil, status.Error(
+			codes.ResourceExhausted,
+			"too many login codes sent, try again later",
+		)
+	}
+
+	expiration := s.config.LoginCode.Expiration
+	resp := &auth_v1_pb.SendLoginCodeResponse{
+		ExpiresAt: timestamppb.New(time.Now().Add(expiration)),
+		Channel:   channelName,
+	}
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		slog.InfoContext(ctx, "login code requested for unknown email")
+		return resp, nil
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to query user: %v", err)
+	}
+	ctx = logctx.WithUserID(ctx, user.ID)
+
+	code, err := generateLoginCode()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate login code: %v", err)
+	}
+	if err := s.loginCodes.Create(ctx, user.ID, code, expiration); err != nil {
+		slog.ErrorContext(ctx, "failed to store login code", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to store login code: %v", err)
+	}
+	err = channel.Send(ctx, user, code, expiration)
+	if errors.Is(err, logincode.ErrNoAddress) {
+		slog.InfoContext(ctx, "login code not sent, user unreachable by channel",
+			"channel", channelName)
+		return resp, nil
+	}
+	if err != nil {
+		// Answered like unknown addresses, which would otherwise stand out
+		slog.ErrorContext(ctx, "failed to send login code",
+			"error", err,
+			"channel", channelName)
+		return resp, nil
+	}
+
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventLoginCodeSent,
+		&user.ID,
+		map[string]string{"channel": channelName},
+	)
+	slog.InfoContext(ctx, "login code sent", "channel", channelName)
+	return resp, nil
+}
+
+// VerifyLoginCode logs the user in with the code last sent to them. Wrong codes
+// count as failed logins for the login throttle, and void the code once
+// login_code.max_attempts were made at it.
+func (s *authService) VerifyLoginCode(
+	ctx context.Context,
+	req *auth_v1_pb.VerifyLoginCodeRequest,
+) (*auth_v1_pb.VerifyLoginCodeResponse, error) {
+	if err := s.loginCodesEnabled(); err != nil {
+		return nil, err
+	}
+	ipAddress := extractIPAddress(ctx)
+	email := strings.TrimSpace(req.Email)
+	code := strings.TrimSpace(req.Code)
+	if email == "" || code == "" {
+		return nil, status.Error(codes.InvalidArgument, "email and code are required")
+	}
+	if err := s.checkLoginThrottle(ctx, email, ipAddress); err != nil {
+		return nil, err
+	}
+	invalid := status.Error(codes.Unauthenticated, "invalid or expired login code")
+	fail := func(userID *string, reason string) {
+		s.warnLoginFailure(
+			ctx,
+			ipAddress,
+			"login code verification failed",
+			"error",
+			reason,
+			"ip_address",
+			ipAddress,
+		)
+		recordAuditEvent(
+			ctx,
+			s.auditRepo,
+			model.AuditEventLoginFailed,
+			userID,
+			map[string]string{"method": "login_code", "reason": reason},
+		)
+		s.recordLoginFailure(ctx, email, ipAddress)
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		fail(nil, "user_not_found")
+		return nil, invalid
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to query user: %v", err)
+	}
+	ctx = logctx.WithUserID(ctx, user.ID)
+
+	match, attempts, err := s.loginCodes.Attempt(ctx, user.ID, code)
+	if errors.Is(err, repository.ErrLoginCodeNotFound) {
+		fail(&user.ID, "no_code")
+		return nil, invalid
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to check login code: %v", err)
+	}
+	if !match || attempts > s.config.LoginCode.MaxAttempts {
+		fail(&user.ID, "invalid_code")
+		if attempts >= s.config.LoginCode.MaxAttempts {
+			if err := s.loginCodes.Delete(ctx, user.ID); err != nil &&
+				!errors.Is(err, repository.ErrLoginCodeNotFound) {
+				slog.WarnContext(ctx, "failed to void login code", "error", err)
+			}
+			return nil, status.Error(
+				codes.ResourceExhausted,
+				"too many wrong login codes, request a new code",
+			)
+		}
+		return nil, invalid
+	}
+	// Only the attempt deleting the code gets the session
+	err = s.loginCodes.Delete(ctx, user.ID)
+	if errors.Is(err, repository.ErrLoginCodeNotFound) {
+		return nil, invalid
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to redeem login code: %v", err)
+	}
+
+	if err := s.checkPendingApproval(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := s.checkMaintenance(ctx, user); err != nil {
+		return nil, err
+	}
+	attempt := risk.Attempt{
+		UserID:    user.ID,
+		Email:     user.Email,
+		IPAddress: ipAddress,
+		UserAgent: extractUserAgent(ctx),
+	}
+	assessment, err := s.assessLoginRisk(ctx, attempt, "login_code")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.throttle.RecordSuccess(ctx, email); err != nil {
+		slog.WarnContext(ctx, "failed to reset login throttle", "error", err)
+	}
+
+	now := time.Now()
+	user.LastLoginAt = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		slog.ErrorContext(ctx, "failed to update user last login", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
+	}
+	if err := s.reactivateDormant(ctx, user); err != nil {
+		return nil, err
+	}
+	sessionID, err := s.createSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.rememberLogin(ctx, attempt)
+	s.alertNewLogin(ctx, user, attempt, assessment)
+	metadata := assessment.Metadata()
+	metadata["method"] = "login_code"
+	recordAuditEvent(ctx, s.auditRepo, model.AuditEventLoginSucceeded, &user.ID, metadata)
+	slog.InfoContext(ctx, "login code login completed successfully",
+		"session_id", sessionID[:16],
+		"ip_address", ipAddress)
+
+	expiresAt, err := s.getSessionExpirationTime(ctx, sessionID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get session expiration: %v", err)
+	}
+	return &auth_v1_pb.VerifyLoginCodeResponse{
+		Session: &auth_v1_pb.LoginSession{
+			Id:        sessionID,
+			ExpiresAt: timestamppb.New(expiresAt),
+		},
+	}, nil
+}
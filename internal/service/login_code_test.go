@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/logincode"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/throttle"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var loginCodePattern = regexp.MustCompile(`\b(\d{6})\b`)
+
+// wrongCode returns another code than code.
+func wrongCode(code string) string {
+	if code[0] == '9' {
+		return "0" + code[1:]
+	}
+	return string(code[0]+1) + code[1:]
+}
+
+func TestLoginCode(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	ctx := context.Background()
+	if _, err := s.SendLoginCode(ctx, &auth_v1_pb.SendLoginCodeRequest{
+		Email: "user@example.com",
+	}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected login codes to be disabled by default, got %v", err)
+	}
+
+	mail := &recordingMailer{}
+	s.config.LoginCode = configs.LoginCodeConfig{
+		Channels:    []string{"email"},
+		Expiration:  10 * time.Minute,
+		MaxAttempts: 3,
+	}
+	channels, err := logincode.NewChannels(s.config.LoginCode, mail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.codeChannels = channels
+	s.loginCodeLimit = throttle.NewRateLimiter(s.rdb, "login_code", 5, time.Hour)
+	user := &model.UserModel{ID: "user-1", Email: "user@example.com", Role: model.UserRoleUser}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(email, channel string) error {
+		_, err := s.SendLoginCode(ctx, &auth_v1_pb.SendLoginCodeRequest{
+			Email:   email,
+			Channel: channel,
+		})
+		return err
+	}
+	verify := func(code string) (*auth_v1_pb.VerifyLoginCodeResponse, error) {
+		return s.VerifyLoginCode(ctx, &auth_v1_pb.VerifyLoginCodeRequest{
+			Email: user.Email,
+			Code:  code,
+		})
+	}
+	lastCode := func() string {
+		t.Helper()
+		bodies := mail.sent[user.Email]
+		match := loginCodePattern.FindStringSubmatch(bodies[len(bodies)-1])
+		if match == nil {
+			t.Fatalf("expected a login code, got %q", bodies[len(bodies)-1])
+		}
+		return match[1]
+	}
+
+	if err := send(user.Email, "sms"); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected disabled channels to be rejected, got %v", err)
+	}
+	if err := send("nobody@example.com", ""); err != nil {
+		t.Fatalf("expected unknown addresses to get the same answer, got %v", err)
+	}
+	if len(mail.sent) != 0 {
+		t.Errorf("expected no email to unknown addresses, got %v", mail.sent)
+	}
+	if err := send(user.Email, ""); err != nil {
+		t.Fatalf("SendLoginCode failed: %v", err)
+	}
+	code := lastCode()
+
+	if _, err := verify(wrongCode(code)); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected a wrong code to be rejected, got %v", err)
+	}
+	resp, err := verify(code)
+	if err != nil {
+		t.Fatalf("VerifyLoginCode failed: %v", err)
+	}
+	if userID, err := s.sessionRepo.GetUserID(ctx, resp.Session.Id); err != nil ||
+		userID != user.ID {
+		t.Errorf("expected a session of the user, got %q, %v", userID, err)
+	}
+	if _, err := verify(code); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected the code to work once, got %v", err)
+	}
+
+	// Too many wrong codes void the code
+	if err := send(user.Email, "email"); err != nil {
+		t.Fatalf("SendLoginCode failed: %v", err)
+	}
+	code = lastCode()
+	for range 2 {
+		if _, err := verify(wrongCode(code)); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("expected a wrong code to be rejected, got %v", err)
+		}
+	}
+	if _, err := verify(wrongCode(code)); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected the code to be void after 3 attempts, got %v", err)
+	}
+	if _, err := verify(code); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected the void code to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/poly-workshop/auth-portal/configs"
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/clientip"
+	"github.com/poly-workshop/auth-portal/internal/throttle"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// TestLoginThroughGateway drives logins through the gateway, whose peer
+// address all calls share, and checks that clients are throttled by the
+// addresses it forwards.
+func TestLoginThroughGateway(t *testing.T) {
+	s, mr := newTestAuthService(t)
+	s.throttle = throttle.NewLoginThrottle(s.rdb, configs.ThrottleConfig{
+		Enabled:        true,
+		FreeAttempts:   100,
+		IPFreeAttempts: 1,
+		BaseDelay:      time.Minute,
+		MaxDelay:       time.Hour,
+		Window:         time.Hour,
+	})
+
+	resolver, err := clientip.NewResolver(configs.DefaultTrustedProxies)
+	if err != nil {
+		t.Fatalf("NewResolver failed: %v", err)
+	}
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(clientip.UnaryServerInterceptor(resolver)))
+	auth_v1_pb.RegisterAuthServiceServer(grpcServer, s)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go func() { _ = grpcServer.Serve(lis) }()
+	t.Cleanup(grpcServer.Stop)
+
+	mux := runtime.NewServeMux()
+	err = auth_v1_pb.RegisterAuthServiceHandlerFromEndpoint(
+		context.Background(),
+		mux,
+		lis.Addr().String(),
+		[]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+	)
+	if err != nil {
+		t.Fatalf("failed to register gateway handler: %v", err)
+	}
+	login := func(remoteAddr, forwardedFor string) int {
+		req := httptest.NewRequest(
+			http.MethodPost,
+			"/v1/login/password",
+			strings.NewReader(`{"email":"nobody@example.com","password":"wrong-password"}`),
+		)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for range 2 {
+		login("203.0.113.7:40000", "")
+	}
+	if code := login("203.0.113.7:40000", ""); code != http.StatusTooManyRequests {
+		t.Errorf("expected the failing network to be throttled, got %d", code)
+	}
+	if code := login("198.51.100.9:40000", ""); code == http.StatusTooManyRequests {
+		t.Error("expected other networks not to share the bucket of the gateway")
+	}
+	// Hops clients add themselves are not taken for their address
+	if code := login("198.51.100.9:40000", "203.0.113.7"); code == http.StatusTooManyRequests {
+		t.Error("expected a forged X-Forwarded-For not to be trusted")
+	}
+	if mr.Exists("login_failures:ip:127.0.0.0") {
+		t.Error("expected no bucket for the network of the gateway")
+	}
+}
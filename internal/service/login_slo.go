@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/slo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GetLoginSLO summarizes the login SLIs of the last 24 hours against their
+// objectives.
+func (s *userService) GetLoginSLO(
+	ctx context.Context,
+	_ *user_v1_pb.GetLoginSLORequest,
+) (*user_v1_pb.GetLoginSLOResponse, error) {
+	if s.slo == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "login SLIs are not recorded")
+	}
+	summary, err := s.slo.Summary(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to summarize login SLIs", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to summarize login SLIs: %v", err)
+	}
+	return &user_v1_pb.GetLoginSLOResponse{
+		WindowStart:          timestamppb.New(summary.Start),
+		WindowEnd:            timestamppb.New(summary.End),
+		LoginSuccess:         indicatorToProto(summary.LoginSuccess),
+		TokenIssuanceP99:     indicatorToProto(summary.TokenIssuance),
+		OauthExchangeSuccess: indicatorToProto(summary.OAuthExchangeSuccess),
+	}, nil
+}
+
+func indicatorToProto(indicator slo.Indicator) *user_v1_pb.ServiceLevelIndicator {
+	return &user_v1_pb.ServiceLevelIndicator{
+		Value:                indicator.Value,
+		Target:               indicator.Target,
+		Total:                indicator.Total,
+		Bad:                  indicator.Bad,
+		ErrorBudgetRemaining: indicator.BudgetRemaining,
+	}
+}
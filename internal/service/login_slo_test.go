@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/slo"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetLoginSLO(t *testing.T) {
+	rdb, _ := testutil.NewRedis(t)
+	ctx := context.Background()
+	s := &userService{}
+	req := &user_v1_pb.GetLoginSLORequest{}
+	if _, err := s.GetLoginSLO(ctx, req); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition without a recorder, got %v", err)
+	}
+
+	s.slo = slo.NewRecorder(rdb, configs.SLOConfig{
+		LoginSuccess:         0.5,
+		TokenIssuanceP99:     time.Second,
+		OAuthExchangeSuccess: 0.99,
+	})
+	s.slo.Login(ctx, "password", nil)
+	s.slo.Login(ctx, "password", status.Error(codes.Unavailable, "database is down"))
+	resp, err := s.GetLoginSLO(ctx, req)
+	if err != nil {
+		t.Fatalf("GetLoginSLO failed: %v", err)
+	}
+	login := resp.LoginSuccess
+	if login.Total != 2 || login.Bad != 1 || login.Value != 0.5 || login.ErrorBudgetRemaining != 0 {
+		t.Errorf("unexpected login SLI %v", login)
+	}
+	if resp.TokenIssuanceP99.Target != 1 || resp.OauthExchangeSuccess.ErrorBudgetRemaining != 1 {
+		t.Errorf("unexpected SLIs %v", resp)
+	}
+	if !resp.WindowStart.AsTime().Before(resp.WindowEnd.AsTime()) {
+		t.Errorf("unexpected window %v", resp)
+	}
+}
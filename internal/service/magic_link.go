@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"time"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/logctx"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/risk"
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+// RequestMagicLink mails a login link to the account using the email address.
+// Unknown addresses get the same answer, so the RPC can't be used to find out
+// who has an account; links are rate limited per address so it can't be used
+// to flood inboxes either.
+func (s *authService) RequestMagicLink(
+	ctx context.Context,
+	req *auth_v1_pb.RequestMagicLinkRequest,
+) (*auth_v1_pb.RequestMagicLinkResponse, error) {
+	email := strings.TrimSpace(req.Email)
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return nil, status.Error(codes.InvalidArgument, "invalid email address")
+	}
+	allowed, err := s.magicLinkLimit.Allow(ctx, strings.ToLower(email))
+	if err != nil {
+		slog.WarnContext(ctx, "failed to check magic link rate limit", "error", err)
+	} else if !allowed {
+		return nil, status.Error(
+			codes.ResourceExhausted,
+			"too many login links requested, try again later",
+		)
+	}
+
+	expiration := s.config.Auth.MagicLinkExpiration
+	resp := &auth_v1_pb.RequestMagicLinkResponse{
+		ExpiresAt: timestamppb.New(time.Now().Add(expiration)),
+	}
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		slog.InfoContext(ctx, "magic link requested for unknown email")
+		return resp, nil
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to query user: %v", err)
+	}
+	ctx = logctx.WithUserID(ctx, user.ID)
+
+	token, err := s.magicLinks.Create(ctx, &repository.MagicLink{
+		UserID:    user.ID,
+		Email:     user.Email,
+		CreatedAt: time.Now(),
+	}, expiration)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to store magic link", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to store magic link: %v", err)
+	}
+	link := fmt.Sprintf(
+		"%s/magic-link?token=%s",
+		strings.TrimSuffix(s.config.Mailer.LinkBaseURL, "/"),
+		utils.SignToken(s.config.Auth.JWTSecret, token),
+	)
+	if err := s.mailer.Send(
+		ctx,
+		user.Email,
+		"Your login link",
+		fmt.Sprintf(
+			"Log in to your account: %s\n\n"+
+				"The link can be used once within %d minutes. "+
+				"If you did not request it, you can ignore this email.\n",
+			link,
+			int(expiration.Minutes()),
+		),
+	); err != nil {
+		// Answered like unknown addresses, which would otherwise stand out
+		slog.ErrorContext(ctx, "failed to send magic link", "error", err)
+		return resp, nil
+	}
+
+	recordAuditEvent(ctx, s.auditRepo, model.AuditEventMagicLinkSent, &user.ID, nil)
+	slog.InfoContext(ctx, "magic link sent")
+	return resp, nil
+}
+
+// ConsumeMagicLink logs the user in with the token of a magic link. Each link
+// works once, and only while the account still uses the address it was sent to.
+func (s *authService) ConsumeMagicLink(
+	ctx context.Context,
+	req *auth_v1_pb.ConsumeMagicLinkRequest,
+) (*auth_v1_pb.ConsumeMagicLinkResponse, error) {
+	ipAddress := extractIPAddress(ctx)
+	invalid := status.Error(codes.NotFound, "invalid or expired login link")
+
+	token, ok := utils.VerifySignedToken(s.config.Auth.JWTSecret, req.Token)
+	if !ok {
+		s.warnLoginFailure(
+			ctx,
+			ipAddress,
+			"magic link login failed",
+			"error",
+			"invalid signature",
+			"ip_address",
+			ipAddress,
+		)
+		return nil, invalid
+	}
+	link, err := s.magicLinks.Consume(ctx, token)
+	if errors.Is(err, repository.ErrMagicLinkNotFound) {
+		s.warnLoginFailure(
+			ctx,
+			ipAddress,
+			"magic link login failed",
+			"error",
+			"unknown, used or expired link",
+			"ip_address",
+			ipAddress,
+		)
+		return nil, invalid
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to consume magic link: %v", err)
+	}
+	ctx = logctx.WithUserID(ctx, link.UserID)
+
+	user, err := s.userRepo.GetByID(ctx, link.UserID)
+	if err != nil {
+		return nil, invalid
+	}
+	if user.Email != link.Email {
+		slog.InfoContext(ctx, "magic link rejected, email changed since it was sent")
+		recordAuditEvent(
+			ctx,
+			s.auditRepo,
+			model.AuditEventLoginFailed,
+			&user.ID,
+			map[string]string{"method": "magic_link", "reason": "email_changed"},
+		)
+		return nil, invalid
+	}
+	if err := s.checkPendingApproval(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := s.checkMaintenance(ctx, user); err != nil {
+		return nil, err
+	}
+
+	attempt := risk.Attempt{
+		UserID:    user.ID,
+		Email:     user.Email,
+		IPAddress: ipAddress,
+		UserAgent: extractUserAgent(ctx),
+	}
+	assessment, err := s.assessLoginRisk(ctx, attempt, "magic_link")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.LastLoginAt = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		slog.ErrorContext(ctx, "failed to update user last login", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
+	}
+	if err := s.reactivateDormant(ctx, user); err != nil {
+		return nil, err
+	}
+	sessionID, err := s.createSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.rememberLogin(ctx, attempt)
+	s.alertNewLogin(ctx, user, attempt, assessment)
+	metadata := assessment.Metadata()
+	metadata["method"] = "magic_link"
+	recordAuditEvent(ctx, s.auditRepo, model.AuditEventLoginSucceeded, &user.ID, metadata)
+	slog.InfoContext(ctx, "magic link login completed successfully",
+		"session_id", sessionID[:16],
+		"ip_address", ipAddress)
+
+	expiresAt, err := s.getSessionExpirationTime(ctx, sessionID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get session expiration: %v", err)
+	}
+	return &auth_v1_pb.ConsumeMagicLinkResponse{
+		Session: &auth_v1_pb.LoginSession{
+			Id:        sessionID,
+			ExpiresAt: timestamppb.New(expiresAt),
+		},
+	}, nil
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/notify"
+	"github.com/poly-workshop/auth-portal/internal/risk"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"github.com/poly-workshop/auth-portal/internal/throttle"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// recordingMailer remembers the bodies of sent emails by recipient.
+type recordingMailer struct {
+	mu   sync.Mutex
+	sent map[string][]string
+}
+
+func (m *recordingMailer) Send(_ context.Context, to, _, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sent == nil {
+		m.sent = make(map[string][]string)
+	}
+	m.sent[to] = append(m.sent[to], body)
+	return nil
+}
+
+var magicLinkToken = regexp.MustCompile(`/magic-link\?token=(\S+)`)
+
+func TestMagicLink(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	mail := &recordingMailer{}
+	s.mailer = mail
+	s.magicLinkLimit = throttle.NewRateLimiter(s.rdb, "magic_link", 2, time.Hour)
+	s.config.Auth.JWTSecret = "secret"
+	s.config.Auth.MagicLinkExpiration = 15 * time.Minute
+	s.config.Mailer.LinkBaseURL = "https://portal.example.com"
+	ctx := context.Background()
+	user := &model.UserModel{ID: "user-1", Email: "user@example.com", Role: model.UserRoleUser}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(email string) error {
+		_, err := s.RequestMagicLink(ctx, &auth_v1_pb.RequestMagicLinkRequest{Email: email})
+		return err
+	}
+	consume := func(token string) (*auth_v1_pb.ConsumeMagicLinkResponse, error) {
+		return s.ConsumeMagicLink(ctx, &auth_v1_pb.ConsumeMagicLinkRequest{Token: token})
+	}
+	lastToken := func() string {
+		t.Helper()
+		bodies := mail.sent[user.Email]
+		match := magicLinkToken.FindStringSubmatch(bodies[len(bodies)-1])
+		if match == nil {
+			t.Fatalf("expected a login link, got %q", bodies[len(bodies)-1])
+		}
+		return match[1]
+	}
+
+	if err := request("nobody@example.com"); err != nil {
+		t.Fatalf("expected unknown addresses to get the same answer, got %v", err)
+	}
+	if len(mail.sent) != 0 {
+		t.Errorf("expected no email to unknown addresses, got %v", mail.sent)
+	}
+	if err := request(user.Email); err != nil {
+		t.Fatalf("RequestMagicLink failed: %v", err)
+	}
+	token := lastToken()
+
+	if _, err := consume(token + "x"); status.Code(err) != codes.NotFound {
+		t.Errorf("expected a tampered link to be rejected, got %v", err)
+	}
+	resp, err := consume(token)
+	if err != nil {
+		t.Fatalf("ConsumeMagicLink failed: %v", err)
+	}
+	if userID, err := s.sessionRepo.GetUserID(ctx, resp.Session.Id); err != nil ||
+		userID != user.ID {
+		t.Errorf("expected a session of the user, got %q, %v", userID, err)
+	}
+	if _, err := consume(token); status.Code(err) != codes.NotFound {
+		t.Errorf("expected the link to work once, got %v", err)
+	}
+
+	// Links sent to a previous address are void
+	if err := request(user.Email); err != nil {
+		t.Fatalf("RequestMagicLink failed: %v", err)
+	}
+	token = lastToken()
+	user.Email = "new@example.com"
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := consume(token); status.Code(err) != codes.NotFound {
+		t.Errorf("expected the link to be void after an email change, got %v", err)
+	}
+	if err := request("USER@example.com"); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected the third link per address to be refused, got %v", err)
+	}
+}
+
+func TestMagicLinkLoginAlert(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	mail := &recordingMailer{}
+	s.mailer = mail
+	s.notifier = notify.NewNotifier(mail, testutil.NewNotificationPreferencesRepository())
+	s.magicLinkLimit = throttle.NewRateLimiter(s.rdb, "magic_link", 2, time.Hour)
+	s.risk = risk.NewScorer(s.rdb, throttle.NewLoginThrottle(s.rdb, configs.ThrottleConfig{}),
+		configs.RiskConfig{
+			Enabled:           true,
+			BlockThreshold:    100,
+			VelocityWindow:    time.Hour,
+			VelocityMaxLogins: 10,
+			History:           time.Hour,
+		})
+	s.config.Auth.JWTSecret = "secret"
+	s.config.Auth.MagicLinkExpiration = 15 * time.Minute
+	s.config.Mailer.LinkBaseURL = "https://portal.example.com"
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs("user-agent", "new-agent"))
+	user := &model.UserModel{ID: "user-1", Email: "user@example.com", Role: model.UserRoleUser}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	s.rememberLogin(ctx, risk.Attempt{UserID: user.ID, UserAgent: "known-agent"})
+
+	if _, err := s.RequestMagicLink(ctx, &auth_v1_pb.RequestMagicLinkRequest{
+		Email: user.Email,
+	}); err != nil {
+		t.Fatalf("RequestMagicLink failed: %v", err)
+	}
+	match := magicLinkToken.FindStringSubmatch(mail.sent[user.Email][0])
+	if match == nil {
+		t.Fatalf("expected a login link, got %v", mail.sent)
+	}
+	if _, err := s.ConsumeMagicLink(ctx, &auth_v1_pb.ConsumeMagicLinkRequest{
+		Token: match[1],
+	}); err != nil {
+		t.Fatalf("ConsumeMagicLink failed: %v", err)
+	}
+	if len(mail.sent[user.Email]) != 2 {
+		t.Errorf("expected a login alert for the new device, got %v", mail.sent[user.Email])
+	}
+}
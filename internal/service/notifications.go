@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/notify"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxNoticeSubjectLength bounds the subject of security notices, which ends up
+// in an email header.
+const maxNoticeSubjectLength = 200
+
+func (s *userService) GetNotificationPreferences(
+	ctx context.Context,
+	req *user_v1_pb.GetNotificationPreferencesRequest,
+) (*user_v1_pb.GetNotificationPreferencesResponse, error) {
+	userID := callerID(ctx)
+	if userID == "" {
+		return nil, status.Errorf(codes.Unauthenticated, "user info not found in context")
+	}
+	prefs, err := s.notifications.Get(ctx, userID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get notification preferences: %v", err)
+	}
+	return &user_v1_pb.GetNotificationPreferencesResponse{Preferences: prefs.ToPb()}, nil
+}
+
+// UpdateNotificationPreferences changes the preferences set in the request and
+// keeps the others.
+func (s *userService) UpdateNotificationPreferences(
+	ctx context.Context,
+	req *user_v1_pb.UpdateNotificationPreferencesRequest,
+) (*user_v1_pb.UpdateNotificationPreferencesResponse, error) {
+	userID := callerID(ctx)
+	if userID == "" {
+		return nil, status.Errorf(codes.Unauthenticated, "user info not found in context")
+	}
+	prefs, err := s.notifications.Get(ctx, userID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get notification preferences: %v", err)
+	}
+	if req.SecurityEmails != nil {
+		prefs.SecurityEmails = *req.SecurityEmails
+	}
+	if req.ProductEmails != nil {
+		prefs.ProductEmails = *req.ProductEmails
+	}
+	if req.LoginAlerts != nil {
+		prefs.LoginAlerts = *req.LoginAlerts
+	}
+	if err := s.notifications.Save(ctx, prefs); err != nil {
+		slog.ErrorContext(ctx, "failed to save notification preferences", "error", err)
+		return nil, status.Errorf(
+			codes.Internal,
+			"failed to save notification preferences: %v",
+			err,
+		)
+	}
+
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventNotificationsUpdated,
+		&userID,
+		map[string]string{
+			"security_emails": strconv.FormatBool(prefs.SecurityEmails),
+			"product_emails":  strconv.FormatBool(prefs.ProductEmails),
+			"login_alerts":    strconv.FormatBool(prefs.LoginAlerts),
+		},
+	)
+	return &user_v1_pb.UpdateNotificationPreferencesResponse{Preferences: prefs.ToPb()}, nil
+}
+
+// AdminSendSecurityNotice emails a security notice to a user. Mandatory notices
+// override the user's preferences.
+func (s *userService) AdminSendSecurityNotice(
+	ctx context.Context,
+	req *user_v1_pb.AdminSendSecurityNoticeRequest,
+) (*user_v1_pb.AdminSendSecurityNoticeResponse, error) {
+	subject := strings.TrimSpace(req.Subject)
+	switch {
+	case req.UserId == "":
+		return nil, status.Errorf(codes.InvalidArgument, "user_id is required")
+	case subject == "" || strings.TrimSpace(req.Body) == "":
+		return nil, status.Errorf(codes.InvalidArgument, "subject and body are required")
+	case len(subject) > maxNoticeSubjectLength || strings.ContainsAny(subject, "\r\n"):
+		return nil, status.Errorf(
+			codes.InvalidArgument,
+			"subject must be a single line of at most %d characters",
+			maxNoticeSubjectLength,
+		)
+	}
+	user, err := s.userRepo.GetByID(ctx, req.UserId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "failed to get user: %v", err)
+	}
+
+	sent, err := s.notifier.Notify(ctx, user, notify.Notification{
+		Category:  model.NotificationCategorySecurity,
+		Subject:   subject,
+		Body:      req.Body,
+		Mandatory: req.Mandatory,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to send security notice", "error", err, "user_id", user.ID)
+		return nil, status.Errorf(codes.Internal, "failed to send security notice: %v", err)
+	}
+
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventSecurityNoticeSent,
+		&user.ID,
+		map[string]string{
+			"admin_id":  callerID(ctx),
+			"subject":   subject,
+			"mandatory": strconv.FormatBool(req.Mandatory),
+			"sent":      strconv.FormatBool(sent),
+		},
+	)
+	slog.InfoContext(ctx, "security notice processed",
+		"user_id", user.ID,
+		"admin_id", callerID(ctx),
+		"mandatory", req.Mandatory,
+		"sent", sent)
+	return &user_v1_pb.AdminSendSecurityNoticeResponse{Sent: sent}, nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/notify"
+	"github.com/poly-workshop/auth-portal/internal/risk"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNotificationPreferences(t *testing.T) {
+	user := &model.UserModel{ID: "user-1", Email: "user@example.com"}
+	prefs := testutil.NewNotificationPreferencesRepository()
+	mail := &recordingMailer{}
+	auditRepo := testutil.NewAuditRepository()
+	s := &userService{
+		userRepo:      testutil.NewUserRepository(user),
+		auditRepo:     auditRepo,
+		notifications: prefs,
+		notifier:      notify.NewNotifier(mail, prefs),
+	}
+	ctx := asUser(user.ID)
+
+	got, err := s.GetNotificationPreferences(ctx, &user_v1_pb.GetNotificationPreferencesRequest{})
+	if err != nil {
+		t.Fatalf("GetNotificationPreferences failed: %v", err)
+	}
+	if !got.Preferences.SecurityEmails || got.Preferences.ProductEmails ||
+		!got.Preferences.LoginAlerts {
+		t.Errorf("expected the default preferences, got %v", got.Preferences)
+	}
+
+	updated, err := s.UpdateNotificationPreferences(
+		ctx,
+		&user_v1_pb.UpdateNotificationPreferencesRequest{SecurityEmails: proto.Bool(false)},
+	)
+	if err != nil {
+		t.Fatalf("UpdateNotificationPreferences failed: %v", err)
+	}
+	if updated.Preferences.SecurityEmails || !updated.Preferences.LoginAlerts {
+		t.Errorf("expected only security emails to be turned off, got %v", updated.Preferences)
+	}
+	if n := auditRepo.Count(model.AuditEventNotificationsUpdated); n != 1 {
+		t.Errorf("expected 1 audit event, got %d", n)
+	}
+
+	notice := &user_v1_pb.AdminSendSecurityNoticeRequest{
+		UserId:  user.ID,
+		Subject: "Password reset required",
+		Body:    "Your password was found in a breach.",
+	}
+	resp, err := s.AdminSendSecurityNotice(ctx, notice)
+	if err != nil || resp.Sent || len(mail.sent) != 0 {
+		t.Errorf("expected the notice to respect the preferences, got %v, %v", resp, err)
+	}
+	notice.Mandatory = true
+	resp, err = s.AdminSendSecurityNotice(ctx, notice)
+	if err != nil || !resp.Sent || len(mail.sent[user.Email]) != 1 {
+		t.Errorf("expected mandatory notices to be sent, got %v, %v", resp, err)
+	}
+	if n := auditRepo.Count(model.AuditEventSecurityNoticeSent); n != 2 {
+		t.Errorf("expected 2 audit events, got %d", n)
+	}
+
+	notice.Subject = "Multi\nline"
+	if _, err := s.AdminSendSecurityNotice(ctx, notice); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected subjects with line breaks to be rejected, got %v", err)
+	}
+}
+
+func TestAlertNewLogin(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	prefs := testutil.NewNotificationPreferencesRepository()
+	mail := &recordingMailer{}
+	s.notifier = notify.NewNotifier(mail, prefs)
+	ctx := context.Background()
+	user := &model.UserModel{ID: "user-1", Email: "user@example.com"}
+	attempt := risk.Attempt{UserID: user.ID, IPAddress: "192.0.2.1", UserAgent: "agent"}
+
+	velocity := risk.Assessment{Signals: []risk.Signal{risk.SignalVelocity}}
+	s.alertNewLogin(ctx, user, attempt, velocity)
+	if len(mail.sent) != 0 {
+		t.Errorf("expected no alert for known devices and networks, got %v", mail.sent)
+	}
+	newDevice := risk.Assessment{Signals: []risk.Signal{risk.SignalNewDevice}}
+	s.alertNewLogin(ctx, user, attempt, newDevice)
+	if len(mail.sent[user.Email]) != 1 {
+		t.Fatalf("expected a login alert, got %v", mail.sent)
+	}
+
+	if err := prefs.Save(ctx, &model.NotificationPreferencesModel{
+		UserID:         user.ID,
+		SecurityEmails: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	s.alertNewLogin(ctx, user, attempt, newDevice)
+	if len(mail.sent[user.Email]) != 1 {
+		t.Errorf("expected no alert after opting out, got %v", mail.sent)
+	}
+}
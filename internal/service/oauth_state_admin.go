@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// maxListedOAuthStates bounds the states returned by AdminListOAuthStates;
+	// the counts cover all of them.
+	maxListedOAuthStates = 100
+	// oauthStatePrefixLength is the part of a state shown to admins, enough to
+	// tell states apart but not to complete their logins.
+	oauthStatePrefixLength = 8
+)
+
+// AdminListOAuthStates reports the pending OAuth logins, e.g. to debug logins
+// that never come back from the provider.
+func (s *userService) AdminListOAuthStates(
+	ctx context.Context,
+	req *user_v1_pb.AdminListOAuthStatesRequest,
+) (*user_v1_pb.AdminListOAuthStatesResponse, error) {
+	states, err := s.oauthStates.List(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list oauth states", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to list oauth states: %v", err)
+	}
+	states = filterOAuthStates(states, req.Provider, 0)
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].CreatedAt.After(states[j].CreatedAt)
+	})
+
+	resp := &user_v1_pb.AdminListOAuthStatesResponse{
+		Total:           uint32(len(states)),
+		CountByProvider: make(map[string]uint32),
+	}
+	for _, state := range states {
+		resp.CountByProvider[state.Provider]++
+	}
+	if len(states) > 0 {
+		resp.OldestAgeSeconds = uint32(time.Since(states[len(states)-1].CreatedAt).Seconds())
+	}
+	for _, state := range states[:min(len(states), maxListedOAuthStates)] {
+		prefix := state.State
+		if len(prefix) > oauthStatePrefixLength {
+			prefix = prefix[:oauthStatePrefixLength]
+		}
+		resp.States = append(resp.States, &user_v1_pb.OAuthState{
+			StatePrefix: prefix,
+			Provider:    state.Provider,
+			RedirectUrl: state.RedirectURL,
+			CreatedAt:   timestamppb.New(state.CreatedAt),
+			ExpiresAt:   timestamppb.New(state.ExpiresAt),
+		})
+	}
+	return resp, nil
+}
+
+// AdminPurgeOAuthStates deletes pending OAuth logins, e.g. after the client
+// secret of a provider was rotated. Their users have to start the login again.
+func (s *userService) AdminPurgeOAuthStates(
+	ctx context.Context,
+	req *user_v1_pb.AdminPurgeOAuthStatesRequest,
+) (*user_v1_pb.AdminPurgeOAuthStatesResponse, error) {
+	states, err := s.oauthStates.List(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list oauth states", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to list oauth states: %v", err)
+	}
+	olderThan := time.Duration(req.OlderThanSeconds) * time.Second
+	states = filterOAuthStates(states, req.Provider, olderThan)
+	ids := make([]string, len(states))
+	for i, state := range states {
+		ids[i] = state.State
+	}
+	purged, err := s.oauthStates.Delete(ctx, ids...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to purge oauth states", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to purge oauth states: %v", err)
+	}
+
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventOAuthStatesPurged,
+		nil,
+		map[string]string{
+			"admin_id":           callerID(ctx),
+			"provider":           req.Provider,
+			"older_than_seconds": strconv.FormatUint(uint64(req.OlderThanSeconds), 10),
+			"count":              strconv.Itoa(purged),
+		},
+	)
+	slog.InfoContext(
+		ctx,
+		"oauth states purged by admin",
+		"admin_id",
+		callerID(ctx),
+		"provider",
+		req.Provider,
+		"count",
+		purged,
+	)
+	return &user_v1_pb.AdminPurgeOAuthStatesResponse{Purged: uint32(purged)}, nil
+}
+
+// filterOAuthStates keeps the states of provider (any if empty) created at least
+// olderThan ago.
+func filterOAuthStates(
+	states []repository.OAuthState,
+	provider string,
+	olderThan time.Duration,
+) []repository.OAuthState {
+	kept := states[:0]
+	for _, state := range states {
+		if provider != "" && state.Provider != provider {
+			continue
+		}
+		if olderThan > 0 && time.Since(state.CreatedAt) < olderThan {
+			continue
+		}
+		kept = append(kept, state)
+	}
+	return kept
+}
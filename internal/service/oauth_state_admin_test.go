@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+)
+
+func TestAdminOAuthStates(t *testing.T) {
+	rdb, _ := testutil.NewRedis(t)
+	auditRepo := testutil.NewAuditRepository()
+	s := &userService{oauthStates: repository.NewOAuthStateRepository(rdb), auditRepo: auditRepo}
+	ctx := context.Background()
+
+	now := time.Now()
+	for state, data := range map[string]OAuthStateData{
+		"github-new-state": {Provider: "github", CreatedAt: now.Add(-time.Minute)},
+		"github-old-state": {Provider: "github", CreatedAt: now.Add(-9 * time.Minute)},
+		"gitlab-old-state": {Provider: "gitlab", CreatedAt: now.Add(-8 * time.Minute)},
+	} {
+		data.IPAddress = "192.0.2.1"
+		data.ExpiresAt = data.CreatedAt.Add(10 * time.Minute)
+		value, _ := json.Marshal(data)
+		err := rdb.Set(ctx, repository.OAuthStateKey(state), value, time.Hour).Err()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := s.AdminListOAuthStates(ctx, &user_v1_pb.AdminListOAuthStatesRequest{})
+	if err != nil {
+		t.Fatalf("AdminListOAuthStates failed: %v", err)
+	}
+	if list.Total != 3 ||
+		list.CountByProvider["github"] != 2 || list.CountByProvider["gitlab"] != 1 {
+		t.Errorf("Expected 2 github and 1 gitlab states, got %d: %v",
+			list.Total, list.CountByProvider)
+	}
+	if list.OldestAgeSeconds < 9*60 {
+		t.Errorf("Expected the oldest state to be 9 minutes old, got %ds", list.OldestAgeSeconds)
+	}
+	if len(list.States) != 3 || list.States[0].StatePrefix != "github-n" {
+		t.Errorf("Expected the newest state first and shortened, got %v", list.States)
+	}
+
+	purge, err := s.AdminPurgeOAuthStates(ctx, &user_v1_pb.AdminPurgeOAuthStatesRequest{
+		Provider:         "github",
+		OlderThanSeconds: 5 * 60,
+	})
+	if err != nil {
+		t.Fatalf("AdminPurgeOAuthStates failed: %v", err)
+	}
+	if purge.Purged != 1 {
+		t.Errorf("Expected 1 purged state, got %d", purge.Purged)
+	}
+	for state, exists := range map[string]bool{
+		"github-new-state": true,
+		"github-old-state": false,
+		"gitlab-old-state": true,
+	} {
+		if n := rdb.Exists(ctx, repository.OAuthStateKey(state)).Val(); (n == 1) != exists {
+			t.Errorf("Expected state %s to exist: %v", state, exists)
+		}
+	}
+	if n := auditRepo.Count(model.AuditEventOAuthStatesPurged); n != 1 {
+		t.Errorf("Expected 1 audit event, got %d", n)
+	}
+
+	purge, err = s.AdminPurgeOAuthStates(ctx, &user_v1_pb.AdminPurgeOAuthStatesRequest{})
+	if err != nil || purge.Purged != 2 {
+		t.Errorf("Expected the remaining 2 states to be purged, got %v, %v", purge, err)
+	}
+}
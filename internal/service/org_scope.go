@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// OrgScopedMethods are the RPCs whose handlers restrict calls of org admins to
+// the organizations they administer (see auth.WithOrgScopedMethods). An RPC
+// must only be added once its handler honors auth.OrgScopeFromContext.
+var OrgScopedMethods = []string{
+	user_v1_pb.UserService_ListUsers_FullMethodName,
+	user_v1_pb.UserService_SearchUsers_FullMethodName,
+	user_v1_pb.UserService_GetUser_FullMethodName,
+	user_v1_pb.UserService_GetUserByEmail_FullMethodName,
+	user_v1_pb.UserService_UpdateUser_FullMethodName,
+	user_v1_pb.UserService_DeleteUser_FullMethodName,
+	user_v1_pb.TenantSettingsService_ListTenantSettings_FullMethodName,
+	user_v1_pb.TenantSettingsService_GetTenantSettings_FullMethodName,
+	user_v1_pb.TenantSettingsService_UpdateTenantSettings_FullMethodName,
+	user_v1_pb.TenantSettingsService_DeleteTenantSettings_FullMethodName,
+}
+
+// checkOrgScope fails as if user didn't exist when the call is restricted to
+// organizations user isn't in (see auth.OrgScopeFromContext). With manage, it
+// also refuses admins and org admins, whom org admins can't manage: an org
+// admin of one of the caller's organizations may administer others too.
+func (s *userService) checkOrgScope(
+	ctx context.Context,
+	user *model.UserModel,
+	manage bool,
+) error {
+	orgs, scoped := auth.OrgScopeFromContext(ctx)
+	if !scoped {
+		return nil
+	}
+	if !slices.Contains(orgs, user.Org) {
+		return status.Error(codes.NotFound, "user not found")
+	}
+	if !manage {
+		return nil
+	}
+	if user.Role == model.UserRoleAdmin {
+		return status.Error(codes.PermissionDenied, "org admins cannot manage admins")
+	}
+	if s.enforcer == nil {
+		return status.Error(codes.PermissionDenied, "org admins cannot manage users")
+	}
+	isOrgAdmin, err := auth.IsOrgAdmin(s.enforcer, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to check org roles", "error", err, "user_id", user.ID)
+		return status.Error(codes.Internal, "failed to check org roles")
+	}
+	if isOrgAdmin {
+		return status.Error(codes.PermissionDenied, "org admins cannot manage org admins")
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestOrgAdminScope(t *testing.T) {
+	repo := testutil.NewUserRepository(
+		&model.UserModel{
+			ID:    "user-1",
+			Email: "one@acme.test",
+			Org:   "acme",
+			Role:  model.UserRoleUser,
+		},
+		&model.UserModel{ID: "user-2", Email: "two@globex.test", Org: "globex"},
+		&model.UserModel{ID: "org-admin-2", Email: "lead@acme.test", Org: "acme"},
+		&model.UserModel{
+			ID:    "admin-1",
+			Email: "admin@acme.test",
+			Org:   "acme",
+			Role:  model.UserRoleAdmin,
+		},
+	)
+	enforcer, err := auth.NewEnforcer()
+	if err != nil {
+		t.Fatalf("Failed to create enforcer: %v", err)
+	}
+	// org-admin-2 administers acme and globex, which org-admin-1 doesn't
+	for _, org := range []string{"acme", "globex"} {
+		_, err := enforcer.AddNamedGroupingPolicy("g2", "org-admin-2", "org_admin", org)
+		if err != nil {
+			t.Fatalf("Failed to add org admin: %v", err)
+		}
+	}
+	s := &userService{userRepo: repo, enforcer: enforcer}
+	ctx := context.WithValue(asUser("org-admin-1"), auth.ContextKeyOrgScope, []string{"acme"})
+
+	listed, err := s.ListUsers(ctx, &user_v1_pb.ListUsersRequest{})
+	if err != nil {
+		t.Fatalf("ListUsers failed: %v", err)
+	}
+	if listed.Total != 3 {
+		t.Errorf("expected the three users of acme, got %v", listed.Users)
+	}
+
+	_, err = s.GetUser(ctx, &user_v1_pb.GetUserRequest{Id: "user-2"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected GetUser not to find users of other organizations, got %v", err)
+	}
+	if _, err := s.GetUser(ctx, &user_v1_pb.GetUserRequest{Id: "user-1"}); err != nil {
+		t.Errorf("GetUser failed: %v", err)
+	}
+
+	_, err = s.GetUserByEmail(ctx, &user_v1_pb.GetUserByEmailRequest{Email: "two@globex.test"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected users of other organizations not to be found, got %v", err)
+	}
+
+	name := "Renamed"
+	_, err = s.UpdateUser(ctx, &user_v1_pb.UpdateUserRequest{Id: "user-1", Name: &name})
+	if err != nil {
+		t.Fatalf("UpdateUser failed: %v", err)
+	}
+	role := user_v1_pb.UserRole_USER_ROLE_ADMIN
+	_, err = s.UpdateUser(ctx, &user_v1_pb.UpdateUserRequest{Id: "user-1", Role: &role})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected promoting a user to be denied, got %v", err)
+	}
+	org := "globex"
+	_, err = s.UpdateUser(ctx, &user_v1_pb.UpdateUserRequest{Id: "user-1", Org: &org})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected moving a user to be denied, got %v", err)
+	}
+	_, err = s.UpdateUser(ctx, &user_v1_pb.UpdateUserRequest{Id: "admin-1", Name: &name})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected updating an admin to be denied, got %v", err)
+	}
+	_, err = s.UpdateUser(ctx, &user_v1_pb.UpdateUserRequest{Id: "org-admin-2", Name: &name})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected updating an org admin to be denied, got %v", err)
+	}
+	_, err = s.DeleteUser(ctx, &user_v1_pb.DeleteUserRequest{Id: "org-admin-2"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected deleting an org admin to be denied, got %v", err)
+	}
+	user, _ := repo.GetByID(ctx, "user-1")
+	if user.Name != name || user.Role != model.UserRoleUser || user.Org != "acme" {
+		t.Errorf("expected only the name to change, got %+v", user)
+	}
+
+	_, err = s.DeleteUser(ctx, &user_v1_pb.DeleteUserRequest{Id: "user-2"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected deleting a user of another organization to fail, got %v", err)
+	}
+	if _, err := s.DeleteUser(ctx, &user_v1_pb.DeleteUserRequest{Id: "user-1"}); err != nil {
+		t.Fatalf("DeleteUser failed: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, "user-2"); err != nil {
+		t.Errorf("expected the user of globex to remain, got %v", err)
+	}
+}
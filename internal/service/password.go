@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const minPasswordLength = 8
+
+// ChangePassword sets a new password for the current user and clears a pending
+// forced password change.
+func (s *userService) ChangePassword(
+	ctx context.Context,
+	req *user_v1_pb.ChangePasswordRequest,
+) (*user_v1_pb.ChangePasswordResponse, error) {
+	user, err := s.getCurrentUserModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Accounts created through OAuth have no password yet and may set one directly
+	if user.HashedPassword != nil {
+		valid, err := utils.VerifyPassword(req.CurrentPassword, *user.HashedPassword)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to verify password: %v", err)
+		}
+		if !valid {
+			slog.WarnContext(
+				ctx,
+				"password change failed",
+				"error",
+				"invalid current password",
+				"user_id",
+				user.ID,
+			)
+			return nil, status.Errorf(codes.PermissionDenied, "invalid current password")
+		}
+		if req.NewPassword == req.CurrentPassword {
+			return nil, status.Errorf(
+				codes.InvalidArgument,
+				"new password must differ from the current password",
+			)
+		}
+	}
+
+	if err := s.setPassword(ctx, user, req.NewPassword); err != nil {
+		return nil, err
+	}
+	user.MustChangePassword = false
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update password: %v", err)
+	}
+
+	recordAuditEvent(ctx, s.auditRepo, model.AuditEventPasswordChanged, &user.ID, nil)
+	slog.InfoContext(ctx, "password changed", "user_id", user.ID)
+	return &user_v1_pb.ChangePasswordResponse{}, nil
+}
+
+// setPassword hashes password into user, if it is as long as the tenant of
+// user requires.
+func (s *userService) setPassword(
+	ctx context.Context,
+	user *model.UserModel,
+	password string,
+) error {
+	tenant, err := tenantOf(ctx, s.tenants, user.Org)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get tenant settings: %v", err)
+	}
+	if minLength := tenant.MinPasswordLength(minPasswordLength); len(password) < minLength {
+		return status.Errorf(
+			codes.InvalidArgument,
+			"password must be at least %d characters",
+			minLength,
+		)
+	}
+	hashed, err := utils.HashPassword(password)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to hash password: %v", err)
+	}
+	user.HashedPassword = &hashed
+	return nil
+}
+
+// setTemporaryPassword sets a password chosen by an admin, which the user has
+// to replace on next login.
+func (s *userService) setTemporaryPassword(
+	ctx context.Context,
+	user *model.UserModel,
+	password string,
+) error {
+	if err := s.setPassword(ctx, user, password); err != nil {
+		return err
+	}
+	user.MustChangePassword = true
+	return nil
+}
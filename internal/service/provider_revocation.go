@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/logctx"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// RevokeProviderIdentity revokes the sessions and the stored provider token of
+// the user linked to a provider account, and unlinks the account if requested
+// and the user has a password to log in with instead.
+func (s *authService) RevokeProviderIdentity(
+	ctx context.Context,
+	req *auth_v1_pb.RevokeProviderIdentityRequest,
+) (*auth_v1_pb.RevokeProviderIdentityResponse, error) {
+	if req.Provider == "" || req.ProviderUserId == "" {
+		return nil, status.Errorf(
+			codes.InvalidArgument,
+			"provider and provider_user_id are required",
+		)
+	}
+	if req.Provider != "github" {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported provider: %s", req.Provider)
+	}
+
+	user, err := s.userRepo.GetByGithubID(ctx, req.ProviderUserId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "no user is linked to the account")
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to query user by github id", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to query user: %v", err)
+	}
+	ctx = logctx.WithUserID(ctx, user.ID)
+
+	revoked, err := s.sessionRepo.DeleteByUserID(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to revoke sessions", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to revoke sessions: %v", err)
+	}
+	if s.providerTokens != nil {
+		if err := s.providerTokens.Delete(ctx, user.ID, req.Provider); err != nil {
+			slog.ErrorContext(ctx, "failed to delete provider token", "error", err)
+			return nil, status.Errorf(codes.Internal, "failed to delete provider token: %v", err)
+		}
+	}
+
+	// Accounts without a password keep the identity, their only way to log in
+	unlinked := false
+	if req.Unlink && user.HashedPassword != nil {
+		user.GithubID = nil
+		user.GithubLinkedAt = nil
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			slog.ErrorContext(ctx, "failed to unlink identity", "error", err)
+			return nil, status.Errorf(codes.Internal, "failed to unlink identity: %v", err)
+		}
+		unlinked = true
+	}
+
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventIdentityRevoked,
+		&user.ID,
+		map[string]string{
+			"provider": req.Provider,
+			"reason":   req.Reason,
+			"sessions": strconv.Itoa(revoked),
+			"unlinked": strconv.FormatBool(unlinked),
+		},
+	)
+	slog.InfoContext(
+		ctx,
+		"identity revoked by provider",
+		"provider",
+		req.Provider,
+		"reason",
+		req.Reason,
+		"sessions",
+		revoked,
+		"unlinked",
+		unlinked,
+	)
+	return &auth_v1_pb.RevokeProviderIdentityResponse{
+		RevokedSessions: uint32(revoked),
+		Unlinked:        unlinked,
+	}, nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRevokeProviderIdentity(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	providerTokens := testutil.NewProviderTokenRepository()
+	s.providerTokens = providerTokens
+	ctx := context.Background()
+
+	oauthOnlyID, passwordID, password := "1", "2", "hashed"
+	oauthOnly := &model.UserModel{Email: "oauth@example.com", GithubID: &oauthOnlyID}
+	withPassword := &model.UserModel{
+		Email:          "password@example.com",
+		GithubID:       &passwordID,
+		HashedPassword: &password,
+	}
+	for _, user := range []*model.UserModel{oauthOnly, withPassword} {
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.sessionRepo.Create(ctx, user.ID, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		_ = providerTokens.Save(ctx, &repository.ProviderToken{
+			UserID:      user.ID,
+			Provider:    "github",
+			AccessToken: "token",
+		})
+	}
+
+	revoke := func(githubID string) (*auth_v1_pb.RevokeProviderIdentityResponse, error) {
+		return s.RevokeProviderIdentity(ctx, &auth_v1_pb.RevokeProviderIdentityRequest{
+			Provider:       "github",
+			ProviderUserId: githubID,
+			Unlink:         true,
+			Reason:         "authorization_revoked",
+		})
+	}
+	for _, tc := range []struct {
+		user     *model.UserModel
+		unlinked bool
+	}{
+		// The identity is the only way to log in without a password
+		{oauthOnly, false},
+		{withPassword, true},
+	} {
+		resp, err := revoke(*tc.user.GithubID)
+		if err != nil {
+			t.Fatalf("RevokeProviderIdentity failed: %v", err)
+		}
+		if resp.RevokedSessions != 1 || resp.Unlinked != tc.unlinked {
+			t.Errorf("%s: expected 1 revoked session and unlinked %v, got %v",
+				tc.user.Email, tc.unlinked, resp)
+		}
+		if _, err := providerTokens.Get(ctx, tc.user.ID, "github"); err == nil {
+			t.Errorf("%s: expected the provider token to be deleted", tc.user.Email)
+		}
+		user, _ := s.userRepo.GetByID(ctx, tc.user.ID)
+		if (user.GithubID == nil) != tc.unlinked {
+			t.Errorf("%s: expected unlinked %v, got github id %v",
+				tc.user.Email, tc.unlinked, user.GithubID)
+		}
+	}
+
+	if _, err := revoke(passwordID); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unlinked account, got %v", err)
+	}
+	auditRepo := s.auditRepo.(*testutil.AuditRepository)
+	if n := auditRepo.Count(model.AuditEventIdentityRevoked); n != 2 {
+		t.Errorf("expected 2 audit events, got %d", n)
+	}
+}
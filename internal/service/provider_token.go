@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/outbound"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// providerTokenExpiryDelta refreshes tokens this long before they expire, so
+// callers get a token they can still use for a while.
+const providerTokenExpiryDelta = time.Minute
+
+// saveProviderToken keeps the provider token of an OAuth login, if provider
+// tokens are stored. Failures don't fail the login.
+func (s *authService) saveProviderToken(
+	ctx context.Context,
+	userID, provider string,
+	token *oauth2.Token,
+) {
+	if s.providerTokens == nil {
+		return
+	}
+	var scopes []string
+	if oauthConfig, ok := s.oauthConfigs[provider]; ok {
+		scopes = oauthConfig.Scopes
+	}
+	err := s.providerTokens.Save(ctx, newProviderToken(userID, provider, token, scopes))
+	if err != nil {
+		slog.WarnContext(ctx, "failed to store provider token", "error", err, "provider", provider)
+	}
+}
+
+// newProviderToken converts a token issued by a provider. Scopes are taken from
+// the token response if the provider reports them, and default to requested.
+func newProviderToken(
+	userID, provider string,
+	token *oauth2.Token,
+	requested []string,
+) *repository.ProviderToken {
+	scopes := requested
+	if granted, ok := token.Extra("scope").(string); ok && granted != "" {
+		// GitHub separates scopes by commas, the standard by spaces
+		scopes = strings.FieldsFunc(granted, func(r rune) bool {
+			return r == ',' || r == ' '
+		})
+	}
+	return &repository.ProviderToken{
+		UserID:       userID,
+		Provider:     provider,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		TokenType:    token.Type(),
+		Scopes:       scopes,
+		ExpiresAt:    token.Expiry,
+	}
+}
+
+// GetProviderToken returns the stored provider token of a user to another
+// service, refreshing it first if it expired and the provider issued a refresh
+// token.
+func (s *authService) GetProviderToken(
+	ctx context.Context,
+	req *auth_v1_pb.GetProviderTokenRequest,
+) (*auth_v1_pb.GetProviderTokenResponse, error) {
+	if req.UserId == "" || req.Provider == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id and provider are required")
+	}
+	if s.providerTokens == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "provider tokens are not stored")
+	}
+
+	token, err := s.providerTokens.Get(ctx, req.UserId, req.Provider)
+	if errors.Is(err, repository.ErrProviderTokenNotFound) {
+		return nil, status.Errorf(codes.NotFound, "no %s token stored for the user", req.Provider)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get provider token", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to get provider token: %v", err)
+	}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(token.Scopes, scope) {
+			return nil, status.Errorf(
+				codes.FailedPrecondition,
+				"token lacks scope %q; the user has to log in again to grant it",
+				scope,
+			)
+		}
+	}
+
+	refreshed := false
+	expiresSoon := time.Now().Add(providerTokenExpiryDelta).After(token.ExpiresAt)
+	if !token.ExpiresAt.IsZero() && expiresSoon {
+		token, err = s.refreshProviderToken(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		refreshed = true
+	}
+
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventProviderTokenIssued,
+		&req.UserId,
+		map[string]string{
+			"provider":  req.Provider,
+			"caller":    req.Caller,
+			"refreshed": strconv.FormatBool(refreshed),
+		},
+	)
+	resp := &auth_v1_pb.GetProviderTokenResponse{
+		AccessToken: token.AccessToken,
+		TokenType:   token.TokenType,
+		Scopes:      token.Scopes,
+	}
+	if !token.ExpiresAt.IsZero() {
+		resp.ExpiresAt = timestamppb.New(token.ExpiresAt)
+	}
+	return resp, nil
+}
+
+// refreshProviderToken gets a new access token with the refresh token and
+// stores it. Tokens the provider no longer refreshes are deleted, unless a
+// concurrent refresh replaced them meanwhile: providers rotating refresh
+// tokens reject the loser's, and the winner's token is returned instead.
+func (s *authService) refreshProviderToken(
+	ctx context.Context,
+	token *repository.ProviderToken,
+) (*repository.ProviderToken, error) {
+	oauthConfig, ok := s.oauthConfigs[token.Provider]
+	if token.RefreshToken == "" || !ok {
+		return nil, status.Errorf(
+			codes.FailedPrecondition,
+			"token has expired; the user has to log in again",
+		)
+	}
+
+	refreshed, err := oauthConfig.TokenSource(
+		outbound.Context(ctx, s.httpClient),
+		&oauth2.Token{RefreshToken: token.RefreshToken},
+	).Token()
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		// The refresh token expired or the user revoked the app's access
+		slog.InfoContext(ctx, "provider token refresh rejected",
+			"provider", token.Provider, "error_code", retrieveErr.ErrorCode)
+		deleted, err := s.providerTokens.DeleteIfRefreshToken(
+			ctx, token.UserID, token.Provider, token.RefreshToken)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to delete provider token", "error", err)
+		}
+		if err == nil && !deleted {
+			current, err := s.providerTokens.Get(ctx, token.UserID, token.Provider)
+			if err == nil && current.RefreshToken != token.RefreshToken {
+				return current, nil
+			}
+		}
+		return nil, status.Errorf(
+			codes.FailedPrecondition,
+			"token refresh was rejected; the user has to log in again",
+		)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to refresh provider token",
+			"error", err, "provider", token.Provider)
+		return nil, errorWithReason(codes.Unavailable, ErrorReasonDependencyUnavailable,
+			fmt.Sprintf("failed to refresh provider token: %v", err))
+	}
+
+	updated := newProviderToken(token.UserID, token.Provider, refreshed, token.Scopes)
+	if err := s.providerTokens.Save(ctx, updated); err != nil {
+		slog.ErrorContext(ctx, "failed to store refreshed provider token", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to store provider token: %v", err)
+	}
+	return updated, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	providerPkg "github.com/poly-workshop/auth-portal/internal/provider"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetProviderToken(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	oauthProvider := testutil.NewOAuthProvider(t)
+	s.oauthConfigs = map[string]*oauth2.Config{
+		"github": oauthProvider.Config("https://portal.example.com/callback"),
+	}
+	s.userProviders = map[string]providerPkg.UserProvider{"github": oauthProvider}
+	providerTokens := testutil.NewProviderTokenRepository()
+	s.providerTokens = providerTokens
+	auditRepo := s.auditRepo.(*testutil.AuditRepository)
+	ctx := context.Background()
+	userInfo := providerPkg.UserInfo{ID: "42", Name: "Octo", Email: "octo@example.com"}
+
+	state, err := s.generateState(ctx, "github", "", "", "")
+	if err != nil {
+		t.Fatalf("generateState failed: %v", err)
+	}
+	_, err = s.LoginByOAuth(ctx, &auth_v1_pb.LoginByOAuthRequest{
+		Code:  oauthProvider.IssueCode(userInfo),
+		State: state,
+	})
+	if err != nil {
+		t.Fatalf("LoginByOAuth failed: %v", err)
+	}
+	user, err := s.userRepo.GetByGithubID(ctx, "42")
+	if err != nil {
+		t.Fatalf("expected the user to be created: %v", err)
+	}
+
+	get := func(scopes ...string) (*auth_v1_pb.GetProviderTokenResponse, error) {
+		return s.GetProviderToken(ctx, &auth_v1_pb.GetProviderTokenRequest{
+			UserId:   user.ID,
+			Provider: "github",
+			Scopes:   scopes,
+			Caller:   "workshop",
+		})
+	}
+	resp, err := get("user:email")
+	if err != nil {
+		t.Fatalf("GetProviderToken failed: %v", err)
+	}
+	info, err := oauthProvider.GetUserInfo(ctx, resp.AccessToken)
+	if err != nil || info.ID != "42" {
+		t.Errorf("expected the token of the login, got %v (%v)", info, err)
+	}
+	if _, err := get("repo"); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for a scope not granted, got %v", err)
+	}
+
+	// Expired tokens are refreshed and stored
+	stored, _ := providerTokens.Get(ctx, user.ID, "github")
+	stored.ExpiresAt = time.Now().Add(-time.Minute)
+	_ = providerTokens.Save(ctx, stored)
+	refreshed, err := get()
+	if err != nil {
+		t.Fatalf("GetProviderToken of an expired token failed: %v", err)
+	}
+	if refreshed.AccessToken == resp.AccessToken ||
+		!refreshed.ExpiresAt.AsTime().After(time.Now()) {
+		t.Errorf("expected a new token, got %v", refreshed)
+	}
+	stored, _ = providerTokens.Get(ctx, user.ID, "github")
+	if stored.AccessToken != refreshed.AccessToken {
+		t.Error("expected the refreshed token to be stored")
+	}
+	if n := auditRepo.Count(model.AuditEventProviderTokenIssued); n != 2 {
+		t.Errorf("expected 2 audit events, got %d", n)
+	}
+
+	// A refresh losing the race against another one gets the winner's token
+	stale := *stored
+	stale.ExpiresAt = time.Now().Add(-time.Minute)
+	_ = providerTokens.Save(ctx, &stale)
+	if _, err := get(); err != nil {
+		t.Fatalf("GetProviderToken of an expired token failed: %v", err)
+	}
+	current, err := s.refreshProviderToken(ctx, &stale)
+	if err != nil {
+		t.Fatalf("expected the concurrent refresh to be used, got %v", err)
+	}
+	if stored, err = providerTokens.Get(ctx, user.ID, "github"); err != nil ||
+		current.AccessToken != stored.AccessToken {
+		t.Errorf("expected the stored token to be kept and returned, got %v (%v)", current, err)
+	}
+	if n := auditRepo.Count(model.AuditEventProviderTokenIssued); n != 3 {
+		t.Errorf("expected 3 audit events, got %d", n)
+	}
+
+	// Rejected refresh tokens are deleted
+	stored.ExpiresAt = time.Now().Add(-time.Minute)
+	stored.RefreshToken = "revoked"
+	_ = providerTokens.Save(ctx, stored)
+	if _, err := get(); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for a rejected refresh, got %v", err)
+	}
+	_, err = providerTokens.Get(ctx, user.ID, "github")
+	if !errors.Is(err, repository.ErrProviderTokenNotFound) {
+		t.Errorf("expected the token to be deleted, got %v", err)
+	}
+	if _, err := get(); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound without a token, got %v", err)
+	}
+}
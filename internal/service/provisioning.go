@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/provisioning"
+	"google.golang.org/grpc/codes"
+)
+
+// provisionUser runs the provisioning hooks on a user about to be created. The
+// hooks may change the role, name and metadata of the user; the identity of the
+// user at the provider is kept as the provider told it.
+func (s *authService) provisionUser(
+	ctx context.Context,
+	identity provisioning.Identity,
+	user *model.UserModel,
+) error {
+	if len(s.provisioners) == 0 {
+		return nil
+	}
+	email, githubID := user.Email, user.GithubID
+	err := s.provisioners.Provision(ctx, identity, user)
+	user.Email, user.GithubID = email, githubID
+	if reason, rejected := provisioning.IsRejected(err); rejected {
+		slog.InfoContext(ctx, "signup rejected by provisioning hook",
+			"provider", identity.Provider,
+			"reason", reason)
+		recordAuditEvent(
+			ctx,
+			s.auditRepo,
+			model.AuditEventSignupRejected,
+			nil,
+			map[string]string{
+				"provider": identity.Provider,
+				"email":    identity.Email,
+				"reason":   "provisioning_rejected",
+				"detail":   reason,
+			},
+		)
+		msg := "signup was rejected"
+		if reason != "" {
+			msg += ": " + reason
+		}
+		return errorWithReason(codes.PermissionDenied, ErrorReasonSignupRejected, msg)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to provision user",
+			"error", err,
+			"provider", identity.Provider)
+		return errorWithReason(codes.Unavailable, ErrorReasonDependencyUnavailable,
+			fmt.Sprintf("failed to provision user: %v", err))
+	}
+	if user.Role != model.UserRoleUser {
+		slog.InfoContext(ctx, "provisioning hook assigned role", "role", user.Role)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	providerPkg "github.com/poly-workshop/auth-portal/internal/provider"
+	"github.com/poly-workshop/auth-portal/internal/provisioning"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoginByOAuthProvisioning(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	oauthProvider := testutil.NewOAuthProvider(t)
+	s.oauthConfigs = map[string]*oauth2.Config{
+		"github": oauthProvider.Config("https://portal.example.com/callback"),
+	}
+	s.userProviders = map[string]providerPkg.UserProvider{"github": oauthProvider}
+	s.provisioners = provisioning.Hooks{provisioning.HookFunc(
+		func(_ context.Context, identity provisioning.Identity, user *model.UserModel) error {
+			if identity.Email == "outsider@example.com" {
+				return provisioning.Reject("not an employee")
+			}
+			if identity.AccessToken == "" {
+				t.Error("expected hooks to get the access token")
+			}
+			user.Role = model.UserRoleAdmin
+			user.Metadata = map[string]string{"org": "platform"}
+			user.Email = "changed@example.com"
+			return nil
+		},
+	)}
+	ctx := context.Background()
+	login := func(userInfo providerPkg.UserInfo) error {
+		state, err := s.generateState(ctx, "github", "", "", "")
+		if err != nil {
+			t.Fatalf("generateState failed: %v", err)
+		}
+		_, err = s.LoginByOAuth(ctx, &auth_v1_pb.LoginByOAuthRequest{
+			Code:  oauthProvider.IssueCode(userInfo),
+			State: state,
+		})
+		return err
+	}
+
+	if err := login(providerPkg.UserInfo{ID: "42", Email: "octo@example.com"}); err != nil {
+		t.Fatalf("LoginByOAuth failed: %v", err)
+	}
+	user, err := s.userRepo.GetByGithubID(ctx, "42")
+	if err != nil {
+		t.Fatalf("expected the user to be created: %v", err)
+	}
+	if user.Role != model.UserRoleAdmin || user.Metadata["org"] != "platform" {
+		t.Errorf("expected the hook to enrich the user, got %+v", user)
+	}
+	if user.Email != "octo@example.com" {
+		t.Errorf("expected hooks not to change the email, got %s", user.Email)
+	}
+
+	err = login(providerPkg.UserInfo{ID: "43", Email: "outsider@example.com"})
+	if status.Code(err) != codes.PermissionDenied || errorReason(err) != ErrorReasonSignupRejected {
+		t.Fatalf("expected the signup to be rejected, got %v", err)
+	}
+	if n, _ := s.userRepo.Count(ctx, repository.UserFilter{}); n != 1 {
+		t.Errorf("expected no user for the rejected signup, got %d users", n)
+	}
+	auditRepo := s.auditRepo.(*testutil.AuditRepository)
+	if n := auditRepo.Count(model.AuditEventSignupRejected); n != 1 {
+		t.Errorf("expected 1 signup rejected event, got %d", n)
+	}
+}
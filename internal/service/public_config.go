@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/featureflags"
+)
+
+// Names of the features reported by GetPublicConfig
+const (
+	FeatureSignupApproval        = "signup_approval"
+	FeatureEmailDomainRestricted = "email_domain_restricted"
+	FeatureAccountDeletion       = "account_deletion"
+	FeatureEmailChange           = "email_change"
+	FeatureSignupClosed          = "signup_closed"
+	FeatureEmailCheckCaptcha     = "email_check_captcha"
+)
+
+// GetPublicConfig describes the login methods and policies of this deployment,
+// so the frontend does not need to hardcode environment-specific values.
+// It is public and must never expose secrets.
+func (s *authService) GetPublicConfig(
+	ctx context.Context,
+	req *auth_v1_pb.GetPublicConfigRequest,
+) (*auth_v1_pb.GetPublicConfigResponse, error) {
+	names := configuredProviders(s.oauthConfigs)
+	providers := make([]*auth_v1_pb.OAuthProviderInfo, 0, len(names))
+	for _, name := range names {
+		oauthConfig := s.oauthConfigs[name]
+		providers = append(providers, &auth_v1_pb.OAuthProviderInfo{
+			Name:         name,
+			AuthorizeUrl: oauthConfig.Endpoint.AuthURL,
+			RedirectUrl:  oauthConfig.RedirectURL,
+			Scopes:       oauthConfig.Scopes,
+		})
+	}
+
+	account := s.config.Account
+	restricted := len(account.AllowedEmailDomains) > 0
+	features := map[string]bool{
+		FeatureSignupApproval: restricted &&
+			account.DisallowedDomainSignup == configs.DomainSignupApproval,
+		FeatureEmailDomainRestricted: restricted,
+		FeatureAccountDeletion:       true,
+		FeatureEmailChange:           true,
+		FeatureEmailCheckCaptcha:     account.EmailCheckCaptcha,
+	}
+	// Runtime flags are reported as well, e.g. so the SPA can show a maintenance banner
+	flags, err := s.flags.All(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load feature flags for public config", "error", err)
+	}
+	for name, enabled := range flags {
+		features[name] = enabled
+	}
+	// The effective signup mode also depends on the configuration, so it
+	// overrides the raw flags
+	mode := s.signupMode(ctx)
+	features[FeatureSignupClosed] = mode == configs.SignupModeClosed
+	features[featureflags.SignupInviteOnly] = mode == configs.SignupModeInviteOnly
+
+	return &auth_v1_pb.GetPublicConfigResponse{
+		PasswordLoginEnabled: true,
+		OauthProviders:       providers,
+		PasswordPolicy: &auth_v1_pb.PasswordPolicy{
+			MinLength: minPasswordLength,
+		},
+		Features:       features,
+		CaptchaSiteKey: s.config.Captcha.SiteKey,
+	}, nil
+}
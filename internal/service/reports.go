@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"time"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultReportPeriodDays = 30
+	maxReportPeriodDays     = 366
+	defaultReportListLimit  = 50
+	maxReportListLimit      = 200
+	// contentDispositionMetadataKey names the downloaded file; the gateway
+	// serves it as the Content-Disposition header.
+	contentDispositionMetadataKey = "content-disposition"
+)
+
+// CreateReport requests a report, which the report job generates in the
+// background.
+func (s *userService) CreateReport(
+	ctx context.Context,
+	req *user_v1_pb.CreateReportRequest,
+) (*user_v1_pb.CreateReportResponse, error) {
+	kind, ok := model.ReportKindFromPb(req.Kind)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "kind is required")
+	}
+	periodDays := int(req.PeriodDays)
+	switch {
+	case periodDays == 0:
+		periodDays = defaultReportPeriodDays
+	case periodDays < 0 || periodDays > maxReportPeriodDays:
+		return nil, status.Errorf(
+			codes.InvalidArgument,
+			"period_days must be between 1 and %d",
+			maxReportPeriodDays,
+		)
+	}
+	created := &model.ReportModel{
+		Kind:        kind,
+		Format:      model.ReportFormatFromPb(req.Format),
+		Status:      model.ReportStatusPending,
+		PeriodDays:  periodDays,
+		RequestedBy: callerID(ctx),
+	}
+	if err := s.reportRepo.Create(ctx, created); err != nil {
+		slog.ErrorContext(ctx, "failed to create report", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to create report: %v", err)
+	}
+	return &user_v1_pb.CreateReportResponse{Report: created.ToPb()}, nil
+}
+
+// ListReports returns the latest reports, newest first.
+func (s *userService) ListReports(
+	ctx context.Context,
+	req *user_v1_pb.ListReportsRequest,
+) (*user_v1_pb.ListReportsResponse, error) {
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultReportListLimit
+	}
+	limit = min(limit, maxReportListLimit)
+	reports, err := s.reportRepo.List(ctx, limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list reports", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to list reports: %v", err)
+	}
+	pbReports := make([]*user_v1_pb.Report, len(reports))
+	for i, listed := range reports {
+		pbReports[i] = listed.ToPb()
+	}
+	return &user_v1_pb.ListReportsResponse{Reports: pbReports}, nil
+}
+
+// DownloadReport returns a signed link to the file of a generated report,
+// which can be fetched without a token until it expires.
+func (s *userService) DownloadReport(
+	ctx context.Context,
+	req *user_v1_pb.DownloadReportRequest,
+) (*user_v1_pb.DownloadReportResponse, error) {
+	found, err := s.getReport(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if found.Status != model.ReportStatusReady {
+		return nil, status.Errorf(codes.FailedPrecondition, "report is %s", found.Status)
+	}
+	expires := time.Now().Add(s.config.Reports.URLExpiration).Truncate(time.Second)
+	query := url.Values{
+		"expires":   {strconv.FormatInt(expires.Unix(), 10)},
+		"signature": {s.reportSigner.Sign(found.ID, expires)},
+	}
+	link := fmt.Sprintf("%s/v1/reports/%s/content?%s",
+		s.config.Reports.DownloadBaseURL, url.PathEscape(found.ID), query.Encode())
+	return &user_v1_pb.DownloadReportResponse{
+		Url:       link,
+		ExpiresAt: timestamppb.New(expires),
+	}, nil
+}
+
+// GetReportContent serves the file of a report to holders of a signed link.
+func (s *userService) GetReportContent(
+	ctx context.Context,
+	req *user_v1_pb.GetReportContentRequest,
+) (*httpbody.HttpBody, error) {
+	expires := time.Unix(req.Expires, 0)
+	if !s.reportSigner.Verify(req.Id, expires, req.Signature, time.Now()) {
+		return nil, status.Errorf(codes.PermissionDenied, "invalid or expired download link")
+	}
+	found, err := s.getReport(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if found.Status != model.ReportStatusReady {
+		return nil, status.Errorf(codes.FailedPrecondition, "report is %s", found.Status)
+	}
+	file, err := s.objects.Open(found.ObjectPath)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to open report", "error", err, "report_id", found.ID)
+		return nil, status.Errorf(codes.Internal, "failed to open report: %v", err)
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to read report", "error", err, "report_id", found.ID)
+		return nil, status.Errorf(codes.Internal, "failed to read report: %v", err)
+	}
+	disposition := fmt.Sprintf(`attachment; filename="%s-%s.%s"`,
+		found.Kind, found.CreatedAt.UTC().Format("2006-01-02"), found.Format)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(contentDispositionMetadataKey, disposition))
+	return &httpbody.HttpBody{ContentType: found.Format.ContentType(), Data: data}, nil
+}
+
+func (s *userService) getReport(ctx context.Context, id string) (*model.ReportModel, error) {
+	if id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "id is required")
+	}
+	found, err := s.reportRepo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "report not found")
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get report", "error", err, "report_id", id)
+		return nil, status.Errorf(codes.Internal, "failed to get report: %v", err)
+	}
+	return found, nil
+}
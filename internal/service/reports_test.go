@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/job"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/objectstore"
+	"github.com/poly-workshop/auth-portal/internal/report"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReports(t *testing.T) {
+	objects, err := objectstore.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := report.NewSigner("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := testutil.NewUserRepository(&model.UserModel{ID: "user-1", Email: "a@example.com"})
+	s := &userService{
+		userRepo:     users,
+		reportRepo:   testutil.NewReportRepository(),
+		objects:      objects,
+		reportSigner: signer,
+	}
+	s.config.Reports.DownloadBaseURL = "https://auth.example.com/api"
+	s.config.Reports.URLExpiration = time.Minute
+	ctx := asUser("admin-1")
+
+	if _, err := s.CreateReport(ctx, &user_v1_pb.CreateReportRequest{}); status.Code(err) !=
+		codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument without a kind, got %v", err)
+	}
+	created, err := s.CreateReport(ctx, &user_v1_pb.CreateReportRequest{
+		Kind: user_v1_pb.ReportKind_REPORT_KIND_USER_ROSTER,
+	})
+	if err != nil {
+		t.Fatalf("CreateReport failed: %v", err)
+	}
+	if created.Report.PeriodDays != defaultReportPeriodDays ||
+		created.Report.RequestedBy != "admin-1" ||
+		created.Report.Format != user_v1_pb.ReportFormat_REPORT_FORMAT_CSV {
+		t.Errorf("unexpected report %v", created.Report)
+	}
+	id := created.Report.Id
+	download := &user_v1_pb.DownloadReportRequest{Id: id}
+	if _, err := s.DownloadReport(ctx, download); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition before the report is generated, got %v", err)
+	}
+
+	generator := report.NewGenerator(users, testutil.NewAuditRepository())
+	if err := job.NewReportJob(s.reportRepo, generator, objects).Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	listed, err := s.ListReports(ctx, &user_v1_pb.ListReportsRequest{})
+	if err != nil {
+		t.Fatalf("ListReports failed: %v", err)
+	}
+	if len(listed.Reports) != 1 ||
+		listed.Reports[0].Status != user_v1_pb.ReportStatus_REPORT_STATUS_READY {
+		t.Fatalf("expected the ready report, got %v", listed.Reports)
+	}
+
+	resp, err := s.DownloadReport(ctx, download)
+	if err != nil {
+		t.Fatalf("DownloadReport failed: %v", err)
+	}
+	link, err := url.Parse(resp.Url)
+	if err != nil || link.Path != "/api/v1/reports/"+id+"/content" {
+		t.Fatalf("unexpected download URL %q", resp.Url)
+	}
+	expires, _ := strconv.ParseInt(link.Query().Get("expires"), 10, 64)
+	content := &user_v1_pb.GetReportContentRequest{
+		Id:        id,
+		Expires:   expires,
+		Signature: link.Query().Get("signature"),
+	}
+	body, err := s.GetReportContent(context.Background(), content)
+	if err != nil {
+		t.Fatalf("GetReportContent failed: %v", err)
+	}
+	if body.ContentType != "text/csv; charset=utf-8" || len(body.Data) == 0 {
+		t.Errorf("unexpected content %q of type %q", body.Data, body.ContentType)
+	}
+
+	content.Expires++
+	if _, err := s.GetReportContent(context.Background(), content); status.Code(err) !=
+		codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for a tampered link, got %v", err)
+	}
+}
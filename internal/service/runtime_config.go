@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/buildinfo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// AdminGetRuntimeConfig returns the configuration this server loaded at
+// startup, with its secrets redacted, to debug deployments that don't behave as
+// their configuration files suggest.
+func (s *userService) AdminGetRuntimeConfig(
+	ctx context.Context,
+	req *user_v1_pb.AdminGetRuntimeConfigRequest,
+) (*user_v1_pb.AdminGetRuntimeConfigResponse, error) {
+	config, err := structpb.NewValue(configValue(reflect.ValueOf(s.config.Redacted())))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to convert configuration: %v", err)
+	}
+	sources := configs.LoadSources()
+	hostname, _ := os.Hostname()
+	return &user_v1_pb.AdminGetRuntimeConfigResponse{
+		Config:       config.GetStructValue(),
+		Mode:         sources.Mode,
+		ConfigFiles:  sources.Files,
+		EnvOverrides: sources.EnvOverrides,
+		Hostname:     hostname,
+		Version:      buildinfo.Get().Version,
+	}, nil
+}
+
+// configValue converts a configuration value to the types structpb.NewValue
+// takes: structs and maps become maps, durations strings like "1h0m0s".
+func configValue(v reflect.Value) any {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		fields := make(map[string]any, v.NumField())
+		for i := range v.NumField() {
+			if field := v.Type().Field(i); field.IsExported() {
+				fields[field.Name] = configValue(v.Field(i))
+			}
+		}
+		return fields
+	case reflect.Map:
+		entries := make(map[string]any, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			entries[fmt.Sprint(iter.Key().Interface())] = configValue(iter.Value())
+		}
+		return entries
+	case reflect.Slice, reflect.Array:
+		items := make([]any, v.Len())
+		for i := range v.Len() {
+			items[i] = configValue(v.Index(i))
+		}
+		return items
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return configValue(v.Elem())
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
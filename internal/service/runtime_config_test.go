@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+)
+
+func TestAdminGetRuntimeConfig(t *testing.T) {
+	t.Setenv("AUTH__JWT_ISSUER", "https://auth.example.com")
+	s := &userService{config: configs.Config{
+		Auth: configs.AuthConfig{
+			JWTSecret:                 "jwt-secret",
+			JWTIssuer:                 "https://auth.example.com",
+			AccessTokenLifetimeByRole: map[string]time.Duration{"admin": 15 * time.Minute},
+		},
+		Server: configs.ServerConfig{Port: 50051},
+	}}
+
+	resp, err := s.AdminGetRuntimeConfig(
+		context.Background(),
+		&user_v1_pb.AdminGetRuntimeConfigRequest{},
+	)
+	if err != nil {
+		t.Fatalf("AdminGetRuntimeConfig failed: %v", err)
+	}
+	auth := resp.Config.Fields["Auth"].GetStructValue().Fields
+	if got := auth["JWTSecret"].GetStringValue(); got != configs.RedactedValue {
+		t.Errorf("expected the JWT secret to be redacted, got %q", got)
+	}
+	if got := auth["JWTIssuer"].GetStringValue(); got != "https://auth.example.com" {
+		t.Errorf("expected the issuer, got %q", got)
+	}
+	lifetimes := auth["AccessTokenLifetimeByRole"].GetStructValue().Fields
+	if got := lifetimes["admin"].GetStringValue(); got != "15m0s" {
+		t.Errorf("expected durations as strings, got %q", got)
+	}
+	server := resp.Config.Fields["Server"].GetStructValue().Fields
+	if got := server["Port"].GetNumberValue(); got != 50051 {
+		t.Errorf("expected the port, got %v", got)
+	}
+	if resp.Mode == "" || resp.Hostname == "" || resp.Version == "" {
+		t.Errorf("expected the mode, hostname and version, got %+v", resp)
+	}
+	found := false
+	for _, key := range resp.EnvOverrides {
+		found = found || key == "auth.jwt_issuer"
+	}
+	if !found {
+		t.Errorf("expected auth.jwt_issuer among the env overrides, got %v", resp.EnvOverrides)
+	}
+}
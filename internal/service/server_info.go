@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	"github.com/poly-workshop/auth-portal/internal/buildinfo"
+)
+
+// GetServerInfo reports the build of this server. It is public, like the
+// version of most services, so the SPA can compare it with its own.
+func (s *authService) GetServerInfo(
+	ctx context.Context,
+	req *auth_v1_pb.GetServerInfoRequest,
+) (*auth_v1_pb.GetServerInfoResponse, error) {
+	info := buildinfo.Get()
+	return &auth_v1_pb.GetServerInfoResponse{
+		Version:   info.Version,
+		Commit:    info.Commit,
+		BuildDate: info.Date,
+		GoVersion: info.GoVersion,
+	}, nil
+}
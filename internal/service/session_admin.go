@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// AdminRevokeUserSessions revokes every session of a user, e.g. when the account
+// is suspected to be compromised.
+func (s *userService) AdminRevokeUserSessions(
+	ctx context.Context,
+	req *user_v1_pb.AdminRevokeUserSessionsRequest,
+) (*user_v1_pb.AdminRevokeUserSessionsResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_id is required")
+	}
+	_, err := s.userRepo.GetByID(ctx, req.UserId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get user", "error", err, "user_id", req.UserId)
+		return nil, status.Error(codes.Internal, "failed to get user")
+	}
+
+	revoked, err := s.sessionRepo.DeleteByUserID(ctx, req.UserId)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to revoke sessions", "error", err, "user_id", req.UserId)
+		return nil, status.Errorf(codes.Internal, "failed to revoke sessions: %v", err)
+	}
+
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventSessionsRevokedByAdmin,
+		&req.UserId,
+		map[string]string{"admin_id": callerID(ctx), "count": strconv.Itoa(revoked)},
+	)
+	slog.InfoContext(
+		ctx,
+		"user sessions revoked by admin",
+		"user_id",
+		req.UserId,
+		"admin_id",
+		callerID(ctx),
+		"count",
+		revoked,
+	)
+	return &user_v1_pb.AdminRevokeUserSessionsResponse{Revoked: uint32(revoked)}, nil
+}
+
+// AdminRevokeSession revokes a single session, identified either by its ID or by
+// the reference carried in the sid claim of its tokens.
+func (s *userService) AdminRevokeSession(
+	ctx context.Context,
+	req *user_v1_pb.AdminRevokeSessionRequest,
+) (*user_v1_pb.AdminRevokeSessionResponse, error) {
+	if req.SessionId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "session_id is required")
+	}
+
+	userID, err := s.sessionRepo.GetUserID(ctx, req.SessionId)
+	switch {
+	case err == nil:
+		err = s.sessionRepo.Delete(ctx, req.SessionId)
+	case errors.Is(err, repository.ErrSessionNotFound):
+		userID, err = s.sessionRepo.DeleteByRef(ctx, req.SessionId)
+	}
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		return nil, status.Errorf(codes.NotFound, "session not found")
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to revoke session", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to revoke session: %v", err)
+	}
+
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventSessionsRevokedByAdmin,
+		&userID,
+		map[string]string{"admin_id": callerID(ctx), "count": "1"},
+	)
+	slog.InfoContext(
+		ctx,
+		"session revoked by admin",
+		"user_id",
+		userID,
+		"admin_id",
+		callerID(ctx),
+	)
+	return &user_v1_pb.AdminRevokeSessionResponse{}, nil
+}
+
+// callerID returns the ID of the authenticated caller, empty for internal calls.
+func callerID(ctx context.Context) string {
+	if userInfo, ok := ctx.Value(auth.ContextKeyUserInfo).(*auth.UserInfo); ok {
+		return userInfo.UserID
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdminRevokeSession(t *testing.T) {
+	sessionRepo, _ := testutil.NewSessionRepository(t)
+	auditRepo := testutil.NewAuditRepository()
+	s := &userService{sessionRepo: sessionRepo, auditRepo: auditRepo}
+	ctx := context.Background()
+
+	byID, err := sessionRepo.Create(ctx, "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	byRef, err := sessionRepo.Create(ctx, "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	kept, err := sessionRepo.Create(ctx, "user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	for _, id := range []string{byID, repository.SessionRef(byRef)} {
+		_, err := s.AdminRevokeSession(ctx, &user_v1_pb.AdminRevokeSessionRequest{SessionId: id})
+		if err != nil {
+			t.Fatalf("AdminRevokeSession(%s) failed: %v", id, err)
+		}
+	}
+
+	for _, id := range []string{byID, byRef} {
+		if _, err := sessionRepo.GetUserID(ctx, id); err == nil {
+			t.Errorf("Expected session %s to be revoked", id)
+		}
+		if active, _ := sessionRepo.Active(ctx, repository.SessionRef(id)); active {
+			t.Errorf("Expected reference of session %s to be revoked", id)
+		}
+	}
+	if _, err := sessionRepo.GetUserID(ctx, kept); err != nil {
+		t.Errorf("Expected other sessions to be kept, got %v", err)
+	}
+	if n := auditRepo.Count(model.AuditEventSessionsRevokedByAdmin); n != 2 {
+		t.Errorf("Expected 2 audit events, got %d", n)
+	}
+
+	_, err = s.AdminRevokeSession(ctx, &user_v1_pb.AdminRevokeSessionRequest{SessionId: byID})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for a revoked session, got %v", err)
+	}
+}
+
+// unavailableUsers fails every lookup, like a database that is down.
+type unavailableUsers struct {
+	*testutil.UserRepository
+}
+
+func (unavailableUsers) GetByID(context.Context, string) (*model.UserModel, error) {
+	return nil, errors.New("dial tcp 10.0.0.5:5432: connection refused")
+}
+
+func TestAdminRevokeUserSessions(t *testing.T) {
+	sessionRepo, _ := testutil.NewSessionRepository(t)
+	s := &userService{
+		userRepo:    testutil.NewUserRepository(&model.UserModel{ID: "user-1"}),
+		sessionRepo: sessionRepo,
+		auditRepo:   testutil.NewAuditRepository(),
+	}
+	ctx := context.Background()
+	if _, err := sessionRepo.Create(ctx, "user-1", time.Hour); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	resp, err := s.AdminRevokeUserSessions(ctx, &user_v1_pb.AdminRevokeUserSessionsRequest{
+		UserId: "user-1",
+	})
+	if err != nil || resp.Revoked != 1 {
+		t.Fatalf("expected the session to be revoked, got %v (%v)", resp, err)
+	}
+	_, err = s.AdminRevokeUserSessions(ctx, &user_v1_pb.AdminRevokeUserSessionsRequest{
+		UserId: "user-2",
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for a missing user, got %v", err)
+	}
+
+	s.userRepo = unavailableUsers{}
+	_, err = s.AdminRevokeUserSessions(ctx, &user_v1_pb.AdminRevokeUserSessionsRequest{
+		UserId: "user-1",
+	})
+	if status.Code(err) != codes.Internal || strings.Contains(err.Error(), "10.0.0.5") {
+		t.Errorf("expected Internal without the database error, got %v", err)
+	}
+}
@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	maxOrgLength       = 100
+	maxLogoURLLength   = 2048
+	maxTenantMinLength = 128
+)
+
+type tenantSettingsService struct {
+	tenants   repository.TenantSettingsRepository
+	auditRepo repository.AuditRepository
+	// providers are the OAuth providers configured, which tenants may restrict
+	providers []string
+	user_v1_pb.UnimplementedTenantSettingsServiceServer
+}
+
+func NewTenantSettingsService(
+	tenants repository.TenantSettingsRepository,
+	auditRepo repository.AuditRepository,
+	authCfg configs.AuthConfig,
+) user_v1_pb.TenantSettingsServiceServer {
+	return &tenantSettingsService{
+		tenants:   tenants,
+		auditRepo: auditRepo,
+		providers: configuredProviders(OAuthConfigs(authCfg)),
+	}
+}
+
+// configuredProviders returns the names of the OAuth providers users can log
+// in with, sorted.
+func configuredProviders(oauthConfigs map[string]*oauth2.Config) []string {
+	names := make([]string, 0, len(oauthConfigs))
+	for name, oauthConfig := range oauthConfigs {
+		// Providers without client credentials cannot be used to log in
+		if oauthConfig.ClientID == "" {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// tenantOf returns the settings of the tenant of the organization org, nil if
+// it has none.
+func tenantOf(
+	ctx context.Context,
+	tenants repository.TenantSettingsRepository,
+	org string,
+) (*model.TenantSettingsModel, error) {
+	if tenants == nil || org == "" {
+		return nil, nil
+	}
+	return tenants.Get(ctx, org)
+}
+
+func validateOrg(org string) error {
+	if org == "" || len(org) > maxOrgLength {
+		return status.Errorf(
+			codes.InvalidArgument,
+			"org is required and must be at most %d characters",
+			maxOrgLength,
+		)
+	}
+	return nil
+}
+
+// checkTenantScope fails as if the tenant didn't exist when the call is
+// restricted to other organizations (see auth.OrgScopeFromContext).
+func checkTenantScope(ctx context.Context, org string) error {
+	if orgs, scoped := auth.OrgScopeFromContext(ctx); scoped && !slices.Contains(orgs, org) {
+		return status.Error(codes.NotFound, "tenant not found")
+	}
+	return nil
+}
+
+// ListTenantSettings returns the tenants with settings; org admins only see
+// those of the organizations they administer.
+func (s *tenantSettingsService) ListTenantSettings(
+	ctx context.Context,
+	req *user_v1_pb.ListTenantSettingsRequest,
+) (*user_v1_pb.ListTenantSettingsResponse, error) {
+	orgs, _ := auth.OrgScopeFromContext(ctx)
+	tenants, err := s.tenants.List(ctx, orgs)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list tenant settings", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to list tenant settings: %v", err)
+	}
+	resp := &user_v1_pb.ListTenantSettingsResponse{}
+	for _, tenant := range tenants {
+		resp.Tenants = append(resp.Tenants, tenant.ToPb())
+	}
+	return resp, nil
+}
+
+// GetTenantSettings returns the settings of a tenant, empty if it has none.
+func (s *tenantSettingsService) GetTenantSettings(
+	ctx context.Context,
+	req *user_v1_pb.GetTenantSettingsRequest,
+) (*user_v1_pb.GetTenantSettingsResponse, error) {
+	if err := validateOrg(req.Org); err != nil {
+		return nil, err
+	}
+	if err := checkTenantScope(ctx, req.Org); err != nil {
+		return nil, err
+	}
+	tenant, err := s.tenants.Get(ctx, req.Org)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get tenant settings: %v", err)
+	}
+	if tenant == nil {
+		tenant = &model.TenantSettingsModel{Org: req.Org}
+	}
+	return &user_v1_pb.GetTenantSettingsResponse{Settings: tenant.ToPb()}, nil
+}
+
+// UpdateTenantSettings changes the settings set in the request and keeps the
+// others.
+func (s *tenantSettingsService) UpdateTenantSettings(
+	ctx context.Context,
+	req *user_v1_pb.UpdateTenantSettingsRequest,
+) (*user_v1_pb.UpdateTenantSettingsResponse, error) {
+	if err := validateOrg(req.Org); err != nil {
+		return nil, err
+	}
+	if err := checkTenantScope(ctx, req.Org); err != nil {
+		return nil, err
+	}
+	tenant, err := s.tenants.Get(ctx, req.Org)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get tenant settings: %v", err)
+	}
+	if tenant == nil {
+		tenant = &model.TenantSettingsModel{Org: req.Org}
+	}
+	if req.LogoUrl != nil {
+		if err := validateLogoURL(*req.LogoUrl); err != nil {
+			return nil, err
+		}
+		tenant.LogoURL = *req.LogoUrl
+	}
+	if req.AllowedProviders != nil {
+		providers := slices.Clone(req.AllowedProviders.Names)
+		for _, provider := range providers {
+			if !slices.Contains(s.providers, provider) {
+				return nil, status.Errorf(
+					codes.InvalidArgument,
+					"unknown OAuth provider %q, configured ones are %v",
+					provider,
+					s.providers,
+				)
+			}
+		}
+		slices.Sort(providers)
+		tenant.AllowedProviders = slices.Compact(providers)
+	}
+	if req.AccessTokenLifetimeSeconds != nil {
+		if *req.AccessTokenLifetimeSeconds < 0 {
+			return nil, status.Error(
+				codes.InvalidArgument,
+				"access token lifetime must not be negative",
+			)
+		}
+		tenant.AccessTokenLifetime = *req.AccessTokenLifetimeSeconds
+	}
+	if req.SessionLifetimeSeconds != nil {
+		if *req.SessionLifetimeSeconds < 0 {
+			return nil, status.Error(codes.InvalidArgument, "session lifetime must not be negative")
+		}
+		tenant.SessionLifetime = *req.SessionLifetimeSeconds
+	}
+	if req.PasswordMinLength != nil {
+		if *req.PasswordMinLength < 0 || *req.PasswordMinLength > maxTenantMinLength {
+			return nil, status.Errorf(
+				codes.InvalidArgument,
+				"password min length must be between 0 and %d",
+				maxTenantMinLength,
+			)
+		}
+		tenant.PasswordMinLength = int(*req.PasswordMinLength)
+	}
+	tenant.UpdatedAt = time.Now()
+	tenant.UpdatedBy = callerID(ctx)
+	if err := s.tenants.Save(ctx, tenant); err != nil {
+		slog.ErrorContext(ctx, "failed to save tenant settings", "error", err, "org", req.Org)
+		return nil, status.Errorf(codes.Internal, "failed to save tenant settings: %v", err)
+	}
+
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventTenantSettingsChanged,
+		nil,
+		map[string]string{
+			"admin_id":              tenant.UpdatedBy,
+			"org":                   tenant.Org,
+			"logo_url":              tenant.LogoURL,
+			"allowed_providers":     strings.Join(tenant.AllowedProviders, ","),
+			"access_token_lifetime": strconv.FormatInt(tenant.AccessTokenLifetime, 10),
+			"session_lifetime":      strconv.FormatInt(tenant.SessionLifetime, 10),
+			"password_min_length":   strconv.Itoa(tenant.PasswordMinLength),
+		},
+	)
+	slog.InfoContext(ctx, "tenant settings updated",
+		"org", tenant.Org,
+		"admin_id", tenant.UpdatedBy)
+	return &user_v1_pb.UpdateTenantSettingsResponse{Settings: tenant.ToPb()}, nil
+}
+
+// validateLogoURL accepts https URLs, or an empty one to remove the logo.
+func validateLogoURL(logoURL string) error {
+	if logoURL == "" {
+		return nil
+	}
+	parsed, err := url.Parse(logoURL)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" ||
+		len(logoURL) > maxLogoURLLength {
+		return status.Errorf(
+			codes.InvalidArgument,
+			"logo url must be an https URL of at most %d characters",
+			maxLogoURLLength,
+		)
+	}
+	return nil
+}
+
+// DeleteTenantSettings resets the settings of a tenant to the defaults.
+func (s *tenantSettingsService) DeleteTenantSettings(
+	ctx context.Context,
+	req *user_v1_pb.DeleteTenantSettingsRequest,
+) (*user_v1_pb.DeleteTenantSettingsResponse, error) {
+	if err := validateOrg(req.Org); err != nil {
+		return nil, err
+	}
+	if err := checkTenantScope(ctx, req.Org); err != nil {
+		return nil, err
+	}
+	deleted, err := s.tenants.Delete(ctx, req.Org)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to delete tenant settings", "error", err, "org", req.Org)
+		return nil, status.Errorf(codes.Internal, "failed to delete tenant settings: %v", err)
+	}
+	if !deleted {
+		return nil, status.Error(codes.NotFound, "tenant has no settings")
+	}
+
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventTenantSettingsChanged,
+		nil,
+		map[string]string{"admin_id": callerID(ctx), "org": req.Org, "reset": "true"},
+	)
+	slog.InfoContext(ctx, "tenant settings reset", "org", req.Org, "admin_id", callerID(ctx))
+	return &user_v1_pb.DeleteTenantSettingsResponse{}, nil
+}
+
+// GetTenantPublicConfig describes the login page of a tenant. It is public:
+// unknown tenants get the defaults, so they can't be told apart.
+func (s *tenantSettingsService) GetTenantPublicConfig(
+	ctx context.Context,
+	req *user_v1_pb.GetTenantPublicConfigRequest,
+) (*user_v1_pb.GetTenantPublicConfigResponse, error) {
+	if err := validateOrg(req.Org); err != nil {
+		return nil, err
+	}
+	tenant, err := s.tenants.Get(ctx, req.Org)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get tenant settings", "error", err, "org", req.Org)
+		return nil, status.Error(codes.Internal, "failed to get tenant config")
+	}
+	resp := &user_v1_pb.GetTenantPublicConfigResponse{
+		Org:               req.Org,
+		PasswordMinLength: int32(tenant.MinPasswordLength(minPasswordLength)),
+	}
+	if tenant != nil {
+		resp.LogoUrl = tenant.LogoURL
+	}
+	for _, provider := range s.providers {
+		if tenant.AllowsProvider(provider) {
+			resp.OauthProviders = append(resp.OauthProviders, provider)
+		}
+	}
+	return resp, nil
+}
+
+// checkTenantProvider refuses logins with an OAuth provider the tenant of user
+// doesn't allow.
+func (s *authService) checkTenantProvider(
+	ctx context.Context,
+	user *model.UserModel,
+	provider string,
+) error {
+	tenant, err := tenantOf(ctx, s.tenants, user.Org)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get tenant settings", "error", err)
+		return status.Errorf(codes.Internal, "failed to get tenant settings: %v", err)
+	}
+	if tenant.AllowsProvider(provider) {
+		return nil
+	}
+	slog.InfoContext(ctx, "login refused, provider not allowed by tenant",
+		"provider", provider,
+		"org", user.Org)
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventLoginFailed,
+		&user.ID,
+		map[string]string{
+			"method":   "oauth",
+			"provider": provider,
+			"reason":   "provider_not_allowed_by_tenant",
+		},
+	)
+	return status.Errorf(
+		codes.PermissionDenied,
+		"your organization does not allow logging in with %s",
+		provider,
+	)
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTenantSettings(t *testing.T) {
+	auditRepo := testutil.NewAuditRepository()
+	s := &tenantSettingsService{
+		tenants:   testutil.NewTenantSettingsRepository(&model.TenantSettingsModel{Org: "globex"}),
+		auditRepo: auditRepo,
+		providers: []string{"github"},
+	}
+	ctx := context.WithValue(
+		context.Background(),
+		auth.ContextKeyUserInfo,
+		&auth.UserInfo{UserID: "admin-1"},
+	)
+
+	got, err := s.GetTenantSettings(ctx, &user_v1_pb.GetTenantSettingsRequest{Org: "acme"})
+	if err != nil {
+		t.Fatalf("GetTenantSettings failed: %v", err)
+	}
+	if got.Settings.Org != "acme" || got.Settings.PasswordMinLength != 0 {
+		t.Errorf("expected empty settings for a tenant without any, got %v", got.Settings)
+	}
+
+	updated, err := s.UpdateTenantSettings(ctx, &user_v1_pb.UpdateTenantSettingsRequest{
+		Org:                    "acme",
+		LogoUrl:                proto.String("https://cdn.acme.test/logo.png"),
+		AllowedProviders:       &user_v1_pb.TenantProviders{Names: []string{"github", "github"}},
+		SessionLifetimeSeconds: proto.Int64(3600),
+	})
+	if err != nil {
+		t.Fatalf("UpdateTenantSettings failed: %v", err)
+	}
+	settings := updated.Settings
+	if settings.LogoUrl != "https://cdn.acme.test/logo.png" ||
+		len(settings.AllowedProviders) != 1 || settings.SessionLifetimeSeconds != 3600 ||
+		settings.UpdatedBy != "admin-1" {
+		t.Errorf("unexpected settings %v", settings)
+	}
+	updated, err = s.UpdateTenantSettings(ctx, &user_v1_pb.UpdateTenantSettingsRequest{
+		Org:               "acme",
+		PasswordMinLength: proto.Int32(12),
+	})
+	if err != nil {
+		t.Fatalf("UpdateTenantSettings failed: %v", err)
+	}
+	if updated.Settings.PasswordMinLength != 12 || updated.Settings.SessionLifetimeSeconds != 3600 {
+		t.Errorf("expected unset fields to be kept, got %v", updated.Settings)
+	}
+	if n := auditRepo.Count(model.AuditEventTenantSettingsChanged); n != 2 {
+		t.Errorf("expected 2 audit events, got %d", n)
+	}
+
+	for name, req := range map[string]*user_v1_pb.UpdateTenantSettingsRequest{
+		"http logo": {Org: "acme", LogoUrl: proto.String("http://cdn.acme.test/logo.png")},
+		"unknown provider": {
+			Org:              "acme",
+			AllowedProviders: &user_v1_pb.TenantProviders{Names: []string{"gitlab"}},
+		},
+		"negative lifetime": {Org: "acme", AccessTokenLifetimeSeconds: proto.Int64(-1)},
+		"long password":     {Org: "acme", PasswordMinLength: proto.Int32(129)},
+		"no org":            {PasswordMinLength: proto.Int32(10)},
+	} {
+		if _, err := s.UpdateTenantSettings(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected InvalidArgument, got %v", name, err)
+		}
+	}
+
+	// Org admins only see and change the tenants they administer
+	scoped := context.WithValue(ctx, auth.ContextKeyOrgScope, []string{"acme"})
+	listed, err := s.ListTenantSettings(scoped, &user_v1_pb.ListTenantSettingsRequest{})
+	if err != nil {
+		t.Fatalf("ListTenantSettings failed: %v", err)
+	}
+	if len(listed.Tenants) != 1 || listed.Tenants[0].Org != "acme" {
+		t.Errorf("expected only acme, got %v", listed.Tenants)
+	}
+	_, err = s.DeleteTenantSettings(scoped, &user_v1_pb.DeleteTenantSettingsRequest{Org: "globex"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected other tenants not to be found, got %v", err)
+	}
+	listed, err = s.ListTenantSettings(ctx, &user_v1_pb.ListTenantSettingsRequest{})
+	if err != nil || len(listed.Tenants) != 2 {
+		t.Errorf("expected both tenants for admins, got %v, %v", listed, err)
+	}
+
+	_, err = s.DeleteTenantSettings(scoped, &user_v1_pb.DeleteTenantSettingsRequest{Org: "acme"})
+	if err != nil {
+		t.Fatalf("DeleteTenantSettings failed: %v", err)
+	}
+	_, err = s.DeleteTenantSettings(ctx, &user_v1_pb.DeleteTenantSettingsRequest{Org: "acme"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound once reset, got %v", err)
+	}
+}
+
+func TestGetTenantPublicConfig(t *testing.T) {
+	s := &tenantSettingsService{
+		tenants: testutil.NewTenantSettingsRepository(&model.TenantSettingsModel{
+			Org:               "acme",
+			LogoURL:           "https://cdn.acme.test/logo.png",
+			AllowedProviders:  []string{"github"},
+			PasswordMinLength: 14,
+		}),
+		providers: []string{"github", "gitlab"},
+	}
+	ctx := context.Background()
+
+	got, err := s.GetTenantPublicConfig(ctx, &user_v1_pb.GetTenantPublicConfigRequest{Org: "acme"})
+	if err != nil {
+		t.Fatalf("GetTenantPublicConfig failed: %v", err)
+	}
+	if got.LogoUrl != "https://cdn.acme.test/logo.png" || len(got.OauthProviders) != 1 ||
+		got.PasswordMinLength != 14 {
+		t.Errorf("unexpected config %v", got)
+	}
+
+	got, err = s.GetTenantPublicConfig(
+		ctx,
+		&user_v1_pb.GetTenantPublicConfigRequest{Org: "initech"},
+	)
+	if err != nil {
+		t.Fatalf("GetTenantPublicConfig failed: %v", err)
+	}
+	if got.LogoUrl != "" || len(got.OauthProviders) != 2 ||
+		got.PasswordMinLength != minPasswordLength {
+		t.Errorf("expected the defaults for unknown tenants, got %v", got)
+	}
+}
+
+func TestTenantSettingsEnforcement(t *testing.T) {
+	tenants := testutil.NewTenantSettingsRepository(&model.TenantSettingsModel{
+		Org:                 "acme",
+		AllowedProviders:    []string{"gitlab"},
+		AccessTokenLifetime: 60,
+		SessionLifetime:     600,
+		PasswordMinLength:   12,
+	})
+	user := &model.UserModel{ID: "user-1", Email: "one@acme.test", Org: "acme"}
+	ctx := context.Background()
+
+	s, _ := newTestAuthService(t)
+	s.sessionRepo = repository.NewSessionRepository(s.rdb)
+	s.tenants = tenants
+	s.config.Session.ExpirationDuration = 24 * time.Hour
+	sessionID, err := s.createSession(ctx, user)
+	if err != nil {
+		t.Fatalf("createSession failed: %v", err)
+	}
+	if ttl, _ := s.sessionRepo.TTL(ctx, sessionID); ttl > 10*time.Minute {
+		t.Errorf("expected the session lifetime of the tenant, got %v", ttl)
+	}
+	tenant, _ := tenants.Get(ctx, "acme")
+	now := time.Now()
+	expiresAt := s.accessTokenExpiration(now, now.Add(time.Hour), user.Role, tenant)
+	if !expiresAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("expected the token lifetime of the tenant, got %v", expiresAt.Sub(now))
+	}
+	err = s.checkTenantProvider(ctx, user, "github")
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected providers the tenant doesn't allow to be refused, got %v", err)
+	}
+	other := &model.UserModel{ID: "user-2", Email: "two@globex.test", Org: "globex"}
+	if err := s.checkTenantProvider(ctx, other, "github"); err != nil {
+		t.Errorf("expected users of other tenants to be left alone, got %v", err)
+	}
+
+	users := &userService{tenants: tenants}
+	err = users.setPassword(ctx, user, "short-pass")
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected the password policy of the tenant to apply, got %v", err)
+	}
+	if err := users.setPassword(ctx, user, "a-longer-password"); err != nil {
+		t.Errorf("setPassword failed: %v", err)
+	}
+	if err := users.setPassword(ctx, other, "short-pass"); err != nil {
+		t.Errorf("expected users of other tenants to keep the default policy, got %v", err)
+	}
+}
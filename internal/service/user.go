@@ -2,12 +2,32 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"time"
 
+	"github.com/casbin/casbin/v2"
+	"github.com/poly-workshop/auth-portal/configs"
 	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/featureflags"
+	"github.com/poly-workshop/auth-portal/internal/mailer"
 	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/notify"
+	"github.com/poly-workshop/auth-portal/internal/objectstore"
+	"github.com/poly-workshop/auth-portal/internal/report"
 	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/slo"
+	"github.com/poly-workshop/auth-portal/internal/throttle"
+	"github.com/poly-workshop/auth-portal/internal/usage"
+	"github.com/poly-workshop/auth-portal/internal/utils"
 	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
 )
 
 type UserService interface {
@@ -15,18 +35,113 @@ type UserService interface {
 	GetUser(ctx context.Context, req *user_v1_pb.GetUserRequest) (*user_v1_pb.GetCurrentUserResponse, error)
 	UpdateUser(ctx context.Context, req *user_v1_pb.UpdateUserRequest) (*user_v1_pb.UpdateUserResponse, error)
 	DeleteUser(ctx context.Context, req *user_v1_pb.DeleteUserRequest) (*user_v1_pb.DeleteUserResponse, error)
+	GetUserByEmail(ctx context.Context, req *user_v1_pb.GetUserByEmailRequest) (*user_v1_pb.GetUserByEmailResponse, error)
+	BatchGetUsers(ctx context.Context, req *user_v1_pb.BatchGetUsersRequest) (*user_v1_pb.BatchGetUsersResponse, error)
 	ListUsers(ctx context.Context, req *user_v1_pb.ListUsersRequest) (*user_v1_pb.ListUsersResponse, error)
+	SearchUsers(ctx context.Context, req *user_v1_pb.SearchUsersRequest) (*user_v1_pb.SearchUsersResponse, error)
+	ListInactiveUsers(ctx context.Context, req *user_v1_pb.ListInactiveUsersRequest) (*user_v1_pb.ListInactiveUsersResponse, error)
+	ExportUsers(req *user_v1_pb.ExportUsersRequest, stream grpc.ServerStreamingServer[user_v1_pb.ExportUsersResponse]) error
 	GetCurrentUser(ctx context.Context, req *user_v1_pb.GetCurrentUserRequest) (*user_v1_pb.GetCurrentUserResponse, error)
+	ListMyIdentities(ctx context.Context, req *user_v1_pb.ListMyIdentitiesRequest) (*user_v1_pb.ListMyIdentitiesResponse, error)
+	RequestAccountDeletion(ctx context.Context, req *user_v1_pb.RequestAccountDeletionRequest) (*user_v1_pb.RequestAccountDeletionResponse, error)
+	CancelAccountDeletion(ctx context.Context, req *user_v1_pb.CancelAccountDeletionRequest) (*user_v1_pb.CancelAccountDeletionResponse, error)
+	RequestEmailChange(ctx context.Context, req *user_v1_pb.RequestEmailChangeRequest) (*user_v1_pb.RequestEmailChangeResponse, error)
+	ConfirmEmailChange(ctx context.Context, req *user_v1_pb.ConfirmEmailChangeRequest) (*user_v1_pb.ConfirmEmailChangeResponse, error)
+	RollbackEmailChange(ctx context.Context, req *user_v1_pb.RollbackEmailChangeRequest) (*user_v1_pb.RollbackEmailChangeResponse, error)
+	ChangePassword(ctx context.Context, req *user_v1_pb.ChangePasswordRequest) (*user_v1_pb.ChangePasswordResponse, error)
+	AdminRevokeUserSessions(ctx context.Context, req *user_v1_pb.AdminRevokeUserSessionsRequest) (*user_v1_pb.AdminRevokeUserSessionsResponse, error)
+	AdminRevokeSession(ctx context.Context, req *user_v1_pb.AdminRevokeSessionRequest) (*user_v1_pb.AdminRevokeSessionResponse, error)
+	AdminInviteUser(ctx context.Context, req *user_v1_pb.AdminInviteUserRequest) (*user_v1_pb.AdminInviteUserResponse, error)
+	AdminListFeatureFlags(ctx context.Context, req *user_v1_pb.AdminListFeatureFlagsRequest) (*user_v1_pb.AdminListFeatureFlagsResponse, error)
+	AdminSetFeatureFlag(ctx context.Context, req *user_v1_pb.AdminSetFeatureFlagRequest) (*user_v1_pb.AdminSetFeatureFlagResponse, error)
+	AdminListOAuthStates(ctx context.Context, req *user_v1_pb.AdminListOAuthStatesRequest) (*user_v1_pb.AdminListOAuthStatesResponse, error)
+	AdminPurgeOAuthStates(ctx context.Context, req *user_v1_pb.AdminPurgeOAuthStatesRequest) (*user_v1_pb.AdminPurgeOAuthStatesResponse, error)
+	GetNotificationPreferences(ctx context.Context, req *user_v1_pb.GetNotificationPreferencesRequest) (*user_v1_pb.GetNotificationPreferencesResponse, error)
+	UpdateNotificationPreferences(ctx context.Context, req *user_v1_pb.UpdateNotificationPreferencesRequest) (*user_v1_pb.UpdateNotificationPreferencesResponse, error)
+	AdminSendSecurityNotice(ctx context.Context, req *user_v1_pb.AdminSendSecurityNoticeRequest) (*user_v1_pb.AdminSendSecurityNoticeResponse, error)
+	AdminUpdateUserEntitlements(ctx context.Context, req *user_v1_pb.AdminUpdateUserEntitlementsRequest) (*user_v1_pb.AdminUpdateUserEntitlementsResponse, error)
+	GetKeyUsage(ctx context.Context, req *user_v1_pb.GetKeyUsageRequest) (*user_v1_pb.GetKeyUsageResponse, error)
+	GetLoginSLO(ctx context.Context, req *user_v1_pb.GetLoginSLORequest) (*user_v1_pb.GetLoginSLOResponse, error)
+	AdminListFailpoints(ctx context.Context, req *user_v1_pb.AdminListFailpointsRequest) (*user_v1_pb.AdminListFailpointsResponse, error)
+	AdminSetFailpoint(ctx context.Context, req *user_v1_pb.AdminSetFailpointRequest) (*user_v1_pb.AdminSetFailpointResponse, error)
+	CreateReport(ctx context.Context, req *user_v1_pb.CreateReportRequest) (*user_v1_pb.CreateReportResponse, error)
+	ListReports(ctx context.Context, req *user_v1_pb.ListReportsRequest) (*user_v1_pb.ListReportsResponse, error)
+	DownloadReport(ctx context.Context, req *user_v1_pb.DownloadReportRequest) (*user_v1_pb.DownloadReportResponse, error)
+	GetReportContent(ctx context.Context, req *user_v1_pb.GetReportContentRequest) (*httpbody.HttpBody, error)
 }
 
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo        repository.UserRepository
+	sessionRepo     repository.SessionRepository
+	auditRepo       repository.AuditRepository
+	emailChangeRepo repository.EmailChangeRepository
+	roleVersions    repository.RoleVersionRepository
+	tenants         repository.TenantSettingsRepository
+	inviteRepo      repository.InviteRepository
+	oauthStates     repository.OAuthStateRepository
+	notifications   repository.NotificationPreferencesRepository
+	mailer          mailer.Mailer
+	notifier        *notify.Notifier
+	flags           *featureflags.Store
+	keyUsage        *usage.Meter
+	reportRepo      repository.ReportRepository
+	objects         objectstore.Store
+	reportSigner    *report.Signer
+	enforcer        *casbin.SyncedEnforcer
+	searchLimit     *throttle.RateLimiter
+	enumeration     *usage.EnumerationQuota
+	slo             *slo.Recorder
+	config          configs.Config
 	user_v1_pb.UnimplementedUserServiceServer
 }
 
-func NewUserService(userRepo repository.UserRepository) user_v1_pb.UserServiceServer {
+func NewUserService(
+	userRepo repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	auditRepo repository.AuditRepository,
+	emailChangeRepo repository.EmailChangeRepository,
+	roleVersions repository.RoleVersionRepository,
+	tenants repository.TenantSettingsRepository,
+	inviteRepo repository.InviteRepository,
+	oauthStates repository.OAuthStateRepository,
+	notifications repository.NotificationPreferencesRepository,
+	mailer mailer.Mailer,
+	flags *featureflags.Store,
+	keyUsage *usage.Meter,
+	reportRepo repository.ReportRepository,
+	objects objectstore.Store,
+	reportSigner *report.Signer,
+	searchLimit *throttle.RateLimiter,
+	enumeration *usage.EnumerationQuota,
+	sloRecorder *slo.Recorder,
+) user_v1_pb.UserServiceServer {
+	// The enforcer restricts the fields roles may update; all are allowed without it
+	enforcer, err := auth.SharedEnforcer()
+	if err != nil {
+		slog.Warn("failed to create enforcer, not restricting updatable fields", "error", err)
+	}
 	return &userService{
-		userRepo: userRepo,
+		userRepo:        userRepo,
+		sessionRepo:     sessionRepo,
+		auditRepo:       auditRepo,
+		emailChangeRepo: emailChangeRepo,
+		roleVersions:    roleVersions,
+		tenants:         tenants,
+		inviteRepo:      inviteRepo,
+		oauthStates:     oauthStates,
+		notifications:   notifications,
+		mailer:          mailer,
+		notifier:        notify.NewNotifier(mailer, notifications),
+		flags:           flags,
+		keyUsage:        keyUsage,
+		reportRepo:      reportRepo,
+		objects:         objects,
+		reportSigner:    reportSigner,
+		enforcer:        enforcer,
+		searchLimit:     searchLimit,
+		enumeration:     enumeration,
+		slo:             sloRecorder,
+		config:          configs.Load(),
 	}
 }
 
@@ -40,6 +155,11 @@ func (s *userService) CreateUser(
 		GithubID: req.GithubId,
 	}
 	user.Role.FromPb(req.Role)
+	if req.Password != nil {
+		if err := s.setTemporaryPassword(ctx, user, *req.Password); err != nil {
+			return nil, err
+		}
+	}
 
 	err := s.userRepo.Create(ctx, user)
 	if err != nil {
@@ -56,6 +176,10 @@ func (s *userService) GetUser(
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
+	if err := s.checkOrgScope(ctx, user, false); err != nil {
+		return nil, err
+	}
+	setETag(ctx, user.Version)
 	return &user_v1_pb.GetUserResponse{
 		User: user.ToPb(),
 	}, nil
@@ -84,16 +208,82 @@ func (s *userService) UpdateUser(
 	ctx context.Context,
 	req *user_v1_pb.UpdateUserRequest,
 ) (*user_v1_pb.UpdateUserResponse, error) {
+	version, err := expectedVersion(ctx, req.Version)
+	if err != nil {
+		return nil, err
+	}
 	user, err := s.userRepo.GetByID(ctx, req.Id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
+	if err := s.checkOrgScope(ctx, user, true); err != nil {
+		return nil, err
+	}
+	if version != nil && user.Version != *version {
+		return nil, versionMismatchError(*version)
+	}
+
+	if req.GetPhoneNumber() != "" && !utils.ValidPhoneNumber(req.GetPhoneNumber()) {
+		return nil, status.Error(
+			codes.InvalidArgument,
+			"phone number must be in E.164 format, e.g. +15551234567",
+		)
+	}
 
-	user.UpdateFromPb(req)
-	err = s.userRepo.Update(ctx, user)
+	if err := s.restrictUpdateMask(ctx, req); err != nil {
+		return nil, err
+	}
+	previousRole, previousOrg := user.Role, user.Org
+	password := req.Password
+	if req.UpdateMask != nil {
+		password, err = applyUpdateMask(user, req)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		user.UpdateFromPb(req)
+	}
+	if _, scoped := auth.OrgScopeFromContext(ctx); scoped &&
+		(user.Role != previousRole || user.Org != previousOrg) {
+		return nil, status.Error(
+			codes.PermissionDenied,
+			"org admins cannot change the role or organization of users",
+		)
+	}
+	if password != nil {
+		if err := s.setTemporaryPassword(ctx, user, *password); err != nil {
+			return nil, err
+		}
+	}
+	if version != nil {
+		err = s.userRepo.UpdateIfVersion(ctx, user, *version)
+	} else {
+		err = s.userRepo.Update(ctx, user)
+	}
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return nil, versionMismatchError(*version)
+	}
 	if err != nil {
 		return nil, err
 	}
+	setETag(ctx, user.Version)
+	if user.Role != previousRole {
+		// Invalidate tokens carrying the previous role
+		if _, err := s.roleVersions.Bump(ctx, user.ID); err != nil {
+			slog.ErrorContext(ctx, "failed to bump role version", "error", err, "user_id", user.ID)
+			return nil, status.Errorf(codes.Internal, "failed to propagate role change: %v", err)
+		}
+		recordAuditEvent(
+			ctx,
+			s.auditRepo,
+			model.AuditEventRoleChanged,
+			&user.ID,
+			map[string]string{"from": string(previousRole), "to": string(user.Role)},
+		)
+	}
+	if password != nil {
+		recordAuditEvent(ctx, s.auditRepo, model.AuditEventPasswordSetByAdmin, &user.ID, nil)
+	}
 	return &user_v1_pb.UpdateUserResponse{}, nil
 }
 
@@ -101,6 +291,18 @@ func (s *userService) DeleteUser(
 	ctx context.Context,
 	req *user_v1_pb.DeleteUserRequest,
 ) (*user_v1_pb.DeleteUserResponse, error) {
+	if _, scoped := auth.OrgScopeFromContext(ctx); scoped {
+		user, err := s.userRepo.GetByID(ctx, req.Id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+		}
+		if err := s.checkOrgScope(ctx, user, true); err != nil {
+			return nil, err
+		}
+	}
 	err := s.userRepo.Delete(ctx, req.Id)
 	if err != nil {
 		return nil, err
@@ -121,21 +323,117 @@ func (s *userService) ListUsers(
 	offset := int((req.Page - 1) * req.PageSize)
 	limit := int(req.PageSize)
 
+	if err := s.checkEnumeration(ctx); err != nil {
+		return nil, err
+	}
+	filter := repository.UserFilter{PendingApproval: req.PendingApproval}
+	if orgs, scoped := auth.OrgScopeFromContext(ctx); scoped {
+		filter.Orgs = orgs
+	}
 	result := &user_v1_pb.ListUsersResponse{}
-	count, err := s.userRepo.Count(ctx)
+	count, err := s.userRepo.Count(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count users: %w", err)
 	}
 	if count > 0 {
 		result.Total = uint64(count)
-		users, err := s.userRepo.List(ctx, offset, limit)
+		users, err := s.userRepo.List(ctx, filter, offset, limit)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list users: %w", err)
 		}
 		result.Users = make([]*user_v1_pb.User, len(users))
 		for i, user := range users {
-			result.Users[i] = user.ToPb()
+			result.Users[i] = user.ToPbView(req.View)
 		}
+		s.recordEnumeration(ctx, "ListUsers", len(users))
 	}
 	return result, nil
 }
+
+// RequestAccountDeletion schedules the current user's account for deletion after
+// the configured grace period and revokes all of its sessions right away.
+func (s *userService) RequestAccountDeletion(
+	ctx context.Context,
+	req *user_v1_pb.RequestAccountDeletionRequest,
+) (*user_v1_pb.RequestAccountDeletionResponse, error) {
+	user, err := s.getCurrentUserModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeletionScheduledAt == nil {
+		scheduledAt := time.Now().Add(s.config.Account.DeletionGracePeriod)
+		user.DeletionScheduledAt = &scheduledAt
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to schedule deletion: %v", err)
+		}
+		recordAuditEvent(
+			ctx,
+			s.auditRepo,
+			model.AuditEventAccountDeletionRequested,
+			&user.ID,
+			nil,
+		)
+	}
+
+	revoked, err := s.sessionRepo.DeleteByUserID(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to revoke sessions", "error", err, "user_id", user.ID)
+		return nil, status.Errorf(codes.Internal, "failed to revoke sessions: %v", err)
+	}
+
+	slog.InfoContext(
+		ctx,
+		"account deletion requested",
+		"user_id",
+		user.ID,
+		"scheduled_at",
+		*user.DeletionScheduledAt,
+		"revoked_sessions",
+		revoked,
+	)
+	return &user_v1_pb.RequestAccountDeletionResponse{
+		ScheduledAt: timestamppb.New(*user.DeletionScheduledAt),
+	}, nil
+}
+
+// CancelAccountDeletion clears a pending deletion request of the current user.
+func (s *userService) CancelAccountDeletion(
+	ctx context.Context,
+	req *user_v1_pb.CancelAccountDeletionRequest,
+) (*user_v1_pb.CancelAccountDeletionResponse, error) {
+	user, err := s.getCurrentUserModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeletionScheduledAt == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "no account deletion is pending")
+	}
+
+	user.DeletionScheduledAt = nil
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to cancel deletion: %v", err)
+	}
+	recordAuditEvent(
+		ctx,
+		s.auditRepo,
+		model.AuditEventAccountDeletionCancelled,
+		&user.ID,
+		nil,
+	)
+
+	slog.InfoContext(ctx, "account deletion cancelled", "user_id", user.ID)
+	return &user_v1_pb.CancelAccountDeletionResponse{}, nil
+}
+
+func (s *userService) getCurrentUserModel(ctx context.Context) (*model.UserModel, error) {
+	userInfo, ok := ctx.Value(auth.ContextKeyUserInfo).(*auth.UserInfo)
+	if !ok {
+		return nil, status.Errorf(codes.Unauthenticated, "user info not found in context")
+	}
+	user, err := s.userRepo.GetByID(ctx, userInfo.UserID)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "failed to get user: %v", err)
+	}
+	return user, nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"log/slog"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultExportChunkSize = 500
+	maxExportChunkSize     = 1000
+)
+
+// ExportUsers streams all users ordered by ID, one chunk per message. A chunk
+// is only read once the previous one has been handed to the transport, whose
+// flow control blocks Send for slow clients, so at most one chunk is held in
+// memory however many users there are. The export stops once the admin read
+// their daily quota of users.
+func (s *userService) ExportUsers(
+	req *user_v1_pb.ExportUsersRequest,
+	stream grpc.ServerStreamingServer[user_v1_pb.ExportUsersResponse],
+) error {
+	ctx := stream.Context()
+	chunkSize := int(req.ChunkSize)
+	if chunkSize < 1 {
+		chunkSize = defaultExportChunkSize
+	}
+	chunkSize = min(chunkSize, maxExportChunkSize)
+
+	filter := repository.UserFilter{PendingApproval: req.PendingApproval}
+	afterID := ""
+	exported := 0
+	for {
+		if err := s.checkEnumeration(ctx); err != nil {
+			return err
+		}
+		users, err := s.userRepo.ListAfter(ctx, filter, afterID, chunkSize)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to list users: %v", err)
+		}
+		if len(users) == 0 {
+			break
+		}
+
+		resp := &user_v1_pb.ExportUsersResponse{Users: make([]*user_v1_pb.User, len(users))}
+		for i, user := range users {
+			resp.Users[i] = user.ToPbView(req.View)
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+		s.recordEnumeration(ctx, "ExportUsers", len(users))
+		exported += len(users)
+		if len(users) < chunkSize {
+			break
+		}
+		afterID = users[len(users)-1].ID
+	}
+
+	slog.InfoContext(ctx, "users exported", "count", exported, "admin_id", callerID(ctx))
+	return nil
+}
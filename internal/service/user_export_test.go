@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"github.com/poly-workshop/auth-portal/internal/usage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// exportStream collects the messages sent on an ExportUsers stream.
+type exportStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*user_v1_pb.ExportUsersResponse
+}
+
+func (s *exportStream) Context() context.Context {
+	if s.ctx != nil {
+		return s.ctx
+	}
+	return context.Background()
+}
+
+func (s *exportStream) Send(resp *user_v1_pb.ExportUsersResponse) error {
+	s.sent = append(s.sent, resp)
+	return nil
+}
+
+func TestExportUsers(t *testing.T) {
+	var users []*model.UserModel
+	for i := range 7 {
+		users = append(users, &model.UserModel{
+			ID:              fmt.Sprintf("user-%d", i),
+			Email:           fmt.Sprintf("user-%d@example.com", i),
+			PendingApproval: i == 3,
+		})
+	}
+	s := &userService{userRepo: testutil.NewUserRepository(users...)}
+
+	stream := &exportStream{}
+	if err := s.ExportUsers(&user_v1_pb.ExportUsersRequest{ChunkSize: 3}, stream); err != nil {
+		t.Fatalf("ExportUsers failed: %v", err)
+	}
+	var sizes []int
+	seen := map[string]bool{}
+	for _, resp := range stream.sent {
+		sizes = append(sizes, len(resp.Users))
+		for _, user := range resp.Users {
+			seen[user.Id] = true
+		}
+	}
+	if fmt.Sprint(sizes) != "[3 3 1]" || len(seen) != 7 {
+		t.Errorf("expected chunks of 3, 3 and 1 covering every user, got %v", sizes)
+	}
+
+	pending := true
+	stream = &exportStream{}
+	req := &user_v1_pb.ExportUsersRequest{PendingApproval: &pending}
+	if err := s.ExportUsers(req, stream); err != nil {
+		t.Fatalf("ExportUsers failed: %v", err)
+	}
+	if len(stream.sent) != 1 || len(stream.sent[0].Users) != 1 ||
+		stream.sent[0].Users[0].Id != "user-3" {
+		t.Errorf("expected only the pending user, got %v", stream.sent)
+	}
+
+	stream = &exportStream{}
+	req = &user_v1_pb.ExportUsersRequest{View: user_v1_pb.UserView_USER_VIEW_LITE}
+	if err := s.ExportUsers(req, stream); err != nil {
+		t.Fatalf("ExportUsers failed: %v", err)
+	}
+	if user := stream.sent[0].Users[0]; user.Email == "" || user.CreatedAt != nil {
+		t.Errorf("expected the lite view of users, got %v", user)
+	}
+}
+
+func TestExportUsersEnumerationQuota(t *testing.T) {
+	var users []*model.UserModel
+	for i := range 7 {
+		users = append(users, &model.UserModel{
+			ID:    fmt.Sprintf("user-%d", i),
+			Email: fmt.Sprintf("user-%d@example.com", i),
+		})
+	}
+	rdb, _ := testutil.NewRedis(t)
+	cfg := configs.EnumerationConfig{DailyUsers: 4, AlertUsers: 2}
+	auditRepo := testutil.NewAuditRepository()
+	s := &userService{
+		userRepo:    testutil.NewUserRepository(users...),
+		auditRepo:   auditRepo,
+		enumeration: usage.NewEnumerationQuota(rdb, cfg),
+		config:      configs.Config{Enumeration: cfg},
+	}
+
+	stream := &exportStream{ctx: asUser("admin-1")}
+	err := s.ExportUsers(&user_v1_pb.ExportUsersRequest{ChunkSize: 3}, stream)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected the export to stop at the quota, got %v", err)
+	}
+	if len(stream.sent) != 2 {
+		t.Errorf("expected the chunk reaching the quota to be sent, got %d", len(stream.sent))
+	}
+	if n := auditRepo.Count(model.AuditEventEnumerationAlert); n != 1 {
+		t.Errorf("expected one enumeration alert, got %d", n)
+	}
+	_, err = s.ListUsers(asUser("admin-1"), &user_v1_pb.ListUsersRequest{})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected listings to be rejected for the rest of the day, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+const maxBatchGetUsers = 100
+
+func (s *userService) GetUserByEmail(
+	ctx context.Context,
+	req *user_v1_pb.GetUserByEmailRequest,
+) (*user_v1_pb.GetUserByEmailResponse, error) {
+	if req.Email == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
+	}
+	if err := s.checkOrgScope(ctx, user, false); err != nil {
+		return nil, err
+	}
+	return &user_v1_pb.GetUserByEmailResponse{User: user.ToPb()}, nil
+}
+
+// BatchGetUsers looks up all IDs with one query and returns the users in the
+// order they were requested.
+func (s *userService) BatchGetUsers(
+	ctx context.Context,
+	req *user_v1_pb.BatchGetUsersRequest,
+) (*user_v1_pb.BatchGetUsersResponse, error) {
+	if len(req.Ids) > maxBatchGetUsers {
+		return nil, status.Errorf(
+			codes.InvalidArgument,
+			"at most %d ids can be requested at once",
+			maxBatchGetUsers,
+		)
+	}
+	resp := &user_v1_pb.BatchGetUsersResponse{}
+	if len(req.Ids) == 0 {
+		return resp, nil
+	}
+	users, err := s.userRepo.GetByIDs(ctx, req.Ids)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get users: %v", err)
+	}
+	byID := make(map[string]*model.UserModel, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+	for _, id := range req.Ids {
+		if user, ok := byID[id]; ok {
+			resp.Users = append(resp.Users, user.ToPb())
+		}
+	}
+	return resp, nil
+}
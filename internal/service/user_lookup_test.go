@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetUserByEmail(t *testing.T) {
+	s := &userService{userRepo: testutil.NewUserRepository(
+		&model.UserModel{ID: "user-1", Email: "a@example.com"},
+	)}
+	ctx := context.Background()
+
+	resp, err := s.GetUserByEmail(ctx, &user_v1_pb.GetUserByEmailRequest{Email: "a@example.com"})
+	if err != nil || resp.User.Id != "user-1" {
+		t.Fatalf("expected user-1, got %v, %v", resp, err)
+	}
+	_, err = s.GetUserByEmail(ctx, &user_v1_pb.GetUserByEmailRequest{Email: "b@example.com"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown email, got %v", err)
+	}
+}
+
+func TestBatchGetUsers(t *testing.T) {
+	repo := testutil.NewUserRepository()
+	for i := range 3 {
+		_ = repo.Create(context.Background(), &model.UserModel{
+			ID:    fmt.Sprintf("user-%d", i),
+			Email: fmt.Sprintf("user-%d@example.com", i),
+		})
+	}
+	_ = repo.Delete(context.Background(), "user-1")
+	s := &userService{userRepo: repo}
+
+	resp, err := s.BatchGetUsers(context.Background(), &user_v1_pb.BatchGetUsersRequest{
+		Ids: []string{"user-2", "unknown", "user-1", "user-0"},
+	})
+	if err != nil {
+		t.Fatalf("BatchGetUsers failed: %v", err)
+	}
+	var ids []string
+	for _, user := range resp.Users {
+		ids = append(ids, user.Id)
+	}
+	if fmt.Sprint(ids) != "[user-2 user-0]" {
+		t.Errorf("expected existing users in request order, got %v", ids)
+	}
+
+	_, err = s.BatchGetUsers(context.Background(), &user_v1_pb.BatchGetUsersRequest{
+		Ids: make([]string, maxBatchGetUsers+1),
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected too many ids to be rejected, got %v", err)
+	}
+}
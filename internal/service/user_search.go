@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	maxUserSearchResults  = 10
+	minUserSearchQueryLen = 2
+)
+
+// SearchUsers finds the users whose name or email starts with the query, for
+// autocomplete. Results are few and in the lite view, and callers are rate
+// limited, so it can't be used to page through all users; org admins only
+// find the users of their organizations.
+func (s *userService) SearchUsers(
+	ctx context.Context,
+	req *user_v1_pb.SearchUsersRequest,
+) (*user_v1_pb.SearchUsersResponse, error) {
+	query := strings.TrimSpace(req.Query)
+	if utf8.RuneCountInString(query) < minUserSearchQueryLen {
+		return nil, status.Errorf(
+			codes.InvalidArgument,
+			"query must be at least %d characters",
+			minUserSearchQueryLen,
+		)
+	}
+	if s.searchLimit != nil {
+		allowed, err := s.searchLimit.Allow(ctx, callerID(ctx))
+		if err != nil {
+			slog.WarnContext(ctx, "failed to check user search rate limit", "error", err)
+		} else if !allowed {
+			return nil, status.Error(codes.ResourceExhausted, "too many searches, try again later")
+		}
+	}
+	if err := s.checkEnumeration(ctx); err != nil {
+		return nil, err
+	}
+	limit := int(req.Limit)
+	if limit < 1 || limit > maxUserSearchResults {
+		limit = maxUserSearchResults
+	}
+
+	var filter repository.UserFilter
+	if orgs, scoped := auth.OrgScopeFromContext(ctx); scoped {
+		filter.Orgs = orgs
+	}
+	users, err := s.userRepo.Search(ctx, filter, query, limit)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to search users: %v", err)
+	}
+	resp := &user_v1_pb.SearchUsersResponse{Users: make([]*user_v1_pb.User, len(users))}
+	for i, user := range users {
+		resp.Users[i] = user.ToPbView(user_v1_pb.UserView_USER_VIEW_LITE)
+	}
+	s.recordEnumeration(ctx, "SearchUsers", len(users))
+	return resp, nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"github.com/poly-workshop/auth-portal/internal/throttle"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSearchUsers(t *testing.T) {
+	repo := testutil.NewUserRepository(
+		&model.UserModel{ID: "user-1", Name: "Alice Smith", Email: "alice@acme.test", Org: "acme"},
+		&model.UserModel{ID: "user-2", Name: "Bob", Email: "alfred@globex.test", Org: "globex"},
+		&model.UserModel{ID: "user-3", Name: "Albert", Email: "albert@acme.test", Org: "acme"},
+		&model.UserModel{ID: "user-4", Name: "Carol", Email: "carol@acme.test", Org: "acme"},
+	)
+	for i := range 12 {
+		_ = repo.Create(context.Background(), &model.UserModel{
+			ID:    fmt.Sprintf("many-%d", i),
+			Name:  fmt.Sprintf("Many %02d", i),
+			Email: fmt.Sprintf("many-%d@example.com", i),
+		})
+	}
+	rdb, _ := testutil.NewRedis(t)
+	s := &userService{
+		userRepo:    repo,
+		searchLimit: throttle.NewRateLimiter(rdb, "user_search", 4, time.Minute),
+	}
+	ctx := asUser("admin-1")
+	search := func(ctx context.Context, query string) []string {
+		t.Helper()
+		resp, err := s.SearchUsers(ctx, &user_v1_pb.SearchUsersRequest{Query: query})
+		if err != nil {
+			t.Fatalf("SearchUsers(%q) failed: %v", query, err)
+		}
+		var names []string
+		for _, user := range resp.Users {
+			if user.CreatedAt != nil {
+				t.Errorf("expected the lite view, got %v", user)
+			}
+			names = append(names, user.Name)
+		}
+		return names
+	}
+
+	if got := fmt.Sprint(search(ctx, "AL")); got != "[Albert Alice Smith Bob]" {
+		t.Errorf("expected prefix matches on name or email ordered by name, got %s", got)
+	}
+	if got := search(ctx, "many"); len(got) != maxUserSearchResults {
+		t.Errorf("expected at most %d results, got %d", maxUserSearchResults, len(got))
+	}
+	scoped := context.WithValue(ctx, auth.ContextKeyOrgScope, []string{"acme"})
+	if got := fmt.Sprint(search(scoped, "al")); got != "[Albert Alice Smith]" {
+		t.Errorf("expected org admins to find their organization's users only, got %s", got)
+	}
+
+	_, err := s.SearchUsers(ctx, &user_v1_pb.SearchUsersRequest{Query: " a "})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected a one character query to be rejected, got %v", err)
+	}
+	search(ctx, "carol")
+	_, err = s.SearchUsers(ctx, &user_v1_pb.SearchUsersRequest{Query: "carol"})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected the fifth search of a minute to be rate limited, got %v", err)
+	}
+}
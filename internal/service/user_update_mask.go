@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// applyUpdateMask applies the fields named by req.UpdateMask to user; named
+// optional fields that are unset in req are cleared. It returns the password
+// to set, if the mask names one.
+func applyUpdateMask(user *model.UserModel, req *user_v1_pb.UpdateUserRequest) (*string, error) {
+	var password *string
+	for _, path := range req.UpdateMask.GetPaths() {
+		switch path {
+		case "name":
+			if req.Name == nil {
+				return nil, status.Error(codes.InvalidArgument, "name cannot be cleared")
+			}
+			user.Name = *req.Name
+		case "email":
+			if req.Email == nil {
+				return nil, status.Error(codes.InvalidArgument, "email cannot be cleared")
+			}
+			user.Email = *req.Email
+		case "role":
+			if req.Role == nil {
+				return nil, status.Error(codes.InvalidArgument, "role cannot be cleared")
+			}
+			user.Role.FromPb(*req.Role)
+		case "password":
+			if req.Password == nil {
+				return nil, status.Error(codes.InvalidArgument, "password cannot be cleared")
+			}
+			password = req.Password
+		case "github_id":
+			user.SetGithubID(req.GithubId)
+		case "must_change_password":
+			user.MustChangePassword = req.GetMustChangePassword()
+		case "pending_approval":
+			user.PendingApproval = req.GetPendingApproval()
+		case "org":
+			user.Org = req.GetOrg()
+		case "phone_number":
+			user.SetPhoneNumber(req.PhoneNumber)
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "update_mask: unknown field %q", path)
+		}
+	}
+	return password, nil
+}
+
+// restrictUpdateMask restricts an update by a user to the fields their role
+// may update (see auth.UpdatableFields): a mask naming other fields is
+// refused, while without a mask the other fields set in req are ignored.
+// Internal callers may update any field.
+func (s *userService) restrictUpdateMask(
+	ctx context.Context,
+	req *user_v1_pb.UpdateUserRequest,
+) error {
+	userInfo, ok := ctx.Value(auth.ContextKeyUserInfo).(*auth.UserInfo)
+	if !ok || s.enforcer == nil {
+		return nil
+	}
+	var role model.UserRole
+	role.FromPb(userInfo.Role)
+	fields, err := auth.UpdatableFields(
+		s.enforcer,
+		string(role),
+		user_v1_pb.UserService_UpdateUser_FullMethodName,
+	)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get updatable fields", "error", err)
+		return status.Errorf(codes.Internal, "failed to check permissions: %v", err)
+	}
+	if fields == nil {
+		return nil
+	}
+
+	if req.UpdateMask != nil {
+		for _, path := range req.UpdateMask.GetPaths() {
+			if !slices.Contains(fields, path) {
+				return status.Errorf(
+					codes.PermissionDenied,
+					"role %s may not update %s",
+					role,
+					path,
+				)
+			}
+		}
+		return nil
+	}
+	mask := &fieldmaskpb.FieldMask{}
+	req.ProtoReflect().Range(func(field protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if slices.Contains(fields, string(field.Name())) {
+			mask.Paths = append(mask.Paths, string(field.Name()))
+		}
+		return true
+	})
+	req.UpdateMask = mask
+	return nil
+}
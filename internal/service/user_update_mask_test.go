@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"github.com/poly-workshop/auth-portal/pkg/auth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestUpdateUserMask(t *testing.T) {
+	githubID := "4242"
+	repo := testutil.NewUserRepository(&model.UserModel{
+		ID:                 "user-1",
+		Name:               "Octo Cat",
+		Email:              "octocat@example.com",
+		GithubID:           &githubID,
+		MustChangePassword: true,
+	})
+	s := &userService{userRepo: repo}
+	ctx := context.Background()
+
+	// Only the masked fields change: github_id is cleared, the name in the
+	// request is ignored.
+	name := "ignored"
+	_, err := s.UpdateUser(ctx, &user_v1_pb.UpdateUserRequest{
+		Id:         "user-1",
+		Name:       &name,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"github_id", "must_change_password"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateUser failed: %v", err)
+	}
+	user, _ := repo.GetByID(ctx, "user-1")
+	if user.GithubID != nil || user.MustChangePassword || user.Name != "Octo Cat" {
+		t.Errorf("expected only github_id and must_change_password to be cleared, got %+v", user)
+	}
+
+	for _, paths := range [][]string{{"name"}, {"password"}, {"id"}, {"unknown"}} {
+		_, err := s.UpdateUser(ctx, &user_v1_pb.UpdateUserRequest{
+			Id:         "user-1",
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: paths},
+		})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("mask %v: expected InvalidArgument, got %v", paths, err)
+		}
+	}
+}
+
+func TestUpdateUserRestrictedFields(t *testing.T) {
+	enforcer, err := casbin.NewSyncedEnforcer(
+		filepath.Join("..", "..", "configs", "rbac_model.conf"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create enforcer: %v", err)
+	}
+	for _, field := range []string{"name", "phone_number"} {
+		if _, err := enforcer.AddPolicy("admin", "/UserService/UpdateUser#"+field); err != nil {
+			t.Fatalf("AddPolicy failed: %v", err)
+		}
+	}
+	repo := testutil.NewUserRepository(&model.UserModel{
+		ID:    "user-1",
+		Name:  "Octo Cat",
+		Email: "octocat@example.com",
+		Role:  model.UserRoleUser,
+	})
+	s := &userService{userRepo: repo, enforcer: enforcer}
+	ctx := context.WithValue(context.Background(), auth.ContextKeyUserInfo, &auth.UserInfo{
+		UserID: "admin-1",
+		Role:   user_v1_pb.UserRole_USER_ROLE_ADMIN,
+	})
+
+	// A mask naming a field the role may not update is refused
+	email := "mallory@example.com"
+	_, err = s.UpdateUser(ctx, &user_v1_pb.UpdateUserRequest{
+		Id:         "user-1",
+		Email:      &email,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name", "email"}},
+	})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied, got %v", err)
+	}
+
+	// Without a mask only the permitted fields change
+	name := "Mona Lisa"
+	role := user_v1_pb.UserRole_USER_ROLE_ADMIN
+	_, err = s.UpdateUser(ctx, &user_v1_pb.UpdateUserRequest{
+		Id:    "user-1",
+		Name:  &name,
+		Email: &email,
+		Role:  &role,
+	})
+	if err != nil {
+		t.Fatalf("UpdateUser failed: %v", err)
+	}
+	user, _ := repo.GetByID(ctx, "user-1")
+	if user.Name != name || user.Email != "octocat@example.com" || user.Role != model.UserRoleUser {
+		t.Errorf("expected only the name to change, got %+v", user)
+	}
+
+	// Internal callers are not restricted
+	_, err = s.UpdateUser(context.Background(), &user_v1_pb.UpdateUserRequest{
+		Id:         "user-1",
+		Email:      &email,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"email"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateUser failed: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorReasonVersionMismatch is returned by UpdateUser when the user changed
+// since the version the client sent; the gateway answers it with 412.
+const ErrorReasonVersionMismatch = "VERSION_MISMATCH"
+
+const (
+	// etagMetadataKey carries the user version back to the gateway, which
+	// serves it as the ETag header.
+	etagMetadataKey = "etag"
+	// ifMatchMetadataKey is the If-Match header forwarded by the gateway.
+	ifMatchMetadataKey = "if-match"
+)
+
+// formatETag renders a user version as a strong entity tag.
+func formatETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// setETag sends the user version as response header metadata. Outside of a
+// gRPC call (e.g. in tests) there is nothing to send it on, which is fine.
+func setETag(ctx context.Context, version int64) {
+	_ = grpc.SetHeader(ctx, metadata.Pairs(etagMetadataKey, formatETag(version)))
+}
+
+// expectedVersion returns the version an update is conditional on: the
+// request's version field, else the If-Match value forwarded by the gateway.
+// It returns nil for unconditional updates, including "If-Match: *".
+func expectedVersion(ctx context.Context, version *int64) (*int64, error) {
+	if version != nil {
+		return version, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(ifMatchMetadataKey)
+	if len(values) == 0 || strings.TrimSpace(values[0]) == "*" {
+		return nil, nil
+	}
+	tag := strings.TrimPrefix(strings.TrimSpace(values[0]), "W/")
+	parsed, err := strconv.ParseInt(strings.Trim(tag, `"`), 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid If-Match value %q", values[0])
+	}
+	return &parsed, nil
+}
+
+func versionMismatchError(version int64) error {
+	return errorWithReason(
+		codes.FailedPrecondition,
+		ErrorReasonVersionMismatch,
+		"user was modified since version "+strconv.FormatInt(version, 10),
+	)
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// headerStream records the header metadata set by a handler.
+type headerStream struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestUserVersionETag(t *testing.T) {
+	repo := testutil.NewUserRepository(&model.UserModel{ID: "user-1", Email: "a@example.com"})
+	s := &userService{userRepo: repo}
+
+	stream := &headerStream{}
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+	resp, err := s.GetUser(ctx, &user_v1_pb.GetUserRequest{Id: "user-1"})
+	if err != nil {
+		t.Fatalf("GetUser failed: %v", err)
+	}
+	if resp.User.Version != 1 || stream.header.Get("etag")[0] != `"1"` {
+		t.Fatalf(
+			"expected version 1 and ETag \"1\", got %d and %v",
+			resp.User.Version,
+			stream.header,
+		)
+	}
+
+	name := "renamed"
+	update := func(ifMatch string, version *int64) error {
+		ctx := context.Background()
+		if ifMatch != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("if-match", ifMatch))
+		}
+		_, err := s.UpdateUser(
+			ctx,
+			&user_v1_pb.UpdateUserRequest{Id: "user-1", Name: &name, Version: version},
+		)
+		return err
+	}
+
+	if err := update(`"1"`, nil); err != nil {
+		t.Fatalf("expected the update with the current ETag to succeed, got %v", err)
+	}
+	err = update(`"1"`, nil)
+	if status.Code(err) != codes.FailedPrecondition ||
+		errorReason(err) != ErrorReasonVersionMismatch {
+		t.Errorf("expected a version mismatch for a stale ETag, got %v", err)
+	}
+	stale := int64(1)
+	if err := update("", &stale); errorReason(err) != ErrorReasonVersionMismatch {
+		t.Errorf("expected a version mismatch for a stale version field, got %v", err)
+	}
+	if err := update("*", nil); err != nil {
+		t.Errorf("expected If-Match: * to update unconditionally, got %v", err)
+	}
+	if err := update("", nil); err != nil {
+		t.Errorf("expected updates without a version to stay unconditional, got %v", err)
+	}
+	if err := update("not-a-version", nil); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected an invalid If-Match to be rejected, got %v", err)
+	}
+
+	user, _ := repo.GetByID(context.Background(), "user-1")
+	if user.Version != 4 {
+		t.Errorf("expected 3 updates to bump the version to 4, got %d", user.Version)
+	}
+}
@@ -0,0 +1,31 @@
+package siem
+
+import (
+	"context"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+)
+
+// auditRepository publishes every stored audit event to the exporter.
+type auditRepository struct {
+	repository.AuditRepository
+	exporter *Exporter
+}
+
+// NewAuditRepository wraps repo so that created events are also exported.
+// Events are only exported once they were stored.
+func NewAuditRepository(
+	repo repository.AuditRepository,
+	exporter *Exporter,
+) repository.AuditRepository {
+	return &auditRepository{AuditRepository: repo, exporter: exporter}
+}
+
+func (r *auditRepository) Create(ctx context.Context, event *model.AuditEventModel) error {
+	if err := r.AuditRepository.Create(ctx, event); err != nil {
+		return err
+	}
+	r.exporter.Publish(EventFromAudit(event))
+	return nil
+}
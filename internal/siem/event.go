@@ -0,0 +1,64 @@
+package siem
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+)
+
+// Event is the exported form of an audit event.
+type Event struct {
+	ID        string          `json:"id"`
+	Time      time.Time       `json:"time"`
+	Type      string          `json:"type"`
+	UserID    string          `json:"user_id,omitempty"`
+	Pseudonym string          `json:"pseudonym,omitempty"`
+	IPAddress string          `json:"ip_address,omitempty"`
+	UserAgent string          `json:"user_agent,omitempty"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
+}
+
+// EventFromAudit converts a stored audit event for export.
+func EventFromAudit(event *model.AuditEventModel) Event {
+	exported := Event{
+		ID:        event.ID,
+		Time:      event.CreatedAt,
+		Type:      string(event.Type),
+		IPAddress: event.IPAddress,
+		UserAgent: event.UserAgent,
+	}
+	if exported.Time.IsZero() {
+		exported.Time = time.Now()
+	}
+	if event.UserID != nil {
+		exported.UserID = *event.UserID
+	}
+	if event.Pseudonym != nil {
+		exported.Pseudonym = *event.Pseudonym
+	}
+	if event.Metadata != "" && json.Valid([]byte(event.Metadata)) {
+		exported.Metadata = json.RawMessage(event.Metadata)
+	}
+	return exported
+}
+
+// matchEventType reports whether eventType is selected by the patterns; a
+// pattern ending in "*" selects every type starting with the rest of it. No
+// patterns select every type.
+func matchEventType(patterns []string, eventType string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(eventType, prefix) {
+				return true
+			}
+		} else if pattern == eventType {
+			return true
+		}
+	}
+	return false
+}
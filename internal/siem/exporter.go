@@ -0,0 +1,175 @@
+package siem
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// maxAttempts is how often a batch is written before it is given up.
+const maxAttempts = 3
+
+var (
+	exportedEvents = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "auth_siem_events_exported_total",
+		Help: "Security events delivered to the SIEM sink.",
+	})
+	droppedEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_siem_events_dropped_total",
+		Help: "Security events dropped before reaching the SIEM sink, by reason.",
+	}, []string{"reason"})
+)
+
+// Exporter streams security events to a SIEM. Events are queued in a bounded
+// buffer and written in batches in the background; when the sink cannot keep up
+// and the buffer is full, further events are dropped rather than slowing down
+// requests. Dropped events remain in the audit log.
+type Exporter struct {
+	formatter     Formatter
+	sink          Sink
+	eventTypes    []string
+	batchSize     int
+	flushInterval time.Duration
+	events        chan Event
+	done          chan struct{}
+}
+
+// NewExporter creates an exporter for the configured sink and format.
+func NewExporter(cfg configs.SIEMConfig) (*Exporter, error) {
+	formatter, err := NewFormatter(cfg.Format)
+	if err != nil {
+		return nil, err
+	}
+	sink, err := NewSink(cfg, formatter.ContentType())
+	if err != nil {
+		return nil, err
+	}
+	return newExporter(cfg, formatter, sink), nil
+}
+
+func newExporter(cfg configs.SIEMConfig, formatter Formatter, sink Sink) *Exporter {
+	flushInterval := cfg.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = configs.DefaultSIEMFlushIntervalSeconds * time.Second
+	}
+	return &Exporter{
+		formatter:     formatter,
+		sink:          sink,
+		eventTypes:    cfg.EventTypes,
+		batchSize:     max(cfg.BatchSize, 1),
+		flushInterval: flushInterval,
+		events:        make(chan Event, max(cfg.BufferSize, 1)),
+		done:          make(chan struct{}),
+	}
+}
+
+// Publish queues an event for export without blocking.
+func (e *Exporter) Publish(event Event) {
+	if !matchEventType(e.eventTypes, event.Type) {
+		return
+	}
+	select {
+	case e.events <- event:
+	default:
+		droppedEvents.WithLabelValues("buffer_full").Inc()
+	}
+}
+
+// Start exports queued events in the background until ctx is cancelled; the
+// remaining events are flushed before the sink is closed.
+func (e *Exporter) Start(ctx context.Context) {
+	go e.run(ctx)
+}
+
+// Wait blocks until the exporter has stopped after its context was cancelled.
+func (e *Exporter) Wait() {
+	<-e.done
+}
+
+func (e *Exporter) run(ctx context.Context) {
+	defer close(e.done)
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, e.batchSize)
+	flush := func(ctx context.Context) {
+		if len(batch) > 0 {
+			e.write(ctx, batch)
+			batch = batch[:0]
+		}
+	}
+	for {
+		select {
+		case event := <-e.events:
+			batch = append(batch, event)
+			if len(batch) >= e.batchSize {
+				flush(ctx)
+			}
+		case <-ticker.C:
+			flush(ctx)
+		case <-ctx.Done():
+			// Flush what is left with a fresh deadline, since ctx is already done
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+		drain:
+			for {
+				select {
+				case event := <-e.events:
+					batch = append(batch, event)
+					if len(batch) >= e.batchSize {
+						flush(shutdownCtx)
+					}
+				default:
+					break drain
+				}
+			}
+			flush(shutdownCtx)
+			if err := e.sink.Close(); err != nil {
+				slog.Warn("failed to close siem sink", "error", err)
+			}
+			return
+		}
+	}
+}
+
+// write formats and delivers a batch, retrying with backoff before dropping it.
+func (e *Exporter) write(ctx context.Context, batch []Event) {
+	lines := make([][]byte, 0, len(batch))
+	for _, event := range batch {
+		line, err := e.formatter.Format(event)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to format siem event", "error", err, "type", event.Type)
+			droppedEvents.WithLabelValues("format_error").Inc()
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	backoff := 500 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		err := e.sink.Write(ctx, lines)
+		if err == nil {
+			exportedEvents.Add(float64(len(lines)))
+			return
+		}
+		if attempt == maxAttempts {
+			slog.ErrorContext(ctx, "failed to export siem events", "error", err, "count", len(lines))
+			droppedEvents.WithLabelValues("sink_error").Add(float64(len(lines)))
+			return
+		}
+		select {
+		case <-ctx.Done():
+			droppedEvents.WithLabelValues("sink_error").Add(float64(len(lines)))
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
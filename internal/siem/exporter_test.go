@@ -0,0 +1,105 @@
+package siem
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+)
+
+type recordingSink struct {
+	mu       sync.Mutex
+	batches  [][][]byte
+	failures int
+	closed   bool
+}
+
+func (s *recordingSink) Write(_ context.Context, lines [][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("collector unavailable")
+	}
+	s.batches = append(s.batches, lines)
+	return nil
+}
+
+func (s *recordingSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *recordingSink) lines() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, batch := range s.batches {
+		n += len(batch)
+	}
+	return n
+}
+
+func TestExporterBatchesAndFlushesOnShutdown(t *testing.T) {
+	sink := &recordingSink{}
+	exporter := newExporter(configs.SIEMConfig{
+		EventTypes:    []string{"login.*"},
+		BufferSize:    10,
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	}, jsonFormatter{}, sink)
+	ctx, cancel := context.WithCancel(context.Background())
+	exporter.Start(ctx)
+
+	for _, eventType := range []string{"login.failed", "role.changed", "login.succeeded"} {
+		exporter.Publish(Event{Type: eventType})
+	}
+	exporter.Publish(Event{Type: "login.failed"})
+	exporter.Publish(Event{Type: "login.failed"})
+	cancel()
+	exporter.Wait()
+
+	if got := sink.lines(); got != 4 {
+		t.Errorf("Expected the 4 login events to be exported, got %d", got)
+	}
+	if !sink.closed {
+		t.Error("Expected the sink to be closed on shutdown")
+	}
+}
+
+func TestExporterDropsWhenBufferIsFull(t *testing.T) {
+	sink := &recordingSink{}
+	exporter := newExporter(configs.SIEMConfig{
+		BufferSize:    2,
+		BatchSize:     10,
+		FlushInterval: time.Hour,
+	}, jsonFormatter{}, sink)
+
+	// Not started yet, so nothing drains the buffer
+	for range 5 {
+		exporter.Publish(Event{Type: "login.failed"})
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	exporter.Start(ctx)
+	cancel()
+	exporter.Wait()
+
+	if got := sink.lines(); got != 2 {
+		t.Errorf("Expected only the buffered events to be exported, got %d", got)
+	}
+}
+
+func TestExporterRetriesFailedWrites(t *testing.T) {
+	sink := &recordingSink{failures: 1}
+	exporter := newExporter(configs.SIEMConfig{BufferSize: 1, BatchSize: 1}, jsonFormatter{}, sink)
+
+	exporter.write(context.Background(), []Event{{Type: "login.failed"}})
+	if got := sink.lines(); got != 1 {
+		t.Errorf("Expected the batch to be delivered on retry, got %d lines", got)
+	}
+}
@@ -0,0 +1,88 @@
+package siem
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+)
+
+func testEvent() Event {
+	userID := "user-1"
+	return EventFromAudit(&model.AuditEventModel{
+		ID:        "event-1",
+		CreatedAt: time.UnixMilli(1700000000000),
+		Type:      model.AuditEventLoginFailed,
+		UserID:    &userID,
+		IPAddress: "10.0.0.1",
+		UserAgent: "agent|with=specials\\",
+		Metadata:  `{"reason":"invalid_password"}`,
+	})
+}
+
+func TestJSONFormat(t *testing.T) {
+	line, err := jsonFormatter{}.Format(testEvent())
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(line, &decoded); err != nil {
+		t.Fatalf("Expected valid JSON, got %s", line)
+	}
+	if decoded["type"] != "login.failed" || decoded["user_id"] != "user-1" {
+		t.Errorf("Unexpected event %s", line)
+	}
+	metadata, ok := decoded["metadata"].(map[string]any)
+	if !ok || metadata["reason"] != "invalid_password" {
+		t.Errorf("Expected metadata to be embedded as an object, got %s", line)
+	}
+}
+
+func TestCEFFormat(t *testing.T) {
+	line, err := cefFormatter{}.Format(testEvent())
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	got := string(line)
+	prefix := "CEF:0|poly-workshop|auth-portal|1.0|login.failed|login.failed|5|"
+	if !strings.HasPrefix(got, prefix) {
+		t.Errorf("Expected header %q, got %q", prefix, got)
+	}
+	for _, want := range []string{
+		"rt=1700000000000",
+		"suser=user-1",
+		"src=10.0.0.1",
+		`requestClientApplication=agent|with\=specials\\`,
+		`cs2Label=metadata cs2={"reason":"invalid_password"}`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in %q", want, got)
+		}
+	}
+	if strings.Contains(got, "cs1Label") {
+		t.Errorf("Expected empty pseudonym to be left out, got %q", got)
+	}
+}
+
+func TestMatchEventType(t *testing.T) {
+	tests := []struct {
+		patterns  []string
+		eventType string
+		want      bool
+	}{
+		{nil, "login.failed", true},
+		{[]string{"login.failed"}, "login.failed", true},
+		{[]string{"login.failed"}, "login.succeeded", false},
+		{[]string{"login.*"}, "login.succeeded", true},
+		{[]string{"login.*"}, "role.changed", false},
+	}
+	for _, tt := range tests {
+		if got := matchEventType(tt.patterns, tt.eventType); got != tt.want {
+			t.Errorf("matchEventType(%v, %s) = %v, want %v", tt.patterns, tt.eventType, got, tt.want)
+		}
+	}
+}
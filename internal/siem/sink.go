@@ -0,0 +1,169 @@
+package siem
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+)
+
+const (
+	SinkFile   = "file"
+	SinkSyslog = "syslog"
+	SinkHTTP   = "http"
+)
+
+// Sink delivers batches of formatted events.
+type Sink interface {
+	Write(ctx context.Context, lines [][]byte) error
+	Close() error
+}
+
+// NewSink creates the sink selected by the configuration.
+func NewSink(cfg configs.SIEMConfig, contentType string) (Sink, error) {
+	switch cfg.Sink {
+	case SinkFile:
+		if cfg.FilePath == "" {
+			return nil, fmt.Errorf("siem file sink requires a file path")
+		}
+		file, err := os.OpenFile(cfg.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open siem file: %w", err)
+		}
+		return &fileSink{file: file}, nil
+	case SinkSyslog:
+		if cfg.SyslogAddress == "" {
+			return nil, fmt.Errorf("siem syslog sink requires an address")
+		}
+		network := cfg.SyslogNetwork
+		if network == "" {
+			network = "udp"
+		}
+		hostname, _ := os.Hostname()
+		return &syslogSink{network: network, address: cfg.SyslogAddress, hostname: hostname}, nil
+	case SinkHTTP:
+		if cfg.HTTPURL == "" {
+			return nil, fmt.Errorf("siem http sink requires a url")
+		}
+		return &httpSink{
+			url:           cfg.HTTPURL,
+			authorization: cfg.HTTPAuthorization,
+			contentType:   contentType,
+			client:        &http.Client{Timeout: 10 * time.Second},
+		}, nil
+	default:
+		return nil, fmt.Errorf("siem sink %s not supported", cfg.Sink)
+	}
+}
+
+// fileSink appends one event per line to a local file, e.g. for a log shipper.
+type fileSink struct {
+	file *os.File
+}
+
+func (s *fileSink) Write(_ context.Context, lines [][]byte) error {
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	_, err := s.file.Write(buf.Bytes())
+	return err
+}
+
+func (s *fileSink) Close() error {
+	return s.file.Close()
+}
+
+// syslogPriority is facility authpriv (10) with severity notice (5).
+const syslogPriority = 10*8 + 5
+
+// syslogSink sends every event as an RFC 5424 message, reconnecting after errors.
+type syslogSink struct {
+	network  string
+	address  string
+	hostname string
+	mu       sync.Mutex
+	conn     net.Conn
+}
+
+func (s *syslogSink) Write(ctx context.Context, lines [][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, s.network, s.address)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog: %w", err)
+		}
+		s.conn = conn
+	}
+	for _, line := range lines {
+		msg := fmt.Sprintf(
+			"<%d>1 %s %s auth-portal - - - %s\n",
+			syslogPriority,
+			time.Now().UTC().Format(time.RFC3339Nano),
+			s.hostname,
+			line,
+		)
+		if _, err := s.conn.Write([]byte(msg)); err != nil {
+			_ = s.conn.Close()
+			s.conn = nil
+			return fmt.Errorf("failed to write to syslog: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *syslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// httpSink posts batches to a collector, one event per line (e.g. the raw
+// endpoint of a Splunk HTTP Event Collector).
+type httpSink struct {
+	url           string
+	authorization string
+	contentType   string
+	client        *http.Client
+}
+
+func (s *httpSink) Write(ctx context.Context, lines [][]byte) error {
+	body := bytes.Join(lines, []byte("\n"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", s.contentType)
+	if s.authorization != "" {
+		req.Header.Set("Authorization", s.authorization)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("siem collector responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *httpSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,303 @@
+// Package slo measures the indicators of the login SLOs: the success rate of
+// logins, the latency of token issuance and the error rate of OAuth code
+// exchanges. They are exported as Prometheus metrics, with the request ID as
+// exemplar, and counted by the hour in Redis, so the last 24 hours can be
+// summarized across servers and restarts.
+package slo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/logctx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Outcome classifies an event of an SLI. Only errors count against the
+// objective: rejections are the answer to the request, e.g. a wrong password.
+type Outcome string
+
+const (
+	OutcomeSuccess  Outcome = "success"
+	OutcomeRejected Outcome = "rejected"
+	OutcomeError    Outcome = "error"
+)
+
+// tokenBuckets are the upper bounds of the token issuance latency histogram,
+// in seconds.
+var tokenBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5}
+
+var (
+	loginAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_login_attempts_total",
+		Help: "Logins by method and outcome (success, rejected or error).",
+	}, []string{"method", "outcome"})
+	tokenIssuanceSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "auth_token_issuance_seconds",
+		Help:    "Time spent issuing access tokens for sessions.",
+		Buckets: tokenBuckets,
+	})
+	oauthExchanges = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_oauth_exchanges_total",
+		Help: "OAuth code exchanges by provider and outcome (success, rejected or error).",
+	}, []string{"provider", "outcome"})
+)
+
+const (
+	hourFormat = "2006-01-02T15"
+	// The counters outlive the summarized window a little
+	hourRetention = 26 * time.Hour
+	// Window is the time summarized, by the hour
+	Window = 24 * time.Hour
+
+	fieldTokenSlow = "token:slow"
+)
+
+// Recorder records the events of the SLIs. A nil Recorder, or one without
+// Redis, only updates the metrics.
+type Recorder struct {
+	rdb redis.UniversalClient
+	cfg configs.SLOConfig
+	now func() time.Time
+}
+
+func NewRecorder(rdb redis.UniversalClient, cfg configs.SLOConfig) *Recorder {
+	return &Recorder{rdb: rdb, cfg: cfg, now: time.Now}
+}
+
+func hourKey(hour time.Time) string {
+	return "slo:hour:" + hour.Format(hourFormat)
+}
+
+func loginField(outcome Outcome) string {
+	return "login:" + string(outcome)
+}
+
+func exchangeField(outcome Outcome) string {
+	return "exchange:" + string(outcome)
+}
+
+func tokenField(bucket int) string {
+	if bucket == len(tokenBuckets) {
+		return "token:le:+Inf"
+	}
+	return "token:le:" + strconv.FormatFloat(tokenBuckets[bucket], 'g', -1, 64)
+}
+
+// LoginOutcome classifies the error of a login RPC by its status code.
+func LoginOutcome(err error) Outcome {
+	switch status.Code(err) {
+	case codes.OK:
+		return OutcomeSuccess
+	case codes.Internal, codes.Unknown, codes.Unavailable, codes.DeadlineExceeded,
+		codes.DataLoss, codes.Unimplemented:
+		return OutcomeError
+	default:
+		return OutcomeRejected
+	}
+}
+
+// ExchangeOutcome classifies the error of an OAuth code exchange: the provider
+// refusing the code (4xx) is a rejection, anything else an error.
+func ExchangeOutcome(err error) Outcome {
+	var retrieveErr *oauth2.RetrieveError
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.As(err, &retrieveErr) && retrieveErr.Response != nil &&
+		retrieveErr.Response.StatusCode < http.StatusInternalServerError:
+		return OutcomeRejected
+	default:
+		return OutcomeError
+	}
+}
+
+// Login records a login by method that ended with err.
+func (r *Recorder) Login(ctx context.Context, method string, err error) {
+	outcome := LoginOutcome(err)
+	add(ctx, loginAttempts.WithLabelValues(method, string(outcome)))
+	r.count(ctx, loginField(outcome))
+}
+
+// TokenIssued records a token issued in elapsed.
+func (r *Recorder) TokenIssued(ctx context.Context, elapsed time.Duration) {
+	seconds := elapsed.Seconds()
+	if labels := exemplar(ctx); labels != nil {
+		tokenIssuanceSeconds.(prometheus.ExemplarObserver).ObserveWithExemplar(seconds, labels)
+	} else {
+		tokenIssuanceSeconds.Observe(seconds)
+	}
+	bucket := 0
+	for bucket < len(tokenBuckets) && seconds > tokenBuckets[bucket] {
+		bucket++
+	}
+	fields := []string{tokenField(bucket)}
+	if r != nil && r.cfg.TokenIssuanceP99 > 0 && elapsed > r.cfg.TokenIssuanceP99 {
+		fields = append(fields, fieldTokenSlow)
+	}
+	r.count(ctx, fields...)
+}
+
+// OAuthExchange records a code exchange with provider that ended with err.
+func (r *Recorder) OAuthExchange(ctx context.Context, provider string, err error) {
+	outcome := ExchangeOutcome(err)
+	add(ctx, oauthExchanges.WithLabelValues(provider, string(outcome)))
+	r.count(ctx, exchangeField(outcome))
+}
+
+// count adds one to fields in the counters of the current hour. Failures are
+// logged only: the SLIs must not fail the logins they measure.
+func (r *Recorder) count(ctx context.Context, fields ...string) {
+	if r == nil || r.rdb == nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	key := hourKey(r.now().UTC())
+	pipe := r.rdb.TxPipeline()
+	for _, field := range fields {
+		pipe.HIncrBy(ctx, key, field, 1)
+	}
+	pipe.Expire(ctx, key, hourRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		slog.WarnContext(ctx, "failed to count SLI event", "error", err)
+	}
+}
+
+func add(ctx context.Context, counter prometheus.Counter) {
+	if labels := exemplar(ctx); labels != nil {
+		counter.(prometheus.ExemplarAdder).AddWithExemplar(1, labels)
+		return
+	}
+	counter.Inc()
+}
+
+// exemplar links an observation to the logs of its request. Request IDs
+// Prometheus would refuse as exemplar labels, for which it panics, are left
+// out.
+func exemplar(ctx context.Context) prometheus.Labels {
+	const name = "request_id"
+	requestID := logctx.RequestID(ctx)
+	if requestID == "" || !utf8.ValidString(requestID) ||
+		utf8.RuneCountInString(name+requestID) > prometheus.ExemplarMaxRunes {
+		return nil
+	}
+	return prometheus.Labels{name: requestID}
+}
+
+// Indicator is an SLI over the summarized window.
+type Indicator struct {
+	// Value is the share of good events for ratios, the 99th percentile in
+	// seconds for latencies; the bound of its bucket, the largest bound if it
+	// is beyond
+	Value float64
+	// Target is the objective of Value
+	Target float64
+	// Total events and those that failed the objective; rejections are not
+	// counted
+	Total int64
+	Bad   int64
+	// BudgetRemaining is the share of the error budget left, negative once
+	// it is exhausted
+	BudgetRemaining float64
+}
+
+// Summary are the SLIs of the window from Start to End.
+type Summary struct {
+	Start, End           time.Time
+	LoginSuccess         Indicator
+	TokenIssuance        Indicator
+	OAuthExchangeSuccess Indicator
+}
+
+// Summary returns the SLIs of the last 24 hours, by the hour: the current one
+// and the 23 before.
+func (r *Recorder) Summary(ctx context.Context) (*Summary, error) {
+	now := r.now().UTC()
+	end := now.Truncate(time.Hour)
+	hours := int(Window / time.Hour)
+	pipe := r.rdb.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, hours)
+	for i := range hours {
+		cmds[i] = pipe.HGetAll(ctx, hourKey(end.Add(-time.Duration(i)*time.Hour)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to read SLI counters: %w", err)
+	}
+	counts := make(map[string]int64)
+	for _, cmd := range cmds {
+		for field, value := range cmd.Val() {
+			n, _ := strconv.ParseInt(value, 10, 64)
+			counts[field] += n
+		}
+	}
+
+	summary := &Summary{
+		Start: end.Add(-Window + time.Hour),
+		End:   now,
+		LoginSuccess: ratio(
+			counts[loginField(OutcomeSuccess)],
+			counts[loginField(OutcomeError)],
+			r.cfg.LoginSuccess,
+		),
+		OAuthExchangeSuccess: ratio(
+			counts[exchangeField(OutcomeSuccess)],
+			counts[exchangeField(OutcomeError)],
+			r.cfg.OAuthExchangeSuccess,
+		),
+	}
+
+	var issued int64
+	for bucket := range len(tokenBuckets) + 1 {
+		issued += counts[tokenField(bucket)]
+	}
+	latency := Indicator{
+		Target: r.cfg.TokenIssuanceP99.Seconds(),
+		Total:  issued,
+		Bad:    counts[fieldTokenSlow],
+	}
+	var seen int64
+	for bucket := range len(tokenBuckets) + 1 {
+		seen += counts[tokenField(bucket)]
+		if issued > 0 && float64(seen) >= 0.99*float64(issued) {
+			latency.Value = tokenBuckets[min(bucket, len(tokenBuckets)-1)]
+			break
+		}
+	}
+	latency.BudgetRemaining = budgetRemaining(latency.Total, latency.Bad, 0.99)
+	summary.TokenIssuance = latency
+	return summary, nil
+}
+
+func ratio(good, bad int64, target float64) Indicator {
+	indicator := Indicator{Value: 1, Target: target, Total: good + bad, Bad: bad}
+	if indicator.Total > 0 {
+		indicator.Value = float64(good) / float64(indicator.Total)
+	}
+	indicator.BudgetRemaining = budgetRemaining(indicator.Total, bad, target)
+	return indicator
+}
+
+// budgetRemaining returns the share of the error budget of total events left
+// after bad ones, the budget being the events the target allows to fail.
+func budgetRemaining(total, bad int64, target float64) float64 {
+	budget := float64(total) * (1 - target)
+	if budget <= 0 {
+		if bad > 0 {
+			return -1
+		}
+		return 1
+	}
+	return 1 - float64(bad)/budget
+}
@@ -0,0 +1,103 @@
+package slo
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/logctx"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestOutcomes(t *testing.T) {
+	for err, want := range map[error]Outcome{
+		nil: OutcomeSuccess,
+		status.Error(codes.Unauthenticated, "wrong password"): OutcomeRejected,
+		status.Error(codes.Unavailable, "provider is down"):   OutcomeError,
+		errors.New("not a status"):                            OutcomeError,
+	} {
+		if got := LoginOutcome(err); got != want {
+			t.Errorf("LoginOutcome(%v) = %s, want %s", err, got, want)
+		}
+	}
+
+	refused := &oauth2.RetrieveError{Response: &http.Response{StatusCode: http.StatusBadRequest}}
+	failed := &oauth2.RetrieveError{Response: &http.Response{StatusCode: http.StatusBadGateway}}
+	for err, want := range map[error]Outcome{
+		nil:                      OutcomeSuccess,
+		refused:                  OutcomeRejected,
+		failed:                   OutcomeError,
+		context.DeadlineExceeded: OutcomeError,
+	} {
+		if got := ExchangeOutcome(err); got != want {
+			t.Errorf("ExchangeOutcome(%v) = %s, want %s", err, got, want)
+		}
+	}
+}
+
+func TestSummary(t *testing.T) {
+	rdb, _ := testutil.NewRedis(t)
+	recorder := NewRecorder(rdb, configs.SLOConfig{
+		LoginSuccess:         0.9,
+		TokenIssuanceP99:     100 * time.Millisecond,
+		OAuthExchangeSuccess: 0.99,
+	})
+	now := time.Date(2026, 10, 16, 12, 30, 0, 0, time.UTC)
+	recorder.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// Outside of the window
+	recorder.now = func() time.Time { return now.Add(-Window) }
+	recorder.Login(ctx, "password", status.Error(codes.Internal, "database is down"))
+	recorder.now = func() time.Time { return now.Add(-23 * time.Hour) }
+	for range 18 {
+		recorder.Login(ctx, "oauth", nil)
+	}
+	recorder.now = func() time.Time { return now }
+	recorder.Login(ctx, "password", status.Error(codes.Unauthenticated, "wrong password"))
+	recorder.Login(ctx, "password", status.Error(codes.Internal, "database is down"))
+	recorder.Login(ctx, "password", nil)
+	for range 99 {
+		recorder.TokenIssued(ctx, 3*time.Millisecond)
+	}
+	recorder.TokenIssued(ctx, time.Second)
+	recorder.OAuthExchange(ctx, "github", nil)
+
+	summary, err := recorder.Summary(ctx)
+	if err != nil {
+		t.Fatalf("Summary failed: %v", err)
+	}
+	if !summary.Start.Equal(now.Truncate(time.Hour).Add(-23 * time.Hour)) {
+		t.Errorf("unexpected window start %v", summary.Start)
+	}
+	login := summary.LoginSuccess
+	if login.Total != 20 || login.Bad != 1 || login.Value != 0.95 ||
+		math.Abs(login.BudgetRemaining-0.5) > 1e-9 {
+		t.Errorf("unexpected login SLI %+v", login)
+	}
+	token := summary.TokenIssuance
+	if token.Total != 100 || token.Bad != 1 || token.Value != .005 ||
+		math.Abs(token.BudgetRemaining) > 1e-9 {
+		t.Errorf("unexpected token issuance SLI %+v", token)
+	}
+	exchange := summary.OAuthExchangeSuccess
+	if exchange.Total != 1 || exchange.Value != 1 || exchange.BudgetRemaining != 1 {
+		t.Errorf("unexpected OAuth exchange SLI %+v", exchange)
+	}
+}
+
+func TestLongRequestIDExemplar(t *testing.T) {
+	ctx := logctx.WithRequestID(context.Background(), strings.Repeat("x", 200))
+	// Prometheus panics on exemplars it refuses
+	NewRecorder(nil, configs.SLOConfig{}).Login(ctx, "password", nil)
+	ctx = logctx.WithRequestID(context.Background(), "req\xff")
+	NewRecorder(nil, configs.SLOConfig{}).TokenIssued(ctx, time.Millisecond)
+}
@@ -0,0 +1,197 @@
+package testutil
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+)
+
+// AuditRepository records audit events in memory, chained like the gorm
+// repository does.
+type AuditRepository struct {
+	mu     sync.Mutex
+	events []*model.AuditEventModel
+	head   model.AuditChainHeadModel
+}
+
+var _ repository.AuditRepository = (*AuditRepository)(nil)
+
+func NewAuditRepository() *AuditRepository {
+	return &AuditRepository{}
+}
+
+func (r *AuditRepository) Create(_ context.Context, event *model.AuditEventModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event.Seal(r.head.Sequence, r.head.Hash)
+	r.head.Sequence, r.head.Hash = event.Sequence, event.Hash
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *AuditRepository) AnonymizeByUserID(
+	_ context.Context,
+	userID, pseudonym string,
+) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for _, event := range r.events {
+		if event.UserID != nil && *event.UserID == userID {
+			event.UserID = nil
+			event.Pseudonym = &pseudonym
+			scrub(event)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *AuditRepository) ScrubBefore(_ context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for _, event := range r.events {
+		if event.CreatedAt.Before(before) &&
+			(event.IPAddress != "" || event.UserAgent != "" || event.Metadata != "") {
+			scrub(event)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *AuditRepository) DeleteBefore(_ context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.events[:0]
+	for _, event := range r.events {
+		if !event.CreatedAt.Before(before) {
+			kept = append(kept, event)
+		}
+	}
+	n := int64(len(r.events) - len(kept))
+	r.events = kept
+	return n, nil
+}
+
+func (r *AuditRepository) DetachBefore(
+	_ context.Context,
+	types []model.AuditEventType,
+	before time.Time,
+) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for _, event := range r.events {
+		if event.CreatedAt.Before(before) && slices.Contains(types, event.Type) &&
+			(event.UserID != nil || event.IPAddress != "" || event.UserAgent != "" ||
+				event.Metadata != "") {
+			event.UserID = nil
+			scrub(event)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *AuditRepository) ListAfter(
+	_ context.Context,
+	filter repository.AuditFilter,
+	after *model.AuditEventModel,
+	limit int,
+) ([]*model.AuditEventModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Events are kept in the order they were created
+	started := after == nil
+	var events []*model.AuditEventModel
+	for _, event := range r.events {
+		if !started {
+			started = event.ID == after.ID
+			continue
+		}
+		if len(events) == limit {
+			break
+		}
+		if filter.Matches(event) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *AuditRepository) CountByType(
+	_ context.Context,
+	filter repository.AuditFilter,
+) (map[model.AuditEventType]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := make(map[model.AuditEventType]int64)
+	for _, event := range r.events {
+		if filter.Matches(event) {
+			counts[event.Type]++
+		}
+	}
+	return counts, nil
+}
+
+func (r *AuditRepository) ListChain(
+	_ context.Context,
+	after int64,
+	limit int,
+) ([]*model.AuditEventModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []*model.AuditEventModel
+	for _, event := range r.events {
+		if event.Sequence > after && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func scrub(event *model.AuditEventModel) {
+	event.IPAddress = ""
+	event.UserAgent = ""
+	event.Metadata = ""
+	event.Scrubbed = true
+}
+
+// Events returns the recorded events in the order they were created.
+func (r *AuditRepository) Events() []*model.AuditEventModel {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*model.AuditEventModel(nil), r.events...)
+}
+
+// Remove deletes the event of the sequence, as tampering with the audit
+// history would.
+func (r *AuditRepository) Remove(sequence int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, event := range r.events {
+		if event.Sequence == sequence {
+			r.events = append(r.events[:i], r.events[i+1:]...)
+			return
+		}
+	}
+}
+
+// Count returns how many events of the type were recorded.
+func (r *AuditRepository) Count(eventType model.AuditEventType) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, event := range r.events {
+		if event.Type == eventType {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,4 @@
+// Package testutil provides in-memory and miniredis-backed doubles of the
+// repositories and OAuth providers, so service-level tests run without a
+// database, Redis server or network access.
+package testutil
@@ -0,0 +1,53 @@
+package testutil
+
+import (
+	"context"
+	"sync"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+)
+
+// NotificationPreferencesRepository keeps notification preferences in memory.
+type NotificationPreferencesRepository struct {
+	mu    sync.Mutex
+	prefs map[string]model.NotificationPreferencesModel
+}
+
+var _ repository.NotificationPreferencesRepository = (*NotificationPreferencesRepository)(nil)
+
+func NewNotificationPreferencesRepository() *NotificationPreferencesRepository {
+	return &NotificationPreferencesRepository{
+		prefs: make(map[string]model.NotificationPreferencesModel),
+	}
+}
+
+func (r *NotificationPreferencesRepository) Get(
+	_ context.Context,
+	userID string,
+) (*model.NotificationPreferencesModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prefs, ok := r.prefs[userID]
+	if !ok {
+		return model.DefaultNotificationPreferences(userID), nil
+	}
+	return &prefs, nil
+}
+
+func (r *NotificationPreferencesRepository) Save(
+	_ context.Context,
+	prefs *model.NotificationPreferencesModel,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prefs[prefs.UserID] = *prefs
+	return nil
+}
+
+func (r *NotificationPreferencesRepository) Delete(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.prefs, userID)
+	return nil
+}
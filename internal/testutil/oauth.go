@@ -0,0 +1,171 @@
+package testutil
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/internal/provider"
+	"golang.org/x/oauth2"
+)
+
+// OAuthClientID is the client ID the fake provider expects.
+const OAuthClientID = "test-client"
+
+var ErrUnknownAccessToken = errors.New("unknown access token")
+
+// OAuthProvider fakes an OAuth provider: an authorization and a token endpoint
+// served by an httptest server, and a provider.UserProvider resolving the
+// access tokens issued by it. Refresh tokens are rotated on every use.
+type OAuthProvider struct {
+	Server *httptest.Server
+
+	mu      sync.Mutex
+	user    *provider.UserInfo
+	codes   map[string]provider.UserInfo
+	tokens  map[string]provider.UserInfo
+	refresh map[string]provider.UserInfo
+}
+
+var _ provider.UserProvider = (*OAuthProvider)(nil)
+
+// NewOAuthProvider starts a fake provider that is shut down when the test finishes.
+func NewOAuthProvider(t testing.TB) *OAuthProvider {
+	t.Helper()
+	p := &OAuthProvider{
+		codes:   make(map[string]provider.UserInfo),
+		tokens:  make(map[string]provider.UserInfo),
+		refresh: make(map[string]provider.UserInfo),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Server.Close)
+	return p
+}
+
+// Config returns an OAuth config pointing at the fake provider.
+func (p *OAuthProvider) Config(redirectURL string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     OAuthClientID,
+		ClientSecret: "test-secret",
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   p.Server.URL + "/authorize",
+			TokenURL:  p.Server.URL + "/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		RedirectURL: redirectURL,
+		Scopes:      []string{"user:email"},
+	}
+}
+
+// SetUser sets the user who logs in at the authorization endpoint.
+func (p *OAuthProvider) SetUser(user provider.UserInfo) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.user = &user
+}
+
+// IssueCode returns an authorization code for the user, as if they had logged
+// in at the authorization endpoint. Codes can be exchanged once.
+func (p *OAuthProvider) IssueCode(user provider.UserInfo) string {
+	code := randomString()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.codes[code] = user
+	return code
+}
+
+// GetUserInfo resolves an access token issued by the token endpoint.
+func (p *OAuthProvider) GetUserInfo(_ context.Context, token string) (provider.UserInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	user, ok := p.tokens[token]
+	if !ok {
+		return provider.UserInfo{}, ErrUnknownAccessToken
+	}
+	return user, nil
+}
+
+// authorize logs in the user set with SetUser and redirects back with a code.
+func (p *OAuthProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURL, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || query.Get("client_id") != OAuthClientID {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+	p.mu.Lock()
+	user := p.user
+	p.mu.Unlock()
+	if user == nil {
+		http.Error(w, "no user logged in", http.StatusUnauthorized)
+		return
+	}
+
+	params := redirectURL.Query()
+	params.Set("code", p.IssueCode(*user))
+	params.Set("state", query.Get("state"))
+	redirectURL.RawQuery = params.Encode()
+	http.Redirect(w, r, redirectURL.String(), http.StatusFound)
+}
+
+// token exchanges an authorization code or a refresh token for an access token.
+func (p *OAuthProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("client_id") != OAuthClientID {
+		writeOAuthError(w, "invalid_client")
+		return
+	}
+
+	p.mu.Lock()
+	var user provider.UserInfo
+	var ok bool
+	if r.PostForm.Get("grant_type") == "refresh_token" {
+		refreshToken := r.PostForm.Get("refresh_token")
+		user, ok = p.refresh[refreshToken]
+		delete(p.refresh, refreshToken)
+	} else {
+		code := r.PostForm.Get("code")
+		user, ok = p.codes[code]
+		delete(p.codes, code)
+	}
+	token, refreshToken := randomString(), randomString()
+	if ok {
+		p.tokens[token] = user
+		p.refresh[refreshToken] = user
+	}
+	p.mu.Unlock()
+	if !ok {
+		writeOAuthError(w, "invalid_grant")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"access_token":  token,
+		"refresh_token": refreshToken,
+		"token_type":    "bearer",
+		"expires_in":    3600,
+		"scope":         "user:email",
+	})
+}
+
+func writeOAuthError(w http.ResponseWriter, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
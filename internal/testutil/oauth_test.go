@@ -0,0 +1,45 @@
+package testutil
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/internal/provider"
+)
+
+func TestOAuthProviderAuthorizationCodeFlow(t *testing.T) {
+	p := NewOAuthProvider(t)
+	user := provider.UserInfo{ID: "1", Email: "user@example.com"}
+	p.SetUser(user)
+	cfg := p.Config("https://portal.example.com/callback")
+	ctx := context.Background()
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	resp, err := client.Get(cfg.AuthCodeURL("state-1"))
+	if err != nil {
+		t.Fatalf("authorize failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || location.Query().Get("state") != "state-1" {
+		t.Fatalf("unexpected redirect %q", resp.Header.Get("Location"))
+	}
+
+	code := location.Query().Get("code")
+	token, err := cfg.Exchange(ctx, code)
+	if err != nil {
+		t.Fatalf("Exchange failed: %v", err)
+	}
+	got, err := p.GetUserInfo(ctx, token.AccessToken)
+	if err != nil || got != user {
+		t.Fatalf("expected %v, got %v (%v)", user, got, err)
+	}
+
+	if _, err := cfg.Exchange(ctx, code); err == nil {
+		t.Error("expected codes to be single-use")
+	}
+}
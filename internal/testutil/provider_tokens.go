@@ -0,0 +1,73 @@
+package testutil
+
+import (
+	"context"
+	"sync"
+
+	"github.com/poly-workshop/auth-portal/internal/repository"
+)
+
+// ProviderTokenRepository keeps provider tokens in memory, unencrypted.
+type ProviderTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[[2]string]repository.ProviderToken
+}
+
+var _ repository.ProviderTokenRepository = (*ProviderTokenRepository)(nil)
+
+func NewProviderTokenRepository() *ProviderTokenRepository {
+	return &ProviderTokenRepository{tokens: make(map[[2]string]repository.ProviderToken)}
+}
+
+func (r *ProviderTokenRepository) Save(_ context.Context, token *repository.ProviderToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[[2]string{token.UserID, token.Provider}] = *token
+	return nil
+}
+
+func (r *ProviderTokenRepository) Get(
+	_ context.Context,
+	userID, provider string,
+) (*repository.ProviderToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[[2]string{userID, provider}]
+	if !ok {
+		return nil, repository.ErrProviderTokenNotFound
+	}
+	return &token, nil
+}
+
+func (r *ProviderTokenRepository) Delete(_ context.Context, userID, provider string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tokens, [2]string{userID, provider})
+	return nil
+}
+
+func (r *ProviderTokenRepository) DeleteByUserID(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key := range r.tokens {
+		if key[0] == userID {
+			delete(r.tokens, key)
+		}
+	}
+	return nil
+}
+
+func (r *ProviderTokenRepository) DeleteIfRefreshToken(
+	_ context.Context,
+	userID, provider, refreshToken string,
+) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := [2]string{userID, provider}
+	token, ok := r.tokens[key]
+	if !ok || token.RefreshToken != refreshToken {
+		return false, nil
+	}
+	delete(r.tokens, key)
+	return true, nil
+}
@@ -0,0 +1,27 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRedis starts a miniredis server for the test and returns a client for it.
+// Both are closed when the test finishes; the server can be used to inspect
+// keys or fast-forward TTLs.
+func NewRedis(t testing.TB) (redis.UniversalClient, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return rdb, mr
+}
+
+// NewSessionRepository returns a session repository backed by a fresh miniredis server.
+func NewSessionRepository(t testing.TB) (repository.SessionRepository, *miniredis.Miniredis) {
+	t.Helper()
+	rdb, mr := NewRedis(t)
+	return repository.NewSessionRepository(rdb), mr
+}
@@ -0,0 +1,94 @@
+package testutil
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ReportRepository keeps reports in memory.
+type ReportRepository struct {
+	mu      sync.Mutex
+	reports []*model.ReportModel
+}
+
+var _ repository.ReportRepository = (*ReportRepository)(nil)
+
+func NewReportRepository() *ReportRepository {
+	return &ReportRepository{}
+}
+
+func (r *ReportRepository) Create(_ context.Context, report *model.ReportModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if report.ID == "" {
+		report.ID = uuid.New().String()
+	}
+	if report.CreatedAt.IsZero() {
+		report.CreatedAt = time.Now()
+	}
+	stored := *report
+	r.reports = append(r.reports, &stored)
+	return nil
+}
+
+func (r *ReportRepository) GetByID(_ context.Context, id string) (*model.ReportModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, report := range r.reports {
+		if report.ID == id {
+			found := *report
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *ReportRepository) List(_ context.Context, limit int) ([]*model.ReportModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var reports []*model.ReportModel
+	for _, report := range slices.Backward(r.reports) {
+		if len(reports) == limit {
+			break
+		}
+		listed := *report
+		reports = append(reports, &listed)
+	}
+	return reports, nil
+}
+
+func (r *ReportRepository) ClaimPending(context.Context) (*model.ReportModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, report := range r.reports {
+		if report.Status == model.ReportStatusPending {
+			now := time.Now()
+			report.Status = model.ReportStatusRunning
+			report.ClaimedAt = &now
+			report.Attempts++
+			claimed := *report
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *ReportRepository) Update(_ context.Context, report *model.ReportModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, stored := range r.reports {
+		if stored.ID == report.ID {
+			updated := *report
+			r.reports[i] = &updated
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
@@ -0,0 +1,76 @@
+package testutil
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+)
+
+// TenantSettingsRepository keeps tenant settings in memory.
+type TenantSettingsRepository struct {
+	mu      sync.Mutex
+	tenants map[string]model.TenantSettingsModel
+}
+
+var _ repository.TenantSettingsRepository = (*TenantSettingsRepository)(nil)
+
+func NewTenantSettingsRepository(tenants ...*model.TenantSettingsModel) *TenantSettingsRepository {
+	r := &TenantSettingsRepository{tenants: make(map[string]model.TenantSettingsModel)}
+	for _, tenant := range tenants {
+		r.tenants[tenant.Org] = *tenant
+	}
+	return r
+}
+
+func (r *TenantSettingsRepository) Get(
+	_ context.Context,
+	org string,
+) (*model.TenantSettingsModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tenant, ok := r.tenants[org]
+	if !ok {
+		return nil, nil
+	}
+	return &tenant, nil
+}
+
+func (r *TenantSettingsRepository) List(
+	_ context.Context,
+	orgs []string,
+) ([]*model.TenantSettingsModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var tenants []*model.TenantSettingsModel
+	for org, tenant := range r.tenants {
+		if orgs == nil || slices.Contains(orgs, org) {
+			tenants = append(tenants, &tenant)
+		}
+	}
+	slices.SortFunc(tenants, func(a, b *model.TenantSettingsModel) int {
+		return strings.Compare(a.Org, b.Org)
+	})
+	return tenants, nil
+}
+
+func (r *TenantSettingsRepository) Save(
+	_ context.Context,
+	tenant *model.TenantSettingsModel,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tenants[tenant.Org] = *tenant
+	return nil
+}
+
+func (r *TenantSettingsRepository) Delete(_ context.Context, org string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.tenants[org]
+	delete(r.tenants, org)
+	return ok, nil
+}
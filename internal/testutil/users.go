@@ -0,0 +1,292 @@
+package testutil
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"gorm.io/gorm"
+)
+
+// UserRepository keeps users in memory. Like the gorm repository, lookups of
+// missing users fail with gorm.ErrRecordNotFound and deleted users stay around
+// until they are purged.
+type UserRepository struct {
+	mu    sync.Mutex
+	users map[string]*model.UserModel
+}
+
+var _ repository.UserRepository = (*UserRepository)(nil)
+
+func NewUserRepository(users ...*model.UserModel) *UserRepository {
+	r := &UserRepository{users: make(map[string]*model.UserModel)}
+	for _, user := range users {
+		_ = r.Create(context.Background(), user)
+	}
+	return r
+}
+
+func (r *UserRepository) Create(_ context.Context, user *model.UserModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user.ID == "" {
+		user.ID = uuid.New().String()
+	}
+	for _, existing := range r.users {
+		if strings.EqualFold(existing.Email, user.Email) {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	user.UpdatedAt = now
+	if user.Version == 0 {
+		user.Version = 1
+	}
+	r.users[user.ID] = clone(user)
+	return nil
+}
+
+func (r *UserRepository) GetByID(_ context.Context, id string) (*model.UserModel, error) {
+	return r.find(func(user *model.UserModel) bool { return user.ID == id })
+}
+
+func (r *UserRepository) GetByIDs(_ context.Context, ids []string) ([]*model.UserModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var users []*model.UserModel
+	for _, id := range ids {
+		if user, ok := r.users[id]; ok && !user.DeletedAt.Valid {
+			users = append(users, clone(user))
+		}
+	}
+	return users, nil
+}
+
+func (r *UserRepository) GetByEmail(_ context.Context, email string) (*model.UserModel, error) {
+	return r.find(func(user *model.UserModel) bool { return user.Email == email })
+}
+
+func (r *UserRepository) GetByGithubID(
+	_ context.Context,
+	githubID string,
+) (*model.UserModel, error) {
+	return r.find(func(user *model.UserModel) bool {
+		return user.GithubID != nil && *user.GithubID == githubID
+	})
+}
+
+func (r *UserRepository) Update(_ context.Context, user *model.UserModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.update(user)
+	return nil
+}
+
+func (r *UserRepository) UpdateIfVersion(
+	_ context.Context,
+	user *model.UserModel,
+	version int64,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.users[user.ID]; ok && stored.Version != version {
+		return repository.ErrVersionConflict
+	}
+	r.update(user)
+	return nil
+}
+
+func (r *UserRepository) update(user *model.UserModel) {
+	user.UpdatedAt = time.Now()
+	if stored, ok := r.users[user.ID]; ok {
+		user.Version = stored.Version
+	}
+	user.Version++
+	r.users[user.ID] = clone(user)
+}
+
+func (r *UserRepository) TouchLastSeen(_ context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user, ok := r.users[id]; ok {
+		user.LastSeenAt = &at
+	}
+	return nil
+}
+
+func (r *UserRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user, ok := r.users[id]; ok {
+		user.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	}
+	return nil
+}
+
+func (r *UserRepository) List(
+	_ context.Context,
+	filter repository.UserFilter,
+	offset, limit int,
+) ([]*model.UserModel, error) {
+	users := r.filter(filter)
+	if offset >= len(users) {
+		return nil, nil
+	}
+	users = users[offset:]
+	if limit >= 0 && limit < len(users) {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+func (r *UserRepository) ListAfter(
+	_ context.Context,
+	filter repository.UserFilter,
+	afterID string,
+	limit int,
+) ([]*model.UserModel, error) {
+	users := r.filter(filter)
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	i := sort.Search(len(users), func(i int) bool { return users[i].ID > afterID })
+	users = users[i:]
+	if limit >= 0 && limit < len(users) {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+func (r *UserRepository) Count(_ context.Context, filter repository.UserFilter) (int64, error) {
+	return int64(len(r.filter(filter))), nil
+}
+
+func (r *UserRepository) Search(
+	_ context.Context,
+	filter repository.UserFilter,
+	prefix string,
+	limit int,
+) ([]*model.UserModel, error) {
+	prefix = strings.ToLower(prefix)
+	var users []*model.UserModel
+	for _, user := range r.filter(filter) {
+		if strings.HasPrefix(strings.ToLower(user.Name), prefix) ||
+			strings.HasPrefix(strings.ToLower(user.Email), prefix) {
+			users = append(users, user)
+		}
+	}
+	sort.SliceStable(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	if limit >= 0 && limit < len(users) {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+func (r *UserRepository) ListDeletionDue(
+	_ context.Context,
+	now time.Time,
+	limit int,
+) ([]*model.UserModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var users []*model.UserModel
+	for _, user := range r.sorted() {
+		if user.DeletionScheduledAt != nil && !user.DeletionScheduledAt.After(now) {
+			users = append(users, clone(user))
+		}
+		if len(users) == limit {
+			break
+		}
+	}
+	return users, nil
+}
+
+func (r *UserRepository) ListDeletedBefore(
+	_ context.Context,
+	before time.Time,
+	limit int,
+) ([]*model.UserModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var users []*model.UserModel
+	for _, user := range r.sorted() {
+		if user.DeletedAt.Valid && user.DeletedAt.Time.Before(before) {
+			users = append(users, clone(user))
+		}
+		if len(users) == limit {
+			break
+		}
+	}
+	return users, nil
+}
+
+func (r *UserRepository) Purge(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.users, id)
+	return nil
+}
+
+func (r *UserRepository) find(match func(*model.UserModel) bool) (*model.UserModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if !user.DeletedAt.Valid && match(user) {
+			return clone(user), nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *UserRepository) filter(filter repository.UserFilter) []*model.UserModel {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var users []*model.UserModel
+	for _, user := range r.sorted() {
+		if user.DeletedAt.Valid {
+			continue
+		}
+		if filter.PendingApproval != nil && user.PendingApproval != *filter.PendingApproval {
+			continue
+		}
+		if filter.InactiveSince != nil && !user.LastActivity().Before(*filter.InactiveSince) {
+			continue
+		}
+		if filter.Deactivated != nil && (user.DeactivatedAt != nil) != *filter.Deactivated {
+			continue
+		}
+		if filter.Orgs != nil && !slices.Contains(filter.Orgs, user.Org) {
+			continue
+		}
+		users = append(users, clone(user))
+	}
+	return users
+}
+
+// sorted returns the users in creation order, so listings are stable.
+func (r *UserRepository) sorted() []*model.UserModel {
+	users := make([]*model.UserModel, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].ID < users[j].ID
+		}
+		return users[i].CreatedAt.Before(users[j].CreatedAt)
+	})
+	return users
+}
+
+// clone copies the user so callers can't modify the stored state without Update.
+func clone(user *model.UserModel) *model.UserModel {
+	copied := *user
+	return &copied
+}
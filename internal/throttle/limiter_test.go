@@ -0,0 +1,33 @@
+package throttle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+)
+
+func TestRateLimiter(t *testing.T) {
+	rdb, mr := testutil.NewRedis(t)
+	limiter := NewRateLimiter(rdb, "test", 2, time.Hour)
+	ctx := context.Background()
+
+	for i, want := range []bool{true, true, false} {
+		allowed, err := limiter.Allow(ctx, "10.0.0.0")
+		if err != nil {
+			t.Fatalf("Allow failed: %v", err)
+		}
+		if allowed != want {
+			t.Errorf("call %d: expected allowed=%v", i+1, want)
+		}
+	}
+	if allowed, _ := limiter.Allow(ctx, "10.0.1.0"); !allowed {
+		t.Error("expected other clients to have their own limit")
+	}
+
+	mr.FastForward(time.Hour)
+	if allowed, _ := limiter.Allow(ctx, "10.0.0.0"); !allowed {
+		t.Error("expected the limit to reset once the counter expired")
+	}
+}
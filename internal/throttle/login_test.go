@@ -0,0 +1,34 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	base := time.Second
+	max := 30 * time.Second
+
+	tests := []struct {
+		name     string
+		failures int
+		expected time.Duration
+	}{
+		{"no failures", 0, 0},
+		{"within free attempts", 3, 0},
+		{"first throttled failure", 4, time.Second},
+		{"second throttled failure", 5, 2 * time.Second},
+		{"fourth throttled failure", 7, 8 * time.Second},
+		{"capped at max", 20, max},
+		{"far beyond max", 1000, max},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay := BackoffDelay(tt.failures, 3, base, max)
+			if delay != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, delay)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	enumeratedUsers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_enumerated_users_total",
+		Help: "Users read by admins through listings, by method.",
+	}, []string{"method"})
+	enumerationRejected = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "auth_enumeration_quota_exceeded_total",
+		Help: "Listings of users rejected for exceeding the daily enumeration quota.",
+	})
+	enumerationAlerts = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "auth_enumeration_alerts_total",
+		Help: "Admins crossing the daily enumeration alert threshold.",
+	})
+)
+
+// EnumerationQuota counts the users each principal reads through listings
+// per UTC day, in Redis so the counts hold across servers. The quota is soft:
+// a call is only rejected once the principal already reached it, so the call
+// reaching it returns in full.
+type EnumerationQuota struct {
+	rdb redis.UniversalClient
+	cfg configs.EnumerationConfig
+	now func() time.Time
+}
+
+func NewEnumerationQuota(
+	rdb redis.UniversalClient,
+	cfg configs.EnumerationConfig,
+) *EnumerationQuota {
+	return &EnumerationQuota{rdb: rdb, cfg: cfg, now: time.Now}
+}
+
+func enumerationKey(principal string, now time.Time) string {
+	return fmt.Sprintf("enumeration:{%s}:day:%s", principal, now.Format(dayFormat))
+}
+
+// Check fails with ResourceExhausted if principal read its daily quota of
+// users.
+func (q *EnumerationQuota) Check(ctx context.Context, principal string) error {
+	if q == nil || q.cfg.DailyUsers <= 0 {
+		return nil
+	}
+	read, err := q.rdb.Get(ctx, enumerationKey(principal, q.now().UTC())).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to read enumeration count: %w", err)
+	}
+	if read >= q.cfg.DailyUsers {
+		enumerationRejected.Inc()
+		return status.Errorf(
+			codes.ResourceExhausted,
+			"daily quota of %d listed users exhausted",
+			q.cfg.DailyUsers,
+		)
+	}
+	return nil
+}
+
+// Record counts n users principal read through method and reports whether
+// they crossed the alert threshold, which happens once per principal and day.
+func (q *EnumerationQuota) Record(
+	ctx context.Context,
+	principal, method string,
+	n int,
+) (bool, error) {
+	if q == nil || n <= 0 {
+		return false, nil
+	}
+	enumeratedUsers.WithLabelValues(method).Add(float64(n))
+	if q.cfg.DailyUsers <= 0 && q.cfg.AlertUsers <= 0 {
+		return false, nil
+	}
+	key := enumerationKey(principal, q.now().UTC())
+	pipe := q.rdb.TxPipeline()
+	read := pipe.IncrBy(ctx, key, int64(n))
+	pipe.Expire(ctx, key, dayRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, fmt.Errorf("failed to count listed users: %w", err)
+	}
+	alertAt := q.cfg.AlertUsers
+	if alertAt <= 0 || read.Val() < alertAt || read.Val()-int64(n) >= alertAt {
+		return false, nil
+	}
+	enumerationAlerts.Inc()
+	return true, nil
+}
@@ -0,0 +1,56 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestEnumerationQuota(t *testing.T) {
+	rdb, _ := testutil.NewRedis(t)
+	ctx := context.Background()
+	quota := NewEnumerationQuota(rdb, configs.EnumerationConfig{DailyUsers: 100, AlertUsers: 50})
+	now := time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)
+	quota.now = func() time.Time { return now }
+
+	var alerts []bool
+	for _, n := range []int{40, 20, 30} {
+		if err := quota.Check(ctx, "admin-1"); err != nil {
+			t.Fatalf("expected listings within the quota to pass, got %v", err)
+		}
+		alert, err := quota.Record(ctx, "admin-1", "ListUsers", n)
+		if err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+		alerts = append(alerts, alert)
+	}
+	if !alerts[1] || alerts[0] || alerts[2] {
+		t.Errorf("expected a single alert when crossing the threshold, got %v", alerts)
+	}
+	// The quota is soft: 90 users were read, so one more listing passes
+	if err := quota.Check(ctx, "admin-1"); err != nil {
+		t.Fatalf("expected a listing below the quota to pass, got %v", err)
+	}
+	_, _ = quota.Record(ctx, "admin-1", "ExportUsers", 500)
+	if err := quota.Check(ctx, "admin-1"); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected the quota to be enforced, got %v", err)
+	}
+	if err := quota.Check(ctx, "admin-2"); err != nil {
+		t.Errorf("expected other admins to have their own quota, got %v", err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if err := quota.Check(ctx, "admin-1"); err != nil {
+		t.Errorf("expected the quota to reset on the next day, got %v", err)
+	}
+
+	var disabled *EnumerationQuota
+	if err := disabled.Check(ctx, "admin-1"); err != nil {
+		t.Errorf("expected a nil quota to allow everything, got %v", err)
+	}
+}
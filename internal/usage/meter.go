@@ -0,0 +1,110 @@
+// Package usage counts the calls of internal credentials per UTC day and month
+// and enforces their quotas, so internal consumers can be budgeted, and the
+// users admins read through listings per day.
+package usage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var rejectedCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "auth_key_quota_exceeded_total",
+	Help: "Calls of internal credentials rejected for exceeding their quota, by key and window.",
+}, []string{"key", "window"})
+
+const (
+	dayFormat   = "2006-01-02"
+	monthFormat = "2006-01"
+	// The counters outlive their window a little, so the last one can still be read
+	dayRetention   = 48 * time.Hour
+	monthRetention = 32 * 24 * time.Hour
+)
+
+// Usage is the number of calls of a credential in the current windows.
+type Usage struct {
+	Day     string
+	Daily   int64
+	Month   string
+	Monthly int64
+	Quota   configs.Quota
+}
+
+// Meter counts calls in Redis, so the counts and quotas hold across servers.
+type Meter struct {
+	rdb    redis.UniversalClient
+	quotas map[string]configs.Quota
+	now    func() time.Time
+}
+
+func NewMeter(rdb redis.UniversalClient, cfg configs.UsageConfig) *Meter {
+	return &Meter{rdb: rdb, quotas: cfg.Quotas, now: time.Now}
+}
+
+// The keys of a credential share a hash tag, so they can be read together on
+// Redis Cluster.
+func dayKey(key string, now time.Time) string {
+	return fmt.Sprintf("key_usage:{%s}:day:%s", key, now.Format(dayFormat))
+}
+
+func monthKey(key string, now time.Time) string {
+	return fmt.Sprintf("key_usage:{%s}:month:%s", key, now.Format(monthFormat))
+}
+
+// Record counts a call of the credential named key and fails with
+// ResourceExhausted if that exceeds one of its quotas. Rejected calls count
+// too, so clients retrying in a loop stay rejected.
+func (m *Meter) Record(ctx context.Context, key string) error {
+	now := m.now().UTC()
+	pipe := m.rdb.TxPipeline()
+	daily := pipe.Incr(ctx, dayKey(key, now))
+	pipe.Expire(ctx, dayKey(key, now), dayRetention)
+	monthly := pipe.Incr(ctx, monthKey(key, now))
+	pipe.Expire(ctx, monthKey(key, now), monthRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to count call: %w", err)
+	}
+
+	quota := m.quotas[key]
+	switch {
+	case quota.Daily > 0 && daily.Val() > quota.Daily:
+		rejectedCalls.WithLabelValues(key, "daily").Inc()
+		return status.Errorf(codes.ResourceExhausted, "daily quota of %q exhausted", key)
+	case quota.Monthly > 0 && monthly.Val() > quota.Monthly:
+		rejectedCalls.WithLabelValues(key, "monthly").Inc()
+		return status.Errorf(codes.ResourceExhausted, "monthly quota of %q exhausted", key)
+	}
+	return nil
+}
+
+// Usage returns the calls of the credential named key in the current windows.
+func (m *Meter) Usage(ctx context.Context, key string) (*Usage, error) {
+	now := m.now().UTC()
+	counts, err := m.rdb.MGet(ctx, dayKey(key, now), monthKey(key, now)).Result()
+	if err != nil {
+		return nil, err
+	}
+	return &Usage{
+		Day:     now.Format(dayFormat),
+		Daily:   count(counts[0]),
+		Month:   now.Format(monthFormat),
+		Monthly: count(counts[1]),
+		Quota:   m.quotas[key],
+	}, nil
+}
+
+// count parses a counter read with MGET, which is nil before the first call.
+func count(value any) int64 {
+	s, _ := value.(string)
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
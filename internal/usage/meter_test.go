@@ -0,0 +1,50 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMeter(t *testing.T) {
+	rdb, _ := testutil.NewRedis(t)
+	ctx := context.Background()
+	meter := NewMeter(rdb, configs.UsageConfig{
+		Quotas: map[string]configs.Quota{"internal": {Daily: 2, Monthly: 3}},
+	})
+	now := time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)
+	meter.now = func() time.Time { return now }
+
+	for range 2 {
+		if err := meter.Record(ctx, "internal"); err != nil {
+			t.Fatalf("expected calls within the quota to pass, got %v", err)
+		}
+	}
+	if err := meter.Record(ctx, "internal"); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected the daily quota to be enforced, got %v", err)
+	}
+	// Keys without a quota are only counted
+	if err := meter.Record(ctx, "billing"); err != nil {
+		t.Errorf("expected calls of keys without a quota to pass, got %v", err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if err := meter.Record(ctx, "internal"); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected the monthly quota to hold on the next day, got %v", err)
+	}
+	usage, err := meter.Usage(ctx, "internal")
+	if err != nil {
+		t.Fatalf("Usage failed: %v", err)
+	}
+	if usage.Day != "2026-10-17" || usage.Daily != 1 || usage.Monthly != 4 {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+	if usage, _ := meter.Usage(ctx, "unused"); usage.Daily != 0 || usage.Monthly != 0 {
+		t.Errorf("expected no calls of an unused key, got %+v", usage)
+	}
+}
@@ -0,0 +1,31 @@
+package utils
+
+import "strings"
+
+// MatchEmailDomain reports whether the domain of email is one of the allowed
+// domains. An entry "*.example.com" matches any subdomain of example.com but
+// not example.com itself. An empty list allows every domain.
+func MatchEmailDomain(allowed []string, email string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+	if domain == "" {
+		return false
+	}
+	for _, entry := range allowed {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if suffix, ok := strings.CutPrefix(entry, "*."); ok {
+			if strings.HasSuffix(domain, "."+suffix) {
+				return true
+			}
+		} else if domain == entry {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package utils
+
+import "testing"
+
+func TestMatchEmailDomain(t *testing.T) {
+	allowed := []string{"company.com", "*.corp.example"}
+
+	tests := []struct {
+		email    string
+		expected bool
+	}{
+		{"alice@company.com", true},
+		{"alice@COMPANY.com", true},
+		{"alice@sub.company.com", false},
+		{"alice@evilcompany.com", false},
+		{"bob@eu.corp.example", true},
+		{"bob@a.b.corp.example", true},
+		{"bob@corp.example", false},
+		{"bob@corp.example.evil", false},
+		{"not-an-email", false},
+		{"alice@", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			if got := MatchEmailDomain(allowed, tt.email); got != tt.expected {
+				t.Errorf("Expected %v for %q, got %v", tt.expected, tt.email, got)
+			}
+		})
+	}
+
+	if !MatchEmailDomain(nil, "anyone@anywhere.org") {
+		t.Error("Expected an empty allowlist to allow every domain")
+	}
+}
@@ -0,0 +1,11 @@
+package utils
+
+import "regexp"
+
+var phoneNumberPattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// ValidPhoneNumber reports whether number is in E.164 format, e.g. "+15551234567",
+// as SMS gateways expect it.
+func ValidPhoneNumber(number string) bool {
+	return phoneNumberPattern.MatchString(number)
+}
@@ -0,0 +1,19 @@
+package utils
+
+import "testing"
+
+func TestValidPhoneNumber(t *testing.T) {
+	for number, want := range map[string]bool{
+		"+15551234567":    true,
+		"+4930123456":     true,
+		"15551234567":     false,
+		"+0551234567":     false,
+		"+1 555 123 4567": false,
+		"+1234":           false,
+		"":                false,
+	} {
+		if got := ValidPhoneNumber(number); got != want {
+			t.Errorf("ValidPhoneNumber(%q) = %v, want %v", number, got, want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"strings"
+)
+
+// pseudonymLength is the number of hex characters kept from the HMAC digest.
+const pseudonymLength = 32
+
+// Pseudonymize derives a stable, non-reversible identifier for value using
+// HMAC-SHA256. The same key and value always yield the same pseudonym, so
+// anonymized records stay correlatable without revealing who they belonged to.
+func Pseudonymize(key, value string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:pseudonymLength]
+}
+
+// MaskID hides all but the last characters of an external account ID, at
+// most 4 and at most half of them, e.g. "12345678" becomes "****5678".
+func MaskID(id string) string {
+	visible := min(4, len(id)/2)
+	return strings.Repeat("*", len(id)-visible) + id[len(id)-visible:]
+}
+
+// TruncateIP drops the host part of an IP address, keeping the /24 network
+// for IPv4 and the /48 network for IPv6. Invalid input yields an empty string.
+func TruncateIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return parsed.Mask(net.CIDRMask(48, 128)).String()
+}
@@ -0,0 +1,54 @@
+package utils
+
+import "testing"
+
+func TestPseudonymize(t *testing.T) {
+	a := Pseudonymize("key", "user-1")
+	if len(a) != pseudonymLength {
+		t.Fatalf("Expected pseudonym of length %d, got %d", pseudonymLength, len(a))
+	}
+	if a != Pseudonymize("key", "user-1") {
+		t.Error("Expected pseudonym to be stable for the same key and value")
+	}
+	if a == Pseudonymize("key", "user-2") {
+		t.Error("Expected different values to yield different pseudonyms")
+	}
+	if a == Pseudonymize("other-key", "user-1") {
+		t.Error("Expected different keys to yield different pseudonyms")
+	}
+}
+
+func TestMaskID(t *testing.T) {
+	tests := map[string]string{
+		"12345678": "****5678",
+		"4242":     "**42",
+		"7":        "*",
+		"":         "",
+	}
+	for id, expected := range tests {
+		if result := MaskID(id); result != expected {
+			t.Errorf("MaskID(%q): expected %q, got %q", id, expected, result)
+		}
+	}
+}
+
+func TestTruncateIP(t *testing.T) {
+	tests := []struct {
+		ip       string
+		expected string
+	}{
+		{"203.0.113.42", "203.0.113.0"},
+		{"2001:db8:abcd:12::1", "2001:db8:abcd::"},
+		{"::ffff:198.51.100.7", "198.51.100.0"},
+		{"not-an-ip", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if result := TruncateIP(tt.ip); result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+)
+
+// MatchRedirectURL reports whether rawURL is permitted by one of the allowed
+// entries. An entry matches either exactly or as a pattern:
+//
+//   - a host starting with "*." matches any subdomain of the rest of the host
+//     (but not the bare domain itself), e.g. "https://*.example.com/callback"
+//   - a path ending with "*" matches any path with that prefix,
+//     e.g. "https://app.example.com/auth/*"
+//
+// Scheme and port always have to match exactly. URLs carrying user info or a
+// fragment are never allowed.
+func MatchRedirectURL(allowed []string, rawURL string) bool {
+	candidate, err := url.Parse(rawURL)
+	if err != nil || !candidate.IsAbs() || candidate.User != nil || candidate.Fragment != "" ||
+		candidate.Host == "" {
+		return false
+	}
+	for _, entry := range allowed {
+		if entry == rawURL || matchRedirectPattern(entry, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchRedirectPattern(entry string, candidate *url.URL) bool {
+	if !strings.Contains(entry, "*") {
+		return false
+	}
+	// Parse the pattern with placeholders, since "*" is not valid in a host
+	pattern, err := url.Parse(strings.ReplaceAll(entry, "*", "wildcard"))
+	if err != nil || !strings.EqualFold(pattern.Scheme, candidate.Scheme) {
+		return false
+	}
+	if pattern.Port() != candidate.Port() {
+		return false
+	}
+
+	patternHost := strings.ToLower(pattern.Hostname())
+	host := strings.ToLower(candidate.Hostname())
+	if suffix, ok := strings.CutPrefix(patternHost, "wildcard."); ok {
+		if !strings.HasSuffix(host, "."+suffix) {
+			return false
+		}
+	} else if patternHost != host {
+		return false
+	}
+
+	if prefix, ok := strings.CutSuffix(pattern.Path, "wildcard"); ok {
+		return strings.HasPrefix(candidate.Path, prefix)
+	}
+	return pattern.Path == candidate.Path && pattern.RawQuery == candidate.RawQuery
+}
@@ -0,0 +1,42 @@
+package utils
+
+import "testing"
+
+func TestMatchRedirectURL(t *testing.T) {
+	allowed := []string{
+		"http://localhost:8080/auth/callback",
+		"https://*.example.com/auth/callback",
+		"https://app.example.org/auth/*",
+	}
+
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"http://localhost:8080/auth/callback", true},
+		{"http://localhost:8081/auth/callback", false},
+		{"http://localhost:8080/auth/callback?next=/", false},
+		{"https://tenant.example.com/auth/callback", true},
+		{"https://a.b.example.com/auth/callback", true},
+		{"https://example.com/auth/callback", false},
+		{"https://evilexample.com/auth/callback", false},
+		{"https://tenant.example.com.evil.com/auth/callback", false},
+		{"http://tenant.example.com/auth/callback", false},
+		{"https://tenant.example.com/auth/callback/other", false},
+		{"https://app.example.org/auth/callback", true},
+		{"https://app.example.org/auth/", true},
+		{"https://app.example.org/other", false},
+		{"https://user@app.example.org/auth/callback", false},
+		{"https://app.example.org/auth/callback#token", false},
+		{"/auth/callback", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := MatchRedirectURL(allowed, tt.url); got != tt.expected {
+				t.Errorf("Expected %v for %q, got %v", tt.expected, tt.url, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package utils
+
+import "testing"
+
+func TestEncryptSecret(t *testing.T) {
+	encrypted, err := EncryptSecret("key", "gho_secret")
+	if err != nil {
+		t.Fatalf("EncryptSecret failed: %v", err)
+	}
+	if again, _ := EncryptSecret("key", "gho_secret"); again == encrypted {
+		t.Error("Expected a new nonce for every encryption")
+	}
+
+	secret, err := DecryptSecret("key", encrypted)
+	if err != nil || secret != "gho_secret" {
+		t.Errorf("Expected the secret back, got %q, %v", secret, err)
+	}
+	if _, err := DecryptSecret("other key", encrypted); err == nil {
+		t.Error("Expected decryption with another key to fail")
+	}
+	if _, err := DecryptSecret("key", "c2hvcnQ="); err == nil {
+		t.Error("Expected decryption of a truncated secret to fail")
+	}
+}
+
+func TestSignToken(t *testing.T) {
+	signed := SignToken("key", "abc123")
+	if token, ok := VerifySignedToken("key", signed); !ok || token != "abc123" {
+		t.Errorf("Expected the token back, got %q, %v", token, ok)
+	}
+	for _, forged := range []string{
+		"abc123",
+		"abc124" + signed[len("abc123"):],
+		SignToken("other key", "abc123"),
+	} {
+		if _, ok := VerifySignedToken("key", forged); ok {
+			t.Errorf("Expected %q to be rejected", forged)
+		}
+	}
+}
@@ -1,14 +1,27 @@
 package utils
 
 import (
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
 	"github.com/poly-workshop/auth-portal/internal/model"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+const (
+	ClaimUserID             = "user_id"
+	ClaimUserRole           = "user_role"
+	ClaimMustChangePassword = "must_change_password"
+	ClaimRoleVersion        = "role_version"
+	ClaimSessionRef         = "sid"
+	ClaimScope              = "scope"
+	ClaimEntitlements       = "entitlements"
+)
+
 type UserTokenClaims struct {
 	jwt.MapClaims
 }
@@ -26,15 +39,31 @@ func NewUserTokenClaimsWithExpiration(
 	role model.UserRole,
 	expiresAt time.Time,
 ) UserTokenClaims {
+	now := time.Now().Unix()
 	return UserTokenClaims{
 		MapClaims: jwt.MapClaims{
-			"user_id":   userID,
-			"user_role": role.ToPb(),
-			"exp":       expiresAt.Unix(),
+			ClaimUserID:   userID,
+			ClaimUserRole: role.ToPb(),
+			"exp":         expiresAt.Unix(),
+			"sub":         userID,
+			"iat":         now,
+			"nbf":         now,
+			// jti uniquely identifies the token, e.g. for revocation lists
+			"jti": uuid.New().String(),
 		},
 	}
 }
 
+// SetIssuer sets the iss and aud claims; empty values are left out.
+func (c UserTokenClaims) SetIssuer(issuer, audience string) {
+	if issuer != "" {
+		c.MapClaims["iss"] = issuer
+	}
+	if audience != "" {
+		c.MapClaims["aud"] = audience
+	}
+}
+
 func (c UserTokenClaims) GetExpirationTime() (*jwt.NumericDate, error) {
 	return c.MapClaims.GetExpirationTime()
 }
@@ -59,6 +88,42 @@ func (c UserTokenClaims) GetSubject() (string, error) {
 	return c.MapClaims.GetSubject()
 }
 
+// MustChangePassword reports whether the token is restricted to changing the password.
+func (c UserTokenClaims) MustChangePassword() bool {
+	restricted, _ := c.MapClaims[ClaimMustChangePassword].(bool)
+	return restricted
+}
+
+// RoleVersion returns the role version the token was issued with, 0 if absent.
+func (c UserTokenClaims) RoleVersion() int64 {
+	version, _ := c.MapClaims[ClaimRoleVersion].(float64)
+	return int64(version)
+}
+
+// SessionRef returns the reference to the session the token was issued for.
+func (c UserTokenClaims) SessionRef() string {
+	ref, _ := c.MapClaims[ClaimSessionRef].(string)
+	return ref
+}
+
+// Scopes returns the space separated entries of the scope claim, nil if absent.
+func (c UserTokenClaims) Scopes() []string {
+	scope, _ := c.MapClaims[ClaimScope].(string)
+	return strings.Fields(scope)
+}
+
+// Entitlements returns the entries of the entitlements claim, nil if absent.
+func (c UserTokenClaims) Entitlements() []string {
+	values, _ := c.MapClaims[ClaimEntitlements].([]any)
+	var entitlements []string
+	for _, value := range values {
+		if name, ok := value.(string); ok {
+			entitlements = append(entitlements, name)
+		}
+	}
+	return entitlements
+}
+
 // NewUserTokenWithExpiration creates a new UserToken with a custom expiration time.
 func NewUserTokenWithExpiration(
 	userID string,
@@ -67,6 +132,15 @@ func NewUserTokenWithExpiration(
 	expiresAt time.Time,
 ) (*auth_v1_pb.UserToken, error) {
 	claims := NewUserTokenClaimsWithExpiration(userID, role, expiresAt)
+	return SignUserToken(claims, secret, expiresAt)
+}
+
+// SignUserToken signs the given claims, allowing callers to add claims before signing.
+func SignUserToken(
+	claims UserTokenClaims,
+	secret string,
+	expiresAt time.Time,
+) (*auth_v1_pb.UserToken, error) {
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	signedToken, err := jwtToken.SignedString([]byte(secret))
 	if err != nil {
@@ -79,21 +153,138 @@ func NewUserTokenWithExpiration(
 	}, nil
 }
 
-func ValidateUserToken(tokenString, secret string) (*UserTokenClaims, error) {
+// DefaultValidMethods are the signing algorithms accepted unless configured otherwise.
+var DefaultValidMethods = []string{jwt.SigningMethodHS256.Alg()}
+
+type validationOptions struct {
+	issuer       string
+	audience     string
+	allowLegacy  bool
+	validMethods []string
+	leeway       time.Duration
+}
+
+// ValidationOption adds a check to ValidateUserToken.
+type ValidationOption func(*validationOptions)
+
+// WithExpectedIssuer requires the iss claim to equal issuer.
+func WithExpectedIssuer(issuer string) ValidationOption {
+	return func(o *validationOptions) {
+		o.issuer = issuer
+	}
+}
+
+// WithExpectedAudience requires the aud claim to contain audience.
+func WithExpectedAudience(audience string) ValidationOption {
+	return func(o *validationOptions) {
+		o.audience = audience
+	}
+}
+
+// WithLegacyTokens accepts tokens issued before the iss and aud claims were
+// added, as long as they carry neither claim.
+func WithLegacyTokens(allow bool) ValidationOption {
+	return func(o *validationOptions) {
+		o.allowLegacy = allow
+	}
+}
+
+// WithValidMethods restricts the accepted signing algorithms; tokens signed with
+// any other algorithm (including "none") are rejected before their signature is
+// checked. Defaults to DefaultValidMethods.
+func WithValidMethods(methods ...string) ValidationOption {
+	return func(o *validationOptions) {
+		o.validMethods = methods
+	}
+}
+
+// WithLeeway tolerates clock skew between servers of up to leeway when checking
+// the exp, nbf and iat claims.
+func WithLeeway(leeway time.Duration) ValidationOption {
+	return func(o *validationOptions) {
+		o.leeway = leeway
+	}
+}
+
+// parserOptions translates the options into JWT parser options. Issuer and
+// audience are only left to the parser if legacy tokens without them are refused.
+func (o validationOptions) parserOptions() []jwt.ParserOption {
+	methods := o.validMethods
+	if len(methods) == 0 {
+		methods = DefaultValidMethods
+	}
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(o.leeway),
+	}
+	if !o.allowLegacy {
+		if o.issuer != "" {
+			opts = append(opts, jwt.WithIssuer(o.issuer))
+		}
+		if o.audience != "" {
+			opts = append(opts, jwt.WithAudience(o.audience))
+		}
+	}
+	return opts
+}
+
+func ValidateUserToken(
+	tokenString, secret string,
+	opts ...ValidationOption,
+) (*UserTokenClaims, error) {
+	var options validationOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	token, err := jwt.ParseWithClaims(
 		tokenString,
 		&UserTokenClaims{},
 		func(token *jwt.Token) (interface{}, error) {
+			// Only HMAC keys exist so far; never hand the secret to another algorithm
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, jwt.ErrTokenSignatureInvalid
+			}
 			return []byte(secret), nil
 		},
+		options.parserOptions()...,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*UserTokenClaims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*UserTokenClaims)
+	if !ok || !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	if options.allowLegacy {
+		if err := options.verifyIssuer(claims); err != nil {
+			return nil, err
+		}
+	}
+	return claims, nil
+}
+
+// verifyIssuer checks issuer and audience, accepting tokens carrying neither.
+func (o validationOptions) verifyIssuer(claims *UserTokenClaims) error {
+	issuer, err := claims.GetIssuer()
+	if err != nil {
+		return err
+	}
+	audience, err := claims.GetAudience()
+	if err != nil {
+		return err
+	}
+	if issuer == "" && len(audience) == 0 {
+		return nil
 	}
 
-	return nil, jwt.ErrTokenInvalidClaims
+	if o.issuer != "" && issuer != o.issuer {
+		return jwt.ErrTokenInvalidIssuer
+	}
+	if o.audience != "" && !slices.Contains(audience, o.audience) {
+		return jwt.ErrTokenInvalidAudience
+	}
+	return nil
 }
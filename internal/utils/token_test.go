@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/poly-workshop/auth-portal/internal/model"
 )
@@ -143,3 +144,216 @@ func TestBackwardsCompatibility(t *testing.T) {
 		t.Errorf("Expected user ID %s in claims, got %v", userID, claimsUserID)
 	}
 }
+
+func TestSignUserTokenMustChangePassword(t *testing.T) {
+	userID := uuid.New().String()
+	secret := "test-secret"
+	expiresAt := time.Now().Add(time.Hour)
+
+	claims := NewUserTokenClaimsWithExpiration(userID, model.UserRoleUser, expiresAt)
+	if claims.MustChangePassword() {
+		t.Error("Expected new claims not to be restricted")
+	}
+	claims.MapClaims[ClaimMustChangePassword] = true
+
+	token, err := SignUserToken(claims, secret, expiresAt)
+	if err != nil {
+		t.Fatalf("Failed to sign user token: %v", err)
+	}
+
+	parsed, err := ValidateUserToken(token.Token, secret)
+	if err != nil {
+		t.Fatalf("Failed to validate token: %v", err)
+	}
+	if !parsed.MustChangePassword() {
+		t.Error("Expected must_change_password claim to survive signing")
+	}
+}
+
+func TestStandardClaims(t *testing.T) {
+	userID := uuid.New().String()
+	expiresAt := time.Now().Add(time.Hour)
+
+	first := NewUserTokenClaimsWithExpiration(userID, model.UserRoleUser, expiresAt)
+	second := NewUserTokenClaimsWithExpiration(userID, model.UserRoleUser, expiresAt)
+	first.SetIssuer("auth-portal", "my-app")
+
+	if sub, _ := first.GetSubject(); sub != userID {
+		t.Errorf("Expected sub %s, got %s", userID, sub)
+	}
+	if iat, ok := first.MapClaims["iat"].(int64); !ok || iat > time.Now().Unix() {
+		t.Errorf("Expected iat to be set, got %v", first.MapClaims["iat"])
+	}
+	if nbf, ok := first.MapClaims["nbf"].(int64); !ok || nbf != first.MapClaims["iat"] {
+		t.Errorf("Expected nbf to equal iat, got %v", first.MapClaims["nbf"])
+	}
+	if first.MapClaims["jti"] == "" || first.MapClaims["jti"] == second.MapClaims["jti"] {
+		t.Error("Expected a unique jti per token")
+	}
+	if iss, _ := first.GetIssuer(); iss != "auth-portal" {
+		t.Errorf("Expected iss auth-portal, got %s", iss)
+	}
+	if aud, _ := first.GetAudience(); len(aud) != 1 || aud[0] != "my-app" {
+		t.Errorf("Expected aud [my-app], got %v", aud)
+	}
+}
+
+func TestValidateUserTokenIssuerAndAudience(t *testing.T) {
+	secret := "test-secret"
+	expiresAt := time.Now().Add(time.Hour)
+	sign := func(issuer, audience string) string {
+		claims := NewUserTokenClaimsWithExpiration("user-1", model.UserRoleUser, expiresAt)
+		claims.SetIssuer(issuer, audience)
+		token, err := SignUserToken(claims, secret, expiresAt)
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		return token.Token
+	}
+
+	tests := []struct {
+		name        string
+		token       string
+		allowLegacy bool
+		wantErr     bool
+	}{
+		{"matching issuer and audience", sign("auth-portal", "my-app"), false, false},
+		{"wrong issuer", sign("someone-else", "my-app"), false, true},
+		{"wrong audience", sign("auth-portal", "other-app"), false, true},
+		{"legacy token rejected", sign("", ""), false, true},
+		{"legacy token allowed", sign("", ""), true, false},
+		{"wrong issuer despite legacy flag", sign("someone-else", "my-app"), true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateUserToken(
+				tt.token,
+				secret,
+				WithExpectedIssuer("auth-portal"),
+				WithExpectedAudience("my-app"),
+				WithLegacyTokens(tt.allowLegacy),
+			)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateUserTokenSigningMethods(t *testing.T) {
+	secret := "test-secret"
+	claims := NewUserTokenClaimsWithExpiration("user-1", model.UserRoleUser, time.Now().Add(time.Hour))
+	sign := func(method jwt.SigningMethod, key interface{}) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		opts    []ValidationOption
+		wantErr bool
+	}{
+		{"HS256 accepted by default", sign(jwt.SigningMethodHS256, []byte(secret)), nil, false},
+		{"HS512 rejected by default", sign(jwt.SigningMethodHS512, []byte(secret)), nil, true},
+		{
+			"none rejected",
+			sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType),
+			[]ValidationOption{WithValidMethods("none", "HS256")},
+			true,
+		},
+		{
+			"HS512 accepted when configured",
+			sign(jwt.SigningMethodHS512, []byte(secret)),
+			[]ValidationOption{WithValidMethods("HS256", "HS512")},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateUserToken(tt.token, secret, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateUserTokenLeeway(t *testing.T) {
+	secret := "test-secret"
+	expiresAt := time.Now().Add(time.Hour)
+	sign := func(mutate func(jwt.MapClaims)) string {
+		claims := NewUserTokenClaimsWithExpiration("user-1", model.UserRoleUser, expiresAt)
+		mutate(claims.MapClaims)
+		token, err := SignUserToken(claims, secret, expiresAt)
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		return token.Token
+	}
+	shift := func(claim string, d time.Duration) func(jwt.MapClaims) {
+		return func(c jwt.MapClaims) {
+			c[claim] = time.Now().Add(d).Unix()
+		}
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		leeway  time.Duration
+		wantErr bool
+	}{
+		{"fresh token", sign(func(jwt.MapClaims) {}), 0, false},
+		{"expired within leeway", sign(shift("exp", -10*time.Second)), 30 * time.Second, false},
+		{"expired beyond leeway", sign(shift("exp", -time.Minute)), 30 * time.Second, true},
+		{"not yet valid without leeway", sign(shift("nbf", 10*time.Second)), 0, true},
+		{"not yet valid within leeway", sign(shift("nbf", 10*time.Second)), 30 * time.Second, false},
+		{"issued in the future", sign(shift("iat", time.Minute)), 30 * time.Second, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateUserToken(tt.token, secret, WithLeeway(tt.leeway))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func BenchmarkSignUserToken(b *testing.B) {
+	expiresAt := time.Now().Add(time.Hour)
+	claims := NewUserTokenClaimsWithExpiration("user-1", model.UserRoleUser, expiresAt)
+	claims.SetIssuer("auth-portal", "auth-portal")
+	for b.Loop() {
+		if _, err := SignUserToken(claims, "test-secret", expiresAt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidateUserToken(b *testing.B) {
+	expiresAt := time.Now().Add(time.Hour)
+	claims := NewUserTokenClaimsWithExpiration("user-1", model.UserRoleUser, expiresAt)
+	claims.SetIssuer("auth-portal", "auth-portal")
+	token, err := SignUserToken(claims, "test-secret", expiresAt)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for b.Loop() {
+		_, err := ValidateUserToken(
+			token.Token,
+			"test-secret",
+			WithExpectedIssuer("auth-portal"),
+			WithExpectedAudience("auth-portal"),
+		)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var authzDecisions = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "auth_authz_decisions_total",
+	Help: "Decisions of the RBAC policy on user calls: allowed, denied or dry_run_denied.",
+}, []string{"decision"})
+
+type decisionOptions struct {
+	mode   string
+	dryRun bool
+}
+
+// WithDecisionLog logs the decisions of the RBAC policy selected by mode, one
+// of configs.AuthzDecisionLogOff, AuthzDecisionLogDenials and AuthzDecisionLogAll.
+// With dryRun, calls the policy denies are let through and only logged.
+func WithDecisionLog(mode string, dryRun bool) InterceptorOption {
+	return func(o *interceptorOptions) {
+		o.decisions = decisionOptions{mode: mode, dryRun: dryRun}
+	}
+}
+
+// decide logs and counts a decision of the policy on a call of a user of role
+// that requires permission, and reports whether the call may pass.
+func (a *authenticator) decide(
+	ctx context.Context,
+	role, permission string,
+	allowed bool,
+) bool {
+	opts := a.options.decisions
+	decision := "allowed"
+	switch {
+	case !allowed && opts.dryRun:
+		decision = "dry_run_denied"
+	case !allowed:
+		decision = "denied"
+	}
+	authzDecisions.WithLabelValues(decision).Inc()
+
+	if opts.mode == configs.AuthzDecisionLogAll ||
+		(!allowed && opts.mode == configs.AuthzDecisionLogDenials) {
+		level := slog.LevelInfo
+		if !allowed {
+			level = slog.LevelWarn
+		}
+		// The request ID, user and method are added by the log handler
+		slog.Log(ctx, level, "authorization decision",
+			"decision", decision,
+			"role", role,
+			"permission", permission,
+		)
+	}
+	return allowed || opts.dryRun
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// captureLogs sends the default logger to a buffer for the rest of the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func TestInterceptorDecisionLog(t *testing.T) {
+	enforcer, err := NewEnforcer()
+	if err != nil {
+		t.Fatalf("Failed to create enforcer: %v", err)
+	}
+	// A policy change revoking a permission users have today
+	revoked := "/UserService/GetCurrentUser"
+	if _, err := enforcer.RemovePolicy("user", revoked); err != nil {
+		t.Fatalf("Failed to remove policy: %v", err)
+	}
+	userCtx := metadata.NewIncomingContext(
+		context.Background(),
+		metadata.Pairs("authorization", "Bearer "+signTestToken(t, model.UserRoleUser, 0, "")),
+	)
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	call := func(interceptor grpc.UnaryServerInterceptor, method string) error {
+		_, err := interceptor(userCtx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+	revokedMethod := user_v1_pb.UserService_GetCurrentUser_FullMethodName
+	grantedMethod := user_v1_pb.UserService_ChangePassword_FullMethodName
+
+	t.Run("denials", func(t *testing.T) {
+		logs := captureLogs(t)
+		interceptor := BuildAuthInterceptor(testJWTSecret, WithEnforcer(enforcer),
+			WithDecisionLog(configs.AuthzDecisionLogDenials, false))
+		if err := call(interceptor, revokedMethod); status.Code(err) != codes.PermissionDenied {
+			t.Fatalf("expected PermissionDenied, got %v", err)
+		}
+		if err := call(interceptor, grantedMethod); err != nil {
+			t.Fatalf("expected the call to pass, got %v", err)
+		}
+		if got := strings.Count(logs.String(), "authorization decision"); got != 1 {
+			t.Fatalf("expected one logged decision, got %d in %q", got, logs)
+		}
+		if !strings.Contains(logs.String(), "decision=denied") ||
+			!strings.Contains(logs.String(), "permission="+revoked) {
+			t.Errorf("expected the denial to be logged, got %q", logs)
+		}
+	})
+
+	t.Run("all", func(t *testing.T) {
+		logs := captureLogs(t)
+		interceptor := BuildAuthInterceptor(testJWTSecret, WithEnforcer(enforcer),
+			WithDecisionLog(configs.AuthzDecisionLogAll, false))
+		_ = call(interceptor, revokedMethod)
+		_ = call(interceptor, grantedMethod)
+		if got := strings.Count(logs.String(), "authorization decision"); got != 2 {
+			t.Fatalf("expected two logged decisions, got %d in %q", got, logs)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		logs := captureLogs(t)
+		interceptor := BuildAuthInterceptor(testJWTSecret, WithEnforcer(enforcer),
+			WithDecisionLog(configs.AuthzDecisionLogDenials, true))
+		if err := call(interceptor, revokedMethod); err != nil {
+			t.Fatalf("expected the dry run to let the call pass, got %v", err)
+		}
+		if !strings.Contains(logs.String(), "decision=dry_run_denied") {
+			t.Errorf("expected the denial to be logged, got %q", logs)
+		}
+		// The policy is not the only check; admin RPCs stay closed
+		err := call(interceptor, user_v1_pb.UserService_ListUsers_FullMethodName)
+		if status.Code(err) != codes.PermissionDenied {
+			t.Errorf("expected PermissionDenied for an admin RPC, got %v", err)
+		}
+	})
+}
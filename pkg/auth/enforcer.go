@@ -4,12 +4,30 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/casbin/casbin/v2"
 )
 
-// NewEnforcer creates a new Casbin enforcer with the RBAC model and policy
-func NewEnforcer() (*casbin.Enforcer, error) {
+var (
+	sharedEnforcerOnce sync.Once
+	sharedEnforcer     *casbin.SyncedEnforcer
+	sharedEnforcerErr  error
+)
+
+// SharedEnforcer returns the enforcer of this process, created on first use.
+// Everything that checks permissions uses it, so a policy reload applies to all
+// of them at once.
+func SharedEnforcer() (*casbin.SyncedEnforcer, error) {
+	sharedEnforcerOnce.Do(func() {
+		sharedEnforcer, sharedEnforcerErr = NewEnforcer()
+	})
+	return sharedEnforcer, sharedEnforcerErr
+}
+
+// NewEnforcer creates a new Casbin enforcer with the RBAC model and policy.
+// Prefer SharedEnforcer outside of tests.
+func NewEnforcer() (*casbin.SyncedEnforcer, error) {
 	// Get current working directory and find the configs
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -47,7 +65,7 @@ func NewEnforcer() (*casbin.Enforcer, error) {
 		)
 	}
 
-	enforcer, err := casbin.NewEnforcer(modelPath, policyPath)
+	enforcer, err := casbin.NewSyncedEnforcer(modelPath, policyPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create enforcer: %w", err)
 	}
@@ -56,7 +74,7 @@ func NewEnforcer() (*casbin.Enforcer, error) {
 }
 
 // CheckPermission checks if a user role has permission to access a method
-func CheckPermission(enforcer *casbin.Enforcer, role, method string) (bool, error) {
+func CheckPermission(enforcer *casbin.SyncedEnforcer, role, method string) (bool, error) {
 	allowed, err := enforcer.Enforce(role, method)
 	if err != nil {
 		return false, fmt.Errorf("failed to enforce policy: %w", err)
@@ -86,12 +86,54 @@ func TestEnforcer(t *testing.T) {
 			method:   "/UserService/DeleteUser",
 			expected: false,
 		},
+		{
+			name:     "user cannot revoke sessions of other users",
+			role:     "user",
+			method:   "/UserService/AdminRevokeUserSessions",
+			expected: false,
+		},
+		{
+			name:     "admin can revoke a session",
+			role:     "admin",
+			method:   "/UserService/AdminRevokeSession",
+			expected: true,
+		},
+		{
+			name:     "admin can set feature flags",
+			role:     "admin",
+			method:   "/UserService/AdminSetFeatureFlag",
+			expected: true,
+		},
+		{
+			name:     "user cannot set feature flags",
+			role:     "user",
+			method:   "/UserService/AdminSetFeatureFlag",
+			expected: false,
+		},
+		{
+			name:     "user cannot invite users",
+			role:     "user",
+			method:   "/UserService/AdminInviteUser",
+			expected: false,
+		},
 		{
 			name:     "user can get current user",
 			role:     "user",
 			method:   "/UserService/GetCurrentUser",
 			expected: true,
 		},
+		{
+			name:     "user can request account deletion",
+			role:     "user",
+			method:   "/UserService/RequestAccountDeletion",
+			expected: true,
+		},
+		{
+			name:     "user can cancel account deletion",
+			role:     "user",
+			method:   "/UserService/CancelAccountDeletion",
+			expected: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -141,3 +183,36 @@ func TestConvertRoleToString(t *testing.T) {
 		})
 	}
 }
+
+func TestPolicyObject(t *testing.T) {
+	tests := []struct {
+		name       string
+		fullMethod string
+		expected   string
+	}{
+		{
+			name:       "package qualified method",
+			fullMethod: user_v1_pb.UserService_GetCurrentUser_FullMethodName,
+			expected:   "/UserService/GetCurrentUser",
+		},
+		{
+			name:       "unqualified method",
+			fullMethod: "/UserService/GetUser",
+			expected:   "/UserService/GetUser",
+		},
+		{
+			name:       "malformed method",
+			fullMethod: "invalid",
+			expected:   "invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := policyObject(tt.fullMethod)
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
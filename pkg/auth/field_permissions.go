@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+)
+
+// fieldObjectSeparator separates the policy object of an RPC from the name of
+// a field it updates in field objects.
+const fieldObjectSeparator = "#"
+
+// FieldObject returns the policy object granting updates of field through the
+// RPC whose policy object is object, e.g. "/UserService/UpdateUser#email".
+func FieldObject(object, field string) string {
+	return object + fieldObjectSeparator + field
+}
+
+// UpdatableFields returns the fields a user of role may update through the RPC
+// named by fullMethod, nil if any. A role is restricted to the fields whose
+// FieldObject it is granted once it is granted one, directly or through the
+// roles it inherits, e.g.
+//
+//	p, admin, /UserService/UpdateUser#name
+//	p, admin, /UserService/UpdateUser#phone_number
+func UpdatableFields(enforcer *casbin.SyncedEnforcer, role, fullMethod string) ([]string, error) {
+	permissions, err := enforcer.GetImplicitPermissionsForUser(role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+	prefix := FieldObject(requiredPermission(fullMethod, MethodAuthz(fullMethod)), "")
+	var fields []string
+	for _, permission := range permissions {
+		if len(permission) > 1 && strings.HasPrefix(permission[1], prefix) {
+			fields = append(fields, strings.TrimPrefix(permission[1], prefix))
+		}
+	}
+	slices.Sort(fields)
+	return slices.Compact(fields), nil
+}
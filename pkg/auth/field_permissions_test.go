@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+)
+
+func TestUpdatableFields(t *testing.T) {
+	policyPath := filepath.Join(t.TempDir(), "policy.csv")
+	policy := strings.Join([]string{
+		"p, admin, /UserService/UpdateUser",
+		"p, support, /UserService/UpdateUser",
+		"p, support, /UserService/UpdateUser#name",
+		"p, support, /UserService/UpdateUser#phone_number",
+		"p, lead, /UserService/UpdateUser#email",
+		"g, lead, support",
+	}, "\n")
+	if err := os.WriteFile(policyPath, []byte(policy), 0o600); err != nil {
+		t.Fatalf("Failed to write policy: %v", err)
+	}
+	enforcer, err := casbin.NewSyncedEnforcer(
+		filepath.Join("..", "..", "configs", "rbac_model.conf"),
+		policyPath,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create enforcer: %v", err)
+	}
+
+	for _, tc := range []struct {
+		role string
+		want []string
+	}{
+		{"admin", nil},
+		{"support", []string{"name", "phone_number"}},
+		{"lead", []string{"email", "name", "phone_number"}},
+	} {
+		got, err := UpdatableFields(
+			enforcer,
+			tc.role,
+			user_v1_pb.UserService_UpdateUser_FullMethodName,
+		)
+		if err != nil {
+			t.Fatalf("UpdatableFields failed: %v", err)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s: expected fields %v, got %v", tc.role, tc.want, got)
+		}
+	}
+}
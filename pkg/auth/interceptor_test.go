@@ -0,0 +1,350 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/repository"
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+	"github.com/poly-workshop/auth-portal/internal/utils"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testJWTSecret = "test_secret"
+
+type staticRoleVersions map[string]int64
+
+func (v staticRoleVersions) Get(_ context.Context, userID string) (int64, error) {
+	return v[userID], nil
+}
+
+type countingSessions struct {
+	active map[string]bool
+	calls  int
+}
+
+func (s *countingSessions) Active(_ context.Context, sessionRef string) (bool, error) {
+	s.calls++
+	return s.active[sessionRef], nil
+}
+
+func signTestToken(
+	t testing.TB,
+	role model.UserRole,
+	roleVersion int64,
+	sessionRef string,
+) string {
+	t.Helper()
+	expiresAt := time.Now().Add(time.Hour)
+	claims := utils.NewUserTokenClaimsWithExpiration("user-1", role, expiresAt)
+	if roleVersion > 0 {
+		claims.MapClaims[utils.ClaimRoleVersion] = roleVersion
+	}
+	if sessionRef != "" {
+		claims.MapClaims[utils.ClaimSessionRef] = sessionRef
+	}
+	token, err := utils.SignUserToken(claims, testJWTSecret, expiresAt)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return token.Token
+}
+
+func TestInterceptorRoleVersion(t *testing.T) {
+	interceptor := BuildAuthInterceptor(
+		testJWTSecret,
+		WithRoleVersions(staticRoleVersions{"user-1": 2}),
+	)
+	info := &grpc.UnaryServerInfo{FullMethod: user_v1_pb.UserService_ListUsers_FullMethodName}
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+
+	tests := []struct {
+		name        string
+		roleVersion int64
+		expected    codes.Code
+	}{
+		{"token issued before role change", 1, codes.Unauthenticated},
+		{"token without role version", 0, codes.Unauthenticated},
+		{"token with current role version", 2, codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := signTestToken(t, model.UserRoleAdmin, tt.roleVersion, "")
+			ctx := metadata.NewIncomingContext(
+				context.Background(),
+				metadata.Pairs("authorization", "Bearer "+token),
+			)
+			_, err := interceptor(ctx, nil, info, handler)
+			if status.Code(err) != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestInterceptorRoleVersionAfterSessionMigration(t *testing.T) {
+	ctx := context.Background()
+	src, _ := testutil.NewRedis(t)
+	dst, _ := testutil.NewRedis(t)
+	for range 2 {
+		if _, err := repository.NewRoleVersionRepository(src).Bump(ctx, "user-1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := repository.MigrateSessions(ctx, src, dst,
+		repository.MigrateSessionsOptions{}); err != nil {
+		t.Fatalf("MigrateSessions failed: %v", err)
+	}
+
+	interceptor := BuildAuthInterceptor(
+		testJWTSecret,
+		WithRoleVersions(repository.NewRoleVersionRepository(dst)),
+	)
+	info := &grpc.UnaryServerInfo{FullMethod: user_v1_pb.UserService_ListUsers_FullMethodName}
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	token := signTestToken(t, model.UserRoleAdmin, 1, "")
+	_, err := interceptor(metadata.NewIncomingContext(ctx,
+		metadata.Pairs("authorization", "Bearer "+token)), nil, info, handler)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected a token from before the role change to stay rejected, got %v", err)
+	}
+}
+
+func TestInterceptorSessionCheck(t *testing.T) {
+	sessions := &countingSessions{active: map[string]bool{"live": true}}
+	interceptor := BuildAuthInterceptor(
+		testJWTSecret,
+		WithSessionCheck(sessions, time.Minute),
+	)
+	info := &grpc.UnaryServerInfo{FullMethod: user_v1_pb.UserService_GetCurrentUser_FullMethodName}
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	call := func(sessionRef string) error {
+		token := signTestToken(t, model.UserRoleUser, 0, sessionRef)
+		ctx := metadata.NewIncomingContext(
+			context.Background(),
+			metadata.Pairs("authorization", "Bearer "+token),
+		)
+		_, err := interceptor(ctx, nil, info, handler)
+		return err
+	}
+
+	if err := call("live"); err != nil {
+		t.Errorf("Expected active session to pass, got %v", err)
+	}
+	if err := call("revoked"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated for revoked session, got %v", err)
+	}
+	if err := call(""); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated for unbound token, got %v", err)
+	}
+
+	calls := sessions.calls
+	if err := call("live"); err != nil {
+		t.Errorf("Expected cached session to pass, got %v", err)
+	}
+	if sessions.calls != calls {
+		t.Error("Expected the second check to be served from the cache")
+	}
+}
+
+type recordedActivity []string
+
+func (r *recordedActivity) RecordActivity(_ context.Context, userID string) {
+	*r = append(*r, userID)
+}
+
+func TestInterceptorRecordsActivity(t *testing.T) {
+	var recorded recordedActivity
+	interceptor := BuildAuthInterceptor(testJWTSecret, WithActivityRecorder(&recorded))
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	call := func(method string) {
+		token := signTestToken(t, model.UserRoleUser, 0, "")
+		ctx := metadata.NewIncomingContext(
+			context.Background(),
+			metadata.Pairs("authorization", "Bearer "+token),
+		)
+		_, _ = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	}
+
+	call(user_v1_pb.UserService_GetCurrentUser_FullMethodName)
+	// Denied calls are not activity
+	call(user_v1_pb.UserService_ListUsers_FullMethodName)
+	if len(recorded) != 1 || recorded[0] != "user-1" {
+		t.Errorf("expected only the allowed call to be recorded, got %v", recorded)
+	}
+}
+
+func TestInterceptorPasswordChangeRequired(t *testing.T) {
+	interceptor := BuildAuthInterceptor(testJWTSecret)
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	expiresAt := time.Now().Add(time.Hour)
+	claims := utils.NewUserTokenClaimsWithExpiration("user-1", model.UserRoleUser, expiresAt)
+	claims.MapClaims[utils.ClaimMustChangePassword] = true
+	token, err := utils.SignUserToken(claims, testJWTSecret, expiresAt)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	ctx := metadata.NewIncomingContext(
+		context.Background(),
+		metadata.Pairs("authorization", "Bearer "+token.Token),
+	)
+
+	info := &grpc.UnaryServerInfo{FullMethod: user_v1_pb.UserService_GetCurrentUser_FullMethodName}
+	_, err = interceptor(ctx, nil, info, handler)
+	var reason string
+	for _, detail := range status.Convert(err).Details() {
+		if errInfo, ok := detail.(*errdetails.ErrorInfo); ok && errInfo.Domain == ErrorDomain {
+			reason = errInfo.Reason
+		}
+	}
+	if status.Code(err) != codes.PermissionDenied || reason != ErrorReasonPasswordChangeRequired {
+		t.Errorf("expected the call to be refused with %s, got %v (%q)",
+			ErrorReasonPasswordChangeRequired, err, reason)
+	}
+	info.FullMethod = user_v1_pb.UserService_ChangePassword_FullMethodName
+	if _, err := interceptor(ctx, nil, info, handler); err != nil {
+		t.Errorf("expected the password change to be allowed, got %v", err)
+	}
+}
+
+// BenchmarkInterceptor measures the per-call cost of authenticating a user
+// token and authorizing it against the RBAC policy, with the optional checks
+// enabled one by one.
+func BenchmarkInterceptor(b *testing.B) {
+	rdb, _ := testutil.NewRedis(b)
+	sessionRepo := repository.NewSessionRepository(rdb)
+	sessionID, err := sessionRepo.Create(context.Background(), "user-1", time.Hour)
+	if err != nil {
+		b.Fatalf("Failed to create session: %v", err)
+	}
+	sessionRef := repository.SessionRef(sessionID)
+
+	benchmarks := []struct {
+		name    string
+		options []InterceptorOption
+	}{
+		{name: "token"},
+		{name: "role_version", options: []InterceptorOption{
+			WithRoleVersions(staticRoleVersions{}),
+		}},
+		{name: "session_cached", options: []InterceptorOption{
+			WithSessionCheck(sessionRepo, time.Minute),
+		}},
+		{name: "session_uncached", options: []InterceptorOption{
+			WithSessionCheck(sessionRepo, 0),
+		}},
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: user_v1_pb.UserService_GetCurrentUser_FullMethodName}
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	token := signTestToken(b, model.UserRoleUser, 0, sessionRef)
+	ctx := metadata.NewIncomingContext(
+		context.Background(),
+		metadata.Pairs("authorization", "Bearer "+token),
+	)
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			interceptor := BuildAuthInterceptor(testJWTSecret, bm.options...)
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := interceptor(ctx, nil, info, handler); err != nil {
+					b.Fatalf("Interceptor failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestInterceptorAuthLevels(t *testing.T) {
+	interceptor := BuildAuthInterceptor(testJWTSecret)
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	userCtx := metadata.NewIncomingContext(
+		context.Background(),
+		metadata.Pairs("authorization", "Bearer "+signTestToken(t, model.UserRoleUser, 0, "")),
+	)
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		method   string
+		expected codes.Code
+	}{
+		{
+			"public RPC without token",
+			context.Background(),
+			user_v1_pb.UserService_ConfirmEmailChange_FullMethodName,
+			codes.OK,
+		},
+		{
+			"user RPC without token",
+			context.Background(),
+			user_v1_pb.UserService_GetCurrentUser_FullMethodName,
+			codes.Unauthenticated,
+		},
+		{"user RPC", userCtx, user_v1_pb.UserService_GetCurrentUser_FullMethodName, codes.OK},
+		{
+			"admin RPC with user token",
+			userCtx,
+			user_v1_pb.UserService_ListUsers_FullMethodName,
+			codes.PermissionDenied,
+		},
+		{
+			"internal RPC with user token",
+			userCtx,
+			auth_v1_pb.AuthService_GetProviderToken_FullMethodName,
+			codes.PermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &grpc.UnaryServerInfo{FullMethod: tt.method}
+			_, err := interceptor(tt.ctx, nil, info, handler)
+			if status.Code(err) != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context { return s.ctx }
+
+func TestStreamInterceptor(t *testing.T) {
+	interceptor := BuildAuthStreamInterceptor(testJWTSecret)
+	info := &grpc.StreamServerInfo{FullMethod: user_v1_pb.UserService_ExportUsers_FullMethodName}
+	var caller *UserInfo
+	handler := func(srv any, stream grpc.ServerStream) error {
+		caller, _ = stream.Context().Value(ContextKeyUserInfo).(*UserInfo)
+		return nil
+	}
+	call := func(role model.UserRole) error {
+		ctx := metadata.NewIncomingContext(
+			context.Background(),
+			metadata.Pairs("authorization", "Bearer "+signTestToken(t, role, 0, "")),
+		)
+		return interceptor(nil, &testServerStream{ctx: ctx}, info, handler)
+	}
+
+	if err := call(model.UserRoleUser); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected PermissionDenied for users, got %v", err)
+	}
+	if err := call(model.UserRoleAdmin); err != nil {
+		t.Fatalf("Expected admins to pass, got %v", err)
+	}
+	if caller == nil || caller.UserID != "user-1" {
+		t.Errorf("Expected the handler's stream to carry the caller, got %v", caller)
+	}
+}
@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	"github.com/poly-workshop/auth-portal/internal/rpcmeta"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// InternalCredentialFromContext returns the name of the internal credential
+// the call in ctx was made with, false for calls with user tokens.
+func InternalCredentialFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(ContextKeyInternalCredential).(string)
+	return name, ok
+}
+
+// minSigningKeyLength is the minimum length of the signing keys of internal
+// credentials.
+const minSigningKeyLength = 32
+
+// InternalCredentials authenticates internal calls, by token, by signature
+// (see SignRequest) or by workload token (see WorkloadVerifier). Tokens are
+// only kept as SHA-256 digests, which are compared in constant time.
+type InternalCredentials struct {
+	credentials []internalCredential
+}
+
+type internalCredential struct {
+	name        string
+	digests     [][sha256.Size]byte
+	signingKeys [][]byte
+	workloads   []configs.WorkloadIdentity
+	methods     map[string]bool
+	expiresAt   time.Time
+}
+
+// NewInternalCredentials returns the credentials of auth.internal_token, named
+// InternalKey and allowed every RPC unless token is empty, and of
+// auth.internal_credentials. It fails for invalid digests, short signing keys,
+// unknown methods, incomplete workloads, and names and workloads used twice.
+func NewInternalCredentials(
+	token string,
+	credentials []configs.InternalCredential,
+) (*InternalCredentials, error) {
+	c := &InternalCredentials{}
+	names := make(map[string]bool)
+	workloads := make(map[configs.WorkloadIdentity]bool)
+	if token != "" {
+		c.credentials = append(c.credentials, internalCredential{
+			name:    InternalKey,
+			digests: [][sha256.Size]byte{sha256.Sum256([]byte(token))},
+		})
+		names[InternalKey] = true
+	}
+	for _, cfg := range credentials {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("internal credential without name")
+		}
+		if names[cfg.Name] {
+			return nil, fmt.Errorf("internal credential %q defined twice", cfg.Name)
+		}
+		names[cfg.Name] = true
+		if len(cfg.TokenSHA256) == 0 && len(cfg.SigningKeys) == 0 &&
+			len(cfg.Workloads) == 0 {
+			return nil, fmt.Errorf("internal credential %q has no token digest, "+
+				"signing key or workload", cfg.Name)
+		}
+		credential := internalCredential{name: cfg.Name, expiresAt: cfg.ExpiresAt}
+		for _, digest := range cfg.TokenSHA256 {
+			decoded, err := hex.DecodeString(digest)
+			if err != nil || len(decoded) != sha256.Size {
+				return nil, fmt.Errorf("internal credential %q: invalid SHA-256 digest", cfg.Name)
+			}
+			credential.digests = append(credential.digests, [sha256.Size]byte(decoded))
+		}
+		for _, key := range cfg.SigningKeys {
+			if len(key) < minSigningKeyLength {
+				return nil, fmt.Errorf("internal credential %q: signing key shorter than %d",
+					cfg.Name, minSigningKeyLength)
+			}
+			credential.signingKeys = append(credential.signingKeys, []byte(key))
+		}
+		for _, workload := range cfg.Workloads {
+			if workload.Issuer == "" || workload.Subject == "" {
+				return nil, fmt.Errorf(
+					"internal credential %q: workload requires issuer and subject", cfg.Name)
+			}
+			if workloads[workload] {
+				return nil, fmt.Errorf("workload %q of %q mapped twice",
+					workload.Subject, workload.Issuer)
+			}
+			workloads[workload] = true
+			credential.workloads = append(credential.workloads, workload)
+		}
+		if len(cfg.Methods) > 0 {
+			credential.methods = make(map[string]bool, len(cfg.Methods))
+			for _, fullMethod := range cfg.Methods {
+				if _, ok := rpcmeta.Method(fullMethod); !ok {
+					return nil, fmt.Errorf(
+						"internal credential %q: unknown method %q", cfg.Name, fullMethod)
+				}
+				credential.methods[fullMethod] = true
+			}
+		}
+		c.credentials = append(c.credentials, credential)
+	}
+	return c, nil
+}
+
+// Authenticate returns the name of the credential token belongs to, if it may
+// call fullMethod at now. Unknown and expired tokens fail with Unauthenticated,
+// calls of methods not allowed for the credential with PermissionDenied.
+func (c *InternalCredentials) Authenticate(
+	token, fullMethod string,
+	now time.Time,
+) (string, error) {
+	digest := sha256.Sum256([]byte(token))
+	var found *internalCredential
+	// Compare every digest, so the time taken doesn't tell which one matched
+	for i := range c.credentials {
+		for _, candidate := range c.credentials[i].digests {
+			if subtle.ConstantTimeCompare(digest[:], candidate[:]) == 1 {
+				found = &c.credentials[i]
+			}
+		}
+	}
+	if found == nil {
+		return "", status.Error(codes.Unauthenticated, "invalid internal token")
+	}
+	if err := found.authorize(fullMethod, now); err != nil {
+		return "", err
+	}
+	return found.name, nil
+}
+
+// AuthenticateWorkload returns the name of the credential of the workload
+// subject of issuer, if it may call fullMethod at now. The token of the
+// workload is left to WorkloadVerifier to check.
+func (c *InternalCredentials) AuthenticateWorkload(
+	issuer, subject, fullMethod string,
+	now time.Time,
+) (string, error) {
+	workload := configs.WorkloadIdentity{Issuer: issuer, Subject: subject}
+	for i := range c.credentials {
+		if slices.Contains(c.credentials[i].workloads, workload) {
+			if err := c.credentials[i].authorize(fullMethod, now); err != nil {
+				return "", err
+			}
+			return c.credentials[i].name, nil
+		}
+	}
+	return "", status.Error(codes.PermissionDenied, "unknown workload")
+}
+
+// authorize checks that the credential may call fullMethod at now.
+func (c *internalCredential) authorize(fullMethod string, now time.Time) error {
+	if !c.expiresAt.IsZero() && !now.Before(c.expiresAt) {
+		return status.Error(codes.Unauthenticated, "internal credential has expired")
+	}
+	if c.methods != nil && !c.methods[fullMethod] {
+		return status.Error(
+			codes.PermissionDenied, "internal credential may not call this method")
+	}
+	return nil
+}
+
+func (c *InternalCredentials) lookup(name string) *internalCredential {
+	for i := range c.credentials {
+		if c.credentials[i].name == name {
+			return &c.credentials[i]
+		}
+	}
+	return nil
+}
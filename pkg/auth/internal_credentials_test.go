@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func sha256Hex(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
+
+func TestInternalCredentials(t *testing.T) {
+	now := time.Now()
+	credentials, err := NewInternalCredentials("legacy-token", []configs.InternalCredential{
+		{
+			Name:        "reporting",
+			TokenSHA256: []string{sha256Hex("old-token"), sha256Hex("new-token")},
+			Methods:     []string{user_v1_pb.UserService_GetUser_FullMethodName},
+		},
+		{
+			Name:        "retired",
+			TokenSHA256: []string{sha256Hex("retired-token")},
+			ExpiresAt:   now.Add(-time.Minute),
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewInternalCredentials failed: %v", err)
+	}
+
+	getUser := user_v1_pb.UserService_GetUser_FullMethodName
+	listUsers := user_v1_pb.UserService_ListUsers_FullMethodName
+	tests := []struct {
+		token, method string
+		wantName      string
+		wantCode      codes.Code
+	}{
+		{"legacy-token", listUsers, InternalKey, codes.OK},
+		{"old-token", getUser, "reporting", codes.OK},
+		{"new-token", getUser, "reporting", codes.OK},
+		{"new-token", listUsers, "", codes.PermissionDenied},
+		{"retired-token", getUser, "", codes.Unauthenticated},
+		{"unknown-token", getUser, "", codes.Unauthenticated},
+		{"", getUser, "", codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		name, err := credentials.Authenticate(tt.token, tt.method, now)
+		if status.Code(err) != tt.wantCode || name != tt.wantName {
+			t.Errorf("Authenticate(%q, %s) = %q, %v; want %q, %v",
+				tt.token, tt.method, name, err, tt.wantName, tt.wantCode)
+		}
+	}
+}
+
+func TestInternalCredentialsWithoutToken(t *testing.T) {
+	credentials, err := NewInternalCredentials("", nil)
+	if err != nil {
+		t.Fatalf("NewInternalCredentials failed: %v", err)
+	}
+	_, err = credentials.Authenticate("", user_v1_pb.UserService_GetUser_FullMethodName, time.Now())
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected an empty token to be refused, got %v", err)
+	}
+}
+
+func TestNewInternalCredentialsValidation(t *testing.T) {
+	valid := []string{sha256Hex("token")}
+	tests := []struct {
+		name        string
+		credentials []configs.InternalCredential
+		wantErr     string
+	}{
+		{"no name", []configs.InternalCredential{{TokenSHA256: valid}}, "without name"},
+		{"no digest", []configs.InternalCredential{{Name: "a"}}, "no token digest"},
+		{
+			"short signing key",
+			[]configs.InternalCredential{{Name: "a", SigningKeys: []string{"short"}}},
+			"signing key shorter",
+		},
+		{
+			"invalid digest",
+			[]configs.InternalCredential{{Name: "a", TokenSHA256: []string{"abc"}}},
+			"invalid SHA-256 digest",
+		},
+		{
+			"duplicate name",
+			[]configs.InternalCredential{{Name: InternalKey, TokenSHA256: valid}},
+			"defined twice",
+		},
+		{
+			"unknown method",
+			[]configs.InternalCredential{
+				{Name: "a", TokenSHA256: valid, Methods: []string{"/user.v1.UserService/Nope"}},
+			},
+			"unknown method",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewInternalCredentials("legacy-token", tt.credentials)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+type recordedUsage []string
+
+func (r *recordedUsage) Record(_ context.Context, key string) error {
+	*r = append(*r, key)
+	return nil
+}
+
+func TestInterceptorInternalCredentials(t *testing.T) {
+	credentials, err := NewInternalCredentials("", []configs.InternalCredential{
+		{Name: "reporting", TokenSHA256: []string{sha256Hex("reporting-token")}},
+	})
+	if err != nil {
+		t.Fatalf("NewInternalCredentials failed: %v", err)
+	}
+	var usage recordedUsage
+	interceptor := BuildAuthInterceptor(
+		testJWTSecret,
+		WithInternalCredentials(credentials),
+		WithUsageMeter(&usage),
+	)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"authorization", "Bearer reporting-token",
+		"x-token-type", "internal",
+	))
+	info := &grpc.UnaryServerInfo{
+		FullMethod: auth_v1_pb.AuthService_GetProviderToken_FullMethodName,
+	}
+	var caller string
+	_, err = interceptor(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+		caller, _ = InternalCredentialFromContext(ctx)
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatalf("expected the call to pass, got %v", err)
+	}
+	if caller != "reporting" {
+		t.Errorf("expected the call to carry credential reporting, got %q", caller)
+	}
+	if len(usage) != 1 || usage[0] != "reporting" {
+		t.Errorf("expected usage recorded for reporting, got %v", usage)
+	}
+}
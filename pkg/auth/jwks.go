@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// jwksRefreshInterval is how long fetched keys are used before they are
+	// fetched again
+	jwksRefreshInterval = time.Hour
+	// jwksMinFetchInterval bounds how often tokens signed with unknown keys,
+	// e.g. after a key rotation, make the keys be fetched again
+	jwksMinFetchInterval = time.Minute
+	// maxJWKSSize bounds the documents read from issuers
+	maxJWKSSize = 1 << 20
+)
+
+// jwks holds the public keys of an issuer by key ID.
+type jwks struct {
+	issuer string
+	client *http.Client
+
+	mu          sync.Mutex
+	url         string
+	keys        map[string]any
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+// newJWKS returns the keys served at url, or at the jwks_uri of the OpenID
+// configuration of issuer if url is empty.
+func newJWKS(issuer, url string, client *http.Client) *jwks {
+	return &jwks{issuer: issuer, url: url, client: client}
+}
+
+// key returns the key kid, fetching the keys if they are stale or don't have
+// it, at most once per jwksMinFetchInterval. Stale keys are kept while the
+// issuer can't be reached.
+func (k *jwks) key(ctx context.Context, kid string) (any, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := time.Now()
+	key, ok := k.keys[kid]
+	stale := now.Sub(k.fetchedAt) >= jwksRefreshInterval
+	if ok && !stale {
+		return key, nil
+	}
+	if now.Sub(k.attemptedAt) >= jwksMinFetchInterval {
+		k.attemptedAt = now
+		if err := k.fetch(ctx); err != nil {
+			slog.WarnContext(ctx, "failed to fetch workload issuer keys",
+				"issuer", k.issuer, "error", err)
+		} else {
+			k.fetchedAt = now
+		}
+		key, ok = k.keys[kid]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (k *jwks) fetch(ctx context.Context) error {
+	if k.url == "" {
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		configURL := strings.TrimSuffix(k.issuer, "/") + "/.well-known/openid-configuration"
+		if err := k.getJSON(ctx, configURL, &discovery); err != nil {
+			return err
+		}
+		if discovery.JWKSURI == "" {
+			return errors.New("openid configuration without jwks_uri")
+		}
+		k.url = discovery.JWKSURI
+	}
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := k.getJSON(ctx, k.url, &set); err != nil {
+		return err
+	}
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			slog.WarnContext(ctx, "ignoring workload issuer key", "issuer", k.issuer,
+				"kid", jwk.Kid, "error", err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	k.keys = keys
+	return nil
+}
+
+func (k *jwks) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxJWKSSize)).Decode(v)
+}
+
+// jsonWebKey is an RSA or EC public key of a JWK set (RFC 7517).
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64BigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64BigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64BigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64BigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func base64BigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
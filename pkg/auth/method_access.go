@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"fmt"
+
+	authz_v1_pb "github.com/poly-workshop/auth-portal/gen/authz/v1"
+	"github.com/poly-workshop/auth-portal/internal/rpcmeta"
+)
+
+// MethodAccess overrides the authz.v1.authz option of RPCs per deployment: it
+// opens user RPCs to anonymous callers and disables RPCs altogether. Callers
+// of opened RPCs who send a token are still authenticated, so handlers know
+// who they are. A nil MethodAccess overrides nothing.
+type MethodAccess struct {
+	public   map[string]bool
+	disabled map[string]bool
+}
+
+// NewMethodAccess returns the overrides for the RPCs named by their full method
+// names, e.g. "/auth.v1.AuthService/CheckEmailAvailable". It fails for RPCs
+// unknown to this binary, for admin and internal RPCs made public, and for
+// RPCs listed as both public and disabled.
+func NewMethodAccess(public, disabled []string) (*MethodAccess, error) {
+	access := &MethodAccess{
+		public:   make(map[string]bool, len(public)),
+		disabled: make(map[string]bool, len(disabled)),
+	}
+	for _, fullMethod := range public {
+		if _, ok := rpcmeta.Method(fullMethod); !ok {
+			return nil, fmt.Errorf("unknown public method %q", fullMethod)
+		}
+		switch level := MethodAuthz(fullMethod).AuthLevel; level {
+		case authz_v1_pb.AuthLevel_AUTH_LEVEL_PUBLIC, authz_v1_pb.AuthLevel_AUTH_LEVEL_USER:
+		default:
+			return nil, fmt.Errorf("%v method %q cannot be made public", level, fullMethod)
+		}
+		access.public[fullMethod] = true
+	}
+	for _, fullMethod := range disabled {
+		if _, ok := rpcmeta.Method(fullMethod); !ok {
+			return nil, fmt.Errorf("unknown disabled method %q", fullMethod)
+		}
+		if access.public[fullMethod] {
+			return nil, fmt.Errorf("method %q is both public and disabled", fullMethod)
+		}
+		access.disabled[fullMethod] = true
+	}
+	return access, nil
+}
+
+// Disabled reports whether calls of fullMethod are refused.
+func (a *MethodAccess) Disabled(fullMethod string) bool {
+	return a != nil && a.disabled[fullMethod]
+}
+
+// Opened reports whether fullMethod is a user RPC opened to anonymous callers.
+func (a *MethodAccess) Opened(fullMethod string) bool {
+	return a != nil && a.public[fullMethod] &&
+		MethodAuthz(fullMethod).AuthLevel != authz_v1_pb.AuthLevel_AUTH_LEVEL_PUBLIC
+}
+
+// Authz returns the authz.v1.authz option of fullMethod with the overrides
+// applied.
+func (a *MethodAccess) Authz(fullMethod string) *authz_v1_pb.MethodAuthz {
+	authz := MethodAuthz(fullMethod)
+	if a != nil && a.public[fullMethod] {
+		return &authz_v1_pb.MethodAuthz{
+			AuthLevel:          authz_v1_pb.AuthLevel_AUTH_LEVEL_PUBLIC,
+			RequiredPermission: authz.RequiredPermission,
+		}
+	}
+	return authz
+}
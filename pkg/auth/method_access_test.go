@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestNewMethodAccessValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		public   []string
+		disabled []string
+		wantErr  string
+	}{
+		{"none", nil, nil, ""},
+		{
+			"valid",
+			[]string{user_v1_pb.UserService_GetCurrentUser_FullMethodName},
+			[]string{auth_v1_pb.AuthService_CheckEmailAvailable_FullMethodName},
+			"",
+		},
+		{"unknown public", []string{"/user.v1.UserService/Nope"}, nil, "unknown public method"},
+		{"unknown disabled", nil, []string{"UserService/ListUsers"}, "unknown disabled method"},
+		{
+			"admin made public",
+			[]string{user_v1_pb.UserService_ListUsers_FullMethodName},
+			nil,
+			"cannot be made public",
+		},
+		{
+			"internal made public",
+			[]string{auth_v1_pb.AuthService_GetProviderToken_FullMethodName},
+			nil,
+			"cannot be made public",
+		},
+		{
+			"public and disabled",
+			[]string{user_v1_pb.UserService_GetCurrentUser_FullMethodName},
+			[]string{user_v1_pb.UserService_GetCurrentUser_FullMethodName},
+			"both public and disabled",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMethodAccess(tt.public, tt.disabled)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("NewMethodAccess failed: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestInterceptorMethodAccess(t *testing.T) {
+	access, err := NewMethodAccess(
+		[]string{user_v1_pb.UserService_GetCurrentUser_FullMethodName},
+		[]string{auth_v1_pb.AuthService_CheckEmailAvailable_FullMethodName},
+	)
+	if err != nil {
+		t.Fatalf("NewMethodAccess failed: %v", err)
+	}
+	interceptor := BuildAuthInterceptor(testJWTSecret, WithMethodAccess(access))
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+
+	tests := []struct {
+		method   string
+		expected codes.Code
+	}{
+		{user_v1_pb.UserService_GetCurrentUser_FullMethodName, codes.OK},
+		{auth_v1_pb.AuthService_CheckEmailAvailable_FullMethodName, codes.Unimplemented},
+		{auth_v1_pb.AuthService_GetPublicConfig_FullMethodName, codes.OK},
+		{user_v1_pb.UserService_ChangePassword_FullMethodName, codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		info := &grpc.UnaryServerInfo{FullMethod: tt.method}
+		_, err := interceptor(context.Background(), nil, info, handler)
+		if status.Code(err) != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.method, tt.expected, err)
+		}
+	}
+
+	// Callers of opened RPCs who send a token are known to the handler
+	info := &grpc.UnaryServerInfo{FullMethod: user_v1_pb.UserService_GetCurrentUser_FullMethodName}
+	var userID string
+	whoami := func(ctx context.Context, req any) (any, error) {
+		if userInfo, ok := ctx.Value(ContextKeyUserInfo).(*UserInfo); ok {
+			userID = userInfo.UserID
+		}
+		return "ok", nil
+	}
+	token := signTestToken(t, model.UserRoleUser, 0, "")
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs("authorization", "Bearer "+token))
+	if _, err := interceptor(ctx, nil, info, whoami); err != nil || userID != "user-1" {
+		t.Errorf("expected the caller to be authenticated, got %q (%v)", userID, err)
+	}
+	ctx = metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs("authorization", "Bearer invalid"))
+	if _, err := interceptor(ctx, nil, info, whoami); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected an invalid token to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"strings"
+	"sync"
+
+	authz_v1_pb "github.com/poly-workshop/auth-portal/gen/authz/v1"
+	"github.com/poly-workshop/auth-portal/internal/rpcmeta"
+	"google.golang.org/protobuf/proto"
+)
+
+// healthServicePrefix prefixes the methods of the gRPC health service, which
+// load balancers call without credentials.
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// methodAuthzCache maps full method names to their *authz_v1_pb.MethodAuthz.
+var methodAuthzCache sync.Map
+
+// MethodAuthz returns the authz.v1.authz option of the RPC named by fullMethod,
+// e.g. "/user.v1.UserService/GetUser". The gRPC health service is public; other
+// RPCs without the option, or unknown to this binary, require a user token.
+func MethodAuthz(fullMethod string) *authz_v1_pb.MethodAuthz {
+	if cached, ok := methodAuthzCache.Load(fullMethod); ok {
+		return cached.(*authz_v1_pb.MethodAuthz)
+	}
+	authz := lookupMethodAuthz(fullMethod)
+	methodAuthzCache.Store(fullMethod, authz)
+	return authz
+}
+
+func lookupMethodAuthz(fullMethod string) *authz_v1_pb.MethodAuthz {
+	if strings.HasPrefix(fullMethod, healthServicePrefix) {
+		return &authz_v1_pb.MethodAuthz{AuthLevel: authz_v1_pb.AuthLevel_AUTH_LEVEL_PUBLIC}
+	}
+	defaultAuthz := &authz_v1_pb.MethodAuthz{AuthLevel: authz_v1_pb.AuthLevel_AUTH_LEVEL_USER}
+	method, ok := rpcmeta.Method(fullMethod)
+	if !ok {
+		return defaultAuthz
+	}
+	ext := proto.GetExtension(method.Options(), authz_v1_pb.E_Authz)
+	authz, ok := ext.(*authz_v1_pb.MethodAuthz)
+	if !ok || authz == nil {
+		return defaultAuthz
+	}
+	if authz.AuthLevel == authz_v1_pb.AuthLevel_AUTH_LEVEL_UNSPECIFIED {
+		authz = &authz_v1_pb.MethodAuthz{
+			AuthLevel:          authz_v1_pb.AuthLevel_AUTH_LEVEL_USER,
+			RequiredPermission: authz.RequiredPermission,
+		}
+	}
+	return authz
+}
+
+// requiredPermission is the policy object a call of fullMethod is checked against.
+func requiredPermission(fullMethod string, authz *authz_v1_pb.MethodAuthz) string {
+	if authz.RequiredPermission != "" {
+		return authz.RequiredPermission
+	}
+	return policyObject(fullMethod)
+}
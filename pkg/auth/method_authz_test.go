@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"fmt"
+	"testing"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	authz_v1_pb "github.com/poly-workshop/auth-portal/gen/authz/v1"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestMethodAuthz(t *testing.T) {
+	public := authz_v1_pb.AuthLevel_AUTH_LEVEL_PUBLIC
+	user := authz_v1_pb.AuthLevel_AUTH_LEVEL_USER
+	admin := authz_v1_pb.AuthLevel_AUTH_LEVEL_ADMIN
+	internal := authz_v1_pb.AuthLevel_AUTH_LEVEL_INTERNAL
+	tests := []struct {
+		method   string
+		expected authz_v1_pb.AuthLevel
+	}{
+		{auth_v1_pb.AuthService_GetPublicConfig_FullMethodName, public},
+		{user_v1_pb.UserService_GetCurrentUser_FullMethodName, user},
+		{user_v1_pb.UserService_ListUsers_FullMethodName, admin},
+		{auth_v1_pb.AuthService_GetProviderToken_FullMethodName, internal},
+		{"/grpc.health.v1.Health/Watch", public},
+		{"/unknown.v1.Service/Method", user},
+		{"malformed", user},
+	}
+	for _, tt := range tests {
+		if got := MethodAuthz(tt.method).AuthLevel; got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.method, tt.expected, got)
+		}
+	}
+}
+
+// TestMethodAuthzMatchesPolicy keeps the RPC annotations and the RBAC policy in
+// sync: every RPC declares its level, and the policy grants exactly what the
+// levels promise.
+func TestMethodAuthzMatchesPolicy(t *testing.T) {
+	enforcer, err := NewEnforcer()
+	if err != nil {
+		t.Fatalf("Failed to create enforcer: %v", err)
+	}
+	files := []protoreflect.FileDescriptor{
+		auth_v1_pb.File_auth_v1_auth_proto,
+		user_v1_pb.File_user_v1_user_proto,
+	}
+	for _, file := range files {
+		for i := range file.Services().Len() {
+			service := file.Services().Get(i)
+			for j := range service.Methods().Len() {
+				method := service.Methods().Get(j)
+				fullMethod := fmt.Sprintf("/%s/%s", service.FullName(), method.Name())
+				t.Run(fullMethod, func(t *testing.T) {
+					if !proto.HasExtension(method.Options(), authz_v1_pb.E_Authz) {
+						t.Fatal("missing (authz.v1.authz) option")
+					}
+					authz := MethodAuthz(fullMethod)
+					permission := requiredPermission(fullMethod, authz)
+					userAllowed, _ := CheckPermission(enforcer, "user", permission)
+					adminAllowed, _ := CheckPermission(enforcer, "admin", permission)
+
+					switch authz.AuthLevel {
+					case authz_v1_pb.AuthLevel_AUTH_LEVEL_PUBLIC,
+						authz_v1_pb.AuthLevel_AUTH_LEVEL_INTERNAL:
+						if userAllowed || adminAllowed {
+							t.Errorf("%v RPC should not be in the policy: %s",
+								authz.AuthLevel, permission)
+						}
+					case authz_v1_pb.AuthLevel_AUTH_LEVEL_USER:
+						if !userAllowed {
+							t.Errorf("user RPC is not granted to users: %s", permission)
+						}
+					case authz_v1_pb.AuthLevel_AUTH_LEVEL_ADMIN:
+						if userAllowed || !adminAllowed {
+							t.Errorf("admin RPC must be granted to admins only: %s", permission)
+						}
+					default:
+						t.Errorf("unexpected auth level %v", authz.AuthLevel)
+					}
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/casbin/casbin/v2"
+)
+
+// orgPolicyType is the suffix of the request, policy, role and matcher
+// definitions of the org admin model (r2, p2, g2 and m2), whose domains are
+// organizations.
+const orgPolicyType = "2"
+
+// AdministeredOrgs returns the organizations in which userID is granted the
+// policy object permission as an org admin, nil if none. Users are made org
+// admins of an organization by grouping rules such as
+//
+//	g2, <user id>, org_admin, acme
+//
+// and org admins are granted RPCs by rules such as
+//
+//	p2, org_admin, /UserService/ListUsers
+func AdministeredOrgs(
+	enforcer *casbin.SyncedEnforcer,
+	userID, permission string,
+) ([]string, error) {
+	assignments, err := enforcer.GetFilteredNamedGroupingPolicy("g"+orgPolicyType, 0, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get org roles: %w", err)
+	}
+	var orgs []string
+	for _, assignment := range assignments {
+		if len(assignment) < 3 || slices.Contains(orgs, assignment[2]) {
+			continue
+		}
+		allowed, err := enforcer.Enforce(
+			casbin.NewEnforceContext(orgPolicyType),
+			userID,
+			assignment[2],
+			permission,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to enforce org policy: %w", err)
+		}
+		if allowed {
+			orgs = append(orgs, assignment[2])
+		}
+	}
+	slices.Sort(orgs)
+	return orgs, nil
+}
+
+// IsOrgAdmin reports whether userID is an org admin of any organization.
+func IsOrgAdmin(enforcer *casbin.SyncedEnforcer, userID string) (bool, error) {
+	assignments, err := enforcer.GetFilteredNamedGroupingPolicy("g"+orgPolicyType, 0, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get org roles: %w", err)
+	}
+	return len(assignments) > 0, nil
+}
+
+// OrgScopeFromContext returns the organizations a call is restricted to, false
+// if it is not restricted. Calls of org admins are restricted to the
+// organizations they administer.
+func OrgScopeFromContext(ctx context.Context) ([]string, bool) {
+	orgs, ok := ctx.Value(ContextKeyOrgScope).([]string)
+	return orgs, ok
+}
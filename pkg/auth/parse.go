@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"slices"
+
 	"github.com/golang-jwt/jwt/v5"
 	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
 	"github.com/poly-workshop/auth-portal/internal/utils"
@@ -9,26 +11,50 @@ import (
 type UserInfo struct {
 	UserID string
 	Role   user_v1_pb.UserRole
+	// MustChangePassword restricts the caller to the ChangePassword RPC
+	MustChangePassword bool
+	// RoleVersion is the user's role version at the time the token was issued
+	RoleVersion int64
+	// SessionRef references the login session the token was issued for
+	SessionRef string
+	// Scopes are the policy objects (or their hashes) granted when the token was issued
+	Scopes []string
+	// Entitlements are the features of the user's plan when the token was issued
+	Entitlements []string
+}
+
+// HasEntitlement reports whether the token entitles the user to name, so
+// product services can gate features on the plan without calling the portal.
+func (u *UserInfo) HasEntitlement(name string) bool {
+	return slices.Contains(u.Entitlements, name)
 }
 
-func ParseUserToken(tokenString, secret string) (*UserInfo, error) {
-	claims, err := utils.ValidateUserToken(tokenString, secret)
+func ParseUserToken(
+	tokenString, secret string,
+	opts ...utils.ValidationOption,
+) (*UserInfo, error) {
+	claims, err := utils.ValidateUserToken(tokenString, secret, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	userID, ok := claims.MapClaims["user_id"].(string)
+	userID, ok := claims.MapClaims[utils.ClaimUserID].(string)
 	if !ok {
 		return nil, jwt.ErrTokenInvalidClaims
 	}
 
-	role, ok := claims.MapClaims["user_role"].(float64)
+	role, ok := claims.MapClaims[utils.ClaimUserRole].(float64)
 	if !ok {
 		return nil, jwt.ErrTokenInvalidClaims
 	}
 
 	return &UserInfo{
-		UserID: userID,
-		Role:   user_v1_pb.UserRole(role),
+		UserID:             userID,
+		Role:               user_v1_pb.UserRole(role),
+		MustChangePassword: claims.MustChangePassword(),
+		RoleVersion:        claims.RoleVersion(),
+		SessionRef:         claims.SessionRef(),
+		Scopes:             claims.Scopes(),
+		Entitlements:       claims.Entitlements(),
 	}, nil
 }
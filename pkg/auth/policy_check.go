@@ -0,0 +1,220 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	authz_v1_pb "github.com/poly-workshop/auth-portal/gen/authz/v1"
+	"github.com/poly-workshop/auth-portal/internal/model"
+	"github.com/poly-workshop/auth-portal/internal/rpcmeta"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// PolicySet is an RBAC model and policy loaded to be checked before it is
+// deployed, e.g. by the tests of a policy repository: Assert runs table-driven
+// expectations against it and Lint finds rules that can't work as intended.
+type PolicySet struct {
+	enforcer *casbin.SyncedEnforcer
+}
+
+// LoadPolicySet loads the model and policy files, e.g. configs/rbac_model.conf
+// and a changed configs/rbac_policy.csv.
+func LoadPolicySet(modelPath, policyPath string) (*PolicySet, error) {
+	enforcer, err := casbin.NewSyncedEnforcer(modelPath, policyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load policy: %w", err)
+	}
+	return &PolicySet{enforcer: enforcer}, nil
+}
+
+// NewPolicySet checks the policy of enforcer, e.g. of NewEnforcer.
+func NewPolicySet(enforcer *casbin.SyncedEnforcer) *PolicySet {
+	return &PolicySet{enforcer: enforcer}
+}
+
+// Allowed reports whether a user of role may call method with a user token as
+// the auth interceptor decides it, i.e. including the authz.v1.authz level of
+// the RPC. method is a full gRPC method name such as
+// "/user.v1.UserService/GetUser", or a policy object such as
+// "/UserService/GetUser" to check the policy alone.
+func (p *PolicySet) Allowed(role, method string) (bool, error) {
+	service, _, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	if !strings.Contains(service, ".") {
+		return CheckPermission(p.enforcer, role, method)
+	}
+	if _, ok := rpcmeta.Method(method); !ok {
+		return false, fmt.Errorf("unknown method %q", method)
+	}
+	authz := MethodAuthz(method)
+	switch authz.AuthLevel {
+	case authz_v1_pb.AuthLevel_AUTH_LEVEL_PUBLIC:
+		return true, nil
+	case authz_v1_pb.AuthLevel_AUTH_LEVEL_INTERNAL:
+		return false, nil
+	}
+	allowed, err := CheckPermission(p.enforcer, role, requiredPermission(method, authz))
+	if err != nil || !allowed {
+		return false, err
+	}
+	return authz.AuthLevel != authz_v1_pb.AuthLevel_AUTH_LEVEL_ADMIN ||
+		role == string(model.UserRoleAdmin), nil
+}
+
+// PolicyAssertion expects a user of Role to be allowed, or not, to call Method
+// (see PolicySet.Allowed).
+type PolicyAssertion struct {
+	Role    string
+	Method  string
+	Allowed bool
+}
+
+// PolicyAssertionFailure is an assertion the policy doesn't meet.
+type PolicyAssertionFailure struct {
+	PolicyAssertion
+	// Err is why the assertion couldn't be checked, if it couldn't
+	Err error
+}
+
+func (f PolicyAssertionFailure) Error() string {
+	if f.Err != nil {
+		return fmt.Sprintf("%s %s: %v", f.Role, f.Method, f.Err)
+	}
+	if f.Allowed {
+		return fmt.Sprintf("%s should be allowed to call %s but is denied", f.Role, f.Method)
+	}
+	return fmt.Sprintf("%s should be denied %s but is allowed", f.Role, f.Method)
+}
+
+// Assert checks the assertions and returns those the policy doesn't meet.
+func (p *PolicySet) Assert(assertions []PolicyAssertion) []PolicyAssertionFailure {
+	var failures []PolicyAssertionFailure
+	for _, assertion := range assertions {
+		allowed, err := p.Allowed(assertion.Role, assertion.Method)
+		if err != nil || allowed != assertion.Allowed {
+			failures = append(failures, PolicyAssertionFailure{
+				PolicyAssertion: assertion,
+				Err:             err,
+			})
+		}
+	}
+	return failures
+}
+
+// Kinds of PolicyIssue
+const (
+	// PolicyIssueUnreachable is a rule that is never checked: its object is no
+	// RPC's permission or FieldObject, or that of a public or internal RPC
+	PolicyIssueUnreachable = "unreachable"
+	// PolicyIssueConflict is a rule granting an admin RPC to another role,
+	// which the interceptor refuses regardless
+	PolicyIssueConflict = "conflict"
+	// PolicyIssueRedundant is a rule granting a role what it inherits anyway
+	PolicyIssueRedundant = "redundant"
+	// PolicyIssueUngranted is a user or admin RPC no role is granted, so
+	// nobody can call it with a user token
+	PolicyIssueUngranted = "ungranted"
+)
+
+// PolicyIssue is a problem Lint found.
+type PolicyIssue struct {
+	// Kind is one of the PolicyIssue constants
+	Kind string
+	// Role and Object are those of the rule, or the object of an ungranted RPC
+	Role   string
+	Object string
+}
+
+func (i PolicyIssue) String() string {
+	if i.Role == "" {
+		return fmt.Sprintf("%s: %s", i.Kind, i.Object)
+	}
+	return fmt.Sprintf("%s: p, %s, %s", i.Kind, i.Role, i.Object)
+}
+
+// Lint compares the policy with the RPCs of files, e.g.
+// user_v1_pb.File_user_v1_user_proto, and returns the rules that are
+// unreachable, conflicting or redundant, and the RPCs nobody is granted.
+func (p *PolicySet) Lint(files ...protoreflect.FileDescriptor) ([]PolicyIssue, error) {
+	levels := make(map[string]authz_v1_pb.AuthLevel)
+	inputs := make(map[string]protoreflect.MessageDescriptor)
+	for _, file := range files {
+		for i := range file.Services().Len() {
+			service := file.Services().Get(i)
+			for j := range service.Methods().Len() {
+				method := service.Methods().Get(j)
+				fullMethod := fmt.Sprintf("/%s/%s", service.FullName(), method.Name())
+				authz := MethodAuthz(fullMethod)
+				permission := requiredPermission(fullMethod, authz)
+				levels[permission] = authz.AuthLevel
+				inputs[permission] = method.Input()
+			}
+		}
+	}
+
+	rules, err := p.enforcer.GetPolicy()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy: %w", err)
+	}
+	var issues []PolicyIssue
+	granted := make(map[string]bool)
+	for _, rule := range rules {
+		if len(rule) < 2 {
+			continue
+		}
+		role, object := rule[0], rule[1]
+		granted[object] = true
+		// Field objects are reachable like their RPC, if it has the field
+		base, field, isField := strings.Cut(object, fieldObjectSeparator)
+		switch level, ok := levels[base]; {
+		case !ok,
+			level == authz_v1_pb.AuthLevel_AUTH_LEVEL_PUBLIC,
+			level == authz_v1_pb.AuthLevel_AUTH_LEVEL_INTERNAL,
+			isField && inputs[base].Fields().ByName(protoreflect.Name(field)) == nil:
+			issues = append(issues, PolicyIssue{PolicyIssueUnreachable, role, object})
+			continue
+		case level == authz_v1_pb.AuthLevel_AUTH_LEVEL_ADMIN && role != string(model.UserRoleAdmin):
+			issues = append(issues, PolicyIssue{PolicyIssueConflict, role, object})
+		}
+		inherited, err := p.inherits(role, object)
+		if err != nil {
+			return nil, err
+		}
+		if inherited {
+			issues = append(issues, PolicyIssue{PolicyIssueRedundant, role, object})
+		}
+	}
+
+	var ungranted []string
+	for object, level := range levels {
+		if (level == authz_v1_pb.AuthLevel_AUTH_LEVEL_USER ||
+			level == authz_v1_pb.AuthLevel_AUTH_LEVEL_ADMIN) && !granted[object] {
+			ungranted = append(ungranted, object)
+		}
+	}
+	slices.Sort(ungranted)
+	for _, object := range ungranted {
+		issues = append(issues, PolicyIssue{Kind: PolicyIssueUngranted, Object: object})
+	}
+	return issues, nil
+}
+
+// inherits reports whether role is granted object through one of the roles
+// it inherits from.
+func (p *PolicySet) inherits(role, object string) (bool, error) {
+	parents, err := p.enforcer.GetImplicitRolesForUser(role)
+	if err != nil {
+		return false, fmt.Errorf("failed to get roles: %w", err)
+	}
+	for _, parent := range parents {
+		allowed, err := CheckPermission(p.enforcer, parent, object)
+		if err != nil {
+			return false, err
+		}
+		if allowed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	auth_v1_pb "github.com/poly-workshop/auth-portal/gen/auth/v1"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+)
+
+func TestPolicySetAssert(t *testing.T) {
+	enforcer, err := NewEnforcer()
+	if err != nil {
+		t.Fatalf("Failed to create enforcer: %v", err)
+	}
+	policy := NewPolicySet(enforcer)
+	failures := policy.Assert([]PolicyAssertion{
+		{"user", user_v1_pb.UserService_GetCurrentUser_FullMethodName, true},
+		{"user", user_v1_pb.UserService_ListUsers_FullMethodName, false},
+		{"admin", user_v1_pb.UserService_ListUsers_FullMethodName, true},
+		{"user", auth_v1_pb.AuthService_GetPublicConfig_FullMethodName, true},
+		{"admin", auth_v1_pb.AuthService_GetProviderToken_FullMethodName, false},
+		{"user", "/UserService/GetCurrentUser", true},
+	})
+	for _, failure := range failures {
+		t.Error(failure)
+	}
+
+	failures = policy.Assert([]PolicyAssertion{
+		{"user", user_v1_pb.UserService_DeleteUser_FullMethodName, true},
+		{"user", "/user.v1.UserService/Nope", false},
+	})
+	if len(failures) != 2 {
+		t.Fatalf("expected two failures, got %v", failures)
+	}
+	if got := failures[0].Error(); !strings.Contains(got, "should be allowed") {
+		t.Errorf("unexpected failure %q", got)
+	}
+	if failures[1].Err == nil {
+		t.Errorf("expected an unknown method to fail with an error, got %v", failures[1])
+	}
+}
+
+// TestPolicySetLint keeps configs/rbac_policy.csv free of lint issues.
+func TestPolicySetLint(t *testing.T) {
+	enforcer, err := NewEnforcer()
+	if err != nil {
+		t.Fatalf("Failed to create enforcer: %v", err)
+	}
+	issues, err := NewPolicySet(enforcer).Lint(
+		auth_v1_pb.File_auth_v1_auth_proto,
+		user_v1_pb.File_user_v1_user_proto,
+	)
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	for _, issue := range issues {
+		t.Error(issue)
+	}
+}
+
+func TestPolicySetLintIssues(t *testing.T) {
+	policyPath := filepath.Join(t.TempDir(), "policy.csv")
+	policy := strings.Join([]string{
+		"p, user, /UserService/GetCurrentUser",
+		"p, user, /UserService/ListUsers",
+		"p, user, /AuthService/GetPublicConfig",
+		"p, user, /UserService/Nope",
+		"p, support, /UserService/GetCurrentUser",
+		"p, admin, /UserService/UpdateUser#name",
+		"p, admin, /UserService/UpdateUser#nope",
+		"g, support, user",
+	}, "\n")
+	if err := os.WriteFile(policyPath, []byte(policy), 0o600); err != nil {
+		t.Fatalf("Failed to write policy: %v", err)
+	}
+	set, err := LoadPolicySet(filepath.Join("..", "..", "configs", "rbac_model.conf"), policyPath)
+	if err != nil {
+		t.Fatalf("LoadPolicySet failed: %v", err)
+	}
+	issues, err := set.Lint(
+		auth_v1_pb.File_auth_v1_auth_proto,
+		user_v1_pb.File_user_v1_user_proto,
+	)
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+
+	for _, want := range []PolicyIssue{
+		{PolicyIssueConflict, "user", "/UserService/ListUsers"},
+		{PolicyIssueUnreachable, "user", "/AuthService/GetPublicConfig"},
+		{PolicyIssueUnreachable, "user", "/UserService/Nope"},
+		{PolicyIssueRedundant, "support", "/UserService/GetCurrentUser"},
+		{PolicyIssueUnreachable, "admin", "/UserService/UpdateUser#nope"},
+		{Kind: PolicyIssueUngranted, Object: "/UserService/CreateUser"},
+	} {
+		if !slices.Contains(issues, want) {
+			t.Errorf("expected issue %v in %v", want, issues)
+		}
+	}
+	for _, issue := range issues {
+		if issue.Object == "/UserService/GetCurrentUser" && issue.Role == "user" ||
+			issue.Object == "/UserService/UpdateUser#name" {
+			t.Errorf("unexpected issue %v", issue)
+		}
+	}
+}
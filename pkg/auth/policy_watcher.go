@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// PolicyWatcher propagates policy changes between server instances. It is
+// Casbin's persist.Watcher, so the watchers of the Casbin ecosystem fit as well.
+type PolicyWatcher interface {
+	persist.Watcher
+}
+
+var (
+	policyWatcherMu sync.Mutex
+	policyWatcher   PolicyWatcher
+)
+
+// WatchPolicy makes the shared enforcer reload its policy whenever watcher
+// reports a change made by another instance.
+func WatchPolicy(watcher PolicyWatcher) error {
+	enforcer, err := SharedEnforcer()
+	if err != nil {
+		return err
+	}
+	if err := enforcer.SetWatcher(watcher); err != nil {
+		return fmt.Errorf("failed to set policy watcher: %w", err)
+	}
+	// Replace Casbin's default callback, which reloads without the enforcer's lock
+	err = watcher.SetUpdateCallback(func(string) {
+		if err := enforcer.LoadPolicy(); err != nil {
+			slog.Error("failed to reload policy after update", "error", err)
+			return
+		}
+		slog.Info("policy reloaded after update by another instance")
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set policy watcher callback: %w", err)
+	}
+
+	policyWatcherMu.Lock()
+	policyWatcher = watcher
+	policyWatcherMu.Unlock()
+	return nil
+}
+
+// ReloadPolicy reloads the shared enforcer's policy from its source and tells
+// the other instances to do the same.
+func ReloadPolicy() error {
+	enforcer, err := SharedEnforcer()
+	if err != nil {
+		return err
+	}
+	if err := enforcer.LoadPolicy(); err != nil {
+		return fmt.Errorf("failed to reload policy: %w", err)
+	}
+
+	policyWatcherMu.Lock()
+	watcher := policyWatcher
+	policyWatcherMu.Unlock()
+	if watcher == nil {
+		return nil
+	}
+	if err := watcher.Update(); err != nil {
+		return fmt.Errorf("failed to notify other instances: %w", err)
+	}
+	return nil
+}
+
+// policyUpdatesChannel is the Redis channel policy updates are published on.
+const policyUpdatesChannel = "rbac_policy_updates"
+
+// RedisWatcher is a PolicyWatcher using Redis pub/sub. Each instance ignores
+// its own updates, as it has applied them already.
+type RedisWatcher struct {
+	rdb      redis.UniversalClient
+	pubsub   *redis.PubSub
+	instance string
+
+	mu       sync.Mutex
+	callback func(string)
+}
+
+// NewRedisWatcher subscribes to policy updates; updates published before it
+// returns are not received.
+func NewRedisWatcher(ctx context.Context, rdb redis.UniversalClient) (*RedisWatcher, error) {
+	pubsub := rdb.Subscribe(ctx, policyUpdatesChannel)
+	// Wait for the subscription to be confirmed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to policy updates: %w", err)
+	}
+
+	w := &RedisWatcher{rdb: rdb, pubsub: pubsub, instance: uuid.NewString()}
+	go w.listen()
+	return w, nil
+}
+
+func (w *RedisWatcher) listen() {
+	for msg := range w.pubsub.Channel() {
+		if msg.Payload == w.instance {
+			continue
+		}
+		w.mu.Lock()
+		callback := w.callback
+		w.mu.Unlock()
+		if callback != nil {
+			callback(msg.Payload)
+		}
+	}
+}
+
+// SetUpdateCallback sets the function called when another instance changed
+// the policy.
+func (w *RedisWatcher) SetUpdateCallback(callback func(string)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callback = callback
+	return nil
+}
+
+// Update tells the other instances that the policy has changed.
+func (w *RedisWatcher) Update() error {
+	return w.rdb.Publish(context.Background(), policyUpdatesChannel, w.instance).Err()
+}
+
+// Close stops listening for updates.
+func (w *RedisWatcher) Close() {
+	_ = w.pubsub.Close()
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/internal/testutil"
+)
+
+func TestRedisWatcher(t *testing.T) {
+	rdb, _ := testutil.NewRedis(t)
+	ctx := context.Background()
+
+	newWatcher := func() (*RedisWatcher, chan string) {
+		w, err := NewRedisWatcher(ctx, rdb)
+		if err != nil {
+			t.Fatalf("NewRedisWatcher failed: %v", err)
+		}
+		t.Cleanup(w.Close)
+		updates := make(chan string, 1)
+		_ = w.SetUpdateCallback(func(msg string) { updates <- msg })
+		return w, updates
+	}
+	first, firstUpdates := newWatcher()
+	_, secondUpdates := newWatcher()
+
+	if err := first.Update(); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	select {
+	case <-secondUpdates:
+	case <-time.After(time.Second):
+		t.Fatal("expected the other instance to be notified")
+	}
+	select {
+	case <-firstUpdates:
+		t.Error("expected an instance to ignore its own update")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSharedEnforcer(t *testing.T) {
+	first, err := SharedEnforcer()
+	if err != nil {
+		t.Fatalf("SharedEnforcer failed: %v", err)
+	}
+	second, _ := SharedEnforcer()
+	if first != second {
+		t.Error("expected the same enforcer on every call")
+	}
+	if err := ReloadPolicy(); err != nil {
+		t.Errorf("ReloadPolicy failed: %v", err)
+	}
+	if allowed, _ := CheckPermission(first, "admin", "/UserService/ListUsers"); !allowed {
+		t.Error("expected the reloaded policy to grant admins ListUsers")
+	}
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+)
+
+// scopeHashLength is the number of hex characters kept from a scope hash.
+const scopeHashLength = 8
+
+// RoleScopes returns the policy objects granted to role, including those
+// inherited from other roles, sorted and without duplicates.
+func RoleScopes(enforcer *casbin.SyncedEnforcer, role string) ([]string, error) {
+	permissions, err := enforcer.GetImplicitPermissionsForUser(role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+
+	scopes := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		if len(permission) > 1 {
+			scopes = append(scopes, permission[1])
+		}
+	}
+	slices.Sort(scopes)
+	return slices.Compact(scopes), nil
+}
+
+// ScopeClaim builds the space separated scope claim for role; with hashed the
+// policy objects are replaced by their ScopeHash to keep tokens small.
+func ScopeClaim(enforcer *casbin.SyncedEnforcer, role string, hashed bool) (string, error) {
+	scopes, err := RoleScopes(enforcer, role)
+	if err != nil {
+		return "", err
+	}
+	if hashed {
+		for i, scope := range scopes {
+			scopes[i] = ScopeHash(scope)
+		}
+	}
+	return strings.Join(scopes, " "), nil
+}
+
+// ScopeHash returns the short hash representing a policy object such as
+// "/UserService/GetUser" in hashed scope claims.
+func ScopeHash(scope string) string {
+	sum := sha256.Sum256([]byte(scope))
+	return hex.EncodeToString(sum[:])[:scopeHashLength]
+}
+
+// HasScope reports whether the token grants the policy object, in either the
+// plain or the hashed form. Downstream services can use it for coarse
+// authorization without calling back to the portal.
+func (u *UserInfo) HasScope(object string) bool {
+	hash := ScopeHash(object)
+	for _, scope := range u.Scopes {
+		if scope == object || scope == hash {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRoleScopes(t *testing.T) {
+	enforcer, err := NewEnforcer()
+	if err != nil {
+		t.Fatalf("Failed to create enforcer: %v", err)
+	}
+
+	userScopes, err := RoleScopes(enforcer, "user")
+	if err != nil {
+		t.Fatalf("RoleScopes failed: %v", err)
+	}
+	if !slices.Contains(userScopes, "/UserService/GetCurrentUser") {
+		t.Errorf("Expected user scopes to contain GetCurrentUser, got %v", userScopes)
+	}
+	if slices.Contains(userScopes, "/UserService/CreateUser") {
+		t.Errorf("Expected user scopes not to contain CreateUser, got %v", userScopes)
+	}
+
+	adminScopes, err := RoleScopes(enforcer, "admin")
+	if err != nil {
+		t.Fatalf("RoleScopes failed: %v", err)
+	}
+	// Admins inherit the user role
+	for _, scope := range []string{"/UserService/CreateUser", "/UserService/ChangePassword"} {
+		if !slices.Contains(adminScopes, scope) {
+			t.Errorf("Expected admin scopes to contain %s, got %v", scope, adminScopes)
+		}
+	}
+	unique := slices.Compact(slices.Clone(adminScopes))
+	if !slices.IsSorted(adminScopes) || len(unique) != len(adminScopes) {
+		t.Errorf("Expected sorted scopes without duplicates, got %v", adminScopes)
+	}
+}
+
+func TestScopeClaim(t *testing.T) {
+	enforcer, err := NewEnforcer()
+	if err != nil {
+		t.Fatalf("Failed to create enforcer: %v", err)
+	}
+
+	plain, err := ScopeClaim(enforcer, "user", false)
+	if err != nil {
+		t.Fatalf("ScopeClaim failed: %v", err)
+	}
+	hashed, err := ScopeClaim(enforcer, "user", true)
+	if err != nil {
+		t.Fatalf("ScopeClaim failed: %v", err)
+	}
+	if len(hashed) >= len(plain) {
+		t.Errorf("Expected hashed claim to be smaller, got %d >= %d bytes", len(hashed), len(plain))
+	}
+
+	for _, claim := range []string{plain, hashed} {
+		info := &UserInfo{Scopes: strings.Fields(claim)}
+		if !info.HasScope("/UserService/GetCurrentUser") {
+			t.Errorf("Expected claim %q to grant GetCurrentUser", claim)
+		}
+		if info.HasScope("/UserService/DeleteUser") {
+			t.Errorf("Expected claim %q not to grant DeleteUser", claim)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SessionChecker reports whether the session a token is bound to still exists.
+type SessionChecker interface {
+	Active(ctx context.Context, sessionRef string) (bool, error)
+}
+
+// maxSessionCacheEntries bounds the cache; expired entries are pruned once it is reached.
+const maxSessionCacheEntries = 10000
+
+type sessionCacheEntry struct {
+	active    bool
+	expiresAt time.Time
+}
+
+// sessionCache remembers session checks for a short time, so bursts of calls
+// with the same token cost a single lookup. Revocations take effect after at
+// most one cache TTL.
+type sessionCache struct {
+	checker SessionChecker
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	entries map[string]sessionCacheEntry
+}
+
+func newSessionCache(checker SessionChecker, ttl time.Duration) *sessionCache {
+	return &sessionCache{
+		checker: checker,
+		ttl:     ttl,
+		entries: make(map[string]sessionCacheEntry),
+	}
+}
+
+func (c *sessionCache) Active(ctx context.Context, sessionRef string) (bool, error) {
+	now := time.Now()
+	c.mu.Lock()
+	entry, ok := c.entries[sessionRef]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.active, nil
+	}
+
+	active, err := c.checker.Active(ctx, sessionRef)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxSessionCacheEntries {
+		for ref, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, ref)
+			}
+		}
+		if len(c.entries) >= maxSessionCacheEntries {
+			clear(c.entries)
+		}
+	}
+	c.entries[sessionRef] = sessionCacheEntry{active: active, expiresAt: now.Add(c.ttl)}
+	return active, nil
+}
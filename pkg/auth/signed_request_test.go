@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/poly-workshop/auth-portal/configs"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testSigningKey     = "0123456789abcdef0123456789abcdef"
+	testNextSigningKey = "fedcba9876543210fedcba9876543210"
+)
+
+type memoryNonces map[string]bool
+
+func (n memoryNonces) Claim(_ context.Context, nonce string, _ time.Duration) (bool, error) {
+	if n[nonce] {
+		return false, nil
+	}
+	n[nonce] = true
+	return true, nil
+}
+
+func newSignedTestInterceptor(t *testing.T) grpc.UnaryServerInterceptor {
+	t.Helper()
+	credentials, err := NewInternalCredentials("", []configs.InternalCredential{{
+		Name:        "reporting",
+		SigningKeys: []string{testSigningKey, testNextSigningKey},
+		Methods:     []string{user_v1_pb.UserService_GetUser_FullMethodName},
+	}})
+	if err != nil {
+		t.Fatalf("NewInternalCredentials failed: %v", err)
+	}
+	return BuildAuthInterceptor(
+		testJWTSecret,
+		WithInternalCredentials(credentials),
+		WithSignedRequests(memoryNonces{}, time.Minute),
+	)
+}
+
+func callSigned(
+	interceptor grpc.UnaryServerInterceptor,
+	md metadata.MD,
+	method string,
+	req any,
+) (string, error) {
+	var caller string
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	_, err := interceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+		caller, _ = InternalCredentialFromContext(ctx)
+		return "ok", nil
+	})
+	return caller, err
+}
+
+func TestSignedRequests(t *testing.T) {
+	interceptor := newSignedTestInterceptor(t)
+	method := user_v1_pb.UserService_GetUser_FullMethodName
+	req := &user_v1_pb.GetUserRequest{Id: "user-1"}
+	sign := func(key string, now time.Time) metadata.MD {
+		md, err := SignRequest("reporting", key, method, req, now)
+		if err != nil {
+			t.Fatalf("SignRequest failed: %v", err)
+		}
+		return md
+	}
+
+	md := sign(testSigningKey, time.Now())
+	caller, err := callSigned(interceptor, md, method, req)
+	if err != nil || caller != "reporting" {
+		t.Fatalf("expected the call to pass as reporting, got %q, %v", caller, err)
+	}
+	_, err = callSigned(interceptor, md, method, req)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected a replay to be refused, got %v", err)
+	}
+	_, err = callSigned(interceptor, sign(testNextSigningKey, time.Now()), method, req)
+	if err != nil {
+		t.Errorf("expected the next signing key to be accepted, got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		md       metadata.MD
+		method   string
+		req      any
+		expected codes.Code
+	}{
+		{
+			"other message",
+			sign(testSigningKey, time.Now()),
+			method,
+			&user_v1_pb.GetUserRequest{Id: "user-2"},
+			codes.Unauthenticated,
+		},
+		{
+			"other method",
+			sign(testSigningKey, time.Now()),
+			user_v1_pb.UserService_ListUsers_FullMethodName,
+			req,
+			codes.Unauthenticated,
+		},
+		{
+			"stale timestamp",
+			sign(testSigningKey, time.Now().Add(-2*time.Minute)),
+			method,
+			req,
+			codes.Unauthenticated,
+		},
+		{
+			"unknown key",
+			sign("not-a-signing-key-of-reporting!!", time.Now()),
+			method,
+			req,
+			codes.Unauthenticated,
+		},
+		{
+			"missing signature",
+			metadata.Pairs("x-token-type", TokenTypeSigned, MetadataSignatureKey, "reporting"),
+			method,
+			req,
+			codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := callSigned(interceptor, tt.md, tt.method, tt.req)
+			if status.Code(err) != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestSignedRequestsMethodRestriction(t *testing.T) {
+	interceptor := newSignedTestInterceptor(t)
+	method := user_v1_pb.UserService_ListUsers_FullMethodName
+	req := &user_v1_pb.ListUsersRequest{}
+	md, err := SignRequest("reporting", testSigningKey, method, req, time.Now())
+	if err != nil {
+		t.Fatalf("SignRequest failed: %v", err)
+	}
+	_, err = callSigned(interceptor, md, method, req)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied, got %v", err)
+	}
+}
+
+func TestSignedRequestsNotAccepted(t *testing.T) {
+	interceptor := BuildAuthInterceptor(testJWTSecret)
+	method := user_v1_pb.UserService_GetUser_FullMethodName
+	req := &user_v1_pb.GetUserRequest{Id: "user-1"}
+	md, err := SignRequest("reporting", testSigningKey, method, req, time.Now())
+	if err != nil {
+		t.Fatalf("SignRequest failed: %v", err)
+	}
+	_, err = callSigned(interceptor, md, method, req)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated, got %v", err)
+	}
+}
+
+func TestSigningUnaryClientInterceptor(t *testing.T) {
+	interceptor := newSignedTestInterceptor(t)
+	method := user_v1_pb.UserService_GetUser_FullMethodName
+	req := &user_v1_pb.GetUserRequest{Id: "user-1"}
+	var sent metadata.MD
+	invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn,
+		_ ...grpc.CallOption) error {
+		sent, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-1")
+	client := SigningUnaryClientInterceptor("reporting", testSigningKey)
+	if err := client(ctx, method, req, nil, nil, invoker); err != nil {
+		t.Fatalf("client interceptor failed: %v", err)
+	}
+	if got := sent.Get("x-request-id"); len(got) != 1 {
+		t.Errorf("expected the outgoing metadata to be kept, got %v", sent)
+	}
+	if _, err := callSigned(interceptor, sent, method, req); err != nil {
+		t.Errorf("expected the signed call to pass, got %v", err)
+	}
+}
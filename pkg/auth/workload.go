@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/poly-workshop/auth-portal/configs"
+)
+
+// TokenTypeWorkload marks internal calls carrying a workload token, e.g. a
+// Kubernetes service account token or a SPIFFE JWT-SVID, as bearer token.
+const TokenTypeWorkload = "workload"
+
+// workloadSigningMethods are the algorithms accepted for workload tokens;
+// Kubernetes signs with RS256 and SPIRE with RS256 or ES256 by default.
+var workloadSigningMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+// workloadHTTPTimeout bounds the discovery and key requests to an issuer.
+const workloadHTTPTimeout = 10 * time.Second
+
+// WorkloadVerifier verifies the tokens of workloads against the keys of their
+// issuers, which are fetched when first needed and refreshed periodically.
+type WorkloadVerifier struct {
+	issuers map[string]*workloadIssuer
+}
+
+type workloadIssuer struct {
+	audience string
+	keys     *jwks
+}
+
+// NewWorkloadVerifier returns a verifier of the tokens of issuers. It fails
+// for issuers without issuer or audience and for unreadable CA files.
+func NewWorkloadVerifier(issuers []configs.WorkloadIssuer) (*WorkloadVerifier, error) {
+	v := &WorkloadVerifier{issuers: make(map[string]*workloadIssuer, len(issuers))}
+	for _, cfg := range issuers {
+		if cfg.Issuer == "" || cfg.Audience == "" {
+			return nil, errors.New("workload issuer requires issuer and audience")
+		}
+		if _, ok := v.issuers[cfg.Issuer]; ok {
+			return nil, fmt.Errorf("workload issuer %q defined twice", cfg.Issuer)
+		}
+		client, err := workloadHTTPClient(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("workload issuer %q: %w", cfg.Issuer, err)
+		}
+		v.issuers[cfg.Issuer] = &workloadIssuer{
+			audience: cfg.Audience,
+			keys:     newJWKS(cfg.Issuer, cfg.JWKSURL, client),
+		}
+	}
+	return v, nil
+}
+
+func workloadHTTPClient(caFile string) (*http.Client, error) {
+	client := &http.Client{Timeout: workloadHTTPTimeout}
+	if caFile == "" {
+		return client, nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates in %s", caFile)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	client.Transport = transport
+	return client, nil
+}
+
+// Verify returns the issuer and subject of a workload token signed by a
+// configured issuer for its audience.
+func (v *WorkloadVerifier) Verify(ctx context.Context, token string) (string, string, error) {
+	unverified, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return "", "", err
+	}
+	iss, err := unverified.Claims.GetIssuer()
+	if err != nil {
+		return "", "", err
+	}
+	issuer, ok := v.issuers[iss]
+	if !ok {
+		return "", "", fmt.Errorf("untrusted issuer %q", iss)
+	}
+	parsed, err := jwt.Parse(
+		token,
+		func(token *jwt.Token) (any, error) {
+			kid, _ := token.Header["kid"].(string)
+			return issuer.keys.key(ctx, kid)
+		},
+		jwt.WithIssuer(iss),
+		jwt.WithAudience(issuer.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithValidMethods(workloadSigningMethods),
+	)
+	if err != nil {
+		return "", "", err
+	}
+	subject, err := parsed.Claims.GetSubject()
+	if err != nil || strings.TrimSpace(subject) == "" {
+		return "", "", errors.New("workload token without subject")
+	}
+	return iss, subject, nil
+}
@@ -0,0 +1,233 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/poly-workshop/auth-portal/configs"
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testWorkloadSubject = "system:serviceaccount:reporting:reporter"
+
+type testIssuer struct {
+	url    string
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+}
+
+func encodeBigInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+// newTestIssuer serves an OpenID configuration and a JWK set with an RSA and
+// an EC key.
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate EC key: %v", err)
+	}
+	issuer := &testIssuer{rsaKey: rsaKey, ecKey: ecKey}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration",
+		func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]string{"jwks_uri": issuer.url + "/keys"})
+		})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{
+			{
+				"kty": "RSA", "kid": "rsa", "use": "sig",
+				"n": encodeBigInt(rsaKey.N),
+				"e": encodeBigInt(big.NewInt(int64(rsaKey.E))),
+			},
+			{
+				"kty": "EC", "kid": "ec", "crv": "P-256",
+				"x": encodeBigInt(ecKey.X), "y": encodeBigInt(ecKey.Y),
+			},
+		}})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	issuer.url = srv.URL
+	return issuer
+}
+
+func (i *testIssuer) sign(
+	t *testing.T,
+	method jwt.SigningMethod,
+	kid string,
+	claims jwt.MapClaims,
+) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	var key any = i.rsaKey
+	if kid == "ec" {
+		key = i.ecKey
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func (i *testIssuer) claims(audience string, expiresIn time.Duration) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss": i.url,
+		"sub": testWorkloadSubject,
+		"aud": []string{audience},
+		"exp": time.Now().Add(expiresIn).Unix(),
+	}
+}
+
+func TestWorkloadVerifier(t *testing.T) {
+	issuer := newTestIssuer(t)
+	other := newTestIssuer(t)
+	verifier, err := NewWorkloadVerifier([]configs.WorkloadIssuer{
+		{Issuer: issuer.url, Audience: "auth-portal"},
+	})
+	if err != nil {
+		t.Fatalf("NewWorkloadVerifier failed: %v", err)
+	}
+
+	rs256, es256 := jwt.SigningMethodRS256, jwt.SigningMethodES256
+	valid := issuer.claims("auth-portal", time.Hour)
+	hmacToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, valid).SignedString([]byte("secret"))
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"RSA", issuer.sign(t, rs256, "rsa", valid), false},
+		{"EC", issuer.sign(t, es256, "ec", valid), false},
+		{"other audience", issuer.sign(t, rs256, "rsa", issuer.claims("other", time.Hour)), true},
+		{"expired", issuer.sign(t, rs256, "rsa", issuer.claims("auth-portal", -time.Hour)), true},
+		{
+			"untrusted issuer",
+			other.sign(t, rs256, "rsa", other.claims("auth-portal", time.Hour)),
+			true,
+		},
+		{"unknown key", issuer.sign(t, rs256, "rotated", valid), true},
+		{"HMAC", hmacToken, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iss, subject, err := verifier.Verify(context.Background(), tt.token)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected the token to be refused, got subject %q", subject)
+				}
+				return
+			}
+			if err != nil || iss != issuer.url || subject != testWorkloadSubject {
+				t.Fatalf("expected subject %q of %q, got %q of %q, %v",
+					testWorkloadSubject, issuer.url, subject, iss, err)
+			}
+		})
+	}
+}
+
+func TestInterceptorWorkloadIdentity(t *testing.T) {
+	issuer := newTestIssuer(t)
+	other := newTestIssuer(t)
+	verifier, err := NewWorkloadVerifier([]configs.WorkloadIssuer{
+		{Issuer: issuer.url, Audience: "auth-portal"},
+		{Issuer: other.url, Audience: "auth-portal"},
+	})
+	if err != nil {
+		t.Fatalf("NewWorkloadVerifier failed: %v", err)
+	}
+	credentials, err := NewInternalCredentials("", []configs.InternalCredential{{
+		Name: "reporting",
+		Workloads: []configs.WorkloadIdentity{
+			{Issuer: issuer.url, Subject: testWorkloadSubject},
+		},
+		Methods: []string{user_v1_pb.UserService_GetUser_FullMethodName},
+	}})
+	if err != nil {
+		t.Fatalf("NewInternalCredentials failed: %v", err)
+	}
+	interceptor := BuildAuthInterceptor(
+		testJWTSecret,
+		WithInternalCredentials(credentials),
+		WithWorkloadIdentity(verifier),
+	)
+	token := issuer.sign(t, jwt.SigningMethodRS256, "rsa", issuer.claims("auth-portal", time.Hour))
+	stranger := issuer.claims("auth-portal", time.Hour)
+	stranger["sub"] = "system:serviceaccount:default:default"
+
+	tests := []struct {
+		name     string
+		token    string
+		method   string
+		expected codes.Code
+	}{
+		{"mapped workload", token, user_v1_pb.UserService_GetUser_FullMethodName, codes.OK},
+		{
+			"method not allowed",
+			token,
+			user_v1_pb.UserService_ListUsers_FullMethodName,
+			codes.PermissionDenied,
+		},
+		{
+			"unmapped workload",
+			issuer.sign(t, jwt.SigningMethodRS256, "rsa", stranger),
+			user_v1_pb.UserService_GetUser_FullMethodName,
+			codes.PermissionDenied,
+		},
+		{
+			// Subjects are only unique per issuer, e.g. per cluster
+			"subject of another issuer",
+			other.sign(t, jwt.SigningMethodRS256, "rsa", other.claims("auth-portal", time.Hour)),
+			user_v1_pb.UserService_GetUser_FullMethodName,
+			codes.PermissionDenied,
+		},
+		{
+			"invalid token",
+			"not-a-token",
+			user_v1_pb.UserService_GetUser_FullMethodName,
+			codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				"authorization", "Bearer "+tt.token,
+				"x-token-type", TokenTypeWorkload,
+			))
+			info := &grpc.UnaryServerInfo{FullMethod: tt.method}
+			var caller string
+			_, err := interceptor(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+				caller, _ = InternalCredentialFromContext(ctx)
+				return "ok", nil
+			})
+			if status.Code(err) != tt.expected {
+				t.Fatalf("expected %v, got %v", tt.expected, err)
+			}
+			if err == nil && caller != "reporting" {
+				t.Errorf("expected the call to carry credential reporting, got %q", caller)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// call sends a JSON request to the gateway and decodes the JSON response.
+func call(t *testing.T, method, path, token string, body any) (int, map[string]any) {
+	t.Helper()
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("failed to encode request: %v", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, suite.gatewayURL+path, reqBody)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	result := map[string]any{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil && err != io.EOF {
+		t.Fatalf("failed to decode response of %s %s: %v", method, path, err)
+	}
+	return resp.StatusCode, result
+}
+
+// mustCall is call for requests that are expected to succeed.
+func mustCall(t *testing.T, method, path, token string, body any) map[string]any {
+	t.Helper()
+	code, result := call(t, method, path, token, body)
+	if code != http.StatusOK {
+		t.Fatalf("%s %s answered %d: %v", method, path, code, result)
+	}
+	return result
+}
+
+// field walks nested JSON objects, e.g. field(resp, "session", "id").
+func field(value map[string]any, path ...string) string {
+	var current any = value
+	for _, key := range path {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return ""
+		}
+		current = object[key]
+	}
+	if current == nil {
+		return ""
+	}
+	return fmt.Sprint(current)
+}
+
+// userToken exchanges a login session for an access token.
+func userToken(t *testing.T, sessionID string) string {
+	t.Helper()
+	resp := mustCall(t, http.MethodPost, "/v1/token", "", map[string]string{"sessionId": sessionID})
+	token := field(resp, "token", "token")
+	if token == "" {
+		t.Fatalf("no token in response %v", resp)
+	}
+	return token
+}
+
+// internalUserClient calls the user service directly with the internal token,
+// which the gateway does not forward.
+func internalUserClient(t *testing.T) (user_v1_pb.UserServiceClient, context.Context) {
+	t.Helper()
+	conn, err := grpc.NewClient(
+		suite.grpcAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to connect to grpc server: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	ctx := metadata.AppendToOutgoingContext(
+		context.Background(),
+		"authorization", "Bearer "+internalToken,
+		"x-token-type", "internal",
+	)
+	return user_v1_pb.NewUserServiceClient(conn), ctx
+}
@@ -0,0 +1,93 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// container is a throwaway Docker container with its port published on a
+// random host port.
+type container struct {
+	id   string
+	addr string
+}
+
+// startContainer runs the image detached and returns the host address the
+// container port is published on. Containers are removed when stopped.
+func startContainer(ctx context.Context, image, port string, env ...string) (*container, error) {
+	args := []string{"run", "--detach", "--rm", "--publish", "127.0.0.1::" + port}
+	for _, e := range env {
+		args = append(args, "--env", e)
+	}
+	id, err := docker(ctx, append(args, image)...)
+	if err != nil {
+		return nil, err
+	}
+	c := &container{id: id}
+
+	mapping, err := docker(ctx, "port", id, port)
+	if err != nil {
+		c.stop()
+		return nil, err
+	}
+	// One line per address family, e.g. "127.0.0.1:49153"
+	c.addr, _, _ = strings.Cut(mapping, "\n")
+	return c, nil
+}
+
+func (c *container) host() string {
+	host, _, _ := net.SplitHostPort(c.addr)
+	return host
+}
+
+func (c *container) port() string {
+	_, port, _ := net.SplitHostPort(c.addr)
+	return port
+}
+
+// exec runs a command inside the container.
+func (c *container) exec(ctx context.Context, cmd ...string) error {
+	_, err := docker(ctx, append([]string{"exec", c.id}, cmd...)...)
+	return err
+}
+
+func (c *container) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_, _ = docker(ctx, "rm", "--force", c.id)
+}
+
+func docker(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("docker %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// waitFor polls check until it succeeds or the timeout expires.
+func waitFor(ctx context.Context, timeout time.Duration, check func() error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		err := check()
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("not ready after %s: %w", timeout, err)
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
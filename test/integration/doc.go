@@ -0,0 +1,8 @@
+// Package integration holds the end-to-end test suite. It starts Postgres and
+// Redis containers, boots the gRPC server and the gateway on random ports
+// against them, and drives the public API like a client would.
+//
+// The suite needs Docker and is excluded from regular test runs:
+//
+//	go test -tags integration ./test/integration/
+package integration
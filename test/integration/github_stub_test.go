@@ -0,0 +1,112 @@
+//go:build integration
+
+package integration
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// githubUser is a user of the GitHub stub.
+type githubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+	Email string `json:"-"`
+}
+
+// githubStub serves the parts of the GitHub OAuth and REST APIs used for
+// logins, the way GitHub Enterprise Server lays them out.
+type githubStub struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	codes  map[string]githubUser
+	tokens map[string]githubUser
+}
+
+func newGitHubStub() *githubStub {
+	stub := &githubStub{
+		codes:  make(map[string]githubUser),
+		tokens: make(map[string]githubUser),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login/oauth/access_token", stub.accessToken)
+	mux.HandleFunc("GET /api/v3/user", stub.user)
+	mux.HandleFunc("GET /api/v3/user/emails", stub.emails)
+	stub.Server = httptest.NewServer(mux)
+	return stub
+}
+
+// issueCode returns an authorization code for the user, as if they had
+// authorized the app on GitHub.
+func (s *githubStub) issueCode(user githubUser) string {
+	code := randomHex()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.codes[code] = user
+	return code
+}
+
+func (s *githubStub) accessToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	user, ok := s.codes[code]
+	delete(s.codes, code)
+	token := randomHex()
+	if ok {
+		s.tokens[token] = user
+	}
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code"})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"access_token": token,
+		"token_type":   "bearer",
+	})
+}
+
+func (s *githubStub) authenticated(w http.ResponseWriter, r *http.Request) (githubUser, bool) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	s.mu.Lock()
+	user, ok := s.tokens[token]
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, `{"message": "Bad credentials"}`, http.StatusUnauthorized)
+	}
+	return user, ok
+}
+
+func (s *githubStub) user(w http.ResponseWriter, r *http.Request) {
+	if user, ok := s.authenticated(w, r); ok {
+		_ = json.NewEncoder(w).Encode(user)
+	}
+}
+
+func (s *githubStub) emails(w http.ResponseWriter, r *http.Request) {
+	if user, ok := s.authenticated(w, r); ok {
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"email": user.Email, "primary": true, "verified": true},
+		})
+	}
+}
+
+func randomHex() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,120 @@
+//go:build integration
+
+package integration
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	user_v1_pb "github.com/poly-workshop/auth-portal/gen/user/v1"
+)
+
+func TestOAuthLogin(t *testing.T) {
+	resp := mustCall(t, http.MethodGet, "/v1/oauth/url?provider=github", "", nil)
+	authURL, err := url.Parse(field(resp, "url"))
+	if err != nil || authURL.Host != mustParse(t, suite.github.URL).Host {
+		t.Fatalf("expected an authorize url of the stub, got %q", field(resp, "url"))
+	}
+	state := field(resp, "state")
+
+	code := suite.github.issueCode(githubUser{
+		ID:    4242,
+		Login: "octocat",
+		Name:  "Octo Cat",
+		Email: "octocat@example.com",
+	})
+	resp = mustCall(t, http.MethodPost, "/v1/login/oauth", "", map[string]string{
+		"code":  code,
+		"state": state,
+	})
+	token := userToken(t, field(resp, "session", "id"))
+
+	me := mustCall(t, http.MethodGet, "/v1/users/me", token, nil)
+	if got := field(me, "user", "email"); got != "octocat@example.com" {
+		t.Errorf("expected the GitHub user's email, got %q", got)
+	}
+
+	// The state is single-use
+	status, _ := call(t, http.MethodPost, "/v1/login/oauth", "", map[string]string{
+		"code":  suite.github.issueCode(githubUser{ID: 4242, Email: "octocat@example.com"}),
+		"state": state,
+	})
+	if status == http.StatusOK {
+		t.Error("expected a reused state to be rejected")
+	}
+}
+
+func TestPasswordLoginAndRBAC(t *testing.T) {
+	client, ctx := internalUserClient(t)
+	password := "initial-password"
+	for _, user := range []*user_v1_pb.CreateUserRequest{
+		{Name: "Admin", Email: "admin@example.com", Role: user_v1_pb.UserRole_USER_ROLE_ADMIN},
+		{Name: "User", Email: "user@example.com", Role: user_v1_pb.UserRole_USER_ROLE_USER},
+	} {
+		user.Password = &password
+		if _, err := client.CreateUser(ctx, user); err != nil {
+			t.Fatalf("CreateUser(%s) failed: %v", user.Email, err)
+		}
+	}
+
+	adminToken := loginWithNewPassword(t, "admin@example.com", password)
+	userToken := loginWithNewPassword(t, "user@example.com", password)
+
+	if status, _ := call(t, http.MethodGet, "/v1/users", "", nil); status != http.StatusUnauthorized {
+		t.Errorf("expected anonymous requests to be rejected, got %d", status)
+	}
+	status, _ := call(t, http.MethodGet, "/v1/users", userToken, nil)
+	if status != http.StatusForbidden {
+		t.Errorf("expected users to be denied listing users, got %d", status)
+	}
+	mustCall(t, http.MethodGet, "/v1/users", adminToken, nil)
+	mustCall(t, http.MethodGet, "/v1/users/me", userToken, nil)
+
+	if status, _ := call(t, http.MethodPost, "/v1/login/password", "", map[string]string{
+		"email":    "user@example.com",
+		"password": "wrong-password",
+	}); status == http.StatusOK {
+		t.Error("expected a wrong password to be rejected")
+	}
+}
+
+// loginWithNewPassword logs in with the temporary password set by an admin,
+// replaces it as required and returns a token for the new password's session.
+func loginWithNewPassword(t *testing.T, email, password string) string {
+	t.Helper()
+	resp := mustCall(t, http.MethodPost, "/v1/login/password", "", map[string]string{
+		"email":    email,
+		"password": password,
+	})
+	if field(resp, "mustChangePassword") != "true" {
+		t.Fatalf("expected a forced password change for %s, got %v", email, resp)
+	}
+	token := userToken(t, field(resp, "session", "id"))
+
+	// Until the password is changed the token only grants ChangePassword
+	status, _ := call(t, http.MethodGet, "/v1/users/me", token, nil)
+	if status != http.StatusForbidden {
+		t.Errorf("expected a restricted token before the password change, got %d", status)
+	}
+	newPassword := password + "-changed"
+	mustCall(t, http.MethodPost, "/v1/users/me/password", token, map[string]string{
+		"currentPassword": password,
+		"newPassword":     newPassword,
+	})
+
+	resp = mustCall(t, http.MethodPost, "/v1/login/password", "", map[string]string{
+		"email":    email,
+		"password": newPassword,
+	})
+	return userToken(t, field(resp, "session", "id"))
+}
+
+func mustParse(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", raw, err)
+	}
+	return u
+}
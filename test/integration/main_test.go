@@ -0,0 +1,227 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	internalToken = "integration-internal-token"
+	jwtSecret     = "integration-jwt-secret"
+	redirectURL   = "http://localhost/auth/callback"
+)
+
+// suite is the environment shared by all tests of the package.
+var suite struct {
+	gatewayURL string
+	grpcAddr   string
+	github     *githubStub
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	ctx := context.Background()
+	workDir, err := os.MkdirTemp("", "auth-portal-integration")
+	if err != nil {
+		log.Printf("failed to create work dir: %v", err)
+		return 1
+	}
+	defer func() { _ = os.RemoveAll(workDir) }()
+
+	postgres, err := startPostgres(ctx)
+	if err != nil {
+		log.Printf("failed to start postgres: %v", err)
+		return 1
+	}
+	defer postgres.stop()
+
+	redisContainer, err := startRedis(ctx)
+	if err != nil {
+		log.Printf("failed to start redis: %v", err)
+		return 1
+	}
+	defer redisContainer.stop()
+
+	suite.github = newGitHubStub()
+	defer suite.github.Close()
+
+	grpcPort, err := freePort()
+	if err != nil {
+		log.Printf("failed to pick grpc port: %v", err)
+		return 1
+	}
+	httpPort, err := freePort()
+	if err != nil {
+		log.Printf("failed to pick http port: %v", err)
+		return 1
+	}
+	suite.grpcAddr = net.JoinHostPort("localhost", strconv.Itoa(grpcPort))
+	suite.gatewayURL = fmt.Sprintf("http://localhost:%d/api", httpPort)
+
+	// Configuration is passed through the environment, overriding configs/default.toml
+	env := append(os.Environ(),
+		"MODE=integration",
+		"SERVER__PORT="+strconv.Itoa(grpcPort),
+		"SERVER__HTTP_PORT="+strconv.Itoa(httpPort),
+		"METRICS__PORT=0",
+		"AUTH__INTERNAL_TOKEN="+internalToken,
+		"AUTH__JWT_SECRET="+jwtSecret,
+		"AUTH__GITHUB_BASE_URL="+suite.github.URL,
+		"AUTH__GITHUB_REDIRECT_URL="+redirectURL,
+		"GORM_CLIENT__DATABASE__DRIVER=postgres",
+		"GORM_CLIENT__DATABASE__HOST="+postgres.host(),
+		"GORM_CLIENT__DATABASE__PORT="+postgres.port(),
+		"GORM_CLIENT__DATABASE__USERNAME=postgres",
+		"GORM_CLIENT__DATABASE__PASSWORD=postgres",
+		"GORM_CLIENT__DATABASE__NAME=auth_portal",
+		"GORM_CLIENT__DATABASE__SSLMODE=disable",
+		"REDIS__URLS="+redisContainer.addr,
+	)
+
+	grpcServer, err := startServer(ctx, workDir, "grpc-server", env)
+	if err != nil {
+		log.Printf("failed to start grpc server: %v", err)
+		return 1
+	}
+	defer grpcServer.stop()
+	gateway, err := startServer(ctx, workDir, "gateway-server", env)
+	if err != nil {
+		log.Printf("failed to start gateway: %v", err)
+		return 1
+	}
+	defer gateway.stop()
+
+	err = waitFor(ctx, time.Minute, func() error {
+		resp, err := http.Get(suite.gatewayURL + "/config")
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("config endpoint answered %s", resp.Status)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("servers did not become ready: %v", err)
+		grpcServer.dumpLog()
+		gateway.dumpLog()
+		return 1
+	}
+
+	code := m.Run()
+	if code != 0 {
+		grpcServer.dumpLog()
+		gateway.dumpLog()
+	}
+	return code
+}
+
+func startPostgres(ctx context.Context) (*container, error) {
+	c, err := startContainer(ctx, "postgres:16-alpine", "5432/tcp",
+		"POSTGRES_PASSWORD=postgres",
+		"POSTGRES_DB=auth_portal",
+	)
+	if err != nil {
+		return nil, err
+	}
+	// pg_isready inside the container succeeds once the server accepts connections
+	err = waitFor(ctx, time.Minute, func() error {
+		return c.exec(ctx, "pg_isready", "--username=postgres", "--host=127.0.0.1")
+	})
+	if err != nil {
+		c.stop()
+		return nil, err
+	}
+	return c, nil
+}
+
+func startRedis(ctx context.Context) (*container, error) {
+	c, err := startContainer(ctx, "redis:7-alpine", "6379/tcp")
+	if err != nil {
+		return nil, err
+	}
+	rdb := redis.NewClient(&redis.Options{Addr: c.addr})
+	defer func() { _ = rdb.Close() }()
+	if err := waitFor(ctx, 30*time.Second, func() error {
+		return rdb.Ping(ctx).Err()
+	}); err != nil {
+		c.stop()
+		return nil, err
+	}
+	return c, nil
+}
+
+// server is a binary of cmd/ running in the background.
+type server struct {
+	cmd     *exec.Cmd
+	logPath string
+}
+
+// startServer builds cmd/<name> and runs it from the repository root, so it
+// finds the configs directory.
+func startServer(ctx context.Context, workDir, name string, env []string) (*server, error) {
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		return nil, err
+	}
+	binary := filepath.Join(workDir, name)
+	build := exec.CommandContext(ctx, "go", "build", "-o", binary, "./cmd/"+name)
+	build.Dir = root
+	if out, err := build.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to build %s: %w: %s", name, err, out)
+	}
+
+	logPath := filepath.Join(workDir, name+".log")
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = logFile.Close() }()
+
+	cmd := exec.Command(binary)
+	cmd.Dir = root
+	cmd.Env = env
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &server{cmd: cmd, logPath: logPath}, nil
+}
+
+func (s *server) stop() {
+	_ = s.cmd.Process.Kill()
+	_ = s.cmd.Wait()
+}
+
+func (s *server) dumpLog() {
+	if out, err := os.ReadFile(s.logPath); err == nil {
+		log.Printf("%s output:\n%s", filepath.Base(s.cmd.Path), out)
+	}
+}
+
+func freePort() (int, error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = lis.Close() }()
+	return lis.Addr().(*net.TCPAddr).Port, nil
+}